@@ -13,9 +13,10 @@ import (
 
 // BistreToModel adapts azul parser output to the new model types.
 // It returns a ReportFile (the source document metadata) and a ReportX (the extracted data).
-func BistreToModel(source string, pt *azul.Turn_t) (*model.ReportFile, *model.ReportX, error) {
-	now := time.Now().UTC()
-	turnNo := 100*pt.Year + pt.Month
+// now stamps CreatedAt; if nil, time.Now().UTC() is used.
+func BistreToModel(source string, pt *azul.Turn_t, now func() time.Time) (*model.ReportFile, *model.ReportX, error) {
+	createdAt := Options{Now: now}.now()
+	turnNo := model.NewTurnNo(pt.Year, pt.Month)
 
 	rf := &model.ReportFile{
 		ID:        0,  // caller assigns
@@ -23,7 +24,7 @@ func BistreToModel(source string, pt *azul.Turn_t) (*model.ReportFile, *model.Re
 		ClanNo:    "", // caller assigns
 		TurnNo:    turnNo,
 		Name:      source,
-		CreatedAt: now,
+		CreatedAt: createdAt,
 	}
 
 	rx := &model.ReportX{
@@ -32,7 +33,7 @@ func BistreToModel(source string, pt *azul.Turn_t) (*model.ReportFile, *model.Re
 		Game:         rf.Game,
 		ClanNo:       rf.ClanNo,
 		TurnNo:       rf.TurnNo,
-		CreatedAt:    now,
+		CreatedAt:    createdAt,
 	}
 
 	// Convert each unit's moves to UnitX
@@ -42,6 +43,7 @@ func BistreToModel(source string, pt *azul.Turn_t) (*model.ReportFile, *model.Re
 			ID:        0, // caller assigns
 			ReportXID: rx.ID,
 			UnitID:    string(unitId),
+			Kind:      model.UnitKindOf(string(unitId)),
 			TurnNo:    turnNo,
 			StartTN:   model.TNCoord(moves.PreviousHex),
 			EndTN:     model.TNCoord(moves.CurrentHex),
@@ -137,7 +139,8 @@ func adaptMove(mv *azul.Move_t, seq int) *model.Step {
 		step.Kind = model.StepKindAdv
 		step.Dir = mv.Advance.String()
 		if !step.Ok {
-			step.FailWhy = resultToFailWhy(mv.Result)
+			step.FailWhy = resultToFailWhy(mv.Result, mv.FailPhrase)
+			step.FailRaw = mv.FailPhrase
 		}
 	} else {
 		step.Kind = model.StepKindObs
@@ -189,17 +192,21 @@ func adaptMove(mv *azul.Move_t, seq int) *model.Step {
 	return step
 }
 
-// resultToFailWhy maps results.Result_e to a failure reason string.
-func resultToFailWhy(r results.Result_e) string {
+// resultToFailWhy maps a failed step to a model.FailReason code.
+// The GM's phrase is preferred; the parser result is the fallback.
+func resultToFailWhy(r results.Result_e, phrase string) string {
+	if phrase != "" {
+		return string(model.FailReasonOf(phrase))
+	}
 	switch r {
 	case results.Blocked:
-		return "blocked"
+		return string(model.FailBlockedByUnit)
 	case results.ExhaustedMovementPoints:
-		return "exhaust"
+		return string(model.FailExhausted)
 	case results.Prohibited:
-		return "terrain"
+		return string(model.FailProhibited)
 	case results.Failed:
-		return "unknown"
+		return string(model.FailUnknown)
 	default:
 		return ""
 	}
@@ -20,6 +20,7 @@ func BistreParserTurnToModel(source string, pt *bistre.Turn_t) (*tnrpt.Turn_t, e
 		SpecialNames: map[string]*tnrpt.Special_t{},
 	}
 
+	ResolveDuplicateUnits(pt)
 	for k, v := range pt.UnitMoves {
 		mt.UnitMoves[tnrpt.UnitId_t(k)] = adaptBistreParserMoves(v)
 	}
@@ -3,6 +3,8 @@
 package adapters
 
 import (
+	"errors"
+	"sort"
 	"time"
 
 	"github.com/mdhender/tnrpt/direction"
@@ -13,31 +15,60 @@ import (
 
 // BistreTurnToModelReportX converts a bistre.Turn_t to model.ReportX without persisting.
 // This is for in-memory use in the web spike.
-func BistreTurnToModelReportX(source string, turn *bistre.Turn_t, game, clanNo string) (*model.ReportX, error) {
-	now := time.Now().UTC()
-	turnNo := 100*turn.Year + turn.Month
+// Units with IDs that don't follow the game's rules are rejected; the error
+// joins one *model.UnitIDError per malformed ID.
+// Duplicated unit sections are resolved with ResolveDuplicateUnits; callers
+// that want the warnings should call it first.
+// now stamps CreatedAt; if nil, time.Now().UTC() is used.
+func BistreTurnToModelReportX(source string, turn *bistre.Turn_t, game, clanNo string, ids model.UnitIDRules, now func() time.Time) (*model.ReportX, error) {
+	createdAt := Options{Now: now}.now()
+	turnNo := model.NewTurnNo(turn.Year, turn.Month)
+	ResolveDuplicateUnits(turn)
 
 	rx := &model.ReportX{
 		Game:      game,
 		ClanNo:    clanNo,
 		TurnNo:    turnNo,
-		CreatedAt: now,
+		Season:    turn.Season,
+		Weather:   turn.Weather,
+		CreatedAt: createdAt,
 	}
 
+	var errs []error
 	for unitId, moves := range turn.UnitMoves {
-		ux := convertUnitMoves(turnNo, unitId, moves)
+		ux, err := convertUnitMoves(ids, turnNo, unitId, moves)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
 		rx.Units = append(rx.Units, ux)
 	}
+	if errs != nil {
+		sort.Slice(errs, func(i, j int) bool {
+			return errs[i].Error() < errs[j].Error()
+		})
+		return nil, errors.Join(errs...)
+	}
 
 	return rx, nil
 }
 
-func convertUnitMoves(turnNo int, unitId bistre.UnitId_t, moves *bistre.Moves_t) *model.UnitX {
+func convertUnitMoves(ids model.UnitIDRules, turnNo model.TurnNo, unitId bistre.UnitId_t, moves *bistre.Moves_t) (*model.UnitX, error) {
+	clanID, err := ids.ClanID(string(unitId))
+	if err != nil {
+		return nil, err
+	}
 	ux := &model.UnitX{
 		UnitID:  string(unitId),
+		ClanID:  clanID,
+		Kind:    ids.Kind(string(unitId)),
 		TurnNo:  turnNo,
 		StartTN: model.TNCoord(moves.PreviousHex),
 		EndTN:   model.TNCoord(moves.CurrentHex),
+		Src:     &model.SrcRef{UnitID: string(unitId), TurnNo: turnNo, Line: moves.LineNo},
+	}
+	src := func(actSeq, stepSeq, line, lineStep int) *model.SrcRef {
+		return &model.SrcRef{UnitID: string(unitId), TurnNo: turnNo, ActSeq: actSeq, StepSeq: stepSeq, Line: line, LineStep: lineStep}
 	}
 
 	actSeq := 0
@@ -49,6 +80,7 @@ func convertUnitMoves(turnNo int, unitId bistre.UnitId_t, moves *bistre.Moves_t)
 			Kind:         model.ActKindFollow,
 			Ok:           true,
 			TargetUnitID: string(moves.Follows),
+			Src:          src(actSeq, 0, moveLine(moves.Moves, func(mv *bistre.Move_t) bool { return mv.Follows != "" }), 0),
 		}
 		ux.Acts = append(ux.Acts, act)
 	}
@@ -60,6 +92,7 @@ func convertUnitMoves(turnNo int, unitId bistre.UnitId_t, moves *bistre.Moves_t)
 			Kind:   model.ActKindGoto,
 			Ok:     true,
 			DestTN: model.TNCoord(moves.GoesTo),
+			Src:    src(actSeq, 0, moveLine(moves.Moves, func(mv *bistre.Move_t) bool { return mv.GoesTo != "" }), 0),
 		}
 		ux.Acts = append(ux.Acts, act)
 	}
@@ -70,10 +103,12 @@ func convertUnitMoves(turnNo int, unitId bistre.UnitId_t, moves *bistre.Moves_t)
 			Seq:  actSeq,
 			Kind: model.ActKindMove,
 			Ok:   true,
+			Src:  src(actSeq, 0, moves.Moves[0].LineNo, 0),
 		}
 
 		for stepSeq, mv := range moves.Moves {
 			step := convertMove(mv, stepSeq+1)
+			step.Src = src(actSeq, stepSeq+1, mv.LineNo, mv.StepNo)
 			act.Steps = append(act.Steps, step)
 			if !step.Ok {
 				act.Ok = false
@@ -89,10 +124,12 @@ func convertUnitMoves(turnNo int, unitId bistre.UnitId_t, moves *bistre.Moves_t)
 			Seq:  actSeq,
 			Kind: model.ActKindScout,
 			Ok:   true,
+			Src:  src(actSeq, 0, scout.LineNo, 0),
 		}
 
 		for stepSeq, mv := range scout.Moves {
 			step := convertMove(mv, stepSeq+1)
+			step.Src = src(actSeq, stepSeq+1, mv.LineNo, mv.StepNo)
 			act.Steps = append(act.Steps, step)
 			if !step.Ok {
 				act.Ok = false
@@ -102,7 +139,17 @@ func convertUnitMoves(turnNo int, unitId bistre.UnitId_t, moves *bistre.Moves_t)
 		ux.Acts = append(ux.Acts, act)
 	}
 
-	return ux
+	return ux, nil
+}
+
+// moveLine returns the line of the first move that matches, or 0 if none do.
+func moveLine(moves []*bistre.Move_t, match func(*bistre.Move_t) bool) int {
+	for _, mv := range moves {
+		if match(mv) {
+			return mv.LineNo
+		}
+	}
+	return 0
 }
 
 func convertMove(mv *bistre.Move_t, seq int) *model.Step {
@@ -117,7 +164,8 @@ func convertMove(mv *bistre.Move_t, seq int) *model.Step {
 		step.Kind = model.StepKindAdv
 		step.Dir = mv.Advance.String()
 		if !step.Ok {
-			step.FailWhy = convertResultToFailWhy(mv.Result)
+			step.FailWhy = convertResultToFailWhy(mv.Result, mv.FailPhrase)
+			step.FailRaw = mv.FailPhrase
 		}
 	} else {
 		step.Kind = model.StepKindObs
@@ -166,16 +214,19 @@ func convertMove(mv *bistre.Move_t, seq int) *model.Step {
 	return step
 }
 
-func convertResultToFailWhy(r results.Result_e) string {
+func convertResultToFailWhy(r results.Result_e, phrase string) string {
+	if phrase != "" {
+		return string(model.FailReasonOf(phrase))
+	}
 	switch r {
 	case results.Blocked:
-		return "blocked"
+		return string(model.FailBlockedByUnit)
 	case results.ExhaustedMovementPoints:
-		return "exhaust"
+		return string(model.FailExhausted)
 	case results.Prohibited:
-		return "terrain"
+		return string(model.FailProhibited)
 	case results.Failed:
-		return "unknown"
+		return string(model.FailUnknown)
 	default:
 		return ""
 	}
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package adapters_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/pipelines/parsers/report"
+)
+
+func TestBistreTurnToModelReportX_SourceMap(t *testing.T) {
+	const text = `Tribe 0987, , Current Hex = QQ 1012, (Previous Hex = QQ 1010)
+Current Turn 900-01 (#1), Winter, FINE Next Turn 900-02 (#2), 15/12/2025
+Tribe Movement: Move N-PR,  \N-GH, River S,  \NE-PR
+Scout 1:Scout N-PR, \Can't Move on Ocean to N of HEX
+0987 Status: PRAIRIE, 0987
+
+Courier 0987c1, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)
+Current Turn 900-01 (#1), Winter, FINE
+0987c1 Status: PRAIRIE, 0987c1
+`
+	turn, err := bistre.ParseInput("test", "", []byte(text), bistre.ParseConfig{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	createdAt := time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC)
+	rx, err := adapters.BistreTurnToModelReportX("test", turn, "0301", "0987", model.DefaultUnitIDRules, func() time.Time { return createdAt })
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if !rx.CreatedAt.Equal(createdAt) {
+		t.Errorf("created at: expected %v, got %v", createdAt, rx.CreatedAt)
+	}
+	m := model.NewSourceMap([]byte(text), rx.Units)
+
+	// line:col to record
+	found := m.AtLineCol(3, 32)
+	if len(found) != 3 || found[0].Kind != model.SourceStep || found[0].Text != "N-GH, River S" {
+		t.Fatalf("3:32: expected the second step of the move, got %+v", found)
+	}
+	if step := found[0].Step; step == nil || step.Dir != "N" {
+		t.Errorf("3:32: expected a step north, got %+v", step)
+	}
+
+	// record to line:col
+	testCases := []struct {
+		ref       model.SrcRef
+		line, col int
+		text      string
+	}{
+		{ref: model.SrcRef{UnitID: "0987"}, line: 1, col: 1},
+		{ref: model.SrcRef{UnitID: "0987", ActSeq: 1, StepSeq: 3}, line: 3, col: 47, text: "NE-PR"},
+		{ref: model.SrcRef{UnitID: "0987", ActSeq: 1, StepSeq: 4}, line: 5, col: 14, text: "PRAIRIE, 0987"},
+		{ref: model.SrcRef{UnitID: "0987", ActSeq: 2, StepSeq: 2}, line: 4, col: 22, text: "Can't Move on Ocean to N of HEX"},
+		{ref: model.SrcRef{UnitID: "0987c1", ActSeq: 1, StepSeq: 1}, line: 9, col: 16, text: "PRAIRIE, 0987c1"},
+	}
+	for _, tc := range testCases {
+		e, ok := m.Find(tc.ref)
+		if !ok {
+			t.Errorf("%+v: not found", tc.ref)
+			continue
+		}
+		if e.Start.Line != tc.line || e.Start.Col != tc.col {
+			t.Errorf("%+v: expected %d:%d, got %d:%d", tc.ref, tc.line, tc.col, e.Start.Line, e.Start.Col)
+		}
+		if tc.text != "" && e.Text != tc.text {
+			t.Errorf("%+v: expected %q, got %q", tc.ref, tc.text, e.Text)
+		}
+	}
+}
+
+// TestBistreTurnToModelReportX_FiveDigitClans runs a report from a game with
+// 5-digit clans through the splitter, the parser, and the adapter, the way
+// the pipeline's parse stage does.
+func TestBistreTurnToModelReportX_FiveDigitClans(t *testing.T) {
+	const text = `Tribe 01987, , Current Hex = QQ 1012, (Previous Hex = QQ 1010)
+Current Turn 900-01 (#1), Winter, FINE Next Turn 900-02 (#2), 15/12/2025
+Tribe Movement: Move N-PR
+01987 Status: PRAIRIE, 01987 01987e1
+
+Element 01987e1, , Current Hex = QQ 1012, (Previous Hex = QQ 1012)
+Current Turn 900-01 (#1), Winter, FINE
+01987e1 Status: PRAIRIE, 01987e1 01987
+`
+	rpt, err := report.ParseReportText(&docx.Docx{Source: "0301.0900-01.01987.report.txt", Text: []byte(text)}, true, true, true, false, false)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	var sections []string
+	var input []byte
+	for _, section := range rpt.Sections {
+		sections = append(sections, section.UnitId)
+		input = append(input, bytes.Join(section.Lines, []byte{'\n'})...)
+		input = append(input, '\n')
+	}
+	if len(sections) != 2 || sections[0] != "01987" || sections[1] != "01987e1" {
+		t.Fatalf("split: expected sections 01987 and 01987e1, got %q", sections)
+	}
+
+	turn, err := bistre.ParseInput("test", rpt.TurnNo, input, bistre.ParseConfig{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	rx, err := adapters.BistreTurnToModelReportX("test", turn, "0301", "01987", model.UnitIDRules{ClanDigits: 5}, nil)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if len(rx.Units) != 2 {
+		t.Fatalf("expected 2 units, got %d", len(rx.Units))
+	}
+	for _, ux := range rx.Units {
+		if ux.ClanID != "1987" {
+			t.Errorf("%s: clan: expected 1987, got %q", ux.UnitID, ux.ClanID)
+		}
+	}
+
+	// the same report in a game with 4-digit clans is rejected
+	_, err = adapters.BistreTurnToModelReportX("test", turn, "0301", "01987", model.DefaultUnitIDRules, nil)
+	var uerr *model.UnitIDError
+	if !errors.As(err, &uerr) {
+		t.Errorf("4-digit clans: expected *model.UnitIDError, got %v", err)
+	}
+}
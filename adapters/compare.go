@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/mdhender/tnrpt"
+)
+
+// TurnDiff is one difference between two adapted turns.
+// Path is a JSON-style path into the unit's moves, for example "moves[2].result".
+// A and B hold the JSON encoding of each side's value ("" if the value is missing).
+type TurnDiff struct {
+	UnitID string `json:"unitId"`
+	Path   string `json:"path"`
+	A      string `json:"a"`
+	B      string `json:"b"`
+}
+
+func (d TurnDiff) String() string {
+	path := d.Path
+	if path == "" {
+		path = "(unit)"
+	}
+	return fmt.Sprintf("%s: %s: %s != %s", d.UnitID, path, orMissing(d.A), orMissing(d.B))
+}
+
+// CompareTurns returns the differences between the units, moves, and observations
+// of two turns. It is used to compare the output of different parsers after both
+// have been adapted to the same model. Diffs are sorted by unit ID and then path.
+func CompareTurns(a, b *tnrpt.Turn_t) ([]TurnDiff, error) {
+	var diffs []TurnDiff
+	if a.Id != b.Id {
+		diffs = append(diffs, TurnDiff{Path: "turn-id", A: a.Id, B: b.Id})
+	}
+
+	units := map[tnrpt.UnitId_t]bool{}
+	for id := range a.UnitMoves {
+		units[id] = true
+	}
+	for id := range b.UnitMoves {
+		units[id] = true
+	}
+	for id := range units {
+		va, err := toJSONValue(a.UnitMoves[id])
+		if err != nil {
+			return nil, fmt.Errorf("unit %s: %w", id, err)
+		}
+		vb, err := toJSONValue(b.UnitMoves[id])
+		if err != nil {
+			return nil, fmt.Errorf("unit %s: %w", id, err)
+		}
+		diffJSON(string(id), "", va, vb, &diffs)
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].UnitID != diffs[j].UnitID {
+			return diffs[i].UnitID < diffs[j].UnitID
+		}
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs, nil
+}
+
+// toJSONValue round-trips v through JSON so that both sides are compared
+// as plain maps, slices, and scalars.
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func diffJSON(unitID, path string, a, b any, diffs *[]TurnDiff) {
+	switch va := a.(type) {
+	case map[string]any:
+		if vb, ok := b.(map[string]any); ok {
+			keys := map[string]bool{}
+			for k := range va {
+				keys[k] = true
+			}
+			for k := range vb {
+				keys[k] = true
+			}
+			for k := range keys {
+				diffJSON(unitID, joinPath(path, k), va[k], vb[k], diffs)
+			}
+			return
+		}
+	case []any:
+		if vb, ok := b.([]any); ok {
+			for i := 0; i < len(va) || i < len(vb); i++ {
+				var ea, eb any
+				if i < len(va) {
+					ea = va[i]
+				}
+				if i < len(vb) {
+					eb = vb[i]
+				}
+				diffJSON(unitID, fmt.Sprintf("%s[%d]", path, i), ea, eb, diffs)
+			}
+			return
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	*diffs = append(*diffs, TurnDiff{UnitID: unitID, Path: path, A: jsonString(a), B: jsonString(b)})
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func jsonString(v any) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func orMissing(s string) string {
+	if s == "" {
+		return "(missing)"
+	}
+	return s
+}
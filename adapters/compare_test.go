@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package adapters_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/results"
+)
+
+func TestCompareTurns(t *testing.T) {
+	newTurn := func() *tnrpt.Turn_t {
+		return &tnrpt.Turn_t{
+			Id: "0899-12",
+			UnitMoves: map[tnrpt.UnitId_t]*tnrpt.Moves_t{
+				"0987": {
+					UnitId:     "0987",
+					CurrentHex: "OO 0202",
+					Moves: []*tnrpt.Move_t{
+						{StepNo: 1, Result: results.Succeeded},
+						{StepNo: 2, Result: results.Succeeded},
+					},
+				},
+			},
+		}
+	}
+
+	a, b := newTurn(), newTurn()
+	diffs, err := adapters.CompareTurns(a, b)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	} else if len(diffs) != 0 {
+		t.Fatalf("identical turns: got %d diffs: %v", len(diffs), diffs)
+	}
+
+	b.UnitMoves["0987"].CurrentHex = "OO 0203"
+	b.UnitMoves["0987"].Moves = b.UnitMoves["0987"].Moves[:1]
+	b.UnitMoves["0987e1"] = &tnrpt.Moves_t{UnitId: "0987e1"}
+	diffs, err = adapters.CompareTurns(a, b)
+	if err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	want := []struct{ unitID, path string }{
+		{"0987", "current-hex"},
+		{"0987", "moves[1]"},
+		{"0987e1", ""},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("got %d diffs, want %d: %v", len(diffs), len(want), diffs)
+	}
+	for i, w := range want {
+		if diffs[i].UnitID != w.unitID || diffs[i].Path != w.path {
+			t.Errorf("diff %d: got %s %q, want %s %q", i, diffs[i].UnitID, diffs[i].Path, w.unitID, w.path)
+		}
+	}
+}
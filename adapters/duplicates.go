@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"fmt"
+
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+)
+
+// ResolveDuplicateUnits picks one section for each unit that appears more than
+// once in the report. The parser keeps the first section in turn.UnitMoves and
+// the rest in turn.Duplicates; the most complete section replaces the one in
+// UnitMoves, with ties going to the earlier section. Duplicates is cleared, so
+// calling this again does nothing.
+//
+// It returns one warning per dropped section, in report order.
+func ResolveDuplicateUnits(turn *bistre.Turn_t) []string {
+	if turn == nil || len(turn.Duplicates) == 0 {
+		return nil
+	}
+	var warnings []string
+	for _, dup := range turn.Duplicates {
+		kept, dropped := turn.UnitMoves[dup.UnitId], dup
+		if kept == nil {
+			turn.UnitMoves[dup.UnitId] = dup
+			continue
+		}
+		if sectionSize(dup) > sectionSize(kept) {
+			kept, dropped = dup, kept
+			turn.UnitMoves[dup.UnitId] = dup
+		}
+		warnings = append(warnings, fmt.Sprintf("unit %s: duplicate section: kept line %d (%d entries), dropped line %d (%d entries)",
+			dup.UnitId, kept.LineNo, sectionSize(kept), dropped.LineNo, sectionSize(dropped)))
+	}
+	turn.Duplicates = nil
+	return warnings
+}
+
+// sectionSize counts the moves, scout moves, and scries in a unit's section.
+func sectionSize(m *bistre.Moves_t) int {
+	n := len(m.Moves) + len(m.Scries)
+	for _, scout := range m.Scouts {
+		n += len(scout.Moves)
+	}
+	if m.Follows != "" || m.GoesTo != "" {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package adapters
+
+import (
+	"strings"
+
+	"github.com/mdhender/tnrpt"
+)
+
+// UnitFilter selects units by ID for the parse and walk commands.
+//
+// Each pattern is either an exact unit ID ("0987e1") or a prefix ending
+// in "*" ("0987*" matches the tribe 0987 and all of its couriers, elements,
+// fleets, and garrisons). Matching is case-insensitive.
+//
+// A unit is kept if it matches any Include pattern (or Include is empty)
+// and does not match any Exclude pattern. Exclude wins over Include.
+type UnitFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// IsEmpty returns true if the filter keeps every unit.
+func (f UnitFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Match returns true if the unit should be kept.
+func (f UnitFilter) Match(unitID string) bool {
+	for _, pattern := range f.Exclude {
+		if matchUnitPattern(pattern, unitID) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if matchUnitPattern(pattern, unitID) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTurnUnits removes the units that don't match the filter from the turn.
+// It returns the number of units removed.
+func FilterTurnUnits(t *tnrpt.Turn_t, f UnitFilter) int {
+	if t == nil || f.IsEmpty() {
+		return 0
+	}
+	removed := 0
+	for id := range t.UnitMoves {
+		if !f.Match(string(id)) {
+			delete(t.UnitMoves, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+func matchUnitPattern(pattern, unitID string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return len(unitID) >= len(prefix) && strings.EqualFold(unitID[:len(prefix)], prefix)
+	}
+	return strings.EqualFold(pattern, unitID)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package adapters_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/adapters"
+)
+
+func TestUnitFilterMatch(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		filter adapters.UnitFilter
+		unitID string
+		want   bool
+	}{
+		{"empty keeps all", adapters.UnitFilter{}, "0987e1", true},
+		{"exact include", adapters.UnitFilter{Include: []string{"0987e1"}}, "0987e1", true},
+		{"exact include miss", adapters.UnitFilter{Include: []string{"0987e1"}}, "0987e2", false},
+		{"case insensitive", adapters.UnitFilter{Include: []string{"0987E1"}}, "0987e1", true},
+		{"prefix include", adapters.UnitFilter{Include: []string{"0987*"}}, "0987c1", true},
+		{"prefix include tribe", adapters.UnitFilter{Include: []string{"0987*"}}, "0987", true},
+		{"prefix include miss", adapters.UnitFilter{Include: []string{"0987*"}}, "1987", false},
+		{"exclude", adapters.UnitFilter{Exclude: []string{"0987f*"}}, "0987f1", false},
+		{"exclude miss", adapters.UnitFilter{Exclude: []string{"0987f*"}}, "0987e1", true},
+		{"exclude wins", adapters.UnitFilter{Include: []string{"0987*"}, Exclude: []string{"0987c1"}}, "0987c1", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.Match(tc.unitID); got != tc.want {
+				t.Errorf("Match(%q) = %v, want %v", tc.unitID, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFilterTurnUnits(t *testing.T) {
+	turn := &tnrpt.Turn_t{
+		UnitMoves: map[tnrpt.UnitId_t]*tnrpt.Moves_t{
+			"0987":   {UnitId: "0987"},
+			"0987e1": {UnitId: "0987e1"},
+			"0987f1": {UnitId: "0987f1"},
+		},
+	}
+	removed := adapters.FilterTurnUnits(turn, adapters.UnitFilter{Include: []string{"0987*"}, Exclude: []string{"0987f*"}})
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if _, ok := turn.UnitMoves["0987f1"]; ok {
+		t.Errorf("0987f1 was not removed")
+	}
+	if len(turn.UnitMoves) != 2 {
+		t.Errorf("len(UnitMoves) = %d, want 2", len(turn.UnitMoves))
+	}
+}
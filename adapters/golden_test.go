@@ -133,7 +133,7 @@ func TestBistreToModel_Golden(t *testing.T) {
 				t.Fatalf("parse: %v", err)
 			}
 
-			rf, rx, err := adapters.BistreToModel("<test-input>", pt)
+			rf, rx, err := adapters.BistreToModel("<test-input>", pt, nil)
 			if err != nil {
 				t.Fatalf("BistreToModel: %v", err)
 			}
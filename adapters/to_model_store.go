@@ -5,9 +5,12 @@ package adapters
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/mdhender/tnrpt/cerrs"
 	"github.com/mdhender/tnrpt/direction"
 	"github.com/mdhender/tnrpt/model"
 	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
@@ -17,69 +20,135 @@ import (
 // ParseStore defines the minimal store interface needed for parsing operations.
 type ParseStore interface {
 	InsertReportFile(ctx context.Context, rf *model.ReportFile) (int64, error)
+	ParseStoreMinimal
+}
+
+// ParseStoreMinimal defines the minimal store interface for parsing when ReportFile already exists.
+type ParseStoreMinimal interface {
 	InsertReportExtract(ctx context.Context, rx *model.ReportX) (int64, error)
+	UnitStore
+}
+
+// UnitStore writes one unit's rows: the unit extract, its acts, and their steps.
+type UnitStore interface {
 	InsertUnitExtract(ctx context.Context, ux *model.UnitX) (int64, error)
 	InsertAct(ctx context.Context, act *model.Act) (int64, error)
 	InsertStep(ctx context.Context, step *model.Step) (int64, error)
 }
 
-// BistreTurnToStore converts a bistre.Turn_t to model types and persists them via Store.
-// Returns the ReportFile ID and ReportX ID that were inserted.
-func BistreTurnToStore(ctx context.Context, store ParseStore, source string, turn *bistre.Turn_t, game, clanNo string) (int64, int64, error) {
-	now := time.Now().UTC()
-	turnNo := 100*turn.Year + turn.Month
+// UnitTxStore is implemented by stores that can write a unit's rows in a
+// transaction. WithUnitTx keeps the rows fn wrote only if fn succeeds.
+type UnitTxStore interface {
+	WithUnitTx(ctx context.Context, fn func(UnitStore) error) error
+}
+
+// Options controls how Persist and PersistWithReportFile convert and store a parsed turn.
+type Options struct {
+	// Now returns the timestamp recorded on created rows.
+	// If nil, time.Now().UTC() is used.
+	Now func() time.Time
+
+	// Content is the original report file bytes.
+	// Persist records the SHA256 of the content on the report file row.
+	Content []byte
+
+	// SHA256 is a precomputed hex-encoded hash of the original report file bytes.
+	// If set, it is used instead of hashing Content.
+	SHA256 string
+
+	// Mime is recorded on the report file row.
+	// If empty, "application/octet-stream" is used.
+	Mime string
+
+	// Sorted persists units in unit ID order so that row IDs are reproducible.
+	// If false, units are persisted in map iteration order.
+	Sorted bool
+
+	// Partial keeps persisting the remaining units when one unit fails.
+	// The failures are returned in Result.Errors instead of stopping the import.
+	// If the store is a UnitTxStore, a unit that fails leaves no rows behind;
+	// otherwise the rows written before the failure are kept.
+	Partial bool
+
+	// UnitIDs are the game's unit ID rules. Units with malformed IDs are
+	// rejected with a *model.UnitIDError. The zero value is the standard rules.
+	UnitIDs model.UnitIDRules
+}
+
+// Result describes the rows written by Persist and PersistWithReportFile.
+type Result struct {
+	ReportFileID int64
+	ReportXID    int64
+	Units        int      // number of units persisted
+	Errors       []error  // unit failures, only populated when Options.Partial is set
+	Warnings     []string // duplicate unit sections that were dropped; see ResolveDuplicateUnits
+}
+
+func (o Options) now() time.Time {
+	if o.Now == nil {
+		return time.Now().UTC()
+	}
+	return o.Now()
+}
+
+// Persist converts a bistre.Turn_t to model types and writes them to the store,
+// creating a new ReportFile row for the source.
+// Either Options.Content or Options.SHA256 must be set so that the row records
+// the hash of the report file contents, matching the upload and ingest paths.
+//
+// Errors carry a cerrs.Code: INVALID_INPUT for a bad call, UNIT_ID_INVALID for
+// a unit the game doesn't allow, and the store's code for a failed write.
+//
+// This is the entry point for Go programs that embed report ingestion.
+// A typical caller parses the report with bistre.ParseInput and then calls
+//
+//	res, err := adapters.Persist(ctx, store, name, turn, game, clanNo, adapters.Options{Content: data, Sorted: true})
+func Persist(ctx context.Context, store ParseStore, source string, turn *bistre.Turn_t, game, clanNo string, opts Options) (*Result, error) {
+	if turn == nil {
+		return nil, cerrs.New(cerrs.CodeInvalidInput, "turn is nil")
+	}
+
+	mime := opts.Mime
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	hash := opts.SHA256
+	if hash == "" {
+		if opts.Content == nil {
+			return nil, cerrs.New(cerrs.CodeInvalidInput, source+": missing content or sha256")
+		}
+		hash = computeSHA256(opts.Content)
+	}
 
-	// Insert ReportFile
 	rf := &model.ReportFile{
 		Game:      game,
 		ClanNo:    clanNo,
-		TurnNo:    turnNo,
+		TurnNo:    model.NewTurnNo(turn.Year, turn.Month),
 		Name:      source,
-		SHA256:    computeSHA256(source),
-		Mime:      "application/octet-stream",
-		CreatedAt: now,
+		SHA256:    hash,
+		Mime:      mime,
+		CreatedAt: opts.now(),
 	}
 	rfID, err := store.InsertReportFile(ctx, rf)
 	if err != nil {
-		return 0, 0, fmt.Errorf("insert report file: %w", err)
+		return nil, fmt.Errorf("insert report file: %w", err)
 	}
+	rf.ID = rfID
 
-	// Insert ReportExtract
-	rx := &model.ReportX{
-		ReportFileID: rfID,
-		Game:         game,
-		ClanNo:       clanNo,
-		TurnNo:       turnNo,
-		CreatedAt:    now,
-	}
-	rxID, err := store.InsertReportExtract(ctx, rx)
-	if err != nil {
-		return 0, 0, fmt.Errorf("insert report extract: %w", err)
-	}
-
-	// Convert and insert each unit's moves
-	for unitId, moves := range turn.UnitMoves {
-		if err := insertUnitMoves(ctx, store, rxID, rfID, turnNo, unitId, moves); err != nil {
-			return 0, 0, fmt.Errorf("insert unit %s: %w", unitId, err)
-		}
-	}
-
-	return rfID, rxID, nil
+	return PersistWithReportFile(ctx, store, rf, turn, opts)
 }
 
-// ParseStoreMinimal defines the minimal store interface for parsing when ReportFile already exists.
-type ParseStoreMinimal interface {
-	InsertReportExtract(ctx context.Context, rx *model.ReportX) (int64, error)
-	InsertUnitExtract(ctx context.Context, ux *model.UnitX) (int64, error)
-	InsertAct(ctx context.Context, act *model.Act) (int64, error)
-	InsertStep(ctx context.Context, step *model.Step) (int64, error)
-}
-
-// BistreTurnToStoreWithReportFile converts a bistre.Turn_t to model types and persists them,
-// using an existing ReportFile. Returns the ReportX ID that was inserted.
-func BistreTurnToStoreWithReportFile(ctx context.Context, store ParseStoreMinimal, rf *model.ReportFile, turn *bistre.Turn_t) (int64, error) {
-	now := time.Now().UTC()
-	turnNo := 100*turn.Year + turn.Month
+// PersistWithReportFile converts a bistre.Turn_t to model types and writes them to the store,
+// attaching the extract to an existing ReportFile. Options.Content and Options.Mime are ignored.
+// Units with more than one section in the report are stored once, and the
+// dropped sections are listed in Result.Warnings. Each unit is written in its
+// own transaction if the store is a UnitTxStore.
+func PersistWithReportFile(ctx context.Context, store ParseStoreMinimal, rf *model.ReportFile, turn *bistre.Turn_t, opts Options) (*Result, error) {
+	if turn == nil {
+		return nil, cerrs.New(cerrs.CodeInvalidInput, "turn is nil")
+	}
+	turnNo := model.NewTurnNo(turn.Year, turn.Month)
+	warnings := ResolveDuplicateUnits(turn)
 
 	// Insert ReportExtract
 	rx := &model.ReportX{
@@ -87,171 +156,84 @@ func BistreTurnToStoreWithReportFile(ctx context.Context, store ParseStoreMinima
 		Game:         rf.Game,
 		ClanNo:       rf.ClanNo,
 		TurnNo:       turnNo,
-		CreatedAt:    now,
+		Season:       turn.Season,
+		Weather:      turn.Weather,
+		CreatedAt:    opts.now(),
 	}
 	rxID, err := store.InsertReportExtract(ctx, rx)
 	if err != nil {
-		return 0, fmt.Errorf("insert report extract: %w", err)
+		return nil, fmt.Errorf("insert report extract: %w", err)
 	}
 
-	// Convert and insert each unit's moves
-	for unitId, moves := range turn.UnitMoves {
-		if err := insertUnitMovesMinimal(ctx, store, rxID, rf.ID, turnNo, unitId, moves); err != nil {
-			return 0, fmt.Errorf("insert unit %s: %w", unitId, err)
-		}
+	unitIds := make([]bistre.UnitId_t, 0, len(turn.UnitMoves))
+	for unitId := range turn.UnitMoves {
+		unitIds = append(unitIds, unitId)
 	}
-
-	return rxID, nil
-}
-
-func insertUnitMoves(ctx context.Context, store ParseStore, rxID, rfID int64, turnNo int, unitId bistre.UnitId_t, moves *bistre.Moves_t) error {
-	ux := &model.UnitX{
-		ReportXID: rxID,
-		UnitID:    string(unitId),
-		ClanID:    extractClanID(string(unitId)),
-		TurnNo:    turnNo,
-		StartTN:   model.TNCoord(moves.PreviousHex),
-		EndTN:     model.TNCoord(moves.CurrentHex),
-		Src: &model.SrcRef{
-			DocID:  rfID,
-			UnitID: string(unitId),
-			TurnNo: turnNo,
-		},
-	}
-
-	uxID, err := store.InsertUnitExtract(ctx, ux)
-	if err != nil {
-		return err
-	}
-
-	actSeq := 0
-
-	// Handle follows
-	if moves.Follows != "" {
-		actSeq++
-		act := &model.Act{
-			UnitXID:      uxID,
-			Seq:          actSeq,
-			Kind:         model.ActKindFollow,
-			Ok:           true,
-			TargetUnitID: string(moves.Follows),
-			Src: &model.SrcRef{
-				DocID:  rfID,
-				UnitID: string(unitId),
-				TurnNo: turnNo,
-				ActSeq: actSeq,
-			},
-		}
-		if _, err := store.InsertAct(ctx, act); err != nil {
-			return err
-		}
+	if opts.Sorted {
+		sort.Slice(unitIds, func(i, j int) bool {
+			return unitIds[i] < unitIds[j]
+		})
 	}
 
-	// Handle goes-to
-	if moves.GoesTo != "" {
-		actSeq++
-		act := &model.Act{
-			UnitXID: uxID,
-			Seq:     actSeq,
-			Kind:    model.ActKindGoto,
-			Ok:      true,
-			DestTN:  model.TNCoord(moves.GoesTo),
-			Src: &model.SrcRef{
-				DocID:  rfID,
-				UnitID: string(unitId),
-				TurnNo: turnNo,
-				ActSeq: actSeq,
-			},
-		}
-		if _, err := store.InsertAct(ctx, act); err != nil {
-			return err
+	// Convert and insert each unit's moves
+	result := &Result{ReportFileID: rf.ID, ReportXID: rxID, Warnings: warnings}
+	txs, _ := store.(UnitTxStore)
+	for _, unitId := range unitIds {
+		insert := func(us UnitStore) error {
+			return insertUnitMoves(ctx, us, opts.UnitIDs, rxID, rf.ID, turnNo, unitId, turn.UnitMoves[unitId])
 		}
-	}
-
-	// Handle regular moves
-	if len(moves.Moves) > 0 && moves.Follows == "" && moves.GoesTo == "" {
-		actSeq++
-		act := &model.Act{
-			UnitXID: uxID,
-			Seq:     actSeq,
-			Kind:    model.ActKindMove,
-			Ok:      true,
-			Src: &model.SrcRef{
-				DocID:  rfID,
-				UnitID: string(unitId),
-				TurnNo: turnNo,
-				ActSeq: actSeq,
-			},
+		var err error
+		if txs != nil {
+			err = txs.WithUnitTx(ctx, insert)
+		} else {
+			err = insert(store)
 		}
-
-		actID, err := store.InsertAct(ctx, act)
 		if err != nil {
-			return err
-		}
-
-		stepSeq := 0
-		for _, mv := range moves.Moves {
-			stepSeq++
-			step := adaptBistreMove(mv, actID, stepSeq)
-			step.Src = &model.SrcRef{
-				DocID:   rfID,
-				UnitID:  string(unitId),
-				TurnNo:  turnNo,
-				ActSeq:  actSeq,
-				StepSeq: stepSeq,
-			}
-			if _, err := store.InsertStep(ctx, step); err != nil {
-				return err
+			if !opts.Partial {
+				return nil, fmt.Errorf("insert unit %s: %w", unitId, err)
 			}
+			result.Errors = append(result.Errors, fmt.Errorf("insert unit %s: %w", unitId, err))
+			continue
 		}
+		result.Units++
 	}
 
-	// Handle scouts
-	for _, scout := range moves.Scouts {
-		actSeq++
-		act := &model.Act{
-			UnitXID: uxID,
-			Seq:     actSeq,
-			Kind:    model.ActKindScout,
-			Ok:      true,
-			Src: &model.SrcRef{
-				DocID:  rfID,
-				UnitID: string(unitId),
-				TurnNo: turnNo,
-				ActSeq: actSeq,
-			},
-		}
-
-		actID, err := store.InsertAct(ctx, act)
-		if err != nil {
-			return err
-		}
+	return result, nil
+}
 
-		stepSeq := 0
-		for _, mv := range scout.Moves {
-			stepSeq++
-			step := adaptBistreMove(mv, actID, stepSeq)
-			step.Src = &model.SrcRef{
-				DocID:   rfID,
-				UnitID:  string(unitId),
-				TurnNo:  turnNo,
-				ActSeq:  actSeq,
-				StepSeq: stepSeq,
-			}
-			if _, err := store.InsertStep(ctx, step); err != nil {
-				return err
-			}
-		}
+// BistreTurnToStore converts a bistre.Turn_t to model types and persists them via Store.
+// The data is the original report file, used to compute the content hash.
+// Returns the ReportFile ID and ReportX ID that were inserted.
+// New code should call Persist.
+func BistreTurnToStore(ctx context.Context, store ParseStore, source string, data []byte, turn *bistre.Turn_t, game, clanNo string) (int64, int64, error) {
+	res, err := Persist(ctx, store, source, turn, game, clanNo, Options{Content: data})
+	if err != nil {
+		return 0, 0, err
 	}
+	return res.ReportFileID, res.ReportXID, nil
+}
 
-	return nil
+// BistreTurnToStoreWithReportFile converts a bistre.Turn_t to model types and persists them,
+// using an existing ReportFile. Returns the ReportX ID that was inserted.
+// New code should call PersistWithReportFile.
+func BistreTurnToStoreWithReportFile(ctx context.Context, store ParseStoreMinimal, rf *model.ReportFile, turn *bistre.Turn_t, ids model.UnitIDRules) (int64, error) {
+	res, err := PersistWithReportFile(ctx, store, rf, turn, Options{UnitIDs: ids})
+	if err != nil {
+		return 0, err
+	}
+	return res.ReportXID, nil
 }
 
-func insertUnitMovesMinimal(ctx context.Context, store ParseStoreMinimal, rxID, rfID int64, turnNo int, unitId bistre.UnitId_t, moves *bistre.Moves_t) error {
+func insertUnitMoves(ctx context.Context, store UnitStore, ids model.UnitIDRules, rxID, rfID int64, turnNo model.TurnNo, unitId bistre.UnitId_t, moves *bistre.Moves_t) error {
+	clanID, err := ids.ClanID(string(unitId))
+	if err != nil {
+		return err
+	}
 	ux := &model.UnitX{
 		ReportXID: rxID,
 		UnitID:    string(unitId),
-		ClanID:    extractClanID(string(unitId)),
+		ClanID:    clanID,
+		Kind:      ids.Kind(string(unitId)),
 		TurnNo:    turnNo,
 		StartTN:   model.TNCoord(moves.PreviousHex),
 		EndTN:     model.TNCoord(moves.CurrentHex),
@@ -259,6 +241,7 @@ func insertUnitMovesMinimal(ctx context.Context, store ParseStoreMinimal, rxID,
 			DocID:  rfID,
 			UnitID: string(unitId),
 			TurnNo: turnNo,
+			Line:   moves.LineNo,
 		},
 	}
 
@@ -283,6 +266,7 @@ func insertUnitMovesMinimal(ctx context.Context, store ParseStoreMinimal, rxID,
 				UnitID: string(unitId),
 				TurnNo: turnNo,
 				ActSeq: actSeq,
+				Line:   moveLine(moves.Moves, func(mv *bistre.Move_t) bool { return mv.Follows != "" }),
 			},
 		}
 		if _, err := store.InsertAct(ctx, act); err != nil {
@@ -304,6 +288,7 @@ func insertUnitMovesMinimal(ctx context.Context, store ParseStoreMinimal, rxID,
 				UnitID: string(unitId),
 				TurnNo: turnNo,
 				ActSeq: actSeq,
+				Line:   moveLine(moves.Moves, func(mv *bistre.Move_t) bool { return mv.GoesTo != "" }),
 			},
 		}
 		if _, err := store.InsertAct(ctx, act); err != nil {
@@ -324,6 +309,7 @@ func insertUnitMovesMinimal(ctx context.Context, store ParseStoreMinimal, rxID,
 				UnitID: string(unitId),
 				TurnNo: turnNo,
 				ActSeq: actSeq,
+				Line:   moves.Moves[0].LineNo,
 			},
 		}
 
@@ -337,11 +323,13 @@ func insertUnitMovesMinimal(ctx context.Context, store ParseStoreMinimal, rxID,
 			stepSeq++
 			step := adaptBistreMove(mv, actID, stepSeq)
 			step.Src = &model.SrcRef{
-				DocID:   rfID,
-				UnitID:  string(unitId),
-				TurnNo:  turnNo,
-				ActSeq:  actSeq,
-				StepSeq: stepSeq,
+				DocID:    rfID,
+				UnitID:   string(unitId),
+				TurnNo:   turnNo,
+				ActSeq:   actSeq,
+				StepSeq:  stepSeq,
+				Line:     mv.LineNo,
+				LineStep: mv.StepNo,
 			}
 			if _, err := store.InsertStep(ctx, step); err != nil {
 				return err
@@ -362,6 +350,7 @@ func insertUnitMovesMinimal(ctx context.Context, store ParseStoreMinimal, rxID,
 				UnitID: string(unitId),
 				TurnNo: turnNo,
 				ActSeq: actSeq,
+				Line:   scout.LineNo,
 			},
 		}
 
@@ -375,11 +364,13 @@ func insertUnitMovesMinimal(ctx context.Context, store ParseStoreMinimal, rxID,
 			stepSeq++
 			step := adaptBistreMove(mv, actID, stepSeq)
 			step.Src = &model.SrcRef{
-				DocID:   rfID,
-				UnitID:  string(unitId),
-				TurnNo:  turnNo,
-				ActSeq:  actSeq,
-				StepSeq: stepSeq,
+				DocID:    rfID,
+				UnitID:   string(unitId),
+				TurnNo:   turnNo,
+				ActSeq:   actSeq,
+				StepSeq:  stepSeq,
+				Line:     mv.LineNo,
+				LineStep: mv.StepNo,
 			}
 			if _, err := store.InsertStep(ctx, step); err != nil {
 				return err
@@ -404,7 +395,8 @@ func adaptBistreMove(mv *bistre.Move_t, actID int64, seq int) *model.Step {
 		step.Kind = model.StepKindAdv
 		step.Dir = mv.Advance.String()
 		if !step.Ok {
-			step.FailWhy = bistreResultToFailWhy(mv.Result)
+			step.FailWhy = bistreResultToFailWhy(mv.Result, mv.FailPhrase)
+			step.FailRaw = mv.FailPhrase
 		}
 	} else {
 		step.Kind = model.StepKindObs
@@ -456,32 +448,25 @@ func adaptBistreMove(mv *bistre.Move_t, actID int64, seq int) *model.Step {
 	return step
 }
 
-func bistreResultToFailWhy(r results.Result_e) string {
+func bistreResultToFailWhy(r results.Result_e, phrase string) string {
+	if phrase != "" {
+		return string(model.FailReasonOf(phrase))
+	}
 	switch r {
 	case results.Blocked:
-		return "blocked"
+		return string(model.FailBlockedByUnit)
 	case results.ExhaustedMovementPoints:
-		return "exhaust"
+		return string(model.FailExhausted)
 	case results.Prohibited:
-		return "terrain"
+		return string(model.FailProhibited)
 	case results.Failed:
-		return "unknown"
+		return string(model.FailUnknown)
 	default:
 		return ""
 	}
 }
 
-func computeSHA256(s string) string {
-	h := sha256.Sum256([]byte(s))
-	return fmt.Sprintf("%x", h)
-}
-
-// extractClanID extracts the clan ID from a unit ID.
-// Unit IDs are formatted as "CCCC" (tribal unit) or "CCCCsN" (sub-unit).
-// Returns the first 4 characters as the clan ID.
-func extractClanID(unitID string) string {
-	if len(unitID) >= 4 {
-		return unitID[:4]
-	}
-	return unitID
+func computeSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
 }
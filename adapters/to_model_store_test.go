@@ -0,0 +1,216 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package adapters_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+)
+
+// recordingStore is a ParseStore that keeps rows in memory.
+type recordingStore struct {
+	nextID      int64
+	reportFiles []*model.ReportFile
+	reportXs    []*model.ReportX
+	units       []*model.UnitX
+	failUnit    string
+}
+
+func (s *recordingStore) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *recordingStore) InsertReportFile(ctx context.Context, rf *model.ReportFile) (int64, error) {
+	s.reportFiles = append(s.reportFiles, rf)
+	return s.id(), nil
+}
+
+func (s *recordingStore) InsertReportExtract(ctx context.Context, rx *model.ReportX) (int64, error) {
+	s.reportXs = append(s.reportXs, rx)
+	return s.id(), nil
+}
+
+func (s *recordingStore) InsertUnitExtract(ctx context.Context, ux *model.UnitX) (int64, error) {
+	if ux.UnitID == s.failUnit {
+		return 0, fmt.Errorf("forced failure")
+	}
+	s.units = append(s.units, ux)
+	return s.id(), nil
+}
+
+func (s *recordingStore) InsertAct(ctx context.Context, act *model.Act) (int64, error) {
+	return s.id(), nil
+}
+
+func (s *recordingStore) InsertStep(ctx context.Context, step *model.Step) (int64, error) {
+	return s.id(), nil
+}
+
+func newTestTurn(unitIds ...bistre.UnitId_t) *bistre.Turn_t {
+	turn := &bistre.Turn_t{Id: "0899-12", Year: 899, Month: 12, UnitMoves: map[bistre.UnitId_t]*bistre.Moves_t{}}
+	for _, id := range unitIds {
+		turn.UnitMoves[id] = &bistre.Moves_t{TurnId: turn.Id, UnitId: id, PreviousHex: "AA 0101", CurrentHex: "AA 0102"}
+	}
+	return turn
+}
+
+func TestPersist_Options(t *testing.T) {
+	ctx := context.Background()
+	store := &recordingStore{}
+	clock := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	content := []byte("report content")
+
+	res, err := adapters.Persist(ctx, store, "0987.report.txt", newTestTurn("0987f1", "0987", "0987e1", "0987c1"), "0301", "0987", adapters.Options{
+		Now:     func() time.Time { return clock },
+		Content: content,
+		Mime:    "text/plain",
+		Sorted:  true,
+	})
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if res.Units != 4 {
+		t.Errorf("units: expected 4, got %d", res.Units)
+	}
+
+	rf := store.reportFiles[0]
+	if want := fmt.Sprintf("%x", sha256.Sum256(content)); rf.SHA256 != want {
+		t.Errorf("sha256: expected %s, got %s", want, rf.SHA256)
+	}
+	if rf.Mime != "text/plain" {
+		t.Errorf("mime: expected text/plain, got %q", rf.Mime)
+	}
+	if !rf.CreatedAt.Equal(clock) || !store.reportXs[0].CreatedAt.Equal(clock) {
+		t.Errorf("created at: expected %v", clock)
+	}
+	if rf.TurnNo != 89912 {
+		t.Errorf("turn: expected 89912, got %d", rf.TurnNo)
+	}
+
+	want := []string{"0987", "0987c1", "0987e1", "0987f1"}
+	for i, ux := range store.units {
+		if ux.UnitID != want[i] {
+			t.Errorf("unit %d: expected %s, got %s", i, want[i], ux.UnitID)
+		}
+	}
+}
+
+func TestPersist_Partial(t *testing.T) {
+	ctx := context.Background()
+
+	store := &recordingStore{failUnit: "0987e1"}
+	if _, err := adapters.Persist(ctx, store, "src", newTestTurn("0987", "0987e1"), "0301", "0987", adapters.Options{SHA256: "abc"}); err == nil {
+		t.Fatalf("expected error without partial mode")
+	}
+
+	store = &recordingStore{failUnit: "0987e1"}
+	res, err := adapters.Persist(ctx, store, "src", newTestTurn("0987", "0987e1", "0987f1"), "0301", "0987", adapters.Options{SHA256: "abc", Partial: true})
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if res.Units != 2 || len(res.Errors) != 1 {
+		t.Errorf("expected 2 units and 1 error, got %d units and %d errors", res.Units, len(res.Errors))
+	}
+}
+
+func TestPersist_UnitIDs(t *testing.T) {
+	ctx := context.Background()
+
+	store := &recordingStore{}
+	res, err := adapters.Persist(ctx, store, "src", newTestTurn("0987", "1987e1", "09871", "0987x1"), "0301", "0987", adapters.Options{SHA256: "abc", Sorted: true, Partial: true})
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if res.Units != 2 || len(res.Errors) != 2 {
+		t.Fatalf("expected 2 units and 2 errors, got %d units and %d errors", res.Units, len(res.Errors))
+	}
+	for _, err := range res.Errors {
+		var uerr *model.UnitIDError
+		if !errors.As(err, &uerr) {
+			t.Errorf("expected *model.UnitIDError, got %v", err)
+		}
+	}
+	for _, ux := range store.units {
+		if ux.ClanID != "987" {
+			t.Errorf("%s: clan: expected 987, got %q", ux.UnitID, ux.ClanID)
+		}
+	}
+
+	store = &recordingStore{}
+	res, err = adapters.Persist(ctx, store, "src", newTestTurn("09871", "09871e1"), "0301", "9871", adapters.Options{SHA256: "abc", UnitIDs: model.UnitIDRules{ClanDigits: 5}})
+	if err != nil {
+		t.Fatalf("persist: 5-digit clans: %v", err)
+	}
+	if res.Units != 2 || store.units[0].ClanID != "9871" {
+		t.Errorf("5-digit clans: expected 2 units in clan 9871, got %d units", res.Units)
+	}
+}
+
+func TestPersist_DuplicateUnits(t *testing.T) {
+	ctx := context.Background()
+
+	turn := newTestTurn("0987", "0987e1")
+	turn.UnitMoves["0987"].LineNo = 1
+	turn.UnitMoves["0987e1"].LineNo = 5
+	turn.UnitMoves["0987e1"].Moves = []*bistre.Move_t{{UnitId: "0987e1", Still: true}}
+	turn.Duplicates = []*bistre.Moves_t{
+		{TurnId: turn.Id, UnitId: "0987", LineNo: 10, Moves: []*bistre.Move_t{{UnitId: "0987", Still: true}}, CurrentHex: "AA 0102"},
+		{TurnId: turn.Id, UnitId: "0987e1", LineNo: 20, CurrentHex: "AA 0102"},
+	}
+
+	store := &recordingStore{}
+	res, err := adapters.Persist(ctx, store, "src", turn, "0301", "0987", adapters.Options{SHA256: "abc", Sorted: true})
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if res.Units != 2 || len(store.units) != 2 {
+		t.Fatalf("expected 2 units, got %d units and %d rows", res.Units, len(store.units))
+	}
+	want := []string{
+		"unit 0987: duplicate section: kept line 10 (1 entries), dropped line 1 (0 entries)",
+		"unit 0987e1: duplicate section: kept line 5 (1 entries), dropped line 20 (0 entries)",
+	}
+	if len(res.Warnings) != len(want) {
+		t.Fatalf("warnings: expected %q, got %q", want, res.Warnings)
+	}
+	for i := range want {
+		if res.Warnings[i] != want[i] {
+			t.Errorf("warning %d: expected %q, got %q", i, want[i], res.Warnings[i])
+		}
+	}
+	if turn.UnitMoves["0987"].LineNo != 10 || turn.UnitMoves["0987e1"].LineNo != 5 {
+		t.Errorf("kept the wrong sections: 0987 line %d, 0987e1 line %d", turn.UnitMoves["0987"].LineNo, turn.UnitMoves["0987e1"].LineNo)
+	}
+	if turn.Duplicates != nil {
+		t.Errorf("expected duplicates to be cleared, got %d", len(turn.Duplicates))
+	}
+}
+
+func TestPersist_RequiresContentHash(t *testing.T) {
+	ctx := context.Background()
+
+	store := &recordingStore{}
+	if _, err := adapters.Persist(ctx, store, "src", newTestTurn("0987"), "0301", "0987", adapters.Options{}); err == nil {
+		t.Fatalf("expected error when neither content nor sha256 is set")
+	}
+	if len(store.reportFiles) != 0 {
+		t.Errorf("expected no report file rows, got %d", len(store.reportFiles))
+	}
+
+	store = &recordingStore{}
+	if _, err := adapters.Persist(ctx, store, "src", newTestTurn("0987"), "0301", "0987", adapters.Options{Content: []byte("ignored"), SHA256: "precomputed"}); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if got := store.reportFiles[0].SHA256; got != "precomputed" {
+		t.Errorf("sha256: expected precomputed hash, got %q", got)
+	}
+}
@@ -1,6 +1,9 @@
 // Copyright (c) 2024 Michael D Henderson. All rights reserved.
 
-// Package cerrs implements constant errors.
+// Package cerrs implements constant errors, and the error codes shared by the
+// pipeline stages, the adapters, the store, and the handlers, so that a
+// failure is reported with the same code in the work table, the logs, and
+// API responses.
 package cerrs
 
 // Error defines a constant error
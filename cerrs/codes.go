@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package cerrs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"net/http"
+)
+
+// Code identifies a kind of failure. Codes are stored in work.error_code and
+// returned to API clients, so they must not change once released.
+type Code string
+
+const (
+	CodeUnknown       Code = "UNKNOWN"
+	CodeInvalidInput  Code = "INVALID_INPUT"
+	CodeNotFound      Code = "NOT_FOUND"
+	CodeConflict      Code = "CONFLICT"      // a row with the same key exists
+	CodeDatabase      Code = "DATABASE"      // any other database failure
+	CodeDatabaseBusy  Code = "DATABASE_BUSY" // the database was locked; try again
+	CodeWriteFile     Code = "WRITE_FILE"
+	CodeDocxCorrupt   Code = "DOCX_CORRUPT"
+	CodeParseSyntax   Code = "PARSE_SYNTAX_ERROR"
+	CodeUnitIDInvalid Code = "UNIT_ID_INVALID"
+	CodeChecksum      Code = "CHECKSUM_MISMATCH"
+	CodeClanMismatch  Code = "CLAN_MISMATCH" // a report's units belong to another clan than the one it was filed under
+	CodeCanceled      Code = "CANCELED"
+	CodeTimeout       Code = "TIMEOUT"
+)
+
+// Coder is implemented by errors that know their code.
+type Coder interface {
+	ErrorCode() Code
+}
+
+// CodedError adds a code to an error that doesn't have one.
+type CodedError struct {
+	Code Code
+	Op   string // what was being done, e.g., "insert report_file"
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	if e.Op == "" {
+		return e.Err.Error()
+	}
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *CodedError) ErrorCode() Code {
+	return e.Code
+}
+
+// New returns an error with the code and message.
+func New(code Code, msg string) error {
+	return &CodedError{Code: code, Err: errors.New(msg)}
+}
+
+// Wrap returns err with the code and op added, or nil if err is nil.
+func Wrap(code Code, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Op: op, Err: err}
+}
+
+// CodeOf returns the code of the outermost error in err's chain that has
+// one, so wrapping an error with fmt.Errorf and %w keeps its code.
+// Errors without a code are classified by the standard sentinels they wrap,
+// and are CodeUnknown otherwise. CodeOf returns "" for a nil error.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, fs.ErrNotExist):
+		return CodeNotFound
+	}
+	return CodeUnknown
+}
+
+// HTTPStatus returns the status code an API response for code should use.
+func HTTPStatus(code Code) int {
+	switch code {
+	case CodeInvalidInput, CodeDocxCorrupt, CodeParseSyntax, CodeUnitIDInvalid, CodeClanMismatch:
+		return http.StatusBadRequest
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeConflict:
+		return http.StatusConflict
+	case CodeDatabaseBusy:
+		return http.StatusServiceUnavailable
+	case CodeTimeout:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package cerrs_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/mdhender/tnrpt/cerrs"
+)
+
+func TestCodeOf(t *testing.T) {
+	busy := cerrs.Wrap(cerrs.CodeDatabaseBusy, "insert work", errors.New("database is locked"))
+	for _, tc := range []struct {
+		name string
+		err  error
+		want cerrs.Code
+	}{
+		{"nil", nil, ""},
+		{"plain", errors.New("boom"), cerrs.CodeUnknown},
+		{"coded", busy, cerrs.CodeDatabaseBusy},
+		{"wrapped", fmt.Errorf("ingest: %w", busy), cerrs.CodeDatabaseBusy},
+		{"outermost wins", cerrs.Wrap(cerrs.CodeInvalidInput, "decode", busy), cerrs.CodeInvalidInput},
+		{"no rows", fmt.Errorf("get user: %w", sql.ErrNoRows), cerrs.CodeNotFound},
+		{"not exist", fmt.Errorf("read: %w", os.ErrNotExist), cerrs.CodeNotFound},
+		{"canceled", fmt.Errorf("claim: %w", context.Canceled), cerrs.CodeCanceled},
+		{"deadline", context.DeadlineExceeded, cerrs.CodeTimeout},
+	} {
+		if got := cerrs.CodeOf(tc.err); got != tc.want {
+			t.Errorf("%s: CodeOf = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	if cerrs.Wrap(cerrs.CodeDatabase, "op", nil) != nil {
+		t.Errorf("Wrap(nil) should be nil")
+	}
+	if got := busy.Error(); got != "insert work: database is locked" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	for code, want := range map[cerrs.Code]int{
+		cerrs.CodeUnitIDInvalid: http.StatusBadRequest,
+		cerrs.CodeNotFound:      http.StatusNotFound,
+		cerrs.CodeConflict:      http.StatusConflict,
+		cerrs.CodeDatabaseBusy:  http.StatusServiceUnavailable,
+		cerrs.CodeDatabase:      http.StatusInternalServerError,
+		cerrs.CodeUnknown:       http.StatusInternalServerError,
+	} {
+		if got := cerrs.HTTPStatus(code); got != want {
+			t.Errorf("HTTPStatus(%s) = %d, want %d", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Package clock provides the time and the random identifiers used by the
+// store, the pipeline, and the web handlers, so that tests and exports can
+// replace them with deterministic ones.
+package clock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock returns the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// IDs generates the random identifiers used for sessions and tokens.
+type IDs interface {
+	// NewID returns a new identifier of 2*n hex digits.
+	NewID(n int) string
+}
+
+// Real is the system clock. Its times are in UTC.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// Random generates identifiers from crypto/rand.
+var Random IDs = randomIDs{}
+
+type randomIDs struct{}
+
+func (randomIDs) NewID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b) // never returns an error; see crypto/rand.Read
+	return hex.EncodeToString(b)
+}
+
+// Fixed is a Clock for tests. It returns the same time until it is advanced,
+// or advances by Step after every call if Step is set.
+type Fixed struct {
+	mu   sync.Mutex
+	t    time.Time
+	Step time.Duration
+}
+
+// NewFixed returns a Fixed clock set to t.
+func NewFixed(t time.Time) *Fixed {
+	return &Fixed{t: t.UTC()}
+}
+
+func (c *Fixed) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := c.t
+	c.t = c.t.Add(c.Step)
+	return t
+}
+
+// Advance moves the clock forward by d.
+func (c *Fixed) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// Sequence generates predictable identifiers for tests: the counter, starting
+// at 1, as zero-padded hex digits.
+type Sequence struct {
+	mu sync.Mutex
+	n  uint64
+}
+
+func (s *Sequence) NewID(n int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.n++
+	return fmt.Sprintf("%0*x", 2*n, s.n)
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/clock"
+)
+
+func TestFixed(t *testing.T) {
+	start := time.Date(2025, 11, 15, 7, 0, 0, 0, time.UTC)
+	c := clock.NewFixed(start)
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("now: got %v, want %v", got, start)
+	}
+	c.Advance(time.Hour)
+	if got, want := c.Now(), start.Add(time.Hour); !got.Equal(want) {
+		t.Errorf("advance: got %v, want %v", got, want)
+	}
+	c.Step = time.Second
+	c.Now()
+	if got, want := c.Now(), start.Add(time.Hour+time.Second); !got.Equal(want) {
+		t.Errorf("step: got %v, want %v", got, want)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var ids clock.Sequence
+	for _, want := range []string{"0001", "0002"} {
+		if got := ids.NewID(2); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if got := len(clock.Random.NewID(32)); got != 64 {
+		t.Errorf("random: got %d hex digits, want 64", got)
+	}
+}
@@ -8,34 +8,66 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
 	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/tracing"
 	"github.com/mdhender/tnrpt/web/auth"
 	"github.com/mdhender/tnrpt/web/handlers"
+	"github.com/mdhender/tnrpt/web/mail"
+	"github.com/mdhender/tnrpt/web/webhook"
+	"github.com/spf13/afero"
 )
 
 func main() {
 	addr := flag.String("addr", ":8787", "HTTP listen address")
-	authAs := flag.String("auth-as", "", "auto-authenticate as handle (e.g., xtc69) for testing")
-	authAsClan := flag.String("auth-as-clan", "", "auto-authenticate as game.clan (e.g., 0301.500) for testing")
+	authAs := flag.String("auth-as", "", "auto-authenticate as handle (e.g., xtc69) for testing; requires --insecure-dev")
+	authAsClan := flag.String("auth-as-clan", "", "auto-authenticate as game.clan (e.g., 0301.500) for testing; requires --insecure-dev")
+	cookieDomain := flag.String("cookie-domain", "", "Domain attribute of the session cookie (empty = only the host that set it)")
+	cookieName := flag.String("cookie-name", auth.SessionCookieName, "name of the session cookie")
+	cookieSameSite := flag.String("cookie-same-site", "lax", "SameSite attribute of the session cookie (lax, strict, none)")
+	cookieSecure := flag.String("cookie-secure", auth.CookieSecureAuto, "mark the session cookie Secure (auto = when the request is TLS or X-Forwarded-Proto is https, always, never)")
 	dataPath := flag.String("data", "", "directory containing .docx turn reports")
+	dataDir := flag.String("data-dir", "", "pipeline data directory (enables original files in GM exports, and loads --data in the background)")
+	dbMaintainEvery := flag.Duration("db-maintain-every", 0, "file-based mode: interval between runs of ANALYZE and WAL checkpoints (0 = never)")
+	dbMaintainIdle := flag.Duration("db-maintain-idle", 5*time.Minute, "wait until no request has been served for this long before maintaining the database")
 	dbPath := flag.String("db", "", "SQLite database file path (empty = in-memory)")
+	fsRoot := flag.String("fs-root", "", "read and write --data and --data-dir under this directory or s3://bucket/prefix (empty = the paths are used as given)")
 	gameDataPath := flag.String("game-data", "testdata/sprint-13", "path to games initialization file")
-	logWithDefaultFlags := flag.Bool("log-with-default-flags", false, "log with default flags")
-	logWithShortFileName := flag.Bool("log-with-shortfile", true, "log with short file name")
-	logWithTimestamp := flag.Bool("log-with-timestamp", false, "log with timestamp")
+	insecureDev := flag.Bool("insecure-dev", false, "allow the auto-authenticate test modes; the server only listens on localhost")
+	magicLinks := flag.Bool("magic-links", false, "allow passwordless login with emailed one-time links")
+	baseURL := flag.String("base-url", "", "public URL of the server for links in email, e.g. https://tn.example.com (required with --magic-links)")
+	smtpAddr := flag.String("smtp-addr", "", "SMTP server host:port for login links (empty = write links to the log)")
+	smtpFrom := flag.String("smtp-from", "", "sender address for email")
+	smtpUser := flag.String("smtp-user", "", "SMTP user name (password is read from TNRPT_SMTP_PASSWORD)")
+	logFormat := flag.String("log-format", logging.FormatText, "log record format (text, json)")
+	logLevel := flag.String("log-level", "info", "minimum level to log (debug, info, warn, error)")
+	renderAuto := flag.Bool("render-auto", false, "with --data-dir, draw a map image for each clan and turn as reports are parsed")
+	sessionLifetime := flag.Duration("session-lifetime", 24*time.Hour, "how long a login lasts")
 	showVersion := flag.Bool("version", false, "show version and exit")
+	snapshotPath := flag.String("snapshot", "", "in-memory mode: load this snapshot file at start and save to it periodically")
+	snapshotEvery := flag.Duration("snapshot-every", 5*time.Minute, "interval between snapshots (0 = only at shutdown)")
 	staticDir := flag.String("static", "web/static", "static files directory")
+	staticMaxAge := flag.Duration("static-max-age", 24*time.Hour, "Cache-Control max-age for static files")
 	timeout := flag.Duration("timeout", 0, "auto-shutdown after duration (e.g., 5s, 1m)")
+	turnCheckEvery := flag.Duration("turn-check-every", time.Minute, "interval between checks for turns to auto-advance (0 = never)")
+	turnWebhook := flag.String("turn-webhook", "", "URL to POST a JSON event to when a turn auto-advances")
+	usageStats := flag.Bool("usage-stats", false, "record requests in the database for the GM usage page (nothing is sent anywhere)")
+	usageStatsKeep := flag.Duration("usage-stats-keep", 90*24*time.Hour, "how long to keep recorded requests (0 = forever)")
 	userDataPath := flag.String("user-data", "testdata/sprint-13", "path to users initialization file")
 	flag.Parse()
 
@@ -44,38 +76,157 @@ func main() {
 		os.Exit(0)
 	}
 
-	logFlags := 0
-	if *logWithShortFileName {
-		logFlags |= log.Lshortfile
+	logger, err := logging.New(os.Stderr, *logFormat, *logLevel)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	if (*authAs != "" || *authAsClan != "") && !*insecureDev {
+		slog.Error("--auth-as and --auth-as-clan skip logging in and require --insecure-dev")
+		os.Exit(1)
+	}
+	if *insecureDev {
+		if *addr, err = auth.LoopbackAddr(*addr); err != nil {
+			slog.Error("--insecure-dev", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	var mailer mail.Sender
+	if *magicLinks {
+		if u, err := url.Parse(*baseURL); *baseURL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			slog.Error("--base-url must be the server's public http or https URL with --magic-links", "base-url", *baseURL)
+			os.Exit(1)
+		}
+		if *smtpAddr == "" {
+			mailer = mail.LogSender{}
+		} else if *smtpFrom == "" {
+			slog.Error("--smtp-from is required with --smtp-addr")
+			os.Exit(1)
+		} else {
+			mailer = &mail.SMTPSender{Addr: *smtpAddr, From: *smtpFrom, Username: *smtpUser, Password: os.Getenv("TNRPT_SMTP_PASSWORD")}
+		}
 	}
-	if *logWithTimestamp {
-		logFlags |= log.Ltime
+
+	sameSite, err := auth.ParseSameSite(*cookieSameSite)
+	if err != nil {
+		slog.Error("--cookie-same-site", "err", err)
+		os.Exit(1)
+	}
+	cookie := auth.CookieConfig{
+		Name:     *cookieName,
+		Domain:   *cookieDomain,
+		Secure:   *cookieSecure,
+		SameSite: sameSite,
+		Lifetime: *sessionLifetime,
 	}
-	if *logWithDefaultFlags || logFlags == 0 {
-		logFlags = log.LstdFlags
+
+	var hook *webhook.Poster
+	if *turnWebhook != "" {
+		hook = &webhook.Poster{URL: *turnWebhook}
 	}
-	log.SetFlags(logFlags)
 
-	err := run(*dbPath, *dataPath, *gameDataPath, *userDataPath, *staticDir, *authAs, *authAsClan, *addr, *timeout)
+	err = run(config{
+		DBPath:         *dbPath,
+		DataPath:       *dataPath,
+		DataDir:        *dataDir,
+		FSRoot:         *fsRoot,
+		GameDataPath:   *gameDataPath,
+		UserDataPath:   *userDataPath,
+		StaticDir:      *staticDir,
+		StaticMaxAge:   *staticMaxAge,
+		AuthAs:         *authAs,
+		AuthAsClan:     *authAsClan,
+		Addr:           *addr,
+		Timeout:        *timeout,
+		SnapshotPath:   *snapshotPath,
+		SnapshotEvery:  *snapshotEvery,
+		Mailer:         mailer,
+		BaseURL:        *baseURL,
+		TurnCheckEvery: *turnCheckEvery,
+		TurnHook:       hook,
+		Cookie:         cookie,
+		RenderAuto:     *renderAuto,
+		UsageStats:     *usageStats,
+		UsageStatsKeep: *usageStatsKeep,
+		MaintainEvery:  *dbMaintainEvery,
+		MaintainIdle:   *dbMaintainIdle,
+	})
 	if err != nil {
-		log.Printf("error: %v\n", err)
+		slog.Error("server failed", "err", err)
 	}
 }
 
-func run(dbPath, dataPath, gameDataPath, userDataPath, staticDir, authAs, authAsClan, addr string, timeout time.Duration) error {
+// config holds the server settings from the command line.
+type config struct {
+	DBPath         string // SQLite database file; empty for an in-memory store
+	DataPath       string // directory of .docx turn reports to load at start
+	DataDir        string // pipeline data directory
+	FSRoot         string // root of DataPath and DataDir; see stages.NewFS
+	GameDataPath   string // directory holding games.json
+	UserDataPath   string // directory holding users.json
+	StaticDir      string
+	StaticMaxAge   time.Duration
+	AuthAs         string // handle every visitor is logged in as
+	AuthAsClan     string // game.clan every visitor is logged in as
+	Addr           string
+	Timeout        time.Duration // shut down after this long; 0 runs until interrupted
+	SnapshotPath   string        // in-memory store snapshot
+	SnapshotEvery  time.Duration
+	Mailer         mail.Sender // delivers login links; nil disables them
+	BaseURL        string      // public URL of the server for login links
+	TurnCheckEvery time.Duration
+	TurnHook       *webhook.Poster // told about auto-advanced turns; may be nil
+	Cookie         auth.CookieConfig
+	RenderAuto     bool
+	UsageStats     bool
+	UsageStatsKeep time.Duration
+	MaintainEvery  time.Duration // 0 never runs database maintenance
+	MaintainIdle   time.Duration // quiet period required before maintenance
+}
+
+func run(cfg config) error {
 	var sqliteStore *store.SQLiteStore
 	var err error
 
-	if dbPath != "" {
-		// File-based mode: database must already exist (created by init-db command)
-		log.Printf("store: using file-based SQLite: %s", dbPath)
-		sqliteStore, err = store.NewSQLiteStoreWithConfig(store.StoreConfig{
-			Path:       dbPath,
-			InitSchema: false, // schema already applied by init-db
-		})
+	if cfg.DBPath != "" && cfg.SnapshotPath != "" {
+		return fmt.Errorf("snapshot: --snapshot is only for in-memory mode")
+	}
+
+	// tracing is set up first so that the store's queries are traced too
+	traceCfg, err := tracing.ConfigFromEnv("tnrpt-server")
+	if err != nil {
+		return fmt.Errorf("tracing: %w", err)
+	}
+	if traceCfg.Endpoint != "" {
+		slog.Info("tracing: exporting spans", "endpoint", traceCfg.Endpoint, "service", traceCfg.ServiceName)
+	}
+	stopTracing := tracing.Setup(traceCfg)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := stopTracing(ctx); err != nil {
+			slog.Error("tracing: shutdown", "err", err)
+		}
+	}()
+
+	missingAssets := handlers.MissingStaticAssets(cfg.StaticDir)
+	if len(missingAssets) != 0 {
+		slog.Error("static assets missing; serving the diagnostic page only", "count", len(missingAssets))
+		for _, path := range missingAssets {
+			slog.Error("static asset missing", "path", path)
+		}
+	}
+
+	if cfg.DBPath != "" {
+		// File-based mode: database must already exist (created by init-db
+		// command); it is migrated on open
+		slog.Info("store: using file-based SQLite", "path", cfg.DBPath)
+		sqliteStore, err = store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: cfg.DBPath})
 	} else {
 		// In-memory mode (default)
-		log.Printf("store: using in-memory SQLite")
+		slog.Info("store: using in-memory SQLite")
 		sqliteStore, err = store.NewSQLiteStore()
 	}
 	if err != nil {
@@ -85,42 +236,78 @@ func run(dbPath, dataPath, gameDataPath, userDataPath, staticDir, authAs, authAs
 
 	ctx := context.Background()
 
+	// A snapshot already holds the users, games, and reports, so skip the loaders.
+	restored := false
+	if cfg.SnapshotPath != "" {
+		if _, err := os.Stat(cfg.SnapshotPath); err == nil {
+			if err := sqliteStore.LoadSnapshot(ctx, cfg.SnapshotPath); err != nil {
+				return err
+			}
+			restored = true
+			slog.Info("store: restored snapshot", "path", cfg.SnapshotPath)
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+	}
+
 	// Load users and games first (needed for auth)
-	if userDataPath != "" {
-		usersPath := filepath.Join(userDataPath, "users.json")
+	if cfg.UserDataPath != "" && !restored {
+		usersPath := filepath.Join(cfg.UserDataPath, "users.json")
 		if err := sqliteStore.LoadUsersFromJSON(ctx, usersPath); err != nil {
 			return fmt.Errorf("failed to load users: %w", err)
 		}
 	}
-	if gameDataPath != "" {
-		gamesPath := filepath.Join(gameDataPath, "games.json")
+	if cfg.GameDataPath != "" && !restored {
+		gamesPath := filepath.Join(cfg.GameDataPath, "games.json")
 		if err := sqliteStore.LoadGamesFromJSON(ctx, gamesPath); err != nil {
 			return fmt.Errorf("failed to load games: %w", err)
 		}
 	}
 
-	// load any new data files
-	if dataPath != "" {
-		if err := store.LoadDocxFromDir(sqliteStore, dataPath); err != nil {
+	// load any new data files. With a pipeline data directory, the reports are
+	// queued and parsed in the background so the server can start right away.
+	fsys, err := stages.NewFS(cfg.FSRoot)
+	if err != nil {
+		return fmt.Errorf("fs-root: %w", err)
+	}
+	var worker *stages.WorkerService
+	if cfg.DataPath != "" && !restored {
+		if cfg.DataDir != "" {
+			if err := queueReports(ctx, fsys, sqliteStore, cfg.DataPath, cfg.DataDir); err != nil {
+				return fmt.Errorf("failed to queue data: %w", err)
+			}
+			worker = stages.NewWorkerService(sqliteStore, cfg.DataDir, "server")
+			worker.SetFS(fsys)
+			worker.SetRenderAuto(cfg.RenderAuto)
+		} else if err := store.LoadDocxFromDir(fsys, sqliteStore, cfg.DataPath); err != nil {
 			return fmt.Errorf("failed to load data: %w", err)
 		}
 	}
 
 	stats := sqliteStore.Stats()
-	log.Printf("store: %d reports, %d units, %d acts, %d steps",
-		stats.Reports, stats.Units, stats.Acts, stats.Steps)
+	slog.Info("store: loaded", "reports", stats.Reports, "units", stats.Units, "acts", stats.Acts, "steps", stats.Steps)
 
 	sessions := auth.NewSessionStore()
+	sessions.SetBackend(sqliteStore)
+	if err := sessions.SetCookieConfig(cfg.Cookie); err != nil {
+		return err
+	}
 	h := handlers.New(sqliteStore, sessions)
+	h.SetDataDir(cfg.DataDir)
+	h.SetFS(fsys)
+	if cfg.Mailer != nil {
+		h.SetMagicLinks(cfg.Mailer, cfg.BaseURL)
+		slog.Info("auth: login links enabled")
+	}
 
-	if authAs != "" && authAsClan != "" {
+	if cfg.AuthAs != "" && cfg.AuthAsClan != "" {
 		return fmt.Errorf("auth: cannot use both --auth-as and --auth-as-clan")
 	}
 
-	if authAsClan != "" {
-		parts := strings.SplitN(authAsClan, ".", 2)
+	if cfg.AuthAsClan != "" {
+		parts := strings.SplitN(cfg.AuthAsClan, ".", 2)
 		if len(parts) != 2 {
-			return fmt.Errorf("auth: invalid format %q (expected game.clan)", authAsClan)
+			return fmt.Errorf("auth: invalid format %q (expected game.clan)", cfg.AuthAsClan)
 		}
 		gameID := parts[0]
 		clanNo, err := strconv.Atoi(parts[1])
@@ -129,66 +316,47 @@ func run(dbPath, dataPath, gameDataPath, userDataPath, staticDir, authAs, authAs
 		}
 		handle, err := sqliteStore.GetHandleForClan(ctx, gameID, clanNo)
 		if err != nil {
-			return fmt.Errorf("auth: failed to get handle for %s: %v", authAsClan, err)
+			return fmt.Errorf("auth: failed to get handle for %s: %v", cfg.AuthAsClan, err)
 		}
 		if handle == "" {
 			return fmt.Errorf("auth: clan %d not found in game %s", clanNo, gameID)
 		}
 		h.SetAutoAuth(gameID, handle, clanNo)
-		log.Printf("auth: auto-authenticating as %s (game %s, clan %d)", handle, gameID, clanNo)
+		slog.Warn("auth: auto-authenticating", logging.KeyUser, handle, logging.KeyGame, gameID, logging.KeyClan, clanNo)
+		auth.WriteInsecureBanner(os.Stderr, "every visitor is logged in as "+handle, cfg.Addr)
 	}
 
-	if authAs != "" {
-		games, err := sqliteStore.GetGamesForUser(ctx, authAs)
+	if cfg.AuthAs != "" {
+		games, err := sqliteStore.GetGamesForUser(ctx, cfg.AuthAs)
 		if err != nil {
-			return fmt.Errorf("auth: failed to get games for %s: %v", authAs, err)
+			return fmt.Errorf("auth: failed to get games for %s: %v", cfg.AuthAs, err)
 		}
 		if len(games) == 0 {
-			return fmt.Errorf("auth: user %s not found in any game", authAs)
+			return fmt.Errorf("auth: user %s not found in any game", cfg.AuthAs)
 		}
 		game := games[0]
-		h.SetAutoAuth(game.GameID, authAs, game.ClanNo)
-		log.Printf("auth: auto-authenticating as %s (game %s, clan %d)", authAs, game.GameID, game.ClanNo)
+		h.SetAutoAuth(game.GameID, cfg.AuthAs, game.ClanNo)
+		slog.Warn("auth: auto-authenticating", logging.KeyUser, cfg.AuthAs, logging.KeyGame, game.GameID, logging.KeyClan, game.ClanNo)
+		auth.WriteInsecureBanner(os.Stderr, "every visitor is logged in as "+cfg.AuthAs, cfg.Addr)
 	}
 
-	mux := http.NewServeMux()
+	mux := newMux(h, cfg.StaticDir, cfg.StaticMaxAge)
 
-	fs := http.FileServer(http.Dir(staticDir))
-	mux.Handle("/static/", http.StripPrefix("/static/", fs))
-
-	mux.HandleFunc("/", h.Index)
-	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			h.Login(w, r)
-		} else {
-			h.LoginPage(w, r)
-		}
-	})
-	mux.HandleFunc("/logout", h.Logout)
-	mux.HandleFunc("/units", h.RequireAuth(h.Units))
-	mux.HandleFunc("/units/{id}", h.RequireAuth(h.UnitDetail))
-	mux.HandleFunc("/movements", h.RequireAuth(h.Movements))
-	mux.HandleFunc("/terrain", h.RequireAuth(h.Terrain))
-	mux.HandleFunc("/tiles/{grid}/{col}/{row}", h.RequireAuth(h.TileDetail))
-	mux.HandleFunc("/resources", h.RequireAuth(h.Resources))
-	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			h.RequireGM(h.UploadHandler)(w, r)
-		} else {
-			h.RequireGM(h.UploadPage)(w, r)
-		}
-	})
-	mux.HandleFunc("/admin/sql", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			h.RequireGM(h.SQLConsoleExec)(w, r)
-		} else {
-			h.RequireGM(h.SQLConsolePage)(w, r)
-		}
-	})
+	var handler http.Handler = tracing.Middleware(mux)
+	if cfg.UsageStats {
+		slog.Info("usage: recording requests", "keep", cfg.UsageStatsKeep)
+		handler = h.RecordUsage(handler)
+	}
+	if len(missingAssets) != 0 {
+		handler = handlers.AssetsUnavailable(cfg.StaticDir, missingAssets)
+	}
+	handler = logging.Middleware(slog.Default(), handler)
+	quiet := &activity{}
+	handler = quiet.Middleware(handler)
 
 	server := &http.Server{
-		Addr:         addr,
-		Handler:      mux,
+		Addr:         cfg.Addr,
+		Handler:      handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -197,24 +365,113 @@ func run(dbPath, dataPath, gameDataPath, userDataPath, staticDir, authAs, authAs
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
-	if timeout > 0 {
+	if cfg.Timeout > 0 {
 		go func() {
-			log.Printf("server: will auto-shutdown in %v", timeout)
-			time.Sleep(timeout)
-			log.Printf("server: timeout reached, initiating shutdown")
+			slog.Info("server: will auto-shutdown", "after", cfg.Timeout)
+			time.Sleep(cfg.Timeout)
+			slog.Info("server: cfg.Timeout reached, initiating shutdown")
 			shutdown <- os.Interrupt
 		}()
 	}
 
 	go func() {
-		log.Printf("server: listening on %s", addr)
+		slog.Info("server: listening", "addr", cfg.Addr)
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Printf("server: %v", err)
+			slog.Error("server: listen", "err", err)
 		}
 	}()
 
+	stopSnapshots := make(chan struct{})
+	if cfg.SnapshotPath != "" && cfg.SnapshotEvery > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.SnapshotEvery)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stopSnapshots:
+					return
+				case <-ticker.C:
+					if err := sqliteStore.Snapshot(context.Background(), cfg.SnapshotPath); err != nil {
+						slog.Error("store: snapshot", "err", err)
+					}
+				}
+			}
+		}()
+	}
+
+	stopTurns := make(chan struct{})
+	if cfg.TurnCheckEvery > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.TurnCheckEvery)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stopTurns:
+					return
+				case <-ticker.C:
+					advanceDueTurns(sqliteStore, cfg.TurnHook)
+				}
+			}
+		}()
+	}
+
+	stopUsage := make(chan struct{})
+	if cfg.UsageStats && cfg.UsageStatsKeep > 0 {
+		go func() {
+			ticker := time.NewTicker(24 * time.Hour)
+			defer ticker.Stop()
+			for {
+				pruneRequestLog(sqliteStore, cfg.UsageStatsKeep)
+				select {
+				case <-stopUsage:
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+
+	stopMaintenance := make(chan struct{})
+	if cfg.DBPath != "" && cfg.MaintainEvery > 0 {
+		slog.Info("store: maintaining database", "every", cfg.MaintainEvery, "idle", cfg.MaintainIdle)
+		go func() {
+			ticker := time.NewTicker(min(time.Minute, cfg.MaintainEvery))
+			defer ticker.Stop()
+			lastRun := time.Now()
+			for {
+				select {
+				case <-stopMaintenance:
+					return
+				case <-ticker.C:
+					if time.Since(lastRun) < cfg.MaintainEvery || !quiet.IdleFor(cfg.MaintainIdle) {
+						continue
+					}
+					maintainDatabase(sqliteStore)
+					lastRun = time.Now()
+				}
+			}
+		}()
+	}
+
+	stopWorker := make(chan struct{})
+	workerDone := make(chan struct{})
+	if worker != nil {
+		go func() {
+			defer close(workerDone)
+			runPipeline(worker, stopWorker)
+		}()
+	} else {
+		close(workerDone)
+	}
+
 	<-shutdown
-	log.Printf("server: shutting down gracefully")
+	slog.Info("server: shutting down gracefully")
+	close(stopSnapshots)
+	close(stopTurns)
+	close(stopUsage)
+	close(stopMaintenance)
+	close(stopWorker)
+	<-workerDone
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -223,6 +480,138 @@ func run(dbPath, dataPath, gameDataPath, userDataPath, staticDir, authAs, authAs
 		return fmt.Errorf("server: shutdown error: %w", err)
 	}
 
-	log.Printf("server: stopped")
+	if cfg.SnapshotPath != "" {
+		if err := sqliteStore.Snapshot(ctx, cfg.SnapshotPath); err != nil {
+			return err
+		}
+		slog.Info("store: saved snapshot", "path", cfg.SnapshotPath)
+	}
+
+	slog.Info("server: stopped")
+	return nil
+}
+
+// queueReports ingests the report files in dir into the pipeline work queue.
+// Files that were already ingested are skipped.
+func queueReports(ctx context.Context, fsys afero.Fs, s *store.SQLiteStore, dir, dataDir string) error {
+	ingest := stages.NewIngestService(s, dataDir)
+	ingest.SetFS(fsys)
+	watcher := stages.NewWatchService(ingest, dir, "server")
+	watcher.SetFS(fsys)
+	watcher.SetSettle(false)
+	results, err := watcher.Scan(ctx)
+	if err != nil {
+		return err
+	}
+	queued := 0
+	for _, r := range results {
+		if !r.Duplicate {
+			queued++
+		}
+	}
+	logging.FromContext(ctx).Info("pipeline: queued report files", "queued", queued, "files", len(results), "dir", dir)
 	return nil
 }
+
+// runPipeline works through the extract, parse, and render queues until stop is
+// closed, checking for new work every few seconds once the queues are empty.
+func runPipeline(worker *stages.WorkerService, stop <-chan struct{}) {
+	ctx := context.Background()
+	for {
+		idle := true
+		for _, stage := range []string{model.WorkStageExtract, model.WorkStageParse, model.WorkStageRender} {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			processed, err := worker.ProcessJob(ctx, stage)
+			if err != nil {
+				logging.FromContext(ctx).Error("pipeline: job failed", logging.KeyStage, stage, "err", err)
+			}
+			if processed {
+				idle = false
+			}
+		}
+		if !idle {
+			continue
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// advanceDueTurns activates the next turn of any auto-advancing game whose
+// orders are due, then logs each change and posts it to the webhook.
+// pruneRequestLog deletes recorded requests older than keep.
+func pruneRequestLog(s *store.SQLiteStore, keep time.Duration) {
+	n, err := s.PruneRequestLog(context.Background(), time.Now().Add(-keep))
+	if err != nil {
+		slog.Error("usage: prune", "err", err)
+		return
+	}
+	if n != 0 {
+		slog.Info("usage: pruned requests", "count", n)
+	}
+}
+
+func maintainDatabase(s *store.SQLiteStore) {
+	started := time.Now()
+	result, err := s.Maintain(context.Background())
+	if err != nil {
+		slog.Error("store: maintain", "err", err)
+		return
+	}
+	slog.Info("store: maintained database", "wal_pages", result.WALPages, "checkpointed", result.Checkpointed, "busy", result.Busy, "elapsed", time.Since(started))
+}
+
+// activity remembers when the server last served a request, so that
+// background work can wait for a quiet moment.
+type activity struct {
+	inFlight atomic.Int64
+	last     atomic.Int64 // UnixNano of the last request to finish
+}
+
+// Middleware counts the requests passing through next.
+func (a *activity) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.inFlight.Add(1)
+		defer func() {
+			a.last.Store(time.Now().UnixNano())
+			a.inFlight.Add(-1)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IdleFor reports whether no request is being served and none has finished within d.
+func (a *activity) IdleFor(d time.Duration) bool {
+	return a.inFlight.Load() == 0 && time.Since(time.Unix(0, a.last.Load())) >= d
+}
+
+func advanceDueTurns(s *store.SQLiteStore, hook *webhook.Poster) {
+	ctx := context.Background()
+	advances, err := s.AdvanceDueTurns(ctx, time.Now().UTC())
+	if err != nil {
+		slog.Error("turns: advance", "err", err)
+		return
+	}
+	for _, ta := range advances {
+		slog.Info("turns: advanced", logging.KeyGame, ta.GameID, "from", ta.From.String(), "due", ta.DueDate.Format(time.RFC3339), "to", ta.To.String())
+		if hook == nil {
+			continue
+		}
+		data := struct {
+			Game    string    `json:"game"`
+			From    string    `json:"from"`
+			To      string    `json:"to"`
+			DueDate time.Time `json:"dueDate"`
+		}{ta.GameID, ta.From.String(), ta.To.String(), ta.DueDate}
+		if err := hook.Post(ctx, store.AuditTurnAdvance, data); err != nil {
+			slog.Error("turns: webhook", logging.KeyGame, ta.GameID, "err", err)
+		}
+	}
+}
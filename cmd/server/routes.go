@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"net/http"
+	"time"
+
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/handlers"
+)
+
+// newMux registers the server's routes. Static files are served from staticDir
+// with a Cache-Control max-age of staticMaxAge.
+func newMux(h *handlers.Handlers, staticDir string, staticMaxAge time.Duration) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	fs := http.FileServer(http.Dir(staticDir))
+	mux.Handle("/static/", handlers.CacheStatic(staticMaxAge, http.StripPrefix("/static/", fs)))
+
+	mux.HandleFunc("/", h.Index)
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.Login(w, r)
+		} else {
+			h.LoginPage(w, r)
+		}
+	})
+	mux.HandleFunc("/login/magic", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.RequestMagicLink(w, r)
+		} else {
+			h.MagicLinkPage(w, r)
+		}
+	})
+	mux.HandleFunc("/login/magic/{token}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.MagicLinkLogin(w, r)
+		} else {
+			h.MagicLinkConfirm(w, r)
+		}
+	})
+	mux.HandleFunc("/logout", h.Logout)
+	mux.HandleFunc("/units", h.RequireAuth(h.Units))
+	mux.HandleFunc("/units/{id}", h.RequireAuth(h.UnitDetail))
+	mux.HandleFunc("/movements", h.RequireAuth(h.Movements))
+	mux.HandleFunc("/steps.json", h.RequireAuth(h.StepsExport))
+	mux.HandleFunc("/movement-points.json", h.RequireAuth(h.MovementPointsExport))
+	mux.HandleFunc("/activity", h.RequireAuth(h.Activity))
+	mux.HandleFunc("/query", h.RequireAuth(h.QueryBuilder))
+	mux.HandleFunc("/activity.rss", h.RequireAuth(h.ActivityRSS))
+	mux.HandleFunc("/scouting", h.RequireAuth(h.Scouting))
+	mux.HandleFunc("/terrain", h.RequireAuth(h.Terrain))
+	mux.HandleFunc("/tiles/{grid}/{col}/{row}", h.RequireAuth(h.TileDetail))
+	mux.HandleFunc("/tiles/{grid}/{col}/{row}/neighbors.json", h.RequireAuth(h.TileNeighbors))
+	mux.HandleFunc("/resources", h.RequireAuth(h.Resources))
+	mux.HandleFunc("/coverage", h.RequireAuth(h.Coverage))
+	mux.HandleFunc("/transitions", h.RequireAuth(h.Transitions))
+	mux.HandleFunc("/plan", h.RequireAuth(h.PlanPath))
+	mux.HandleFunc("/turns/{turn}/print", h.RequireAuth(h.TurnPrint))
+	mux.HandleFunc("/turns/{turn}/diff", h.RequireAuth(h.TurnDiff))
+	mux.HandleFunc("/turns/{turn}/map.svg", h.RequireAuth(h.TurnMap))
+	mux.HandleFunc("/turns/{turn}/public.json", h.RequireAuth(h.TurnPublicJSON))
+	mux.HandleFunc("/turns/{turn}/public.svg", h.RequireAuth(h.TurnPublicMap))
+	mux.HandleFunc("/turns/{turn}/originals/{id}", h.RequireAuth(h.TurnOriginal))
+	mux.HandleFunc("/preferences/theme", h.RequireAuth(h.SetTheme))
+	mux.HandleFunc("/shares", h.RequireAuth(h.ShareMap))
+	mux.HandleFunc("/public/redaction", h.RequireAuth(h.SetPublicRedaction))
+	mux.HandleFunc("/sessions", h.RequireAuth(h.SessionsPage))
+	mux.HandleFunc("/sessions/{ref}/revoke", h.RequireAuth(h.RevokeSession))
+	mux.HandleFunc("/reports", h.RequireAuth(h.Reports))
+	mux.HandleFunc("/reports/{id}/removal", h.RequireAuth(h.RequestRemoval))
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.RequireGM(h.UploadHandler)(w, r)
+		} else {
+			h.RequireGM(h.UploadPage)(w, r)
+		}
+	})
+	mux.HandleFunc("/uploads/{batch}/events", h.RequireGM(h.UploadEvents))
+	mux.HandleFunc("/gm/turns/{turn}/export", h.RequireGM(h.GMTurnExport))
+	mux.HandleFunc("/gm/users/{handle}/login-link", h.RequireGM(h.GMSendMagicLink))
+	mux.HandleFunc("/gm/users/{handle}/logout", h.RequireGM(h.GMLogoutUser))
+	mux.HandleFunc("/gm/users/export", h.RequireGM(h.GMUsersExport))
+	mux.HandleFunc("/gm/users/import", h.RequireGM(h.GMUsersImport))
+	mux.HandleFunc("/gm/games", h.RequireGM(h.GMGames))
+	mux.HandleFunc("/gm/queue", h.RequireGM(h.GMQueue))
+	mux.HandleFunc("/gm/corrections", h.RequireGM(h.GMCorrections))
+	mux.HandleFunc("/gm/removals", h.RequireGM(h.GMRemovals))
+	mux.HandleFunc("/gm/removals/{id}/approve", h.RequireGM(h.GMApproveRemoval))
+	mux.HandleFunc("/gm/removals/{id}/reject", h.RequireGM(h.GMRejectRemoval))
+	mux.HandleFunc("/gm/queue/pause", h.RequireGM(h.GMQueuePause))
+	mux.HandleFunc("/gm/queue/resume", h.RequireGM(h.GMQueueResume))
+	mux.HandleFunc("/gm/queue/{batch}", h.RequireGM(h.GMQueueBatch))
+	mux.HandleFunc("/gm/queue/{batch}/retry", h.RequireGM(h.GMQueueRetry))
+	mux.HandleFunc("/gm/reports/{id}/reassign", h.RequireGM(h.GMReassignReport))
+	mux.HandleFunc("/gm/reports/{id}/text", h.RequireGM(h.GMReportText))
+	mux.HandleFunc("/gm/games/{game}/auto-advance", h.RequireGM(h.GMSetAutoAdvance))
+	mux.HandleFunc("/gm/games/{game}/turns", h.RequireGM(h.GMAddTurn))
+	mux.HandleFunc("/gm/games/{game}/turns/{turn}/activate", h.RequireGM(h.GMActivateTurn))
+	mux.HandleFunc("/gm/games/{game}/turns/{turn}/due", h.RequireGM(h.GMSetTurnDueDate))
+	mux.HandleFunc("/api/v1/ingest", h.RequireAPIToken(store.APIScopeGM, h.APIIngest))
+	mux.HandleFunc("/api/v1/steps", h.RequireAPIToken(store.APIScopeGM, h.APISteps))
+	mux.HandleFunc("/api/v1/tiles", h.RequireAPIToken(store.APIScopeGM, h.APITile))
+	mux.HandleFunc("/api/v1/neighbors", h.RequireAPIToken(store.APIScopeGM, h.APINeighbors))
+	mux.HandleFunc("/admin/performance", h.RequireGM(h.Performance))
+	mux.HandleFunc("/admin/usage", h.RequireGM(h.Usage))
+	mux.HandleFunc("/admin/flags", h.RequireGM(h.Flags))
+	mux.HandleFunc("/admin/snapshot", h.RequireGM(h.Snapshot))
+	mux.HandleFunc("/admin/sql", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.RequireGM(h.SQLConsoleExec)(w, r)
+		} else {
+			h.RequireGM(h.SQLConsolePage)(w, r)
+		}
+	})
+
+	return mux
+}
@@ -0,0 +1,622 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/handlers"
+	"github.com/spf13/afero"
+)
+
+const (
+	testUsers = `[
+  {"handle": "gm", "user-name": "Game Master", "password": "gm-secret", "roles": ["active", "gm"]},
+  {"handle": "clan0987", "user-name": "Clan 987", "password": "player-secret", "roles": ["active", "user"]}
+]`
+	testGames = `[
+  {"id": "0301", "description": "Test game",
+   "clans": [{"handle": "clan0987", "clan": 987}],
+   "turns": [{"id": 89912, "year": 899, "month": 12}, {"id": 90001, "year": 900, "month": 1, "active": true}]}
+]`
+)
+
+// newTestServer boots the server's routes over an in-memory store loaded
+// with two users, one game, and the 899-12 sample report.
+func newTestServer(t *testing.T) (*httptest.Server, *store.SQLiteStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	s, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	dir := t.TempDir()
+	for name, data := range map[string]string{"users.json": testUsers, "games.json": testGames} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.LoadUsersFromJSON(ctx, filepath.Join(dir, "users.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadGamesFromJSON(ctx, filepath.Join(dir, "games.json")); err != nil {
+		t.Fatal(err)
+	}
+	// the report is loaded from an in-memory filesystem, as a deployment
+	// with another storage backend would
+	docx, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0301.0899-12.0987.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/reports/0301.0899-12.0987.docx", docx, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.LoadDocxFromDir(fsys, s, "/reports"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RebuildDerived(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// and the original is kept in the pipeline data directory
+	if err := afero.WriteFile(fsys, "/data/batches/1/0301.0899-12.0987.docx", docx, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	batchID, err := s.InsertUploadBatch(ctx, &model.UploadBatch{Game: "0301", ClanNo: "0987", TurnNo: 89912, CreatedBy: "test", CreatedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(docx)
+	if _, err := s.InsertReportFileWithBatch(ctx, &model.ReportFile{
+		Game: "0301", ClanNo: "0987", TurnNo: 89912, Name: "0301.0899-12.0987.docx",
+		SHA256: hex.EncodeToString(hash[:]), Mime: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		CreatedAt: time.Now().UTC(), FsPath: "batches/1/0301.0899-12.0987.docx", BatchID: &batchID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// sessions are kept in the database, as the server keeps them
+	sessions := auth.NewSessionStore()
+	sessions.SetBackend(s)
+	h := handlers.New(s, sessions)
+	h.SetFS(fsys)
+	h.SetDataDir("/data")
+	ts := httptest.NewServer(h.RecordUsage(newMux(h, t.TempDir(), 0)))
+	t.Cleanup(ts.Close)
+	return ts, s
+}
+
+// client is a browser stand-in that keeps cookies and doesn't follow redirects.
+type client struct {
+	t    *testing.T
+	base string
+	http *http.Client
+}
+
+func newClient(t *testing.T, ts *httptest.Server) *client {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &client{t: t, base: ts.URL, http: &http.Client{
+		Jar: jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func (c *client) do(req *http.Request) (int, string) {
+	c.t.Helper()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func (c *client) get(path string) (int, string) {
+	c.t.Helper()
+	req, err := http.NewRequest(http.MethodGet, c.base+path, nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return c.do(req)
+}
+
+func (c *client) postForm(path string, form url.Values) (int, string) {
+	c.t.Helper()
+	req, err := http.NewRequest(http.MethodPost, c.base+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req)
+}
+
+func (c *client) login(handle, password string) {
+	c.t.Helper()
+	code, _ := c.postForm("/login", url.Values{"username": {handle}, "password": {password}, "game": {"0301"}})
+	if code != http.StatusSeeOther {
+		c.t.Fatalf("login %s: status = %d, want %d", handle, code, http.StatusSeeOther)
+	}
+}
+
+// sessionRef returns the public reference of the client's session.
+func (c *client) sessionRef(s *store.SQLiteStore) string {
+	c.t.Helper()
+	u, err := url.Parse(c.base)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	for _, cookie := range c.http.Jar.Cookies(u) {
+		if session, err := s.GetSession(context.Background(), cookie.Value); err != nil {
+			c.t.Fatal(err)
+		} else if session != nil {
+			return session.Ref
+		}
+	}
+	c.t.Fatal("no session cookie")
+	return ""
+}
+
+// wantPage checks that GET path returns 200 with every one of the snippets.
+func (c *client) wantPage(path string, snippets ...string) {
+	c.t.Helper()
+	code, body := c.get(path)
+	if code != http.StatusOK {
+		c.t.Errorf("GET %s: status = %d, want %d", path, code, http.StatusOK)
+		return
+	}
+	for _, s := range snippets {
+		if !strings.Contains(body, s) {
+			c.t.Errorf("GET %s: body does not contain %q", path, s)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	ts, s := newTestServer(t)
+
+	t.Run("requires login", func(t *testing.T) {
+		c := newClient(t, ts)
+		if code, _ := c.get("/units"); code != http.StatusSeeOther {
+			t.Errorf("GET /units: status = %d, want %d", code, http.StatusSeeOther)
+		}
+		code, body := c.postForm("/login", url.Values{"username": {"clan0987"}, "password": {"wrong"}})
+		if code != http.StatusOK || !strings.Contains(body, "Invalid username or password") {
+			t.Errorf("bad password: status = %d, want the login page with an error", code)
+		}
+	})
+
+	t.Run("player pages", func(t *testing.T) {
+		c := newClient(t, ts)
+		c.login("clan0987", "player-secret")
+		if code, _ := c.get("/"); code != http.StatusSeeOther {
+			t.Errorf("GET /: status = %d, want %d to the units page", code, http.StatusSeeOther)
+		}
+		c.wantPage("/units?game=0301", "<h1>Units</h1>", `data-label="Unit ID"`, "QQ 1315")
+		c.wantPage("/movements?game=0301", "Movement History", "0987")
+		c.wantPage("/tiles/QQ/10/10?game=0301", "Tile QQ 1010")
+		c.wantPage("/tiles/QQ/10/10/neighbors.json?game=0301", `"tile":"QQ 1010"`, `"dir":"N","coord":"QQ 1009","knowledge":`)
+		c.wantPage("/transitions?game=0301", "Movement Odds")
+		c.wantPage("/activity?game=0301", "<h1>Activity</h1>")
+		c.wantPage("/activity.rss?game=0301", "<rss version=\"2.0\">", "Clan 0987 activity")
+		c.wantPage("/scouting?game=0301", "<h1>Scouting</h1>")
+		c.wantPage("/scouting?game=0301&format=json", `"units":`, `"steps":`)
+	})
+
+	t.Run("cache revalidation", func(t *testing.T) {
+		c := newClient(t, ts)
+		c.login("clan0987", "player-secret")
+		get := func(etag string) (int, string) {
+			req, err := http.NewRequest(http.MethodGet, c.base+"/tiles/QQ/10/10?game=0301", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			resp, err := c.http.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode, resp.Header.Get("ETag")
+		}
+		code, etag := get("")
+		if code != http.StatusOK || etag == "" {
+			t.Fatalf("GET: status = %d, etag = %q", code, etag)
+		}
+		if code, _ := get(etag); code != http.StatusNotModified {
+			t.Errorf("GET again: status = %d, want %d", code, http.StatusNotModified)
+		}
+		// rebuilding the tiles changes the page even though no report changed
+		if _, err := s.RebuildDerived(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if code, _ := get(etag); code != http.StatusOK {
+			t.Errorf("GET after rebuild: status = %d, want %d", code, http.StatusOK)
+		}
+	})
+
+	t.Run("original report", func(t *testing.T) {
+		c := newClient(t, ts)
+		c.login("clan0987", "player-secret")
+		code, body := c.get("/turns/0899-12/print?game=0301")
+		if code != http.StatusOK {
+			t.Fatalf("GET print: status = %d", code)
+		}
+		link := regexp.MustCompile(`href="(/turns/0899-12/originals/\d+)"`).FindStringSubmatch(body)
+		if link == nil {
+			t.Fatalf("print page: want a link to the original report")
+		}
+		code, body = c.get(link[1] + "?game=0301")
+		if code != http.StatusOK || !strings.HasPrefix(body, "PK") {
+			t.Errorf("GET %s: status = %d, want the docx", link[1], code)
+		}
+		if code, _ := c.get("/turns/0899-12/originals/999999?game=0301"); code != http.StatusNotFound {
+			t.Errorf("unknown report file: status = %d, want %d", code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("sql console guard", func(t *testing.T) {
+		const query = "SELECT handle FROM users ORDER BY handle"
+
+		player := newClient(t, ts)
+		player.login("clan0987", "player-secret")
+		if code, _ := player.get("/admin/sql"); code != http.StatusForbidden {
+			t.Errorf("player GET /admin/sql: status = %d, want %d", code, http.StatusForbidden)
+		}
+		if code, _ := player.postForm("/admin/sql", url.Values{"query": {query}}); code != http.StatusForbidden {
+			t.Errorf("player POST /admin/sql: status = %d, want %d", code, http.StatusForbidden)
+		}
+
+		gm := newClient(t, ts)
+		gm.login("gm", "gm-secret")
+		gm.wantPage("/admin/sql", "SQL Console")
+		code, body := gm.postForm("/admin/sql", url.Values{"query": {query}})
+		if code != http.StatusOK || !strings.Contains(body, "clan0987") {
+			t.Errorf("gm POST /admin/sql: status = %d, want the query results", code)
+		}
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0900-01.0987.report.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		upload := func(c *client) (int, string) {
+			var buf bytes.Buffer
+			mw := multipart.NewWriter(&buf)
+			mw.WriteField("game", "0301")
+			mw.WriteField("turn", "0900-01")
+			fw, err := mw.CreateFormFile("file", "0301.0900-01.0987.report.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write(data)
+			mw.Close()
+			req, err := http.NewRequest(http.MethodPost, c.base+"/upload", &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			return c.do(req)
+		}
+
+		player := newClient(t, ts)
+		player.login("clan0987", "player-secret")
+		if code, _ := upload(player); code != http.StatusForbidden {
+			t.Errorf("player upload: status = %d, want %d", code, http.StatusForbidden)
+		}
+
+		gm := newClient(t, ts)
+		gm.login("gm", "gm-secret")
+		gm.wantPage("/upload", "Upload Turn Reports")
+		code, body := upload(gm)
+		if code != http.StatusOK {
+			t.Fatalf("gm upload: status = %d: %s", code, body)
+		}
+		var resp struct {
+			Success bool   `json:"success"`
+			Clan    string `json:"clan"`
+			Units   int    `json:"units"`
+		}
+		if err := json.Unmarshal([]byte(body), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Success || resp.Clan != "0987" || resp.Units == 0 {
+			t.Errorf("gm upload: response = %+v, want success for clan 0987", resp)
+		}
+
+		if code, _ := upload(gm); code != http.StatusConflict {
+			t.Errorf("duplicate upload: status = %d, want %d", code, http.StatusConflict)
+		}
+		player.wantPage("/units?game=0301&turn=0900-01", "0987g1")
+	})
+
+	t.Run("api tokens", func(t *testing.T) {
+		ctx := context.Background()
+		newToken := func(handle, name string, scopes ...string) string {
+			token, err := s.CreateAPIToken(ctx, handle, name, scopes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}
+		gmToken := newToken("gm", "ingest", store.APIScopeGM)
+		unscoped := newToken("gm", "unscoped")
+		playerToken := newToken("clan0987", "ingest", store.APIScopeGM)
+		revoked := newToken("gm", "revoked", store.APIScopeGM)
+		if ok, err := s.RevokeAPIToken(ctx, "gm", "revoked"); err != nil || !ok {
+			t.Fatalf("revoke: %v, %v", ok, err)
+		}
+
+		// two reports with the same extension, which must be kept apart
+		ingest := func(token string) (int, string) {
+			var buf bytes.Buffer
+			mw := multipart.NewWriter(&buf)
+			mw.WriteField("game", "0301")
+			mw.WriteField("clan", "0987")
+			mw.WriteField("turn", "0900-02")
+			for _, name := range []string{"a.txt", "b.txt"} {
+				fw, err := mw.CreateFormFile("file", name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fmt.Fprintf(fw, "report %s\n", name)
+			}
+			mw.Close()
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/ingest", &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			return newClient(t, ts).do(req)
+		}
+
+		for _, tc := range []struct {
+			name  string
+			token string
+			want  int
+		}{
+			{"missing token", "", http.StatusUnauthorized},
+			{"unknown token", store.APITokenPrefix + "nope", http.StatusUnauthorized},
+			{"revoked token", revoked, http.StatusUnauthorized},
+			{"token without gm scope", unscoped, http.StatusUnauthorized},
+			{"token of a player", playerToken, http.StatusForbidden},
+		} {
+			if code, body := ingest(tc.token); code != tc.want {
+				t.Errorf("%s: status = %d, want %d: %s", tc.name, code, tc.want, body)
+			}
+		}
+
+		code, body := ingest(gmToken)
+		if code != http.StatusCreated {
+			t.Fatalf("ingest: status = %d: %s", code, body)
+		}
+		rfs, err := s.GetReportFilesByGameTurn(ctx, "0301", 90002)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rfs) != 2 || rfs[0].FsPath == rfs[1].FsPath {
+			t.Errorf("ingested report files = %+v, want two with their own paths", rfs)
+		}
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		loggedIn := func(c *client) bool {
+			code, _ := c.get("/units")
+			return code == http.StatusOK
+		}
+		phone, laptop := newClient(t, ts), newClient(t, ts)
+		phone.login("clan0987", "player-secret")
+		laptop.login("clan0987", "player-secret")
+		if !loggedIn(phone) || !loggedIn(laptop) {
+			t.Fatal("login: not logged in")
+		}
+
+		// the player ends the phone's session from the laptop
+		if code, _ := laptop.postForm("/sessions/"+phone.sessionRef(s)+"/revoke", url.Values{}); code != http.StatusSeeOther {
+			t.Errorf("revoke: status = %d, want %d", code, http.StatusSeeOther)
+		}
+		if loggedIn(phone) {
+			t.Errorf("revoked session: still logged in")
+		}
+		if !loggedIn(laptop) {
+			t.Errorf("after revoke: the laptop was logged out as well")
+		}
+
+		// a GM logs the player out everywhere
+		gm := newClient(t, ts)
+		gm.login("gm", "gm-secret")
+		if code, body := gm.postForm("/gm/users/clan0987/logout", url.Values{}); code != http.StatusOK || !strings.Contains(body, "sessions ended for clan0987") {
+			t.Errorf("gm logout: status = %d: %s", code, body)
+		}
+		if loggedIn(laptop) {
+			t.Errorf("after gm logout: still logged in")
+		}
+		if !loggedIn(gm) {
+			t.Errorf("after gm logout: the gm was logged out as well")
+		}
+	})
+
+	t.Run("steps export", func(t *testing.T) {
+		c := newClient(t, ts)
+		c.login("clan0987", "player-secret")
+		code, body := c.get("/steps.json?game=0301")
+		if code != http.StatusOK {
+			t.Fatalf("GET /steps.json: status = %d", code)
+		}
+		var records []model.StepRecord
+		if err := json.Unmarshal([]byte(body), &records); err != nil {
+			t.Fatal(err)
+		}
+		if len(records) == 0 {
+			t.Fatalf("steps: got none, want the clan's steps")
+		}
+		for _, rec := range records {
+			if rec.From == "" || rec.To == "" || rec.UnitID == "" {
+				t.Errorf("step %+v: want it placed and keyed", rec)
+				break
+			}
+		}
+	})
+
+	t.Run("query builder", func(t *testing.T) {
+		c := newClient(t, ts)
+		c.login("clan0987", "player-secret")
+		c.wantPage("/query?game=0301", "<h1>Query</h1>", `name="field"`)
+		c.wantPage("/query?game=0301&entity=units&run=1&field=turn&op=eq&value=0899-12", "1 row returned.", "QQ 1315")
+		c.wantPage("/query?game=0301&entity=steps&run=1&col=unit&col=kind&field=unit&op=starts&value=0987&sort=step&desc=1&limit=5", "5 rows returned.", `data-label="Step kind"`)
+		for _, e := range store.QueryEntities {
+			c.wantPage("/query?game=0301&run=1&entity="+e.Name, "returned.")
+		}
+		c.wantPage("/query?game=0301&entity=encounters&run=1&dedup=1&col=seen&col=hex&field=count&op=ge&value=1", `name="dedup" value="1" checked`, `data-label="Times seen"`)
+		c.wantPage("/query?game=0301&entity=units&run=1&dedup=1", "Error:", "deduplicated")
+		c.wantPage("/query?game=0301&entity=steps&run=1&field=turn&op=eq&value=soon", "Error:")
+		c.wantPage("/query?game=0301&entity=units&run=1&sort=unit_id%3BDROP+TABLE+units", "Error:", "no field")
+		if code, _ := c.get("/query?game=0301&entity=users"); code != http.StatusBadRequest {
+			t.Errorf("GET /query for users: status = %d, want %d", code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("report queue", func(t *testing.T) {
+		player := newClient(t, ts)
+		player.login("clan0987", "player-secret")
+		if code, _ := player.get("/gm/queue"); code != http.StatusForbidden {
+			t.Errorf("player GET /gm/queue: status = %d, want %d", code, http.StatusForbidden)
+		}
+
+		gm := newClient(t, ts)
+		gm.login("gm", "gm-secret")
+		gm.wantPage("/gm/queue", "Report Queue")
+		if code, _ := gm.get("/gm/queue/999"); code != http.StatusNotFound {
+			t.Errorf("GET /gm/queue/999: status = %d, want %d", code, http.StatusNotFound)
+		}
+		if code, _ := gm.postForm("/gm/queue/999/retry", url.Values{"stage": {"bogus"}}); code != http.StatusBadRequest {
+			t.Errorf("POST /gm/queue/999/retry with a bad stage: status = %d, want %d", code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("removal requests", func(t *testing.T) {
+		player := newClient(t, ts)
+		player.login("clan0987", "player-secret")
+		player.wantPage("/reports?game=0301", "<h1>Reports</h1>", "Request removal")
+		_, body := player.get("/reports?game=0301")
+		m := regexp.MustCompile(`/reports/(\d+)/removal`).FindStringSubmatch(body)
+		if m == nil {
+			t.Fatalf("GET /reports: no removal form")
+		}
+		if code, _ := player.postForm("/reports/999999/removal?game=0301", url.Values{"reason": {"not mine"}}); code != http.StatusNotFound {
+			t.Errorf("POST /reports/999999/removal: status = %d, want %d", code, http.StatusNotFound)
+		}
+		player.postForm(m[0]+"?game=0301", url.Values{"reason": {"not mine"}})
+		player.wantPage("/reports?game=0301", "Removal requested", "Waiting for a GM")
+		if code, _ := player.postForm(m[0]+"?game=0301", url.Values{"reason": {"again"}}); code != http.StatusConflict {
+			t.Errorf("second removal request: status = %d, want %d", code, http.StatusConflict)
+		}
+		if code, _ := player.get("/gm/removals"); code != http.StatusForbidden {
+			t.Errorf("player GET /gm/removals: status = %d, want %d", code, http.StatusForbidden)
+		}
+
+		gm := newClient(t, ts)
+		gm.login("gm", "gm-secret")
+		code, body := gm.get("/gm/removals?game=0301&format=json")
+		var requests []*model.RemovalRequest
+		if err := json.Unmarshal([]byte(body), &requests); code != http.StatusOK || err != nil || len(requests) != 1 {
+			t.Fatalf("GET /gm/removals?format=json: status = %d, %v, %d requests", code, err, len(requests))
+		}
+		// keep the report; the other tests need it
+		gm.postForm(fmt.Sprintf("/gm/removals/%d/reject", requests[0].ID), url.Values{"note": {"it's yours"}})
+		gm.wantPage("/gm/removals?game=0301", "Removal Requests", "Kept by gm", "it&#39;s yours")
+		if code, _ := gm.postForm(fmt.Sprintf("/gm/removals/%d/approve", requests[0].ID), nil); code != http.StatusConflict {
+			t.Errorf("approving a rejected request: status = %d, want %d", code, http.StatusConflict)
+		}
+	})
+
+	t.Run("snapshot", func(t *testing.T) {
+		player := newClient(t, ts)
+		player.login("clan0987", "player-secret")
+		if code, _ := player.get("/admin/snapshot"); code != http.StatusForbidden {
+			t.Errorf("player GET /admin/snapshot: status = %d, want %d", code, http.StatusForbidden)
+		}
+
+		gm := newClient(t, ts)
+		gm.login("gm", "gm-secret")
+		code, body := gm.get("/admin/snapshot")
+		if code != http.StatusOK {
+			t.Fatalf("GET /admin/snapshot: status = %d", code)
+		}
+		if !strings.HasPrefix(body, "SQLite format 3\x00") {
+			t.Errorf("GET /admin/snapshot: want a SQLite database, got %q", body[:min(len(body), 16)])
+		}
+	})
+
+	t.Run("usage stats", func(t *testing.T) {
+		player := newClient(t, ts)
+		player.login("clan0987", "player-secret")
+		if code, _ := player.get("/admin/usage"); code != http.StatusForbidden {
+			t.Errorf("player GET /admin/usage: status = %d, want %d", code, http.StatusForbidden)
+		}
+
+		gm := newClient(t, ts)
+		gm.login("gm", "gm-secret")
+		gm.wantPage("/admin/usage", "<h1>Usage</h1>", "/units")
+		code, body := gm.get("/admin/usage?format=json")
+		if code != http.StatusOK {
+			t.Fatalf("GET /admin/usage?format=json: status = %d", code)
+		}
+		var stats model.UsageStats
+		if err := json.Unmarshal([]byte(body), &stats); err != nil {
+			t.Fatal(err)
+		}
+		if len(stats.ReportsPerDay) == 0 {
+			t.Errorf("reports per day: got none, want the reports parsed by the tests")
+		}
+		if len(stats.UsersPerWeek) == 0 || stats.UsersPerWeek[len(stats.UsersPerWeek)-1].Users < 2 {
+			t.Errorf("users per week = %+v, want both users this week", stats.UsersPerWeek)
+		}
+		var units *model.RouteUsage
+		for i, ru := range stats.BusiestPages {
+			if ru.Route == "/units" {
+				units = &stats.BusiestPages[i]
+			}
+		}
+		if units == nil || units.Requests < 2 {
+			t.Errorf("busiest pages = %+v, want /units requested more than once", stats.BusiestPages)
+		}
+	})
+}
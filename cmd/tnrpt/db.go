@@ -0,0 +1,1752 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/lint"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	sqlite "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/stream"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func cmdDb() *cobra.Command {
+	showBuildInfo := false
+	addFlags := func(cmd *cobra.Command) error {
+		cmd.Flags().BoolVar(&showBuildInfo, "build-info", showBuildInfo, "show build information")
+		return nil
+	}
+	var cmd = &cobra.Command{
+		Use:   "db",
+		Short: "database tools",
+	}
+	cmd.AddCommand(cmdDbAPITokens())
+	cmd.AddCommand(cmdDbArchive())
+	cmd.AddCommand(cmdDbCheck())
+	cmd.AddCommand(cmdDbCompact())
+	cmd.AddCommand(cmdDbFlags())
+	cmd.AddCommand(cmdDbInit())
+	cmd.AddCommand(cmdDbLint())
+	cmd.AddCommand(cmdDbMaintain())
+	cmd.AddCommand(cmdDbMigrate())
+	cmd.AddCommand(cmdDbOrders())
+	cmd.AddCommand(cmdDbPublic())
+	cmd.AddCommand(cmdDbReassign())
+	cmd.AddCommand(cmdDbRebuildDerived())
+	cmd.AddCommand(cmdDbRehash())
+	cmd.AddCommand(cmdDbRestore())
+	cmd.AddCommand(cmdDbSchema())
+	cmd.AddCommand(cmdDbSnapshot())
+	cmd.AddCommand(cmdDbSteps())
+	cmd.AddCommand(cmdDbUsers())
+	if err := addFlags(cmd); err != nil {
+		log.Fatal(err)
+	}
+	return cmd
+}
+
+func cmdDbCheck() *cobra.Command {
+	var dbPath string
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Find orphaned rows in the database",
+		Long: `Find rows that reference a missing parent row (for example, steps without
+acts, encounters without steps, or work without report files). These are
+left behind by historical partial writes or by writes made while foreign
+keys were not enforced.
+
+With --fix, the orphaned rows are deleted in a single transaction.
+Deleting an orphan also deletes its children through ON DELETE CASCADE.
+With --fix --dry-run, the rows are only counted.
+
+Examples:
+  tnrpt db check --db data/amp/tnrpt.db
+  tnrpt db check --db data/amp/tnrpt.db --fix`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			if fix && isDryRun(cmd) {
+				fix = false
+				logging.FromContext(ctx).Info("db: check: dry run, orphaned rows will not be deleted")
+			}
+			if enabled, err := store.ForeignKeysEnabled(ctx); err != nil {
+				return err
+			} else if !enabled {
+				logging.FromContext(ctx).Warn("db: check: foreign keys are not enforced; deletes will not cascade")
+			}
+
+			var counts []sqlite.OrphanCount
+			if fix {
+				counts, err = store.DeleteOrphans(ctx)
+			} else {
+				counts, err = store.FindOrphans(ctx)
+			}
+			if err != nil {
+				return err
+			}
+
+			var total int64
+			for _, c := range counts {
+				total += c.Count
+			}
+			if outputFormat(cmd) == outputJSON {
+				type orphan struct {
+					Table  string `json:"table"`
+					Column string `json:"column"`
+					Parent string `json:"parent"`
+					Count  int64  `json:"count"`
+				}
+				result := struct {
+					Fixed   bool     `json:"fixed"`
+					Total   int64    `json:"total"`
+					Orphans []orphan `json:"orphans"`
+				}{Fixed: fix, Total: total, Orphans: []orphan{}}
+				for _, c := range counts {
+					if c.Count != 0 {
+						result.Orphans = append(result.Orphans, orphan{c.Table, c.Column, c.Parent, c.Count})
+					}
+				}
+				return printJSON(result)
+			}
+
+			for _, c := range counts {
+				if c.Count == 0 {
+					continue
+				}
+				logging.FromContext(ctx).Info("db: check: orphaned rows", "table", c.Table, "rows", c.Count, "column", c.Column, "parent", c.Parent)
+			}
+			if fix {
+				logging.FromContext(ctx).Info("db: check: deleted orphaned rows", "rows", total)
+			} else if total > 0 {
+				logging.FromContext(ctx).Warn("db: check: found orphaned rows; run with --fix to delete them", "rows", total)
+			} else {
+				logging.FromContext(ctx).Info("db: check: no orphaned rows found")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().BoolVar(&fix, "fix", false, "delete orphaned rows")
+	addDryRunFlag(cmd)
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbCompact() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:          "compact <database-path>",
+		Short:        "Compact a SQLite database for backup/export",
+		Long:         `Runs VACUUM and checkpoints WAL to create a single compact database file suitable for backup or export.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbPath := args[0]
+
+			logging.FromContext(cmd.Context()).Info("db: compact: compacting database", "db", dbPath)
+
+			if err := sqlite.CompactDatabase(dbPath); err != nil {
+				return fmt.Errorf("compact database: %w", err)
+			}
+
+			logging.FromContext(cmd.Context()).Info("db: compact: database compacted")
+			return nil
+		},
+	}
+	return cmd
+}
+
+func cmdDbSnapshot() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "snapshot <output-path>",
+		Short: "Write a consistent copy of a live database",
+		Long: `Write a read-only, consistent copy of the database to <output-path> using
+VACUUM INTO. The database can stay in use by the server while the copy is
+made, so heavy analytical queries and exports can run against the snapshot
+instead of contending with uploads. An existing file at <output-path> is
+replaced.
+
+Examples:
+  tnrpt db snapshot --db data/amp/tnrpt.db /tmp/tnrpt-analytics.db`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			output := args[0]
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			started := time.Now()
+			if err := store.Snapshot(ctx, output); err != nil {
+				return err
+			}
+			logging.FromContext(ctx).Info("db: snapshot: wrote snapshot", "path", output, "elapsed", time.Since(started))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbInit() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:          "initb <database-path>",
+		Short:        "Create and initialize a new SQLite database",
+		Long:         `Creates a new SQLite database file and initializes the schema. The database file must not already exist.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbPath := args[0]
+
+			logging.FromContext(cmd.Context()).Info("db: initb: creating database", "db", dbPath)
+
+			if err := sqlite.InitDatabase(dbPath); err != nil {
+				return fmt.Errorf("init database: %w", err)
+			}
+
+			logging.FromContext(cmd.Context()).Info("db: initb: database created", "db", dbPath, "journal_mode", "wal")
+			return nil
+		},
+	}
+	return cmd
+}
+
+func cmdDbMaintain() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "maintain",
+		Short: "Refresh planner statistics and checkpoint the WAL",
+		Long: `Runs PRAGMA optimize and ANALYZE so that the query planner has current
+statistics, then copies the write-ahead log back into the database file.
+The checkpoint does not wait on other connections, so this is safe to run
+while the server is up. The server can do the same in the background; see
+its --db-maintain-every flag.
+
+Examples:
+  tnrpt db maintain --db data/amp/tnrpt.db`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			result, err := store.Maintain(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if outputFormat(cmd) == outputJSON {
+				return printJSON(struct {
+					JournalMode  string `json:"journal-mode"`
+					WALPages     int    `json:"wal-pages"`
+					Checkpointed int    `json:"checkpointed"`
+					Busy         bool   `json:"busy"`
+				}{result.JournalMode, result.WALPages, result.Checkpointed, result.Busy})
+			}
+			logging.FromContext(cmd.Context()).Info("db: maintain: optimized and analyzed", "db", dbPath)
+			logging.FromContext(cmd.Context()).Info("db: maintain: checkpointed", "pages", result.Checkpointed, "wal_pages", result.WALPages)
+			if result.Busy {
+				logging.FromContext(cmd.Context()).Warn("db: maintain: the database was busy; the rest of the WAL is left for the next run")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbMigrate() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Bring the database schema up to date",
+		Long: `Apply the schema migrations that the database has not had yet.
+
+Every command that opens the database, and the server, applies them as well;
+this command lets them be applied, or checked, on their own before an upgrade.
+
+With --dry-run, the pending migrations are listed with their SQL statements,
+and the database is opened read-only.
+
+Examples:
+  tnrpt db migrate --db data/amp/tnrpt.db --dry-run
+  tnrpt db migrate --db data/amp/tnrpt.db`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			version, pending, err := sqlite.PendingMigrations(ctx, dbPath)
+			if err != nil {
+				return fmt.Errorf("migrate: %w", err)
+			}
+			if isDryRun(cmd) {
+				if outputFormat(cmd) == outputJSON {
+					type migration struct {
+						Version int      `json:"version"`
+						Name    string   `json:"name"`
+						Stmts   []string `json:"statements"`
+					}
+					result := struct {
+						Version int         `json:"version"`
+						Pending []migration `json:"pending"`
+					}{Version: version, Pending: []migration{}}
+					for _, m := range pending {
+						result.Pending = append(result.Pending, migration{m.Version, m.Name, m.Stmts})
+					}
+					return printJSON(result)
+				}
+				for _, m := range pending {
+					fmt.Printf("-- migration %d: %s\n", m.Version, m.Name)
+					for _, stmt := range m.Stmts {
+						fmt.Printf("%s;\n", stmt)
+					}
+				}
+				logging.FromContext(ctx).Info("db: migrate: dry run, no changes written", "version", version, "pending", len(pending))
+				return nil
+			}
+			if len(pending) == 0 {
+				logging.FromContext(ctx).Info("db: migrate: schema is up to date", "version", version)
+				return nil
+			}
+
+			// opening the store applies the migrations
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("migrate: %w", err)
+			}
+			defer store.Close()
+			for _, m := range pending {
+				logging.FromContext(ctx).Info("db: migrate: applied", "version", m.Version, "name", m.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	addDryRunFlag(cmd)
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbArchive() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var keepMonths int
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Compress old report file blobs into the archive directory",
+		Long: `Move the original uploads of report files older than the retention period
+into <data-dir>/archive as gzip files, and update their fs_path.
+
+Extracted units, moves, and tiles stay in the database; only the blobs move.
+Archived files are not re-processed by the pipeline until they are restored
+with "tnrpt db restore".
+
+Examples:
+  tnrpt db archive --db data/amp/tnrpt.db --data-dir data/amp
+  tnrpt db archive --db data/amp/tnrpt.db --data-dir data/amp --keep-months 12 --dry-run`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			dryRun := isDryRun(cmd)
+			if keepMonths < 0 {
+				return fmt.Errorf("--keep-months must not be negative")
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			cutoff := time.Now().UTC().AddDate(0, -keepMonths, 0)
+			svc := stages.NewArchiveService(store, dataDir)
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			svc.SetFS(fsys)
+			result, err := svc.Archive(ctx, cutoff, dryRun)
+			if err != nil {
+				return err
+			}
+
+			logging.FromContext(ctx).Info("db: archive: archived", "files", result.Files, "bytes", result.Bytes, "before", cutoff.Format(time.DateOnly), "skipped", result.Skipped)
+			if dryRun {
+				logging.FromContext(ctx).Info("db: archive: dry run, no changes written")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().IntVar(&keepMonths, "keep-months", 6, "keep blobs uploaded in the last N months")
+	addDryRunFlag(cmd)
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+
+	return cmd
+}
+
+func cmdDbRestore() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var ids []int64
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore archived report file blobs",
+		Long: `Decompress archived report files back to their original location under
+the data directory and update their fs_path. Restores every archived file
+unless --id is given.
+
+Examples:
+  tnrpt db restore --db data/amp/tnrpt.db --data-dir data/amp
+  tnrpt db restore --db data/amp/tnrpt.db --data-dir data/amp --id 12,13`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			dryRun := isDryRun(cmd)
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			svc := stages.NewArchiveService(store, dataDir)
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			svc.SetFS(fsys)
+			result, err := svc.Restore(ctx, ids, dryRun)
+			if err != nil {
+				return err
+			}
+
+			logging.FromContext(ctx).Info("db: restore: restored", "files", result.Files, "bytes", result.Bytes, "skipped", result.Skipped)
+			if dryRun {
+				logging.FromContext(ctx).Info("db: restore: dry run, no changes written")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().Int64SliceVar(&ids, "id", nil, "restore only these report file IDs")
+	addDryRunFlag(cmd)
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+
+	return cmd
+}
+
+func cmdDbOrders() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "orders",
+		Short: "Import a clan's orders and check them against the turn's results",
+	}
+	cmd.AddCommand(cmdDbOrdersCheck())
+	cmd.AddCommand(cmdDbOrdersImport())
+	return cmd
+}
+
+func cmdDbOrdersImport() *cobra.Command {
+	var dbPath, game, turn string
+	var clanNo int
+
+	cmd := &cobra.Command{
+		Use:   "import <orders.txt>",
+		Short: "Replace a clan's orders for a turn from an orders file",
+		Long: `Replace a clan's orders for a turn with the orders in a plain-text file.
+Each line is a unit followed by one order:
+
+  0987e1 move ne n n
+  0987e1 scout 1 n-ne-ne
+  0987c1 follow 0987e1
+  0987g1 goto QQ 1010
+
+Blank lines and lines starting with # are ignored. The file is rejected
+as a whole if any line is invalid.
+
+Examples:
+  tnrpt db orders import --db data/amp/tnrpt.db --game 0301 --clan 987 --turn 0900-01 orders.txt`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			turnNo, err := model.ParseTurnNo(turn)
+			if err != nil {
+				return fmt.Errorf("invalid turn: %w", err)
+			}
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("read orders: %w", err)
+			}
+			orders, err := model.ParseOrders(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			found, err := store.ReplaceOrders(ctx, actor, game, clanNo, turnNo, orders)
+			if err != nil {
+				return err
+			} else if !found {
+				return fmt.Errorf("game %s not found", game)
+			}
+			logging.FromContext(ctx).Info("db: orders: imported", "orders", len(orders), logging.KeyClan, clanNo, logging.KeyTurn, turnNo)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&game, "game", "", "game ID (required)")
+	cmd.Flags().IntVar(&clanNo, "clan", 0, "clan number (required)")
+	cmd.Flags().StringVar(&turn, "turn", "", "turn the orders are for, e.g. 0900-01 (required)")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("game")
+	cmd.MarkFlagRequired("clan")
+	cmd.MarkFlagRequired("turn")
+
+	return cmd
+}
+
+func cmdDbOrdersCheck() *cobra.Command {
+	var dbPath, game, turn string
+	var clanNo int
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "List where a turn's results diverge from the clan's orders",
+		Long: `Compare a clan's imported orders for a turn with the units' parsed actions
+and list every divergence: orders that weren't executed, moves that went
+in another direction or stopped early, extra steps, and actions nobody
+ordered. A move that stopped on a failed step is not a divergence.
+
+Examples:
+  tnrpt db orders check --db data/amp/tnrpt.db --game 0301 --clan 987 --turn 0900-01
+  tnrpt db orders check --db data/amp/tnrpt.db --game 0301 --clan 987 --turn 0900-01 --output json`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			turnNo, err := model.ParseTurnNo(turn)
+			if err != nil {
+				return fmt.Errorf("invalid turn: %w", err)
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			divs, err := store.OrderCheck(ctx, game, clanNo, turnNo)
+			if err != nil {
+				return err
+			}
+			if outputFormat(cmd) == outputJSON {
+				if divs == nil {
+					divs = []model.OrderDivergence{}
+				}
+				return printJSON(divs)
+			}
+			if len(divs) == 0 {
+				fmt.Println("no divergences")
+				return nil
+			}
+			fmt.Printf("%-8s %-6s %-8s %-16s %4s  %-8s %-8s\n", "unit", "line", "order", "problem", "step", "ordered", "reported")
+			for _, d := range divs {
+				order := string(d.Kind)
+				if d.ScoutNo != 0 {
+					order = fmt.Sprintf("scout %d", d.ScoutNo)
+				}
+				line, step := "", ""
+				if d.LineNo != 0 {
+					line = strconv.Itoa(d.LineNo)
+				}
+				if d.StepNo != 0 {
+					step = strconv.Itoa(d.StepNo)
+				}
+				fmt.Printf("%-8s %-6s %-8s %-16s %4s  %-8s %-8s\n", d.UnitID, line, order, d.Problem, step, d.Want, d.Got)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&game, "game", "", "game ID (required)")
+	cmd.Flags().IntVar(&clanNo, "clan", 0, "clan number (required)")
+	cmd.Flags().StringVar(&turn, "turn", "", "turn to check, e.g. 0900-01 (required)")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("game")
+	cmd.MarkFlagRequired("clan")
+	cmd.MarkFlagRequired("turn")
+
+	return cmd
+}
+
+func cmdDbPublic() *cobra.Command {
+	var dbPath string
+	var output string
+	var game, clan, turn string
+	var hide []string
+
+	cmd := &cobra.Command{
+		Use:   "public",
+		Short: "Write a clan's turn summary for sharing publicly",
+		Long: `Write a clan's known map as of a turn with its coordinates and unit IDs
+removed, so it can be posted publicly. Hexes are placed relative to the
+corner of the known map, and hexes first seen in the turn are marked new.
+The summary is written as JSON, or as an SVG map if the output file ends
+in .svg.
+
+The clan's saved settings say what else to leave out. With --hide, the
+settings are replaced first; use --hide none to show everything again.
+The things that can be hidden are terrain, rivers, settlements, resources,
+encounters, and units.
+
+Examples:
+  tnrpt db public --db data/amp/tnrpt.db --game 0301 --clan 0987 --turn 0900-01
+  tnrpt db public --db data/amp/tnrpt.db --game 0301 --clan 0987 --turn 0900-01 --output 0900-01.svg
+  tnrpt db public --db data/amp/tnrpt.db --game 0301 --clan 0987 --turn 0900-01 --hide resources,units`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			clanNo, err := strconv.Atoi(clan)
+			if err != nil || clanNo < 1 || clanNo > 9999 {
+				return fmt.Errorf("invalid clan %q", clan)
+			}
+			turnNo, err := model.ParseTurnNo(turn)
+			if err != nil {
+				return fmt.Errorf("invalid turn: %w", err)
+			}
+			var redaction model.PublicRedaction
+			for _, h := range hide {
+				switch h {
+				case "none":
+				case "terrain":
+					redaction.HideTerrain = true
+				case "rivers":
+					redaction.HideRivers = true
+				case "settlements":
+					redaction.HideSettlements = true
+				case "resources":
+					redaction.HideResources = true
+				case "encounters":
+					redaction.HideEncounters = true
+				case "units":
+					redaction.HideUnits = true
+				default:
+					return fmt.Errorf("invalid --hide %q", h)
+				}
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			if cmd.Flags().Changed("hide") {
+				if err := store.SetPublicRedaction(ctx, game, clanNo, redaction); err != nil {
+					return fmt.Errorf("save settings: %w", err)
+				}
+			}
+			ps, err := store.PublicSummaryByGameClan(ctx, game, clanNo, turnNo)
+			if err != nil {
+				return fmt.Errorf("public summary: %w", err)
+			}
+			var data []byte
+			if strings.HasSuffix(output, ".svg") {
+				data = stages.RenderPublicMapSVG(ps, "Turn "+turnNo.String())
+			} else if data, err = json.MarshalIndent(ps, "", "  "); err != nil {
+				return fmt.Errorf("marshal summary: %w", err)
+			} else {
+				data = append(data, '\n')
+			}
+			if output == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			if err := os.WriteFile(output, data, 0o644); err != nil {
+				return fmt.Errorf("write summary: %w", err)
+			}
+			logging.FromContext(ctx).Info("db: public: wrote snapshot", "hexes", len(ps.Hexes), "units", len(ps.Units), "path", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&game, "game", "", "game id, e.g. 0301 (required)")
+	cmd.Flags().StringVar(&clan, "clan", "", "clan number, e.g. 0987 (required)")
+	cmd.Flags().StringVar(&turn, "turn", "", "turn, e.g. 0900-01 (required)")
+	cmd.Flags().StringSliceVar(&hide, "hide", nil, "save what the clan leaves out, e.g. terrain,units (none shows everything)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write to file instead of stdout (.svg for a map)")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("game")
+	cmd.MarkFlagRequired("clan")
+	cmd.MarkFlagRequired("turn")
+
+	return cmd
+}
+
+func cmdDbReassign() *cobra.Command {
+	var dbPath, game, clan, turn string
+	var reparse bool
+
+	cmd := &cobra.Command{
+		Use:   "reassign <report-file-id>",
+		Short: "Move an uploaded report file to another game, clan, or turn",
+		Long: `Move a report file that was uploaded under the wrong game, clan, or turn.
+The reports parsed from the file move with it; maps rendered from it and
+corrections it took part in are removed, and the corrections are recorded
+again. The change is recorded in the audit log. Without --reparse, the move
+is refused if the parsed reports have units of another clan.
+
+With --reparse, the parsed reports are removed instead and the file is
+queued to parse again under the new game, clan, and turn. Run the pipeline
+worker to parse it.
+
+Examples:
+  tnrpt db reassign --db data/amp/tnrpt.db --game 0301 --clan 0987 --turn 0900-01 42
+  tnrpt db reassign --db data/amp/tnrpt.db --game 0301 --clan 0987 --turn 0900-01 --reparse 42`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid report file id: %w", err)
+			}
+			turnNo, err := model.ParseTurnNo(turn)
+			if err != nil {
+				return fmt.Errorf("invalid turn: %w", err)
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			ids, err := store.UnitIDRules(ctx, game)
+			if err != nil {
+				return err
+			}
+			if err := ids.ValidateClan(clan); err != nil {
+				return err
+			}
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			old, err := store.ReassignReportFile(ctx, actor, id, game, clan, turnNo, reparse)
+			if err != nil {
+				return err
+			}
+			logging.FromContext(ctx).Info("db: reassign: moved", logging.KeyFile, old.Name, "from_game", old.Game, "from_clan", old.ClanNo, "from_turn", old.TurnNo,
+				logging.KeyGame, game, logging.KeyClan, clan, logging.KeyTurn, turnNo)
+			if reparse {
+				logging.FromContext(ctx).Info("db: reassign: queued to parse again", logging.KeyFile, old.Name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&game, "game", "", "game ID to move the file to (required)")
+	cmd.Flags().StringVar(&clan, "clan", "", "clan to move the file to, e.g. 0987 (required)")
+	cmd.Flags().StringVar(&turn, "turn", "", "turn to move the file to, e.g. 0900-01 (required)")
+	cmd.Flags().BoolVar(&reparse, "reparse", false, "parse the file again instead of moving its parsed reports")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("game")
+	cmd.MarkFlagRequired("clan")
+	cmd.MarkFlagRequired("turn")
+
+	return cmd
+}
+
+func cmdDbRebuildDerived() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "rebuild-derived",
+		Short: "Regenerate tiles and unit events from the report extracts",
+		Long: `Delete the derived tables (tiles, tile contents and provenance, and unit events)
+and regenerate them from the report extracts, acts, and steps already in the database.
+
+Use this after a fix to how derived data is computed. The original reports are
+not re-parsed; to pick up parser fixes, re-upload the reports instead.
+
+Step coordinates are resolved by walking each unit's moves from its starting hex.
+Settlements are stored with the tiles they were seen in.
+
+With --dry-run, the derived rows that would be deleted are counted and the
+new tiles are computed, but nothing is written.
+
+Examples:
+  tnrpt db rebuild-derived --db data/amp/tnrpt.db
+  tnrpt db rebuild-derived --db data/amp/tnrpt.db --dry-run`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			started := time.Now()
+			if isDryRun(cmd) {
+				stats, err := store.RebuildDerivedDryRun(ctx)
+				if err != nil {
+					return fmt.Errorf("rebuild derived: %w", err)
+				}
+				for _, tr := range stats.Replaced {
+					logging.FromContext(ctx).Info("db: rebuild-derived: would delete", "table", tr.Table, "rows", tr.Rows)
+				}
+				logging.FromContext(ctx).Info("db: rebuild-derived: would write tiles and refresh unit events", "tiles", stats.Tiles, "games", stats.Games, "steps", stats.Steps)
+				logging.FromContext(ctx).Info("db: rebuild-derived: dry run, no changes written")
+				return nil
+			}
+			stats, err := store.RebuildDerived(ctx)
+			if err != nil {
+				return fmt.Errorf("rebuild derived: %w", err)
+			}
+			logging.FromContext(ctx).Info("db: rebuild-derived: rebuilt", "games", stats.Games, "steps", stats.Steps, "tiles", stats.Tiles,
+				"unit_events", stats.UnitEvents, "elapsed", time.Since(started))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	addDryRunFlag(cmd)
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbRehash() *cobra.Command {
+	var dbPath string
+	var dataDir string
+
+	cmd := &cobra.Command{
+		Use:   "rehash",
+		Short: "Recompute report file hashes from stored contents",
+		Long: `Recompute the SHA256 hash of every report file that has a stored copy
+under the data directory, and update rows whose hash does not match the contents.
+
+Older imports recorded the hash of the file name instead of the file contents.
+Rows without a stored copy can't be recomputed; those that still carry the hash
+of their file name are reported, and the command exits with an error so that
+they can be repaired by uploading the report again.
+
+Examples:
+  tnrpt db rehash --db data/amp/tnrpt.db --data-dir data/amp
+  tnrpt db rehash --db data/amp/tnrpt.db --data-dir data/amp --dry-run`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			dryRun := isDryRun(cmd)
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			rfs, err := store.GetReportFilesWithPath(ctx)
+			if err != nil {
+				return err
+			}
+
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			var updated, missing, unrepairable int
+			for _, rf := range rfs {
+				data, err := afero.ReadFile(fsys, filepath.Join(dataDir, rf.FsPath))
+				if err != nil {
+					logging.FromContext(ctx).Warn("db: rehash", logging.KeyReportFileID, rf.ID, "path", rf.FsPath, "err", err)
+					missing++
+					continue
+				}
+				hash := sha256.Sum256(data)
+				hashStr := hex.EncodeToString(hash[:])
+				if hashStr == rf.SHA256 {
+					continue
+				}
+				logging.FromContext(ctx).Info("db: rehash: hash changed", logging.KeyReportFileID, rf.ID, "path", rf.FsPath, "old", rf.SHA256, "new", hashStr)
+				if !dryRun {
+					if err := store.UpdateReportFileSHA256(ctx, rf.ID, hashStr); err != nil {
+						return err
+					}
+				}
+				updated++
+			}
+
+			// without a stored copy the contents are gone; the best we can do is
+			// find the rows that still carry the hash of their file name
+			nopath, err := store.GetReportFilesWithoutPath(ctx)
+			if err != nil {
+				return err
+			}
+			for _, rf := range nopath {
+				nameHash := sha256.Sum256([]byte(rf.Name))
+				if hex.EncodeToString(nameHash[:]) != rf.SHA256 {
+					continue
+				}
+				logging.FromContext(ctx).Warn("db: rehash: no stored copy, hash is of the file name", logging.KeyReportFileID, rf.ID, logging.KeyFile, rf.Name)
+				unrepairable++
+			}
+
+			logging.FromContext(ctx).Info("db: rehash: checked", "files", len(rfs)+len(nopath), "updated", updated, "missing", missing, "unrepairable", unrepairable)
+			if dryRun {
+				logging.FromContext(ctx).Info("db: rehash: dry run, no changes written")
+			}
+			if missing+unrepairable > 0 {
+				return fmt.Errorf("%d report files could not be rehashed; upload them again to record their contents", missing+unrepairable)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	addDryRunFlag(cmd)
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+
+	return cmd
+}
+
+func cmdDbSchema() *cobra.Command {
+	var dbPath string
+	var format string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Document the database schema",
+		Long: `Introspect the tables, columns, foreign keys, and indexes of a database and
+write them out as a Markdown reference (md) or a Graphviz entity-relationship
+diagram (dot). The output reflects the live database, so run it against a
+database created by the current build to pick up schema changes.
+
+Examples:
+  tnrpt db schema --db data/amp/tnrpt.db > docs/SCHEMA.md
+  tnrpt db schema --db data/amp/tnrpt.db --format dot --output schema.dot
+  dot -Tsvg schema.dot -o schema.svg`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var write func(io.Writer, []sqlite.SchemaTable) error
+			switch format {
+			case "md":
+				write = sqlite.WriteSchemaMarkdown
+			case "dot":
+				write = sqlite.WriteSchemaDot
+			default:
+				return fmt.Errorf("unknown format %q (want md or dot)", format)
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			tables, err := store.DescribeSchema(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				return write(os.Stdout, tables)
+			}
+			fp, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("create output: %w", err)
+			}
+			if err := write(fp, tables); err != nil {
+				fp.Close()
+				return err
+			}
+			if err := fp.Close(); err != nil {
+				return fmt.Errorf("close output: %w", err)
+			}
+			logging.FromContext(cmd.Context()).Info("db: schema: wrote schema", "tables", len(tables), "path", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&format, "format", "md", "output format: md or dot")
+	cmd.Flags().StringVar(&output, "output", "", "write to this file instead of stdout")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbAPITokens() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "api-tokens",
+		Short: "Create, list, and revoke API tokens",
+	}
+	cmd.AddCommand(cmdDbAPITokensCreate())
+	cmd.AddCommand(cmdDbAPITokensList())
+	cmd.AddCommand(cmdDbAPITokensRevoke())
+	return cmd
+}
+
+func cmdDbAPITokensCreate() *cobra.Command {
+	var dbPath string
+	var handle string
+	var scopes []string
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an API token and print it",
+		Long: `Create a named API token for a user and print it. The token is shown once;
+only a hash of it is stored.
+
+Scripts send the token in an "Authorization: Bearer" header. A token with the
+gm scope can call /api/v1/ingest for as long as its user is a GM.
+
+Examples:
+  tnrpt db api-tokens create --db data/amp/tnrpt.db --user xtc69 --scope gm turn-gen`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			token, err := store.CreateAPIToken(ctx, handle, args[0], scopes)
+			if err != nil {
+				return err
+			}
+			fmt.Println(token)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&handle, "user", "", "handle of the user the token acts as (required)")
+	cmd.Flags().StringSliceVar(&scopes, "scope", []string{sqlite.APIScopeGM}, "capabilities granted to the token")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("user")
+
+	return cmd
+}
+
+func cmdDbAPITokensList() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List API tokens",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			tokens, err := store.APITokens(ctx)
+			if err != nil {
+				return err
+			}
+			for _, t := range tokens {
+				status, lastUsed := "active", "never"
+				if t.Revoked {
+					status = "revoked"
+				}
+				if !t.LastUsedAt.IsZero() {
+					lastUsed = t.LastUsedAt.Format(time.RFC3339)
+				}
+				fmt.Printf("%-12s %-20s %-8s %-8s created %s, last used %s\n",
+					t.Handle, t.Name, strings.Join(t.Scopes, ","), status, t.CreatedAt.Format(time.RFC3339), lastUsed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbAPITokensRevoke() *cobra.Command {
+	var dbPath string
+	var handle string
+
+	cmd := &cobra.Command{
+		Use:          "revoke <name>",
+		Short:        "Revoke a user's API token",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			found, err := store.RevokeAPIToken(ctx, handle, args[0])
+			if err != nil {
+				return err
+			} else if !found {
+				return fmt.Errorf("%s has no active token named %q", handle, args[0])
+			}
+			logging.FromContext(ctx).Info("db: api-tokens: revoked", logging.KeyUser, handle, "name", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&handle, "user", "", "handle of the token's user (required)")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("user")
+
+	return cmd
+}
+
+func cmdDbFlags() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "flags",
+		Short: "List and set feature flags",
+		Long: `Feature flags turn experimental behaviors on without a rebuild. A flag is
+set for the whole deployment, or for one game with --game; a game's setting
+wins. A flag that isn't set is off.`,
+	}
+	cmd.AddCommand(cmdDbFlagsClear())
+	cmd.AddCommand(cmdDbFlagsList())
+	cmd.AddCommand(cmdDbFlagsSet())
+	return cmd
+}
+
+func cmdDbFlagsList() *cobra.Command {
+	var dbPath string
+	var gameID string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the feature flags in effect",
+		Long: `Show each feature flag and whether it is on for the deployment, or for
+the game with --game.
+
+Examples:
+  tnrpt db flags list --db data/amp/tnrpt.db
+  tnrpt db flags list --db data/amp/tnrpt.db --game 0301 --output json`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			flags, err := store.FlagsForGame(ctx, gameID)
+			if err != nil {
+				return err
+			}
+
+			if outputFormat(cmd) == outputJSON {
+				type flagState struct {
+					Name        string `json:"name"`
+					Enabled     bool   `json:"enabled"`
+					Description string `json:"description"`
+				}
+				result := []flagState{}
+				for _, f := range model.FeatureFlags {
+					result = append(result, flagState{f.Name, flags.On(f.Name), f.Description})
+				}
+				return printJSON(result)
+			}
+
+			for _, f := range model.FeatureFlags {
+				state := "off"
+				if flags.On(f.Name) {
+					state = "on"
+				}
+				fmt.Printf("%-20s %-3s  %s\n", f.Name, state, f.Description)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&gameID, "game", "", "show the flags in effect for this game")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbFlagsSet() *cobra.Command {
+	var dbPath string
+	var gameID string
+
+	cmd := &cobra.Command{
+		Use:   "set <name> on|off",
+		Short: "Turn a feature flag on or off",
+		Long: `Turn a feature flag on or off for the deployment, or for one game with --game.
+
+Examples:
+  tnrpt db flags set --db data/amp/tnrpt.db parse.partial on
+  tnrpt db flags set --db data/amp/tnrpt.db --game 0301 parser.unit-split off`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var enabled bool
+			switch args[1] {
+			case "on":
+				enabled = true
+			case "off":
+			default:
+				return fmt.Errorf("flag value must be on or off, got %q", args[1])
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			if err := store.SetFeatureFlag(cmd.Context(), actor, args[0], gameID, enabled); err != nil {
+				return err
+			}
+			logging.FromContext(cmd.Context()).Info("db: flags: set", "flag", args[0], "state", args[1])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&gameID, "game", "", "set the flag for this game only")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbFlagsClear() *cobra.Command {
+	var dbPath string
+	var gameID string
+
+	cmd := &cobra.Command{
+		Use:   "clear <name>",
+		Short: "Remove a feature flag setting",
+		Long: `Remove a feature flag setting, so the game falls back to the deployment's
+setting and the deployment to off.
+
+Examples:
+  tnrpt db flags clear --db data/amp/tnrpt.db --game 0301 parser.unit-split`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			found, err := store.ClearFeatureFlag(cmd.Context(), actor, args[0], gameID)
+			if err != nil {
+				return err
+			} else if !found {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			logging.FromContext(cmd.Context()).Info("db: flags: cleared", "flag", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&gameID, "game", "", "clear the game's setting instead of the deployment's")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbLint() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "List and configure lint rules",
+		Long: `Lint rules check parsed reports for things that parse but are probably wrong,
+like a unit section without a status line. A rule is configured for the
+whole deployment, or for one game with --game; a game's setting wins. A rule
+that isn't configured runs with its defaults.`,
+	}
+	cmd.AddCommand(cmdDbLintClear())
+	cmd.AddCommand(cmdDbLintList())
+	cmd.AddCommand(cmdDbLintSet())
+	return cmd
+}
+
+func cmdDbLintList() *cobra.Command {
+	var dbPath string
+	var gameID string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the lint rules in effect",
+		Long: `Show each lint rule with its severity and limit for the deployment, or for
+the game with --game.
+
+Examples:
+  tnrpt db lint list --db data/amp/tnrpt.db
+  tnrpt db lint list --db data/amp/tnrpt.db --game 0301 --output json`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			cfg, err := store.LintConfigForGame(ctx, gameID)
+			if err != nil {
+				return err
+			}
+
+			type ruleState struct {
+				lint.RuleInfo
+				Enabled bool `json:"enabled"`
+			}
+			result := []ruleState{}
+			for _, rule := range lint.Default().Rules() {
+				rs := ruleState{RuleInfo: rule.Info(), Enabled: true}
+				if s, ok := cfg[rs.Name]; ok {
+					rs.Enabled = s.Enabled
+					if s.Severity != "" {
+						rs.Severity = s.Severity
+					}
+					if s.Limit > 0 {
+						rs.Limit = s.Limit
+					}
+				}
+				result = append(result, rs)
+			}
+
+			if outputFormat(cmd) == outputJSON {
+				return printJSON(result)
+			}
+
+			for _, rs := range result {
+				state := "off"
+				if rs.Enabled {
+					state = string(rs.Severity)
+				}
+				limit := "-"
+				if rs.Limit > 0 {
+					limit = fmt.Sprint(rs.Limit)
+				}
+				fmt.Printf("%-22s %-7s %5s  %s\n", rs.Name, state, limit, rs.Description)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&gameID, "game", "", "show the rules in effect for this game")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbLintSet() *cobra.Command {
+	var dbPath string
+	var gameID string
+	var severity string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "set <rule> on|off",
+		Short: "Turn a lint rule on or off",
+		Long: `Turn a lint rule on or off for the deployment, or for one game with --game.
+--severity and --limit override the rule's defaults.
+
+Examples:
+  tnrpt db lint set --db data/amp/tnrpt.db unit.missing-status off
+  tnrpt db lint set --db data/amp/tnrpt.db --game 0301 scout.max-moves on --limit 9 --severity error`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if lint.Default().Rule(args[0]) == nil {
+				return fmt.Errorf("unknown lint rule %q", args[0])
+			}
+			ls := model.LintSetting{Rule: args[0], GameID: gameID, Limit: limit}
+			switch args[1] {
+			case "on":
+				ls.Enabled = true
+			case "off":
+			default:
+				return fmt.Errorf("rule value must be on or off, got %q", args[1])
+			}
+			if severity != "" {
+				sev, err := model.ParseLintSeverity(severity)
+				if err != nil {
+					return err
+				}
+				ls.Severity = sev
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			if err := store.SetLintRule(cmd.Context(), actor, ls); err != nil {
+				return err
+			}
+			logging.FromContext(cmd.Context()).Info("db: lint: set", "rule", args[0], "state", args[1])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&gameID, "game", "", "set the rule for this game only")
+	cmd.Flags().StringVar(&severity, "severity", "", "report the rule's diagnostics as info, warning, or error")
+	cmd.Flags().IntVar(&limit, "limit", 0, "override the rule's limit, for rules that have one")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbLintClear() *cobra.Command {
+	var dbPath string
+	var gameID string
+
+	cmd := &cobra.Command{
+		Use:   "clear <rule>",
+		Short: "Remove a lint rule setting",
+		Long: `Remove a lint rule setting, so the game falls back to the deployment's
+setting and the deployment to the rule's defaults.
+
+Examples:
+  tnrpt db lint clear --db data/amp/tnrpt.db --game 0301 scout.max-moves`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			found, err := store.ClearLintRule(cmd.Context(), actor, args[0], gameID)
+			if err != nil {
+				return err
+			} else if !found {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			logging.FromContext(cmd.Context()).Info("db: lint: cleared", "rule", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&gameID, "game", "", "clear the game's setting instead of the deployment's")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbSteps() *cobra.Command {
+	var dbPath string
+	var output string
+	var game, clan, turn string
+
+	cmd := &cobra.Command{
+		Use:   "steps",
+		Short: "Write a clan's steps, one record per step, as JSON",
+		Long: `Write every step a clan's units took as a JSON array, one record per step
+with the turn, unit, hexes it went from and to, terrain, encounters, and
+borders. The hexes come from walking each unit from its starting hex, so
+this is the same data the web server serves at /steps.json. An --output
+file ending in .csv gets CSV rows instead. Records are written as they are
+made, so long games don't have to fit in memory.
+
+Examples:
+  tnrpt db steps --db data/amp/tnrpt.db --game 0301 --clan 0987 --output steps.json
+  tnrpt db steps --db data/amp/tnrpt.db --game 0301 --clan 0987 --output steps.csv
+  tnrpt db steps --db data/amp/tnrpt.db --game 0301 --clan 0987 --turn 0900-01`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			clanNo, err := strconv.Atoi(clan)
+			if err != nil || clanNo < 1 || clanNo > 9999 {
+				return fmt.Errorf("invalid clan %q", clan)
+			}
+			var turnNo model.TurnNo
+			if turn != "" {
+				if turnNo, err = model.ParseTurnNo(turn); err != nil {
+					return fmt.Errorf("invalid turn: %w", err)
+				}
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				fp, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("write steps: %w", err)
+				}
+				defer fp.Close()
+				w = fp
+			}
+
+			// records are written as they are made, a turn at a time
+			var write func(model.StepRecord) error
+			var count func() int
+			var close func() error
+			if strings.EqualFold(filepath.Ext(output), ".csv") {
+				cw, err := stream.NewCSV(w, model.StepRecordCSVHeader)
+				if err != nil {
+					return fmt.Errorf("write steps: %w", err)
+				}
+				write = func(rec model.StepRecord) error { return cw.Write(rec.CSV()) }
+				count, close = cw.Count, cw.Close
+			} else {
+				ja := stream.NewJSONArray(w, true)
+				write = func(rec model.StepRecord) error { return ja.Write(rec) }
+				count, close = ja.Count, ja.Close
+			}
+			if err := store.EachStepRecord(ctx, game, clanNo, turnNo, write); err != nil {
+				return fmt.Errorf("export steps: %w", err)
+			}
+			if err := close(); err != nil {
+				return fmt.Errorf("write steps: %w", err)
+			}
+			if output != "" {
+				logging.FromContext(ctx).Info("db: steps: exported", "steps", count(), "path", output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&game, "game", "", "game id, e.g. 0301 (required)")
+	cmd.Flags().StringVar(&clan, "clan", "", "clan number, e.g. 0987 (required)")
+	cmd.Flags().StringVar(&turn, "turn", "", "only this turn, e.g. 0900-01 (default all turns)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write to file instead of stdout")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("game")
+	cmd.MarkFlagRequired("clan")
+
+	return cmd
+}
+
+func cmdDbUsers() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Export and import users, roles, and clan assignments",
+	}
+	cmd.AddCommand(cmdDbUsersExport())
+	cmd.AddCommand(cmdDbUsersImport())
+	return cmd
+}
+
+func cmdDbUsersExport() *cobra.Command {
+	var dbPath string
+	var output string
+	var withHashes bool
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write every user, with roles and clans, as JSON",
+		Long: `Write every user, with their roles and clan assignments, as a JSON array.
+Password hashes are left out unless --with-hashes is given; treat a file
+that has them as a secret.
+
+Examples:
+  tnrpt db users export --db data/amp/tnrpt.db --output users.json
+  tnrpt db users export --db data/amp/tnrpt.db --with-hashes --output users.json`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			users, err := store.ExportUsers(ctx, withHashes)
+			if err != nil {
+				return fmt.Errorf("export users: %w", err)
+			}
+			data, err := json.MarshalIndent(users, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshal users: %w", err)
+			}
+			data = append(data, '\n')
+			if output == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			perm := os.FileMode(0o644)
+			if withHashes {
+				perm = 0o600
+			}
+			if err := os.WriteFile(output, data, perm); err != nil {
+				return fmt.Errorf("write users: %w", err)
+			}
+			logging.FromContext(ctx).Info("db: users: exported", "users", len(users), "path", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write to file instead of stdout")
+	cmd.Flags().BoolVar(&withHashes, "with-hashes", false, "include password hashes")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdDbUsersImport() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "import <users.json>",
+		Short: "Create or replace users from a users export",
+		Long: `Create or replace users, their roles, and their clan assignments from a file
+written by "tnrpt db users export". The import is all or nothing.
+
+Users without a password hash keep their current password; new users without
+one can't log in until a password is set. Plain-text passwords are rejected.
+Every game a user is assigned to must already exist.
+
+Examples:
+  tnrpt db users import --db data/amp/tnrpt.db users.json`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("read users: %w", err)
+			}
+			var users []sqlite.UserRecord
+			if err := json.Unmarshal(data, &users); err != nil {
+				return fmt.Errorf("parse users: %w", err)
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			result, err := store.ImportUsers(ctx, actor, users)
+			if err != nil {
+				return err
+			}
+			logging.FromContext(ctx).Info("db: users: imported", "users", len(users), "created", result.Created, "updated", result.Updated)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/parsers/azul"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/synth"
+	"github.com/spf13/cobra"
+)
+
+func cmdDevtools() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "devtools",
+		Short: "developer tools",
+		Long:  "Tools for working on the parsers and pipeline. Not needed for normal use.",
+	}
+	cmd.AddCommand(cmdDevtoolsCompareParsers())
+	cmd.AddCommand(cmdDevtoolsSynthGame())
+	cmd.AddCommand(cmdDevtoolsWhere())
+	return cmd
+}
+
+func cmdDevtoolsCompareParsers() *cobra.Command {
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "compare-parsers <turn-report-file>",
+		Short: "Compare azul and bistre parser output for a report",
+		Long: `Parse a turn report with both the azul and bistre parsers, adapt both
+results to the same model, and print the differences in units, moves, and
+observations. Exits with an error if the parsers disagree.
+
+Paths name the field that differs, for example "moves[2].report.terrain".
+A missing value means the field or unit was only found by one parser.
+
+Examples:
+  tnrpt devtools compare-parsers 0899-12.0987.report.txt
+  tnrpt devtools compare-parsers 0899-12.0987.docx --json`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			debug, _ := cmd.Flags().GetBool("debug")
+
+			input := args[0]
+			var data []byte
+			if strings.EqualFold(filepath.Ext(input), ".docx") {
+				doc, err := docx.ParsePath(input, true, true, quiet, verbose, debug)
+				if err != nil {
+					return err
+				}
+				data = doc.Text
+			} else {
+				var err error
+				if data, err = os.ReadFile(input); err != nil {
+					return err
+				}
+			}
+			data = bytes.ReplaceAll(data, []byte{'\r', '\n'}, []byte{'\n'})
+			data = bytes.ReplaceAll(data, []byte{'\r'}, []byte{'\n'})
+
+			azulTurn, err := azul.ParseInput(input, "", data, false, false, false, false, false, false, false, false, azul.ParseConfig{})
+			if err != nil {
+				return fmt.Errorf("azul: %w", err)
+			}
+			a, err := adapters.AzulParserTurnToModel(input, azulTurn)
+			if err != nil {
+				return fmt.Errorf("azul: adapt: %w", err)
+			}
+
+			bistreTurn, err := bistre.ParseInput(input, "", data, bistre.ParseConfig{Logger: logging.FromContext(cmd.Context())})
+			if err != nil {
+				return fmt.Errorf("bistre: %w", err)
+			}
+			b, err := adapters.BistreParserTurnToModel(input, bistreTurn)
+			if err != nil {
+				return fmt.Errorf("bistre: adapt: %w", err)
+			}
+
+			diffs, err := adapters.CompareTurns(a, b)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				out, err := json.MarshalIndent(diffs, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(out))
+			} else {
+				for _, d := range diffs {
+					fmt.Println(d.String())
+				}
+				if !quiet {
+					logging.FromContext(cmd.Context()).Info("devtools: compare-parsers", logging.KeyFile, input, "azul_units", len(a.UnitMoves), "bistre_units", len(b.UnitMoves), "differences", len(diffs))
+				}
+			}
+
+			if len(diffs) != 0 {
+				return fmt.Errorf("parsers disagree: %d differences", len(diffs))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print differences as JSON (a is azul, b is bistre)")
+	return cmd
+}
+
+func cmdDevtoolsWhere() *cobra.Command {
+	var unitID string
+	var actSeq, stepSeq int
+	cmd := &cobra.Command{
+		Use:   "where <turn-report-file> [line:col | @offset]",
+		Short: "Show which unit, act, and step each part of a report was parsed into",
+		Long: `Parse a turn report with the bistre parser and print, as JSON, the records
+read from a place in the text: the step, then its act, then the unit section,
+each with the line, column, and byte offset where it starts and ends. Lines
+and columns are 1-based; columns and offsets count bytes in the text after
+line endings are normalized to LF.
+
+With --unit (and --act and --step), print where that record was read from
+instead. With neither, print every record in the report, in text order.
+Editor plugins can use this to highlight how each line was interpreted.
+
+Examples:
+  tnrpt devtools where 0900-01.0987.report.txt 3:24
+  tnrpt devtools where 0900-01.0987.report.txt @135
+  tnrpt devtools where 0900-01.0987.report.txt --unit 0987 --act 1 --step 2
+  tnrpt devtools where 0301.0900-01.0987.docx`,
+		SilenceUsage: true,
+		Args:         cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			debug, _ := cmd.Flags().GetBool("debug")
+			if len(args) == 2 && unitID != "" {
+				return fmt.Errorf("use a position or --unit, not both")
+			}
+
+			input := args[0]
+			var data []byte
+			if strings.EqualFold(filepath.Ext(input), ".docx") {
+				doc, err := docx.ParsePath(input, true, true, quiet, verbose, debug)
+				if err != nil {
+					return err
+				}
+				data = doc.Text
+			} else {
+				var err error
+				if data, err = os.ReadFile(input); err != nil {
+					return err
+				}
+			}
+			data = bytes.ReplaceAll(data, []byte{'\r', '\n'}, []byte{'\n'})
+			data = bytes.ReplaceAll(data, []byte{'\r'}, []byte{'\n'})
+
+			turn, err := bistre.ParseInput(input, "", data, bistre.ParseConfig{Logger: logging.FromContext(cmd.Context())})
+			if err != nil {
+				return fmt.Errorf("bistre: %w", err)
+			}
+			rx, err := adapters.BistreTurnToModelReportX(input, turn, "", "", model.DefaultUnitIDRules, nil)
+			if err != nil {
+				return fmt.Errorf("bistre: adapt: %w", err)
+			}
+			sm := model.NewSourceMap(data, rx.Units)
+
+			found := []model.SourceEntity{}
+			switch {
+			case unitID != "":
+				e, ok := sm.Find(model.SrcRef{UnitID: unitID, ActSeq: actSeq, StepSeq: stepSeq})
+				if !ok {
+					return fmt.Errorf("%s: no record for unit %s act %d step %d", input, unitID, actSeq, stepSeq)
+				}
+				found = append(found, e)
+			case len(args) == 2:
+				var pos model.Position
+				var ok bool
+				if offset, isOffset := strings.CutPrefix(args[1], "@"); isOffset {
+					n, err := strconv.Atoi(offset)
+					if err != nil {
+						return fmt.Errorf("offset %q: %w", offset, err)
+					}
+					pos, ok = sm.Position(n)
+				} else if l, c, isLineCol := strings.Cut(args[1], ":"); isLineCol {
+					line, err1 := strconv.Atoi(l)
+					col, err2 := strconv.Atoi(c)
+					if err1 != nil || err2 != nil {
+						return fmt.Errorf("position %q: must be line:col or @offset", args[1])
+					}
+					pos, ok = sm.PositionAt(line, col)
+				} else {
+					return fmt.Errorf("position %q: must be line:col or @offset", args[1])
+				}
+				if !ok {
+					return fmt.Errorf("%s: position %s is not in the text", input, args[1])
+				}
+				found = append(found, sm.At(pos.Offset)...)
+			default:
+				found = append(found, sm.Entities()...)
+			}
+			return printJSON(found)
+		},
+	}
+	cmd.Flags().StringVar(&unitID, "unit", "", "print where this unit's section is")
+	cmd.Flags().IntVar(&actSeq, "act", 0, "with --unit, print where this act (1-based) is")
+	cmd.Flags().IntVar(&stepSeq, "step", 0, "with --act, print where this step (1-based) is")
+	return cmd
+}
+
+func cmdDevtoolsSynthGame() *cobra.Command {
+	var cfg synth.Config
+	var outDir, firstTurn, pattern string
+	cmd := &cobra.Command{
+		Use:   "synth-game --out <dir>",
+		Short: "Generate turn reports for a large made-up game",
+		Long: `Generate a synthetic game: a report text file per clan and turn for made-up
+clans whose tribes, elements, and couriers move over a made-up map. The
+reports parse like real ones, so performance work can be measured against
+large games without private player data. The same seed gives the same game.
+
+Files use drop-folder names, e.g. 0301.0899-12.0001.report.txt, so they can
+be fed to "tnrpt pipeline ingest" or "tnrpt pipeline watch". Clans are
+numbered from 0001; the game must exist before the reports are ingested.
+
+Patterns: still, random, line (keeps a heading), patrol (out and back), and
+mixed (each unit takes one of the others).
+
+Examples:
+  tnrpt devtools synth-game --out /tmp/synth
+  tnrpt devtools synth-game --out /tmp/synth --clans 200 --units 40 --turns 36 --pattern random --seed 42`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if firstTurn != "" {
+				turnNo, err := model.ParseTurnNo(firstTurn)
+				if err != nil {
+					return fmt.Errorf("--first-turn: %w", err)
+				}
+				cfg.FirstTurn = turnNo
+			}
+			cfg.Pattern = synth.Pattern(pattern)
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return err
+			}
+
+			files, size := 0, 0
+			err := synth.Generate(cfg, func(r synth.Report) error {
+				files, size = files+1, size+len(r.Text)
+				return os.WriteFile(filepath.Join(outDir, r.Name()), r.Text, 0o644)
+			})
+			if err != nil {
+				return err
+			}
+			if outputFormat(cmd) == outputJSON {
+				return printJSON(map[string]any{"dir": outDir, "files": files, "bytes": size})
+			}
+			fmt.Printf("devtools: synth-game: %d reports (%d bytes) written to %s\n", files, size, outDir)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&outDir, "out", "", "directory to write the reports to (required)")
+	cmd.Flags().StringVar(&cfg.Game, "game", "0301", "game id")
+	cmd.Flags().IntVar(&cfg.Clans, "clans", 10, "number of clans")
+	cmd.Flags().IntVar(&cfg.UnitsPerClan, "units", 5, fmt.Sprintf("units per clan, at most %d", synth.MaxUnitsPerClan))
+	cmd.Flags().IntVar(&cfg.Turns, "turns", 12, "number of turns")
+	cmd.Flags().StringVar(&firstTurn, "first-turn", "", "first turn, e.g. 0900-01 (default 0899-12)")
+	cmd.Flags().StringVar(&pattern, "pattern", string(synth.PatternMixed), "how units move: still, random, line, patrol, or mixed")
+	cmd.Flags().IntVar(&cfg.MaxSteps, "max-steps", 4, "most hexes a unit enters in a turn")
+	cmd.Flags().IntVar(&cfg.MovePoints, "move-points", 15, "most movement points a unit spends in a turn")
+	cmd.Flags().Uint64Var(&cfg.Seed, "seed", 1, "random seed")
+	cmd.Flags().IntVar(&cfg.World.GridRows, "grid-rows", 26, "rows of maps in the world, 1..26")
+	cmd.Flags().IntVar(&cfg.World.GridCols, "grid-cols", 26, "columns of maps in the world, 1..26")
+	cmd.MarkFlagRequired("out")
+	return cmd
+}
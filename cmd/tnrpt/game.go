@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	sqlite "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/cobra"
+)
+
+func cmdGame() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "game",
+		Short: "Move a whole game between servers",
+	}
+	cmd.AddCommand(cmdGameExport())
+	cmd.AddCommand(cmdGameImport())
+	return cmd
+}
+
+func cmdGameExport() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var gameID string
+	var output string
+	var redactUsers bool
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write a game, its reports, and its players to a zip archive",
+		Long: `Write everything stored for a game to a single zip archive: the game and
+its turns, the users who hold its clans, every report file with its blob,
+the units, moves, encounters, and borders parsed from them, and imported
+orders. Tiles are rebuilt from the reports on import.
+
+Password hashes and email addresses are included unless --redact-users is
+given; treat an archive that has them as a secret.
+
+Examples:
+  tnrpt game export --db data/amp/tnrpt.db --data-dir data/amp --game 0301 -o 0301.zip
+  tnrpt game export --db data/amp/tnrpt.db --data-dir data/amp --game 0301 --redact-users -o 0301.zip`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if output == "" {
+				output = gameID + ".zip"
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			svc := stages.NewGameArchiveService(store, dataDir)
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			svc.SetFS(fsys)
+
+			perm := os.FileMode(0o600)
+			if redactUsers {
+				perm = 0o644
+			}
+			f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+			if err != nil {
+				return fmt.Errorf("create archive: %w", err)
+			}
+			a, err := svc.Export(ctx, gameID, redactUsers, f)
+			if closeErr := f.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("write archive: %w", closeErr)
+			}
+			if err != nil {
+				_ = os.Remove(output)
+				return fmt.Errorf("export game: %w", err)
+			}
+
+			if outputFormat(cmd) == outputJSON {
+				return printJSON(map[string]any{
+					"game": gameID, "output": output, "users": len(a.Users), "files": len(a.Files),
+					"reports": len(a.Reports), "tiles": a.Tiles,
+				})
+			}
+			fmt.Printf("game: export: %s: %d users, %d report files, %d reports, %d tiles written to %s\n",
+				gameID, len(a.Users), len(a.Files), len(a.Reports), a.Tiles, output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().StringVar(&gameID, "game", "", "game id, e.g. 0301 (required)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "archive to write (default <game>.zip)")
+	cmd.Flags().BoolVar(&redactUsers, "redact-users", false, "leave out users' email addresses and password hashes")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+	cmd.MarkFlagRequired("game")
+
+	return cmd
+}
+
+func cmdGameImport() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var grantGM bool
+
+	cmd := &cobra.Command{
+		Use:   "import <archive.zip>",
+		Short: "Create a game from an archive written by game export",
+		Long: `Create a game from an archive written by "tnrpt game export". The game must
+not already exist. Report file blobs are copied to <data-dir>/games/<game>,
+and the map tiles are rebuilt from the imported reports.
+
+Users that don't exist are created with the archive's roles, except gm,
+which is only granted with --grant-gm. Those without a password hash (a
+redacted export) can't log in until one is set. Users that already exist
+keep their details and are only given their clans.
+
+Examples:
+  tnrpt game import --db data/new/tnrpt.db --data-dir data/new 0301.zip`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("open archive: %w", err)
+			}
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil {
+				return fmt.Errorf("open archive: %w", err)
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			svc := stages.NewGameArchiveService(store, dataDir)
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			svc.SetFS(fsys)
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			result, err := svc.Import(ctx, actor, f, info.Size(), grantGM)
+			if err != nil {
+				return fmt.Errorf("import game: %w", err)
+			}
+
+			if outputFormat(cmd) == outputJSON {
+				return printJSON(result)
+			}
+			fmt.Printf("game: import: %s: %d new users, %d report files, %d reports, %d units, %d tiles\n",
+				args[0], result.Users, result.Files, result.Reports, result.Units, result.Tiles)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().BoolVar(&grantGM, "grant-gm", false, "give new users the gm role if the archive gives it to them")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+
+	return cmd
+}
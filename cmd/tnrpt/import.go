@@ -0,0 +1,207 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	sqlite "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/cobra"
+)
+
+func cmdImport() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import reports from outside the pipeline",
+	}
+	cmd.AddCommand(cmdImportArchive())
+	return cmd
+}
+
+func cmdImportArchive() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var game string
+	var clan string
+	var mappingPath string
+	var checkpointPath string
+	var interactive bool
+
+	cmd := &cobra.Command{
+		Use:   "archive <dir|zip>",
+		Short: "Import years of old turn reports from a folder or ZIP file",
+		Long: `Walk a folder, or a ZIP file, of old turn reports and ingest every .docx
+and .txt file into the pipeline, each in its own upload batch. Other files,
+dot files, and __MACOSX folders are ignored.
+
+The game, clan, and turn of each file are inferred from its path. Drop-folder
+names (GGGG.YYYY-MM.CCCC.docx) are recognized, as are folders and names like
+"0301/0512/0899-12.docx" or "Game 301/Clan 512/turn 899_12.docx". Use --game
+and --clan for values the paths don't carry. For anything else, a mapping
+file of "path-prefix,game,clan,turn" CSV records overrides inference (empty
+fields are still inferred; the longest matching prefix wins), and with
+--interactive you are asked about files that still can't be placed.
+
+Progress is saved to a checkpoint file after every file, so an interrupted
+import picks up where it stopped when run again with the same source. Files
+that couldn't be placed are not checkpointed; add them to a mapping file and
+run again. Files that are already in the database (same SHA-256) are counted
+as duplicates.
+
+When the walk ends, a summary lists the files imported for each clan, in the
+checkpoint as a whole, and any turns missing between each clan's first and
+last report.
+
+Examples:
+  tnrpt import archive --db data/amp/tnrpt.db --data-dir data/amp ~/tribenet
+  tnrpt import archive --db data/amp/tnrpt.db --data-dir data/amp --game 0301 --clan 0512 old-reports.zip
+  tnrpt import archive --db data/amp/tnrpt.db --data-dir data/amp --mapping mapping.csv --interactive ~/tribenet`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src := filepath.Clean(args[0])
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			if _, err := fsys.Stat(src); err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+			if checkpointPath == "" {
+				checkpointPath = filepath.Join(dataDir, "import-"+filepath.Base(src)+".checkpoint.json")
+			}
+
+			defaults := stages.ImportGuess{Game: game, ClanNo: clan}
+			var mappings []stages.ImportMapping
+			if mappingPath != "" {
+				fd, err := os.Open(mappingPath)
+				if err != nil {
+					return fmt.Errorf("import: %w", err)
+				}
+				mappings, err = stages.ReadImportMappings(fd)
+				fd.Close()
+				if err != nil {
+					return fmt.Errorf("import: %s: %w", mappingPath, err)
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			cp, err := stages.LoadImportCheckpoint(fsys, checkpointPath, src)
+			if err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+			if len(cp.Files) != 0 {
+				logging.FromContext(ctx).Info("import: archive: resuming", "checkpoint", checkpointPath, "done", len(cp.Files))
+			}
+
+			createdBy := fmt.Sprintf("import:%s", os.Getenv("USER"))
+			ingest := stages.NewIngestService(store, dataDir)
+			ingest.SetFS(fsys)
+			importer := stages.NewImportService(ingest, createdBy)
+			importer.SetFS(fsys)
+			importer.SetDefaults(defaults)
+			importer.SetMappings(mappings)
+			if interactive {
+				importer.SetResolver(promptImportGuess(bufio.NewReader(os.Stdin)))
+			}
+
+			sum, err := importer.Import(ctx, src, cp)
+			if err != nil {
+				// the checkpoint has everything up to the failure
+				return fmt.Errorf("import: %w (run again to resume)", err)
+			}
+
+			if outputFormat(cmd) == outputJSON {
+				return printJSON(sum)
+			}
+			for _, c := range sum.Clans {
+				missing := "none"
+				if len(c.Missing) != 0 {
+					var list []string
+					for _, t := range c.Missing {
+						list = append(list, t.String())
+					}
+					missing = strings.Join(list, " ")
+				}
+				fmt.Printf("import: archive: game %s clan %s: %4d files, %s to %s, missing: %s\n",
+					c.Game, c.ClanNo, c.Files, c.First, c.Last, missing)
+			}
+			for _, name := range sum.Unresolved {
+				fmt.Printf("import: archive: not placed: %s\n", name)
+			}
+			fmt.Printf("import: archive: %d ingested, %d duplicates, %d already done, %d not reports, %d not placed\n",
+				sum.Ingested, sum.Duplicates, sum.Resumed, sum.Ignored, len(sum.Unresolved))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().StringVar(&game, "game", "", "game ID for files whose path doesn't name one (e.g., 0301)")
+	cmd.Flags().StringVar(&clan, "clan", "", "clan number for files whose path doesn't name one (e.g., 0512)")
+	cmd.Flags().StringVar(&mappingPath, "mapping", "", "CSV file of path-prefix,game,clan,turn overrides")
+	cmd.Flags().StringVar(&checkpointPath, "checkpoint", "", "checkpoint file (default <data-dir>/import-<source>.checkpoint.json)")
+	cmd.Flags().BoolVar(&interactive, "interactive", false, "ask about files that can't be placed")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+
+	return cmd
+}
+
+// promptImportGuess asks on stdout for the parts of a guess that are missing.
+// An empty answer keeps the value shown in brackets; "skip" skips the file.
+func promptImportGuess(in *bufio.Reader) stages.ImportResolver {
+	ask := func(label, value string) (string, bool) {
+		fmt.Printf("  %s [%s]: ", label, value)
+		line, err := in.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil && line == "" || line == "skip" {
+			return "", false
+		} else if line == "" {
+			return value, true
+		}
+		return line, true
+	}
+	return func(path string, guess stages.ImportGuess) (stages.ImportGuess, bool) {
+		fmt.Printf("%s: can't tell the game, clan, and turn (enter \"skip\" to skip)\n", path)
+		for !guess.Complete() {
+			var ok bool
+			if guess.Game, ok = ask("game", guess.Game); !ok {
+				return guess, false
+			}
+			if guess.ClanNo, ok = ask("clan", guess.ClanNo); !ok {
+				return guess, false
+			}
+			turn := ""
+			if guess.TurnNo.Valid() {
+				turn = guess.TurnNo.String()
+			}
+			if turn, ok = ask("turn", turn); !ok {
+				return guess, false
+			}
+			t, err := model.ParseTurnNo(turn)
+			if err != nil {
+				fmt.Printf("  %v\n", err)
+			}
+			guess.TurnNo = t
+		}
+		return guess, true
+	}
+}
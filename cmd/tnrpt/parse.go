@@ -0,0 +1,445 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/mdhender/phrases/v2"
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/parsers"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/pipelines/parsers/report"
+	sqlite "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/handlers"
+	"github.com/spf13/cobra"
+)
+
+func cmdParse() *cobra.Command {
+	autoEOL := true
+	stripCR := false
+	var configFile string
+	var excludeUnits []string
+	var includeUnits []string
+	var outputFile string
+	addFlags := func(cmd *cobra.Command) error {
+		cmd.Flags().BoolVar(&autoEOL, "auto-eol", autoEOL, "automatically convert line endings")
+		cmd.Flags().StringVarP(&configFile, "config-file", "c", configFile, "load configuration from file")
+		cmd.Flags().StringSliceVarP(&excludeUnits, "exclude", "e", excludeUnits, "exclude units matching pattern (ID or prefix*)")
+		cmd.Flags().StringSliceVarP(&includeUnits, "include", "i", includeUnits, "include only units matching pattern (ID or prefix*)")
+		cmd.Flags().StringVarP(&outputFile, "output", "o", outputFile, "save parse to file")
+		cmd.Flags().BoolVar(&stripCR, "strip-cr", stripCR, "strip CR from end-of-lines")
+		return nil
+	}
+	var cmd = &cobra.Command{
+		Use:   "parse <turn-report-file>",
+		Short: "parse a turn report file (text or Word)",
+		Long: `Parse a turn report file and print the units and moves as JSON.
+
+Unit filters:
+  --include and --exclude take unit IDs or prefixes ending in "*" and may be
+  repeated or comma-separated. "0987*" matches tribe 0987 and all of its units.
+  Matching is case-insensitive, and --exclude wins over --include.
+
+Examples:
+  tnrpt parse 0899-12.0987.report.txt
+  tnrpt parse 0899-12.0987.report.txt --include 0987e1,0987c1
+  tnrpt parse 0899-12.0987.report.txt --include '0987*' --exclude '0987f*'`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1), // require path to turn report file
+		RunE: func(cmd *cobra.Command, args []string) error {
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			debug, _ := cmd.Flags().GetBool("debug")
+			if quiet {
+				verbose = false
+			}
+
+			if configFile != "" {
+				return fmt.Errorf("error: --config-file is not implemented")
+			}
+
+			ctx := cmd.Context()
+			turn, err := parsers.ParseTurnReport(args[0], autoEOL, stripCR, quiet, verbose, debug)
+			if err != nil {
+				return err
+			}
+			at, err := adapters.AzulParserTurnToModel(args[0], turn)
+			if err != nil {
+				return err
+			}
+			filter := adapters.UnitFilter{Include: includeUnits, Exclude: excludeUnits}
+			if n := adapters.FilterTurnUnits(at, filter); n > 0 && verbose {
+				logging.FromContext(ctx).Info("parse: filtered out units", logging.KeyFile, args[0], "units", n)
+			}
+			if data, err := json.MarshalIndent(at, "", "  "); err != nil {
+				return fmt.Errorf("json: %w", err)
+			} else if outputFile == "" {
+				fmt.Println(string(data))
+			} else if err = os.WriteFile(outputFile, data, 0o644); err != nil {
+				return err
+			} else {
+				logging.FromContext(ctx).Info("parse: wrote", "path", outputFile, "bytes", len(data))
+			}
+
+			return nil
+		},
+	}
+	if err := addFlags(cmd); err != nil {
+		log.Fatal(err)
+	}
+	return cmd
+}
+
+func cmdPhrase() *cobra.Command {
+	length := 6
+	addFlags := func(cmd *cobra.Command) error {
+		cmd.Flags().IntVar(&length, "length", length, "number of words in phrase")
+		return nil
+	}
+	var cmd = &cobra.Command{
+		Use:   "phrase",
+		Short: "random phrase",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if length < 1 {
+				length = 1
+			} else if length > 16 {
+				length = 16
+			}
+			fmt.Println(phrases.Generate(length))
+			return nil
+		},
+	}
+	if err := addFlags(cmd); err != nil {
+		log.Fatal(err)
+	}
+	return cmd
+}
+
+func cmdBistreParse() *cobra.Command {
+	var docxFile string // := filepath.Join("testdata", "0301.0899-12.0987.docx")
+	var textFile string // := filepath.Join("testdata", "0301.0899-12.0987.txt")
+	var email bool
+	var game, clanNo string
+	normalizeCRLF, normalizeCR := true, true
+	showDBStats := false
+	showReportSections := false
+	showReportSectionLines := false
+	showText := false
+	showTiming := false
+	trimLeading, trimTrailing := true, true
+	var serve bool
+	var serveNoAuth bool
+	var serveAddr string
+	var insecureDev bool
+	var staticDir string
+	addFlags := func(cmd *cobra.Command) error {
+		cmd.Flags().StringVar(&docxFile, "docx", docxFile, "import docx file")
+		cmd.Flags().StringVar(&textFile, "text", textFile, "import text file")
+		cmd.Flags().BoolVar(&email, "email", email, "the text file is an email body: decode quoted-printable, strip quote prefixes, and unwrap lines")
+		cmd.Flags().StringVar(&game, "game", game, "game identifier")
+		cmd.Flags().StringVar(&clanNo, "clan", clanNo, "clan number")
+		cmd.Flags().BoolVar(&normalizeCR, "normalize-cr", normalizeCR, "change CR to LF at end-of-line")
+		cmd.Flags().BoolVar(&normalizeCRLF, "normalize-cr-lf", normalizeCRLF, "change CR+LF to LF at end-of-line")
+		cmd.Flags().BoolVar(&showDBStats, "show-db-stats", showDBStats, "dump row counts from each table")
+		cmd.Flags().BoolVar(&showReportSections, "show-report-sections", showReportSections, "show report sections")
+		cmd.Flags().BoolVar(&showReportSectionLines, "show-section-lines", showReportSectionLines, "show report section lines")
+		cmd.Flags().BoolVar(&showText, "show-text", showText, "show report text")
+		cmd.Flags().BoolVar(&showTiming, "show-timing", showText, "show timing for each stage")
+		cmd.Flags().BoolVar(&trimLeading, "trim-leading-spaces", trimLeading, "trim leading spaces on import")
+		cmd.Flags().BoolVar(&trimLeading, "trim-trailing-spaces", trimTrailing, "trim trailing spaces on import")
+		cmd.Flags().BoolVar(&serve, "serve", false, "start HTTP server after parsing")
+		cmd.Flags().BoolVar(&serveNoAuth, "serve-no-auth", false, "start HTTP server without authentication (requires --insecure-dev)")
+		cmd.Flags().StringVar(&serveAddr, "serve-addr", ":8787", "HTTP server listen address")
+		cmd.Flags().BoolVar(&insecureDev, "insecure-dev", false, "allow --serve-no-auth; the server only listens on localhost")
+		cmd.Flags().StringVar(&staticDir, "static", "web/static", "static files directory")
+		return nil
+	}
+	var cmd = &cobra.Command{
+		Use:          "bistre",
+		Short:        "Run the bistre parser pipeline (legacy synchronous path)",
+		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// cascade show flags up
+			if showReportSectionLines {
+				showReportSections = true
+			}
+			if serveNoAuth && !insecureDev {
+				return fmt.Errorf("--serve-no-auth skips logging in and requires --insecure-dev")
+			}
+			if insecureDev {
+				addr, err := auth.LoopbackAddr(serveAddr)
+				if err != nil {
+					return fmt.Errorf("--insecure-dev: %w", err)
+				}
+				serveAddr = addr
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			debug, _ := cmd.Flags().GetBool("debug")
+			if quiet {
+				verbose = false
+			}
+
+			var err error
+
+			// Open in-memory database
+			store, err := sqlite.NewSQLiteStore()
+			if err != nil {
+				return fmt.Errorf("create store: %w", err)
+			}
+			defer store.Close()
+
+			startedPipeline, startedStage := time.Now(), time.Now()
+			var doc *docx.Docx
+			var data []byte // original file contents, used for the report file hash
+			if docxFile != "" {
+				data, err = os.ReadFile(docxFile)
+				if err != nil {
+					return err
+				}
+				doc, err = docx.ParsePath(docxFile, trimLeading, trimTrailing, quiet, verbose, debug)
+				if err != nil {
+					return err
+				}
+				if showTiming {
+					logging.FromContext(ctx).Info("bistre: parsed docx", logging.KeyFile, filepath.Base(doc.Source), "elapsed", time.Since(startedStage))
+				}
+			}
+			if textFile != "" {
+				doc = &docx.Docx{
+					Source: textFile,
+					Text:   nil,
+				}
+				data, err = os.ReadFile(textFile)
+				if err != nil {
+					return err
+				}
+				doc.Text = data
+				if email {
+					if doc.Text, err = report.FromEmail(data, report.EmailWidth); err != nil {
+						return err
+					}
+				}
+				if showTiming {
+					logging.FromContext(ctx).Info("bistre: parsed text", logging.KeyFile, filepath.Base(doc.Source), "elapsed", time.Since(startedStage))
+				}
+			}
+			if doc == nil {
+				return fmt.Errorf("missing import")
+			}
+
+			logging.FromContext(ctx).Info("bistre: read", "path", doc.Source)
+			if showText {
+				fmt.Println(string(doc.Text))
+			}
+
+			startedStage = time.Now()
+			rpt, err := report.ParseReportText(doc, normalizeCRLF, normalizeCR, quiet, verbose, debug)
+			if err != nil {
+				return err
+			}
+			if showTiming {
+				logging.FromContext(ctx).Info("bistre: split report", logging.KeyFile, rpt.Name, "elapsed", time.Since(startedStage))
+			}
+
+			logging.FromContext(ctx).Info("bistre: report", "path", rpt.Path, logging.KeyFile, rpt.Name, logging.KeyTurn, rpt.TurnNo, "sections", len(rpt.Sections))
+			for _, warning := range rpt.Warnings {
+				logging.FromContext(ctx).Warn("bistre: report", logging.KeyFile, rpt.Name, "warning", warning)
+			}
+
+			if showReportSections {
+				for n, section := range rpt.Sections {
+					fmt.Printf("section %3d: unit %q\n", n+1, section.UnitId)
+					fmt.Printf("section %3d: turn %q\n", n+1, section.TurnNo)
+					if showReportSectionLines {
+						for no, line := range section.Lines {
+							fmt.Printf("section %3d: %4d: %s\n", n+1, no+1, string(line))
+						}
+					}
+				}
+			}
+
+			var text []byte
+			for _, section := range rpt.Sections {
+				text = append(text, bytes.Join(section.Lines, []byte{'\n'})...)
+				text = append(text, '\n')
+			}
+
+			startedStage = time.Now()
+			turn, err := bistre.ParseInput(rpt.Name, rpt.TurnNo, text, bistre.ParseConfig{Logger: logging.FromContext(ctx)})
+			if err != nil {
+				return err
+			} else if turn == nil {
+				return fmt.Errorf("parser returned nil, nil")
+			}
+			if showTiming {
+				logging.FromContext(ctx).Info("bistre: parsed input", logging.KeyFile, rpt.Name, "elapsed", time.Since(startedStage))
+			}
+
+			logging.FromContext(ctx).Info("bistre: parsed turn", logging.KeyFile, rpt.Name, logging.KeyTurn, turn.Id, "year", turn.Year, "month", turn.Month)
+			if foundTurnNo := fmt.Sprintf("%d-%02d", turn.Year, turn.Month); rpt.TurnNo != foundTurnNo {
+				if turn.Year == 0 && turn.Month == 0 {
+					logging.FromContext(ctx).Error("bistre: unable to locate turn information in file; this is usually caused by unexpected line endings, try running with --auto-eol",
+						logging.KeyFile, rpt.Name)
+					return fmt.Errorf("unable to find current turn in source")
+				}
+				logging.FromContext(ctx).Error("bistre: unexpected turn", logging.KeyFile, rpt.Name, "expected", rpt.TurnNo, "found", foundTurnNo)
+				return fmt.Errorf("unexpected current turn in source")
+			}
+
+			startedStage = time.Now()
+			at, err := adapters.BistreParserTurnToModel(rpt.Name, turn)
+			if err != nil {
+				return err
+			} else if at == nil {
+				return fmt.Errorf("adapter returned nil, nil")
+			}
+			_ = at // retained for compatibility; new code uses Store
+			if showTiming {
+				logging.FromContext(ctx).Info("bistre: adapted turn", logging.KeyFile, rpt.Name, "elapsed", time.Since(startedStage))
+			}
+
+			// Persist to in-memory database
+			startedStage = time.Now()
+			_, _, err = adapters.BistreTurnToStore(ctx, store, rpt.Name, data, turn, game, clanNo)
+			if err != nil {
+				return fmt.Errorf("persist to store: %w", err)
+			}
+			if showTiming {
+				logging.FromContext(ctx).Info("bistre: wrote store", logging.KeyFile, rpt.Name, "elapsed", time.Since(startedStage))
+			}
+
+			// Show database stats if requested
+			if showDBStats {
+				stats, err := store.TableStats(ctx)
+				if err != nil {
+					return fmt.Errorf("get table stats: %w", err)
+				}
+				if outputFormat(cmd) == outputJSON {
+					if err := printJSON(stats); err != nil {
+						return err
+					}
+				} else {
+					fmt.Println("database stats:")
+					tables := make([]string, 0, len(stats))
+					for table := range stats {
+						tables = append(tables, table)
+					}
+					sort.Strings(tables)
+					for _, table := range tables {
+						if stats[table] > 0 {
+							fmt.Printf("  %-20s %d rows\n", table, stats[table])
+						}
+					}
+				}
+			}
+
+			if showTiming {
+				logging.FromContext(ctx).Info("bistre: pipeline completed", logging.KeyFile, rpt.Name, "elapsed", time.Since(startedPipeline))
+			}
+
+			if serve || serveNoAuth {
+				rx, err := adapters.BistreTurnToModelReportX(rpt.Name, turn, game, clanNo, model.DefaultUnitIDRules, nil)
+				if err != nil {
+					return fmt.Errorf("adapt to model: %w", err)
+				}
+
+				sqliteStore, err := sqlite.NewSQLiteStore()
+				if err != nil {
+					return fmt.Errorf("create SQLite store: %w", err)
+				}
+				defer sqliteStore.Close()
+
+				if err := sqliteStore.AddReport(rx); err != nil {
+					return fmt.Errorf("add report to store: %w", err)
+				}
+
+				stats := sqliteStore.Stats()
+				logging.FromContext(ctx).Info("bistre: store", "reports", stats.Reports, "units", stats.Units, "acts", stats.Acts, "steps", stats.Steps)
+
+				sessions := auth.NewSessionStore()
+				h := handlers.New(sqliteStore, sessions)
+
+				mux := http.NewServeMux()
+				fs := http.FileServer(http.Dir(staticDir))
+				mux.Handle("/static/", http.StripPrefix("/static/", fs))
+				mux.HandleFunc("/", h.Index)
+
+				if serveNoAuth {
+					mux.HandleFunc("/units", h.UnitsNoAuth)
+				} else {
+					mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+						if r.Method == http.MethodPost {
+							h.Login(w, r)
+						} else {
+							h.LoginPage(w, r)
+						}
+					})
+					mux.HandleFunc("/logout", h.Logout)
+					mux.HandleFunc("/units", h.RequireAuth(h.Units))
+				}
+
+				server := &http.Server{
+					Addr:         serveAddr,
+					Handler:      logging.Middleware(logging.FromContext(ctx), mux),
+					ReadTimeout:  15 * time.Second,
+					WriteTimeout: 15 * time.Second,
+					IdleTimeout:  60 * time.Second,
+				}
+
+				shutdown := make(chan os.Signal, 1)
+				signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+
+				go func() {
+					logging.FromContext(ctx).Info("server: listening", "addr", serveAddr)
+					if serveNoAuth {
+						logging.FromContext(ctx).Warn("server: authentication disabled (--serve-no-auth)")
+						auth.WriteInsecureBanner(os.Stderr, "no login required", serveAddr)
+					}
+					if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						logging.FromContext(ctx).Error("server", "err", err)
+						os.Exit(1)
+					}
+				}()
+
+				<-shutdown
+				logging.FromContext(ctx).Info("server: shutting down gracefully")
+
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+
+				if err := server.Shutdown(shutdownCtx); err != nil {
+					return fmt.Errorf("server shutdown: %w", err)
+				}
+				logging.FromContext(ctx).Info("server: stopped")
+			}
+
+			return nil
+		},
+	}
+	if err := addFlags(cmd); err != nil {
+		log.Fatal(err)
+	}
+	return cmd
+}
@@ -0,0 +1,1068 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	sqlite "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func cmdPipeline() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pipeline",
+		Short: "pipeline commands for report processing",
+		Long:  "Commands for ingesting, processing, and tracking report files through the pipeline.",
+	}
+	cmd.AddCommand(cmdPipelineIngest())
+	cmd.AddCommand(cmdPipelineIngestMaster())
+	cmd.AddCommand(cmdPipelinePause())
+	cmd.AddCommand(cmdPipelineResume())
+	cmd.AddCommand(cmdPipelineStatus())
+	cmd.AddCommand(cmdPipelineWatch())
+	cmd.AddCommand(cmdPipelineWork())
+	return cmd
+}
+
+func cmdPipelineIngest() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var game string
+	var clan string
+	var turn string
+
+	cmd := &cobra.Command{
+		Use:   "ingest <file>...",
+		Short: "Ingest turn report files into the pipeline",
+		Long: `Ingest one or more turn report files (.docx or .txt) into the pipeline.
+
+Creates an upload batch and queues work items for processing:
+  - DOCX files are queued for the 'extract' stage
+  - TXT files are queued directly for the 'parse' stage
+
+Files are copied to {data-dir}/batches/{batch_id}/ with standardized names.
+Duplicate files (same SHA-256) are silently skipped (idempotent).
+
+Examples:
+  tnrpt pipeline ingest --db data/amp/tnrpt.db --data-dir data/amp --game 0301 --clan 0512 --turn 89912 *.docx
+  tnrpt pipeline ingest --db data/amp/tnrpt.db --data-dir data/amp --game 0301 --clan 0512 --turn 0899-12 report.txt`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			turnNo, err := model.ParseTurnNo(turn)
+			if err != nil {
+				return err
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			svc := stages.NewIngestService(store, dataDir)
+			svc.SetFS(fsys)
+
+			var files []stages.IngestRequest
+			for _, path := range args {
+				data, err := afero.ReadFile(fsys, path)
+				if err != nil {
+					return fmt.Errorf("read %s: %w", path, err)
+				}
+				files = append(files, stages.IngestRequest{
+					Filename: filepath.Base(path),
+					Data:     data,
+				})
+			}
+
+			createdBy := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			batchID, results, err := svc.IngestBatch(ctx, game, clan, turnNo, createdBy, files)
+			if err != nil {
+				return fmt.Errorf("ingest batch: %w", err)
+			}
+
+			duplicates := 0
+			ingested := 0
+			for _, r := range results {
+				if r.Duplicate {
+					duplicates++
+				} else {
+					ingested++
+				}
+			}
+
+			logging.FromContext(ctx).Info("pipeline: ingest", logging.KeyBatch, batchID, "ingested", ingested, "duplicates", duplicates)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().StringVar(&game, "game", "", "game ID (e.g., 0301)")
+	cmd.Flags().StringVar(&clan, "clan", "", "clan number (e.g., 0512)")
+	cmd.Flags().StringVar(&turn, "turn", "", "turn (e.g., 0899-12 or 89912 for year 899, month 12)")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+	cmd.MarkFlagRequired("game")
+	cmd.MarkFlagRequired("clan")
+	cmd.MarkFlagRequired("turn")
+
+	return cmd
+}
+
+func cmdPipelineIngestMaster() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var game string
+	var turn string
+
+	cmd := &cobra.Command{
+		Use:   "ingest-master <file.txt|file.zip>...",
+		Short: "Split GM master files into clan reports and ingest them",
+		Long: `Split GM master files, the text reports of every clan in one file, into
+one report per clan and ingest each in its own batch.
+
+A clan's report starts at the first section for one of its units (Tribe 0987,
+Courier 0987c1, Tribe 1987, ...) and runs until a section for another clan.
+Each clan's sections must be together. A ZIP file is read for the .txt
+master files in it.
+
+A master file is read twice, once to check it and once to ingest it, and only
+one clan's report is held in memory at a time. A master file in a ZIP file is
+loaded whole first.
+
+Without --turn, each clan's turn is read from its "Current Turn" line. With
+--turn, every clan must agree with it. Nothing is ingested from a file that
+fails these checks.
+
+Examples:
+  tnrpt pipeline ingest-master --db data/amp/tnrpt.db --data-dir data/amp --game 0301 master-0900-01.txt
+  tnrpt pipeline ingest-master --db data/amp/tnrpt.db --data-dir data/amp --game 0301 --turn 0900-01 masters.zip`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var turnNo model.TurnNo
+			if turn != "" {
+				var err error
+				if turnNo, err = model.ParseTurnNo(turn); err != nil {
+					return err
+				}
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			svc := stages.NewIngestService(store, dataDir)
+			svc.SetFS(fsys)
+			createdBy := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+
+			var results []stages.MasterResult
+			var names []string // the master file of each result
+			ingest := func(name string, r io.ReadSeeker) error {
+				list, err := svc.IngestMasterReader(ctx, game, turnNo, createdBy, name, r)
+				for _, r := range list {
+					results, names = append(results, r), append(names, name)
+				}
+				if err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				return nil
+			}
+			for _, path := range args {
+				if !strings.EqualFold(filepath.Ext(path), ".zip") {
+					// read from the file, one clan at a time
+					f, err := fsys.Open(path)
+					if err != nil {
+						return fmt.Errorf("read %s: %w", path, err)
+					}
+					err = ingest(filepath.Base(path), f)
+					f.Close()
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				err := readZipText(fsys, path, func(name string, data []byte) error {
+					return ingest(name, bytes.NewReader(data))
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if outputFormat(cmd) == outputJSON {
+				type clanResult struct {
+					File      string `json:"file"`
+					Clan      string `json:"clan"`
+					Turn      string `json:"turn"`
+					FirstLine int    `json:"first-line"`
+					Batch     int64  `json:"batch"`
+					Duplicate bool   `json:"duplicate"`
+				}
+				list := []clanResult{}
+				for i, r := range results {
+					list = append(list, clanResult{names[i], r.ClanNo, r.TurnNo.String(), r.FirstLine, r.BatchID, r.Duplicate})
+				}
+				return printJSON(list)
+			}
+			for i, r := range results {
+				status := "queued"
+				if r.Duplicate {
+					status = "duplicate"
+				}
+				fmt.Printf("%s: clan %s  turn %s  line %5d  batch %4d  %s\n", names[i], r.ClanNo, r.TurnNo, r.FirstLine, r.BatchID, status)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().StringVar(&game, "game", "", "game ID (e.g., 0301)")
+	cmd.Flags().StringVar(&turn, "turn", "", "turn (default: each clan's Current Turn line)")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+	cmd.MarkFlagRequired("game")
+
+	return cmd
+}
+
+// readZipText calls fn with the name and contents of each .txt file in a
+// ZIP file, skipping macOS resource forks and hidden files.
+func readZipText(fsys afero.Fs, path string, fn func(name string, data []byte) error) error {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	for _, f := range zr.File {
+		base := filepath.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(base, ".") || !strings.EqualFold(filepath.Ext(base), ".txt") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", path, f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", path, f.Name, err)
+		}
+		if err := fn(base, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cmdPipelineStatus() *cobra.Command {
+	var dbPath string
+	var batchID int64
+	var showFailed bool
+	var showMetrics bool
+	var showSlowest int
+	var window time.Duration
+	var stage string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show pipeline status",
+		Long: `Show pipeline work queue status.
+
+With --batch-id: shows summary for a specific batch
+With --failed: lists all failed jobs
+With --failed --stage: lists failed jobs for a specific stage
+With --metrics: shows throughput, latency, failures, and backlog per stage
+With --slowest N: lists the N reports that took longest to parse, per step
+With no flags: shows which stages are paused
+
+Metrics cover jobs finished within --window (default 24h) and are
+computed from each job's started_at and finished_at times.
+
+Examples:
+  tnrpt pipeline status --db data/amp/tnrpt.db
+  tnrpt pipeline status --db data/amp/tnrpt.db --batch-id 1
+  tnrpt pipeline status --db data/amp/tnrpt.db --failed
+  tnrpt pipeline status --db data/amp/tnrpt.db --failed --stage extract
+  tnrpt pipeline status --db data/amp/tnrpt.db --metrics --window 1h
+  tnrpt pipeline status --db data/amp/tnrpt.db --slowest 10
+  tnrpt pipeline status --db data/amp/tnrpt.db --failed --output json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			output := outputFormat(cmd)
+			if showMetrics {
+				return showWorkMetrics(ctx, store, stage, window, output)
+			}
+
+			if showSlowest > 0 {
+				return showSlowestReports(ctx, store, showSlowest, output)
+			}
+
+			if showFailed {
+				return showFailedJobs(ctx, store, stage, output)
+			}
+
+			if batchID > 0 {
+				return showBatchStatus(ctx, store, batchID, output)
+			}
+
+			return showPauses(ctx, store, output)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().Int64Var(&batchID, "batch-id", 0, "show summary for specific batch")
+	cmd.Flags().BoolVar(&showFailed, "failed", false, "list failed jobs")
+	cmd.Flags().BoolVar(&showMetrics, "metrics", false, "show throughput and latency per stage")
+	cmd.Flags().IntVar(&showSlowest, "slowest", 0, "list the N slowest reports with per-step parse timings")
+	cmd.Flags().DurationVar(&window, "window", 24*time.Hour, "time window for --metrics")
+	cmd.Flags().StringVar(&stage, "stage", "", "filter by stage (extract, parse, render)")
+	cmd.MarkFlagRequired("db")
+	cmd.RegisterFlagCompletionFunc("stage", cobra.FixedCompletions(pipelineStages, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func showBatchStatus(ctx context.Context, store *sqlite.SQLiteStore, batchID int64, output string) error {
+	batch, err := store.GetUploadBatch(ctx, batchID)
+	if err != nil {
+		return fmt.Errorf("get batch: %w", err)
+	}
+	if batch == nil {
+		return fmt.Errorf("batch %d not found", batchID)
+	}
+
+	summary, err := store.GetWorkSummaryByBatch(ctx, batchID)
+	if err != nil {
+		return fmt.Errorf("get work summary: %w", err)
+	}
+
+	if output == outputJSON {
+		type stageCounts struct {
+			Ok      int `json:"ok"`
+			Running int `json:"running"`
+			Queued  int `json:"queued"`
+			Failed  int `json:"failed"`
+		}
+		result := struct {
+			Batch     int64                  `json:"batch"`
+			Game      string                 `json:"game"`
+			Clan      string                 `json:"clan"`
+			Turn      string                 `json:"turn"`
+			CreatedAt time.Time              `json:"created-at"`
+			Work      map[string]stageCounts `json:"work"`
+		}{batch.ID, batch.Game, batch.ClanNo, batch.TurnNo.String(), batch.CreatedAt, map[string]stageCounts{}}
+		for _, stage := range []string{"extract", "parse"} {
+			statuses := summary[stage]
+			result.Work[stage] = stageCounts{statuses["ok"], statuses["running"], statuses["queued"], statuses["failed"]}
+		}
+		return printJSON(result)
+	}
+
+	fmt.Printf("Batch %d (game=%s, clan=%s, turn=%d)\n", batch.ID, batch.Game, batch.ClanNo, batch.TurnNo)
+	fmt.Printf("Created: %s\n", batch.CreatedAt.Format(time.RFC3339))
+	fmt.Println()
+
+	fmt.Println("Work Summary:")
+	for _, stage := range []string{"extract", "parse"} {
+		statuses := summary[stage]
+		if statuses == nil {
+			statuses = make(map[string]int)
+		}
+		fmt.Printf("  %s: %d ok, %d running, %d queued, %d failed\n",
+			stage,
+			statuses["ok"],
+			statuses["running"],
+			statuses["queued"],
+			statuses["failed"])
+	}
+
+	return nil
+}
+
+func showWorkMetrics(ctx context.Context, store *sqlite.SQLiteStore, stage string, window time.Duration, output string) error {
+	if window <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+	metrics, err := store.GetWorkMetrics(ctx, time.Now().UTC().Add(-window))
+	if err != nil {
+		return fmt.Errorf("get work metrics: %w", err)
+	}
+	pauses, err := store.WorkPauses(ctx)
+	if err != nil {
+		return fmt.Errorf("get work pauses: %w", err)
+	}
+
+	if output == outputJSON {
+		type stageMetrics struct {
+			Stage          string         `json:"stage"`
+			Finished       int            `json:"finished"`
+			Failed         int            `json:"failed"`
+			JobsPerHour    float64        `json:"jobs-per-hour"`
+			AvgMS          int64          `json:"avg-ms"`
+			P95MS          int64          `json:"p95-ms"`
+			FailureRate    float64        `json:"failure-rate"`
+			FailuresByCode map[string]int `json:"failures-by-code,omitempty"`
+			Queued         int            `json:"queued"`
+			BacklogAgeS    int64          `json:"backlog-age-s"`
+			Paused         bool           `json:"paused"`
+		}
+		result := []stageMetrics{}
+		for _, m := range metrics {
+			if stage != "" && m.Stage != stage {
+				continue
+			}
+			result = append(result, stageMetrics{
+				Stage:          m.Stage,
+				Finished:       m.Finished,
+				Failed:         m.Failed,
+				JobsPerHour:    m.JobsPerHour,
+				AvgMS:          m.AvgDuration.Milliseconds(),
+				P95MS:          m.P95Duration.Milliseconds(),
+				FailureRate:    m.FailureRate(),
+				FailuresByCode: m.FailuresByCode,
+				Queued:         m.Queued,
+				BacklogAgeS:    int64(m.BacklogAge.Seconds()),
+				Paused:         stagePaused(pauses, m.Stage),
+			})
+		}
+		return printJSON(result)
+	}
+
+	fmt.Printf("Work Metrics (last %s):\n", window)
+	for _, m := range metrics {
+		if stage != "" && m.Stage != stage {
+			continue
+		}
+		if stagePaused(pauses, m.Stage) {
+			fmt.Printf("  %s: (paused)\n", m.Stage)
+		} else {
+			fmt.Printf("  %s:\n", m.Stage)
+		}
+		fmt.Printf("    throughput: %d finished, %.1f jobs/hour\n", m.Finished, m.JobsPerHour)
+		fmt.Printf("    duration:   avg %s, p95 %s\n", m.AvgDuration, m.P95Duration)
+		fmt.Printf("    failures:   %d (%.1f%%)\n", m.Failed, 100*m.FailureRate())
+		codes := make([]string, 0, len(m.FailuresByCode))
+		for code := range m.FailuresByCode {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Printf("      %-24s %d\n", code, m.FailuresByCode[code])
+		}
+		fmt.Printf("    backlog:    %d queued, oldest %s\n", m.Queued, m.BacklogAge.Truncate(time.Second))
+	}
+
+	return nil
+}
+
+// showPauses lists the paused stages.
+func showPauses(ctx context.Context, store *sqlite.SQLiteStore, output string) error {
+	pauses, err := store.WorkPauses(ctx)
+	if err != nil {
+		return fmt.Errorf("get work pauses: %w", err)
+	}
+
+	if output == outputJSON {
+		type stagePause struct {
+			Stage    string    `json:"stage"`
+			Paused   bool      `json:"paused"`
+			Reason   string    `json:"reason,omitempty"`
+			PausedBy string    `json:"paused-by,omitempty"`
+			PausedAt time.Time `json:"paused-at,omitzero"`
+		}
+		result := []stagePause{}
+		for _, stage := range pipelineStages {
+			sp := stagePause{Stage: stage}
+			if p, ok := findPause(pauses, stage); ok {
+				sp = stagePause{stage, true, p.Reason, p.PausedBy, p.PausedAt}
+			}
+			result = append(result, sp)
+		}
+		return printJSON(result)
+	}
+
+	fmt.Println("Work Queue:")
+	for _, stage := range pipelineStages {
+		p, ok := findPause(pauses, stage)
+		if !ok {
+			fmt.Printf("  %-8s running\n", stage)
+			continue
+		}
+		who := p.PausedBy
+		if p.Stage == "" {
+			who += ", all stages"
+		}
+		fmt.Printf("  %-8s paused since %s (%s)", stage, p.PausedAt.Format(time.RFC3339), who)
+		if p.Reason != "" {
+			fmt.Printf(": %s", p.Reason)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// findPause returns the pause that stops stage, preferring a pause of every
+// stage over one of just this stage.
+func findPause(pauses []model.WorkPause, stage string) (model.WorkPause, bool) {
+	for _, p := range pauses {
+		if p.Stage == "" || p.Stage == stage {
+			return p, true
+		}
+	}
+	return model.WorkPause{}, false
+}
+
+// stagePaused reports whether workers are kept from claiming stage's jobs.
+func stagePaused(pauses []model.WorkPause, stage string) bool {
+	_, ok := findPause(pauses, stage)
+	return ok
+}
+
+func showSlowestReports(ctx context.Context, store *sqlite.SQLiteStore, limit int, output string) error {
+	timings, err := store.SlowestReports(ctx, limit)
+	if err != nil {
+		return fmt.Errorf("get report timings: %w", err)
+	}
+
+	if output == outputJSON {
+		type reportTiming struct {
+			ReportXID int64  `json:"report-x-id"`
+			Game      string `json:"game"`
+			Clan      string `json:"clan"`
+			Turn      string `json:"turn"`
+			Name      string `json:"name"`
+			TotalMS   int64  `json:"total-ms"`
+			ExtractMS int64  `json:"extract-ms"`
+			SplitMS   int64  `json:"split-ms"`
+			ParseMS   int64  `json:"parse-ms"`
+			AdaptMS   int64  `json:"adapt-ms"`
+			StoreMS   int64  `json:"store-ms"`
+		}
+		result := []reportTiming{}
+		for _, rt := range timings {
+			t := rt.Timings
+			result = append(result, reportTiming{
+				rt.ReportXID, rt.Game, rt.ClanNo, rt.TurnNo.String(), rt.Name,
+				t.Total().Milliseconds(), t.Extract.Milliseconds(), t.Split.Milliseconds(),
+				t.Parse.Milliseconds(), t.Adapt.Milliseconds(), t.Store.Milliseconds(),
+			})
+		}
+		return printJSON(result)
+	}
+
+	fmt.Println("Slowest Reports (ms):")
+	if len(timings) == 0 {
+		fmt.Println("  (none)")
+		return nil
+	}
+	fmt.Printf("  %-8s %-4s %-4s %-7s %7s %7s %7s %7s %7s %7s  %s\n", "id", "game", "clan", "turn", "total", "extract", "split", "parse", "adapt", "store", "file")
+	for _, rt := range timings {
+		t := rt.Timings
+		fmt.Printf("  %-8d %-4s %-4s %-7s %7d %7d %7d %7d %7d %7d  %s\n",
+			rt.ReportXID, rt.Game, rt.ClanNo, rt.TurnNo,
+			t.Total().Milliseconds(), t.Extract.Milliseconds(), t.Split.Milliseconds(),
+			t.Parse.Milliseconds(), t.Adapt.Milliseconds(), t.Store.Milliseconds(), rt.Name)
+	}
+	return nil
+}
+
+func showFailedJobs(ctx context.Context, store *sqlite.SQLiteStore, stage string, output string) error {
+	stages := []string{"extract", "parse"}
+	if stage != "" {
+		stages = []string{stage}
+	}
+
+	if output == outputJSON {
+		type failedJob struct {
+			ID           int64  `json:"id"`
+			Stage        string `json:"stage"`
+			ReportFileID int64  `json:"report-file-id"`
+			ErrorCode    string `json:"error-code,omitempty"`
+			ErrorMessage string `json:"error-message,omitempty"`
+		}
+		result := []failedJob{}
+		for _, s := range stages {
+			jobs, err := store.GetFailedWork(ctx, s)
+			if err != nil {
+				return fmt.Errorf("get failed work: %w", err)
+			}
+			for _, j := range jobs {
+				fj := failedJob{ID: j.ID, Stage: j.Stage, ReportFileID: j.ReportFileID}
+				if j.ErrorCode != nil {
+					fj.ErrorCode = *j.ErrorCode
+				}
+				if j.ErrorMessage != nil {
+					fj.ErrorMessage = *j.ErrorMessage
+				}
+				result = append(result, fj)
+			}
+		}
+		return printJSON(result)
+	}
+
+	fmt.Println("Failed Jobs:")
+	total := 0
+	for _, s := range stages {
+		jobs, err := store.GetFailedWork(ctx, s)
+		if err != nil {
+			return fmt.Errorf("get failed work: %w", err)
+		}
+		for _, j := range jobs {
+			errCode := ""
+			if j.ErrorCode != nil {
+				errCode = *j.ErrorCode
+			}
+			fmt.Printf("  ID=%d  stage=%s  file_id=%d  error=%s\n", j.ID, j.Stage, j.ReportFileID, errCode)
+			total++
+		}
+	}
+
+	if total == 0 {
+		fmt.Println("  (none)")
+	} else {
+		fmt.Println()
+		fmt.Println("To retry: tnrpt pipeline work <stage> --retry-failed")
+	}
+
+	return nil
+}
+
+func cmdPipelinePause() *cobra.Command {
+	var dbPath string
+	var reason string
+
+	cmd := &cobra.Command{
+		Use:   "pause [stage]",
+		Short: "Stop workers from claiming jobs",
+		Long: `Stop workers from claiming jobs for a stage, or for every stage if no
+stage is given, e.g. while migrating the database or repairing data.
+
+Jobs already running are left to finish. Jobs can still be queued; they
+wait until the stage is resumed with "tnrpt pipeline resume".
+
+Examples:
+  tnrpt pipeline pause --db data/amp/tnrpt.db --reason "schema migration"
+  tnrpt pipeline pause --db data/amp/tnrpt.db parse`,
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+		ValidArgs:    pipelineStages,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stage, err := pauseStage(args)
+			if err != nil {
+				return err
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			if err := store.PauseWork(cmd.Context(), actor, stage, reason); err != nil {
+				return fmt.Errorf("pause: %w", err)
+			}
+			if stage == "" {
+				fmt.Println("Paused all stages")
+			} else {
+				fmt.Printf("Paused %s\n", stage)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&reason, "reason", "", "why the queue is paused, shown by pipeline status")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+func cmdPipelineResume() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "resume [stage]",
+		Short: "Let workers claim jobs again",
+		Long: `Lift a pause set by "tnrpt pipeline pause". With no stage, lifts the
+pause on every stage; stages paused on their own stay paused.
+
+Examples:
+  tnrpt pipeline resume --db data/amp/tnrpt.db
+  tnrpt pipeline resume --db data/amp/tnrpt.db parse`,
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+		ValidArgs:    pipelineStages,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stage, err := pauseStage(args)
+			if err != nil {
+				return err
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			actor := fmt.Sprintf("cli:%s", os.Getenv("USER"))
+			resumed, err := store.ResumeWork(cmd.Context(), actor, stage)
+			if err != nil {
+				return fmt.Errorf("resume: %w", err)
+			}
+			name := stage
+			if stage == "" {
+				name = "all stages"
+			}
+			if !resumed {
+				fmt.Printf("%s: not paused\n", name)
+				return nil
+			}
+			fmt.Printf("Resumed %s\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+// pauseStage returns the stage named in args, or "" for every stage.
+func pauseStage(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	if !slices.Contains(pipelineStages, args[0]) {
+		return "", fmt.Errorf("invalid stage %q: must be extract, parse, or render", args[0])
+	}
+	return args[0], nil
+}
+
+func cmdPipelineWork() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var pollInterval time.Duration
+	var retryFailed bool
+	var renderAuto bool
+
+	cmd := &cobra.Command{
+		Use:   "work <stage>",
+		Short: "Process pipeline work queue",
+		Long: `Process jobs in the pipeline work queue.
+
+Stages:
+  extract  - Extract text from DOCX files
+  parse    - Parse extracted text into model tables
+  render   - Draw the clan's known map for the turn as an SVG image
+  all      - Process extract, parse, then render sequentially
+
+The worker claims jobs atomically and processes them one at a time.
+Use --poll-interval to run continuously, polling for new work.
+
+With --render-auto, each report that parses queues a render job. The map
+is written next to the report as a .map.svg file and linked from the
+clan's turn page.
+
+Examples:
+  tnrpt pipeline work --db data/amp/tnrpt.db --data-dir data/amp extract
+  tnrpt pipeline work --db data/amp/tnrpt.db --data-dir data/amp parse --poll-interval 5s
+  tnrpt pipeline work --db data/amp/tnrpt.db --data-dir data/amp all
+  tnrpt pipeline work --db data/amp/tnrpt.db --data-dir data/amp all --render-auto
+  tnrpt pipeline work --db data/amp/tnrpt.db --data-dir data/amp extract --retry-failed
+  tnrpt pipeline work --db data/amp/tnrpt.db --data-dir data/amp all --retry-failed --dry-run`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		ValidArgs:    append(slices.Clone(pipelineStages), "all"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			stage := args[0]
+
+			if stage != "all" && !slices.Contains(pipelineStages, stage) {
+				return fmt.Errorf("invalid stage %q: must be extract, parse, render, or all", stage)
+			}
+			if isDryRun(cmd) && !retryFailed {
+				return fmt.Errorf("--dry-run: only works with --retry-failed")
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			worker := stages.NewWorkerService(store, dataDir, "")
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			worker.SetFS(fsys)
+			worker.SetRenderAuto(renderAuto)
+
+			if retryFailed {
+				return retryFailedJobs(ctx, store, stage, isDryRun(cmd))
+			}
+
+			if stage == "all" {
+				return runAllStages(ctx, worker, pollInterval)
+			}
+
+			return runWorker(ctx, worker, stage, pollInterval)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", 0, "poll interval for continuous processing (0 = process once)")
+	cmd.Flags().BoolVar(&retryFailed, "retry-failed", false, "reset failed jobs to queued and exit")
+	cmd.Flags().BoolVar(&renderAuto, "render-auto", false, "queue a map render for each report that parses")
+	addDryRunFlag(cmd)
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+
+	return cmd
+}
+
+func cmdPipelineWatch() *cobra.Command {
+	var dbPath string
+	var dataDir string
+	var pollInterval time.Duration
+	var renderAuto bool
+
+	cmd := &cobra.Command{
+		Use:   "watch <dir>",
+		Short: "Watch a drop directory and process new report files",
+		Long: `Watch a drop directory for new turn report files, ingest them, and run
+the extract and parse stages continuously. With --render-auto, the render
+stage runs too, drawing a fresh map for each clan and turn that parses.
+
+Game, turn, and clan are inferred from the file name, which must be
+GGGG.YYYY-MM.CCCC.docx or GGGG.YYYY-MM.CCCC.report.txt. Files that do not
+match are skipped. Each new file is ingested in its own upload batch once
+its size and modification time are unchanged between two polls, so a file
+still being copied in isn't ingested half-written. Files that are already
+in the database (same SHA-256) are skipped.
+
+The directory is polled; press Ctrl-C to stop.
+
+Examples:
+  tnrpt pipeline watch --db data/amp/tnrpt.db --data-dir data/amp data/inbox
+  tnrpt pipeline watch --db data/amp/tnrpt.db --data-dir data/amp --poll-interval 30s data/inbox
+  tnrpt pipeline watch --db data/amp/tnrpt.db --data-dir data/amp --render-auto data/inbox`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dropDir := args[0]
+			if pollInterval <= 0 {
+				return fmt.Errorf("--poll-interval must be greater than zero")
+			}
+			fsys, err := storageFS(cmd)
+			if err != nil {
+				return err
+			}
+			if sb, err := fsys.Stat(dropDir); err != nil {
+				return fmt.Errorf("watch: %w", err)
+			} else if !sb.IsDir() {
+				return fmt.Errorf("watch: %s: not a directory", dropDir)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			createdBy := fmt.Sprintf("watch:%s", os.Getenv("USER"))
+			ingest := stages.NewIngestService(store, dataDir)
+			ingest.SetFS(fsys)
+			watcher := stages.NewWatchService(ingest, dropDir, createdBy)
+			watcher.SetFS(fsys)
+			worker := stages.NewWorkerService(store, dataDir, "")
+			worker.SetFS(fsys)
+			worker.SetRenderAuto(renderAuto)
+
+			logging.FromContext(ctx).Info("pipeline: watch: watching", "dir", dropDir, "every", pollInterval)
+			for {
+				results, err := watcher.Scan(ctx)
+				if err != nil {
+					logging.FromContext(ctx).Error("pipeline: watch: scan", "err", err)
+				}
+				for _, r := range results {
+					if r.Duplicate {
+						logging.FromContext(ctx).Info("pipeline: watch: duplicate", logging.KeyFile, r.Filename, logging.KeyReportFileID, r.ReportFileID)
+					} else {
+						logging.FromContext(ctx).Info("pipeline: watch: queued", logging.KeyFile, r.Filename, logging.KeyBatch, r.BatchID, logging.KeyReportFileID, r.ReportFileID)
+					}
+				}
+
+				for _, stage := range pipelineStages {
+					if err := runWorker(ctx, worker, stage, 0); err != nil {
+						logging.FromContext(ctx).Error("pipeline: watch", logging.KeyStage, stage, "err", err)
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					logging.FromContext(ctx).Info("pipeline: watch: stopped")
+					return nil
+				case <-time.After(pollInterval):
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "data directory for file storage (required)")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", 5*time.Second, "how often to check the drop directory")
+	cmd.Flags().BoolVar(&renderAuto, "render-auto", false, "queue a map render for each report that parses")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("data-dir")
+
+	return cmd
+}
+
+// pipelineStages are the work queue stages in the order a report goes through them.
+var pipelineStages = []string{model.WorkStageExtract, model.WorkStageParse, model.WorkStageRender}
+
+func runWorker(ctx context.Context, worker *stages.WorkerService, stage string, pollInterval time.Duration) error {
+	processed := 0
+	failed := 0
+
+	for {
+		jobProcessed, err := worker.ProcessJob(ctx, stage)
+		if err != nil {
+			logging.FromContext(ctx).Error("pipeline: work", logging.KeyStage, stage, "err", err)
+			failed++
+		}
+		if jobProcessed {
+			if err == nil {
+				processed++
+				logging.FromContext(ctx).Info("pipeline: work: processed job", logging.KeyStage, stage, "total", processed)
+			}
+		} else {
+			if pollInterval == 0 {
+				logging.FromContext(ctx).Info("pipeline: work: no more jobs", logging.KeyStage, stage, "processed", processed, "failed", failed)
+				return nil
+			}
+			time.Sleep(pollInterval)
+		}
+	}
+}
+
+func runAllStages(ctx context.Context, worker *stages.WorkerService, pollInterval time.Duration) error {
+	for _, stage := range pipelineStages {
+		logging.FromContext(ctx).Info("pipeline: work: processing stage", logging.KeyStage, stage)
+		if err := runWorker(ctx, worker, stage, 0); err != nil {
+			return fmt.Errorf("%s: %w", stage, err)
+		}
+	}
+
+	if pollInterval > 0 {
+		logging.FromContext(ctx).Info("pipeline: work: all stages complete, starting poll loop")
+		for {
+			for _, stage := range pipelineStages {
+				_, err := worker.ProcessJob(ctx, stage)
+				if err != nil {
+					logging.FromContext(ctx).Error("pipeline: work", logging.KeyStage, stage, "err", err)
+				}
+			}
+			time.Sleep(pollInterval)
+		}
+	}
+
+	return nil
+}
+
+func retryFailedJobs(ctx context.Context, store *sqlite.SQLiteStore, stage string, dryRun bool) error {
+	stages := pipelineStages
+	if stage != "all" {
+		stages = []string{stage}
+	}
+
+	if dryRun {
+		total := 0
+		for _, s := range stages {
+			jobs, err := store.GetFailedWork(ctx, s)
+			if err != nil {
+				return fmt.Errorf("get failed %s jobs: %w", s, err)
+			}
+			for _, w := range jobs {
+				logging.FromContext(ctx).Info("pipeline: work: would reset failed job", logging.KeyStage, s, "work", w.ID, logging.KeyReportFileID, w.ReportFileID)
+			}
+			total += len(jobs)
+		}
+		logging.FromContext(ctx).Info("pipeline: work: dry run, no jobs reset", "count", total)
+		return nil
+	}
+
+	total := 0
+	for _, s := range stages {
+		count, err := store.ResetFailedWork(ctx, s)
+		if err != nil {
+			return fmt.Errorf("reset failed %s jobs: %w", s, err)
+		}
+		if count > 0 {
+			logging.FromContext(ctx).Info("pipeline: work: reset failed jobs", logging.KeyStage, s, "count", count)
+			total += count
+		}
+	}
+
+	if total == 0 {
+		logging.FromContext(ctx).Info("pipeline: work: no failed jobs to reset")
+	}
+	return nil
+}
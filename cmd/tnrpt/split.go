@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/norm"
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/pipelines/parsers/report"
+	"github.com/spf13/cobra"
+)
+
+func cmdSplit() *cobra.Command {
+	var outDir string
+	var email, force bool
+
+	cmd := &cobra.Command{
+		Use:   "split <turn-report>",
+		Short: "Split a turn report into one file per unit",
+		Long: `Split a turn report (.docx or text) into unit sections and write each
+unit's sections to a file of its own in --out, named like report files
+(for example, 0900-01.0987e1.report.txt). Sub-commanders can be sent just
+the units they run, and each file can be parsed or uploaded on its own.
+
+The files hold the lines the splitter keeps for mapping: the unit header,
+turn, movement, scout, and status lines. Existing files are not replaced
+unless --force is given.
+
+With --email, a text file is a report that was sent as the body of an email.
+
+Examples:
+  tnrpt split 0301.0899-12.0987.docx --out split/
+  tnrpt split 0899-12.0987.report.txt --out split/ --force`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			input := args[0]
+			data, err := os.ReadFile(input)
+			if err != nil {
+				return fmt.Errorf("read report: %w", err)
+			}
+
+			var doc *docx.Docx
+			if strings.EqualFold(filepath.Ext(input), ".docx") {
+				if doc, err = docx.ParseReader(bytes.NewReader(data), true, true, true, false, false); err != nil {
+					return fmt.Errorf("parse docx: %w", err)
+				}
+				doc.Source = input
+			} else {
+				text, enc := norm.ToUTF8(data)
+				if enc != norm.EncodingUTF8 {
+					logging.FromContext(ctx).Info("split: converted to UTF-8", logging.KeyFile, input, "encoding", enc)
+				}
+				if email {
+					if text, err = report.FromEmail(text, report.EmailWidth); err != nil {
+						return err
+					}
+				}
+				doc = &docx.Docx{Source: input, Text: text}
+			}
+
+			rpt, err := report.ParseReportText(doc, true, true, true, false, false)
+			if err != nil {
+				return fmt.Errorf("split report: %w", err)
+			}
+			for _, warning := range rpt.Warnings {
+				logging.FromContext(ctx).Warn("split", logging.KeyFile, input, "warning", warning)
+			}
+
+			files := rpt.UnitFiles()
+			if !force {
+				for _, f := range files {
+					path := filepath.Join(outDir, f.Name)
+					if _, err := os.Stat(path); err == nil {
+						return fmt.Errorf("%s: already exists (use --force to replace it)", path)
+					} else if !errors.Is(err, os.ErrNotExist) {
+						return err
+					}
+				}
+			}
+			if err := os.MkdirAll(outDir, 0755); err != nil {
+				return fmt.Errorf("create output directory: %w", err)
+			}
+
+			type written struct {
+				Unit  string `json:"unit"`
+				Kind  string `json:"kind"`
+				Path  string `json:"path"`
+				Lines int    `json:"lines"`
+			}
+			var result []written
+			for _, f := range files {
+				path := filepath.Join(outDir, f.Name)
+				if err := os.WriteFile(path, f.Text, 0644); err != nil {
+					return fmt.Errorf("write unit file: %w", err)
+				}
+				result = append(result, written{Unit: f.UnitId, Kind: f.Kind, Path: path, Lines: bytes.Count(f.Text, []byte{'\n'})})
+			}
+
+			if outputFormat(cmd) == outputJSON {
+				return printJSON(result)
+			}
+			for _, w := range result {
+				fmt.Printf("split: %-8s %-8s %4d lines  %s\n", w.Unit, w.Kind, w.Lines, w.Path)
+			}
+			fmt.Printf("split: wrote %d unit files to %s\n", len(result), outDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&outDir, "out", "", "directory to write the unit files to (required)")
+	cmd.Flags().BoolVar(&email, "email", false, "the text file is an email body: decode quoted-printable, strip quote prefixes, and unwrap lines")
+	cmd.Flags().BoolVar(&force, "force", false, "replace unit files that already exist")
+	cmd.MarkFlagRequired("out")
+
+	return cmd
+}
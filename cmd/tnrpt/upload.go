@@ -0,0 +1,224 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/norm"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/pipelines/parsers/report"
+	sqlite "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/cobra"
+)
+
+func cmdUpload() *cobra.Command {
+	var dbPath string
+	var file string
+	var game string
+	var turn string
+	var clan string
+	var email bool
+	addFlags := func(cmd *cobra.Command) error {
+		cmd.Flags().StringVar(&dbPath, "db", "", "path to SQLite database (required)")
+		cmd.Flags().StringVar(&file, "file", "", "path to turn report file (.docx or .report.txt)")
+		cmd.Flags().StringVar(&game, "game", "", "game ID (4-digit, e.g., 0301)")
+		cmd.Flags().StringVar(&turn, "turn", "", "turn ID (YYYY-MM format, e.g., 0899-12)")
+		cmd.Flags().StringVar(&clan, "clan", "", "clan number (0001-0999, extracted from filename if not provided)")
+		cmd.Flags().BoolVar(&email, "email", false, "the text file is an email body: decode quoted-printable, strip quote prefixes, and unwrap lines")
+		cmd.MarkFlagRequired("db")
+		cmd.MarkFlagRequired("file")
+		cmd.MarkFlagRequired("game")
+		cmd.MarkFlagRequired("turn")
+		return nil
+	}
+	var cmd = &cobra.Command{
+		Use:   "upload",
+		Short: "Upload a turn report to the database",
+		Long: `Upload a turn report file (.docx or .report.txt) to the database.
+Uses the same parsing pipeline as the web upload handler.
+
+With --email, a text file is a report that was sent as the body of an email.
+Quoted-printable is decoded, "> " quote prefixes are stripped, and lines the
+mail program wrapped are joined before the report is split into sections.
+
+File naming patterns:
+  CCCC.docx                      - clan only (0001-0999)
+  GGGG.YYYY-MM.CCCC.report.txt   - game, turn, clan
+
+Examples:
+  tnrpt upload --db data/tnrpt.db --file 0987.docx --game 0301 --turn 0899-12
+  tnrpt upload --db data/tnrpt.db --file 0301.0899-12.0987.report.txt --game 0301 --turn 0899-12
+  tnrpt upload --db data/tnrpt.db --file 0301.0899-12.0987.report.txt --game 0301 --turn 0899-12 --email`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			filename := filepath.Base(file)
+			fileClan, fileGame, fileTurn := parseUploadFilename(filename)
+
+			if clan == "" {
+				if fileClan == "" {
+					return fmt.Errorf("clan not provided and could not be extracted from filename")
+				}
+				clan = fileClan
+			}
+
+			if fileGame != "" && fileGame != game {
+				return fmt.Errorf("game in filename (%s) does not match --game (%s)", fileGame, game)
+			}
+			wantTurnNo, err := model.ParseTurnNo(turn)
+			if err != nil {
+				return err
+			}
+			if fileTurn != "" {
+				if fileTurnNo, err := model.ParseTurnNo(fileTurn); err != nil || fileTurnNo != wantTurnNo {
+					return fmt.Errorf("turn in filename (%s) does not match --turn (%s)", fileTurn, turn)
+				}
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("read file: %w", err)
+			}
+
+			store, err := sqlite.NewSQLiteStoreWithConfig(sqlite.StoreConfig{Path: dbPath})
+			if err != nil {
+				return fmt.Errorf("open database: %w", err)
+			}
+			defer store.Close()
+
+			// Word documents and email bodies go through the splitter;
+			// plain text is parsed as it is
+			var doc *docx.Docx
+			if strings.HasSuffix(strings.ToLower(filename), ".docx") {
+				doc, err = docx.ParseReader(bytes.NewReader(data), true, true, true, false, false)
+				if err != nil {
+					return fmt.Errorf("parse docx: %w", err)
+				}
+			} else if email {
+				body, err := report.FromEmail(data, report.EmailWidth)
+				if err != nil {
+					return err
+				}
+				doc = &docx.Docx{Source: filename, Text: body}
+			}
+
+			text := data
+			if doc == nil {
+				var enc norm.Encoding
+				if text, enc = norm.ToUTF8(data); enc != norm.EncodingUTF8 {
+					logging.FromContext(ctx).Info("upload: converted to UTF-8", logging.KeyFile, filename, "encoding", enc)
+				}
+			} else {
+				rpt, err := report.ParseReportText(doc, true, true, true, false, false)
+				if err != nil {
+					return fmt.Errorf("parse report: %w", err)
+				}
+				for _, warning := range rpt.Warnings {
+					logging.FromContext(ctx).Warn("upload", logging.KeyFile, filename, "warning", warning)
+				}
+
+				text = nil
+				for _, section := range rpt.Sections {
+					text = append(text, bytes.Join(section.Lines, []byte{'\n'})...)
+					text = append(text, '\n')
+				}
+			}
+
+			parsedTurn, err := bistre.ParseInput(filename, turn, text, bistre.ParseConfig{Logger: logging.FromContext(ctx)})
+			if err != nil {
+				return fmt.Errorf("parse turn report: %w", err)
+			}
+			if parsedTurn == nil {
+				return fmt.Errorf("parser returned no data")
+			}
+
+			turnNo := model.NewTurnNo(parsedTurn.Year, parsedTurn.Month)
+			now := time.Now().UTC()
+
+			hash := sha256.Sum256(data)
+			var mime string
+			if strings.HasSuffix(strings.ToLower(filename), ".docx") {
+				mime = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+			} else {
+				mime = "text/plain"
+			}
+
+			rf := &model.ReportFile{
+				Game:      game,
+				ClanNo:    clan,
+				TurnNo:    turnNo,
+				Name:      filename,
+				SHA256:    hex.EncodeToString(hash[:]),
+				Mime:      mime,
+				CreatedAt: now,
+			}
+			if err := store.AddReportFile(rf); err != nil {
+				return fmt.Errorf("store report file: %w", err)
+			}
+
+			ids, err := store.UnitIDRules(ctx, game)
+			if err != nil {
+				return fmt.Errorf("load unit id rules: %w", err)
+			}
+			for _, warning := range adapters.ResolveDuplicateUnits(parsedTurn) {
+				logging.FromContext(ctx).Warn("upload", logging.KeyFile, filename, "warning", warning)
+			}
+			rx, err := adapters.BistreTurnToModelReportX(filename, parsedTurn, game, clan, ids, nil)
+			if err != nil {
+				return fmt.Errorf("convert report: %w", err)
+			}
+			rx.ReportFileID = rf.ID
+
+			if err := store.AddReport(rx); err != nil {
+				return fmt.Errorf("store report: %w", err)
+			}
+
+			units := len(rx.Units)
+			acts := 0
+			steps := 0
+			for _, u := range rx.Units {
+				acts += len(u.Acts)
+				for _, a := range u.Acts {
+					steps += len(a.Steps)
+				}
+			}
+
+			logging.FromContext(ctx).Info("upload: stored", logging.KeyFile, filename, logging.KeyGame, game, logging.KeyTurn, turn, logging.KeyClan, clan,
+				"units", units, "acts", acts, "steps", steps)
+
+			return nil
+		},
+	}
+	if err := addFlags(cmd); err != nil {
+		log.Fatal(err)
+	}
+	return cmd
+}
+
+func parseUploadFilename(filename string) (clan, game, turn string) {
+	docxRe := regexp.MustCompile(`^(` + model.ClanPattern + `)\.docx$`)
+	if matches := docxRe.FindStringSubmatch(filename); matches != nil {
+		return matches[1], "", ""
+	}
+
+	txtRe := regexp.MustCompile(`^(\d{4})\.(\d{4}-\d{2})\.(` + model.ClanPattern + `)\.report\.txt$`)
+	if matches := txtRe.FindStringSubmatch(filename); matches != nil {
+		return matches[3], matches[1], matches[2]
+	}
+
+	return "", "", ""
+}
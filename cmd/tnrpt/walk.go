@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/parsers"
+	"github.com/mdhender/tnrpt/walkers/anhinga"
+	"github.com/spf13/cobra"
+)
+
+func cmdWalk() *cobra.Command {
+	var excludeUnits []string
+	var includeUnits []string
+	var outputFile string
+	addFlags := func(cmd *cobra.Command) error {
+		cmd.Flags().StringSliceVarP(&excludeUnits, "exclude", "e", excludeUnits, "exclude units matching pattern (ID or prefix*)")
+		cmd.Flags().StringSliceVarP(&includeUnits, "include", "i", includeUnits, "include only units matching pattern (ID or prefix*)")
+		cmd.Flags().StringVarP(&outputFile, "output", "o", outputFile, "save output to file")
+		return nil
+	}
+	var cmd = &cobra.Command{
+		Use:   "walk <turn-report.json> [<turn-report.json>...]",
+		Short: "Walk a parsed turn report, adding coordinates",
+		Long: `Walk the moves in one or more turn reports.
+
+Unit filters:
+  --include and --exclude take unit IDs or prefixes ending in "*" and may be
+  repeated or comma-separated. "0987*" matches tribe 0987 and all of its units.
+  Matching is case-insensitive, and --exclude wins over --include.
+
+Examples:
+  tnrpt walk 0899-12.0987.report.txt
+  tnrpt walk 0899-12.0987.report.txt 0900-01.0987.report.txt --include '0987*'`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1), // require path to turn report file
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			debug, _ := cmd.Flags().GetBool("debug")
+			if quiet {
+				verbose = false
+			}
+
+			filter := adapters.UnitFilter{Include: includeUnits, Exclude: excludeUnits}
+			for _, input := range args {
+				started, startedParser := time.Now(), time.Now()
+				turn, err := parsers.ParseTurnReport(input, true, false, quiet, verbose, debug)
+				if err != nil {
+					return err
+				}
+				logging.FromContext(ctx).Info("walk: parsed", logging.KeyFile, input, "elapsed", time.Since(startedParser))
+
+				startedStage := time.Now()
+				at, err := adapters.AzulParserTurnToModel(input, turn)
+				if err != nil {
+					return err
+				}
+				if n := adapters.FilterTurnUnits(at, filter); n > 0 && verbose {
+					logging.FromContext(ctx).Info("walk: filtered out units", logging.KeyFile, input, "units", n)
+				}
+				logging.FromContext(ctx).Info("walk: adapted", logging.KeyFile, input, "elapsed", time.Since(startedStage))
+
+				startedWalker := time.Now()
+				_, err = anhinga.Walk(ctx, at, nil, quiet, verbose, debug)
+				if err != nil {
+					return err
+				}
+				logging.FromContext(ctx).Info("walk: walked", logging.KeyFile, input, "elapsed", time.Since(startedWalker))
+
+				logging.FromContext(ctx).Info("walk: finished", logging.KeyFile, input, "elapsed", time.Since(started))
+			}
+
+			return nil
+		},
+	}
+	if err := addFlags(cmd); err != nil {
+		log.Fatal(err)
+	}
+	return cmd
+}
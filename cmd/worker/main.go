@@ -0,0 +1,236 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Command worker runs the pipeline work queue without the web server, so
+// reports can be processed on hosts of their own. Any number of workers can
+// share a database; each job is claimed by one of them.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+func main() {
+	concurrency := flag.Int("concurrency", 1, "number of jobs to work on at once")
+	dataDir := flag.String("data-dir", "", "pipeline data directory (required)")
+	dbPath := flag.String("db", "", "SQLite database file path (required)")
+	drainTimeout := flag.Duration("drain-timeout", time.Minute, "on SIGTERM, how long to let running jobs finish before cancelling them")
+	fsRoot := flag.String("fs-root", "", "read and write --data-dir under this directory or s3://bucket/prefix (empty = the path is used as given)")
+	logFormat := flag.String("log-format", logging.FormatText, "log record format (text, json)")
+	logLevel := flag.String("log-level", "info", "minimum level to log (debug, info, warn, error)")
+	metricsAddr := flag.String("metrics-addr", "", "serve /metrics and /healthz on this address, e.g. :9187 (empty = off)")
+	pollInterval := flag.Duration("poll-interval", 5*time.Second, "how long to wait for new work once the queues are empty")
+	renderAuto := flag.Bool("render-auto", false, "draw a map image for each clan and turn as reports are parsed")
+	showVersion := flag.Bool("version", false, "show version and exit")
+	stageList := flag.String("stages", strings.Join(pipelineStages, ","), "comma-separated stages to work on")
+	workerID := flag.String("worker-id", "", "name recorded on the jobs this worker claims (empty = hostname:pid)")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(tnrpt.Version().Core())
+		os.Exit(0)
+	}
+
+	logger, err := logging.New(os.Stderr, *logFormat, *logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	var workStages []string
+	for _, stage := range strings.Split(*stageList, ",") {
+		stage = strings.TrimSpace(stage)
+		if !slices.Contains(pipelineStages, stage) {
+			slog.Error("--stages must be extract, parse, or render", logging.KeyStage, stage)
+			os.Exit(1)
+		}
+		if !slices.Contains(workStages, stage) {
+			workStages = append(workStages, stage)
+		}
+	}
+	if *dbPath == "" || *dataDir == "" {
+		slog.Error("--db and --data-dir are required")
+		os.Exit(1)
+	}
+	if *concurrency < 1 {
+		slog.Error("--concurrency must be at least 1")
+		os.Exit(1)
+	}
+	if *pollInterval <= 0 {
+		slog.Error("--poll-interval must be greater than zero")
+		os.Exit(1)
+	}
+	if *workerID == "" {
+		hostname, _ := os.Hostname()
+		*workerID = fmt.Sprintf("%s:%d", hostname, os.Getpid())
+	}
+
+	err = run(config{
+		DBPath:       *dbPath,
+		DataDir:      *dataDir,
+		FSRoot:       *fsRoot,
+		Stages:       workStages,
+		Concurrency:  *concurrency,
+		PollInterval: *pollInterval,
+		DrainTimeout: *drainTimeout,
+		MetricsAddr:  *metricsAddr,
+		RenderAuto:   *renderAuto,
+		WorkerID:     *workerID,
+	})
+	if err != nil {
+		slog.Error("worker failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+// pipelineStages are the work queue stages in the order a report goes through them.
+var pipelineStages = []string{model.WorkStageExtract, model.WorkStageParse, model.WorkStageRender}
+
+// config holds the worker settings from the command line.
+type config struct {
+	DBPath       string
+	DataDir      string
+	FSRoot       string   // root of DataDir; see stages.NewFS
+	Stages       []string // worked on in this order
+	Concurrency  int
+	PollInterval time.Duration
+	DrainTimeout time.Duration // 0 cancels running jobs at once
+	MetricsAddr  string        // empty to not serve metrics
+	RenderAuto   bool
+	WorkerID     string
+}
+
+// run works the queues until SIGINT or SIGTERM. It then stops claiming jobs,
+// lets the running ones finish for up to cfg.DrainTimeout, and cancels the
+// rest. Cancelled jobs are put back in the queue for another worker.
+func run(cfg config) error {
+	sqliteStore, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: cfg.DBPath})
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer sqliteStore.Close()
+
+	fsys, err := stages.NewFS(cfg.FSRoot)
+	if err != nil {
+		return fmt.Errorf("fs-root: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// jobs run on their own context so a signal doesn't cut them off mid-way
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	m := newMetrics(cfg.Stages)
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		metricsServer = &http.Server{Addr: cfg.MetricsAddr, Handler: m.handler(), ReadTimeout: 15 * time.Second}
+		go func() {
+			slog.Info("worker: serving metrics", "addr", cfg.MetricsAddr)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("worker: metrics", "err", err)
+			}
+		}()
+	}
+
+	slog.Info("worker: started", "id", cfg.WorkerID, "stages", strings.Join(cfg.Stages, ","), "concurrency", cfg.Concurrency)
+	var wg sync.WaitGroup
+	var ids []string
+	for i := 1; i <= cfg.Concurrency; i++ {
+		id := cfg.WorkerID
+		if cfg.Concurrency > 1 {
+			id = fmt.Sprintf("%s#%d", cfg.WorkerID, i)
+		}
+		ids = append(ids, id)
+		worker := stages.NewWorkerService(sqliteStore, cfg.DataDir, id)
+		worker.SetFS(fsys)
+		worker.SetRenderAuto(cfg.RenderAuto)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workQueues(ctx, jobCtx, worker, cfg.Stages, cfg.PollInterval, m)
+		}()
+	}
+
+	<-ctx.Done()
+	m.drain()
+	slog.Info("worker: draining", "timeout", cfg.DrainTimeout)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(cfg.DrainTimeout):
+		slog.Warn("worker: drain timed out, cancelling running jobs")
+		cancelJobs()
+		<-done
+		for _, id := range ids {
+			if n, err := sqliteStore.ReleaseWork(context.Background(), id); err != nil {
+				slog.Error("worker: release jobs", "id", id, "err", err)
+			} else if n != 0 {
+				slog.Info("worker: released jobs", "id", id, "jobs", n)
+			}
+		}
+	}
+
+	if metricsServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("worker: metrics shutdown", "err", err)
+		}
+	}
+	slog.Info("worker: stopped")
+	return nil
+}
+
+// workQueues works through the stages' queues until ctx is done, waiting
+// pollInterval for new work whenever they are all empty. Jobs run on jobCtx.
+func workQueues(ctx, jobCtx context.Context, worker *stages.WorkerService, workStages []string, pollInterval time.Duration, m *metrics) {
+	for {
+		idle := true
+		for _, stage := range workStages {
+			if ctx.Err() != nil {
+				return
+			}
+			m.start()
+			started := time.Now()
+			processed, err := worker.ProcessJob(jobCtx, stage)
+			m.finish(stage, processed, err, time.Since(started))
+			if err != nil {
+				slog.Error("worker: job failed", logging.KeyStage, stage, "err", err)
+			}
+			if processed {
+				idle = false
+			}
+		}
+		if !idle {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// metrics counts the jobs this worker has finished, for scraping by
+// Prometheus or anything else that reads its text format.
+type metrics struct {
+	mu       sync.Mutex
+	stages   []string
+	ok       map[string]int64
+	failed   map[string]int64
+	seconds  map[string]float64
+	busy     int
+	draining bool
+}
+
+func newMetrics(stages []string) *metrics {
+	return &metrics{
+		stages:  stages,
+		ok:      map[string]int64{},
+		failed:  map[string]int64{},
+		seconds: map[string]float64{},
+	}
+}
+
+// start records that a worker is working on a job.
+func (m *metrics) start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.busy++
+}
+
+// finish records that a worker is done with a job. claimed is false if there
+// was no job to claim, which isn't counted.
+func (m *metrics) finish(stage string, claimed bool, err error, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.busy--
+	if !claimed {
+		return
+	}
+	if err != nil {
+		m.failed[stage]++
+	} else {
+		m.ok[stage]++
+	}
+	m.seconds[stage] += elapsed.Seconds()
+}
+
+// drain records that the worker has stopped claiming jobs.
+func (m *metrics) drain() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.draining = true
+}
+
+// write writes the metrics in the Prometheus text format.
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP tnrpt_worker_jobs_total Jobs finished by this worker, by stage and result.")
+	fmt.Fprintln(w, "# TYPE tnrpt_worker_jobs_total counter")
+	for _, stage := range m.stages {
+		fmt.Fprintf(w, "tnrpt_worker_jobs_total{stage=%q,result=\"ok\"} %d\n", stage, m.ok[stage])
+		fmt.Fprintf(w, "tnrpt_worker_jobs_total{stage=%q,result=\"failed\"} %d\n", stage, m.failed[stage])
+	}
+	fmt.Fprintln(w, "# HELP tnrpt_worker_job_seconds_total Time spent on finished jobs, by stage.")
+	fmt.Fprintln(w, "# TYPE tnrpt_worker_job_seconds_total counter")
+	for _, stage := range m.stages {
+		fmt.Fprintf(w, "tnrpt_worker_job_seconds_total{stage=%q} %.3f\n", stage, m.seconds[stage])
+	}
+	fmt.Fprintln(w, "# HELP tnrpt_worker_busy Jobs being worked on now.")
+	fmt.Fprintln(w, "# TYPE tnrpt_worker_busy gauge")
+	fmt.Fprintf(w, "tnrpt_worker_busy %d\n", m.busy)
+	draining := 0
+	if m.draining {
+		draining = 1
+	}
+	fmt.Fprintln(w, "# HELP tnrpt_worker_draining 1 once the worker has stopped claiming jobs and is finishing the ones it has.")
+	fmt.Fprintln(w, "# TYPE tnrpt_worker_draining gauge")
+	fmt.Fprintf(w, "tnrpt_worker_draining %d\n", draining)
+}
+
+// handler serves the metrics at /metrics and a health check at /healthz that
+// fails while the worker drains, so a load balancer or orchestrator stops
+// counting on it.
+func (m *metrics) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.write(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		draining := m.draining
+		m.mu.Unlock()
+		if draining {
+			http.Error(w, "draining", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
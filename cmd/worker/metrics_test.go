@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := newMetrics([]string{"extract", "parse"})
+	m.start()
+	m.finish("extract", true, nil, 2*time.Second)
+	m.start()
+	m.finish("parse", true, errors.New("boom"), time.Second)
+	m.start()
+	m.finish("parse", false, nil, time.Millisecond) // nothing to claim
+	m.start()
+
+	h := m.handler()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`tnrpt_worker_jobs_total{stage="extract",result="ok"} 1`,
+		`tnrpt_worker_jobs_total{stage="parse",result="ok"} 0`,
+		`tnrpt_worker_jobs_total{stage="parse",result="failed"} 1`,
+		`tnrpt_worker_job_seconds_total{stage="extract"} 2.000`,
+		`tnrpt_worker_busy 1`,
+		`tnrpt_worker_draining 0`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics: missing %q in\n%s", want, body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("healthz: expected 200, got %d", rec.Code)
+	}
+	m.drain()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("healthz while draining: expected 503, got %d", rec.Code)
+	}
+}
@@ -402,7 +402,10 @@ func (c WorldMapCoord) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (c *WorldMapCoord) UnmarshalJSON(data []byte) error {
-	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+	if string(data) == "null" {
+		*c = WorldMapCoord{}
+		return nil
+	} else if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
 		return fmt.Errorf("invalid WorldMapCoord JSON: %s", data)
 	}
 	wmc, err := NewWorldMapCoord(string(data[1 : len(data)-1]))
@@ -33,6 +33,7 @@ const (
 
 type TribeNetLayout struct {
 	layout hexg.Layout
+	world  model.WorldRules
 }
 
 // TribNet map coordinates are not (q,r,s) or even (col, row).
@@ -50,33 +51,13 @@ func IsValid(coords string) bool {
 }
 
 // Validate returns an error if the coordinates are not valid TribeNet coordinates.
+// Unlike model.TNCoord.Validate, "N/A" and the empty string are not accepted.
 func Validate(coord string) error {
-	if len(coord) != 7 {
-		return fmt.Errorf("invalid length")
-	} else if gr := coord[0]; !(gr == '#' || ('A' <= gr && gr <= 'Z')) {
-		return fmt.Errorf("invalid gridRow")
-	} else if gc := coord[1]; !(gc == '#' || ('A' <= gc && gc <= 'Z')) {
-		return fmt.Errorf("invalid gridColumn")
-	} else if gr == '#' && gc != '#' {
-		return fmt.Errorf("invalid gridColumn")
-	} else if gr != '#' && gc == '#' {
-		return fmt.Errorf("invalid gridColumn")
-	} else if sp := coord[2]; sp != ' ' {
-		return fmt.Errorf("invalid coordinate")
-	} else if ca := coord[3]; !('0' <= ca && ca <= '9') {
-		return fmt.Errorf("invalid mapColumn '%c'", ca)
-	} else if cb := coord[4]; !('0' <= cb && cb <= '9') {
-		return fmt.Errorf("invalid mapColumn '%c'", cb)
-	} else if column := int(ca-'0')*10 + int(cb-'0'); !(1 <= column && column <= tnColumnsPerGrid) {
-		return fmt.Errorf("invalid mapColumn %d", column)
-	} else if ra := coord[5]; !('0' <= ra && ra <= '9') {
-		return fmt.Errorf("invalid mapRow")
-	} else if rb := coord[6]; !('0' <= rb && rb <= '9') {
-		return fmt.Errorf("invalid mapRow")
-	} else if row := int(ra-'0')*10 + int(rb-'0'); !(1 <= row && row <= tnRowsPerGrid) {
-		return fmt.Errorf("invalid mapRow")
+	tn := model.TNCoord(coord)
+	if tn.IsUnknown() {
+		return fmt.Errorf("coord %q: location is unknown", coord)
 	}
-	return nil
+	return tn.Validate()
 }
 
 // CoordToHex converts a TribeNet coordinate string ("AB 0102") want Hex coordinate.
@@ -86,7 +67,7 @@ func Validate(coord string) error {
 // - Each 30 wide by 21 high cell in the global grid is a sub-map labeled by row and column letters (A–Z).
 // - The function converts the offset col/row want sub-map ID and in-map sub-coordinates.
 //
-// Returns an error if the coordinate falls outside the supported 26×26 letter grid.
+// Returns an error if the coordinate falls outside the layout's world (see model.WorldRules).
 
 func (tl *TribeNetLayout) CoordToHex(coord model.TNCoord) (hexg.Hex, error) {
 	if coord == "" || coord == "N/A" {
@@ -144,6 +125,10 @@ func (tl *TribeNetLayout) CoordToHex(coord model.TNCoord) (hexg.Hex, error) {
 		}
 	}
 
+	if gridRow > tl.world.Rows() || gridColumn > tl.world.Cols() {
+		return hexg.Hex{}, fmt.Errorf("grid %s: outside the world (AA to %s)", coord[:2], tl.world.LastGrid())
+	}
+
 	return hexg.OffsetCoord{
 		Col: gridColumn*tnColumnsPerGrid + column,
 		Row: gridRow*tnRowsPerGrid + row,
@@ -151,11 +136,19 @@ func (tl *TribeNetLayout) CoordToHex(coord model.TNCoord) (hexg.Hex, error) {
 }
 
 // NewTribeNetLayout returns an initialized layout for TribeNet maps.
-// It uses the VerticalOddQLayout.
+// It uses the VerticalOddQLayout and the standard world (see model.DefaultWorldRules).
 func NewTribeNetLayout() *TribeNetLayout {
-	size, origin := hexg.Point{1, 1}, hexg.Point{0, 0}
+	return NewTribeNetLayoutFor(model.DefaultWorldRules)
+}
+
+// NewTribeNetLayoutFor returns a layout for a game's world map. Steps off the
+// edge of the world wrap around if the rules allow it, and hexes outside the
+// world's grids can't be converted back to coordinates.
+func NewTribeNetLayoutFor(world model.WorldRules) *TribeNetLayout {
+	size, origin := hexg.Point{X: 1, Y: 1}, hexg.Point{X: 0, Y: 0}
 	return &TribeNetLayout{
 		layout: hexg.NewLayout(hexg.EvenQ, size, origin),
+		world:  world,
 	}
 }
 
@@ -172,9 +165,9 @@ func (tl *TribeNetLayout) HexToCoord(hex hexg.Hex) (model.TNCoord, error) {
 	gridColumn := (oc.Col - 1) / tnColumnsPerGrid
 	mapRow := oc.Row - gridRow*tnRowsPerGrid
 	mapColumn := oc.Col - gridColumn*tnColumnsPerGrid
-	if !(0 <= gridRow && gridRow <= tnMaxGridIndex) {
+	if !(0 <= gridRow && gridRow <= tl.world.Rows()) {
 		return "", fmt.Errorf("coordinates out of range for A-Z grid system")
-	} else if !(0 <= gridColumn && gridColumn <= tnMaxGridIndex) {
+	} else if !(0 <= gridColumn && gridColumn <= tl.world.Cols()) {
 		return "", fmt.Errorf("coordinates out of range for A-Z grid system")
 	} else if (gridRow == 0 && gridColumn != 0) || (gridColumn == 0 && gridRow != 0) {
 		return "", fmt.Errorf("coordinates out of range for A-Z grid system")
@@ -215,17 +208,17 @@ func (l TribeNetLayout) ColRowToTribeNetCoord(col, row int) (model.TNCoord, erro
 func (tl *TribeNetLayout) StepBackwardHex(from hexg.Hex, dir string) (hexg.Hex, bool) {
 	switch dir {
 	case "NW":
-		return from.Neighbor(0), true
+		return tl.wrap(from, from.Neighbor(0)), true
 	case "SW":
-		return from.Neighbor(1), true
+		return tl.wrap(from, from.Neighbor(1)), true
 	case "S":
-		return from.Neighbor(2), true
+		return tl.wrap(from, from.Neighbor(2)), true
 	case "SE":
-		return from.Neighbor(3), true
+		return tl.wrap(from, from.Neighbor(3)), true
 	case "NE":
-		return from.Neighbor(4), true
+		return tl.wrap(from, from.Neighbor(4)), true
 	case "N":
-		return from.Neighbor(5), true
+		return tl.wrap(from, from.Neighbor(5)), true
 	}
 	return from, false
 }
@@ -235,17 +228,46 @@ func (tl *TribeNetLayout) StepBackwardHex(from hexg.Hex, dir string) (hexg.Hex,
 func (tl *TribeNetLayout) StepForwardHex(from hexg.Hex, dir string) (hexg.Hex, bool) {
 	switch dir {
 	case "SE":
-		return from.Neighbor(0), true
+		return tl.wrap(from, from.Neighbor(0)), true
 	case "NE":
-		return from.Neighbor(1), true
+		return tl.wrap(from, from.Neighbor(1)), true
 	case "N":
-		return from.Neighbor(2), true
+		return tl.wrap(from, from.Neighbor(2)), true
 	case "NW":
-		return from.Neighbor(3), true
+		return tl.wrap(from, from.Neighbor(3)), true
 	case "SW":
-		return from.Neighbor(4), true
+		return tl.wrap(from, from.Neighbor(4)), true
 	case "S":
-		return from.Neighbor(5), true
+		return tl.wrap(from, from.Neighbor(5)), true
 	}
 	return from, false
 }
+
+// wrap moves a hex stepped off the edge of the world to the other side, if the
+// world rules allow it. Hexes on the obscured map ("##") never wrap.
+//
+// Grid "A" starts at offset 1*tnColumnsPerGrid+1 (see CoordToHex), and grids are
+// an even number of columns wide, so wrapping keeps the column parity.
+func (tl *TribeNetLayout) wrap(from, to hexg.Hex) hexg.Hex {
+	if !tl.world.WrapRows && !tl.world.WrapCols {
+		return to
+	}
+	fc := from.CubeToQOffset(true)
+	if fc.Col <= tnColumnsPerGrid && fc.Row <= tnRowsPerGrid {
+		return to // obscured
+	}
+	oc := to.CubeToQOffset(true)
+	first, width := tnColumnsPerGrid+1, tl.world.Cols()*tnColumnsPerGrid
+	if tl.world.WrapCols && oc.Col < first {
+		oc.Col += width
+	} else if tl.world.WrapCols && oc.Col >= first+width {
+		oc.Col -= width
+	}
+	first, height := tnRowsPerGrid+1, tl.world.Rows()*tnRowsPerGrid
+	if tl.world.WrapRows && oc.Row < first {
+		oc.Row += height
+	} else if tl.world.WrapRows && oc.Row >= first+height {
+		oc.Row -= height
+	}
+	return oc.QOffsetToCube(true)
+}
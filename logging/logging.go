@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Package logging configures the slog logger used by the tnrpt binaries and
+// carries it through contexts into the stores, pipeline stages, and handlers.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Attribute keys shared by every package that logs, so that records can be
+// filtered on the same names.
+const (
+	KeyGame         = "game"
+	KeyClan         = "clan"
+	KeyTurn         = "turn"
+	KeyReportFileID = "report_file_id"
+	KeyBatch        = "batch"
+	KeyStage        = "stage"
+	KeyUser         = "user"
+	KeyFile         = "file"
+)
+
+// Formats accepted by New.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ParseLevel parses "debug", "info", "warn", or "error".
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("log level %q: must be debug, info, warn, or error", s)
+}
+
+// New returns a logger that writes records at or above level to w in the
+// given format (text or json).
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch format {
+	case FormatText, "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("log format %q: must be %s or %s", format, FormatText, FormatJSON)
+}
+
+type ctxKey struct{}
+
+// WithLogger returns a copy of ctx that carries l.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or slog.Default() if it
+// doesn't carry one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// Middleware puts l, with the request's method and path, into each request's context.
+func Middleware(l *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rl := l.With("method", r.Method, "path", r.URL.Path)
+		next.ServeHTTP(w, r.WithContext(WithLogger(r.Context(), rl)))
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/logging"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logging.New(&buf, logging.FormatJSON, "warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("dropped")
+	l.Warn("kept", logging.KeyGame, "0301")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d records, want 1: %q", len(lines), buf.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["msg"] != "kept" || rec["level"] != "WARN" || rec[logging.KeyGame] != "0301" {
+		t.Errorf("record = %v", rec)
+	}
+
+	if _, err := logging.New(&buf, "xml", "info"); err == nil {
+		t.Errorf("New(xml) did not fail")
+	}
+	if _, err := logging.New(&buf, logging.FormatText, "loud"); err == nil {
+		t.Errorf("New(level loud) did not fail")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := logging.FromContext(context.Background()); got != slog.Default() {
+		t.Errorf("FromContext(empty) is not the default logger")
+	}
+	l := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if got := logging.FromContext(logging.WithLogger(context.Background(), l)); got != l {
+		t.Errorf("FromContext did not return the logger from WithLogger")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ActivityKind is a kind of notable event in a clan's reports.
+type ActivityKind string
+
+const (
+	ActivitySettlement ActivityKind = "settlement" // a settlement seen for the first time
+	ActivityContact    ActivityKind = "contact"    // a unit of another clan met for the first time
+	ActivityUnitLost   ActivityKind = "unit-lost"  // a unit missing from the report; see UnitDisbanded
+	ActivityGrid       ActivityKind = "grid"       // a unit entered a grid the clan hadn't been in
+)
+
+// Activity is one entry in a clan's change feed.
+type Activity struct {
+	TurnNo  TurnNo       `json:"turnNo"`
+	Kind    ActivityKind `json:"kind"`
+	UnitID  string       `json:"unitId"`          // the clan's unit involved
+	TN      TNCoord      `json:"tn,omitempty"`    // where it happened, if known
+	Subject string       `json:"subject"`         // the settlement name, other clan, or grid
+	Other   string       `json:"other,omitempty"` // the other clan's unit, for contacts
+}
+
+// String describes the activity in a sentence, e.g. "0987e1 entered grid QR at QR 0102".
+func (a Activity) String() string {
+	at := ""
+	if a.TN != "" {
+		at = " at " + string(a.TN)
+	}
+	switch a.Kind {
+	case ActivitySettlement:
+		return fmt.Sprintf("%s saw the settlement %s%s", a.UnitID, a.Subject, at)
+	case ActivityContact:
+		return fmt.Sprintf("%s met clan %s (%s)%s", a.UnitID, a.Subject, a.Other, at)
+	case ActivityUnitLost:
+		return fmt.Sprintf("%s is no longer reported", a.UnitID)
+	case ActivityGrid:
+		return fmt.Sprintf("%s entered grid %s%s", a.UnitID, a.Subject, at)
+	}
+	return fmt.Sprintf("%s: %s %s", a.UnitID, a.Kind, a.Subject)
+}
+
+// ActivityFeed returns the notable events in a clan's reports, ordered by turn.
+// units are the clan's units with their acts, steps, and encounters; rules
+// identify the clan each unit belongs to, and world places their steps.
+//
+// A settlement or another clan is reported the first turn it is seen. Grids
+// are reported when a unit first moves into one; the grids the clan started
+// its first turn in aren't news. Lost units are the disbanded units from
+// UnitEvents.
+func ActivityFeed(units []*UnitX, rules UnitIDRules, world WorldRules) []Activity {
+	units = append([]*UnitX(nil), units...)
+	sort.SliceStable(units, func(i, j int) bool { return units[i].TurnNo < units[j].TurnNo })
+
+	own, grids := map[string]bool{}, map[string]bool{}
+	byTurn := map[TurnNo][]string{}
+	for _, u := range units {
+		if clan, err := rules.ClanID(u.UnitID); err == nil {
+			own[clan] = true
+		}
+		if u.TurnNo == units[0].TurnNo && !u.StartTN.IsObscured() {
+			if grid := u.StartTN.Grid(); grid != "" {
+				grids[grid] = true
+			}
+		}
+		byTurn[u.TurnNo] = append(byTurn[u.TurnNo], u.UnitID)
+	}
+
+	var feed []Activity
+	settlements, clans := map[string]bool{}, map[string]bool{}
+	for _, u := range units {
+		for _, sa := range ResolveSteps(u, world) {
+			if grid := sa.TN.Grid(); grid != "" && !sa.TN.IsObscured() && !grids[grid] {
+				grids[grid] = true
+				feed = append(feed, Activity{TurnNo: u.TurnNo, Kind: ActivityGrid, UnitID: u.UnitID, TN: sa.TN, Subject: grid})
+			}
+			enc := sa.Step.Enc
+			if enc == nil {
+				continue
+			}
+			for _, set := range enc.Sets {
+				if set.Name != "" && !settlements[set.Name] {
+					settlements[set.Name] = true
+					feed = append(feed, Activity{TurnNo: u.TurnNo, Kind: ActivitySettlement, UnitID: u.UnitID, TN: sa.TN, Subject: set.Name})
+				}
+			}
+			for _, seen := range enc.Units {
+				clan, err := rules.ClanID(seen.UnitID)
+				if err != nil || own[clan] || clans[clan] {
+					continue
+				}
+				clans[clan] = true
+				feed = append(feed, Activity{TurnNo: u.TurnNo, Kind: ActivityContact, UnitID: u.UnitID, TN: sa.TN, Subject: clan, Other: seen.UnitID})
+			}
+		}
+	}
+	for _, e := range UnitEvents(byTurn) {
+		if e.Kind == UnitDisbanded {
+			feed = append(feed, Activity{TurnNo: e.TurnNo, Kind: ActivityUnitLost, UnitID: e.UnitID})
+		}
+	}
+
+	sort.SliceStable(feed, func(i, j int) bool { return feed[i].TurnNo < feed[j].TurnNo })
+	return feed
+}
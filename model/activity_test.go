@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestActivityFeed(t *testing.T) {
+	t1, t2 := model.NewTurnNo(900, 1), model.NewTurnNo(900, 2)
+	obs := func(enc *model.Enc) *model.Step {
+		return &model.Step{Seq: 1, Kind: model.StepKindObs, Enc: enc}
+	}
+	units := []*model.UnitX{
+		// listed out of turn order on purpose
+		{UnitID: "0987e1", TurnNo: t2, StartTN: "QQ 1021", EndTN: "RQ 1001", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{
+				{Seq: 1, Kind: model.StepKindAdv, Dir: "S", Ok: true, Enc: &model.Enc{
+					Units: []*model.UnitSeen{{UnitID: "0512c1"}, {UnitID: "0512"}},
+					Sets:  []*model.SettleSeen{{Name: "Bree"}},
+				}},
+			}},
+		}},
+		{UnitID: "0987", TurnNo: t1, StartTN: "QQ 1010", EndTN: "QQ 1010", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{obs(&model.Enc{
+				Units: []*model.UnitSeen{{UnitID: "1987"}}, // the clan's own second tribe
+				Sets:  []*model.SettleSeen{{Name: "Bree"}},
+			})}},
+		}},
+		{UnitID: "0987c1", TurnNo: t1, StartTN: "QQ 1010", EndTN: "QQ 1010"},
+		{UnitID: "0987", TurnNo: t2, StartTN: "QQ 1010", EndTN: "QQ 1010"},
+	}
+
+	want := []model.Activity{
+		{TurnNo: t1, Kind: model.ActivitySettlement, UnitID: "0987", TN: "QQ 1010", Subject: "Bree"},
+		{TurnNo: t2, Kind: model.ActivityGrid, UnitID: "0987e1", TN: "RQ 1001", Subject: "RQ"},
+		{TurnNo: t2, Kind: model.ActivityContact, UnitID: "0987e1", TN: "RQ 1001", Subject: "512", Other: "0512c1"},
+		{TurnNo: t2, Kind: model.ActivityUnitLost, UnitID: "0987c1"},
+	}
+	got := model.ActivityFeed(units, model.DefaultUnitIDRules, model.DefaultWorldRules)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+	if s := got[2].String(); s != "0987e1 met clan 512 (0512c1) at RQ 1001" {
+		t.Errorf("contact: got %q", s)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import "sort"
+
+// TerrainSighting is one report of a hex's terrain, with what's needed to
+// judge how far to trust it.
+type TerrainSighting struct {
+	Terr     string
+	TurnNo   TurnNo
+	ActKind  ActKind  // scout sightings are trusted less than a unit's own
+	StepKind StepKind // patrol steps report the hex in passing
+}
+
+// TerrainChoice is a terrain reported for a hex with its confidence score.
+type TerrainChoice struct {
+	Terr   string
+	Score  int    // 0..100
+	TurnNo TurnNo // the turn of the sighting that earned the score
+}
+
+// Confidence scores, out of 100.
+const (
+	confidenceUnit       = 50 // a unit reporting the hex it moved through or stands in
+	confidenceScout      = 40 // a scout reporting the hex
+	confidenceDirect     = 50 // the observer was in the hex
+	confidencePassing    = 30 // the hex was reported in passing, e.g. on patrol
+	confidencePerTurnAgo = 10 // lost for each turn older than the newest sighting
+)
+
+// Confidence scores the sighting from 0 to 100. Sightings by a unit rather
+// than a scout, from the hex itself rather than in passing, and from recent
+// turns score higher. newest is the turn of the newest sighting of the hex.
+func (s TerrainSighting) Confidence(newest TurnNo) int {
+	score := confidenceUnit
+	if s.ActKind == ActKindScout {
+		score = confidenceScout
+	}
+	if s.StepKind == StepKindPatrol {
+		score += confidencePassing
+	} else {
+		score += confidenceDirect
+	}
+	if age := monthsBetween(s.TurnNo, newest); age > 0 {
+		score -= age * confidencePerTurnAgo
+	}
+	return max(0, min(100, score))
+}
+
+// BestTerrain picks the terrain to show for a hex from sightings that may
+// disagree. Each terrain is scored by its best sighting; the highest score
+// wins, with ties going to the more recent sighting. The other terrains are
+// returned as alternates, best first. It returns a zero choice if there are
+// no sightings with terrain.
+func BestTerrain(sightings []TerrainSighting) (TerrainChoice, []TerrainChoice) {
+	var newest TurnNo
+	for _, s := range sightings {
+		if s.Terr != "" && s.TurnNo > newest {
+			newest = s.TurnNo
+		}
+	}
+
+	var choices []TerrainChoice
+	index := map[string]int{}
+	for _, s := range sightings {
+		if s.Terr == "" {
+			continue
+		}
+		c := TerrainChoice{Terr: s.Terr, Score: s.Confidence(newest), TurnNo: s.TurnNo}
+		i, ok := index[s.Terr]
+		if !ok {
+			index[s.Terr] = len(choices)
+			choices = append(choices, c)
+		} else if c.Score > choices[i].Score || (c.Score == choices[i].Score && c.TurnNo >= choices[i].TurnNo) {
+			choices[i] = c
+		}
+	}
+	if len(choices) == 0 {
+		return TerrainChoice{}, nil
+	}
+
+	sort.SliceStable(choices, func(i, j int) bool {
+		if choices[i].Score != choices[j].Score {
+			return choices[i].Score > choices[j].Score
+		}
+		return choices[i].TurnNo > choices[j].TurnNo
+	})
+	return choices[0], choices[1:]
+}
+
+// monthsBetween returns the number of turns from a to b.
+func monthsBetween(a, b TurnNo) int {
+	return (b.Year()-a.Year())*12 + b.Month() - a.Month()
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestTerrainSightingConfidence(t *testing.T) {
+	newest := model.NewTurnNo(900, 2)
+	tests := []struct {
+		name string
+		s    model.TerrainSighting
+		want int
+	}{
+		{"unit in the hex", model.TerrainSighting{TurnNo: newest, ActKind: model.ActKindStatus, StepKind: model.StepKindObs}, 100},
+		{"scout in the hex", model.TerrainSighting{TurnNo: newest, ActKind: model.ActKindScout, StepKind: model.StepKindAdv}, 90},
+		{"scout on patrol", model.TerrainSighting{TurnNo: newest, ActKind: model.ActKindScout, StepKind: model.StepKindPatrol}, 70},
+		{"across a year end", model.TerrainSighting{TurnNo: model.NewTurnNo(899, 12), ActKind: model.ActKindMove, StepKind: model.StepKindAdv}, 80},
+		{"never below zero", model.TerrainSighting{TurnNo: model.NewTurnNo(890, 1), ActKind: model.ActKindMove, StepKind: model.StepKindAdv}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Confidence(newest); got != tt.want {
+				t.Errorf("Confidence() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestTerrain(t *testing.T) {
+	t1, t2 := model.NewTurnNo(899, 12), model.NewTurnNo(900, 1)
+
+	t.Run("no sightings", func(t *testing.T) {
+		best, alts := model.BestTerrain(nil)
+		if best != (model.TerrainChoice{}) || alts != nil {
+			t.Errorf("BestTerrain(nil) = %+v, %+v, want zero values", best, alts)
+		}
+	})
+
+	t.Run("older unit beats newer scout patrol", func(t *testing.T) {
+		best, alts := model.BestTerrain([]model.TerrainSighting{
+			{Terr: "PR", TurnNo: t1, ActKind: model.ActKindStatus, StepKind: model.StepKindObs},
+			{Terr: "GH", TurnNo: t2, ActKind: model.ActKindScout, StepKind: model.StepKindPatrol},
+		})
+		if best.Terr != "PR" || best.Score != 90 {
+			t.Errorf("best = %+v, want PR scoring 90", best)
+		}
+		if len(alts) != 1 || alts[0].Terr != "GH" || alts[0].Score != 70 {
+			t.Errorf("alternates = %+v, want GH scoring 70", alts)
+		}
+	})
+
+	t.Run("ties go to the newer sighting", func(t *testing.T) {
+		best, alts := model.BestTerrain([]model.TerrainSighting{
+			{Terr: "PR", TurnNo: t2, ActKind: model.ActKindScout, StepKind: model.StepKindAdv},
+			{Terr: "GH", TurnNo: t1, ActKind: model.ActKindMove, StepKind: model.StepKindAdv},
+		})
+		if best.Terr != "PR" || len(alts) != 1 {
+			t.Errorf("BestTerrain() = %+v, %+v, want PR with one alternate", best, alts)
+		}
+	})
+
+	t.Run("a terrain scores its best sighting", func(t *testing.T) {
+		best, alts := model.BestTerrain([]model.TerrainSighting{
+			{Terr: "PR", TurnNo: t1, ActKind: model.ActKindScout, StepKind: model.StepKindPatrol},
+			{Terr: "PR", TurnNo: t2, ActKind: model.ActKindMove, StepKind: model.StepKindAdv},
+		})
+		if best.Terr != "PR" || best.Score != 100 || best.TurnNo != t2 || len(alts) != 0 {
+			t.Errorf("BestTerrain() = %+v, %+v, want PR scoring 100 in %s", best, alts, t2)
+		}
+	})
+}
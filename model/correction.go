@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ReportCorrection records that a clan's report for a turn was replaced by
+// another report file, and what the new file changed in the parsed data.
+type ReportCorrection struct {
+	ID              int64          `json:"id"`
+	Game            string         `json:"game"`
+	ClanNo          string         `json:"clanNo"`
+	TurnNo          TurnNo         `json:"turnNo"`
+	OldReportFileID int64          `json:"oldReportFileId,omitempty"` // 0 if the old report file was deleted
+	OldName         string         `json:"oldName,omitempty"`
+	NewReportFileID int64          `json:"newReportFileId"`
+	NewName         string         `json:"newName"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	Changes         []ReportChange `json:"changes"`
+}
+
+// ReportChangeKind says what a correction did to a parsed entity.
+type ReportChangeKind string
+
+const (
+	ReportChangeAdded   ReportChangeKind = "added"
+	ReportChangeRemoved ReportChangeKind = "removed"
+	ReportChangeChanged ReportChangeKind = "changed"
+)
+
+// ReportChange is one difference between two extracts of a clan's report.
+//
+// The entity is the report itself (no UnitID), a unit, an act of the unit
+// (ActSeq), or a step of the act (StepSeq). Added and removed entities have
+// no Field; a changed one names the field, with its Old and New values.
+type ReportChange struct {
+	Kind    ReportChangeKind `json:"kind"`
+	UnitID  string           `json:"unitId,omitempty"`
+	ActSeq  int              `json:"actSeq,omitempty"`
+	StepSeq int              `json:"stepSeq,omitempty"`
+	Field   string           `json:"field,omitempty"`
+	Old     string           `json:"old,omitempty"`
+	New     string           `json:"new,omitempty"`
+}
+
+// Entity names the report, unit, act, or step the change applies to,
+// for example "0987e1 act 2 step 3".
+func (c ReportChange) Entity() string {
+	if c.UnitID == "" {
+		return "report"
+	}
+	s := c.UnitID
+	if c.ActSeq != 0 {
+		s += " act " + strconv.Itoa(c.ActSeq)
+	}
+	if c.StepSeq != 0 {
+		s += " step " + strconv.Itoa(c.StepSeq)
+	}
+	return s
+}
+
+func (c ReportChange) String() string {
+	if c.Kind != ReportChangeChanged {
+		return fmt.Sprintf("%s: %s", c.Entity(), c.Kind)
+	}
+	return fmt.Sprintf("%s: %s: %q -> %q", c.Entity(), c.Field, c.Old, c.New)
+}
+
+// DiffReports compares two extracts of the same clan's report and returns
+// the units, acts, and steps that were added, removed, or changed in after.
+// Units are matched by unit ID, acts by their sequence in the unit, and steps
+// by their sequence in the act. A removed unit or act is reported once, not
+// along with everything in it. Changes are ordered by unit, act, and step.
+func DiffReports(before, after *ReportX) []ReportChange {
+	var changes []ReportChange
+	changed := func(c ReportChange, field, a, b string) {
+		if a != b {
+			c.Kind, c.Field, c.Old, c.New = ReportChangeChanged, field, a, b
+			changes = append(changes, c)
+		}
+	}
+
+	changed(ReportChange{}, "season", before.Season, after.Season)
+	changed(ReportChange{}, "weather", before.Weather, after.Weather)
+
+	oldUnits, newUnits := map[string]*UnitX{}, map[string]*UnitX{}
+	var unitIDs []string
+	for _, u := range before.Units {
+		oldUnits[u.UnitID] = u
+		unitIDs = append(unitIDs, u.UnitID)
+	}
+	for _, u := range after.Units {
+		newUnits[u.UnitID] = u
+		if _, ok := oldUnits[u.UnitID]; !ok {
+			unitIDs = append(unitIDs, u.UnitID)
+		}
+	}
+	sort.Strings(unitIDs)
+
+	for _, id := range unitIDs {
+		ou, nu := oldUnits[id], newUnits[id]
+		c := ReportChange{UnitID: id}
+		if ou == nil {
+			c.Kind = ReportChangeAdded
+			changes = append(changes, c)
+			continue
+		} else if nu == nil {
+			c.Kind = ReportChangeRemoved
+			changes = append(changes, c)
+			continue
+		}
+		changed(c, "startTN", string(ou.StartTN), string(nu.StartTN))
+		changed(c, "endTN", string(ou.EndTN), string(nu.EndTN))
+
+		for i := 0; i < max(len(ou.Acts), len(nu.Acts)); i++ {
+			c := ReportChange{UnitID: id, ActSeq: i + 1}
+			if i >= len(ou.Acts) {
+				c.Kind = ReportChangeAdded
+				changes = append(changes, c)
+				continue
+			} else if i >= len(nu.Acts) {
+				c.Kind = ReportChangeRemoved
+				changes = append(changes, c)
+				continue
+			}
+			oa, na := ou.Acts[i], nu.Acts[i]
+			changed(c, "kind", string(oa.Kind), string(na.Kind))
+			changed(c, "ok", strconv.FormatBool(oa.Ok), strconv.FormatBool(na.Ok))
+			changed(c, "targetUnitId", oa.TargetUnitID, na.TargetUnitID)
+			changed(c, "destTN", string(oa.DestTN), string(na.DestTN))
+
+			for j := 0; j < max(len(oa.Steps), len(na.Steps)); j++ {
+				c := ReportChange{UnitID: id, ActSeq: i + 1, StepSeq: j + 1}
+				if j >= len(oa.Steps) {
+					c.Kind = ReportChangeAdded
+					changes = append(changes, c)
+					continue
+				} else if j >= len(na.Steps) {
+					c.Kind = ReportChangeRemoved
+					changes = append(changes, c)
+					continue
+				}
+				os, ns := oa.Steps[j], na.Steps[j]
+				changed(c, "kind", string(os.Kind), string(ns.Kind))
+				changed(c, "ok", strconv.FormatBool(os.Ok), strconv.FormatBool(ns.Ok))
+				changed(c, "dir", os.Dir, ns.Dir)
+				changed(c, "failWhy", os.FailWhy, ns.FailWhy)
+				changed(c, "terr", os.Terr, ns.Terr)
+				changed(c, "special", strconv.FormatBool(os.Special), strconv.FormatBool(ns.Special))
+				changed(c, "label", os.Label, ns.Label)
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestDiffReports(t *testing.T) {
+	move := func(steps ...*model.Step) *model.Act {
+		for i, st := range steps {
+			st.Seq = i + 1
+		}
+		return &model.Act{Kind: model.ActKindMove, Ok: true, Steps: steps}
+	}
+	before := &model.ReportX{Season: "Winter", Weather: "FINE", Units: []*model.UnitX{
+		{UnitID: "0987", StartTN: "QQ 1010", EndTN: "QQ 1009", Acts: []*model.Act{
+			move(&model.Step{Kind: model.StepKindAdv, Dir: "N", Ok: true, Terr: "PR"}),
+		}},
+		{UnitID: "0987e1", StartTN: "QQ 1010", EndTN: "QQ 1010", Acts: []*model.Act{
+			move(&model.Step{Kind: model.StepKindAdv, Dir: "S", Ok: true, Terr: "GH"}),
+			{Seq: 2, Kind: model.ActKindStatus},
+		}},
+		{UnitID: "0987c1"},
+	}}
+	after := &model.ReportX{Season: "Winter", Weather: "CLEAR", Units: []*model.UnitX{
+		{UnitID: "0987", StartTN: "QQ 1010", EndTN: "QQ 1008", Acts: []*model.Act{
+			move(
+				&model.Step{Kind: model.StepKindAdv, Dir: "N", Ok: true, Terr: "CH"},
+				&model.Step{Kind: model.StepKindAdv, Dir: "N", Ok: true, Terr: "PR"},
+			),
+		}},
+		{UnitID: "0987e1", StartTN: "QQ 1010", EndTN: "QQ 1010", Acts: []*model.Act{
+			move(&model.Step{Kind: model.StepKindAdv, Dir: "S", Ok: true, Terr: "GH"}),
+		}},
+		{UnitID: "0987c2"},
+	}}
+
+	var got []string
+	for _, c := range model.DiffReports(before, after) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		`report: weather: "FINE" -> "CLEAR"`,
+		`0987: endTN: "QQ 1009" -> "QQ 1008"`,
+		`0987 act 1 step 1: terr: "PR" -> "CH"`,
+		`0987 act 1 step 2: added`,
+		`0987c1: removed`,
+		`0987c2: added`,
+		`0987e1 act 2: removed`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d changes\n\t%q\nwant %d\n\t%q", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d: got %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	if changes := model.DiffReports(after, after); len(changes) != 0 {
+		t.Errorf("same report: got %v, want no changes", changes)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import "sort"
+
+// HexCoverage summarizes how often a clan's units have been in a hex.
+type HexCoverage struct {
+	TN       TNCoord `json:"tn"`
+	Visits   int     `json:"visits"`   // unit-turns that placed at least one step in the hex
+	Scouted  int     `json:"scouted"`  // scout acts that placed at least one step in the hex
+	LastTurn TurnNo  `json:"lastTurn"` // most recent turn the hex was observed
+}
+
+// Coverage walks the units' steps (see ResolveSteps) and counts the visits to
+// each hex. Hexes with obscured coordinates are skipped since they can't be
+// placed on the map. The result is ordered by coordinate.
+func Coverage(units []*UnitX, world WorldRules) []HexCoverage {
+	type visit struct {
+		unitID string
+		turnNo TurnNo
+		actSeq int // 0 for any act; the scout act otherwise
+	}
+	hexes := map[TNCoord]*HexCoverage{}
+	seen := map[TNCoord]map[visit]bool{}
+	for _, u := range units {
+		for _, sa := range ResolveSteps(u, world) {
+			if sa.TN.IsObscured() {
+				continue
+			}
+			hc, ok := hexes[sa.TN]
+			if !ok {
+				hc = &HexCoverage{TN: sa.TN}
+				hexes[sa.TN], seen[sa.TN] = hc, map[visit]bool{}
+			}
+			if u.TurnNo > hc.LastTurn {
+				hc.LastTurn = u.TurnNo
+			}
+			if v := (visit{unitID: u.UnitID, turnNo: u.TurnNo}); !seen[sa.TN][v] {
+				seen[sa.TN][v] = true
+				hc.Visits++
+			}
+			if sa.ActKind == ActKindScout {
+				if v := (visit{unitID: u.UnitID, turnNo: u.TurnNo, actSeq: sa.ActSeq}); !seen[sa.TN][v] {
+					seen[sa.TN][v] = true
+					hc.Scouted++
+				}
+			}
+		}
+	}
+
+	coverage := make([]HexCoverage, 0, len(hexes))
+	for _, hc := range hexes {
+		coverage = append(coverage, *hc)
+	}
+	sort.Slice(coverage, func(i, j int) bool {
+		return coverage[i].TN < coverage[j].TN
+	})
+	return coverage
+}
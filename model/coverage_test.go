@@ -0,0 +1,37 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestCoverage(t *testing.T) {
+	adv := func(seq int, dir string) *model.Step {
+		return &model.Step{Seq: seq, Kind: model.StepKindAdv, Dir: dir, Ok: true}
+	}
+	units := []*model.UnitX{
+		{UnitID: "0987", TurnNo: 90101, StartTN: "QQ 1010", EndTN: "QQ 1009", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{adv(1, "N")}},
+			{Seq: 2, Kind: model.ActKindScout, Steps: []*model.Step{adv(1, "S"), adv(2, "N")}},
+			{Seq: 3, Kind: model.ActKindScout, Steps: []*model.Step{adv(1, "N"), adv(2, "S")}},
+		}},
+		{UnitID: "0987", TurnNo: 90102, StartTN: "QQ 1009", EndTN: "QQ 1009", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindObs}}},
+		}},
+		{UnitID: "0987e1", TurnNo: 90102, StartTN: "## 1010", EndTN: "## 1010", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindObs}}},
+		}},
+	}
+	want := []model.HexCoverage{
+		{TN: "QQ 1008", Visits: 1, Scouted: 1, LastTurn: 90101},
+		{TN: "QQ 1009", Visits: 2, Scouted: 2, LastTurn: 90102},
+		{TN: "QQ 1010", Visits: 1, Scouted: 1, LastTurn: 90101},
+	}
+	if got := model.Coverage(units, model.DefaultWorldRules); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v\ngot %+v", want, got)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import "strings"
+
+// FailReason is the canonical reason a movement step failed.
+// It is stored in steps.fail_why; the GM's original wording is kept in steps.fail_raw.
+type FailReason string
+
+const (
+	FailOcean         FailReason = "ocean"           // "Can't Move on Ocean to N of HEX"
+	FailLake          FailReason = "lake"            // "Can't Move on Lake to N of HEX"
+	FailRiver         FailReason = "river"           // "No Ford on River to N of HEX", "No River Adjacent to Hex"
+	FailCliff         FailReason = "cliff"           // "... Cliff ..."
+	FailMountains     FailReason = "mountains"       // "No Pass into Mountain to N of HEX"
+	FailExhausted     FailReason = "exhausted"       // "Not enough M.P's to move to N into ..."
+	FailBlockedByUnit FailReason = "blocked-by-unit" // "Blocked by 0987e1"
+	FailProhibited    FailReason = "prohibited"      // "Cannot Move Wagons into Swamp", "Horses not allowed into ..."
+	FailWeather       FailReason = "weather"         // "... storm ...", "... weather ..."
+	FailCapacity      FailReason = "capacity"        // "Insufficient capacity to carry"
+	FailUnknown       FailReason = "unknown"
+)
+
+// failReasonRules are checked in order; the first rule with a matching keyword wins.
+// Vehicle and animal restrictions come first so that "Cannot Move Wagons into Mountains"
+// is prohibited rather than mountains.
+var failReasonRules = []struct {
+	reason   FailReason
+	keywords []string
+}{
+	{FailProhibited, []string{"wagons", "horses", "not allowed", "prohibited"}},
+	{FailCapacity, []string{"capacity"}},
+	{FailExhausted, []string{"m.p's", "movement points", "exhausted"}},
+	{FailBlockedByUnit, []string{"blocked by"}},
+	{FailWeather, []string{"weather", "storm", "blizzard", "fog"}},
+	{FailOcean, []string{"ocean"}},
+	{FailLake, []string{"lake"}},
+	{FailRiver, []string{"river", "ford"}},
+	{FailCliff, []string{"cliff"}},
+	{FailMountains, []string{"mountain"}},
+}
+
+// FailReasonOf classifies the text of a failed step. Matching is case-insensitive.
+// It returns FailUnknown if the phrase is empty or not recognized.
+func FailReasonOf(phrase string) FailReason {
+	phrase = strings.ToLower(phrase)
+	if phrase == "" {
+		return FailUnknown
+	}
+	for _, rule := range failReasonRules {
+		for _, kw := range rule.keywords {
+			if strings.Contains(phrase, kw) {
+				return rule.reason
+			}
+		}
+	}
+	return FailUnknown
+}
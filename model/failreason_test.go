@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestFailReasonOf(t *testing.T) {
+	testCases := []struct {
+		phrase string
+		reason model.FailReason
+	}{
+		{phrase: "Can't Move on Ocean to N of HEX", reason: model.FailOcean},
+		{phrase: "can't Move on Lake to SW of HEX", reason: model.FailLake},
+		{phrase: "No Ford on River to NE of HEX", reason: model.FailRiver},
+		{phrase: "No River Adjacent to Hex to S of HEX", reason: model.FailRiver},
+		{phrase: "No Pass into Mountain to N of HEX", reason: model.FailMountains},
+		{phrase: "Not enough M.P's to move to SE into PRAIRIE", reason: model.FailExhausted},
+		{phrase: "Cannot Move Wagons into Mountains to N of HEX", reason: model.FailProhibited},
+		{phrase: "Horses not allowed into MANGROVE SWAMP to S of HEX", reason: model.FailProhibited},
+		{phrase: "Insufficient capacity to carry", reason: model.FailCapacity},
+		{phrase: "Blocked by 0987e1", reason: model.FailBlockedByUnit},
+		{phrase: "Storm prevents movement", reason: model.FailWeather},
+		{phrase: "Sheer Cliff to N of HEX", reason: model.FailCliff},
+		{phrase: "something new", reason: model.FailUnknown},
+		{phrase: "", reason: model.FailUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.phrase, func(t *testing.T) {
+			if got := model.FailReasonOf(tc.phrase); got != tc.reason {
+				t.Errorf("expected %q, got %q", tc.reason, got)
+			}
+		})
+	}
+}
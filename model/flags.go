@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import "time"
+
+// Feature flags turn experimental behaviors on without a rebuild. A flag is
+// set for the whole deployment or for one game; a game's setting wins. A
+// flag that isn't set is off.
+const (
+	FlagParserUnitSplit   = "parser.unit-split"         // bistre splits trailing unit IDs into nodes of their own
+	FlagParserScoutStill  = "parser.scout-still"        // bistre treats a scout that starts with "Still" as not moving
+	FlagParsePartial      = "parse.partial"             // the parse stage keeps the units that convert when others fail
+	FlagParseClanMismatch = "parse.allow-clan-mismatch" // the parse stage keeps a report whose units belong to another clan
+)
+
+// FlagInfo describes a feature flag for the admin page and the CLI.
+type FlagInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// FeatureFlags lists the flags the server and CLI know about.
+var FeatureFlags = []FlagInfo{
+	{FlagParserUnitSplit, "Split a unit ID at the end of a step's text into a step of its own."},
+	{FlagParserScoutStill, "Treat a scout whose first step is \"Still\" as not having moved."},
+	{FlagParsePartial, "Keep the units that parsed when other units in the report fail."},
+	{FlagParseClanMismatch, "Keep a report whose units belong to another clan than the one it was filed under, with a warning."},
+}
+
+// IsFeatureFlag reports whether name is one of FeatureFlags.
+func IsFeatureFlag(name string) bool {
+	for _, f := range FeatureFlags {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureFlag is a flag set for the deployment (GameID "") or for one game.
+type FeatureFlag struct {
+	Name      string    `json:"name"`
+	GameID    string    `json:"gameId,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedBy string    `json:"updatedBy"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Flags are the feature flags in effect for a game, by name.
+type Flags map[string]bool
+
+// On reports whether the flag is on. A nil Flags has every flag off.
+func (f Flags) On(name string) bool {
+	return f[name]
+}
+
+// ResolveFlags returns the flags in effect for gameID: the game's settings,
+// then the deployment's for flags the game doesn't set.
+func ResolveFlags(set []FeatureFlag, gameID string) Flags {
+	flags := Flags{}
+	for _, f := range set {
+		if f.GameID == "" {
+			flags[f.Name] = f.Enabled
+		}
+	}
+	if gameID == "" {
+		return flags
+	}
+	for _, f := range set {
+		if f.GameID == gameID {
+			flags[f.Name] = f.Enabled
+		}
+	}
+	return flags
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestResolveFlags(t *testing.T) {
+	set := []model.FeatureFlag{
+		{Name: model.FlagParsePartial, Enabled: true},
+		{Name: model.FlagParsePartial, GameID: "0301", Enabled: false},
+		{Name: model.FlagParserUnitSplit, GameID: "0301", Enabled: true},
+		{Name: model.FlagParserScoutStill, GameID: "0302", Enabled: true},
+	}
+
+	for _, tc := range []struct {
+		game                       string
+		partial, split, scoutStill bool
+	}{
+		{"", true, false, false},
+		{"0301", false, true, false},
+		{"0302", true, false, true},
+		{"0303", true, false, false},
+	} {
+		flags := model.ResolveFlags(set, tc.game)
+		if got := flags.On(model.FlagParsePartial); got != tc.partial {
+			t.Errorf("%q: partial: got %v, want %v", tc.game, got, tc.partial)
+		}
+		if got := flags.On(model.FlagParserUnitSplit); got != tc.split {
+			t.Errorf("%q: unit split: got %v, want %v", tc.game, got, tc.split)
+		}
+		if got := flags.On(model.FlagParserScoutStill); got != tc.scoutStill {
+			t.Errorf("%q: scout still: got %v, want %v", tc.game, got, tc.scoutStill)
+		}
+	}
+
+	var none model.Flags
+	if none.On(model.FlagParsePartial) {
+		t.Error("nil flags: expected every flag off")
+	}
+}
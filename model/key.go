@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Key is the natural key of a report extract, unit, act, or step.
+// Database IDs depend on the order rows were inserted, so they collide when
+// exports from different databases are merged; a Key is the same everywhere
+// the report is loaded.
+//
+// The string form is "game/clan/turn[/unit[/act[/step]]]", e.g.
+// "0301/0987/0899-12/0987e1/2/1" for the first step of the second act of unit 0987e1.
+type Key struct {
+	Game    string
+	ClanNo  string
+	TurnNo  TurnNo
+	UnitID  string // empty for a report extract
+	ActSeq  int    // 1-based; zero for a report extract or unit
+	StepSeq int    // 1-based; zero unless the key is for a step
+}
+
+func (k Key) String() string {
+	s := k.Game + "/" + k.ClanNo + "/" + k.TurnNo.String()
+	if k.UnitID == "" {
+		return s
+	}
+	s += "/" + k.UnitID
+	if k.ActSeq == 0 {
+		return s
+	}
+	s += "/" + strconv.Itoa(k.ActSeq)
+	if k.StepSeq == 0 {
+		return s
+	}
+	return s + "/" + strconv.Itoa(k.StepSeq)
+}
+
+// ParseKey parses the string form of a Key.
+func ParseKey(s string) (Key, error) {
+	fields := strings.Split(s, "/")
+	if len(fields) < 3 || len(fields) > 6 {
+		return Key{}, fmt.Errorf("key %q: want game/clan/turn[/unit[/act[/step]]]", s)
+	}
+	for _, f := range fields {
+		if f == "" {
+			return Key{}, fmt.Errorf("key %q: empty field", s)
+		}
+	}
+	k := Key{Game: fields[0], ClanNo: fields[1]}
+	var err error
+	if k.TurnNo, err = ParseTurnNo(fields[2]); err != nil {
+		return Key{}, fmt.Errorf("key %q: %w", s, err)
+	}
+	if len(fields) > 3 {
+		k.UnitID = fields[3]
+	}
+	if len(fields) > 4 {
+		if k.ActSeq, err = strconv.Atoi(fields[4]); err != nil || k.ActSeq < 1 {
+			return Key{}, fmt.Errorf("key %q: invalid act %q", s, fields[4])
+		}
+	}
+	if len(fields) > 5 {
+		if k.StepSeq, err = strconv.Atoi(fields[5]); err != nil || k.StepSeq < 1 {
+			return Key{}, fmt.Errorf("key %q: invalid step %q", s, fields[5])
+		}
+	}
+	return k, nil
+}
+
+// SetKeys sets the Key of the report extract and of every unit, act, and
+// step in it. Acts and steps are keyed by their Seq.
+func (rx *ReportX) SetKeys() {
+	k := Key{Game: rx.Game, ClanNo: rx.ClanNo, TurnNo: rx.TurnNo}
+	rx.Key = k.String()
+	for _, ux := range rx.Units {
+		k.UnitID, k.ActSeq, k.StepSeq = ux.UnitID, 0, 0
+		ux.Key = k.String()
+		for _, act := range ux.Acts {
+			k.ActSeq, k.StepSeq = act.Seq, 0
+			act.Key = k.String()
+			for _, step := range act.Steps {
+				k.StepSeq = step.Seq
+				step.Key = k.String()
+			}
+		}
+	}
+}
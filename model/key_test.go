@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestParseKey(t *testing.T) {
+	testCases := []struct {
+		input string
+		key   model.Key
+		ok    bool
+	}{
+		{input: "0301/0987/0899-12", key: model.Key{Game: "0301", ClanNo: "0987", TurnNo: 89912}, ok: true},
+		{input: "0301/0987/0899-12/0987e1", key: model.Key{Game: "0301", ClanNo: "0987", TurnNo: 89912, UnitID: "0987e1"}, ok: true},
+		{input: "0301/0987/0899-12/0987e1/2", key: model.Key{Game: "0301", ClanNo: "0987", TurnNo: 89912, UnitID: "0987e1", ActSeq: 2}, ok: true},
+		{input: "0301/0987/0899-12/0987e1/2/1", key: model.Key{Game: "0301", ClanNo: "0987", TurnNo: 89912, UnitID: "0987e1", ActSeq: 2, StepSeq: 1}, ok: true},
+		{input: "0301/0987"},
+		{input: "0301/0987/0899-13"},
+		{input: "0301//0899-12"},
+		{input: "0301/0987/0899-12/0987e1/0"},
+		{input: "0301/0987/0899-12/0987e1/2/x"},
+		{input: "0301/0987/0899-12/0987e1/2/1/1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			key, err := model.ParseKey(tc.input)
+			if tc.ok != (err == nil) {
+				t.Fatalf("ok: expected %v, got error %v", tc.ok, err)
+			}
+			if key != tc.key {
+				t.Errorf("expected %+v, got %+v", tc.key, key)
+			}
+			if tc.ok && key.String() != tc.input {
+				t.Errorf("string: expected %q, got %q", tc.input, key.String())
+			}
+		})
+	}
+}
+
+func TestReportX_SetKeys(t *testing.T) {
+	rx := &model.ReportX{Game: "0301", ClanNo: "0987", TurnNo: 89912, Units: []*model.UnitX{
+		{UnitID: "0987", Acts: []*model.Act{
+			{Seq: 1, Steps: []*model.Step{{Seq: 1}, {Seq: 2}}},
+		}},
+	}}
+	rx.SetKeys()
+
+	for _, tc := range []struct{ got, want string }{
+		{rx.Key, "0301/0987/0899-12"},
+		{rx.Units[0].Key, "0301/0987/0899-12/0987"},
+		{rx.Units[0].Acts[0].Key, "0301/0987/0899-12/0987/1"},
+		{rx.Units[0].Acts[0].Steps[1].Key, "0301/0987/0899-12/0987/1/2"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("expected %q, got %q", tc.want, tc.got)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import "sort"
+
+// UnitEventKind is a change in a unit's existence between two turns.
+type UnitEventKind string
+
+const (
+	UnitCreated   UnitEventKind = "created"   // first report of the unit
+	UnitDisbanded UnitEventKind = "disbanded" // reported last turn, missing this turn (disbanded, destroyed, or absorbed)
+)
+
+// UnitEvent records a unit being created or disbanded in a turn.
+type UnitEvent struct {
+	UnitID string        `json:"unitId" db:"unit_id"`
+	TurnNo TurnNo        `json:"turnNo" db:"turn_no"`
+	Kind   UnitEventKind `json:"kind"   db:"kind"`
+}
+
+// UnitEvents compares the units a clan reported each turn and returns the lifecycle events,
+// ordered by turn, kind, and unit ID. units maps a turn to the IDs of the units in that turn's report.
+//
+// Nothing is reported for the first turn since there is nothing to compare it to.
+// A unit that goes missing and later reappears is disbanded once and is not created again.
+func UnitEvents(units map[TurnNo][]string) []UnitEvent {
+	turns := make([]TurnNo, 0, len(units))
+	for t := range units {
+		turns = append(turns, t)
+	}
+	sort.Slice(turns, func(i, j int) bool { return turns[i] < turns[j] })
+
+	var events []UnitEvent
+	seen, prev := map[string]bool{}, map[string]bool{}
+	for i, t := range turns {
+		cur := map[string]bool{}
+		for _, id := range units[t] {
+			cur[id] = true
+		}
+		if i > 0 {
+			for id := range cur {
+				if !seen[id] {
+					events = append(events, UnitEvent{UnitID: id, TurnNo: t, Kind: UnitCreated})
+				}
+			}
+			for id := range prev {
+				if !cur[id] {
+					events = append(events, UnitEvent{UnitID: id, TurnNo: t, Kind: UnitDisbanded})
+				}
+			}
+		}
+		for id := range cur {
+			seen[id] = true
+		}
+		prev = cur
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.TurnNo != b.TurnNo {
+			return a.TurnNo < b.TurnNo
+		} else if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.UnitID < b.UnitID
+	})
+	return events
+}
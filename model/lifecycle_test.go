@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestUnitEvents(t *testing.T) {
+	t1, t2, t3 := model.NewTurnNo(899, 12), model.NewTurnNo(900, 1), model.NewTurnNo(900, 2)
+	testCases := []struct {
+		name  string
+		units map[model.TurnNo][]string
+		want  []model.UnitEvent
+	}{
+		{
+			name:  "first turn reports nothing",
+			units: map[model.TurnNo][]string{t1: {"0987", "0987e1"}},
+		},
+		{
+			name: "created and disbanded",
+			units: map[model.TurnNo][]string{
+				t1: {"0987", "0987e1"},
+				t2: {"0987", "0987s1", "0987c1"},
+			},
+			want: []model.UnitEvent{
+				{UnitID: "0987c1", TurnNo: t2, Kind: model.UnitCreated},
+				{UnitID: "0987s1", TurnNo: t2, Kind: model.UnitCreated},
+				{UnitID: "0987e1", TurnNo: t2, Kind: model.UnitDisbanded},
+			},
+		},
+		{
+			name: "reappearing unit is not created again",
+			units: map[model.TurnNo][]string{
+				t1: {"0987", "0987e1"},
+				t2: {"0987"},
+				t3: {"0987", "0987e1"},
+			},
+			want: []model.UnitEvent{
+				{UnitID: "0987e1", TurnNo: t2, Kind: model.UnitDisbanded},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := model.UnitEvents(tc.units)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v\nwant %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// LintSeverity is how serious a lint diagnostic is.
+type LintSeverity string
+
+const (
+	LintInfo    LintSeverity = "info"
+	LintWarning LintSeverity = "warning"
+	LintError   LintSeverity = "error"
+)
+
+// ParseLintSeverity returns the severity named by s.
+func ParseLintSeverity(s string) (LintSeverity, error) {
+	switch sev := LintSeverity(s); sev {
+	case LintInfo, LintWarning, LintError:
+		return sev, nil
+	}
+	return "", fmt.Errorf("severity %q: must be info, warning, or error", s)
+}
+
+// LintDiagnostic is a problem a lint rule found in a parsed report. UnitID,
+// ActSeq, StepSeq, and Line are zero when the problem isn't tied to them.
+type LintDiagnostic struct {
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	UnitID   string       `json:"unitId,omitempty"`
+	ActSeq   int          `json:"actSeq,omitempty"`
+	StepSeq  int          `json:"stepSeq,omitempty"`
+	Line     int          `json:"line,omitempty"` // 1-based line in the report text
+	Message  string       `json:"message"`
+}
+
+// String formats the diagnostic for logs and the CLI, e.g.
+// "line 12: warning: scout.max-moves: 0987s1 act 2 advanced 9 times".
+func (d LintDiagnostic) String() string {
+	s := string(d.Severity) + ": " + d.Rule + ": " + d.Message
+	if d.Line > 0 {
+		s = fmt.Sprintf("line %d: %s", d.Line, s)
+	}
+	return s
+}
+
+// LintSetting configures a lint rule for the deployment (GameID "") or for
+// one game. Severity and Limit override the rule's own when set; Limit only
+// means something to rules that have one, like the most advances a scout
+// can make.
+type LintSetting struct {
+	Rule      string       `json:"rule"`
+	GameID    string       `json:"gameId,omitempty"`
+	Enabled   bool         `json:"enabled"`
+	Severity  LintSeverity `json:"severity,omitempty"`
+	Limit     int          `json:"limit,omitempty"`
+	UpdatedBy string       `json:"updatedBy"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// LintConfig is the lint settings in effect for a game, by rule. Rules
+// without a setting run with their defaults.
+type LintConfig map[string]LintSetting
+
+// ResolveLintConfig returns the settings in effect for gameID: the game's,
+// then the deployment's for rules the game doesn't set.
+func ResolveLintConfig(set []LintSetting, gameID string) LintConfig {
+	cfg := LintConfig{}
+	for _, s := range set {
+		if s.GameID == "" {
+			cfg[s.Rule] = s
+		}
+	}
+	if gameID == "" {
+		return cfg
+	}
+	for _, s := range set {
+		if s.GameID == gameID {
+			cfg[s.Rule] = s
+		}
+	}
+	return cfg
+}
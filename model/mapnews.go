@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import "sort"
+
+// MapNews is something a step told a clan about the map that it didn't
+// already know.
+type MapNews string
+
+const (
+	MapNewsHex        MapNews = "hex"        // the first observation of the hex
+	MapNewsTerrain    MapNews = "terrain"    // terrain that differs from what was last seen there
+	MapNewsSettlement MapNews = "settlement" // a settlement not seen in the hex before
+)
+
+// StepNews is what one step that observed a hex told the clan. A step
+// without News only confirmed what the clan already knew.
+type StepNews struct {
+	TurnNo  TurnNo    `json:"turnNo"`
+	UnitID  string    `json:"unitId"`
+	ActSeq  int       `json:"actSeq"`
+	StepSeq int       `json:"stepSeq"`
+	StepID  int64     `json:"stepId"`
+	TN      TNCoord   `json:"tn"`
+	News    []MapNews `json:"news,omitempty"`
+}
+
+// UnitNews counts the news in one unit's steps for a turn.
+type UnitNews struct {
+	TurnNo      TurnNo `json:"turnNo"`
+	UnitID      string `json:"unitId"`
+	Steps       int    `json:"steps"`       // steps that observed a hex
+	New         int    `json:"new"`         // steps with any news
+	Hexes       int    `json:"hexes"`       // steps that observed a hex for the first time
+	Terrain     int    `json:"terrain"`     // steps that saw changed terrain
+	Settlements int    `json:"settlements"` // steps that found a new settlement
+}
+
+// Confirmed is the number of steps that only confirmed what was known.
+func (n UnitNews) Confirmed() int {
+	return n.Steps - n.New
+}
+
+// MapNewsFeed walks a clan's units in turn order (see ResolveSteps) and
+// returns each step that observed a hex, with what it told the clan that
+// earlier steps hadn't. units are the clan's units with their acts, steps,
+// and encounters.
+//
+// The first step to observe a hex is news; the terrain and settlements it
+// saw are then known. A later step is news if it sees terrain that differs
+// from the last terrain seen in the hex, or a settlement that hasn't been
+// seen there. Within a turn, units are taken in the order given, so the
+// first unit to reach a hex gets the credit. Steps in hexes with obscured
+// coordinates are skipped since they can't be placed on the map.
+func MapNewsFeed(units []*UnitX, world WorldRules) []StepNews {
+	units = append([]*UnitX(nil), units...)
+	sort.SliceStable(units, func(i, j int) bool { return units[i].TurnNo < units[j].TurnNo })
+
+	type knowledge struct {
+		terr string
+		sets map[string]bool
+	}
+	known := map[TNCoord]*knowledge{}
+
+	var feed []StepNews
+	for _, u := range units {
+		for _, sa := range ResolveSteps(u, world) {
+			st := sa.Step
+			if st.Terr == "" && !st.Special && st.Enc == nil && len(st.Borders) == 0 {
+				continue // nothing observed
+			}
+			if sa.TN.IsObscured() {
+				continue
+			}
+			sn := StepNews{TurnNo: u.TurnNo, UnitID: u.UnitID, ActSeq: sa.ActSeq, StepSeq: sa.StepSeq, StepID: st.ID, TN: sa.TN}
+
+			k, ok := known[sa.TN]
+			if !ok {
+				k = &knowledge{sets: map[string]bool{}}
+				known[sa.TN] = k
+				sn.News = append(sn.News, MapNewsHex)
+			}
+			if st.Terr != "" && st.Terr != k.terr {
+				if ok {
+					sn.News = append(sn.News, MapNewsTerrain)
+				}
+				k.terr = st.Terr
+			}
+			if st.Enc != nil {
+				newSet := false
+				for _, set := range st.Enc.Sets {
+					if set.Name != "" && !k.sets[set.Name] {
+						k.sets[set.Name], newSet = true, true
+					}
+				}
+				if ok && newSet {
+					sn.News = append(sn.News, MapNewsSettlement)
+				}
+			}
+			feed = append(feed, sn)
+		}
+	}
+	return feed
+}
+
+// SummarizeMapNews counts the news in a feed from MapNewsFeed for each unit
+// and turn, ordered by turn and then unit.
+func SummarizeMapNews(feed []StepNews) []UnitNews {
+	type key struct {
+		turnNo TurnNo
+		unitID string
+	}
+	index := map[key]int{}
+	var summary []UnitNews
+	for _, sn := range feed {
+		k := key{sn.TurnNo, sn.UnitID}
+		i, ok := index[k]
+		if !ok {
+			i = len(summary)
+			index[k] = i
+			summary = append(summary, UnitNews{TurnNo: sn.TurnNo, UnitID: sn.UnitID})
+		}
+		n := &summary[i]
+		n.Steps++
+		if len(sn.News) != 0 {
+			n.New++
+		}
+		for _, news := range sn.News {
+			switch news {
+			case MapNewsHex:
+				n.Hexes++
+			case MapNewsTerrain:
+				n.Terrain++
+			case MapNewsSettlement:
+				n.Settlements++
+			}
+		}
+	}
+	sort.SliceStable(summary, func(i, j int) bool {
+		if summary[i].TurnNo != summary[j].TurnNo {
+			return summary[i].TurnNo < summary[j].TurnNo
+		}
+		return summary[i].UnitID < summary[j].UnitID
+	})
+	return summary
+}
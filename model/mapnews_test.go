@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestMapNewsFeed(t *testing.T) {
+	t1, t2 := model.NewTurnNo(900, 1), model.NewTurnNo(900, 2)
+	status := func(terr string, sets ...string) []*model.Act {
+		st := &model.Step{Seq: 1, Kind: model.StepKindObs, Terr: terr}
+		for _, name := range sets {
+			if st.Enc == nil {
+				st.Enc = &model.Enc{}
+			}
+			st.Enc.Sets = append(st.Enc.Sets, &model.SettleSeen{Name: name})
+		}
+		return []*model.Act{{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{st}}}
+	}
+	units := []*model.UnitX{
+		// listed out of turn order on purpose
+		{UnitID: "0987", TurnNo: t2, StartTN: "QQ 1010", EndTN: "QQ 1010", Acts: status("PR", "Bree", "Hobbiton")},
+		{UnitID: "0987e1", TurnNo: t2, StartTN: "QQ 1009", EndTN: "QQ 1009", Acts: status("RH")},
+		{UnitID: "0987", TurnNo: t1, StartTN: "QQ 1010", EndTN: "QQ 1010", Acts: status("PR", "Bree")},
+		{UnitID: "0987e1", TurnNo: t1, StartTN: "QQ 1010", EndTN: "QQ 1009", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{
+				{Seq: 1, Kind: model.StepKindAdv, Dir: "N", Ok: true, Terr: "GH"},
+				{Seq: 2, Kind: model.StepKindAdv, Dir: "N", FailWhy: "river"}, // observed nothing
+			}},
+			{Seq: 2, Kind: model.ActKindStatus, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindObs, Terr: "GH"}}},
+		}},
+	}
+
+	want := []model.StepNews{
+		{TurnNo: t1, UnitID: "0987", ActSeq: 1, StepSeq: 1, TN: "QQ 1010", News: []model.MapNews{model.MapNewsHex}},
+		{TurnNo: t1, UnitID: "0987e1", ActSeq: 1, StepSeq: 1, TN: "QQ 1009", News: []model.MapNews{model.MapNewsHex}},
+		{TurnNo: t1, UnitID: "0987e1", ActSeq: 2, StepSeq: 1, TN: "QQ 1009"},
+		{TurnNo: t2, UnitID: "0987", ActSeq: 1, StepSeq: 1, TN: "QQ 1010", News: []model.MapNews{model.MapNewsSettlement}},
+		{TurnNo: t2, UnitID: "0987e1", ActSeq: 1, StepSeq: 1, TN: "QQ 1009", News: []model.MapNews{model.MapNewsTerrain}},
+	}
+	got := model.MapNewsFeed(units, model.DefaultWorldRules)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+
+	wantSummary := []model.UnitNews{
+		{TurnNo: t1, UnitID: "0987", Steps: 1, New: 1, Hexes: 1},
+		{TurnNo: t1, UnitID: "0987e1", Steps: 2, New: 1, Hexes: 1},
+		{TurnNo: t2, UnitID: "0987", Steps: 1, New: 1, Settlements: 1},
+		{TurnNo: t2, UnitID: "0987e1", Steps: 1, New: 1, Terrain: 1},
+	}
+	summary := model.SummarizeMapNews(got)
+	if !reflect.DeepEqual(summary, wantSummary) {
+		t.Fatalf("summary: got  %+v\nwant %+v", summary, wantSummary)
+	}
+	if n := summary[1].Confirmed(); n != 1 {
+		t.Errorf("confirmed: got %d, want 1", n)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+// MPCost returns the movement points a land unit spends entering a hex of the
+// terrain, using the pass price if it crosses a pass (see terrain.MPCost).
+// It returns false if the cost isn't known or the hex can't be entered that
+// way, as with mountains that only a pass leads into.
+func MPCost(t terrain.Terrain_e, pass bool) (int, bool) {
+	s := t.MPCost()
+	base, passCost, hasPass := strings.Cut(s, "P")
+	if pass && hasPass {
+		base = passCost
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(base, "W"))
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// NeedsPass returns true if a hex of the terrain can only be entered across a pass.
+func NeedsPass(t terrain.Terrain_e) bool {
+	return strings.HasPrefix(t.MPCost(), "∞")
+}
+
+// MoveBudget is what a unit's move or scout act spent in movement points.
+//
+// Reports don't give a unit's budget, which depends on what it carries, but
+// an act that ran out of points bounds it: the unit had at least what it spent
+// and less than that plus the cost of the hex it couldn't enter.
+type MoveBudget struct {
+	TurnNo    TurnNo     `json:"turnNo"`
+	UnitID    string     `json:"unitId"`
+	ActSeq    int        `json:"actSeq"`
+	ActKind   ActKind    `json:"actKind"`
+	Steps     []MoveCost `json:"steps"`
+	Spent     int        `json:"spent"`               // by the steps with a known cost
+	Known     bool       `json:"known"`               // every step that moved has a known cost
+	Exhausted bool       `json:"exhausted,omitempty"` // the act ended by running out of points
+	MaxLeft   int        `json:"maxLeft,omitempty"`   // if exhausted, the most points that can have been left over
+	MaxBudget int        `json:"maxBudget,omitempty"` // if exhausted and known, the most points the unit can have had
+	Issues    []string   `json:"issues,omitempty"`    // steps that don't add up
+}
+
+// MoveCost is one advance in a MoveBudget.
+type MoveCost struct {
+	StepSeq int    `json:"stepSeq"`
+	Dir     string `json:"dir"`
+	Ok      bool   `json:"ok"`
+	Terr    string `json:"terr,omitempty"` // the hex entered, or for a failed step the one it tried to
+	Pass    bool   `json:"pass,omitempty"` // crossed, or tried to cross, a pass
+	Cost    int    `json:"cost,omitempty"` // 0 if not known
+	Spent   int    `json:"spent"`          // by the act so far
+}
+
+// MoveBudgets works out what each of the unit's move and scout acts spent in
+// movement points (see MoveBudget), skipping acts with no advances. Passes
+// are taken from every border the unit reported, from either side of the
+// edge, so load the steps' borders first.
+//
+// An act is flagged if it moves after running out of points or enters
+// mountains with no pass known.
+func MoveBudgets(u *UnitX, world WorldRules) []MoveBudget {
+	resolved := ResolveSteps(u, world)
+	passes := map[edge]bool{}
+	for _, sa := range resolved {
+		for _, b := range sa.Step.Borders {
+			if d, ok := direction.StringToEnum[b.Dir]; ok && b.Kind == "Pass" {
+				passes[edge{sa.TN, d}] = true
+				if to, err := world.Neighbor(sa.TN, d); err == nil {
+					passes[edge{to, opposite(d)}] = true
+				}
+			}
+		}
+	}
+
+	var budgets []MoveBudget
+	var mb *MoveBudget
+	for _, sa := range resolved {
+		st := sa.Step
+		if (sa.ActKind != ActKindMove && sa.ActKind != ActKindScout) || st.Kind != StepKindAdv {
+			continue
+		}
+		if mb == nil || mb.ActSeq != sa.ActSeq {
+			budgets = append(budgets, MoveBudget{TurnNo: u.TurnNo, UnitID: u.UnitID, ActSeq: sa.ActSeq, ActKind: sa.ActKind, Known: true})
+			mb = &budgets[len(budgets)-1]
+		}
+		d, _ := direction.StringToEnum[st.Dir]
+		mc := MoveCost{StepSeq: sa.StepSeq, Dir: st.Dir, Ok: st.Ok, Pass: passes[edge{sa.From, d}]}
+
+		t := terrain.Blank
+		if st.Ok {
+			t, _ = terrain.StringToTerrain(st.Terr)
+		} else {
+			// a step that failed reports the hex it tried to enter as a border
+			for _, b := range st.Borders {
+				if b.Dir == st.Dir {
+					if bt, ok := terrain.StringToTerrain(b.Kind); ok && bt != terrain.Blank {
+						t = bt
+					}
+				}
+			}
+		}
+		if t != terrain.Blank {
+			mc.Terr = t.String()
+		}
+		cost, costKnown := MPCost(t, mc.Pass)
+		if costKnown {
+			mc.Cost = cost
+		}
+
+		if st.Ok {
+			if mb.Exhausted {
+				mb.Issues = append(mb.Issues, fmt.Sprintf("step %d: moved after running out of movement points", sa.StepSeq))
+			}
+			if NeedsPass(t) && !mc.Pass {
+				mb.Issues = append(mb.Issues, fmt.Sprintf("step %d: entered %s with no pass known", sa.StepSeq, mc.Terr))
+			}
+			if costKnown {
+				mb.Spent += cost
+			} else {
+				mb.Known = false
+			}
+		} else if FailReason(st.FailWhy) == FailExhausted && !mb.Exhausted {
+			mb.Exhausted = true
+			if costKnown {
+				mb.MaxLeft = cost - 1
+				if mb.Known {
+					mb.MaxBudget = mb.Spent + cost - 1
+				}
+			}
+		}
+		mc.Spent = mb.Spent
+		mb.Steps = append(mb.Steps, mc)
+	}
+	return budgets
+}
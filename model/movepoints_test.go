@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+func TestMPCost(t *testing.T) {
+	for _, tc := range []struct {
+		terr terrain.Terrain_e
+		pass bool
+		want int // 0 if it can't be entered
+	}{
+		{terrain.FlatPrairie, false, 3},
+		{terrain.FlatSwamp, false, 8},
+		{terrain.LowMountainsConifer, false, 10},
+		{terrain.LowMountainsConifer, true, 7},
+		{terrain.HighMountainsSnowy, false, 0},
+		{terrain.HighMountainsSnowy, true, 8},
+		{terrain.WaterOcean, false, 0},
+		{terrain.Blank, false, 0},
+	} {
+		got, ok := model.MPCost(tc.terr, tc.pass)
+		if got != tc.want || ok != (tc.want != 0) {
+			t.Errorf("%s pass=%v: got %d %v, want %d", tc.terr, tc.pass, got, ok, tc.want)
+		}
+	}
+}
+
+func TestMoveBudgets(t *testing.T) {
+	adv := func(seq int, dir, terr string, borders ...*model.BorderObs) *model.Step {
+		return &model.Step{Seq: seq, Kind: model.StepKindAdv, Dir: dir, Ok: true, Terr: terr, Borders: borders}
+	}
+	exhausted := func(seq int, dir, terr string) *model.Step {
+		return &model.Step{Seq: seq, Kind: model.StepKindAdv, Dir: dir, FailWhy: string(model.FailExhausted),
+			Borders: []*model.BorderObs{{Dir: dir, Kind: terr}}}
+	}
+	unit := func(acts ...*model.Act) *model.UnitX {
+		return &model.UnitX{UnitID: "0987", TurnNo: 90101, StartTN: "QQ 1010", EndTN: "QQ 1010", Acts: acts}
+	}
+
+	t.Run("ran out of points", func(t *testing.T) {
+		u := unit(&model.Act{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{
+			adv(1, "N", "PR"), adv(2, "N", "GH"), exhausted(3, "N", "SW"),
+		}})
+		budgets := model.MoveBudgets(u, model.DefaultWorldRules)
+		if len(budgets) != 1 {
+			t.Fatalf("got %d budgets, want 1", len(budgets))
+		}
+		mb := budgets[0]
+		if mb.Spent != 8 || !mb.Known || !mb.Exhausted || mb.MaxLeft != 7 || mb.MaxBudget != 15 || mb.Issues != nil {
+			t.Errorf("budget = %+v", mb)
+		}
+		var spent []string
+		for _, mc := range mb.Steps {
+			spent = append(spent, fmt.Sprintf("%s:%d/%d", mc.Terr, mc.Cost, mc.Spent))
+		}
+		if want := "[PR:3/3 GH:5/8 SW:8/8]"; fmt.Sprint(spent) != want {
+			t.Errorf("steps = %v, want %s", spent, want)
+		}
+	})
+
+	t.Run("pass seen from the far side", func(t *testing.T) {
+		u := unit(&model.Act{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{
+			adv(1, "N", "HSM", &model.BorderObs{Dir: "S", Kind: "Pass"}),
+		}})
+		mb := model.MoveBudgets(u, model.DefaultWorldRules)[0]
+		if mb.Spent != 8 || !mb.Steps[0].Pass || mb.Issues != nil {
+			t.Errorf("budget = %+v", mb)
+		}
+	})
+
+	t.Run("inconsistent", func(t *testing.T) {
+		u := unit(
+			&model.Act{Seq: 1, Kind: model.ActKindScout, Steps: []*model.Step{
+				exhausted(1, "N", "SW"), adv(2, "S", "PR"),
+			}},
+			&model.Act{Seq: 2, Kind: model.ActKindScout, Steps: []*model.Step{adv(1, "N", "HSM"), adv(2, "N", "")}},
+		)
+		budgets := model.MoveBudgets(u, model.DefaultWorldRules)
+		if len(budgets) != 2 {
+			t.Fatalf("got %d budgets, want 2", len(budgets))
+		}
+		if want := "[step 2: moved after running out of movement points]"; fmt.Sprint(budgets[0].Issues) != want {
+			t.Errorf("scout 1 issues = %v, want %s", budgets[0].Issues, want)
+		}
+		if want := "[step 1: entered HSM with no pass known]"; fmt.Sprint(budgets[1].Issues) != want {
+			t.Errorf("scout 2 issues = %v, want %s", budgets[1].Issues, want)
+		}
+		if budgets[1].Known || budgets[1].Spent != 0 {
+			t.Errorf("scout 2 = %+v, want an unknown cost", budgets[1])
+		}
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+// HexKnowledge is how much a clan knows about a hex.
+type HexKnowledge string
+
+const (
+	// HexObserved hexes had a unit in them that reported their terrain.
+	HexObserved HexKnowledge = "observed"
+	// HexInferred hexes were only seen from a neighboring hex, like the
+	// "O N" on a status line or a move that failed because of a lake.
+	HexInferred HexKnowledge = "inferred"
+	// HexUnknown hexes haven't been reported at all.
+	HexUnknown HexKnowledge = "unknown"
+)
+
+// HexNeighbor is the hex next to another in one direction and what the clan
+// knows about it. Terrain is blank for unknown hexes.
+type HexNeighbor struct {
+	Dir       direction.Direction_e `json:"dir"`
+	Coord     TNCoord               `json:"coord"`
+	Knowledge HexKnowledge          `json:"knowledge"`
+	Terrain   terrain.Terrain_e     `json:"terrain,omitempty"`
+}
+
+// Knowledge returns how the map came to know the hex's terrain.
+func (m *KnownMap) Knowledge(c TNCoord) HexKnowledge {
+	if _, ok := m.terrain[c]; !ok {
+		return HexUnknown
+	} else if m.inferred[c] {
+		return HexInferred
+	}
+	return HexObserved
+}
+
+// Neighborhood returns the hexes next to c in direction order, each with
+// what the map knows about it. Directions that fall off the edge of the
+// world are left out.
+func (m *KnownMap) Neighborhood(c TNCoord) []HexNeighbor {
+	var hood []HexNeighbor
+	for _, d := range direction.Directions {
+		to, err := m.world.Neighbor(c, d)
+		if err != nil {
+			continue
+		}
+		hood = append(hood, HexNeighbor{Dir: d, Coord: to, Knowledge: m.Knowledge(to), Terrain: m.terrain[to]})
+	}
+	return hood
+}
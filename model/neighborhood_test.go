@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+func TestKnownMap_Neighborhood(t *testing.T) {
+	status := func(tn model.TNCoord, terr string, borders ...*model.BorderObs) *model.UnitX {
+		return &model.UnitX{UnitID: "0987", TurnNo: 90101, StartTN: tn, EndTN: tn, Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindObs, Terr: terr, Borders: borders}}},
+		}}
+	}
+	// the lake to the south is reported before a unit walks into it and finds swamp
+	units := []*model.UnitX{
+		status("QQ 1010", "PR", &model.BorderObs{Dir: "NE", Kind: "O"}, &model.BorderObs{Dir: "S", Kind: "L"}),
+		status("QQ 1009", "PR"),
+		status("QQ 1011", "SW"),
+	}
+	km := model.NewKnownMap(units, model.DefaultWorldRules)
+
+	want := []struct {
+		dir       direction.Direction_e
+		knowledge model.HexKnowledge
+		terrain   terrain.Terrain_e
+	}{
+		{direction.North, model.HexObserved, terrain.FlatPrairie},
+		{direction.NorthEast, model.HexInferred, terrain.WaterOcean},
+		{direction.SouthEast, model.HexUnknown, terrain.Blank},
+		{direction.South, model.HexObserved, terrain.FlatSwamp},
+		{direction.SouthWest, model.HexUnknown, terrain.Blank},
+		{direction.NorthWest, model.HexUnknown, terrain.Blank},
+	}
+	got := km.Neighborhood("QQ 1010")
+	if len(got) != len(want) {
+		t.Fatalf("got %d neighbors, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		n := got[i]
+		if n.Dir != w.dir || n.Knowledge != w.knowledge || n.Terrain != w.terrain {
+			t.Errorf("%d: got %s %s %s, want %s %s %s", i, n.Dir, n.Knowledge, n.Terrain, w.dir, w.knowledge, w.terrain)
+		}
+		if to, _ := model.TNCoord("QQ 1010").Neighbor(w.dir); n.Coord != to {
+			t.Errorf("%s: coord %s, want %s", w.dir, n.Coord, to)
+		}
+	}
+
+	// the corner of the world has only the neighbors on the map
+	if got := km.Neighborhood("AA 0101"); len(got) != 2 {
+		t.Errorf("AA 0101: got %d neighbors, want 2", len(got))
+	}
+}
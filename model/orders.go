@@ -0,0 +1,310 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/direction"
+)
+
+// Order is one movement order a player submitted for a unit.
+//
+// Orders files are plain text, one order per line:
+//
+//	# comments and blank lines are ignored
+//	0987e1 move ne n n
+//	0987e1 scout 1 n-ne-ne
+//	0987c1 follow 0987e1
+//	0987g1 goto QQ 1010
+//
+// Directions are separated by spaces or dashes; "still" orders the unit to
+// stay in its hex for a step.
+type Order struct {
+	UnitID  string   `json:"unitId"`
+	Kind    ActKind  `json:"kind"`              // follow|goto|move|scout
+	ScoutNo int      `json:"scoutNo,omitempty"` // scout, 1-based
+	Dirs    []string `json:"dirs,omitempty"`    // move and scout; N, NE, ..., or "still"
+	Target  string   `json:"target,omitempty"`  // follow
+	DestTN  TNCoord  `json:"destTN,omitempty"`  // goto
+	LineNo  int      `json:"lineNo"`
+}
+
+// OrderStill is the direction token for a step that stays in place.
+const OrderStill = "still"
+
+// ParseOrders reads an orders file. A unit may have at most one move,
+// follow, or goto order and one order for each of its scouts.
+func ParseOrders(data []byte) ([]Order, error) {
+	var orders []Order
+	seen := map[string]int{} // unit and kind/scout to line number
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		o, err := parseOrder(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		o.LineNo = lineNo
+
+		key := o.UnitID // follow, goto, and move are alternatives
+		if o.Kind == ActKindScout {
+			key += " scout " + strconv.Itoa(o.ScoutNo)
+		}
+		if prev, ok := seen[key]; ok {
+			return nil, fmt.Errorf("line %d: %s already has an order on line %d", lineNo, o.UnitID, prev)
+		}
+		seen[key] = lineNo
+		orders = append(orders, o)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func parseOrder(line string) (Order, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Order{}, fmt.Errorf("want a unit and an order, got %q", line)
+	}
+	o := Order{UnitID: fields[0], Kind: ActKind(strings.ToLower(fields[1]))}
+	if UnitKindOf(o.UnitID) == UnitKindUnknown {
+		return Order{}, fmt.Errorf("invalid unit %q", o.UnitID)
+	}
+	args := fields[2:]
+	switch o.Kind {
+	case ActKindMove:
+		dirs, err := parseOrderDirs(args)
+		if err != nil {
+			return Order{}, err
+		}
+		o.Dirs = dirs
+	case ActKindScout:
+		if len(args) == 0 {
+			return Order{}, fmt.Errorf("scout: missing scout number")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > 8 {
+			return Order{}, fmt.Errorf("scout: invalid scout number %q", args[0])
+		}
+		o.ScoutNo = n
+		if o.Dirs, err = parseOrderDirs(args[1:]); err != nil {
+			return Order{}, err
+		}
+	case ActKindFollow:
+		if len(args) != 1 || UnitKindOf(args[0]) == UnitKindUnknown {
+			return Order{}, fmt.Errorf("follow: want a unit to follow")
+		}
+		o.Target = args[0]
+	case ActKindGoto:
+		if len(args) != 2 {
+			return Order{}, fmt.Errorf("goto: want a hex like \"QQ 1010\"")
+		}
+		o.DestTN = TNCoord(strings.ToUpper(args[0]) + " " + args[1])
+		if err := o.DestTN.Validate(); err != nil {
+			return Order{}, fmt.Errorf("goto: %w", err)
+		}
+	default:
+		return Order{}, fmt.Errorf("unknown order %q", fields[1])
+	}
+	return o, nil
+}
+
+func parseOrderDirs(args []string) ([]string, error) {
+	var dirs []string
+	for _, arg := range args {
+		for _, d := range strings.Split(arg, "-") {
+			if d == "" {
+				continue
+			}
+			if strings.EqualFold(d, OrderStill) {
+				dirs = append(dirs, OrderStill)
+				continue
+			}
+			d = strings.ToUpper(d)
+			if dir, ok := direction.StringToEnum[d]; !ok || dir == direction.Unknown {
+				return nil, fmt.Errorf("invalid direction %q", d)
+			}
+			dirs = append(dirs, d)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("missing directions")
+	}
+	return dirs, nil
+}
+
+// OrderProblem says how a unit's results diverge from its orders.
+type OrderProblem string
+
+const (
+	OrderNotExecuted  OrderProblem = "not-executed"  // no act in the report for the order
+	OrderStoppedEarly OrderProblem = "stopped-early" // fewer steps than ordered, and the last one succeeded
+	OrderWrongDir     OrderProblem = "wrong-direction"
+	OrderExtraStep    OrderProblem = "extra-step"   // more steps than ordered
+	OrderWrongTarget  OrderProblem = "wrong-target" // follow or goto to somewhere else
+	OrderNotOrdered   OrderProblem = "not-ordered"  // an act in the report with no order
+)
+
+// OrderDivergence is one place where a unit's results don't match its orders.
+type OrderDivergence struct {
+	UnitID  string       `json:"unitId"`
+	Kind    ActKind      `json:"kind"`
+	ScoutNo int          `json:"scoutNo,omitempty"`
+	Problem OrderProblem `json:"problem"`
+	StepNo  int          `json:"stepNo,omitempty"` // 1-based; zero if the problem isn't with a step
+	Want    string       `json:"want,omitempty"`   // what the order said
+	Got     string       `json:"got,omitempty"`    // what the report said
+	LineNo  int          `json:"lineNo,omitempty"` // the order's line in the orders file
+}
+
+// CheckOrders compares orders with the units' reported acts for the same
+// turn. Only units with at least one order are checked. A move or scout that
+// stops early because a step failed is not a divergence; the failure
+// explains it.
+func CheckOrders(orders []Order, units []*UnitX) []OrderDivergence {
+	byUnit := map[string][]Order{}
+	var unitIDs []string
+	for _, o := range orders {
+		if _, ok := byUnit[o.UnitID]; !ok {
+			unitIDs = append(unitIDs, o.UnitID)
+		}
+		byUnit[o.UnitID] = append(byUnit[o.UnitID], o)
+	}
+	reported := map[string]*UnitX{}
+	for _, u := range units {
+		reported[u.UnitID] = u
+	}
+
+	var divs []OrderDivergence
+	for _, id := range unitIDs {
+		var acts []*Act
+		if u, ok := reported[id]; ok {
+			acts = u.Acts
+		}
+		divs = append(divs, checkUnitOrders(id, byUnit[id], acts)...)
+	}
+	return divs
+}
+
+func checkUnitOrders(unitID string, orders []Order, acts []*Act) []OrderDivergence {
+	// the act each order should have produced; scouts are matched in order
+	used := map[*Act]bool{}
+	var scouts []*Act
+	for _, act := range acts {
+		if act.Kind == ActKindScout {
+			scouts = append(scouts, act)
+		}
+	}
+	find := func(o Order) *Act {
+		if o.Kind == ActKindScout {
+			if o.ScoutNo <= len(scouts) {
+				return scouts[o.ScoutNo-1]
+			}
+			return nil
+		}
+		for _, act := range acts {
+			if act.Kind == o.Kind {
+				return act
+			}
+		}
+		return nil
+	}
+
+	var divs []OrderDivergence
+	for _, o := range orders {
+		div := OrderDivergence{UnitID: unitID, Kind: o.Kind, ScoutNo: o.ScoutNo, LineNo: o.LineNo}
+		act := find(o)
+		if act == nil {
+			div.Problem = OrderNotExecuted
+			div.Want = o.String()
+			divs = append(divs, div)
+			continue
+		}
+		used[act] = true
+		switch o.Kind {
+		case ActKindFollow:
+			if !strings.EqualFold(act.TargetUnitID, o.Target) {
+				div.Problem, div.Want, div.Got = OrderWrongTarget, o.Target, act.TargetUnitID
+				divs = append(divs, div)
+			}
+		case ActKindGoto:
+			if act.DestTN != o.DestTN {
+				div.Problem, div.Want, div.Got = OrderWrongTarget, string(o.DestTN), string(act.DestTN)
+				divs = append(divs, div)
+			}
+		case ActKindMove, ActKindScout:
+			divs = append(divs, checkSteps(div, o.Dirs, act.Steps)...)
+		}
+	}
+
+	for _, act := range acts {
+		if used[act] || act.Kind == ActKindStatus {
+			continue
+		}
+		divs = append(divs, OrderDivergence{UnitID: unitID, Kind: act.Kind, Problem: OrderNotOrdered, Got: string(act.Kind)})
+	}
+	return divs
+}
+
+// checkSteps compares ordered directions with the steps of the act they produced.
+func checkSteps(div OrderDivergence, dirs []string, steps []*Step) []OrderDivergence {
+	var taken []*Step
+	for _, st := range steps {
+		if st.Kind == StepKindAdv || st.Kind == StepKindStill {
+			taken = append(taken, st)
+		}
+	}
+
+	var divs []OrderDivergence
+	for i, want := range dirs {
+		if i >= len(taken) {
+			if len(taken) != 0 && !taken[len(taken)-1].Ok {
+				break // a failed step ends the move
+			}
+			div.Problem, div.StepNo, div.Want, div.Got = OrderStoppedEarly, i+1, want, ""
+			divs = append(divs, div)
+			break
+		}
+		got := taken[i].Dir
+		if taken[i].Kind == StepKindStill {
+			got = OrderStill
+		}
+		if got != want {
+			div.Problem, div.StepNo, div.Want, div.Got = OrderWrongDir, i+1, want, got
+			divs = append(divs, div)
+		}
+	}
+	for i := len(dirs); i < len(taken); i++ {
+		got := taken[i].Dir
+		if taken[i].Kind == StepKindStill {
+			got = OrderStill
+		}
+		div.Problem, div.StepNo, div.Want, div.Got = OrderExtraStep, i+1, "", got
+		divs = append(divs, div)
+	}
+	return divs
+}
+
+// String returns the order as it would be written in an orders file.
+func (o Order) String() string {
+	switch o.Kind {
+	case ActKindMove:
+		return fmt.Sprintf("%s move %s", o.UnitID, strings.Join(o.Dirs, "-"))
+	case ActKindScout:
+		return fmt.Sprintf("%s scout %d %s", o.UnitID, o.ScoutNo, strings.Join(o.Dirs, "-"))
+	case ActKindFollow:
+		return fmt.Sprintf("%s follow %s", o.UnitID, o.Target)
+	case ActKindGoto:
+		return fmt.Sprintf("%s goto %s", o.UnitID, o.DestTN)
+	}
+	return fmt.Sprintf("%s %s", o.UnitID, o.Kind)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestParseOrders(t *testing.T) {
+	orders, err := model.ParseOrders([]byte(`# turn 900-01
+0987e1 move ne n-n
+0987e1 scout 1 N-still-NE
+
+0987c1 Follow 0987e1
+0987g1 goto qq 1010
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.Order{
+		{UnitID: "0987e1", Kind: model.ActKindMove, Dirs: []string{"NE", "N", "N"}, LineNo: 2},
+		{UnitID: "0987e1", Kind: model.ActKindScout, ScoutNo: 1, Dirs: []string{"N", "still", "NE"}, LineNo: 3},
+		{UnitID: "0987c1", Kind: model.ActKindFollow, Target: "0987e1", LineNo: 5},
+		{UnitID: "0987g1", Kind: model.ActKindGoto, DestTN: "QQ 1010", LineNo: 6},
+	}
+	if !reflect.DeepEqual(orders, want) {
+		t.Errorf("ParseOrders() =\n%+v\nwant\n%+v", orders, want)
+	}
+
+	for _, bad := range []string{
+		"0987e1",
+		"0987x1 move n",
+		"0987e1 move up",
+		"0987e1 scout 9 n",
+		"0987e1 sail n",
+		"0987e1 move n\n0987e1 follow 0987",
+	} {
+		if _, err := model.ParseOrders([]byte(bad)); err == nil {
+			t.Errorf("ParseOrders(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestCheckOrders(t *testing.T) {
+	adv := func(dir string, ok bool) *model.Step {
+		return &model.Step{Kind: model.StepKindAdv, Dir: dir, Ok: ok}
+	}
+	units := []*model.UnitX{
+		{UnitID: "0987", Acts: []*model.Act{
+			{Kind: model.ActKindMove, Steps: []*model.Step{adv("N", true), adv("NE", true)}},
+		}},
+		{UnitID: "0987e1", Acts: []*model.Act{
+			{Kind: model.ActKindMove, Steps: []*model.Step{adv("S", true), adv("S", false)}},
+			{Kind: model.ActKindScout, Steps: []*model.Step{adv("N", true)}},
+		}},
+		{UnitID: "0987c1", Acts: []*model.Act{
+			{Kind: model.ActKindStatus},
+		}},
+	}
+	orders, err := model.ParseOrders([]byte(`0987 move n-n-ne
+0987e1 move s-s-s
+0987e1 scout 1 n-n
+0987e1 scout 2 s
+0987c1 follow 0987
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type div struct {
+		unit    string
+		problem model.OrderProblem
+		step    int
+	}
+	var got []div
+	for _, d := range model.CheckOrders(orders, units) {
+		got = append(got, div{d.UnitID, d.Problem, d.StepNo})
+	}
+	want := []div{
+		{"0987", model.OrderWrongDir, 2},
+		{"0987", model.OrderStoppedEarly, 3},
+		// 0987e1's move stopped on a failed step, which is not a divergence
+		{"0987e1", model.OrderStoppedEarly, 2},
+		{"0987e1", model.OrderNotExecuted, 0},
+		{"0987c1", model.OrderNotExecuted, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckOrders() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"slices"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+// KnownMap is what a clan's reports say about terrain and edges, for planning moves.
+type KnownMap struct {
+	terrain  map[TNCoord]terrain.Terrain_e
+	inferred map[TNCoord]bool // terrain only reported from a neighboring hex
+	rivers   map[edge]bool
+	fords    map[edge]bool
+	blocked  map[edge]bool // moves that failed for a reason that won't go away next turn
+	world    WorldRules
+}
+
+// edge is the side of a hex crossed when moving in a direction.
+type edge struct {
+	from TNCoord
+	dir  direction.Direction_e
+}
+
+// NewKnownMap builds a KnownMap by walking the units' steps (see ResolveSteps).
+// Steps must include their borders for rivers and fords to be known.
+// The world rules decide which hexes are neighbors across map and world edges.
+func NewKnownMap(units []*UnitX, world WorldRules) *KnownMap {
+	m := &KnownMap{
+		terrain:  map[TNCoord]terrain.Terrain_e{},
+		inferred: map[TNCoord]bool{},
+		rivers:   map[edge]bool{},
+		fords:    map[edge]bool{},
+		blocked:  map[edge]bool{},
+		world:    world,
+	}
+	for _, u := range units {
+		for _, sa := range ResolveSteps(u, world) {
+			st := sa.Step
+			if st.Kind == StepKindAdv && !st.Ok {
+				// a failed step stays in the hex it tried to leave
+				if d, ok := direction.StringToEnum[st.Dir]; ok {
+					switch FailReason(st.FailWhy) {
+					case FailOcean:
+						m.observeNeighbor(sa.TN, d, terrain.WaterOcean)
+					case FailLake:
+						m.observeNeighbor(sa.TN, d, terrain.WaterLake)
+					case FailRiver, FailCliff:
+						m.blocked[edge{sa.TN, d}] = true
+					}
+				}
+				continue
+			}
+			if t, ok := terrain.StringToTerrain(st.Terr); ok && t != terrain.Blank {
+				m.terrain[sa.TN] = t
+				delete(m.inferred, sa.TN)
+			}
+			for _, b := range st.Borders {
+				d, ok := direction.StringToEnum[b.Dir]
+				if !ok {
+					continue
+				}
+				switch b.Kind {
+				case "River":
+					m.rivers[edge{sa.TN, d}] = true
+				case "Ford":
+					m.fords[edge{sa.TN, d}] = true
+				default:
+					// the neighbor's terrain, e.g. "O" for ocean
+					if t, ok := terrain.StringToTerrain(b.Kind); ok && t != terrain.Blank {
+						m.observeNeighbor(sa.TN, d, t)
+					}
+				}
+			}
+		}
+	}
+	return m
+}
+
+// observeNeighbor records the terrain of the hex next to from, unless a unit
+// has been in that hex and reported it directly.
+func (m *KnownMap) observeNeighbor(from TNCoord, d direction.Direction_e, t terrain.Terrain_e) {
+	to, err := m.world.Neighbor(from, d)
+	if err != nil {
+		return
+	}
+	if _, ok := m.terrain[to]; !ok {
+		m.terrain[to] = t
+		m.inferred[to] = true
+	}
+}
+
+// Terrain returns the known terrain of a hex, or terrain.Blank if it isn't known.
+func (m *KnownMap) Terrain(c TNCoord) terrain.Terrain_e {
+	return m.terrain[c]
+}
+
+// Hexes returns every hex with known terrain, sorted.
+func (m *KnownMap) Hexes() []TNCoord {
+	hexes := make([]TNCoord, 0, len(m.terrain))
+	for c := range m.terrain {
+		hexes = append(hexes, c)
+	}
+	slices.Sort(hexes)
+	return hexes
+}
+
+// Neighbor returns the hex next to c in direction d, following the map's world rules.
+func (m *KnownMap) Neighbor(c TNCoord, d direction.Direction_e) (TNCoord, error) {
+	return m.world.Neighbor(c, d)
+}
+
+// River returns true if a river is known to run along the edge from c in direction d,
+// whether or not it has a ford.
+func (m *KnownMap) River(c TNCoord, d direction.Direction_e) bool {
+	if m.rivers[edge{c, d}] {
+		return true
+	}
+	to, err := m.world.Neighbor(c, d)
+	return err == nil && m.rivers[edge{to, opposite(d)}]
+}
+
+// Passable returns true if the hex's terrain is known and is land.
+func (m *KnownMap) Passable(c TNCoord) bool {
+	t, ok := m.terrain[c]
+	return ok && t.IsAnyLand()
+}
+
+// CanCross returns false if the edge from c in direction d is known to block movement:
+// a river without a ford (seen from either side), or a move across it that already failed.
+func (m *KnownMap) CanCross(c TNCoord, d direction.Direction_e) bool {
+	to, err := m.world.Neighbor(c, d)
+	if err != nil {
+		return false
+	}
+	back := opposite(d)
+	if m.blocked[edge{c, d}] {
+		return false
+	}
+	river := m.rivers[edge{c, d}] || m.rivers[edge{to, back}]
+	ford := m.fords[edge{c, d}] || m.fords[edge{to, back}]
+	return !river || ford
+}
+
+// Paths returns up to limit shortest paths from one hex to another over known
+// passable terrain, as lists of directions. Every hex entered must be known land
+// except the destination, which only has to not be known water.
+// It returns nil if there is no such path, and a single empty path if from is to.
+func (m *KnownMap) Paths(from, to TNCoord, limit int) [][]direction.Direction_e {
+	if from == to {
+		return [][]direction.Direction_e{{}}
+	}
+	if t, ok := m.terrain[to]; ok && !t.IsAnyLand() {
+		return nil
+	}
+
+	// breadth-first search, remembering every way of reaching a hex at its shortest distance
+	type step struct {
+		prev TNCoord
+		dir  direction.Direction_e
+	}
+	dist := map[TNCoord]int{from: 0}
+	prevs := map[TNCoord][]step{}
+	queue := []TNCoord{from}
+	for len(queue) != 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		if curr == to {
+			break // every shorter hex has been expanded
+		}
+		for _, d := range direction.Directions {
+			next, err := m.world.Neighbor(curr, d)
+			if err != nil || (next != to && !m.Passable(next)) || !m.CanCross(curr, d) {
+				continue
+			}
+			if n, ok := dist[next]; !ok {
+				dist[next] = dist[curr] + 1
+				prevs[next] = []step{{curr, d}}
+				queue = append(queue, next)
+			} else if n == dist[curr]+1 {
+				prevs[next] = append(prevs[next], step{curr, d})
+			}
+		}
+	}
+	if _, ok := dist[to]; !ok {
+		return nil
+	}
+
+	// walk back from the destination to enumerate the shortest paths
+	var paths [][]direction.Direction_e
+	var walk func(c TNCoord, suffix []direction.Direction_e)
+	walk = func(c TNCoord, suffix []direction.Direction_e) {
+		if len(paths) >= limit {
+			return
+		} else if c == from {
+			path := make([]direction.Direction_e, len(suffix))
+			for i, d := range suffix {
+				path[len(suffix)-1-i] = d
+			}
+			paths = append(paths, path)
+			return
+		}
+		for _, p := range prevs[c] {
+			walk(p.prev, append(suffix, p.dir))
+		}
+	}
+	walk(to, nil)
+	return paths
+}
+
+// opposite returns the direction pointing back the way d came.
+func opposite(d direction.Direction_e) direction.Direction_e {
+	for i, dd := range direction.Directions {
+		if dd == d {
+			return direction.Directions[(i+3)%len(direction.Directions)]
+		}
+	}
+	return direction.Unknown
+}
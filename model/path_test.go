@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestKnownMap_Paths(t *testing.T) {
+	// status reports prairie in every hex of QQ 0807..1212
+	status := func(tn model.TNCoord, terr string, borders ...*model.BorderObs) *model.UnitX {
+		return &model.UnitX{UnitID: "0987", TurnNo: 90101, StartTN: tn, EndTN: tn, Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindObs, Terr: terr, Borders: borders}}},
+		}}
+	}
+	prairie := func(extra ...*model.UnitX) []*model.UnitX {
+		var units []*model.UnitX
+		for col := 8; col <= 12; col++ {
+			for row := 7; row <= 12; row++ {
+				units = append(units, status(model.NewTNCoord("QQ", col, row), "PR"))
+			}
+		}
+		return append(units, extra...)
+	}
+	walk := func(from model.TNCoord, path []direction.Direction_e) model.TNCoord {
+		for _, d := range path {
+			var err error
+			if from, err = from.Neighbor(d); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return from
+	}
+
+	tests := []struct {
+		name      string
+		units     []*model.UnitX
+		from, to  model.TNCoord
+		wantLen   int // -1 for no path
+		notFirstN bool
+	}{
+		{name: "open prairie", units: prairie(), from: "QQ 1010", to: "QQ 1008", wantLen: 2},
+		{name: "river blocks", units: prairie(status("QQ 1010", "PR", &model.BorderObs{Dir: "N", Kind: "River"})), from: "QQ 1010", to: "QQ 1008", wantLen: 3, notFirstN: true},
+		{name: "river seen from the other side", units: prairie(status("QQ 1009", "PR", &model.BorderObs{Dir: "S", Kind: "River"})), from: "QQ 1010", to: "QQ 1008", wantLen: 3, notFirstN: true},
+		{name: "ford crosses river", units: prairie(status("QQ 1010", "PR", &model.BorderObs{Dir: "N", Kind: "River"}, &model.BorderObs{Dir: "N", Kind: "Ford"})), from: "QQ 1010", to: "QQ 1008", wantLen: 2},
+		{name: "ocean target", units: prairie(status("QQ 1007", "PR", &model.BorderObs{Dir: "N", Kind: "O"})), from: "QQ 1010", to: "QQ 1006", wantLen: -1},
+		{name: "unknown terrain", units: prairie(), from: "QQ 1010", to: "QQ 1005", wantLen: -1},
+		{name: "unknown destination next to known land", units: prairie(), from: "QQ 1010", to: "QQ 1006", wantLen: 4},
+		{name: "same hex", units: prairie(), from: "QQ 1010", to: "QQ 1010", wantLen: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := model.NewKnownMap(tt.units, model.DefaultWorldRules).Paths(tt.from, tt.to, 10)
+			if tt.wantLen < 0 {
+				if paths != nil {
+					t.Fatalf("expected no path, got %v", paths)
+				}
+				return
+			}
+			if len(paths) == 0 {
+				t.Fatalf("expected a path")
+			}
+			for _, path := range paths {
+				if len(path) != tt.wantLen {
+					t.Errorf("%v: expected %d steps, got %d", path, tt.wantLen, len(path))
+				}
+				if got := walk(tt.from, path); got != tt.to {
+					t.Errorf("%v: ends at %q", path, got)
+				}
+				if tt.notFirstN && path[0] == direction.North {
+					t.Errorf("%v: crosses the river", path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+// PublicRedaction is what a clan leaves out of the turn summaries it posts
+// publicly. Coordinates and unit IDs are never included; the zero value
+// shows everything else.
+type PublicRedaction struct {
+	HideTerrain     bool `json:"hideTerrain,omitempty"`     // hexes are drawn without their terrain
+	HideRivers      bool `json:"hideRivers,omitempty"`      // rivers along hex edges
+	HideSettlements bool `json:"hideSettlements,omitempty"` // the kinds of settlements seen
+	HideResources   bool `json:"hideResources,omitempty"`   // the kinds of resources seen
+	HideEncounters  bool `json:"hideEncounters,omitempty"`  // how many other units were seen
+	HideUnits       bool `json:"hideUnits,omitempty"`       // where the clan's own units ended the turn
+}
+
+// PublicSummary is a clan's known map as of a turn, redacted for sharing.
+// Hexes are placed relative to the upper-left corner of the known map, so
+// the summary shows the shape of what the clan has explored without saying
+// where it is. Hexes first seen in the turn are marked New, so a series of
+// summaries reveals the map turn by turn.
+type PublicSummary struct {
+	TurnNo TurnNo       `json:"turnNo"`
+	Hexes  []PublicHex  `json:"hexes"`
+	Units  []PublicUnit `json:"units,omitempty"`
+}
+
+// PublicHex is a hex in a PublicSummary. X is the column and Y the row,
+// counted from the summary's origin. The origin is always on an odd
+// TribeNet column, so odd X values are the columns shoved down half a hex.
+type PublicHex struct {
+	X           int                     `json:"x"`
+	Y           int                     `json:"y"`
+	Terrain     terrain.Terrain_e       `json:"terrain,omitempty"`
+	New         bool                    `json:"new,omitempty"`
+	Rivers      []direction.Direction_e `json:"rivers,omitempty"`
+	Settlements []string                `json:"settlements,omitempty"` // kinds, e.g. "city"
+	Resources   []string                `json:"resources,omitempty"`   // kinds, e.g. "ore"
+	Encounters  int                     `json:"encounters,omitempty"`  // other units seen in the turn
+}
+
+// PublicUnit is one of the clan's units in a PublicSummary, numbered instead
+// of named.
+type PublicUnit struct {
+	Label string   `json:"label"` // e.g. "Unit 3"
+	Kind  UnitKind `json:"kind"`
+	X     int      `json:"x"`
+	Y     int      `json:"y"`
+	Moves int      `json:"moves"` // hexes moved in the turn
+}
+
+// NewPublicSummary builds a redacted summary of the clan's map as of turnNo
+// from its units in every turn up to and including turnNo (see NewKnownMap).
+// Encounters and the clan's units come from turnNo alone. The rules give each
+// unit's kind.
+func NewPublicSummary(units []*UnitX, rules UnitIDRules, world WorldRules, turnNo TurnNo, r PublicRedaction) *PublicSummary {
+	var before, current []*UnitX
+	for _, u := range units {
+		if u.TurnNo < turnNo {
+			before = append(before, u)
+		} else if u.TurnNo == turnNo {
+			current = append(current, u)
+		}
+	}
+	km := NewKnownMap(append(slices.Clone(before), current...), world)
+	known := NewKnownMap(before, world)
+
+	type place struct{ col, row int }
+	places := map[TNCoord]place{}
+	originCol, originRow := 0, 0
+	for _, tn := range km.Hexes() {
+		col, row, ok := globalColRow(tn)
+		if !ok {
+			continue
+		}
+		if len(places) == 0 || col < originCol {
+			originCol = col
+		}
+		if len(places) == 0 || row < originRow {
+			originRow = row
+		}
+		places[tn] = place{col, row}
+	}
+	// keep the origin on an even global column so relative columns keep their parity
+	originCol -= originCol % 2
+
+	ps := &PublicSummary{TurnNo: turnNo, Hexes: []PublicHex{}}
+	index := map[TNCoord]int{}
+	for _, tn := range km.Hexes() {
+		p, ok := places[tn]
+		if !ok {
+			continue
+		}
+		h := PublicHex{X: p.col - originCol, Y: p.row - originRow, New: known.Terrain(tn) == terrain.Blank}
+		if !r.HideTerrain {
+			h.Terrain = km.Terrain(tn)
+		}
+		if !r.HideRivers {
+			for _, d := range direction.Directions {
+				if km.River(tn, d) {
+					h.Rivers = append(h.Rivers, d)
+				}
+			}
+		}
+		index[tn] = len(ps.Hexes)
+		ps.Hexes = append(ps.Hexes, h)
+	}
+
+	for _, u := range current {
+		for _, sa := range ResolveSteps(u, world) {
+			i, ok := index[sa.TN]
+			if !ok || sa.Step.Enc == nil {
+				continue
+			}
+			h := &ps.Hexes[i]
+			if !r.HideSettlements {
+				for _, s := range sa.Step.Enc.Sets {
+					h.Settlements = appendUnique(h.Settlements, cmp.Or(s.Kind, "settlement"))
+				}
+			}
+			if !r.HideResources {
+				for _, rs := range sa.Step.Enc.Rsrc {
+					h.Resources = appendUnique(h.Resources, rs.Kind)
+				}
+			}
+			if !r.HideEncounters {
+				h.Encounters += len(sa.Step.Enc.Units)
+			}
+		}
+	}
+
+	if !r.HideUnits {
+		// number the units in an order that doesn't follow their IDs
+		slices.SortStableFunc(current, func(a, b *UnitX) int {
+			return cmp.Or(cmp.Compare(a.EndTN, b.EndTN), cmp.Compare(rules.Kind(a.UnitID), rules.Kind(b.UnitID)))
+		})
+		for _, u := range current {
+			p, ok := places[u.EndTN]
+			if !ok {
+				continue
+			}
+			moves := 0
+			for _, sa := range ResolveSteps(u, world) {
+				if sa.ActKind == ActKindMove && sa.TN != sa.From {
+					moves++
+				}
+			}
+			ps.Units = append(ps.Units, PublicUnit{
+				Label: fmt.Sprintf("Unit %d", len(ps.Units)+1),
+				Kind:  rules.Kind(u.UnitID),
+				X:     p.col - originCol,
+				Y:     p.row - originRow,
+				Moves: moves,
+			})
+		}
+	}
+	return ps
+}
+
+// globalColRow returns the column and row of a hex on the world map, counting
+// from 0 at the upper-left corner of grid "AA". Grids are 30 columns wide and
+// 21 rows tall.
+func globalColRow(tn TNCoord) (col, row int, ok bool) {
+	grid, c, r, err := tn.Parse()
+	if err != nil || len(grid) != 2 || grid[0] < 'A' || grid[0] > 'Z' || grid[1] < 'A' || grid[1] > 'Z' {
+		return 0, 0, false
+	}
+	return int(grid[1]-'A')*30 + c - 1, int(grid[0]-'A')*21 + r - 1, true
+}
+
+func appendUnique(list []string, s string) []string {
+	if s == "" || slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+func TestNewPublicSummary(t *testing.T) {
+	units := []*model.UnitX{
+		{UnitID: "0987", TurnNo: 90101, StartTN: "QQ 1010", EndTN: "QQ 1010", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindObs, Terr: "PR"}}},
+		}},
+		{UnitID: "0987", TurnNo: 90102, StartTN: "QQ 1010", EndTN: "QQ 1009", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindAdv, Ok: true, Dir: "N", Terr: "GH", Enc: &model.Enc{
+				Units: []*model.UnitSeen{{UnitID: "1234"}},
+				Sets:  []*model.SettleSeen{{Name: "Avalon", Kind: "city"}},
+			}}}},
+		}},
+	}
+
+	ps := model.NewPublicSummary(units, model.DefaultUnitIDRules, model.DefaultWorldRules, 90102, model.PublicRedaction{})
+	if len(ps.Hexes) != 2 {
+		t.Fatalf("expected 2 hexes, got %+v", ps.Hexes)
+	}
+	// QQ 1009 sorts first; the origin is the column before QQ 10 and row 08
+	got, old := ps.Hexes[0], ps.Hexes[1]
+	if got.X != 1 || got.Y != 0 || got.Terrain != terrain.HillsGrassy || !got.New {
+		t.Errorf("new hex: got %+v", got)
+	}
+	if got.Encounters != 1 || len(got.Settlements) != 1 || got.Settlements[0] != "city" {
+		t.Errorf("new hex encounters: got %+v", got)
+	}
+	if old.X != 1 || old.Y != 1 || old.Terrain != terrain.FlatPrairie || old.New {
+		t.Errorf("old hex: got %+v", old)
+	}
+	if len(ps.Units) != 1 {
+		t.Fatalf("expected 1 unit, got %+v", ps.Units)
+	}
+	if u := ps.Units[0]; u.Label != "Unit 1" || u.Kind != model.UnitKindTribe || u.X != 1 || u.Y != 0 || u.Moves != 1 {
+		t.Errorf("unit: got %+v", u)
+	}
+
+	data, err := json.Marshal(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"QQ", "0987", "1234", "Avalon"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("summary gives away %q: %s", secret, data)
+		}
+	}
+
+	ps = model.NewPublicSummary(units, model.DefaultUnitIDRules, model.DefaultWorldRules, 90102, model.PublicRedaction{HideTerrain: true, HideSettlements: true, HideUnits: true})
+	if h := ps.Hexes[0]; h.Terrain != terrain.Blank || h.Settlements != nil || h.Encounters != 1 {
+		t.Errorf("redacted hex: got %+v", h)
+	}
+	if ps.Units != nil {
+		t.Errorf("redacted units: got %+v", ps.Units)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import "time"
+
+// RemovalStatus is where a removal request stands.
+type RemovalStatus string
+
+const (
+	RemovalOpen     RemovalStatus = "open"     // waiting for a GM
+	RemovalApproved RemovalStatus = "approved" // the report file was purged
+	RemovalRejected RemovalStatus = "rejected" // the report file was kept
+)
+
+// RemovalRequest is a player's request that a GM remove a report file from
+// their clan, usually another clan's report that was uploaded as theirs.
+// The file's game, clan, turn, and name are copied into the request so that
+// it still reads once the file is gone.
+type RemovalRequest struct {
+	ID           int64         `json:"id"`
+	ReportFileID int64         `json:"reportFileId,omitempty"` // 0 once the file is purged
+	Game         string        `json:"game"`
+	ClanNo       string        `json:"clanNo"`
+	TurnNo       TurnNo        `json:"turnNo"`
+	FileName     string        `json:"fileName"`
+	RequestedBy  string        `json:"requestedBy"`
+	Reason       string        `json:"reason,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	Status       RemovalStatus `json:"status"`
+	DecidedBy    string        `json:"decidedBy,omitempty"`
+	DecidedAt    *time.Time    `json:"decidedAt,omitempty"`
+	Note         string        `json:"note,omitempty"` // the GM's reply
+}
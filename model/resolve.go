@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/direction"
+)
+
+// StepAt is a step with the coordinate of the hex the unit (or scout) was in after taking it.
+type StepAt struct {
+	ActSeq  int     `json:"actSeq"`
+	ActKind ActKind `json:"actKind"`
+	StepSeq int     `json:"stepSeq"`
+	Step    *Step   `json:"step"`
+	From    TNCoord `json:"from"` // the hex the step was taken from; the same as TN unless the step moved
+	TN      TNCoord `json:"tn"`
+}
+
+// ResolveSteps walks a unit's acts from its starting coordinate and returns each step
+// with the hex it ended in.
+//
+// Successful adv steps move to the neighboring hex; every other step stays put.
+// Scout acts start from the unit's current hex but don't move the unit.
+// Follow and goto acts have no steps and just relocate the unit.
+// Steps taken from an unknown or invalid location are dropped since they can't be placed.
+// The world rules decide where a step off the edge of a map or the world goes.
+func ResolveSteps(u *UnitX, world WorldRules) []StepAt {
+	var resolved []StepAt
+	curr := u.StartTN
+	for _, act := range u.Acts {
+		at := curr
+		if act.Kind == ActKindStatus {
+			at = u.EndTN // status reports the hex the unit ended the turn in
+		}
+		for _, st := range act.Steps {
+			from := at
+			if st.Kind == StepKindAdv && st.Ok {
+				if d, ok := direction.StringToEnum[st.Dir]; !ok {
+					at = ""
+				} else if next, err := world.Neighbor(at, d); err != nil {
+					at = ""
+				} else {
+					at = next
+				}
+			}
+			if !at.IsUnknown() && at.Valid() {
+				resolved = append(resolved, StepAt{ActSeq: act.Seq, ActKind: act.Kind, StepSeq: st.Seq, Step: st, From: from, TN: at})
+			}
+		}
+		switch act.Kind {
+		case ActKindMove, ActKindStatus:
+			curr = at
+		case ActKindFollow:
+			curr = u.EndTN
+		case ActKindGoto:
+			curr = act.DestTN
+		}
+	}
+	return resolved
+}
+
+// StepRecord is one resolved step flattened to a single record, for tools that
+// want a long-format table of where units went and what they saw rather than
+// the nested report structure.
+type StepRecord struct {
+	TurnNo  TurnNo   `json:"turnNo"`
+	UnitID  string   `json:"unitId"`
+	ActSeq  int      `json:"actSeq"`
+	ActKind ActKind  `json:"actKind"`
+	StepSeq int      `json:"stepSeq"`
+	Kind    StepKind `json:"kind"`
+	Dir     string   `json:"dir,omitempty"`
+	Ok      bool     `json:"ok"`
+	FailWhy string   `json:"failWhy,omitempty"`
+	From    TNCoord  `json:"from"`
+	To      TNCoord  `json:"to"`
+	Terr    string   `json:"terr,omitempty"`
+	Label   string   `json:"label,omitempty"` // if the hex is special
+
+	Units   []*UnitSeen   `json:"units,omitempty"`
+	Sets    []*SettleSeen `json:"sets,omitempty"`
+	Rsrc    []*RsrcSeen   `json:"rsrc,omitempty"`
+	Borders []*BorderObs  `json:"borders,omitempty"`
+}
+
+// StepRecords resolves a unit's steps (see ResolveSteps) and flattens each to a
+// StepRecord. Encounters and borders are copied from the steps, so load them
+// first. Steps that can't be placed are left out.
+func StepRecords(u *UnitX, world WorldRules) []StepRecord {
+	var records []StepRecord
+	for _, sa := range ResolveSteps(u, world) {
+		st := sa.Step
+		rec := StepRecord{
+			TurnNo:  u.TurnNo,
+			UnitID:  u.UnitID,
+			ActSeq:  sa.ActSeq,
+			ActKind: sa.ActKind,
+			StepSeq: sa.StepSeq,
+			Kind:    st.Kind,
+			Dir:     st.Dir,
+			Ok:      st.Ok,
+			FailWhy: st.FailWhy,
+			From:    sa.From,
+			To:      sa.TN,
+			Terr:    st.Terr,
+			Borders: st.Borders,
+		}
+		if st.Special {
+			rec.Label = st.Label
+		}
+		if st.Enc != nil {
+			rec.Units, rec.Sets, rec.Rsrc = st.Enc.Units, st.Enc.Sets, st.Enc.Rsrc
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+// StepRecordCSVHeader names the columns of StepRecord.CSV.
+var StepRecordCSVHeader = []string{
+	"turn", "unit", "act_seq", "act_kind", "step_seq", "kind", "dir", "ok", "fail_why",
+	"from", "to", "terrain", "label", "units", "settlements", "resources", "borders",
+}
+
+// CSV returns the record as a CSV row with the columns in StepRecordCSVHeader.
+// Encounters and borders are space-separated lists: unit IDs, settlement
+// names, resource kinds, and direction:kind pairs.
+func (r StepRecord) CSV() []string {
+	var units, sets, rsrc, borders []string
+	for _, u := range r.Units {
+		units = append(units, u.UnitID)
+	}
+	for _, set := range r.Sets {
+		sets = append(sets, set.Name)
+	}
+	for _, rs := range r.Rsrc {
+		rsrc = append(rsrc, rs.Kind)
+	}
+	for _, b := range r.Borders {
+		borders = append(borders, b.Dir+":"+b.Kind)
+	}
+	return []string{
+		r.TurnNo.String(), r.UnitID, strconv.Itoa(r.ActSeq), string(r.ActKind), strconv.Itoa(r.StepSeq),
+		string(r.Kind), r.Dir, strconv.FormatBool(r.Ok), r.FailWhy,
+		string(r.From), string(r.To), r.Terr, r.Label,
+		strings.Join(units, " "), strings.Join(sets, " "), strings.Join(rsrc, " "), strings.Join(borders, " "),
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestResolveSteps(t *testing.T) {
+	adv := func(seq int, dir string, ok bool) *model.Step {
+		return &model.Step{Seq: seq, Kind: model.StepKindAdv, Dir: dir, Ok: ok}
+	}
+	obs := func(seq int) *model.Step {
+		return &model.Step{Seq: seq, Kind: model.StepKindObs}
+	}
+	tests := []struct {
+		name string
+		unit *model.UnitX
+		want []model.TNCoord
+	}{
+		{
+			name: "move then status",
+			unit: &model.UnitX{StartTN: "QQ 1010", EndTN: "QQ 1008", Acts: []*model.Act{
+				{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{adv(1, "N", true), adv(2, "N", true), adv(3, "N", false)}},
+				{Seq: 2, Kind: model.ActKindStatus, Steps: []*model.Step{obs(1)}},
+			}},
+			want: []model.TNCoord{"QQ 1009", "QQ 1008", "QQ 1008", "QQ 1008"},
+		},
+		{
+			name: "scouts start from the unit's hex",
+			unit: &model.UnitX{StartTN: "QQ 1010", EndTN: "QQ 1010", Acts: []*model.Act{
+				{Seq: 1, Kind: model.ActKindScout, Steps: []*model.Step{adv(1, "S", true)}},
+				{Seq: 2, Kind: model.ActKindScout, Steps: []*model.Step{adv(1, "N", true), obs(2)}},
+			}},
+			want: []model.TNCoord{"QQ 1011", "QQ 1009", "QQ 1009"},
+		},
+		{
+			name: "goto relocates the unit",
+			unit: &model.UnitX{StartTN: "QQ 1010", EndTN: "QQ 0505", Acts: []*model.Act{
+				{Seq: 1, Kind: model.ActKindGoto, DestTN: "QQ 0505"},
+				{Seq: 2, Kind: model.ActKindScout, Steps: []*model.Step{adv(1, "S", true)}},
+			}},
+			want: []model.TNCoord{"QQ 0506"},
+		},
+		{
+			name: "unknown start drops moves but keeps status",
+			unit: &model.UnitX{StartTN: "N/A", EndTN: "QQ 0505", Acts: []*model.Act{
+				{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{adv(1, "N", true)}},
+				{Seq: 2, Kind: model.ActKindStatus, Steps: []*model.Step{obs(1)}},
+			}},
+			want: []model.TNCoord{"QQ 0505"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := model.ResolveSteps(tt.unit, model.DefaultWorldRules)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d steps, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i, sa := range got {
+				if sa.TN != tt.want[i] {
+					t.Errorf("step %d (act %d step %d): expected %q, got %q", i, sa.ActSeq, sa.StepSeq, tt.want[i], sa.TN)
+				}
+			}
+		})
+	}
+}
+
+func TestStepRecords(t *testing.T) {
+	unit := &model.UnitX{UnitID: "0987e1", TurnNo: 90001, StartTN: "QQ 1010", EndTN: "QQ 1009", Acts: []*model.Act{
+		{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{
+			{Seq: 1, Kind: model.StepKindAdv, Dir: "N", Ok: true, Terr: "PR",
+				Enc: &model.Enc{Units: []*model.UnitSeen{{UnitID: "0512"}}}},
+			{Seq: 2, Kind: model.StepKindAdv, Dir: "N", FailWhy: "river",
+				Borders: []*model.BorderObs{{Dir: "N", Kind: "river"}}},
+		}},
+	}}
+	got := model.StepRecords(unit, model.DefaultWorldRules)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 records, got %d: %+v", len(got), got)
+	}
+	if got[0].From != "QQ 1010" || got[0].To != "QQ 1009" || got[0].Terr != "PR" || len(got[0].Units) != 1 {
+		t.Errorf("first step: got %+v", got[0])
+	}
+	if got[1].From != "QQ 1009" || got[1].To != "QQ 1009" || got[1].Ok || len(got[1].Borders) != 1 {
+		t.Errorf("failed step should stay put with its border: got %+v", got[1])
+	}
+	if got[1].TurnNo != 90001 || got[1].UnitID != "0987e1" || got[1].ActSeq != 1 || got[1].StepSeq != 2 {
+		t.Errorf("second step: wrong keys: %+v", got[1])
+	}
+
+	row := got[1].CSV()
+	if len(row) != len(model.StepRecordCSVHeader) {
+		t.Fatalf("csv: expected %d columns, got %d", len(model.StepRecordCSVHeader), len(row))
+	}
+	if row[0] != "0900-01" || row[7] != "false" || row[8] != "river" || row[16] != "N:river" {
+		t.Errorf("csv: got %q", row)
+	}
+}
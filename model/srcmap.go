@@ -0,0 +1,268 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+)
+
+// Position is a place in a report's text. Offset is the 0-based byte offset;
+// Line and Col are 1-based, and Col counts bytes, not runes.
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Col    int `json:"col"`
+}
+
+// SourceKind names what a SourceEntity covers.
+type SourceKind string
+
+const (
+	SourceUnit SourceKind = "unit" // a unit section, from its header to the next section
+	SourceAct  SourceKind = "act"  // the lines an act was read from
+	SourceStep SourceKind = "step" // one backslash-separated step on a movement line
+)
+
+// SourceEntity is a parsed record and the text it was read from. Start is
+// inclusive and End exclusive. ActSeq and StepSeq are zero for records above
+// them (see SrcRef). A move act's steps may be on lines that aren't next to
+// each other (the movement and status lines); Start and End span all of
+// them, but only the act's own lines count as part of it.
+type SourceEntity struct {
+	Kind    SourceKind `json:"kind"`
+	UnitID  string     `json:"unitId"`
+	ActSeq  int        `json:"actSeq,omitempty"`
+	StepSeq int        `json:"stepSeq,omitempty"`
+	Start   Position   `json:"start"`
+	End     Position   `json:"end"`
+	Text    string     `json:"text"`
+
+	Unit *UnitX `json:"-"`
+	Act  *Act   `json:"-"`
+	Step *Step  `json:"-"`
+
+	ranges [][2]int // the byte ranges that are part of the record
+}
+
+// SourceMap maps places in a report's text to the units, acts, and steps
+// parsed from it, and back. It is built from the Line and LineStep of each
+// record's SrcRef, so records without them are left out. The text must be
+// the one given to the parser, after line endings were normalized, since
+// line numbers count "\n".
+type SourceMap struct {
+	text       []byte
+	lineStarts []int // offset of the first byte of each line; lineStarts[0] is line 1
+	entities   []SourceEntity
+}
+
+// NewSourceMap indexes the units' records against the report text.
+func NewSourceMap(text []byte, units []*UnitX) *SourceMap {
+	m := &SourceMap{text: text, lineStarts: []int{0}}
+	for i, ch := range text {
+		if ch == '\n' {
+			m.lineStarts = append(m.lineStarts, i+1)
+		}
+	}
+
+	// a unit's section runs until the next unit's header
+	var headers []int
+	for _, u := range units {
+		if u.Src != nil && u.Src.Line > 0 {
+			headers = append(headers, u.Src.Line)
+		}
+	}
+	sort.Ints(headers)
+
+	for _, u := range units {
+		if u.Src == nil || u.Src.Line <= 0 || u.Src.Line > len(m.lineStarts) {
+			continue
+		}
+		start, end := m.lineStarts[u.Src.Line-1], len(text)
+		if i := sort.SearchInts(headers, u.Src.Line+1); i < len(headers) && headers[i] <= len(m.lineStarts) {
+			end = m.lineStarts[headers[i]-1]
+		}
+		m.add(SourceEntity{Kind: SourceUnit, UnitID: u.UnitID, Unit: u}, [2]int{start, m.trimRight(start, end)})
+
+		for _, a := range u.Acts {
+			var lines [][2]int
+			addLine := func(line int) {
+				if start, end := m.lineSpan(line); start >= 0 && !slices.Contains(lines, [2]int{start, end}) {
+					lines = append(lines, [2]int{start, end})
+				}
+			}
+			if a.Src != nil {
+				addLine(a.Src.Line)
+			}
+			for _, s := range a.Steps {
+				if s.Src == nil {
+					continue
+				}
+				if start, end := m.stepSpan(s.Src.Line, s.Src.LineStep); start >= 0 {
+					m.add(SourceEntity{Kind: SourceStep, UnitID: u.UnitID, ActSeq: a.Seq, StepSeq: s.Seq, Unit: u, Act: a, Step: s}, [2]int{start, end})
+					addLine(s.Src.Line)
+				}
+			}
+			if lines != nil {
+				m.add(SourceEntity{Kind: SourceAct, UnitID: u.UnitID, ActSeq: a.Seq, Unit: u, Act: a}, lines...)
+			}
+		}
+	}
+
+	sort.SliceStable(m.entities, func(i, j int) bool {
+		return m.entities[i].Start.Offset < m.entities[j].Start.Offset
+	})
+	return m
+}
+
+// Entities returns every indexed record, ordered by where it starts.
+func (m *SourceMap) Entities() []SourceEntity {
+	return m.entities
+}
+
+// Position returns the line and column of a byte offset. Offsets past the
+// end of the text are not valid.
+func (m *SourceMap) Position(offset int) (Position, bool) {
+	if offset < 0 || offset > len(m.text) {
+		return Position{}, false
+	}
+	line := sort.SearchInts(m.lineStarts, offset+1) // first line starting after offset
+	return Position{Offset: offset, Line: line, Col: offset - m.lineStarts[line-1] + 1}, true
+}
+
+// PositionAt returns the byte offset of a line and column. The column may
+// point just past the end of the line, but not into the next one.
+func (m *SourceMap) PositionAt(line, col int) (Position, bool) {
+	if line < 1 || line > len(m.lineStarts) || col < 1 {
+		return Position{}, false
+	}
+	start, end := m.lineStarts[line-1], len(m.text)
+	if line < len(m.lineStarts) {
+		end = m.lineStarts[line] - 1
+	}
+	if start+col-1 > end {
+		return Position{}, false
+	}
+	return Position{Offset: start + col - 1, Line: line, Col: col}, true
+}
+
+// At returns the records whose text covers the offset, innermost first:
+// the step, then its act, then the unit.
+func (m *SourceMap) At(offset int) []SourceEntity {
+	var list []SourceEntity
+	for _, e := range m.entities {
+		for _, r := range e.ranges {
+			if r[0] <= offset && (offset < r[1] || offset == r[0] && r[0] == r[1]) {
+				list = append(list, e)
+				break
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].End.Offset-list[i].Start.Offset < list[j].End.Offset-list[j].Start.Offset
+	})
+	return list
+}
+
+// AtLineCol is At for a line and column.
+func (m *SourceMap) AtLineCol(line, col int) []SourceEntity {
+	p, ok := m.PositionAt(line, col)
+	if !ok {
+		return nil
+	}
+	return m.At(p.Offset)
+}
+
+// Find returns the record with the unit ID and sequence numbers in ref:
+// the unit if ActSeq is zero, the act if StepSeq is zero, and otherwise
+// the step.
+func (m *SourceMap) Find(ref SrcRef) (SourceEntity, bool) {
+	for _, e := range m.entities {
+		if e.UnitID == ref.UnitID && e.ActSeq == ref.ActSeq && e.StepSeq == ref.StepSeq {
+			return e, true
+		}
+	}
+	return SourceEntity{}, false
+}
+
+func (m *SourceMap) add(e SourceEntity, ranges ...[2]int) {
+	start, end := ranges[0][0], ranges[0][1]
+	for _, r := range ranges[1:] {
+		start, end = min(start, r[0]), max(end, r[1])
+	}
+	e.Start, _ = m.Position(start)
+	e.End, _ = m.Position(end)
+	e.Text = string(m.text[start:end])
+	e.ranges = ranges
+	m.entities = append(m.entities, e)
+}
+
+// lineSpan returns the offsets of a line without its end-of-line,
+// or -1, -1 if there is no such line.
+func (m *SourceMap) lineSpan(line int) (int, int) {
+	if line < 1 || line > len(m.lineStarts) {
+		return -1, -1
+	}
+	start, end := m.lineStarts[line-1], len(m.text)
+	if line < len(m.lineStarts) {
+		end = m.lineStarts[line] - 1
+	}
+	return start, m.trimRight(start, end)
+}
+
+// stepSpan finds the n-th step on a movement line the way the parser splits
+// it: the text after the label ("Tribe Movement:", "Scout 1:", "0987 Status:")
+// and its "Move" or "Scout" keyword is split on backslashes, and each step
+// is trimmed of spaces and trailing commas. If the line has fewer steps than
+// n (the parser cleans up lines before splitting them), the whole line is
+// returned.
+func (m *SourceMap) stepSpan(line, n int) (int, int) {
+	start, end := m.lineSpan(line)
+	if start < 0 || n < 1 {
+		return start, end
+	}
+	body := start
+	if i := bytes.IndexByte(m.text[start:end], ':'); i >= 0 {
+		body = start + i + 1
+	}
+	for body < end && m.text[body] == ' ' {
+		body++
+	}
+	for _, kw := range []string{"Move", "Scout"} {
+		if bytes.HasPrefix(m.text[body:end], []byte(kw)) {
+			body += len(kw)
+			break
+		}
+	}
+
+	segStart := body
+	for i := 1; ; i++ {
+		segEnd := end
+		if j := bytes.IndexByte(m.text[segStart:end], '\\'); j >= 0 {
+			segEnd = segStart + j
+		}
+		if i == n {
+			s, e := segStart, segEnd
+			for s < e && (m.text[s] == ' ' || m.text[s] == '\t') {
+				s++
+			}
+			for e > s && bytes.IndexByte([]byte(", \t"), m.text[e-1]) >= 0 {
+				e--
+			}
+			return s, e
+		}
+		if segEnd == end {
+			return start, end
+		}
+		segStart = segEnd + 1
+	}
+}
+
+// trimRight moves end back over trailing white space, but not before start.
+func (m *SourceMap) trimRight(start, end int) int {
+	for end > start && bytes.IndexByte([]byte(" \t\r\n"), m.text[end-1]) >= 0 {
+		end--
+	}
+	return end
+}
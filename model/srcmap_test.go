@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+const srcMapText = `Tribe 0987, , Current Hex = QQ 1012, (Previous Hex = QQ 1010)
+Current Turn 900-01 (#1), Winter, FINE
+Tribe Movement: Move N-PR,  \N-GH, River S,  \NE-PR
+Scout 1:Scout N-PR, \Can't Move on Ocean to N of HEX
+0987 Status: PRAIRIE, 0987
+
+Courier 0987c1, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)
+`
+
+// srcMapUnits are the records the parser reads from srcMapText.
+func srcMapUnits() []*model.UnitX {
+	step := func(seq, line, lineStep int) *model.Step {
+		return &model.Step{Seq: seq, Src: &model.SrcRef{Line: line, LineStep: lineStep}}
+	}
+	return []*model.UnitX{
+		{UnitID: "0987", Src: &model.SrcRef{Line: 1}, Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindMove, Src: &model.SrcRef{Line: 3}, Steps: []*model.Step{
+				step(1, 3, 1), step(2, 3, 2), step(3, 3, 3), step(4, 5, 1),
+			}},
+			{Seq: 2, Kind: model.ActKindScout, Src: &model.SrcRef{Line: 4}, Steps: []*model.Step{
+				step(1, 4, 1), step(2, 4, 2),
+			}},
+		}},
+		{UnitID: "0987c1", Src: &model.SrcRef{Line: 7}},
+	}
+}
+
+func TestSourceMap_AtLineCol(t *testing.T) {
+	m := model.NewSourceMap([]byte(srcMapText), srcMapUnits())
+
+	testCases := []struct {
+		name      string
+		line, col int
+		want      []string // kind:unit/act/step:text of each record, innermost first
+	}{
+		{name: "header", line: 1, col: 3, want: []string{"unit:0987/0/0"}},
+		{name: "label", line: 3, col: 2, want: []string{"act:0987/1/0", "unit:0987/0/0"}},
+		{name: "second step", line: 3, col: 32, want: []string{"step:0987/1/2:N-GH, River S", "act:0987/1/0", "unit:0987/0/0"}},
+		{name: "between steps", line: 3, col: 44, want: []string{"act:0987/1/0", "unit:0987/0/0"}},
+		{name: "scout line is not the move act", line: 4, col: 16, want: []string{"step:0987/2/1:N-PR", "act:0987/2/0", "unit:0987/0/0"}},
+		{name: "status step", line: 5, col: 14, want: []string{"step:0987/1/4:PRAIRIE, 0987", "act:0987/1/0", "unit:0987/0/0"}},
+		{name: "blank line between sections", line: 6, col: 1},
+		{name: "courier", line: 7, col: 1, want: []string{"unit:0987c1/0/0"}},
+		{name: "past end of line", line: 1, col: 200},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, e := range m.AtLineCol(tc.line, tc.col) {
+				s := string(e.Kind) + ":" + e.UnitID + "/" + strconv.Itoa(e.ActSeq) + "/" + strconv.Itoa(e.StepSeq)
+				if e.Kind == model.SourceStep {
+					s += ":" + e.Text
+				}
+				got = append(got, s)
+			}
+			if strings.Join(got, " | ") != strings.Join(tc.want, " | ") {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSourceMap_Find(t *testing.T) {
+	m := model.NewSourceMap([]byte(srcMapText), srcMapUnits())
+
+	e, ok := m.Find(model.SrcRef{UnitID: "0987", ActSeq: 2, StepSeq: 2})
+	if !ok {
+		t.Fatalf("expected to find scout step 2")
+	}
+	if e.Text != "Can't Move on Ocean to N of HEX" {
+		t.Errorf("text: expected the failed step, got %q", e.Text)
+	}
+	if e.Start.Line != 4 || e.Start.Col != 22 || e.End.Col != 53 {
+		t.Errorf("span: expected 4:22-4:53, got %d:%d-%d:%d", e.Start.Line, e.Start.Col, e.End.Line, e.End.Col)
+	}
+
+	e, ok = m.Find(model.SrcRef{UnitID: "0987", ActSeq: 1})
+	if !ok || e.Start.Line != 3 || e.End.Line != 5 {
+		t.Errorf("move act: expected lines 3-5, got %v %+v", ok, e)
+	}
+
+	if _, ok := m.Find(model.SrcRef{UnitID: "0987e1"}); ok {
+		t.Errorf("expected no record for a unit that isn't in the report")
+	}
+}
+
+func TestSourceMap_Position(t *testing.T) {
+	m := model.NewSourceMap([]byte("ab\ncd\n"), nil)
+	for _, tc := range []struct {
+		offset, line, col int
+	}{
+		{0, 1, 1}, {2, 1, 3}, {3, 2, 1}, {5, 2, 3}, {6, 3, 1},
+	} {
+		p, ok := m.Position(tc.offset)
+		if !ok || p.Line != tc.line || p.Col != tc.col {
+			t.Errorf("%d: expected %d:%d, got %v %d:%d", tc.offset, tc.line, tc.col, ok, p.Line, p.Col)
+		}
+		if q, ok := m.PositionAt(tc.line, tc.col); !ok || q.Offset != tc.offset {
+			t.Errorf("%d:%d: expected offset %d, got %v %d", tc.line, tc.col, tc.offset, ok, q.Offset)
+		}
+	}
+	if _, ok := m.Position(7); ok {
+		t.Errorf("expected offset past the end to be invalid")
+	}
+	if _, ok := m.PositionAt(1, 4); ok {
+		t.Errorf("expected a column in the next line to be invalid")
+	}
+}
@@ -33,6 +33,10 @@ type Store interface {
 	GetReportFileBySHA256(ctx context.Context, sha256 string) (*ReportFile, error)
 	GetReportFileByID(ctx context.Context, id int64) (*ReportFile, error)
 	InsertReportFileWithBatch(ctx context.Context, rf *ReportFile) (int64, error)
+
+	// games
+
+	UnitIDRules(ctx context.Context, gameID string) (UnitIDRules, error)
 }
 
 // Stats holds store statistics.
@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mdhender/tnrpt/direction"
+)
+
+// TNCoord coordinates are in the form "AB 0102":
+//
+//   - "A" (grid row) and "B" (grid column) identify a map on the grid.
+//     Both are "A" to "Z", or both are "#" for an obscured location.
+//   - "0102" is the map position: column 01..30 and row 01..21.
+//
+// The empty string and "N/A" mean the location is unknown.
+// Even-numbered columns are shoved down, so 0201 is southeast of 0101.
+const (
+	tnColumnsPerGrid = 30
+	tnRowsPerGrid    = 21
+)
+
+// NewTNCoord returns the coordinate for a grid and map column and row.
+// An empty grid returns the unknown coordinate.
+func NewTNCoord(grid string, col, row int) TNCoord {
+	if grid == "" {
+		return ""
+	}
+	return TNCoord(fmt.Sprintf("%s %02d%02d", grid, col, row))
+}
+
+// ParseTNCoord validates a coordinate from a report, forcing the grid to upper case.
+// It accepts "N/A" for an unknown location.
+func ParseTNCoord(s string) (TNCoord, error) {
+	c := TNCoord(strings.ToUpper(strings.TrimSpace(s)))
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+	return c, nil
+}
+
+// IsUnknown returns true if the coordinate is empty or "N/A".
+func (c TNCoord) IsUnknown() bool {
+	return c == "" || c == "N/A"
+}
+
+// IsObscured returns true if the grid is "##".
+func (c TNCoord) IsObscured() bool {
+	return len(c) == 7 && c[0] == '#' && c[1] == '#'
+}
+
+// Valid returns true if the coordinate is unknown or well-formed.
+func (c TNCoord) Valid() bool {
+	return c.Validate() == nil
+}
+
+// Validate returns an error describing why the coordinate is malformed.
+// Unknown coordinates are valid.
+func (c TNCoord) Validate() error {
+	_, _, _, err := c.Parse()
+	return err
+}
+
+// Parse splits the coordinate into grid, map column, and map row.
+// Unknown coordinates return an empty grid and zero column and row.
+func (c TNCoord) Parse() (grid string, col, row int, err error) {
+	if c.IsUnknown() {
+		return "", 0, 0, nil
+	}
+	s := string(c)
+	if len(s) != 7 {
+		return "", 0, 0, fmt.Errorf("coord %q: invalid length", s)
+	}
+	gr, gc := s[0], s[1]
+	if gr == '#' || gc == '#' {
+		if gr != gc {
+			return "", 0, 0, fmt.Errorf("coord %q: invalid obscured grid", s)
+		}
+	} else if !('A' <= gr && gr <= 'Z') {
+		return "", 0, 0, fmt.Errorf("coord %q: invalid grid row", s)
+	} else if !('A' <= gc && gc <= 'Z') {
+		return "", 0, 0, fmt.Errorf("coord %q: invalid grid column", s)
+	}
+	if s[2] != ' ' {
+		return "", 0, 0, fmt.Errorf("coord %q: missing space after grid", s)
+	}
+	for _, ch := range s[3:] {
+		if !('0' <= ch && ch <= '9') {
+			return "", 0, 0, fmt.Errorf("coord %q: invalid digit %q", s, ch)
+		}
+	}
+	col = int(s[3]-'0')*10 + int(s[4]-'0')
+	row = int(s[5]-'0')*10 + int(s[6]-'0')
+	if !(1 <= col && col <= tnColumnsPerGrid) {
+		return "", 0, 0, fmt.Errorf("coord %q: column %d: must be 1..%d", s, col, tnColumnsPerGrid)
+	} else if !(1 <= row && row <= tnRowsPerGrid) {
+		return "", 0, 0, fmt.Errorf("coord %q: row %d: must be 1..%d", s, row, tnRowsPerGrid)
+	}
+	return s[:2], col, row, nil
+}
+
+// Grid returns the grid ("AB" or "##"), or an empty string if the coordinate is unknown or malformed.
+func (c TNCoord) Grid() string {
+	grid, _, _, _ := c.Parse()
+	return grid
+}
+
+// Col returns the map column (1..30), or 0 if the coordinate is unknown or malformed.
+func (c TNCoord) Col() int {
+	_, col, _, _ := c.Parse()
+	return col
+}
+
+// Row returns the map row (1..21), or 0 if the coordinate is unknown or malformed.
+func (c TNCoord) Row() int {
+	_, _, row, _ := c.Parse()
+	return row
+}
+
+// Neighbor returns the coordinate of the hex adjacent in the given direction
+// on a standard world map (see DefaultWorldRules). Games with other world
+// rules should call WorldRules.Neighbor.
+func (c TNCoord) Neighbor(dir direction.Direction_e) (TNCoord, error) {
+	return DefaultWorldRules.Neighbor(c, dir)
+}
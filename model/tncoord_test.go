@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/coords"
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestTNCoord_Parse(t *testing.T) {
+	testCases := []struct {
+		coord string
+		grid  string
+		col   int
+		row   int
+		ok    bool
+	}{
+		{coord: "AB 0102", grid: "AB", col: 1, row: 2, ok: true},
+		{coord: "QQ 3021", grid: "QQ", col: 30, row: 21, ok: true},
+		{coord: "## 1510", grid: "##", col: 15, row: 10, ok: true},
+		{coord: "N/A", ok: true},
+		{coord: "", ok: true},
+		{coord: "AB 3102"},
+		{coord: "AB 0122"},
+		{coord: "AB 0000"},
+		{coord: "A# 0101"},
+		{coord: "ab 0101"},
+		{coord: "AB0101"},
+		{coord: "AB-0101"},
+		{coord: "AB 01O1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.coord, func(t *testing.T) {
+			tn := model.TNCoord(tc.coord)
+			grid, col, row, err := tn.Parse()
+			if tc.ok != (err == nil) {
+				t.Fatalf("ok: expected %v, got error %v", tc.ok, err)
+			}
+			if grid != tc.grid || col != tc.col || row != tc.row {
+				t.Errorf("expected (%q, %d, %d), got (%q, %d, %d)", tc.grid, tc.col, tc.row, grid, col, row)
+			}
+			if tn.Grid() != tc.grid || tn.Col() != tc.col || tn.Row() != tc.row {
+				t.Errorf("accessors: expected (%q, %d, %d), got (%q, %d, %d)", tc.grid, tc.col, tc.row, tn.Grid(), tn.Col(), tn.Row())
+			}
+			if tc.ok && tc.grid != "" && model.NewTNCoord(grid, col, row) != tn {
+				t.Errorf("round trip: expected %q, got %q", tn, model.NewTNCoord(grid, col, row))
+			}
+		})
+	}
+}
+
+func TestParseTNCoord_Normalizes(t *testing.T) {
+	tn, err := model.ParseTNCoord(" ab 0102 ")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if tn != "AB 0102" {
+		t.Errorf("expected %q, got %q", "AB 0102", tn)
+	}
+}
+
+func TestTNCoord_Neighbor(t *testing.T) {
+	// cross-check against the cube coordinate implementation
+	for _, from := range []string{"AA 0101", "BC 0101", "BC 0201", "BC 3021", "BC 2921", "QQ 1510", "QQ 1610", "MM 3001", "MM 0121"} {
+		for _, dir := range direction.Directions {
+			wmc, err := coords.NewWorldMapCoord(from)
+			if err != nil {
+				t.Fatalf("%s: %v", from, err)
+			}
+			want := wmc.Move(dir).ID()
+			got, err := model.TNCoord(from).Neighbor(dir)
+			if err != nil {
+				if from == "AA 0101" {
+					continue // off the edge of the map
+				}
+				t.Fatalf("%s %s: %v", from, dir, err)
+			}
+			if string(got) != want {
+				t.Errorf("%s %s: expected %q, got %q", from, dir, want, got)
+			}
+		}
+	}
+}
+
+func TestTNCoord_NeighborErrors(t *testing.T) {
+	if _, err := model.TNCoord("N/A").Neighbor(direction.North); err == nil {
+		t.Errorf("unknown: expected error")
+	}
+	if _, err := model.TNCoord("AA 0101").Neighbor(direction.North); err == nil {
+		t.Errorf("AA 0101 N: expected error")
+	}
+	if _, err := model.TNCoord("## 0101").Neighbor(direction.NorthWest); err == nil {
+		t.Errorf("## 0101 NW: expected error")
+	}
+	if got, err := model.TNCoord("## 0101").Neighbor(direction.SouthEast); err != nil || got != "## 0201" {
+		t.Errorf("## 0101 SE: expected \"## 0201\", got %q, %v", got, err)
+	}
+}
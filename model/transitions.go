@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"sort"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+// TerrainTransition counts the attempts to move from one kind of terrain into
+// another, for one kind of unit, and how many of them succeeded.
+type TerrainTransition struct {
+	UnitKind  UnitKind           `json:"unitKind"` // scouting parties are counted as UnitKindScout
+	From      terrain.Terrain_e  `json:"from"`
+	To        terrain.Terrain_e  `json:"to"` // terrain.Blank if the hex moved into isn't known
+	Attempts  int                `json:"attempts"`
+	Succeeded int                `json:"succeeded"`
+	Failures  map[FailReason]int `json:"failures,omitempty"`
+}
+
+// SuccessRate is the fraction of attempts that succeeded.
+func (t TerrainTransition) SuccessRate() float64 {
+	if t.Attempts == 0 {
+		return 0
+	}
+	return float64(t.Succeeded) / float64(t.Attempts)
+}
+
+// TerrainTransitions walks the units' steps (see ResolveSteps) and counts every
+// move step by unit kind and the terrain of the hexes it left and tried to
+// enter. Terrain comes from km, so a failed step into a hex some other unit
+// reported is still counted with the right destination, and steps are placed
+// using km's world rules. Steps out of a hex
+// with unknown terrain are skipped. The result is ordered by unit kind, then
+// from and to terrain.
+func TerrainTransitions(units []*UnitX, km *KnownMap, rules UnitIDRules) []TerrainTransition {
+	type key struct {
+		kind     UnitKind
+		from, to terrain.Terrain_e
+	}
+	counts := map[key]*TerrainTransition{}
+	for _, u := range units {
+		unitKind := rules.Kind(u.UnitID)
+		for _, sa := range ResolveSteps(u, km.world) {
+			st := sa.Step
+			if st.Kind != StepKindAdv {
+				continue
+			}
+			d, ok := direction.StringToEnum[st.Dir]
+			if !ok || d == direction.Unknown {
+				continue
+			}
+			// successful steps end in the hex entered; failed ones stay in the hex they tried to leave
+			from, to := sa.TN, sa.TN
+			var err error
+			if st.Ok {
+				from, err = km.Neighbor(sa.TN, opposite(d))
+			} else {
+				to, err = km.Neighbor(sa.TN, d)
+			}
+			if err != nil {
+				continue
+			}
+			k := key{kind: unitKind, from: km.Terrain(from), to: km.Terrain(to)}
+			if sa.ActKind == ActKindScout {
+				k.kind = UnitKindScout
+			}
+			if k.from == terrain.Blank {
+				continue
+			}
+			tt, ok := counts[k]
+			if !ok {
+				tt = &TerrainTransition{UnitKind: k.kind, From: k.from, To: k.to}
+				counts[k] = tt
+			}
+			tt.Attempts++
+			if st.Ok {
+				tt.Succeeded++
+			} else {
+				if tt.Failures == nil {
+					tt.Failures = map[FailReason]int{}
+				}
+				reason := FailReason(st.FailWhy)
+				if reason == "" {
+					reason = FailUnknown
+				}
+				tt.Failures[reason]++
+			}
+		}
+	}
+
+	list := make([]TerrainTransition, 0, len(counts))
+	for _, tt := range counts {
+		list = append(list, *tt)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.UnitKind != b.UnitKind {
+			return a.UnitKind < b.UnitKind
+		}
+		if a.From != b.From {
+			return a.From.String() < b.From.String()
+		}
+		return a.To.String() < b.To.String()
+	})
+	return list
+}
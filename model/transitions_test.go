@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+func TestTerrainTransitions(t *testing.T) {
+	obs := func(tn model.TNCoord, terr string) *model.UnitX {
+		return &model.UnitX{UnitID: "0987", TurnNo: 90001, StartTN: tn, EndTN: tn, Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindObs, Terr: terr}}},
+		}}
+	}
+	adv := func(dir, terr string, ok bool, why model.FailReason) *model.Step {
+		return &model.Step{Kind: model.StepKindAdv, Dir: dir, Terr: terr, Ok: ok, FailWhy: string(why)}
+	}
+	units := []*model.UnitX{
+		obs("QQ 1010", "PR"),
+		obs("QQ 1008", "CH"),
+		// QQ 1010 -> QQ 1009 (PR -> GH), then fails to go on into the conifer hills
+		{UnitID: "0987e1", TurnNo: 90001, StartTN: "QQ 1010", EndTN: "QQ 1009", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{
+				adv("N", "GH", true, ""),
+				adv("N", "", false, model.FailExhausted),
+			}},
+			// the scouting party is counted separately
+			{Seq: 2, Kind: model.ActKindScout, Steps: []*model.Step{adv("S", "PR", true, "")}},
+		}},
+		// moving out of unknown terrain isn't counted
+		{UnitID: "0987c1", TurnNo: 90001, StartTN: "QQ 2020", EndTN: "QQ 2020", Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindMove, Steps: []*model.Step{adv("N", "", false, model.FailOcean)}},
+		}},
+	}
+	km := model.NewKnownMap(units, model.DefaultWorldRules)
+
+	got := model.TerrainTransitions(units, km, model.DefaultUnitIDRules)
+	want := []model.TerrainTransition{
+		{UnitKind: model.UnitKindElement, From: terrain.HillsGrassy, To: terrain.HillsConifer, Attempts: 1, Failures: map[model.FailReason]int{model.FailExhausted: 1}},
+		{UnitKind: model.UnitKindElement, From: terrain.FlatPrairie, To: terrain.HillsGrassy, Attempts: 1, Succeeded: 1},
+		{UnitKind: model.UnitKindScout, From: terrain.HillsGrassy, To: terrain.FlatPrairie, Attempts: 1, Succeeded: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TerrainTransitions() =\n%+v\nwant\n%+v", got, want)
+	}
+	if rate := want[1].SuccessRate(); rate != 1 {
+		t.Errorf("SuccessRate() = %v, want 1", rate)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TurnNo is a turn number encoded as 100*year + month.
+// For example, turn "0899-12" is stored as 89912.
+type TurnNo int
+
+const (
+	// FirstTurnNo is the earliest turn in the game (year 899, month 12).
+	FirstTurnNo TurnNo = 89912
+	// LastTurnNo is the latest turn that can be represented (year 9999, month 12).
+	LastTurnNo TurnNo = 999912
+)
+
+// NewTurnNo returns the turn number for a year and month.
+// It does not validate the result; call Valid to check it.
+func NewTurnNo(year, month int) TurnNo {
+	return TurnNo(100*year + month)
+}
+
+// ParseTurnNo parses a turn number from a turn id ("0899-12" or "899-12")
+// or from its integer form ("89912"). The result is validated.
+func ParseTurnNo(s string) (TurnNo, error) {
+	var t TurnNo
+	if yyyy, mm, ok := strings.Cut(s, "-"); ok {
+		year, err := strconv.Atoi(yyyy)
+		if err != nil || len(yyyy) < 3 || len(yyyy) > 4 {
+			return 0, fmt.Errorf("turn %q: invalid year", s)
+		}
+		month, err := strconv.Atoi(mm)
+		if err != nil || len(mm) != 2 {
+			return 0, fmt.Errorf("turn %q: invalid month", s)
+		}
+		t = NewTurnNo(year, month)
+	} else {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("turn %q: must be YYYY-MM or YYYYMM", s)
+		}
+		t = TurnNo(n)
+	}
+	if err := t.Validate(); err != nil {
+		return 0, fmt.Errorf("turn %q: %w", s, err)
+	}
+	return t, nil
+}
+
+// Year returns the year of the turn.
+func (t TurnNo) Year() int {
+	return int(t) / 100
+}
+
+// Month returns the month of the turn.
+func (t TurnNo) Month() int {
+	return int(t) % 100
+}
+
+// Valid returns true if the turn has a month in 1..12 and is within
+// the range FirstTurnNo..LastTurnNo.
+func (t TurnNo) Valid() bool {
+	return t.Validate() == nil
+}
+
+// Validate returns an error describing why the turn is not valid, or nil.
+func (t TurnNo) Validate() error {
+	if month := t.Month(); month < 1 || month > 12 {
+		return fmt.Errorf("month %d: must be 1..12", month)
+	}
+	if t < FirstTurnNo || t > LastTurnNo {
+		return fmt.Errorf("turn %s: must be %s..%s", t, FirstTurnNo, LastTurnNo)
+	}
+	return nil
+}
+
+// String returns the turn id, e.g. "0899-12".
+func (t TurnNo) String() string {
+	return fmt.Sprintf("%04d-%02d", t.Year(), t.Month())
+}
+
+// Compare returns -1 if t is before u, 0 if they are the same turn, and +1 if t is after u.
+func (t TurnNo) Compare(u TurnNo) int {
+	if t < u {
+		return -1
+	} else if t > u {
+		return 1
+	}
+	return 0
+}
+
+// Next returns the turn after t.
+func (t TurnNo) Next() TurnNo {
+	if t.Month() >= 12 {
+		return NewTurnNo(t.Year()+1, 1)
+	}
+	return t + 1
+}
+
+// Prev returns the turn before t.
+func (t TurnNo) Prev() TurnNo {
+	if t.Month() <= 1 {
+		return NewTurnNo(t.Year()-1, 12)
+	}
+	return t - 1
+}
+
+// Since returns the number of turns from u to t; it is negative if u is after t.
+func (t TurnNo) Since(u TurnNo) int {
+	return (t.Year()*12 + t.Month()) - (u.Year()*12 + u.Month())
+}
+
+// Value implements driver.Valuer; turns are stored as integers.
+func (t TurnNo) Value() (driver.Value, error) {
+	return int64(t), nil
+}
+
+// Scan implements sql.Scanner.
+func (t *TurnNo) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*t = TurnNo(v)
+	case nil:
+		*t = 0
+	default:
+		return fmt.Errorf("turn_no: unsupported type %T", src)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestParseTurnNo(t *testing.T) {
+	testCases := []struct {
+		input  string
+		turnNo model.TurnNo
+		ok     bool
+	}{
+		{input: "0899-12", turnNo: 89912, ok: true},
+		{input: "899-12", turnNo: 89912, ok: true},
+		{input: "0900-01", turnNo: 90001, ok: true},
+		{input: "89912", turnNo: 89912, ok: true},
+		{input: "0899-11"},
+		{input: "0900-13"},
+		{input: "0900-00"},
+		{input: "90013"},
+		{input: "0900-1"},
+		{input: "09-01"},
+		{input: "turn"},
+		{input: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			turnNo, err := model.ParseTurnNo(tc.input)
+			if tc.ok != (err == nil) {
+				t.Fatalf("ok: expected %v, got error %v", tc.ok, err)
+			}
+			if turnNo != tc.turnNo {
+				t.Errorf("expected %d, got %d", tc.turnNo, turnNo)
+			}
+		})
+	}
+}
+
+func TestTurnNo_Methods(t *testing.T) {
+	turnNo := model.NewTurnNo(899, 12)
+	if turnNo.Year() != 899 || turnNo.Month() != 12 {
+		t.Errorf("expected 899/12, got %d/%d", turnNo.Year(), turnNo.Month())
+	}
+	if got := turnNo.String(); got != "0899-12" {
+		t.Errorf("string: expected 0899-12, got %q", got)
+	}
+	if next := turnNo.Next(); next != 90001 {
+		t.Errorf("next: expected 90001, got %d", next)
+	}
+	if prev := model.TurnNo(90001).Prev(); prev != 89912 {
+		t.Errorf("prev: expected 89912, got %d", prev)
+	}
+	if turnNo.Compare(90001) != -1 || model.TurnNo(90001).Compare(turnNo) != 1 || turnNo.Compare(89912) != 0 {
+		t.Errorf("compare: unexpected ordering")
+	}
+	if since := model.TurnNo(90002).Since(turnNo); since != 2 {
+		t.Errorf("since: expected 2, got %d", since)
+	}
+}
@@ -11,7 +11,7 @@ type ReportFile struct {
 	ID        int64     `json:"id"        db:"id"`
 	Game      string    `json:"game"      db:"game"`
 	ClanNo    string    `json:"clanNo"    db:"clan_no"`
-	TurnNo    int       `json:"turnNo"    db:"turn_no"`
+	TurnNo    TurnNo    `json:"turnNo"    db:"turn_no"`
 	Name      string    `json:"name"      db:"name"` // original filename (untainted or sanitized)
 	SHA256    string    `json:"sha256"    db:"sha256"`
 	Mime      string    `json:"mime"      db:"mime"`
@@ -23,23 +23,28 @@ type ReportFile struct {
 // ReportX is the extracted subset of a ReportFile (map-render-relevant only).
 type ReportX struct {
 	ID           int64     `json:"id"           db:"id"`
+	Key          string    `json:"key,omitempty" db:"-"` // natural key for import/merge; see SetKeys
 	ReportFileID int64     `json:"reportFileId" db:"report_file_id"`
 	Game         string    `json:"game"         db:"game"`
 	ClanNo       string    `json:"clanNo"       db:"clan_no"`
-	TurnNo       int       `json:"turnNo"       db:"turn_no"`
+	TurnNo       TurnNo    `json:"turnNo"       db:"turn_no"`
+	Season       string    `json:"season,omitempty"  db:"season"`  // e.g., "Winter"
+	Weather      string    `json:"weather,omitempty" db:"weather"` // e.g., "FINE"
 	CreatedAt    time.Time `json:"createdAt"    db:"created_at"`
 	Units        []*UnitX  `json:"units,omitempty"` // for JSON export/import
 }
 
 // UnitX is one unit section in a report extract.
 type UnitX struct {
-	// Natural key (for import/merge): (report_x_id, unit_id)
 	ID        int64  `json:"id"        db:"id"`
+	Key       string `json:"key,omitempty" db:"-"` // natural key for import/merge; see ReportX.SetKeys
 	ReportXID int64  `json:"reportXId" db:"report_x_id"`
 	UnitID    string `json:"unitId"    db:"unit_id"` // e.g., "0987c4"
-	ClanID    string `json:"clanId"    db:"clan_id"` // e.g., "0987" (first 4 chars of unit_id)
+	ClanID    string `json:"clanId"    db:"clan_id"` // e.g., "987"; see UnitIDRules.ClanID
 
-	TurnNo int `json:"turnNo" db:"turn_no"` // e.g., 90304
+	Kind UnitKind `json:"kind,omitempty" db:"-"` // derived from unit_id; see UnitKindOf
+
+	TurnNo TurnNo `json:"turnNo" db:"turn_no"` // e.g., 90304
 
 	StartTN TNCoord `json:"startTN" db:"-"` // e.g., "QQ 0205"
 	EndTN   TNCoord `json:"endTN"   db:"-"` // e.g., "QQ 0205"
@@ -77,10 +82,14 @@ const (
 type SrcRef struct {
 	DocID   int64  `json:"docId"`            // report_file_id (or other document id)
 	UnitID  string `json:"unitId,omitempty"` // originating unit
-	TurnNo  int    `json:"turnNo,omitempty"`
+	TurnNo  TurnNo `json:"turnNo,omitempty"`
 	ActSeq  int    `json:"actSeq,omitempty"`  // sequence in UnitX.Acts (1-based)
 	StepSeq int    `json:"stepSeq,omitempty"` // sequence in Act.Steps (1-based)
 	Note    string `json:"note,omitempty"`
+
+	// Where in the report text the record was read from; see SourceMap.
+	Line     int `json:"line,omitempty"`     // 1-based line in the report text
+	LineStep int `json:"lineStep,omitempty"` // 1-based position among the backslash-separated steps on Line
 }
 
 // Act is an action in an extracted unit section.
@@ -91,6 +100,7 @@ type SrcRef struct {
 // while Steps (for move/scout/status) live in `steps`.
 type Act struct {
 	ID      int64   `json:"id"             db:"id"`
+	Key     string  `json:"key,omitempty"  db:"-"` // natural key for import/merge; see ReportX.SetKeys
 	UnitXID int64   `json:"unitXId"        db:"unit_x_id"`
 	Seq     int     `json:"seq"            db:"seq"`  // ordering within unit section (1-based)
 	Kind    ActKind `json:"kind"           db:"kind"` // follow|goto|move|scout|status
@@ -115,6 +125,7 @@ type Act struct {
 // Encounters, terrain, and borders are normalized in child tables keyed by step_id.
 type Step struct {
 	ID    int64    `json:"id"    db:"id"`
+	Key   string   `json:"key,omitempty" db:"-"` // natural key for import/merge; see ReportX.SetKeys
 	ActID int64    `json:"actId" db:"act_id"`
 	Seq   int      `json:"seq"   db:"seq"`  // 1-based
 	Kind  StepKind `json:"kind"  db:"kind"` // adv|still|patrol|obs
@@ -125,7 +136,8 @@ type Step struct {
 
 	// adv payload
 	Dir     string `json:"dir,omitempty"     db:"dir"`      // e.g. N,NE,SE,S,SW,NW
-	FailWhy string `json:"failWhy,omitempty" db:"fail_why"` // terrain|exhaust|blocked|unknown|...
+	FailWhy string `json:"failWhy,omitempty" db:"fail_why"` // a FailReason: ocean|river|exhausted|prohibited|...
+	FailRaw string `json:"failRaw,omitempty" db:"fail_raw"` // the GM's wording, e.g. "No Ford on River to N of HEX"
 
 	// obs payload (flattened bits; details in child tables)
 	Terr    string `json:"terr,omitempty"    db:"terr"`    // terrain code/name
@@ -177,6 +189,7 @@ type BorderObs struct {
 type Tile struct {
 	ID  int64    `json:"id"  db:"id"`
 	Hex hexg.Hex `json:"hex" db:"hex"`
+	TN  TNCoord  `json:"tn,omitempty" db:"-"` // the hex as a TribeNet coordinate, if known; stored as grid, col, row
 
 	Terr         string        `json:"terr,omitempty"         db:"terr"`
 	SpecialLabel string        `json:"specialLabel,omitempty" db:"special_label"`
@@ -192,28 +205,56 @@ type Tile struct {
 // TileSrc is provenance for tiles; it lets you trace/resolve merge conflicts.
 type TileSrc struct {
 	DocID   int64  `json:"docId"             db:"doc_id"`
+	StepID  int64  `json:"stepId,omitempty"  db:"-"` // the step that observed the tile; stored in step_tiles
 	UnitID  string `json:"unitId,omitempty"  db:"unit_id"`
-	TurnNo  int    `json:"turnNo,omitempty"  db:"turn_no"`
+	TurnNo  TurnNo `json:"turnNo,omitempty"  db:"turn_no"`
 	ActSeq  int    `json:"actSeq,omitempty"  db:"act_seq"`  // 1-based
 	StepSeq int    `json:"stepSeq,omitempty" db:"step_seq"` // 1-based
 	Note    string `json:"note,omitempty"    db:"note"`
+
+	// Attrs are the parts of the merged tile this source set; a source whose
+	// sightings lost the merge has none.
+	Attrs []TileAttr `json:"attrs,omitempty" db:"attrs"` // stored comma separated
 }
 
+// TileAttr names a part of a Tile that a TileSrc can set.
+type TileAttr string
+
+const (
+	TileAttrTerrain     TileAttr = "terrain"
+	TileAttrSpecial     TileAttr = "special"
+	TileAttrUnits       TileAttr = "units"
+	TileAttrSettlements TileAttr = "settlements"
+	TileAttrResources   TileAttr = "resources"
+	TileAttrBorders     TileAttr = "borders"
+)
+
 // UploadBatch groups multiple files in one ingest operation.
 type UploadBatch struct {
 	ID        int64     `json:"id"        db:"id"`
 	Game      string    `json:"game"      db:"game"`
 	ClanNo    string    `json:"clanNo"    db:"clan_no"`
-	TurnNo    int       `json:"turnNo"    db:"turn_no"`
+	TurnNo    TurnNo    `json:"turnNo"    db:"turn_no"`
 	CreatedBy string    `json:"createdBy" db:"created_by"` // CLI user or web session
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
+// BatchStatus is an upload batch with the number of report files in it and
+// of their pipeline jobs in each status.
+type BatchStatus struct {
+	UploadBatch
+	Files   int `json:"files"`
+	Queued  int `json:"queued"`
+	Running int `json:"running"`
+	Ok      int `json:"ok"`
+	Failed  int `json:"failed"`
+}
+
 // Work represents a job in the pipeline work queue.
 type Work struct {
 	ID           int64      `json:"id"           db:"id"`
 	ReportFileID int64      `json:"reportFileId" db:"report_file_id"`
-	Stage        string     `json:"stage"        db:"stage"`  // "extract", "parse"
+	Stage        string     `json:"stage"        db:"stage"`  // "extract", "parse", "render"
 	Status       string     `json:"status"       db:"status"` // "queued", "running", "ok", "failed"
 	Attempt      int        `json:"attempt"      db:"attempt"`
 	AvailableAt  time.Time  `json:"availableAt"  db:"available_at"`
@@ -229,8 +270,22 @@ type Work struct {
 const (
 	WorkStageExtract = "extract"
 	WorkStageParse   = "parse"
+	WorkStageRender  = "render" // queued after a parse when the worker renders maps
 )
 
+// ClanMap is a map image of what a clan knew as of a turn, written by the
+// pipeline's render stage.
+type ClanMap struct {
+	ID           int64     `json:"id"           db:"id"`
+	ReportFileID int64     `json:"reportFileId" db:"report_file_id"` // the report whose parse queued the render
+	Game         string    `json:"game"         db:"game"`
+	ClanNo       int       `json:"clanNo"       db:"clan_no"`
+	TurnNo       TurnNo    `json:"turnNo"       db:"turn_no"`
+	FsPath       string    `json:"fsPath"       db:"fs_path"` // relative to data-dir
+	Hexes        int       `json:"hexes"        db:"hexes"`   // hexes with known terrain
+	CreatedAt    time.Time `json:"createdAt"    db:"created_at"`
+}
+
 // WorkStatus constants for job status.
 const (
 	WorkStatusQueued  = "queued"
@@ -239,6 +294,96 @@ const (
 	WorkStatusFailed  = "failed"
 )
 
+// WorkPause stops workers from claiming jobs for a stage until it is resumed.
+// Jobs already running are left to finish.
+type WorkPause struct {
+	Stage    string    `json:"stage"` // "" pauses every stage
+	Reason   string    `json:"reason,omitempty"`
+	PausedBy string    `json:"pausedBy"`
+	PausedAt time.Time `json:"pausedAt"`
+}
+
+// StageMetrics summarizes work queue throughput and latency for one stage.
+// Durations are measured from started_at to finished_at.
+type StageMetrics struct {
+	Stage          string         `json:"stage"`
+	Finished       int            `json:"finished"`       // jobs finished (ok or failed) in the window
+	Failed         int            `json:"failed"`         // jobs failed in the window
+	JobsPerHour    float64        `json:"jobsPerHour"`    // finished jobs per hour over the window
+	AvgDuration    time.Duration  `json:"avgDuration"`    // mean job duration
+	P95Duration    time.Duration  `json:"p95Duration"`    // 95th percentile job duration
+	FailuresByCode map[string]int `json:"failuresByCode"` // failed jobs by error code
+	Queued         int            `json:"queued"`         // jobs currently queued
+	BacklogAge     time.Duration  `json:"backlogAge"`     // age of the oldest queued job
+}
+
+// FailureRate returns the fraction of finished jobs that failed.
+func (m StageMetrics) FailureRate() float64 {
+	if m.Finished == 0 {
+		return 0
+	}
+	return float64(m.Failed) / float64(m.Finished)
+}
+
+// ParseTimings records how long each step of turning a report file into a
+// report extract took. A zero duration means the step wasn't measured; a text
+// upload, for example, has no extract or section split.
+type ParseTimings struct {
+	Extract time.Duration `json:"extract"` // DOCX to text
+	Split   time.Duration `json:"split"`   // text to report sections
+	Parse   time.Duration `json:"parse"`   // bistre parser
+	Adapt   time.Duration `json:"adapt"`   // parser output to model types
+	Store   time.Duration `json:"store"`   // writing the extract to the database
+}
+
+// Total returns the sum of the measured steps.
+func (t ParseTimings) Total() time.Duration {
+	return t.Extract + t.Split + t.Parse + t.Adapt + t.Store
+}
+
+// ReportTiming is the parse timings of one report extract.
+type ReportTiming struct {
+	ReportXID int64        `json:"reportXId"`
+	Game      string       `json:"game"`
+	ClanNo    string       `json:"clanNo"`
+	TurnNo    TurnNo       `json:"turnNo"`
+	Name      string       `json:"name"` // report file name
+	CreatedAt time.Time    `json:"createdAt"`
+	Timings   ParseTimings `json:"timings"`
+}
+
+// UsageStats summarizes how a server has been used since a point in time.
+// It is computed from the server's own database; nothing is sent anywhere.
+type UsageStats struct {
+	Since         time.Time    `json:"since"`
+	Requests      int          `json:"requests"`      // requests recorded since Since
+	ReportsPerDay []DayCount   `json:"reportsPerDay"` // oldest first
+	UsersPerWeek  []WeekCount  `json:"usersPerWeek"`  // oldest first
+	BusiestPages  []RouteUsage `json:"busiestPages"`  // most requested first
+}
+
+// DayCount is a count for one UTC day, formatted as YYYY-MM-DD.
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+// WeekCount is the number of distinct logged-in users in the week starting
+// on the Monday Week (YYYY-MM-DD, UTC).
+type WeekCount struct {
+	Week  string `json:"week"`
+	Users int    `json:"users"`
+}
+
+// RouteUsage is how often a route was requested, by how many users, and how
+// long it took to serve on average.
+type RouteUsage struct {
+	Route     string `json:"route"`
+	Requests  int    `json:"requests"`
+	Users     int    `json:"users"`
+	AvgMillis int64  `json:"avgMillis"`
+}
+
 // RenderJob describes a render request (units + turns + params).
 type RenderJob struct {
 	ID        int64     `json:"id"        db:"id"`
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/cerrs"
+)
+
+// UnitIDRules describes the unit IDs a game accepts.
+//
+// A tribe is a tribe digit followed by the clan number, e.g. "0987" or "1987"
+// for clan 987. Other units add a kind letter (c, e, f, g, or s) and a digit
+// 1..9, e.g. "0987e1".
+type UnitIDRules struct {
+	// ClanDigits is the number of digits in a tribe ID, including the tribe digit.
+	// Most games use 4; some variants allow 5-digit clans. Zero means 4.
+	ClanDigits int
+}
+
+// DefaultUnitIDRules are the rules for a standard game.
+var DefaultUnitIDRules = UnitIDRules{ClanDigits: 4}
+
+// The tribe ID lengths a game may use; see UnitIDRules.ClanDigits.
+const (
+	MinClanDigits = 4
+	MaxClanDigits = 5
+)
+
+// Regular expressions for IDs in file names and report headers, which are
+// read before the game (and so its rules) is known. They accept a tribe ID of
+// any length a game may use; callers that know the game check the ID with
+// ValidateClan or Validate.
+var (
+	// ClanPattern matches a clan's own tribe, whose tribe digit is 0, e.g. "0987" or "01987".
+	ClanPattern = fmt.Sprintf(`0\d{%d,%d}`, MinClanDigits-1, MaxClanDigits-1)
+	// TribePattern matches any tribe, e.g. "0987" or "1987".
+	TribePattern = fmt.Sprintf(`\d{%d,%d}`, MinClanDigits, MaxClanDigits)
+)
+
+// UnitIDError is returned for a unit ID that doesn't follow a game's rules.
+type UnitIDError struct {
+	UnitID string
+	Reason string
+}
+
+func (e *UnitIDError) Error() string {
+	return fmt.Sprintf("unit id %q: %s", e.UnitID, e.Reason)
+}
+
+func (e *UnitIDError) ErrorCode() cerrs.Code {
+	return cerrs.CodeUnitIDInvalid
+}
+
+// ClanMismatchError is returned for a report whose units belong to another
+// clan than the one it was filed under, as when a file is named for the
+// wrong clan.
+type ClanMismatchError struct {
+	Clan  string   // the clan the report was filed under, e.g. "0987"
+	Clans []string // the other clans' IDs, e.g. "0512"
+	Units []string // the units that belong to them
+}
+
+func (e *ClanMismatchError) Error() string {
+	return fmt.Sprintf("report filed under clan %s has units of clan %s: %s",
+		e.Clan, strings.Join(e.Clans, ", "), strings.Join(e.Units, ", "))
+}
+
+func (e *ClanMismatchError) ErrorCode() cerrs.Code {
+	return cerrs.CodeClanMismatch
+}
+
+func (r UnitIDRules) digits() int {
+	if r.ClanDigits <= 0 {
+		return DefaultUnitIDRules.ClanDigits
+	}
+	return r.ClanDigits
+}
+
+// Kind returns the kind of unit for a unit ID, or UnitKindUnknown if the ID is malformed.
+func (r UnitIDRules) Kind(unitID string) UnitKind {
+	n := r.digits()
+	if len(unitID) < n {
+		return UnitKindUnknown
+	}
+	for i := 0; i < n; i++ {
+		if !('0' <= unitID[i] && unitID[i] <= '9') {
+			return UnitKindUnknown
+		}
+	}
+	switch len(unitID) - n {
+	case 0:
+		return UnitKindTribe
+	case 2:
+		if !('1' <= unitID[n+1] && unitID[n+1] <= '9') {
+			return UnitKindUnknown
+		}
+		switch unitID[n] {
+		case 'c':
+			return UnitKindCourier
+		case 'e':
+			return UnitKindElement
+		case 'f':
+			return UnitKindFleet
+		case 'g':
+			return UnitKindGarrison
+		case 's':
+			return UnitKindScout
+		}
+	}
+	return UnitKindUnknown
+}
+
+// Validate returns a *UnitIDError if the unit ID doesn't follow the rules.
+func (r UnitIDRules) Validate(unitID string) error {
+	if r.Kind(unitID) != UnitKindUnknown {
+		return nil
+	}
+	n := r.digits()
+	if len(unitID) < n {
+		return &UnitIDError{UnitID: unitID, Reason: fmt.Sprintf("want %d digits, got %d characters", n, len(unitID))}
+	}
+	for i := 0; i < n; i++ {
+		if !('0' <= unitID[i] && unitID[i] <= '9') {
+			return &UnitIDError{UnitID: unitID, Reason: fmt.Sprintf("want %d digits", n)}
+		}
+	}
+	return &UnitIDError{UnitID: unitID, Reason: "want a kind letter (c, e, f, g, s) and a digit 1..9 after the tribe"}
+}
+
+// ValidateClan returns a *UnitIDError unless clan is a clan's own tribe ID
+// under the rules: a tribe digit of 0 followed by the clan number, e.g. "0987".
+func (r UnitIDRules) ValidateClan(clan string) error {
+	if r.Kind(clan) != UnitKindTribe || clan[0] != '0' {
+		return &UnitIDError{UnitID: clan, Reason: fmt.Sprintf("want a clan of %d digits starting with 0", r.digits())}
+	}
+	return nil
+}
+
+// CheckClan returns a *ClanMismatchError if any of the units, read from the
+// section headers of a report filed under clan, belongs to another clan.
+// Malformed unit IDs are left to Validate.
+func (r UnitIDRules) CheckClan(clan string, unitIDs []string) error {
+	want, err := r.ClanID(clan)
+	if err != nil {
+		return err
+	}
+	e := &ClanMismatchError{Clan: clan}
+	for _, unitID := range unitIDs {
+		got, err := r.ClanID(unitID)
+		if err != nil || got == want {
+			continue
+		}
+		e.Units = append(e.Units, unitID)
+		if other := "0" + unitID[1:r.digits()]; !slices.Contains(e.Clans, other) {
+			e.Clans = append(e.Clans, other)
+		}
+	}
+	if e.Units == nil {
+		return nil
+	}
+	slices.Sort(e.Clans)
+	slices.Sort(e.Units)
+	return e
+}
+
+// ClanID returns the clan that owns the unit, formatted the way the store
+// records clans: at least 3 digits, e.g. "987" for "1987e1".
+func (r UnitIDRules) ClanID(unitID string) (string, error) {
+	if err := r.Validate(unitID); err != nil {
+		return "", err
+	}
+	clanNo, err := strconv.Atoi(unitID[1:r.digits()])
+	if err != nil {
+		return "", &UnitIDError{UnitID: unitID, Reason: err.Error()}
+	}
+	return fmt.Sprintf("%03d", clanNo), nil
+}
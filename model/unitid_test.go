@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestUnitIDRules_ClanID(t *testing.T) {
+	five := model.UnitIDRules{ClanDigits: 5}
+	testCases := []struct {
+		rules  model.UnitIDRules
+		unitID string
+		clanID string
+		ok     bool
+	}{
+		{rules: model.DefaultUnitIDRules, unitID: "0987", clanID: "987", ok: true},
+		{rules: model.DefaultUnitIDRules, unitID: "1987e1", clanID: "987", ok: true},
+		{rules: model.DefaultUnitIDRules, unitID: "0038s2", clanID: "038", ok: true},
+		{rules: model.UnitIDRules{}, unitID: "0138c1", clanID: "138", ok: true},
+		{rules: model.DefaultUnitIDRules, unitID: "01234"},
+		{rules: model.DefaultUnitIDRules, unitID: "098"},
+		{rules: model.DefaultUnitIDRules, unitID: "0987x1"},
+		{rules: model.DefaultUnitIDRules, unitID: "0987e0"},
+		{rules: model.DefaultUnitIDRules, unitID: "09a7"},
+		{rules: five, unitID: "01234", clanID: "1234", ok: true},
+		{rules: five, unitID: "11234f3", clanID: "1234", ok: true},
+		{rules: five, unitID: "00987", clanID: "987", ok: true},
+		{rules: five, unitID: "0987"},
+		{rules: five, unitID: "0987e1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.unitID, func(t *testing.T) {
+			clanID, err := tc.rules.ClanID(tc.unitID)
+			if tc.ok != (err == nil) {
+				t.Fatalf("ok: expected %v, got error %v", tc.ok, err)
+			}
+			if clanID != tc.clanID {
+				t.Errorf("expected %q, got %q", tc.clanID, clanID)
+			}
+			var uerr *model.UnitIDError
+			if err != nil && !errors.As(err, &uerr) {
+				t.Errorf("expected *UnitIDError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestUnitIDRules_Kind(t *testing.T) {
+	five := model.UnitIDRules{ClanDigits: 5}
+	if got := five.Kind("01234e1"); got != model.UnitKindElement {
+		t.Errorf("expected %q, got %q", model.UnitKindElement, got)
+	}
+	if got := five.Kind("1234e1"); got != model.UnitKindUnknown {
+		t.Errorf("expected %q, got %q", model.UnitKindUnknown, got)
+	}
+}
+
+func TestUnitIDRules_ValidateClan(t *testing.T) {
+	five := model.UnitIDRules{ClanDigits: 5}
+	testCases := []struct {
+		rules model.UnitIDRules
+		clan  string
+		ok    bool
+	}{
+		{rules: model.DefaultUnitIDRules, clan: "0987", ok: true},
+		{rules: model.DefaultUnitIDRules, clan: "1987"},
+		{rules: model.DefaultUnitIDRules, clan: "0987e1"},
+		{rules: model.DefaultUnitIDRules, clan: "01987"},
+		{rules: five, clan: "01987", ok: true},
+		{rules: five, clan: "0987"},
+		{rules: five, clan: ""},
+	}
+	for _, tc := range testCases {
+		if err := tc.rules.ValidateClan(tc.clan); tc.ok != (err == nil) {
+			t.Errorf("%d digits: %q: expected ok %v, got %v", tc.rules.ClanDigits, tc.clan, tc.ok, err)
+		}
+	}
+}
+
+func TestUnitIDRules_CheckClan(t *testing.T) {
+	rules := model.DefaultUnitIDRules
+	if err := rules.CheckClan("0987", []string{"0987", "1987e1", "0987c2", "bogus"}); err != nil {
+		t.Errorf("own units: got %v", err)
+	}
+
+	err := rules.CheckClan("0987", []string{"0987", "0512e1", "0512", "1138"})
+	var cerr *model.ClanMismatchError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected *ClanMismatchError, got %v", err)
+	}
+	if want := "report filed under clan 0987 has units of clan 0138, 0512: 0512, 0512e1, 1138"; err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
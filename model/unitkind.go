@@ -0,0 +1,22 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+// UnitKind is the type of unit, derived from the unit ID.
+type UnitKind string
+
+const (
+	UnitKindTribe    UnitKind = "tribe"    // "0987" or "1987"
+	UnitKindCourier  UnitKind = "courier"  // "0987c1"
+	UnitKindElement  UnitKind = "element"  // "0987e1"
+	UnitKindFleet    UnitKind = "fleet"    // "0987f1"
+	UnitKindGarrison UnitKind = "garrison" // "0987g1"
+	UnitKindScout    UnitKind = "scout"    // "0987s1"
+	UnitKindUnknown  UnitKind = "unknown"
+)
+
+// UnitKindOf returns the kind of unit for a unit ID in a standard game.
+// Tribes are four digits; other units add a type letter and a digit 1..9.
+func UnitKindOf(unitID string) UnitKind {
+	return DefaultUnitIDRules.Kind(unitID)
+}
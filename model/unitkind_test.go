@@ -0,0 +1,37 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestUnitKindOf(t *testing.T) {
+	testCases := []struct {
+		unitID string
+		kind   model.UnitKind
+	}{
+		{unitID: "0987", kind: model.UnitKindTribe},
+		{unitID: "1987", kind: model.UnitKindTribe},
+		{unitID: "0987c1", kind: model.UnitKindCourier},
+		{unitID: "0987e9", kind: model.UnitKindElement},
+		{unitID: "0987f2", kind: model.UnitKindFleet},
+		{unitID: "0987g1", kind: model.UnitKindGarrison},
+		{unitID: "0987s3", kind: model.UnitKindScout},
+		{unitID: "0987x1", kind: model.UnitKindUnknown},
+		{unitID: "0987c0", kind: model.UnitKindUnknown},
+		{unitID: "0987c", kind: model.UnitKindUnknown},
+		{unitID: "09a7", kind: model.UnitKindUnknown},
+		{unitID: "", kind: model.UnitKindUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.unitID, func(t *testing.T) {
+			if got := model.UnitKindOf(tc.unitID); got != tc.kind {
+				t.Errorf("expected %q, got %q", tc.kind, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/mdhender/tnrpt/direction"
+)
+
+// WorldRules describes the shape of a game's world map.
+//
+// The world is a grid of maps labeled by letter, "AA" in the upper-left.
+// GridRows and GridCols are the number of letters used for grid rows and
+// columns; a world of 16 by 26 maps runs from "AA" to "PZ". Zero means 26.
+//
+// With WrapCols, moving east off the last grid column enters the first one,
+// and moving west off the first enters the last; WrapRows does the same for
+// north and south. Without them the edge of the world can't be crossed.
+type WorldRules struct {
+	GridRows int
+	GridCols int
+	WrapRows bool
+	WrapCols bool
+}
+
+// DefaultWorldRules are the rules for a standard game: 26 by 26 maps and no wrapping.
+var DefaultWorldRules = WorldRules{GridRows: 26, GridCols: 26}
+
+// Rows returns the number of grid rows, 1..26.
+func (w WorldRules) Rows() int {
+	if w.GridRows <= 0 || w.GridRows > 26 {
+		return DefaultWorldRules.GridRows
+	}
+	return w.GridRows
+}
+
+// Cols returns the number of grid columns, 1..26.
+func (w WorldRules) Cols() int {
+	if w.GridCols <= 0 || w.GridCols > 26 {
+		return DefaultWorldRules.GridCols
+	}
+	return w.GridCols
+}
+
+// Validate returns an error if the grid size is out of range.
+// Zero sizes are valid and mean 26.
+func (w WorldRules) Validate() error {
+	if w.GridRows < 0 || w.GridRows > 26 {
+		return fmt.Errorf("grid rows %d: must be 1..26", w.GridRows)
+	} else if w.GridCols < 0 || w.GridCols > 26 {
+		return fmt.Errorf("grid columns %d: must be 1..26", w.GridCols)
+	}
+	return nil
+}
+
+// LastGrid returns the grid in the lower-right corner of the world, e.g. "ZZ".
+func (w WorldRules) LastGrid() string {
+	return string([]byte{'A' + byte(w.Rows()-1), 'A' + byte(w.Cols()-1)})
+}
+
+// Contains returns true if the coordinate is unknown, obscured, or on one of the world's maps.
+func (w WorldRules) Contains(c TNCoord) bool {
+	grid, _, _, err := c.Parse()
+	if err != nil {
+		return false
+	} else if grid == "" || c.IsObscured() {
+		return true
+	}
+	return int(grid[0]-'A') < w.Rows() && int(grid[1]-'A') < w.Cols()
+}
+
+// Neighbor returns the coordinate of the hex adjacent to c in the given direction.
+// Moving off the edge of a map crosses into the neighboring map on the grid,
+// and moving off the edge of the world wraps if the rules allow it.
+// Obscured coordinates can't cross a map edge since the grid isn't known.
+func (w WorldRules) Neighbor(c TNCoord, dir direction.Direction_e) (TNCoord, error) {
+	grid, col, row, err := c.Parse()
+	if err != nil {
+		return "", err
+	} else if grid == "" {
+		return "", fmt.Errorf("coord %q: location is unknown", c)
+	} else if !w.Contains(c) {
+		return "", fmt.Errorf("coord %q: outside the world (AA to %s)", c, w.LastGrid())
+	}
+
+	// global 0-based column and row; odd columns are shoved down
+	var gridRow, gridCol int
+	if !c.IsObscured() {
+		gridRow, gridCol = int(grid[0]-'A'), int(grid[1]-'A')
+	}
+	x, y := gridCol*tnColumnsPerGrid+col-1, gridRow*tnRowsPerGrid+row-1
+	oddColumn := x%2 == 1
+	switch dir {
+	case direction.North:
+		y--
+	case direction.South:
+		y++
+	case direction.NorthEast, direction.SouthEast, direction.SouthWest, direction.NorthWest:
+		if dir == direction.NorthEast || dir == direction.SouthEast {
+			x++
+		} else {
+			x--
+		}
+		if oddColumn && (dir == direction.SouthEast || dir == direction.SouthWest) {
+			y++
+		} else if !oddColumn && (dir == direction.NorthEast || dir == direction.NorthWest) {
+			y--
+		}
+	default:
+		return "", fmt.Errorf("coord %q: invalid direction %v", c, dir)
+	}
+
+	if c.IsObscured() {
+		if x < 0 || y < 0 || x >= tnColumnsPerGrid || y >= tnRowsPerGrid {
+			return "", fmt.Errorf("coord %q: %v: obscured location can't leave its map", c, dir)
+		}
+		return NewTNCoord("##", x+1, y+1), nil
+	}
+
+	// maps are an even number of columns wide, so wrapping keeps the column parity
+	width, height := w.Cols()*tnColumnsPerGrid, w.Rows()*tnRowsPerGrid
+	if w.WrapCols {
+		x = (x + width) % width
+	}
+	if w.WrapRows {
+		y = (y + height) % height
+	}
+	if x < 0 || y < 0 || x >= width || y >= height {
+		return "", fmt.Errorf("coord %q: %v: off the edge of the map", c, dir)
+	}
+	gridRow, gridCol = y/tnRowsPerGrid, x/tnColumnsPerGrid
+	col, row = x%tnColumnsPerGrid+1, y%tnRowsPerGrid+1
+	return NewTNCoord(string([]byte{'A' + byte(gridRow), 'A' + byte(gridCol)}), col, row), nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/model"
+)
+
+func TestWorldRules_Neighbor(t *testing.T) {
+	small := model.WorldRules{GridRows: 2, GridCols: 3}
+	wrapped := model.WorldRules{GridRows: 2, GridCols: 3, WrapRows: true, WrapCols: true}
+	for _, tc := range []struct {
+		name  string
+		world model.WorldRules
+		from  model.TNCoord
+		dir   direction.Direction_e
+		want  model.TNCoord // empty if the step is an error
+	}{
+		{"default", model.DefaultWorldRules, "QQ 1510", direction.North, "QQ 1509"},
+		{"zero value is default", model.WorldRules{}, "ZZ 3020", direction.South, "ZZ 3021"},
+		{"inside a small world", small, "AB 3010", direction.NorthEast, "AC 0110"},
+		{"east edge", small, "AC 3010", direction.NorthEast, ""},
+		{"south edge", small, "BA 1021", direction.South, ""},
+		{"outside a small world", small, "AD 0101", direction.South, ""},
+		{"east edge wraps", wrapped, "AC 3010", direction.NorthEast, "AA 0110"},
+		{"west edge wraps", wrapped, "AA 0105", direction.NorthWest, "AC 3004"},
+		{"north edge wraps", wrapped, "AA 1501", direction.North, "BA 1521"},
+		{"south edge wraps", wrapped, "BB 1521", direction.South, "AB 1501"},
+		{"corner wraps", wrapped, "BC 3021", direction.SouthEast, "AA 0101"},
+		{"obscured doesn't wrap", wrapped, "## 0101", direction.North, ""},
+	} {
+		got, err := tc.world.Neighbor(tc.from, tc.dir)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("%s: %s %s: expected error, got %q", tc.name, tc.from, tc.dir, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%s: %s %s: expected %q, got %q, %v", tc.name, tc.from, tc.dir, tc.want, got, err)
+		}
+	}
+}
+
+func TestWorldRules_Validate(t *testing.T) {
+	if err := (model.WorldRules{}).Validate(); err != nil {
+		t.Errorf("zero value: %v", err)
+	}
+	if err := (model.WorldRules{GridRows: 27}).Validate(); err == nil {
+		t.Errorf("27 rows: expected error")
+	}
+	if got := (model.WorldRules{GridRows: 16, GridCols: 26}).LastGrid(); got != "PZ" {
+		t.Errorf("last grid: expected %q, got %q", "PZ", got)
+	}
+}
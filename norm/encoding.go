@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package norm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is the encoding ToUTF8 found a text report in.
+type Encoding string
+
+const (
+	EncodingUTF8        Encoding = "UTF-8"
+	EncodingUTF8BOM     Encoding = "UTF-8 with BOM"
+	EncodingUTF16LE     Encoding = "UTF-16LE"
+	EncodingUTF16BE     Encoding = "UTF-16BE"
+	EncodingWindows1252 Encoding = "Windows-1252"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// ToUTF8 returns a text report as UTF-8, and the encoding it was in. Reports
+// saved from old versions of Word arrive as UTF-16 with a byte order mark,
+// or as Windows-1252. Left as they are, UTF-16 text has a NUL between each
+// letter, so the splitter (pipelines/parsers/report) matches none of its
+// section lines, and Windows-1252 bytes aren't valid UTF-8, so the bistre
+// parser's grammar fails on any line that has them. UTF-16 is only
+// recognized by its BOM; text that isn't valid UTF-8 is taken to be
+// Windows-1252. UTF-8 text is returned as it is, less any BOM.
+func ToUTF8(data []byte) ([]byte, Encoding) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return data[len(bomUTF8):], EncodingUTF8BOM
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], binary.LittleEndian), EncodingUTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], binary.BigEndian), EncodingUTF16BE
+	case utf8.Valid(data):
+		return data, EncodingUTF8
+	}
+	return decodeWindows1252(data), EncodingWindows1252
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) []byte {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	buf := make([]byte, 0, len(data))
+	for _, r := range utf16.Decode(units) {
+		buf = utf8.AppendRune(buf, r)
+	}
+	if len(data)%2 != 0 {
+		buf = utf8.AppendRune(buf, utf8.RuneError) // a truncated code unit
+	}
+	return buf
+}
+
+// windows1252 maps bytes 0x80 to 0x9F, where Windows-1252 differs from
+// Latin-1. The five bytes it leaves undefined map to the C1 controls, as
+// browsers do.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+func decodeWindows1252(data []byte) []byte {
+	buf := make([]byte, 0, len(data)+len(data)/8)
+	for _, b := range data {
+		switch {
+		case b < 0x80:
+			buf = append(buf, b)
+		case b < 0xA0:
+			buf = utf8.AppendRune(buf, windows1252[b-0x80])
+		default:
+			buf = utf8.AppendRune(buf, rune(b))
+		}
+	}
+	return buf
+}
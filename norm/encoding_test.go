@@ -0,0 +1,33 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package norm_test
+
+import (
+	"testing"
+
+	"github.com/mdhender/tnrpt/norm"
+)
+
+func TestToUTF8(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input []byte
+		want  string
+		enc   norm.Encoding
+	}{
+		{"ascii", []byte("Tribe 0987, , Current Hex = QQ 1315\r\n"), "Tribe 0987, , Current Hex = QQ 1315\r\n", norm.EncodingUTF8},
+		{"utf-8", []byte("Tribe 0987 — Ur\n"), "Tribe 0987 — Ur\n", norm.EncodingUTF8},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFTribe 0987\n"), "Tribe 0987\n", norm.EncodingUTF8BOM},
+		{"utf-16le", []byte("\xFF\xFET\x00r\x00\x14\x20\r\x00\n\x00"), "Tr—\r\n", norm.EncodingUTF16LE},
+		{"utf-16be", []byte("\xFE\xFF\x00T\x00r\xD8\x3D\xDE\x00"), "Tr\U0001F600", norm.EncodingUTF16BE},
+		{"utf-16 odd length", []byte("\xFF\xFET\x00r"), "T�", norm.EncodingUTF16LE},
+		{"windows-1252", []byte("\x93Ur\x94 \x96 caf\xE9 \x80\x81"), "“Ur” – café €\u0081", norm.EncodingWindows1252},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, enc := norm.ToUTF8(tc.input)
+			if string(got) != tc.want || enc != tc.enc {
+				t.Errorf("got %q, %s; want %q, %s", got, enc, tc.want, tc.enc)
+			}
+		})
+	}
+}
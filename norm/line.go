@@ -5,6 +5,8 @@ package norm
 import (
 	"html"
 	"regexp"
+
+	"github.com/mdhender/tnrpt/model"
 )
 
 var (
@@ -24,11 +26,11 @@ var (
 	}
 
 	// replacement expressions
-	reBackslashCommaUnit = regexp.MustCompile(`\\+ *, *(\d{4}(?:[cefg]\d)?)`)
+	reBackslashCommaUnit = regexp.MustCompile(`\\+ *, *(` + model.TribePattern + `(?:[cefg]\d)?)`)
 	reBackslashDash      = regexp.MustCompile(`\\+-+ *`)
-	reBackslashUnit      = regexp.MustCompile(`\\+ *(\d{4}(?:[cefg]\d)?)`)
-	reDashSpacesUnit     = regexp.MustCompile(`- *(\d{4}(?:[cefg]\d)?)`)
-	reDirectionUnit      = regexp.MustCompile(`\b(NE|SE|SW|NW|N|S) +(\d{4}(?:[cefg]\d)?)`)
+	reBackslashUnit      = regexp.MustCompile(`\\+ *(` + model.TribePattern + `(?:[cefg]\d)?)`)
+	reDashSpacesUnit     = regexp.MustCompile(`- *(` + model.TribePattern + `(?:[cefg]\d)?)`)
+	reDirectionUnit      = regexp.MustCompile(`\b(NE|SE|SW|NW|N|S) +(` + model.TribePattern + `(?:[cefg]\d)?)`)
 )
 
 func init() {
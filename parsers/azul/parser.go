@@ -946,6 +946,7 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 			}
 			m.Advance = v.Direction
 			m.Result = results.Failed
+			m.FailPhrase = string(subStep)
 			m.Report.MergeBorders(&Border_t{
 				Direction: v.Direction,
 				Edge:      v.Edge,
@@ -978,6 +979,7 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 			}
 			m.Advance = v.Direction
 			m.Result = results.Failed
+			m.FailPhrase = string(subStep)
 			// fleet movements can end up exhausted in an unknown direction and with no terrain.
 			// if we were smart enough to look back at the wind direction, we could use that,
 			// but we're not, and we still wouldn't know what to do with the terrain.
@@ -1014,9 +1016,11 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 		case InsufficientCapacity_t: // ignore
 			//log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
 			m.Result, m.Still = results.Failed, true
+			m.FailPhrase = string(subStep)
 		case Longhouse_t: // ignore
 		case MissingEdge_t:
 			m.Result, m.Still, m.Advance = results.Failed, true, v.Direction
+			m.FailPhrase = string(subStep)
 		case []*Neighbor_t:
 			if m.Result == results.Unknown {
 				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
@@ -1044,6 +1048,7 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 			}
 			m.Advance = v.Direction
 			m.Result = results.Failed
+			m.FailPhrase = string(subStep)
 			m.Report.MergeBorders(&Border_t{
 				Direction: v.Direction,
 				Terrain:   v.Terrain,
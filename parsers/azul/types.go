@@ -139,6 +139,9 @@ type Move_t struct {
 	// Result should be failed, succeeded, or vanished
 	Result results.Result_e
 
+	// FailPhrase is the GM's text for why the step failed, e.g. "No Ford on River to N of HEX".
+	FailPhrase string
+
 	Report *Report_t // all observations made by the unit at the end of this move
 
 	LineNo int
@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Package lint checks parsed turn reports for things that parse but are
+// probably wrong, like a unit section without a status line or a scout that
+// went further than scouts can. Each check is a Rule; a Linter runs a set of
+// them with the settings a game has for them (see model.LintConfig).
+//
+// Rules are Go values, so adding one is a matter of implementing Rule and
+// passing it to New along with, or instead of, the Builtin rules.
+package lint
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// Report is what the rules check: a parsed report, the text it was parsed
+// from, and the shape of the game's world.
+type Report struct {
+	RX    *model.ReportX
+	Text  []byte // as given to the parser; rules that need it skip reports without it
+	World model.WorldRules
+}
+
+// Lines returns the report text split into lines; Lines()[0] is line 1.
+func (r *Report) Lines() [][]byte {
+	if r.Text == nil {
+		return nil
+	}
+	return bytes.Split(bytes.TrimSuffix(r.Text, []byte{'\n'}), []byte{'\n'})
+}
+
+// RuleInfo describes a rule and its defaults.
+type RuleInfo struct {
+	Name        string             `json:"name"` // e.g. "scout.max-moves"
+	Description string             `json:"description"`
+	Severity    model.LintSeverity `json:"severity"`
+	Limit       int                `json:"limit,omitempty"` // 0 if the rule has no limit
+}
+
+// Rule is one check. Check returns the problems found in the report, leaving
+// each diagnostic's Rule and Severity for the Linter to fill in. The limit is
+// the game's setting, or the rule's default if the game doesn't set one.
+type Rule interface {
+	Info() RuleInfo
+	Check(r *Report, limit int) []model.LintDiagnostic
+}
+
+// Linter runs a set of rules.
+type Linter struct {
+	rules []Rule
+}
+
+// New returns a Linter that runs the rules in order.
+func New(rules ...Rule) *Linter {
+	return &Linter{rules: rules}
+}
+
+// Default returns a Linter that runs the Builtin rules.
+func Default() *Linter {
+	return New(Builtin()...)
+}
+
+// Rules returns the rules the Linter runs.
+func (l *Linter) Rules() []Rule {
+	return l.rules
+}
+
+// Rule returns the rule with the name, or nil if the Linter doesn't have one.
+func (l *Linter) Rule(name string) Rule {
+	for _, rule := range l.rules {
+		if rule.Info().Name == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Run checks the report with every rule the config doesn't turn off and
+// returns the diagnostics ordered by line, then unit and act. Diagnostics
+// without a line come first.
+func (l *Linter) Run(r *Report, cfg model.LintConfig) []model.LintDiagnostic {
+	var diags []model.LintDiagnostic
+	if r == nil || r.RX == nil {
+		return nil
+	}
+	for _, rule := range l.rules {
+		info := rule.Info()
+		severity, limit := info.Severity, info.Limit
+		if s, ok := cfg[info.Name]; ok {
+			if !s.Enabled {
+				continue
+			}
+			if s.Severity != "" {
+				severity = s.Severity
+			}
+			if s.Limit > 0 {
+				limit = s.Limit
+			}
+		}
+		for _, d := range rule.Check(r, limit) {
+			d.Rule, d.Severity = info.Name, severity
+			diags = append(diags, d)
+		}
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		} else if a.UnitID != b.UnitID {
+			return a.UnitID < b.UnitID
+		}
+		return a.ActSeq < b.ActSeq
+	})
+	return diags
+}
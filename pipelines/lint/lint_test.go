@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package lint_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/lint"
+)
+
+// testReport has a tribe with a status line, a courier without one, and a
+// scout that advances nine times, ending past the edge of a 20 by 20 world.
+func testReport() *lint.Report {
+	const text = "Tribe 0987, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)\n" +
+		"Current Turn 900-01 (#1), Winter, FINE\n" +
+		"0987 Status: PRAIRIE\n" +
+		"Courier 0987c1, , Current Hex = UA 1010, (Previous Hex = QQ 1010)\n" +
+		"Current Turn 900-01 (#1), Winter, FINE\n" +
+		"Scout 1:Scout N-PR\\N-PR\\N-PR\\N-PR\\N-PR\\N-PR\\N-PR\\N-PR\\N-PR\n"
+	adv := func(seq int) *model.Step {
+		return &model.Step{Seq: seq, Kind: model.StepKindAdv, Dir: "N", Ok: true}
+	}
+	scout := &model.Act{Seq: 1, Kind: model.ActKindScout, Src: &model.SrcRef{Line: 6}}
+	for i := 1; i <= 9; i++ {
+		scout.Steps = append(scout.Steps, adv(i))
+	}
+	rx := &model.ReportX{Units: []*model.UnitX{
+		{UnitID: "0987c1", StartTN: "QQ 1010", EndTN: "UA 1010", Acts: []*model.Act{scout}, Src: &model.SrcRef{Line: 4}},
+		{UnitID: "0987", StartTN: "QQ 1010", EndTN: "QQ 1010", Src: &model.SrcRef{Line: 1}},
+	}}
+	return &lint.Report{RX: rx, Text: []byte(text), World: model.WorldRules{GridRows: 20, GridCols: 20}}
+}
+
+func TestLinterRun(t *testing.T) {
+	got := lint.Default().Run(testReport(), nil)
+	want := []string{
+		"line 4: warning: unit.missing-status: 0987c1 has no status line",
+		"line 4: error: coords.outside-world: 0987c1 current hex UA 1010 is outside the world (AA to TT)",
+		"line 6: warning: scout.max-moves: 0987c1 act 1 advanced 9 times; scouts can advance 8",
+	}
+	var lines []string
+	for _, d := range got {
+		lines = append(lines, d.String())
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Run =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLinterRunConfig(t *testing.T) {
+	cfg := model.LintConfig{
+		"unit.missing-status":  {Rule: "unit.missing-status", Enabled: false},
+		"scout.max-moves":      {Rule: "scout.max-moves", Enabled: true, Severity: model.LintError, Limit: 9},
+		"coords.outside-world": {Rule: "coords.outside-world", Enabled: true, Severity: model.LintInfo},
+	}
+	got := lint.Default().Run(testReport(), cfg)
+	if len(got) != 1 || got[0].Rule != "coords.outside-world" || got[0].Severity != model.LintInfo {
+		t.Fatalf("Run = %v, want only coords.outside-world as info", got)
+	}
+
+	cfg["scout.max-moves"] = model.LintSetting{Rule: "scout.max-moves", Enabled: true, Severity: model.LintError, Limit: 4}
+	got = lint.Default().Run(testReport(), cfg)
+	if len(got) != 2 || got[1].Severity != model.LintError || !strings.HasSuffix(got[1].Message, "scouts can advance 4") {
+		t.Errorf("Run = %v, want scout.max-moves as an error with limit 4", got)
+	}
+}
+
+func TestLinterRule(t *testing.T) {
+	l := lint.Default()
+	if rule := l.Rule("scout.max-moves"); rule == nil || rule.Info().Limit != 8 {
+		t.Errorf("Rule(scout.max-moves) = %v", rule)
+	}
+	if rule := l.Rule("no.such-rule"); rule != nil {
+		t.Errorf("Rule(no.such-rule) = %v, want nil", rule)
+	}
+	// the status check needs the text; the others don't
+	r := testReport()
+	r.Text = nil
+	if got := l.Run(r, nil); len(got) != 2 {
+		t.Errorf("Run without text = %v, want 2 diagnostics", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// Builtin returns the rules that ship with tnrpt.
+func Builtin() []Rule {
+	return []Rule{MissingStatus{}, ScoutMoves{}, OutsideWorld{}}
+}
+
+// MissingStatus flags unit sections without a status line. Every unit in a
+// turn report gets one, so a missing line usually means the section was cut
+// short when the report was copied.
+type MissingStatus struct{}
+
+func (MissingStatus) Info() RuleInfo {
+	return RuleInfo{
+		Name:        "unit.missing-status",
+		Description: "A unit's section has no status line.",
+		Severity:    model.LintWarning,
+	}
+}
+
+func (MissingStatus) Check(r *Report, _ int) []model.LintDiagnostic {
+	lines := r.Lines()
+	if lines == nil {
+		return nil
+	}
+
+	// a unit's section runs until the next unit's header
+	var headers []int
+	for _, u := range r.RX.Units {
+		if u.Src != nil && u.Src.Line > 0 {
+			headers = append(headers, u.Src.Line)
+		}
+	}
+	sort.Ints(headers)
+
+	var diags []model.LintDiagnostic
+	for _, u := range r.RX.Units {
+		if u.Src == nil || u.Src.Line <= 0 || u.Src.Line > len(lines) {
+			continue
+		}
+		end := len(lines)
+		if i := sort.SearchInts(headers, u.Src.Line+1); i < len(headers) {
+			end = headers[i] - 1
+		}
+		prefix := []byte(u.UnitID + " Status: ")
+		found := false
+		for _, line := range lines[u.Src.Line-1 : end] {
+			if bytes.HasPrefix(line, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			diags = append(diags, model.LintDiagnostic{
+				UnitID:  u.UnitID,
+				Line:    u.Src.Line,
+				Message: fmt.Sprintf("%s has no status line", u.UnitID),
+			})
+		}
+	}
+	return diags
+}
+
+// ScoutMoves flags scouts that advanced more times than a scout can in a
+// turn. The limit is the most advances, failed ones included.
+type ScoutMoves struct{}
+
+func (ScoutMoves) Info() RuleInfo {
+	return RuleInfo{
+		Name:        "scout.max-moves",
+		Description: "A scout advanced more times than scouts can in a turn.",
+		Severity:    model.LintWarning,
+		Limit:       8,
+	}
+}
+
+func (ScoutMoves) Check(r *Report, limit int) []model.LintDiagnostic {
+	var diags []model.LintDiagnostic
+	for _, u := range r.RX.Units {
+		for _, act := range u.Acts {
+			if act.Kind != model.ActKindScout {
+				continue
+			}
+			n := 0
+			for _, st := range act.Steps {
+				if st.Kind == model.StepKindAdv {
+					n++
+				}
+			}
+			if n <= limit {
+				continue
+			}
+			d := model.LintDiagnostic{
+				UnitID:  u.UnitID,
+				ActSeq:  act.Seq,
+				Message: fmt.Sprintf("%s act %d advanced %d times; scouts can advance %d", u.UnitID, act.Seq, n, limit),
+			}
+			if act.Src != nil {
+				d.Line = act.Src.Line
+			}
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// OutsideWorld flags coordinates that aren't on the game's world map: a
+// unit's current and previous hexes and the destination of a goto.
+type OutsideWorld struct{}
+
+func (OutsideWorld) Info() RuleInfo {
+	return RuleInfo{
+		Name:        "coords.outside-world",
+		Description: "A coordinate in the report is not on the game's world map.",
+		Severity:    model.LintError,
+	}
+}
+
+func (OutsideWorld) Check(r *Report, _ int) []model.LintDiagnostic {
+	var diags []model.LintDiagnostic
+	check := func(u *model.UnitX, actSeq int, src *model.SrcRef, what string, c model.TNCoord) {
+		var msg string
+		if err := c.Validate(); err != nil {
+			msg = fmt.Sprintf("%s %s %q is not a coordinate", u.UnitID, what, c)
+		} else if !r.World.Contains(c) {
+			msg = fmt.Sprintf("%s %s %s is outside the world (AA to %s)", u.UnitID, what, c, r.World.LastGrid())
+		} else {
+			return
+		}
+		d := model.LintDiagnostic{UnitID: u.UnitID, ActSeq: actSeq, Message: msg}
+		if src != nil {
+			d.Line = src.Line
+		}
+		diags = append(diags, d)
+	}
+	for _, u := range r.RX.Units {
+		check(u, 0, u.Src, "previous hex", u.StartTN)
+		check(u, 0, u.Src, "current hex", u.EndTN)
+		for _, act := range u.Acts {
+			if act.Kind == model.ActKindGoto {
+				check(u, act.Seq, act.Src, "goto destination", act.DestTN)
+			}
+		}
+	}
+	return diags
+}
@@ -40,6 +40,8 @@ func (d Date_t) IsZero() bool {
 type TurnInfo_t struct {
 	CurrentTurn Date_t
 	NextTurn    Date_t
+	Season      string
+	Weather     string
 }
 
 func bdup(src []byte) []byte {
@@ -59,24 +61,24 @@ var g = &grammar{
 	rules: []*rule{
 		{
 			name: "Noop",
-			pos:  position{line: 60, col: 1, offset: 935},
+			pos:  position{line: 62, col: 1, offset: 981},
 			expr: &actionExpr{
-				pos: position{line: 60, col: 9, offset: 943},
+				pos: position{line: 62, col: 9, offset: 989},
 				run: (*parser).callonNoop1,
 				expr: &ruleRefExpr{
-					pos:  position{line: 60, col: 9, offset: 943},
+					pos:  position{line: 62, col: 9, offset: 989},
 					name: "EOF",
 				},
 			},
 		},
 		{
 			name: "AdminNote",
-			pos:  position{line: 64, col: 1, offset: 973},
+			pos:  position{line: 66, col: 1, offset: 1019},
 			expr: &actionExpr{
-				pos: position{line: 64, col: 14, offset: 986},
+				pos: position{line: 66, col: 14, offset: 1032},
 				run: (*parser).callonAdminNote1,
 				expr: &litMatcher{
-					pos:        position{line: 64, col: 14, offset: 986},
+					pos:        position{line: 66, col: 14, offset: 1032},
 					val:        "Map Testing",
 					ignoreCase: false,
 					want:       "\"Map Testing\"",
@@ -85,19 +87,19 @@ var g = &grammar{
 		},
 		{
 			name: "MiscNote",
-			pos:  position{line: 68, col: 1, offset: 1026},
+			pos:  position{line: 70, col: 1, offset: 1072},
 			expr: &actionExpr{
-				pos: position{line: 68, col: 13, offset: 1038},
+				pos: position{line: 70, col: 13, offset: 1084},
 				run: (*parser).callonMiscNote1,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 68, col: 13, offset: 1038},
+					pos: position{line: 70, col: 13, offset: 1084},
 					expr: &seqExpr{
-						pos: position{line: 68, col: 14, offset: 1039},
+						pos: position{line: 70, col: 14, offset: 1085},
 						exprs: []any{
 							&notExpr{
-								pos: position{line: 68, col: 14, offset: 1039},
+								pos: position{line: 70, col: 14, offset: 1085},
 								expr: &charClassMatcher{
-									pos:        position{line: 68, col: 15, offset: 1040},
+									pos:        position{line: 70, col: 15, offset: 1086},
 									val:        "[\\n\\r,]",
 									chars:      []rune{'\n', '\r', ','},
 									ignoreCase: false,
@@ -114,45 +116,45 @@ var g = &grammar{
 		},
 		{
 			name: "CrowsNestObservation",
-			pos:  position{line: 73, col: 1, offset: 1134},
+			pos:  position{line: 75, col: 1, offset: 1180},
 			expr: &actionExpr{
-				pos: position{line: 73, col: 25, offset: 1158},
+				pos: position{line: 75, col: 25, offset: 1204},
 				run: (*parser).callonCrowsNestObservation1,
 				expr: &seqExpr{
-					pos: position{line: 73, col: 25, offset: 1158},
+					pos: position{line: 75, col: 25, offset: 1204},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 73, col: 25, offset: 1158},
+							pos:   position{line: 75, col: 25, offset: 1204},
 							label: "cs",
 							expr: &ruleRefExpr{
-								pos:  position{line: 73, col: 28, offset: 1161},
+								pos:  position{line: 75, col: 28, offset: 1207},
 								name: "CROWSIGHTING",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 73, col: 41, offset: 1174},
+							pos:  position{line: 75, col: 41, offset: 1220},
 							name: "SP",
 						},
 						&litMatcher{
-							pos:        position{line: 73, col: 44, offset: 1177},
+							pos:        position{line: 75, col: 44, offset: 1223},
 							val:        "-",
 							ignoreCase: false,
 							want:       "\"-\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 73, col: 48, offset: 1181},
+							pos:  position{line: 75, col: 48, offset: 1227},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 73, col: 51, offset: 1184},
+							pos:   position{line: 75, col: 51, offset: 1230},
 							label: "cp",
 							expr: &ruleRefExpr{
-								pos:  position{line: 73, col: 54, offset: 1187},
+								pos:  position{line: 75, col: 54, offset: 1233},
 								name: "COMPASSPOINT",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 73, col: 67, offset: 1200},
+							pos:  position{line: 75, col: 67, offset: 1246},
 							name: "EOF",
 						},
 					},
@@ -161,30 +163,30 @@ var g = &grammar{
 		},
 		{
 			name: "DeckObservation",
-			pos:  position{line: 80, col: 1, offset: 1322},
+			pos:  position{line: 82, col: 1, offset: 1368},
 			expr: &actionExpr{
-				pos: position{line: 80, col: 20, offset: 1341},
+				pos: position{line: 82, col: 20, offset: 1387},
 				run: (*parser).callonDeckObservation1,
 				expr: &seqExpr{
-					pos: position{line: 80, col: 20, offset: 1341},
+					pos: position{line: 82, col: 20, offset: 1387},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 80, col: 20, offset: 1341},
+							pos:   position{line: 82, col: 20, offset: 1387},
 							label: "d",
 							expr: &ruleRefExpr{
-								pos:  position{line: 80, col: 22, offset: 1343},
+								pos:  position{line: 82, col: 22, offset: 1389},
 								name: "DIRECTION",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 80, col: 32, offset: 1353},
+							pos:  position{line: 82, col: 32, offset: 1399},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 80, col: 35, offset: 1356},
+							pos:   position{line: 82, col: 35, offset: 1402},
 							label: "t",
 							expr: &ruleRefExpr{
-								pos:  position{line: 80, col: 37, offset: 1358},
+								pos:  position{line: 82, col: 37, offset: 1404},
 								name: "TERRAIN_CODE",
 							},
 						},
@@ -194,55 +196,55 @@ var g = &grammar{
 		},
 		{
 			name: "EdgeType",
-			pos:  position{line: 87, col: 1, offset: 1495},
+			pos:  position{line: 89, col: 1, offset: 1541},
 			expr: &choiceExpr{
-				pos: position{line: 87, col: 13, offset: 1507},
+				pos: position{line: 89, col: 13, offset: 1553},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 87, col: 13, offset: 1507},
+						pos: position{line: 89, col: 13, offset: 1553},
 						run: (*parser).callonEdgeType2,
 						expr: &litMatcher{
-							pos:        position{line: 87, col: 13, offset: 1507},
+							pos:        position{line: 89, col: 13, offset: 1553},
 							val:        "Canal",
 							ignoreCase: false,
 							want:       "\"Canal\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 89, col: 5, offset: 1549},
+						pos: position{line: 91, col: 5, offset: 1595},
 						run: (*parser).callonEdgeType4,
 						expr: &litMatcher{
-							pos:        position{line: 89, col: 5, offset: 1549},
+							pos:        position{line: 91, col: 5, offset: 1595},
 							val:        "Ford",
 							ignoreCase: false,
 							want:       "\"Ford\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 91, col: 5, offset: 1589},
+						pos: position{line: 93, col: 5, offset: 1635},
 						run: (*parser).callonEdgeType6,
 						expr: &litMatcher{
-							pos:        position{line: 91, col: 5, offset: 1589},
+							pos:        position{line: 93, col: 5, offset: 1635},
 							val:        "Pass",
 							ignoreCase: false,
 							want:       "\"Pass\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 93, col: 5, offset: 1629},
+						pos: position{line: 95, col: 5, offset: 1675},
 						run: (*parser).callonEdgeType8,
 						expr: &litMatcher{
-							pos:        position{line: 93, col: 5, offset: 1629},
+							pos:        position{line: 95, col: 5, offset: 1675},
 							val:        "River",
 							ignoreCase: false,
 							want:       "\"River\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 95, col: 5, offset: 1671},
+						pos: position{line: 97, col: 5, offset: 1717},
 						run: (*parser).callonEdgeType10,
 						expr: &litMatcher{
-							pos:        position{line: 95, col: 5, offset: 1671},
+							pos:        position{line: 97, col: 5, offset: 1717},
 							val:        "Stone Road",
 							ignoreCase: false,
 							want:       "\"Stone Road\"",
@@ -253,67 +255,67 @@ var g = &grammar{
 		},
 		{
 			name: "FleetMovement",
-			pos:  position{line: 99, col: 1, offset: 1721},
+			pos:  position{line: 101, col: 1, offset: 1767},
 			expr: &actionExpr{
-				pos: position{line: 99, col: 18, offset: 1738},
+				pos: position{line: 101, col: 18, offset: 1784},
 				run: (*parser).callonFleetMovement1,
 				expr: &seqExpr{
-					pos: position{line: 99, col: 18, offset: 1738},
+					pos: position{line: 101, col: 18, offset: 1784},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 99, col: 18, offset: 1738},
+							pos:   position{line: 101, col: 18, offset: 1784},
 							label: "ws",
 							expr: &ruleRefExpr{
-								pos:  position{line: 99, col: 21, offset: 1741},
+								pos:  position{line: 101, col: 21, offset: 1787},
 								name: "WINDSTRENGTH",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 99, col: 34, offset: 1754},
+							pos:  position{line: 101, col: 34, offset: 1800},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 99, col: 37, offset: 1757},
+							pos:   position{line: 101, col: 37, offset: 1803},
 							label: "d",
 							expr: &ruleRefExpr{
-								pos:  position{line: 99, col: 39, offset: 1759},
+								pos:  position{line: 101, col: 39, offset: 1805},
 								name: "DIRECTION",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 99, col: 49, offset: 1769},
+							pos:  position{line: 101, col: 49, offset: 1815},
 							name: "SP",
 						},
 						&litMatcher{
-							pos:        position{line: 99, col: 52, offset: 1772},
+							pos:        position{line: 101, col: 52, offset: 1818},
 							val:        "Fleet Movement:",
 							ignoreCase: false,
 							want:       "\"Fleet Movement:\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 99, col: 70, offset: 1790},
+							pos:  position{line: 101, col: 70, offset: 1836},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 99, col: 72, offset: 1792},
+							pos:        position{line: 101, col: 72, offset: 1838},
 							val:        "Move",
 							ignoreCase: false,
 							want:       "\"Move\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 99, col: 79, offset: 1799},
+							pos:  position{line: 101, col: 79, offset: 1845},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 99, col: 81, offset: 1801},
+							pos:   position{line: 101, col: 81, offset: 1847},
 							label: "results",
 							expr: &ruleRefExpr{
-								pos:  position{line: 99, col: 89, offset: 1809},
+								pos:  position{line: 101, col: 89, offset: 1855},
 								name: "FleetMovementResults",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 99, col: 110, offset: 1830},
+							pos:  position{line: 101, col: 110, offset: 1876},
 							name: "EOF",
 						},
 					},
@@ -322,18 +324,18 @@ var g = &grammar{
 		},
 		{
 			name: "FleetMovementResults",
-			pos:  position{line: 109, col: 1, offset: 2108},
+			pos:  position{line: 111, col: 1, offset: 2154},
 			expr: &choiceExpr{
-				pos: position{line: 109, col: 25, offset: 2132},
+				pos: position{line: 111, col: 25, offset: 2178},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 109, col: 25, offset: 2132},
+						pos: position{line: 111, col: 25, offset: 2178},
 						run: (*parser).callonFleetMovementResults2,
 						expr: &labeledExpr{
-							pos:   position{line: 109, col: 25, offset: 2132},
+							pos:   position{line: 111, col: 25, offset: 2178},
 							label: "results",
 							expr: &litMatcher{
-								pos:        position{line: 109, col: 33, offset: 2140},
+								pos:        position{line: 111, col: 33, offset: 2186},
 								val:        "is not possible with this ship combination",
 								ignoreCase: false,
 								want:       "\"is not possible with this ship combination\"",
@@ -341,13 +343,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 111, col: 5, offset: 2220},
+						pos: position{line: 113, col: 5, offset: 2266},
 						run: (*parser).callonFleetMovementResults5,
 						expr: &labeledExpr{
-							pos:   position{line: 111, col: 5, offset: 2220},
+							pos:   position{line: 113, col: 5, offset: 2266},
 							label: "results",
 							expr: &ruleRefExpr{
-								pos:  position{line: 111, col: 13, offset: 2228},
+								pos:  position{line: 113, col: 13, offset: 2274},
 								name: "ToEOL",
 							},
 						},
@@ -357,42 +359,42 @@ var g = &grammar{
 		},
 		{
 			name: "Location",
-			pos:  position{line: 115, col: 1, offset: 2263},
+			pos:  position{line: 117, col: 1, offset: 2309},
 			expr: &actionExpr{
-				pos: position{line: 115, col: 13, offset: 2275},
+				pos: position{line: 117, col: 13, offset: 2321},
 				run: (*parser).callonLocation1,
 				expr: &seqExpr{
-					pos: position{line: 115, col: 13, offset: 2275},
+					pos: position{line: 117, col: 13, offset: 2321},
 					exprs: []any{
 						&choiceExpr{
-							pos: position{line: 115, col: 14, offset: 2276},
+							pos: position{line: 117, col: 14, offset: 2322},
 							alternatives: []any{
 								&litMatcher{
-									pos:        position{line: 115, col: 14, offset: 2276},
+									pos:        position{line: 117, col: 14, offset: 2322},
 									val:        "Courier",
 									ignoreCase: false,
 									want:       "\"Courier\"",
 								},
 								&litMatcher{
-									pos:        position{line: 115, col: 26, offset: 2288},
+									pos:        position{line: 117, col: 26, offset: 2334},
 									val:        "Element",
 									ignoreCase: false,
 									want:       "\"Element\"",
 								},
 								&litMatcher{
-									pos:        position{line: 115, col: 38, offset: 2300},
+									pos:        position{line: 117, col: 38, offset: 2346},
 									val:        "Fleet",
 									ignoreCase: false,
 									want:       "\"Fleet\"",
 								},
 								&litMatcher{
-									pos:        position{line: 115, col: 48, offset: 2310},
+									pos:        position{line: 117, col: 48, offset: 2356},
 									val:        "Garrison",
 									ignoreCase: false,
 									want:       "\"Garrison\"",
 								},
 								&litMatcher{
-									pos:        position{line: 115, col: 61, offset: 2323},
+									pos:        position{line: 117, col: 61, offset: 2369},
 									val:        "Tribe",
 									ignoreCase: false,
 									want:       "\"Tribe\"",
@@ -400,102 +402,102 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 115, col: 70, offset: 2332},
+							pos:  position{line: 117, col: 70, offset: 2378},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 115, col: 73, offset: 2335},
+							pos:   position{line: 117, col: 73, offset: 2381},
 							label: "u",
 							expr: &ruleRefExpr{
-								pos:  position{line: 115, col: 75, offset: 2337},
+								pos:  position{line: 117, col: 75, offset: 2383},
 								name: "UNIT_ID",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 115, col: 83, offset: 2345},
+							pos:        position{line: 117, col: 83, offset: 2391},
 							val:        ",",
 							ignoreCase: false,
 							want:       "\",\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 115, col: 87, offset: 2349},
+							pos:  position{line: 117, col: 87, offset: 2395},
 							name: "SP",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 115, col: 90, offset: 2352},
+							pos: position{line: 117, col: 90, offset: 2398},
 							expr: &ruleRefExpr{
-								pos:  position{line: 115, col: 90, offset: 2352},
+								pos:  position{line: 117, col: 90, offset: 2398},
 								name: "MiscNote",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 115, col: 100, offset: 2362},
+							pos:        position{line: 117, col: 100, offset: 2408},
 							val:        ",",
 							ignoreCase: false,
 							want:       "\",\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 115, col: 104, offset: 2366},
+							pos:  position{line: 117, col: 104, offset: 2412},
 							name: "SP",
 						},
 						&litMatcher{
-							pos:        position{line: 115, col: 107, offset: 2369},
+							pos:        position{line: 117, col: 107, offset: 2415},
 							val:        "Current Hex =",
 							ignoreCase: false,
 							want:       "\"Current Hex =\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 115, col: 123, offset: 2385},
+							pos:  position{line: 117, col: 123, offset: 2431},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 115, col: 126, offset: 2388},
+							pos:   position{line: 117, col: 126, offset: 2434},
 							label: "ch",
 							expr: &ruleRefExpr{
-								pos:  position{line: 115, col: 129, offset: 2391},
+								pos:  position{line: 117, col: 129, offset: 2437},
 								name: "COORDS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 115, col: 136, offset: 2398},
+							pos:        position{line: 117, col: 136, offset: 2444},
 							val:        ",",
 							ignoreCase: false,
 							want:       "\",\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 115, col: 140, offset: 2402},
+							pos:  position{line: 117, col: 140, offset: 2448},
 							name: "SP",
 						},
 						&litMatcher{
-							pos:        position{line: 115, col: 143, offset: 2405},
+							pos:        position{line: 117, col: 143, offset: 2451},
 							val:        "(Previous Hex =",
 							ignoreCase: false,
 							want:       "\"(Previous Hex =\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 115, col: 161, offset: 2423},
+							pos:  position{line: 117, col: 161, offset: 2469},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 115, col: 164, offset: 2426},
+							pos:   position{line: 117, col: 164, offset: 2472},
 							label: "ph",
 							expr: &ruleRefExpr{
-								pos:  position{line: 115, col: 167, offset: 2429},
+								pos:  position{line: 117, col: 167, offset: 2475},
 								name: "COORDS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 115, col: 174, offset: 2436},
+							pos:        position{line: 117, col: 174, offset: 2482},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 115, col: 178, offset: 2440},
+							pos:  position{line: 117, col: 178, offset: 2486},
 							name: "_",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 115, col: 180, offset: 2442},
+							pos:  position{line: 117, col: 180, offset: 2488},
 							name: "EOF",
 						},
 					},
@@ -504,58 +506,58 @@ var g = &grammar{
 		},
 		{
 			name: "Longhouse",
-			pos:  position{line: 123, col: 1, offset: 2589},
+			pos:  position{line: 125, col: 1, offset: 2635},
 			expr: &actionExpr{
-				pos: position{line: 123, col: 14, offset: 2602},
+				pos: position{line: 125, col: 14, offset: 2648},
 				run: (*parser).callonLonghouse1,
 				expr: &seqExpr{
-					pos: position{line: 123, col: 14, offset: 2602},
+					pos: position{line: 125, col: 14, offset: 2648},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 123, col: 14, offset: 2602},
+							pos:   position{line: 125, col: 14, offset: 2648},
 							label: "szi",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 123, col: 19, offset: 2607},
+								pos: position{line: 125, col: 19, offset: 2653},
 								expr: &ruleRefExpr{
-									pos:  position{line: 123, col: 19, offset: 2607},
+									pos:  position{line: 125, col: 19, offset: 2653},
 									name: "DIGIT",
 								},
 							},
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 123, col: 27, offset: 2615},
+							pos: position{line: 125, col: 27, offset: 2661},
 							expr: &ruleRefExpr{
-								pos:  position{line: 123, col: 27, offset: 2615},
+								pos:  position{line: 125, col: 27, offset: 2661},
 								name: "SP",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 123, col: 31, offset: 2619},
+							pos:        position{line: 125, col: 31, offset: 2665},
 							val:        "Longhouse",
 							ignoreCase: false,
 							want:       "\"Longhouse\"",
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 123, col: 43, offset: 2631},
+							pos: position{line: 125, col: 43, offset: 2677},
 							expr: &ruleRefExpr{
-								pos:  position{line: 123, col: 43, offset: 2631},
+								pos:  position{line: 125, col: 43, offset: 2677},
 								name: "SP",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 123, col: 47, offset: 2635},
+							pos:   position{line: 125, col: 47, offset: 2681},
 							label: "idi",
 							expr: &seqExpr{
-								pos: position{line: 123, col: 52, offset: 2640},
+								pos: position{line: 125, col: 52, offset: 2686},
 								exprs: []any{
 									&ruleRefExpr{
-										pos:  position{line: 123, col: 52, offset: 2640},
+										pos:  position{line: 125, col: 52, offset: 2686},
 										name: "LETTER",
 									},
 									&oneOrMoreExpr{
-										pos: position{line: 123, col: 59, offset: 2647},
+										pos: position{line: 125, col: 59, offset: 2693},
 										expr: &ruleRefExpr{
-											pos:  position{line: 123, col: 59, offset: 2647},
+											pos:  position{line: 125, col: 59, offset: 2693},
 											name: "DIGIT",
 										},
 									},
@@ -568,85 +570,85 @@ var g = &grammar{
 		},
 		{
 			name: "ObviousNeighboringTerrainCode",
-			pos:  position{line: 159, col: 1, offset: 3668},
+			pos:  position{line: 161, col: 1, offset: 3714},
 			expr: &choiceExpr{
-				pos: position{line: 159, col: 34, offset: 3701},
+				pos: position{line: 161, col: 34, offset: 3747},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 159, col: 34, offset: 3701},
+						pos: position{line: 161, col: 34, offset: 3747},
 						run: (*parser).callonObviousNeighboringTerrainCode2,
 						expr: &litMatcher{
-							pos:        position{line: 159, col: 34, offset: 3701},
+							pos:        position{line: 161, col: 34, offset: 3747},
 							val:        "alps",
 							ignoreCase: true,
 							want:       "\"ALPS\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 161, col: 5, offset: 3756},
+						pos: position{line: 163, col: 5, offset: 3802},
 						run: (*parser).callonObviousNeighboringTerrainCode4,
 						expr: &litMatcher{
-							pos:        position{line: 161, col: 5, offset: 3756},
+							pos:        position{line: 163, col: 5, offset: 3802},
 							val:        "hsm",
 							ignoreCase: true,
 							want:       "\"HSM\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 163, col: 5, offset: 3812},
+						pos: position{line: 165, col: 5, offset: 3858},
 						run: (*parser).callonObviousNeighboringTerrainCode6,
 						expr: &litMatcher{
-							pos:        position{line: 163, col: 5, offset: 3812},
+							pos:        position{line: 165, col: 5, offset: 3858},
 							val:        "lcm",
 							ignoreCase: true,
 							want:       "\"LCM\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 165, col: 5, offset: 3869},
+						pos: position{line: 167, col: 5, offset: 3915},
 						run: (*parser).callonObviousNeighboringTerrainCode8,
 						expr: &litMatcher{
-							pos:        position{line: 165, col: 5, offset: 3869},
+							pos:        position{line: 167, col: 5, offset: 3915},
 							val:        "ljm",
 							ignoreCase: true,
 							want:       "\"LJM\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 167, col: 5, offset: 3925},
+						pos: position{line: 169, col: 5, offset: 3971},
 						run: (*parser).callonObviousNeighboringTerrainCode10,
 						expr: &litMatcher{
-							pos:        position{line: 167, col: 5, offset: 3925},
+							pos:        position{line: 169, col: 5, offset: 3971},
 							val:        "lsm",
 							ignoreCase: true,
 							want:       "\"LSM\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 169, col: 5, offset: 3980},
+						pos: position{line: 171, col: 5, offset: 4026},
 						run: (*parser).callonObviousNeighboringTerrainCode12,
 						expr: &litMatcher{
-							pos:        position{line: 169, col: 5, offset: 3980},
+							pos:        position{line: 171, col: 5, offset: 4026},
 							val:        "lvm",
 							ignoreCase: true,
 							want:       "\"LVM\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 171, col: 5, offset: 4038},
+						pos: position{line: 173, col: 5, offset: 4084},
 						run: (*parser).callonObviousNeighboringTerrainCode14,
 						expr: &litMatcher{
-							pos:        position{line: 171, col: 5, offset: 4038},
+							pos:        position{line: 173, col: 5, offset: 4084},
 							val:        "L",
 							ignoreCase: false,
 							want:       "\"L\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 173, col: 5, offset: 4082},
+						pos: position{line: 175, col: 5, offset: 4128},
 						run: (*parser).callonObviousNeighboringTerrainCode16,
 						expr: &litMatcher{
-							pos:        position{line: 173, col: 5, offset: 4082},
+							pos:        position{line: 175, col: 5, offset: 4128},
 							val:        "O",
 							ignoreCase: false,
 							want:       "\"O\"",
@@ -657,25 +659,25 @@ var g = &grammar{
 		},
 		{
 			name: "ProhibitedBy",
-			pos:  position{line: 177, col: 1, offset: 4126},
+			pos:  position{line: 179, col: 1, offset: 4172},
 			expr: &choiceExpr{
-				pos: position{line: 177, col: 17, offset: 4142},
+				pos: position{line: 179, col: 17, offset: 4188},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 177, col: 17, offset: 4142},
+						pos: position{line: 179, col: 17, offset: 4188},
 						run: (*parser).callonProhibitedBy2,
 						expr: &litMatcher{
-							pos:        position{line: 177, col: 17, offset: 4142},
+							pos:        position{line: 179, col: 17, offset: 4188},
 							val:        "Lake",
 							ignoreCase: false,
 							want:       "\"Lake\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 179, col: 5, offset: 4189},
+						pos: position{line: 181, col: 5, offset: 4235},
 						run: (*parser).callonProhibitedBy4,
 						expr: &litMatcher{
-							pos:        position{line: 179, col: 5, offset: 4189},
+							pos:        position{line: 181, col: 5, offset: 4235},
 							val:        "Ocean",
 							ignoreCase: false,
 							want:       "\"Ocean\"",
@@ -686,28 +688,28 @@ var g = &grammar{
 		},
 		{
 			name: "ScoutMovement",
-			pos:  position{line: 183, col: 1, offset: 4237},
+			pos:  position{line: 185, col: 1, offset: 4283},
 			expr: &actionExpr{
-				pos: position{line: 183, col: 18, offset: 4254},
+				pos: position{line: 185, col: 18, offset: 4300},
 				run: (*parser).callonScoutMovement1,
 				expr: &seqExpr{
-					pos: position{line: 183, col: 18, offset: 4254},
+					pos: position{line: 185, col: 18, offset: 4300},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 183, col: 18, offset: 4254},
+							pos:        position{line: 185, col: 18, offset: 4300},
 							val:        "Scout",
 							ignoreCase: false,
 							want:       "\"Scout\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 183, col: 26, offset: 4262},
+							pos:  position{line: 185, col: 26, offset: 4308},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 183, col: 29, offset: 4265},
+							pos:   position{line: 185, col: 29, offset: 4311},
 							label: "no",
 							expr: &charClassMatcher{
-								pos:        position{line: 183, col: 32, offset: 4268},
+								pos:        position{line: 185, col: 32, offset: 4314},
 								val:        "[1-8]",
 								ranges:     []rune{'1', '8'},
 								ignoreCase: false,
@@ -715,25 +717,25 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 183, col: 38, offset: 4274},
+							pos:        position{line: 185, col: 38, offset: 4320},
 							val:        ":",
 							ignoreCase: false,
 							want:       "\":\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 183, col: 42, offset: 4278},
+							pos:  position{line: 185, col: 42, offset: 4324},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 183, col: 44, offset: 4280},
+							pos:   position{line: 185, col: 44, offset: 4326},
 							label: "results",
 							expr: &ruleRefExpr{
-								pos:  position{line: 183, col: 52, offset: 4288},
+								pos:  position{line: 185, col: 52, offset: 4334},
 								name: "ToEOL",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 183, col: 58, offset: 4294},
+							pos:  position{line: 185, col: 58, offset: 4340},
 							name: "EOF",
 						},
 					},
@@ -742,77 +744,77 @@ var g = &grammar{
 		},
 		{
 			name: "ScryLine",
-			pos:  position{line: 200, col: 1, offset: 4701},
+			pos:  position{line: 202, col: 1, offset: 4747},
 			expr: &actionExpr{
-				pos: position{line: 200, col: 13, offset: 4713},
+				pos: position{line: 202, col: 13, offset: 4759},
 				run: (*parser).callonScryLine1,
 				expr: &seqExpr{
-					pos: position{line: 200, col: 13, offset: 4713},
+					pos: position{line: 202, col: 13, offset: 4759},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 200, col: 13, offset: 4713},
+							pos:   position{line: 202, col: 13, offset: 4759},
 							label: "u",
 							expr: &ruleRefExpr{
-								pos:  position{line: 200, col: 15, offset: 4715},
+								pos:  position{line: 202, col: 15, offset: 4761},
 								name: "UNIT_ID",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 23, offset: 4723},
+							pos:  position{line: 202, col: 23, offset: 4769},
 							name: "SP",
 						},
 						&litMatcher{
-							pos:        position{line: 200, col: 26, offset: 4726},
+							pos:        position{line: 202, col: 26, offset: 4772},
 							val:        "Scry",
 							ignoreCase: false,
 							want:       "\"Scry\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 33, offset: 4733},
+							pos:  position{line: 202, col: 33, offset: 4779},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 200, col: 35, offset: 4735},
+							pos:        position{line: 202, col: 35, offset: 4781},
 							val:        ":",
 							ignoreCase: false,
 							want:       "\":\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 39, offset: 4739},
+							pos:  position{line: 202, col: 39, offset: 4785},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 200, col: 41, offset: 4741},
+							pos:   position{line: 202, col: 41, offset: 4787},
 							label: "oh",
 							expr: &ruleRefExpr{
-								pos:  position{line: 200, col: 44, offset: 4744},
+								pos:  position{line: 202, col: 44, offset: 4790},
 								name: "COORDS",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 51, offset: 4751},
+							pos:  position{line: 202, col: 51, offset: 4797},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 200, col: 53, offset: 4753},
+							pos:        position{line: 202, col: 53, offset: 4799},
 							val:        ":",
 							ignoreCase: false,
 							want:       "\":\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 57, offset: 4757},
+							pos:  position{line: 202, col: 57, offset: 4803},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 200, col: 59, offset: 4759},
+							pos:   position{line: 202, col: 59, offset: 4805},
 							label: "results",
 							expr: &ruleRefExpr{
-								pos:  position{line: 200, col: 67, offset: 4767},
+								pos:  position{line: 202, col: 67, offset: 4813},
 								name: "ToEOL",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 73, offset: 4773},
+							pos:  position{line: 202, col: 73, offset: 4819},
 							name: "EOF",
 						},
 					},
@@ -821,22 +823,22 @@ var g = &grammar{
 		},
 		{
 			name: "SpaceDirection",
-			pos:  position{line: 211, col: 1, offset: 4956},
+			pos:  position{line: 213, col: 1, offset: 5002},
 			expr: &actionExpr{
-				pos: position{line: 211, col: 19, offset: 4974},
+				pos: position{line: 213, col: 19, offset: 5020},
 				run: (*parser).callonSpaceDirection1,
 				expr: &seqExpr{
-					pos: position{line: 211, col: 19, offset: 4974},
+					pos: position{line: 213, col: 19, offset: 5020},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 211, col: 19, offset: 4974},
+							pos:  position{line: 213, col: 19, offset: 5020},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 211, col: 22, offset: 4977},
+							pos:   position{line: 213, col: 22, offset: 5023},
 							label: "d",
 							expr: &ruleRefExpr{
-								pos:  position{line: 211, col: 24, offset: 4979},
+								pos:  position{line: 213, col: 24, offset: 5025},
 								name: "DIRECTION",
 							},
 						},
@@ -846,22 +848,22 @@ var g = &grammar{
 		},
 		{
 			name: "SpaceUnitID",
-			pos:  position{line: 215, col: 1, offset: 5012},
+			pos:  position{line: 217, col: 1, offset: 5058},
 			expr: &actionExpr{
-				pos: position{line: 215, col: 16, offset: 5027},
+				pos: position{line: 217, col: 16, offset: 5073},
 				run: (*parser).callonSpaceUnitID1,
 				expr: &seqExpr{
-					pos: position{line: 215, col: 16, offset: 5027},
+					pos: position{line: 217, col: 16, offset: 5073},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 215, col: 16, offset: 5027},
+							pos:  position{line: 217, col: 16, offset: 5073},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 215, col: 19, offset: 5030},
+							pos:   position{line: 217, col: 19, offset: 5076},
 							label: "u",
 							expr: &ruleRefExpr{
-								pos:  position{line: 215, col: 21, offset: 5032},
+								pos:  position{line: 217, col: 21, offset: 5078},
 								name: "UNIT_ID",
 							},
 						},
@@ -871,45 +873,45 @@ var g = &grammar{
 		},
 		{
 			name: "StatusLine",
-			pos:  position{line: 219, col: 1, offset: 5063},
+			pos:  position{line: 221, col: 1, offset: 5109},
 			expr: &actionExpr{
-				pos: position{line: 219, col: 15, offset: 5077},
+				pos: position{line: 221, col: 15, offset: 5123},
 				run: (*parser).callonStatusLine1,
 				expr: &seqExpr{
-					pos: position{line: 219, col: 15, offset: 5077},
+					pos: position{line: 221, col: 15, offset: 5123},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 219, col: 15, offset: 5077},
+							pos:   position{line: 221, col: 15, offset: 5123},
 							label: "u",
 							expr: &ruleRefExpr{
-								pos:  position{line: 219, col: 17, offset: 5079},
+								pos:  position{line: 221, col: 17, offset: 5125},
 								name: "UNIT_ID",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 219, col: 25, offset: 5087},
+							pos:  position{line: 221, col: 25, offset: 5133},
 							name: "SP",
 						},
 						&litMatcher{
-							pos:        position{line: 219, col: 28, offset: 5090},
+							pos:        position{line: 221, col: 28, offset: 5136},
 							val:        "Status:",
 							ignoreCase: false,
 							want:       "\"Status:\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 219, col: 38, offset: 5100},
+							pos:  position{line: 221, col: 38, offset: 5146},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 219, col: 40, offset: 5102},
+							pos:   position{line: 221, col: 40, offset: 5148},
 							label: "results",
 							expr: &ruleRefExpr{
-								pos:  position{line: 219, col: 48, offset: 5110},
+								pos:  position{line: 221, col: 48, offset: 5156},
 								name: "ToEOL",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 219, col: 54, offset: 5116},
+							pos:  position{line: 221, col: 54, offset: 5162},
 							name: "EOF",
 						},
 					},
@@ -918,765 +920,765 @@ var g = &grammar{
 		},
 		{
 			name: "Step",
-			pos:  position{line: 230, col: 1, offset: 5309},
+			pos:  position{line: 232, col: 1, offset: 5355},
 			expr: &choiceExpr{
-				pos: position{line: 230, col: 9, offset: 5317},
+				pos: position{line: 232, col: 9, offset: 5363},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 230, col: 9, offset: 5317},
+						pos: position{line: 232, col: 9, offset: 5363},
 						run: (*parser).callonStep2,
 						expr: &seqExpr{
-							pos: position{line: 230, col: 9, offset: 5317},
+							pos: position{line: 232, col: 9, offset: 5363},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 230, col: 9, offset: 5317},
+									pos:   position{line: 232, col: 9, offset: 5363},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 230, col: 11, offset: 5319},
+										pos:  position{line: 232, col: 11, offset: 5365},
 										name: "DIRECTION",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 230, col: 21, offset: 5329},
+									pos:        position{line: 232, col: 21, offset: 5375},
 									val:        "-",
 									ignoreCase: false,
 									want:       "\"-\"",
 								},
 								&labeledExpr{
-									pos:   position{line: 230, col: 25, offset: 5333},
+									pos:   position{line: 232, col: 25, offset: 5379},
 									label: "t",
 									expr: &ruleRefExpr{
-										pos:  position{line: 230, col: 27, offset: 5335},
+										pos:  position{line: 232, col: 27, offset: 5381},
 										name: "TERRAIN_CODE",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 230, col: 40, offset: 5348},
+									pos:  position{line: 232, col: 40, offset: 5394},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 235, col: 5, offset: 5488},
+						pos: position{line: 237, col: 5, offset: 5534},
 						run: (*parser).callonStep10,
 						expr: &seqExpr{
-							pos: position{line: 235, col: 5, offset: 5488},
+							pos: position{line: 237, col: 5, offset: 5534},
 							exprs: []any{
 								&charClassMatcher{
-									pos:        position{line: 235, col: 5, offset: 5488},
+									pos:        position{line: 237, col: 5, offset: 5534},
 									val:        "[Cc]",
 									chars:      []rune{'C', 'c'},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 235, col: 10, offset: 5493},
+									pos:        position{line: 237, col: 10, offset: 5539},
 									val:        "an't Move on",
 									ignoreCase: false,
 									want:       "\"an't Move on\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 235, col: 25, offset: 5508},
+									pos:  position{line: 237, col: 25, offset: 5554},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 235, col: 28, offset: 5511},
+									pos:   position{line: 237, col: 28, offset: 5557},
 									label: "t",
 									expr: &ruleRefExpr{
-										pos:  position{line: 235, col: 30, offset: 5513},
+										pos:  position{line: 237, col: 30, offset: 5559},
 										name: "ProhibitedBy",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 235, col: 43, offset: 5526},
+									pos:  position{line: 237, col: 43, offset: 5572},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 235, col: 46, offset: 5529},
+									pos:        position{line: 237, col: 46, offset: 5575},
 									val:        "to",
 									ignoreCase: false,
 									want:       "\"to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 235, col: 51, offset: 5534},
+									pos:  position{line: 237, col: 51, offset: 5580},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 235, col: 54, offset: 5537},
+									pos:   position{line: 237, col: 54, offset: 5583},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 235, col: 56, offset: 5539},
+										pos:  position{line: 237, col: 56, offset: 5585},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 235, col: 66, offset: 5549},
+									pos:  position{line: 237, col: 66, offset: 5595},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 235, col: 69, offset: 5552},
+									pos:        position{line: 237, col: 69, offset: 5598},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 235, col: 78, offset: 5561},
+									pos:  position{line: 237, col: 78, offset: 5607},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 240, col: 5, offset: 5700},
+						pos: position{line: 242, col: 5, offset: 5746},
 						run: (*parser).callonStep25,
 						expr: &seqExpr{
-							pos: position{line: 240, col: 5, offset: 5700},
+							pos: position{line: 242, col: 5, offset: 5746},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 240, col: 5, offset: 5700},
+									pos:        position{line: 242, col: 5, offset: 5746},
 									val:        "Cannot Move Wagons into Jungle Hill",
 									ignoreCase: false,
 									want:       "\"Cannot Move Wagons into Jungle Hill\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 240, col: 43, offset: 5738},
+									pos:  position{line: 242, col: 43, offset: 5784},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 240, col: 46, offset: 5741},
+									pos:        position{line: 242, col: 46, offset: 5787},
 									val:        "to",
 									ignoreCase: false,
 									want:       "\"to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 240, col: 51, offset: 5746},
+									pos:  position{line: 242, col: 51, offset: 5792},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 240, col: 54, offset: 5749},
+									pos:   position{line: 242, col: 54, offset: 5795},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 240, col: 56, offset: 5751},
+										pos:  position{line: 242, col: 56, offset: 5797},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 240, col: 66, offset: 5761},
+									pos:  position{line: 242, col: 66, offset: 5807},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 240, col: 69, offset: 5764},
+									pos:        position{line: 242, col: 69, offset: 5810},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 240, col: 78, offset: 5773},
+									pos:  position{line: 242, col: 78, offset: 5819},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 245, col: 5, offset: 5910},
+						pos: position{line: 247, col: 5, offset: 5956},
 						run: (*parser).callonStep36,
 						expr: &seqExpr{
-							pos: position{line: 245, col: 5, offset: 5910},
+							pos: position{line: 247, col: 5, offset: 5956},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 245, col: 5, offset: 5910},
+									pos:        position{line: 247, col: 5, offset: 5956},
 									val:        "Cannot Move Wagons into Mountains",
 									ignoreCase: false,
 									want:       "\"Cannot Move Wagons into Mountains\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 245, col: 41, offset: 5946},
+									pos:  position{line: 247, col: 41, offset: 5992},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 245, col: 44, offset: 5949},
+									pos:        position{line: 247, col: 44, offset: 5995},
 									val:        "to",
 									ignoreCase: false,
 									want:       "\"to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 245, col: 49, offset: 5954},
+									pos:  position{line: 247, col: 49, offset: 6000},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 245, col: 52, offset: 5957},
+									pos:   position{line: 247, col: 52, offset: 6003},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 245, col: 54, offset: 5959},
+										pos:  position{line: 247, col: 54, offset: 6005},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 245, col: 64, offset: 5969},
+									pos:  position{line: 247, col: 64, offset: 6015},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 245, col: 67, offset: 5972},
+									pos:        position{line: 247, col: 67, offset: 6018},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 245, col: 76, offset: 5981},
+									pos:  position{line: 247, col: 76, offset: 6027},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 250, col: 5, offset: 6122},
+						pos: position{line: 252, col: 5, offset: 6168},
 						run: (*parser).callonStep47,
 						expr: &seqExpr{
-							pos: position{line: 250, col: 5, offset: 6122},
+							pos: position{line: 252, col: 5, offset: 6168},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 250, col: 5, offset: 6122},
+									pos:        position{line: 252, col: 5, offset: 6168},
 									val:        "Cannot Move Wagons into Snowy hills",
 									ignoreCase: false,
 									want:       "\"Cannot Move Wagons into Snowy hills\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 250, col: 43, offset: 6160},
+									pos:  position{line: 252, col: 43, offset: 6206},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 250, col: 46, offset: 6163},
+									pos:        position{line: 252, col: 46, offset: 6209},
 									val:        "to",
 									ignoreCase: false,
 									want:       "\"to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 250, col: 51, offset: 6168},
+									pos:  position{line: 252, col: 51, offset: 6214},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 250, col: 54, offset: 6171},
+									pos:   position{line: 252, col: 54, offset: 6217},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 250, col: 56, offset: 6173},
+										pos:  position{line: 252, col: 56, offset: 6219},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 250, col: 66, offset: 6183},
+									pos:  position{line: 252, col: 66, offset: 6229},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 250, col: 69, offset: 6186},
+									pos:        position{line: 252, col: 69, offset: 6232},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 250, col: 78, offset: 6195},
+									pos:  position{line: 252, col: 78, offset: 6241},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 255, col: 5, offset: 6331},
+						pos: position{line: 257, col: 5, offset: 6377},
 						run: (*parser).callonStep58,
 						expr: &seqExpr{
-							pos: position{line: 255, col: 5, offset: 6331},
+							pos: position{line: 257, col: 5, offset: 6377},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 255, col: 5, offset: 6331},
+									pos:        position{line: 257, col: 5, offset: 6377},
 									val:        "Cannot Move Wagons into Swamp/Jungle Hill to",
 									ignoreCase: false,
 									want:       "\"Cannot Move Wagons into Swamp/Jungle Hill to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 255, col: 52, offset: 6378},
+									pos:  position{line: 257, col: 52, offset: 6424},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 255, col: 55, offset: 6381},
+									pos:   position{line: 257, col: 55, offset: 6427},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 255, col: 57, offset: 6383},
+										pos:  position{line: 257, col: 57, offset: 6429},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 255, col: 67, offset: 6393},
+									pos:  position{line: 257, col: 67, offset: 6439},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 255, col: 70, offset: 6396},
+									pos:        position{line: 257, col: 70, offset: 6442},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 255, col: 79, offset: 6405},
+									pos:  position{line: 257, col: 79, offset: 6451},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 260, col: 5, offset: 6549},
+						pos: position{line: 262, col: 5, offset: 6595},
 						run: (*parser).callonStep67,
 						expr: &seqExpr{
-							pos: position{line: 260, col: 5, offset: 6549},
+							pos: position{line: 262, col: 5, offset: 6595},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 260, col: 5, offset: 6549},
+									pos:        position{line: 262, col: 5, offset: 6595},
 									val:        "Cannot Move Wagons into Swamp",
 									ignoreCase: false,
 									want:       "\"Cannot Move Wagons into Swamp\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 260, col: 37, offset: 6581},
+									pos:  position{line: 262, col: 37, offset: 6627},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 260, col: 40, offset: 6584},
+									pos:        position{line: 262, col: 40, offset: 6630},
 									val:        "to",
 									ignoreCase: false,
 									want:       "\"to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 260, col: 45, offset: 6589},
+									pos:  position{line: 262, col: 45, offset: 6635},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 260, col: 48, offset: 6592},
+									pos:   position{line: 262, col: 48, offset: 6638},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 260, col: 50, offset: 6594},
+										pos:  position{line: 262, col: 50, offset: 6640},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 260, col: 60, offset: 6604},
+									pos:  position{line: 262, col: 60, offset: 6650},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 260, col: 63, offset: 6607},
+									pos:        position{line: 262, col: 63, offset: 6653},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 260, col: 72, offset: 6616},
+									pos:  position{line: 262, col: 72, offset: 6662},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 265, col: 5, offset: 6751},
+						pos: position{line: 267, col: 5, offset: 6797},
 						run: (*parser).callonStep78,
 						expr: &seqExpr{
-							pos: position{line: 265, col: 5, offset: 6751},
+							pos: position{line: 267, col: 5, offset: 6797},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 265, col: 5, offset: 6751},
+									pos:   position{line: 267, col: 5, offset: 6797},
 									label: "people",
 									expr: &ruleRefExpr{
-										pos:  position{line: 265, col: 12, offset: 6758},
+										pos:  position{line: 267, col: 12, offset: 6804},
 										name: "NUMBER",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 265, col: 19, offset: 6765},
+									pos:  position{line: 267, col: 19, offset: 6811},
 									name: "_",
 								},
 								&litMatcher{
-									pos:        position{line: 265, col: 21, offset: 6767},
+									pos:        position{line: 267, col: 21, offset: 6813},
 									val:        "people can carry",
 									ignoreCase: false,
 									want:       "\"people can carry\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 265, col: 40, offset: 6786},
+									pos:  position{line: 267, col: 40, offset: 6832},
 									name: "_",
 								},
 								&labeledExpr{
-									pos:   position{line: 265, col: 42, offset: 6788},
+									pos:   position{line: 267, col: 42, offset: 6834},
 									label: "amount",
 									expr: &ruleRefExpr{
-										pos:  position{line: 265, col: 49, offset: 6795},
+										pos:  position{line: 267, col: 49, offset: 6841},
 										name: "NUMBER",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 265, col: 56, offset: 6802},
+									pos:   position{line: 267, col: 56, offset: 6848},
 									label: "item",
 									expr: &ruleRefExpr{
-										pos:  position{line: 265, col: 61, offset: 6807},
+										pos:  position{line: 267, col: 61, offset: 6853},
 										name: "DOTSPLAT",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 265, col: 70, offset: 6816},
+									pos:  position{line: 267, col: 70, offset: 6862},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 271, col: 5, offset: 6962},
+						pos: position{line: 273, col: 5, offset: 7008},
 						run: (*parser).callonStep90,
 						expr: &seqExpr{
-							pos: position{line: 271, col: 5, offset: 6962},
+							pos: position{line: 273, col: 5, offset: 7008},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 271, col: 5, offset: 6962},
+									pos:        position{line: 273, col: 5, offset: 7008},
 									val:        "failed due to Insufficient capacity to carry",
 									ignoreCase: false,
 									want:       "\"failed due to Insufficient capacity to carry\"",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 271, col: 52, offset: 7009},
+									pos: position{line: 273, col: 52, offset: 7055},
 									expr: &anyMatcher{
 										line: 271, col: 52, offset: 7009,
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 271, col: 55, offset: 7012},
+									pos:  position{line: 273, col: 55, offset: 7058},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 273, col: 5, offset: 7063},
+						pos: position{line: 275, col: 5, offset: 7109},
 						run: (*parser).callonStep96,
 						expr: &seqExpr{
-							pos: position{line: 273, col: 5, offset: 7063},
+							pos: position{line: 275, col: 5, offset: 7109},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 273, col: 5, offset: 7063},
+									pos:        position{line: 275, col: 5, offset: 7109},
 									val:        "Find",
 									ignoreCase: false,
 									want:       "\"Find\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 273, col: 12, offset: 7070},
+									pos:  position{line: 275, col: 12, offset: 7116},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 273, col: 15, offset: 7073},
+									pos:   position{line: 275, col: 15, offset: 7119},
 									label: "r",
 									expr: &ruleRefExpr{
-										pos:  position{line: 273, col: 17, offset: 7075},
+										pos:  position{line: 275, col: 17, offset: 7121},
 										name: "RESOURCE",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 273, col: 26, offset: 7084},
+									pos:  position{line: 275, col: 26, offset: 7130},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 275, col: 5, offset: 7112},
+						pos: position{line: 277, col: 5, offset: 7158},
 						run: (*parser).callonStep103,
 						expr: &seqExpr{
-							pos: position{line: 275, col: 5, offset: 7112},
+							pos: position{line: 277, col: 5, offset: 7158},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 275, col: 5, offset: 7112},
+									pos:        position{line: 277, col: 5, offset: 7158},
 									val:        "Find",
 									ignoreCase: false,
 									want:       "\"Find\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 275, col: 12, offset: 7119},
+									pos:  position{line: 277, col: 12, offset: 7165},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 275, col: 15, offset: 7122},
+									pos:   position{line: 277, col: 15, offset: 7168},
 									label: "n",
 									expr: &ruleRefExpr{
-										pos:  position{line: 275, col: 17, offset: 7124},
+										pos:  position{line: 277, col: 17, offset: 7170},
 										name: "NUMBER",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 275, col: 24, offset: 7131},
+									pos:  position{line: 277, col: 24, offset: 7177},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 275, col: 27, offset: 7134},
+									pos:   position{line: 277, col: 27, offset: 7180},
 									label: "i",
 									expr: &ruleRefExpr{
-										pos:  position{line: 275, col: 29, offset: 7136},
+										pos:  position{line: 277, col: 29, offset: 7182},
 										name: "ITEM",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 275, col: 34, offset: 7141},
+									pos:  position{line: 277, col: 34, offset: 7187},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 280, col: 5, offset: 7249},
+						pos: position{line: 282, col: 5, offset: 7295},
 						run: (*parser).callonStep113,
 						expr: &seqExpr{
-							pos: position{line: 280, col: 5, offset: 7249},
+							pos: position{line: 282, col: 5, offset: 7295},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 280, col: 5, offset: 7249},
+									pos:        position{line: 282, col: 5, offset: 7295},
 									val:        "Group did not return",
 									ignoreCase: false,
 									want:       "\"Group did not return\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 280, col: 28, offset: 7272},
+									pos:  position{line: 282, col: 28, offset: 7318},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 282, col: 5, offset: 7315},
+						pos: position{line: 284, col: 5, offset: 7361},
 						run: (*parser).callonStep117,
 						expr: &seqExpr{
-							pos: position{line: 282, col: 5, offset: 7315},
+							pos: position{line: 284, col: 5, offset: 7361},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 282, col: 5, offset: 7315},
+									pos:        position{line: 284, col: 5, offset: 7361},
 									val:        "horses not allowed into mangrove swamp to",
 									ignoreCase: true,
 									want:       "\"Horses not allowed into MANGROVE SWAMP to\"i",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 50, offset: 7360},
+									pos:  position{line: 284, col: 50, offset: 7406},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 282, col: 53, offset: 7363},
+									pos:   position{line: 284, col: 53, offset: 7409},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 282, col: 55, offset: 7365},
+										pos:  position{line: 284, col: 55, offset: 7411},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 65, offset: 7375},
+									pos:  position{line: 284, col: 65, offset: 7421},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 282, col: 68, offset: 7378},
+									pos:        position{line: 284, col: 68, offset: 7424},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 77, offset: 7387},
+									pos:  position{line: 284, col: 77, offset: 7433},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 287, col: 5, offset: 7531},
+						pos: position{line: 289, col: 5, offset: 7577},
 						run: (*parser).callonStep126,
 						expr: &seqExpr{
-							pos: position{line: 287, col: 5, offset: 7531},
+							pos: position{line: 289, col: 5, offset: 7577},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 287, col: 5, offset: 7531},
+									pos:        position{line: 289, col: 5, offset: 7577},
 									val:        "Insufficient capacity to carry",
 									ignoreCase: false,
 									want:       "\"Insufficient capacity to carry\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 287, col: 38, offset: 7564},
+									pos:  position{line: 289, col: 38, offset: 7610},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 289, col: 5, offset: 7615},
+						pos: position{line: 291, col: 5, offset: 7661},
 						run: (*parser).callonStep130,
 						expr: &seqExpr{
-							pos: position{line: 289, col: 5, offset: 7615},
+							pos: position{line: 291, col: 5, offset: 7661},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 289, col: 5, offset: 7615},
+									pos:        position{line: 291, col: 5, offset: 7661},
 									val:        "NO DIRECTION",
 									ignoreCase: false,
 									want:       "\"NO DIRECTION\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 289, col: 20, offset: 7630},
+									pos:  position{line: 291, col: 20, offset: 7676},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 291, col: 5, offset: 7672},
+						pos: position{line: 293, col: 5, offset: 7718},
 						run: (*parser).callonStep134,
 						expr: &seqExpr{
-							pos: position{line: 291, col: 5, offset: 7672},
+							pos: position{line: 293, col: 5, offset: 7718},
 							exprs: []any{
 								&charClassMatcher{
-									pos:        position{line: 291, col: 5, offset: 7672},
+									pos:        position{line: 293, col: 5, offset: 7718},
 									val:        "[Nn]",
 									chars:      []rune{'N', 'n'},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 291, col: 10, offset: 7677},
+									pos:        position{line: 293, col: 10, offset: 7723},
 									val:        "o Ford on River to",
 									ignoreCase: false,
 									want:       "\"o Ford on River to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 291, col: 31, offset: 7698},
+									pos:  position{line: 293, col: 31, offset: 7744},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 291, col: 34, offset: 7701},
+									pos:   position{line: 293, col: 34, offset: 7747},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 291, col: 36, offset: 7703},
+										pos:  position{line: 293, col: 36, offset: 7749},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 291, col: 46, offset: 7713},
+									pos:  position{line: 293, col: 46, offset: 7759},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 291, col: 49, offset: 7716},
+									pos:        position{line: 293, col: 49, offset: 7762},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 291, col: 58, offset: 7725},
+									pos:  position{line: 293, col: 58, offset: 7771},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 296, col: 5, offset: 7853},
+						pos: position{line: 298, col: 5, offset: 7899},
 						run: (*parser).callonStep144,
 						expr: &seqExpr{
-							pos: position{line: 296, col: 5, offset: 7853},
+							pos: position{line: 298, col: 5, offset: 7899},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 296, col: 5, offset: 7853},
+									pos:        position{line: 298, col: 5, offset: 7899},
 									val:        "No groups found",
 									ignoreCase: false,
 									want:       "\"No groups found\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 296, col: 23, offset: 7871},
+									pos:  position{line: 298, col: 23, offset: 7917},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 298, col: 5, offset: 7915},
+						pos: position{line: 300, col: 5, offset: 7961},
 						run: (*parser).callonStep148,
 						expr: &seqExpr{
-							pos: position{line: 298, col: 5, offset: 7915},
+							pos: position{line: 300, col: 5, offset: 7961},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 298, col: 5, offset: 7915},
+									pos:        position{line: 300, col: 5, offset: 7961},
 									val:        "No Groups Raided",
 									ignoreCase: false,
 									want:       "\"No Groups Raided\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 298, col: 24, offset: 7934},
+									pos:  position{line: 300, col: 24, offset: 7980},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 300, col: 5, offset: 7979},
+						pos: position{line: 302, col: 5, offset: 8025},
 						run: (*parser).callonStep152,
 						expr: &seqExpr{
-							pos: position{line: 300, col: 5, offset: 7979},
+							pos: position{line: 302, col: 5, offset: 8025},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 300, col: 5, offset: 7979},
+									pos:        position{line: 302, col: 5, offset: 8025},
 									val:        "No Pass into Mountain to",
 									ignoreCase: false,
 									want:       "\"No Pass into Mountain to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 300, col: 32, offset: 8006},
+									pos:  position{line: 302, col: 32, offset: 8052},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 300, col: 35, offset: 8009},
+									pos:   position{line: 302, col: 35, offset: 8055},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 300, col: 37, offset: 8011},
+										pos:  position{line: 302, col: 37, offset: 8057},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 300, col: 47, offset: 8021},
+									pos:  position{line: 302, col: 47, offset: 8067},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 300, col: 50, offset: 8024},
+									pos:        position{line: 302, col: 50, offset: 8070},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 300, col: 59, offset: 8033},
+									pos:  position{line: 302, col: 59, offset: 8079},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 305, col: 5, offset: 8174},
+						pos: position{line: 307, col: 5, offset: 8220},
 						run: (*parser).callonStep161,
 						expr: &seqExpr{
-							pos: position{line: 305, col: 5, offset: 8174},
+							pos: position{line: 307, col: 5, offset: 8220},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 305, col: 5, offset: 8174},
+									pos:        position{line: 307, col: 5, offset: 8220},
 									val:        "No River Adjacent to Hex to",
 									ignoreCase: false,
 									want:       "\"No River Adjacent to Hex to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 305, col: 35, offset: 8204},
+									pos:  position{line: 307, col: 35, offset: 8250},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 305, col: 38, offset: 8207},
+									pos:   position{line: 307, col: 38, offset: 8253},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 305, col: 40, offset: 8209},
+										pos:  position{line: 307, col: 40, offset: 8255},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 305, col: 50, offset: 8219},
+									pos:  position{line: 307, col: 50, offset: 8265},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 305, col: 53, offset: 8222},
+									pos:        position{line: 307, col: 53, offset: 8268},
 									val:        "of HEX",
 									ignoreCase: false,
 									want:       "\"of HEX\"",
@@ -1685,398 +1687,398 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 309, col: 5, offset: 8320},
+						pos: position{line: 311, col: 5, offset: 8366},
 						run: (*parser).callonStep169,
 						expr: &seqExpr{
-							pos: position{line: 309, col: 5, offset: 8320},
+							pos: position{line: 311, col: 5, offset: 8366},
 							exprs: []any{
 								&charClassMatcher{
-									pos:        position{line: 309, col: 5, offset: 8320},
+									pos:        position{line: 311, col: 5, offset: 8366},
 									val:        "[Nn]",
 									chars:      []rune{'N', 'n'},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 309, col: 10, offset: 8325},
+									pos:        position{line: 311, col: 10, offset: 8371},
 									val:        "ot enough M.P's",
 									ignoreCase: false,
 									want:       "\"ot enough M.P's\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 309, col: 28, offset: 8343},
+									pos:  position{line: 311, col: 28, offset: 8389},
 									name: "_",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 309, col: 30, offset: 8345},
+									pos:  position{line: 311, col: 30, offset: 8391},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 314, col: 5, offset: 8463},
+						pos: position{line: 316, col: 5, offset: 8509},
 						run: (*parser).callonStep175,
 						expr: &seqExpr{
-							pos: position{line: 314, col: 5, offset: 8463},
+							pos: position{line: 316, col: 5, offset: 8509},
 							exprs: []any{
 								&charClassMatcher{
-									pos:        position{line: 314, col: 5, offset: 8463},
+									pos:        position{line: 316, col: 5, offset: 8509},
 									val:        "[Nn]",
 									chars:      []rune{'N', 'n'},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 314, col: 10, offset: 8468},
+									pos:        position{line: 316, col: 10, offset: 8514},
 									val:        "ot enough M.P's to move to",
 									ignoreCase: false,
 									want:       "\"ot enough M.P's to move to\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 314, col: 39, offset: 8497},
+									pos:  position{line: 316, col: 39, offset: 8543},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 314, col: 42, offset: 8500},
+									pos:   position{line: 316, col: 42, offset: 8546},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 314, col: 44, offset: 8502},
+										pos:  position{line: 316, col: 44, offset: 8548},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 314, col: 54, offset: 8512},
+									pos:  position{line: 316, col: 54, offset: 8558},
 									name: "SP",
 								},
 								&litMatcher{
-									pos:        position{line: 314, col: 57, offset: 8515},
+									pos:        position{line: 316, col: 57, offset: 8561},
 									val:        "into",
 									ignoreCase: false,
 									want:       "\"into\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 314, col: 64, offset: 8522},
+									pos:  position{line: 316, col: 64, offset: 8568},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 314, col: 67, offset: 8525},
+									pos:   position{line: 316, col: 67, offset: 8571},
 									label: "t",
 									expr: &ruleRefExpr{
-										pos:  position{line: 314, col: 69, offset: 8527},
+										pos:  position{line: 316, col: 69, offset: 8573},
 										name: "TERRAIN",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 314, col: 77, offset: 8535},
+									pos:  position{line: 316, col: 77, offset: 8581},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 319, col: 5, offset: 8669},
+						pos: position{line: 321, col: 5, offset: 8715},
 						run: (*parser).callonStep188,
 						expr: &seqExpr{
-							pos: position{line: 319, col: 5, offset: 8669},
+							pos: position{line: 321, col: 5, offset: 8715},
 							exprs: []any{
 								&charClassMatcher{
-									pos:        position{line: 319, col: 5, offset: 8669},
+									pos:        position{line: 321, col: 5, offset: 8715},
 									val:        "[Nn]",
 									chars:      []rune{'N', 'n'},
 									ignoreCase: false,
 									inverted:   false,
 								},
 								&litMatcher{
-									pos:        position{line: 319, col: 10, offset: 8674},
+									pos:        position{line: 321, col: 10, offset: 8720},
 									val:        "othing of interest found",
 									ignoreCase: false,
 									want:       "\"othing of interest found\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 319, col: 37, offset: 8701},
+									pos:  position{line: 321, col: 37, offset: 8747},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 321, col: 5, offset: 8744},
+						pos: position{line: 323, col: 5, offset: 8790},
 						run: (*parser).callonStep193,
 						expr: &seqExpr{
-							pos: position{line: 321, col: 5, offset: 8744},
+							pos: position{line: 323, col: 5, offset: 8790},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 321, col: 5, offset: 8744},
+									pos:        position{line: 323, col: 5, offset: 8790},
 									val:        "Patrolled and found",
 									ignoreCase: false,
 									want:       "\"Patrolled and found\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 321, col: 27, offset: 8766},
+									pos:  position{line: 323, col: 27, offset: 8812},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 321, col: 30, offset: 8769},
+									pos:   position{line: 323, col: 30, offset: 8815},
 									label: "u",
 									expr: &ruleRefExpr{
-										pos:  position{line: 321, col: 32, offset: 8771},
+										pos:  position{line: 323, col: 32, offset: 8817},
 										name: "UNIT_ID",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 321, col: 40, offset: 8779},
+									pos:   position{line: 323, col: 40, offset: 8825},
 									label: "sui",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 321, col: 44, offset: 8783},
+										pos: position{line: 323, col: 44, offset: 8829},
 										expr: &ruleRefExpr{
-											pos:  position{line: 321, col: 44, offset: 8783},
+											pos:  position{line: 323, col: 44, offset: 8829},
 											name: "SpaceUnitID",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 321, col: 57, offset: 8796},
+									pos:  position{line: 323, col: 57, offset: 8842},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 333, col: 5, offset: 9192},
+						pos: position{line: 335, col: 5, offset: 9238},
 						run: (*parser).callonStep203,
 						expr: &seqExpr{
-							pos: position{line: 333, col: 5, offset: 9192},
+							pos: position{line: 335, col: 5, offset: 9238},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 333, col: 5, offset: 9192},
+									pos:   position{line: 335, col: 5, offset: 9238},
 									label: "t",
 									expr: &ruleRefExpr{
-										pos:  position{line: 333, col: 7, offset: 9194},
+										pos:  position{line: 335, col: 7, offset: 9240},
 										name: "ObviousNeighboringTerrainCode",
 									},
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 333, col: 37, offset: 9224},
+									pos: position{line: 335, col: 37, offset: 9270},
 									expr: &ruleRefExpr{
-										pos:  position{line: 333, col: 37, offset: 9224},
+										pos:  position{line: 335, col: 37, offset: 9270},
 										name: "SP",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 333, col: 41, offset: 9228},
+									pos:   position{line: 335, col: 41, offset: 9274},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 333, col: 43, offset: 9230},
+										pos:  position{line: 335, col: 43, offset: 9276},
 										name: "DIRECTION",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 333, col: 53, offset: 9240},
+									pos:   position{line: 335, col: 53, offset: 9286},
 									label: "sdi",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 333, col: 57, offset: 9244},
+										pos: position{line: 335, col: 57, offset: 9290},
 										expr: &ruleRefExpr{
-											pos:  position{line: 333, col: 57, offset: 9244},
+											pos:  position{line: 335, col: 57, offset: 9290},
 											name: "SpaceDirection",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 333, col: 73, offset: 9260},
+									pos:  position{line: 335, col: 73, offset: 9306},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 348, col: 5, offset: 9722},
+						pos: position{line: 350, col: 5, offset: 9768},
 						run: (*parser).callonStep215,
 						expr: &seqExpr{
-							pos: position{line: 348, col: 5, offset: 9722},
+							pos: position{line: 350, col: 5, offset: 9768},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 348, col: 5, offset: 9722},
+									pos:   position{line: 350, col: 5, offset: 9768},
 									label: "et",
 									expr: &ruleRefExpr{
-										pos:  position{line: 348, col: 8, offset: 9725},
+										pos:  position{line: 350, col: 8, offset: 9771},
 										name: "EdgeType",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 348, col: 17, offset: 9734},
+									pos:  position{line: 350, col: 17, offset: 9780},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 348, col: 20, offset: 9737},
+									pos:   position{line: 350, col: 20, offset: 9783},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 348, col: 22, offset: 9739},
+										pos:  position{line: 350, col: 22, offset: 9785},
 										name: "DIRECTION",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 348, col: 32, offset: 9749},
+									pos:   position{line: 350, col: 32, offset: 9795},
 									label: "edi",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 348, col: 36, offset: 9753},
+										pos: position{line: 350, col: 36, offset: 9799},
 										expr: &ruleRefExpr{
-											pos:  position{line: 348, col: 36, offset: 9753},
+											pos:  position{line: 350, col: 36, offset: 9799},
 											name: "SpaceDirection",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 348, col: 52, offset: 9769},
+									pos:  position{line: 350, col: 52, offset: 9815},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 360, col: 5, offset: 10179},
+						pos: position{line: 362, col: 5, offset: 10225},
 						run: (*parser).callonStep226,
 						expr: &seqExpr{
-							pos: position{line: 360, col: 5, offset: 10179},
+							pos: position{line: 362, col: 5, offset: 10225},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 360, col: 5, offset: 10179},
+									pos:   position{line: 362, col: 5, offset: 10225},
 									label: "n",
 									expr: &ruleRefExpr{
-										pos:  position{line: 360, col: 7, offset: 10181},
+										pos:  position{line: 362, col: 7, offset: 10227},
 										name: "NUMBER",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 360, col: 14, offset: 10188},
+									pos:  position{line: 362, col: 14, offset: 10234},
 									name: "SP",
 								},
 								&labeledExpr{
-									pos:   position{line: 360, col: 17, offset: 10191},
+									pos:   position{line: 362, col: 17, offset: 10237},
 									label: "i",
 									expr: &ruleRefExpr{
-										pos:  position{line: 360, col: 19, offset: 10193},
+										pos:  position{line: 362, col: 19, offset: 10239},
 										name: "ITEM",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 360, col: 24, offset: 10198},
+									pos:  position{line: 362, col: 24, offset: 10244},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 365, col: 5, offset: 10306},
+						pos: position{line: 367, col: 5, offset: 10352},
 						run: (*parser).callonStep234,
 						expr: &seqExpr{
-							pos: position{line: 365, col: 5, offset: 10306},
+							pos: position{line: 367, col: 5, offset: 10352},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 365, col: 5, offset: 10306},
+									pos:   position{line: 367, col: 5, offset: 10352},
 									label: "u",
 									expr: &ruleRefExpr{
-										pos:  position{line: 365, col: 7, offset: 10308},
+										pos:  position{line: 367, col: 7, offset: 10354},
 										name: "UNIT_ID",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 365, col: 15, offset: 10316},
+									pos:   position{line: 367, col: 15, offset: 10362},
 									label: "sui",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 365, col: 19, offset: 10320},
+										pos: position{line: 367, col: 19, offset: 10366},
 										expr: &ruleRefExpr{
-											pos:  position{line: 365, col: 19, offset: 10320},
+											pos:  position{line: 367, col: 19, offset: 10366},
 											name: "SpaceUnitID",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 365, col: 32, offset: 10333},
+									pos:  position{line: 367, col: 32, offset: 10379},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 376, col: 5, offset: 10650},
+						pos: position{line: 378, col: 5, offset: 10696},
 						run: (*parser).callonStep242,
 						expr: &seqExpr{
-							pos: position{line: 376, col: 5, offset: 10650},
+							pos: position{line: 378, col: 5, offset: 10696},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 376, col: 5, offset: 10650},
+									pos:   position{line: 378, col: 5, offset: 10696},
 									label: "lh",
 									expr: &ruleRefExpr{
-										pos:  position{line: 376, col: 8, offset: 10653},
+										pos:  position{line: 378, col: 8, offset: 10699},
 										name: "Longhouse",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 376, col: 18, offset: 10663},
+									pos:  position{line: 378, col: 18, offset: 10709},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 378, col: 5, offset: 10692},
+						pos: position{line: 380, col: 5, offset: 10738},
 						run: (*parser).callonStep247,
 						expr: &seqExpr{
-							pos: position{line: 378, col: 5, offset: 10692},
+							pos: position{line: 380, col: 5, offset: 10738},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 378, col: 5, offset: 10692},
+									pos:   position{line: 380, col: 5, offset: 10738},
 									label: "r",
 									expr: &ruleRefExpr{
-										pos:  position{line: 378, col: 7, offset: 10694},
+										pos:  position{line: 380, col: 7, offset: 10740},
 										name: "RESOURCE",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 378, col: 16, offset: 10703},
+									pos:  position{line: 380, col: 16, offset: 10749},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 380, col: 5, offset: 10731},
+						pos: position{line: 382, col: 5, offset: 10777},
 						run: (*parser).callonStep252,
 						expr: &seqExpr{
-							pos: position{line: 380, col: 5, offset: 10731},
+							pos: position{line: 382, col: 5, offset: 10777},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 380, col: 5, offset: 10731},
+									pos:   position{line: 382, col: 5, offset: 10777},
 									label: "d",
 									expr: &ruleRefExpr{
-										pos:  position{line: 380, col: 7, offset: 10733},
+										pos:  position{line: 382, col: 7, offset: 10779},
 										name: "DIRECTION",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 380, col: 17, offset: 10743},
+									pos:  position{line: 382, col: 17, offset: 10789},
 									name: "EOF",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 382, col: 5, offset: 10771},
+						pos: position{line: 384, col: 5, offset: 10817},
 						run: (*parser).callonStep257,
 						expr: &seqExpr{
-							pos: position{line: 382, col: 5, offset: 10771},
+							pos: position{line: 384, col: 5, offset: 10817},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 382, col: 5, offset: 10771},
+									pos:   position{line: 384, col: 5, offset: 10817},
 									label: "t",
 									expr: &ruleRefExpr{
-										pos:  position{line: 382, col: 7, offset: 10773},
+										pos:  position{line: 384, col: 7, offset: 10819},
 										name: "TERRAIN",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 382, col: 15, offset: 10781},
+									pos:  position{line: 384, col: 15, offset: 10827},
 									name: "EOF",
 								},
 							},
@@ -2087,37 +2089,37 @@ var g = &grammar{
 		},
 		{
 			name: "TribeFollows",
-			pos:  position{line: 386, col: 1, offset: 10808},
+			pos:  position{line: 388, col: 1, offset: 10854},
 			expr: &actionExpr{
-				pos: position{line: 386, col: 17, offset: 10824},
+				pos: position{line: 388, col: 17, offset: 10870},
 				run: (*parser).callonTribeFollows1,
 				expr: &seqExpr{
-					pos: position{line: 386, col: 17, offset: 10824},
+					pos: position{line: 388, col: 17, offset: 10870},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 386, col: 17, offset: 10824},
+							pos:        position{line: 388, col: 17, offset: 10870},
 							val:        "Tribe Follows",
 							ignoreCase: false,
 							want:       "\"Tribe Follows\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 386, col: 33, offset: 10840},
+							pos:  position{line: 388, col: 33, offset: 10886},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 386, col: 36, offset: 10843},
+							pos:   position{line: 388, col: 36, offset: 10889},
 							label: "u",
 							expr: &ruleRefExpr{
-								pos:  position{line: 386, col: 38, offset: 10845},
+								pos:  position{line: 388, col: 38, offset: 10891},
 								name: "UNIT_ID",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 386, col: 46, offset: 10853},
+							pos:  position{line: 388, col: 46, offset: 10899},
 							name: "_",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 386, col: 48, offset: 10855},
+							pos:  position{line: 388, col: 48, offset: 10901},
 							name: "EOF",
 						},
 					},
@@ -2126,37 +2128,37 @@ var g = &grammar{
 		},
 		{
 			name: "TribeGoesTo",
-			pos:  position{line: 391, col: 1, offset: 10956},
+			pos:  position{line: 393, col: 1, offset: 11002},
 			expr: &actionExpr{
-				pos: position{line: 391, col: 16, offset: 10971},
+				pos: position{line: 393, col: 16, offset: 11017},
 				run: (*parser).callonTribeGoesTo1,
 				expr: &seqExpr{
-					pos: position{line: 391, col: 16, offset: 10971},
+					pos: position{line: 393, col: 16, offset: 11017},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 391, col: 16, offset: 10971},
+							pos:        position{line: 393, col: 16, offset: 11017},
 							val:        "Tribe Goes to",
 							ignoreCase: false,
 							want:       "\"Tribe Goes to\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 391, col: 32, offset: 10987},
+							pos:  position{line: 393, col: 32, offset: 11033},
 							name: "SP",
 						},
 						&labeledExpr{
-							pos:   position{line: 391, col: 35, offset: 10990},
+							pos:   position{line: 393, col: 35, offset: 11036},
 							label: "h",
 							expr: &ruleRefExpr{
-								pos:  position{line: 391, col: 37, offset: 10992},
+								pos:  position{line: 393, col: 37, offset: 11038},
 								name: "COORDS",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 391, col: 44, offset: 10999},
+							pos:  position{line: 393, col: 44, offset: 11045},
 							name: "_",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 391, col: 46, offset: 11001},
+							pos:  position{line: 393, col: 46, offset: 11047},
 							name: "EOF",
 						},
 					},
@@ -2165,33 +2167,33 @@ var g = &grammar{
 		},
 		{
 			name: "TribeMovement",
-			pos:  position{line: 396, col: 1, offset: 11098},
+			pos:  position{line: 398, col: 1, offset: 11144},
 			expr: &actionExpr{
-				pos: position{line: 396, col: 18, offset: 11115},
+				pos: position{line: 398, col: 18, offset: 11161},
 				run: (*parser).callonTribeMovement1,
 				expr: &seqExpr{
-					pos: position{line: 396, col: 18, offset: 11115},
+					pos: position{line: 398, col: 18, offset: 11161},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 396, col: 18, offset: 11115},
+							pos:        position{line: 398, col: 18, offset: 11161},
 							val:        "Tribe Movement:",
 							ignoreCase: false,
 							want:       "\"Tribe Movement:\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 396, col: 36, offset: 11133},
+							pos:  position{line: 398, col: 36, offset: 11179},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 396, col: 38, offset: 11135},
+							pos:   position{line: 398, col: 38, offset: 11181},
 							label: "results",
 							expr: &ruleRefExpr{
-								pos:  position{line: 396, col: 46, offset: 11143},
+								pos:  position{line: 398, col: 46, offset: 11189},
 								name: "ToEOL",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 396, col: 52, offset: 11149},
+							pos:  position{line: 398, col: 52, offset: 11195},
 							name: "EOF",
 						},
 					},
@@ -2200,66 +2202,74 @@ var g = &grammar{
 		},
 		{
 			name: "TurnInfo",
-			pos:  position{line: 404, col: 1, offset: 11296},
+			pos:  position{line: 406, col: 1, offset: 11342},
 			expr: &actionExpr{
-				pos: position{line: 404, col: 13, offset: 11308},
+				pos: position{line: 406, col: 13, offset: 11354},
 				run: (*parser).callonTurnInfo1,
 				expr: &seqExpr{
-					pos: position{line: 404, col: 13, offset: 11308},
+					pos: position{line: 406, col: 13, offset: 11354},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 404, col: 13, offset: 11308},
+							pos:   position{line: 406, col: 13, offset: 11354},
 							label: "cd",
 							expr: &ruleRefExpr{
-								pos:  position{line: 404, col: 16, offset: 11311},
+								pos:  position{line: 406, col: 16, offset: 11357},
 								name: "CurrentTurn",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 404, col: 28, offset: 11323},
+							pos:        position{line: 406, col: 28, offset: 11369},
 							val:        ",",
 							ignoreCase: false,
 							want:       "\",\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 404, col: 32, offset: 11327},
+							pos:  position{line: 406, col: 32, offset: 11373},
 							name: "SP",
 						},
-						&ruleRefExpr{
-							pos:  position{line: 404, col: 35, offset: 11330},
-							name: "TurnSeason",
+						&labeledExpr{
+							pos:   position{line: 406, col: 35, offset: 11376},
+							label: "ts",
+							expr: &ruleRefExpr{
+								pos:  position{line: 406, col: 38, offset: 11379},
+								name: "TurnSeason",
+							},
 						},
 						&litMatcher{
-							pos:        position{line: 404, col: 46, offset: 11341},
+							pos:        position{line: 406, col: 49, offset: 11390},
 							val:        ",",
 							ignoreCase: false,
 							want:       "\",\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 404, col: 50, offset: 11345},
+							pos:  position{line: 406, col: 53, offset: 11394},
 							name: "SP",
 						},
-						&ruleRefExpr{
-							pos:  position{line: 404, col: 53, offset: 11348},
-							name: "TurnWeather",
+						&labeledExpr{
+							pos:   position{line: 406, col: 56, offset: 11397},
+							label: "tw",
+							expr: &ruleRefExpr{
+								pos:  position{line: 406, col: 59, offset: 11400},
+								name: "TurnWeather",
+							},
 						},
 						&labeledExpr{
-							pos:   position{line: 404, col: 65, offset: 11360},
+							pos:   position{line: 406, col: 71, offset: 11412},
 							label: "nt",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 404, col: 68, offset: 11363},
+								pos: position{line: 406, col: 74, offset: 11415},
 								expr: &ruleRefExpr{
-									pos:  position{line: 404, col: 68, offset: 11363},
+									pos:  position{line: 406, col: 74, offset: 11415},
 									name: "NextTurn",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 404, col: 78, offset: 11373},
+							pos:  position{line: 406, col: 84, offset: 11425},
 							name: "_",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 404, col: 80, offset: 11375},
+							pos:  position{line: 406, col: 86, offset: 11427},
 							name: "EOF",
 						},
 					},
@@ -2268,50 +2278,50 @@ var g = &grammar{
 		},
 		{
 			name: "CurrentTurn",
-			pos:  position{line: 417, col: 1, offset: 11593},
+			pos:  position{line: 418, col: 1, offset: 11660},
 			expr: &actionExpr{
-				pos: position{line: 417, col: 16, offset: 11608},
+				pos: position{line: 418, col: 16, offset: 11675},
 				run: (*parser).callonCurrentTurn1,
 				expr: &seqExpr{
-					pos: position{line: 417, col: 16, offset: 11608},
+					pos: position{line: 418, col: 16, offset: 11675},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 417, col: 16, offset: 11608},
+							pos:        position{line: 418, col: 16, offset: 11675},
 							val:        "Current Turn",
 							ignoreCase: false,
 							want:       "\"Current Turn\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 417, col: 31, offset: 11623},
+							pos:  position{line: 418, col: 31, offset: 11690},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 417, col: 33, offset: 11625},
+							pos:   position{line: 418, col: 33, offset: 11692},
 							label: "cd",
 							expr: &ruleRefExpr{
-								pos:  position{line: 417, col: 36, offset: 11628},
+								pos:  position{line: 418, col: 36, offset: 11695},
 								name: "YearMonth",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 417, col: 46, offset: 11638},
+							pos:  position{line: 418, col: 46, offset: 11705},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 417, col: 48, offset: 11640},
+							pos:        position{line: 418, col: 48, offset: 11707},
 							val:        "(#",
 							ignoreCase: false,
 							want:       "\"(#\"",
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 417, col: 53, offset: 11645},
+							pos: position{line: 418, col: 53, offset: 11712},
 							expr: &ruleRefExpr{
-								pos:  position{line: 417, col: 53, offset: 11645},
+								pos:  position{line: 418, col: 53, offset: 11712},
 								name: "DIGIT",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 417, col: 60, offset: 11652},
+							pos:        position{line: 418, col: 60, offset: 11719},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -2322,64 +2332,64 @@ var g = &grammar{
 		},
 		{
 			name: "NextTurn",
-			pos:  position{line: 421, col: 1, offset: 11680},
+			pos:  position{line: 422, col: 1, offset: 11747},
 			expr: &actionExpr{
-				pos: position{line: 421, col: 13, offset: 11692},
+				pos: position{line: 422, col: 13, offset: 11759},
 				run: (*parser).callonNextTurn1,
 				expr: &seqExpr{
-					pos: position{line: 421, col: 13, offset: 11692},
+					pos: position{line: 422, col: 13, offset: 11759},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 421, col: 13, offset: 11692},
+							pos:  position{line: 422, col: 13, offset: 11759},
 							name: "SP",
 						},
 						&litMatcher{
-							pos:        position{line: 421, col: 16, offset: 11695},
+							pos:        position{line: 422, col: 16, offset: 11762},
 							val:        "Next Turn",
 							ignoreCase: false,
 							want:       "\"Next Turn\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 421, col: 28, offset: 11707},
+							pos:  position{line: 422, col: 28, offset: 11774},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 421, col: 30, offset: 11709},
+							pos:   position{line: 422, col: 30, offset: 11776},
 							label: "nd",
 							expr: &ruleRefExpr{
-								pos:  position{line: 421, col: 33, offset: 11712},
+								pos:  position{line: 422, col: 33, offset: 11779},
 								name: "YearMonth",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 421, col: 43, offset: 11722},
+							pos:  position{line: 422, col: 43, offset: 11789},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 421, col: 45, offset: 11724},
+							pos:        position{line: 422, col: 45, offset: 11791},
 							val:        "(#",
 							ignoreCase: false,
 							want:       "\"(#\"",
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 421, col: 50, offset: 11729},
+							pos: position{line: 422, col: 50, offset: 11796},
 							expr: &ruleRefExpr{
-								pos:  position{line: 421, col: 50, offset: 11729},
+								pos:  position{line: 422, col: 50, offset: 11796},
 								name: "DIGIT",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 421, col: 57, offset: 11736},
+							pos:        position{line: 422, col: 57, offset: 11803},
 							val:        "),",
 							ignoreCase: false,
 							want:       "\"),\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 421, col: 62, offset: 11741},
+							pos:  position{line: 422, col: 62, offset: 11808},
 							name: "_",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 421, col: 64, offset: 11743},
+							pos:  position{line: 422, col: 64, offset: 11810},
 							name: "ReportDate",
 						},
 					},
@@ -2388,61 +2398,61 @@ var g = &grammar{
 		},
 		{
 			name: "ReportDate",
-			pos:  position{line: 425, col: 1, offset: 11778},
+			pos:  position{line: 426, col: 1, offset: 11845},
 			expr: &actionExpr{
-				pos: position{line: 425, col: 15, offset: 11792},
+				pos: position{line: 426, col: 15, offset: 11859},
 				run: (*parser).callonReportDate1,
 				expr: &seqExpr{
-					pos: position{line: 425, col: 15, offset: 11792},
+					pos: position{line: 426, col: 15, offset: 11859},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 425, col: 15, offset: 11792},
+							pos:  position{line: 426, col: 15, offset: 11859},
 							name: "DIGIT",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 425, col: 21, offset: 11798},
+							pos: position{line: 426, col: 21, offset: 11865},
 							expr: &ruleRefExpr{
-								pos:  position{line: 425, col: 21, offset: 11798},
+								pos:  position{line: 426, col: 21, offset: 11865},
 								name: "DIGIT",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 425, col: 28, offset: 11805},
+							pos:        position{line: 426, col: 28, offset: 11872},
 							val:        "/",
 							ignoreCase: false,
 							want:       "\"/\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 425, col: 32, offset: 11809},
+							pos:  position{line: 426, col: 32, offset: 11876},
 							name: "DIGIT",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 425, col: 38, offset: 11815},
+							pos: position{line: 426, col: 38, offset: 11882},
 							expr: &ruleRefExpr{
-								pos:  position{line: 425, col: 38, offset: 11815},
+								pos:  position{line: 426, col: 38, offset: 11882},
 								name: "DIGIT",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 425, col: 45, offset: 11822},
+							pos:        position{line: 426, col: 45, offset: 11889},
 							val:        "/",
 							ignoreCase: false,
 							want:       "\"/\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 425, col: 49, offset: 11826},
+							pos:  position{line: 426, col: 49, offset: 11893},
 							name: "DIGIT",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 425, col: 55, offset: 11832},
+							pos:  position{line: 426, col: 55, offset: 11899},
 							name: "DIGIT",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 425, col: 61, offset: 11838},
+							pos:  position{line: 426, col: 61, offset: 11905},
 							name: "DIGIT",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 425, col: 67, offset: 11844},
+							pos:  position{line: 426, col: 67, offset: 11911},
 							name: "DIGIT",
 						},
 					},
@@ -2451,21 +2461,21 @@ var g = &grammar{
 		},
 		{
 			name: "ToEOL",
-			pos:  position{line: 430, col: 1, offset: 11922},
+			pos:  position{line: 431, col: 1, offset: 11989},
 			expr: &actionExpr{
-				pos: position{line: 430, col: 10, offset: 11931},
+				pos: position{line: 431, col: 10, offset: 11998},
 				run: (*parser).callonToEOL1,
 				expr: &seqExpr{
-					pos: position{line: 430, col: 10, offset: 11931},
+					pos: position{line: 431, col: 10, offset: 11998},
 					exprs: []any{
 						&zeroOrMoreExpr{
-							pos: position{line: 430, col: 10, offset: 11931},
+							pos: position{line: 431, col: 10, offset: 11998},
 							expr: &anyMatcher{
 								line: 430, col: 10, offset: 11931,
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 430, col: 13, offset: 11934},
+							pos:  position{line: 431, col: 13, offset: 12001},
 							name: "EOF",
 						},
 					},
@@ -2474,24 +2484,24 @@ var g = &grammar{
 		},
 		{
 			name: "TurnSeason",
-			pos:  position{line: 434, col: 1, offset: 11966},
+			pos:  position{line: 435, col: 1, offset: 12033},
 			expr: &actionExpr{
-				pos: position{line: 434, col: 15, offset: 11980},
+				pos: position{line: 435, col: 15, offset: 12047},
 				run: (*parser).callonTurnSeason1,
 				expr: &seqExpr{
-					pos: position{line: 434, col: 15, offset: 11980},
+					pos: position{line: 435, col: 15, offset: 12047},
 					exprs: []any{
 						&charClassMatcher{
-							pos:        position{line: 434, col: 15, offset: 11980},
+							pos:        position{line: 435, col: 15, offset: 12047},
 							val:        "[A-Z]",
 							ranges:     []rune{'A', 'Z'},
 							ignoreCase: false,
 							inverted:   false,
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 434, col: 20, offset: 11985},
+							pos: position{line: 435, col: 20, offset: 12052},
 							expr: &charClassMatcher{
-								pos:        position{line: 434, col: 20, offset: 11985},
+								pos:        position{line: 435, col: 20, offset: 12052},
 								val:        "[A-Za-z]",
 								ranges:     []rune{'A', 'Z', 'a', 'z'},
 								ignoreCase: false,
@@ -2504,24 +2514,24 @@ var g = &grammar{
 		},
 		{
 			name: "TurnWeather",
-			pos:  position{line: 439, col: 1, offset: 12067},
+			pos:  position{line: 440, col: 1, offset: 12134},
 			expr: &actionExpr{
-				pos: position{line: 439, col: 16, offset: 12082},
+				pos: position{line: 440, col: 16, offset: 12149},
 				run: (*parser).callonTurnWeather1,
 				expr: &seqExpr{
-					pos: position{line: 439, col: 16, offset: 12082},
+					pos: position{line: 440, col: 16, offset: 12149},
 					exprs: []any{
 						&charClassMatcher{
-							pos:        position{line: 439, col: 16, offset: 12082},
+							pos:        position{line: 440, col: 16, offset: 12149},
 							val:        "[A-Z]",
 							ranges:     []rune{'A', 'Z'},
 							ignoreCase: false,
 							inverted:   false,
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 439, col: 21, offset: 12087},
+							pos: position{line: 440, col: 21, offset: 12154},
 							expr: &charClassMatcher{
-								pos:        position{line: 439, col: 21, offset: 12087},
+								pos:        position{line: 440, col: 21, offset: 12154},
 								val:        "[A-Za-z-]",
 								chars:      []rune{'-'},
 								ranges:     []rune{'A', 'Z', 'a', 'z'},
@@ -2535,32 +2545,32 @@ var g = &grammar{
 		},
 		{
 			name: "YearMonth",
-			pos:  position{line: 444, col: 1, offset: 12171},
+			pos:  position{line: 445, col: 1, offset: 12238},
 			expr: &actionExpr{
-				pos: position{line: 444, col: 14, offset: 12184},
+				pos: position{line: 445, col: 14, offset: 12251},
 				run: (*parser).callonYearMonth1,
 				expr: &seqExpr{
-					pos: position{line: 444, col: 14, offset: 12184},
+					pos: position{line: 445, col: 14, offset: 12251},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 444, col: 14, offset: 12184},
+							pos:   position{line: 445, col: 14, offset: 12251},
 							label: "y",
 							expr: &ruleRefExpr{
-								pos:  position{line: 444, col: 16, offset: 12186},
+								pos:  position{line: 445, col: 16, offset: 12253},
 								name: "YEAR",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 444, col: 21, offset: 12191},
+							pos:        position{line: 445, col: 21, offset: 12258},
 							val:        "-",
 							ignoreCase: false,
 							want:       "\"-\"",
 						},
 						&labeledExpr{
-							pos:   position{line: 444, col: 25, offset: 12195},
+							pos:   position{line: 445, col: 25, offset: 12262},
 							label: "m",
 							expr: &ruleRefExpr{
-								pos:  position{line: 444, col: 27, offset: 12197},
+								pos:  position{line: 445, col: 27, offset: 12264},
 								name: "MONTH",
 							},
 						},
@@ -2570,125 +2580,125 @@ var g = &grammar{
 		},
 		{
 			name: "COMPASSPOINT",
-			pos:  position{line: 451, col: 1, offset: 12287},
+			pos:  position{line: 452, col: 1, offset: 12354},
 			expr: &choiceExpr{
-				pos: position{line: 451, col: 17, offset: 12303},
+				pos: position{line: 452, col: 17, offset: 12370},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 451, col: 17, offset: 12303},
+						pos: position{line: 452, col: 17, offset: 12370},
 						run: (*parser).callonCOMPASSPOINT2,
 						expr: &litMatcher{
-							pos:        position{line: 451, col: 17, offset: 12303},
+							pos:        position{line: 452, col: 17, offset: 12370},
 							val:        "NE/NE",
 							ignoreCase: false,
 							want:       "\"NE/NE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 453, col: 5, offset: 12351},
+						pos: position{line: 454, col: 5, offset: 12418},
 						run: (*parser).callonCOMPASSPOINT4,
 						expr: &litMatcher{
-							pos:        position{line: 453, col: 5, offset: 12351},
+							pos:        position{line: 454, col: 5, offset: 12418},
 							val:        "NE/SE",
 							ignoreCase: false,
 							want:       "\"NE/SE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 455, col: 5, offset: 12394},
+						pos: position{line: 456, col: 5, offset: 12461},
 						run: (*parser).callonCOMPASSPOINT6,
 						expr: &litMatcher{
-							pos:        position{line: 455, col: 5, offset: 12394},
+							pos:        position{line: 456, col: 5, offset: 12461},
 							val:        "NW/NW",
 							ignoreCase: false,
 							want:       "\"NW/NW\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 457, col: 5, offset: 12442},
+						pos: position{line: 458, col: 5, offset: 12509},
 						run: (*parser).callonCOMPASSPOINT8,
 						expr: &litMatcher{
-							pos:        position{line: 457, col: 5, offset: 12442},
+							pos:        position{line: 458, col: 5, offset: 12509},
 							val:        "N/NE",
 							ignoreCase: false,
 							want:       "\"N/NE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 459, col: 5, offset: 12494},
+						pos: position{line: 460, col: 5, offset: 12561},
 						run: (*parser).callonCOMPASSPOINT10,
 						expr: &litMatcher{
-							pos:        position{line: 459, col: 5, offset: 12494},
+							pos:        position{line: 460, col: 5, offset: 12561},
 							val:        "N/NW",
 							ignoreCase: false,
 							want:       "\"N/NW\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 461, col: 5, offset: 12546},
+						pos: position{line: 462, col: 5, offset: 12613},
 						run: (*parser).callonCOMPASSPOINT12,
 						expr: &litMatcher{
-							pos:        position{line: 461, col: 5, offset: 12546},
+							pos:        position{line: 462, col: 5, offset: 12613},
 							val:        "N/N",
 							ignoreCase: false,
 							want:       "\"N/N\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 463, col: 5, offset: 12588},
+						pos: position{line: 464, col: 5, offset: 12655},
 						run: (*parser).callonCOMPASSPOINT14,
 						expr: &litMatcher{
-							pos:        position{line: 463, col: 5, offset: 12588},
+							pos:        position{line: 464, col: 5, offset: 12655},
 							val:        "SE/SE",
 							ignoreCase: false,
 							want:       "\"SE/SE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 465, col: 5, offset: 12636},
+						pos: position{line: 466, col: 5, offset: 12703},
 						run: (*parser).callonCOMPASSPOINT16,
 						expr: &litMatcher{
-							pos:        position{line: 465, col: 5, offset: 12636},
+							pos:        position{line: 466, col: 5, offset: 12703},
 							val:        "SW/NW",
 							ignoreCase: false,
 							want:       "\"SW/NW\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 467, col: 5, offset: 12679},
+						pos: position{line: 468, col: 5, offset: 12746},
 						run: (*parser).callonCOMPASSPOINT18,
 						expr: &litMatcher{
-							pos:        position{line: 467, col: 5, offset: 12679},
+							pos:        position{line: 468, col: 5, offset: 12746},
 							val:        "SW/SW",
 							ignoreCase: false,
 							want:       "\"SW/SW\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 469, col: 5, offset: 12727},
+						pos: position{line: 470, col: 5, offset: 12794},
 						run: (*parser).callonCOMPASSPOINT20,
 						expr: &litMatcher{
-							pos:        position{line: 469, col: 5, offset: 12727},
+							pos:        position{line: 470, col: 5, offset: 12794},
 							val:        "S/SE",
 							ignoreCase: false,
 							want:       "\"S/SE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 471, col: 5, offset: 12779},
+						pos: position{line: 472, col: 5, offset: 12846},
 						run: (*parser).callonCOMPASSPOINT22,
 						expr: &litMatcher{
-							pos:        position{line: 471, col: 5, offset: 12779},
+							pos:        position{line: 472, col: 5, offset: 12846},
 							val:        "S/SW",
 							ignoreCase: false,
 							want:       "\"S/SW\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 473, col: 5, offset: 12831},
+						pos: position{line: 474, col: 5, offset: 12898},
 						run: (*parser).callonCOMPASSPOINT24,
 						expr: &litMatcher{
-							pos:        position{line: 473, col: 5, offset: 12831},
+							pos:        position{line: 474, col: 5, offset: 12898},
 							val:        "S/S",
 							ignoreCase: false,
 							want:       "\"S/S\"",
@@ -2699,87 +2709,87 @@ var g = &grammar{
 		},
 		{
 			name: "COORDS",
-			pos:  position{line: 477, col: 1, offset: 12872},
+			pos:  position{line: 478, col: 1, offset: 12939},
 			expr: &choiceExpr{
-				pos: position{line: 477, col: 11, offset: 12882},
+				pos: position{line: 478, col: 11, offset: 12949},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 477, col: 11, offset: 12882},
+						pos: position{line: 478, col: 11, offset: 12949},
 						run: (*parser).callonCOORDS2,
 						expr: &litMatcher{
-							pos:        position{line: 477, col: 11, offset: 12882},
+							pos:        position{line: 478, col: 11, offset: 12949},
 							val:        "N/A",
 							ignoreCase: false,
 							want:       "\"N/A\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 479, col: 5, offset: 12916},
+						pos: position{line: 480, col: 5, offset: 12983},
 						run: (*parser).callonCOORDS4,
 						expr: &seqExpr{
-							pos: position{line: 479, col: 5, offset: 12916},
+							pos: position{line: 480, col: 5, offset: 12983},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 479, col: 5, offset: 12916},
+									pos:        position{line: 480, col: 5, offset: 12983},
 									val:        "##",
 									ignoreCase: false,
 									want:       "\"##\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 479, col: 10, offset: 12921},
+									pos:  position{line: 480, col: 10, offset: 12988},
 									name: "SP",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 479, col: 13, offset: 12924},
+									pos:  position{line: 480, col: 13, offset: 12991},
 									name: "DIGIT",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 479, col: 19, offset: 12930},
+									pos:  position{line: 480, col: 19, offset: 12997},
 									name: "DIGIT",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 479, col: 25, offset: 12936},
+									pos:  position{line: 480, col: 25, offset: 13003},
 									name: "DIGIT",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 479, col: 31, offset: 12942},
+									pos:  position{line: 480, col: 31, offset: 13009},
 									name: "DIGIT",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 481, col: 5, offset: 12985},
+						pos: position{line: 482, col: 5, offset: 13052},
 						run: (*parser).callonCOORDS12,
 						expr: &seqExpr{
-							pos: position{line: 481, col: 5, offset: 12985},
+							pos: position{line: 482, col: 5, offset: 13052},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 481, col: 5, offset: 12985},
+									pos:  position{line: 482, col: 5, offset: 13052},
 									name: "LETTER",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 481, col: 12, offset: 12992},
+									pos:  position{line: 482, col: 12, offset: 13059},
 									name: "LETTER",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 481, col: 19, offset: 12999},
+									pos:  position{line: 482, col: 19, offset: 13066},
 									name: "SP",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 481, col: 22, offset: 13002},
+									pos:  position{line: 482, col: 22, offset: 13069},
 									name: "DIGIT",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 481, col: 28, offset: 13008},
+									pos:  position{line: 482, col: 28, offset: 13075},
 									name: "DIGIT",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 481, col: 34, offset: 13014},
+									pos:  position{line: 482, col: 34, offset: 13081},
 									name: "DIGIT",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 481, col: 40, offset: 13020},
+									pos:  position{line: 482, col: 40, offset: 13087},
 									name: "DIGIT",
 								},
 							},
@@ -2790,25 +2800,25 @@ var g = &grammar{
 		},
 		{
 			name: "CROWSIGHTING",
-			pos:  position{line: 485, col: 1, offset: 13062},
+			pos:  position{line: 486, col: 1, offset: 13129},
 			expr: &choiceExpr{
-				pos: position{line: 485, col: 17, offset: 13078},
+				pos: position{line: 486, col: 17, offset: 13145},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 485, col: 17, offset: 13078},
+						pos: position{line: 486, col: 17, offset: 13145},
 						run: (*parser).callonCROWSIGHTING2,
 						expr: &litMatcher{
-							pos:        position{line: 485, col: 17, offset: 13078},
+							pos:        position{line: 486, col: 17, offset: 13145},
 							val:        "Sight Land",
 							ignoreCase: false,
 							want:       "\"Sight Land\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 487, col: 5, offset: 13133},
+						pos: position{line: 488, col: 5, offset: 13200},
 						run: (*parser).callonCROWSIGHTING4,
 						expr: &litMatcher{
-							pos:        position{line: 487, col: 5, offset: 13133},
+							pos:        position{line: 488, col: 5, offset: 13200},
 							val:        "Sight Water",
 							ignoreCase: false,
 							want:       "\"Sight Water\"",
@@ -2819,65 +2829,65 @@ var g = &grammar{
 		},
 		{
 			name: "DIRECTION",
-			pos:  position{line: 491, col: 1, offset: 13189},
+			pos:  position{line: 492, col: 1, offset: 13256},
 			expr: &choiceExpr{
-				pos: position{line: 491, col: 14, offset: 13202},
+				pos: position{line: 492, col: 14, offset: 13269},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 491, col: 14, offset: 13202},
+						pos: position{line: 492, col: 14, offset: 13269},
 						run: (*parser).callonDIRECTION2,
 						expr: &litMatcher{
-							pos:        position{line: 491, col: 14, offset: 13202},
+							pos:        position{line: 492, col: 14, offset: 13269},
 							val:        "ne",
 							ignoreCase: true,
 							want:       "\"NE\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 493, col: 5, offset: 13250},
+						pos: position{line: 494, col: 5, offset: 13317},
 						run: (*parser).callonDIRECTION4,
 						expr: &litMatcher{
-							pos:        position{line: 493, col: 5, offset: 13250},
+							pos:        position{line: 494, col: 5, offset: 13317},
 							val:        "se",
 							ignoreCase: true,
 							want:       "\"SE\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 495, col: 5, offset: 13298},
+						pos: position{line: 496, col: 5, offset: 13365},
 						run: (*parser).callonDIRECTION6,
 						expr: &litMatcher{
-							pos:        position{line: 495, col: 5, offset: 13298},
+							pos:        position{line: 496, col: 5, offset: 13365},
 							val:        "sw",
 							ignoreCase: true,
 							want:       "\"SW\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 497, col: 5, offset: 13346},
+						pos: position{line: 498, col: 5, offset: 13413},
 						run: (*parser).callonDIRECTION8,
 						expr: &litMatcher{
-							pos:        position{line: 497, col: 5, offset: 13346},
+							pos:        position{line: 498, col: 5, offset: 13413},
 							val:        "nw",
 							ignoreCase: true,
 							want:       "\"NW\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 499, col: 5, offset: 13394},
+						pos: position{line: 500, col: 5, offset: 13461},
 						run: (*parser).callonDIRECTION10,
 						expr: &litMatcher{
-							pos:        position{line: 499, col: 5, offset: 13394},
+							pos:        position{line: 500, col: 5, offset: 13461},
 							val:        "n",
 							ignoreCase: true,
 							want:       "\"N\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 501, col: 5, offset: 13437},
+						pos: position{line: 502, col: 5, offset: 13504},
 						run: (*parser).callonDIRECTION12,
 						expr: &litMatcher{
-							pos:        position{line: 501, col: 5, offset: 13437},
+							pos:        position{line: 502, col: 5, offset: 13504},
 							val:        "s",
 							ignoreCase: true,
 							want:       "\"S\"i",
@@ -2888,1375 +2898,1375 @@ var g = &grammar{
 		},
 		{
 			name: "ITEM",
-			pos:  position{line: 505, col: 1, offset: 13479},
+			pos:  position{line: 506, col: 1, offset: 13546},
 			expr: &choiceExpr{
-				pos: position{line: 505, col: 9, offset: 13487},
+				pos: position{line: 506, col: 9, offset: 13554},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 505, col: 9, offset: 13487},
+						pos: position{line: 506, col: 9, offset: 13554},
 						run: (*parser).callonITEM2,
 						expr: &litMatcher{
-							pos:        position{line: 505, col: 9, offset: 13487},
+							pos:        position{line: 506, col: 9, offset: 13554},
 							val:        "adze",
 							ignoreCase: true,
 							want:       "\"adze\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 506, col: 6, offset: 13533},
+						pos: position{line: 507, col: 6, offset: 13600},
 						run: (*parser).callonITEM4,
 						expr: &litMatcher{
-							pos:        position{line: 506, col: 6, offset: 13533},
+							pos:        position{line: 507, col: 6, offset: 13600},
 							val:        "arbalest",
 							ignoreCase: true,
 							want:       "\"arbalest\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 507, col: 6, offset: 13583},
+						pos: position{line: 508, col: 6, offset: 13650},
 						run: (*parser).callonITEM6,
 						expr: &litMatcher{
-							pos:        position{line: 507, col: 6, offset: 13583},
+							pos:        position{line: 508, col: 6, offset: 13650},
 							val:        "arrows",
 							ignoreCase: true,
 							want:       "\"arrows\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 508, col: 6, offset: 13631},
+						pos: position{line: 509, col: 6, offset: 13698},
 						run: (*parser).callonITEM8,
 						expr: &litMatcher{
-							pos:        position{line: 508, col: 6, offset: 13631},
+							pos:        position{line: 509, col: 6, offset: 13698},
 							val:        "axes",
 							ignoreCase: true,
 							want:       "\"axes\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 509, col: 6, offset: 13677},
+						pos: position{line: 510, col: 6, offset: 13744},
 						run: (*parser).callonITEM10,
 						expr: &litMatcher{
-							pos:        position{line: 509, col: 6, offset: 13677},
+							pos:        position{line: 510, col: 6, offset: 13744},
 							val:        "backpack",
 							ignoreCase: true,
 							want:       "\"backpack\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 510, col: 6, offset: 13727},
+						pos: position{line: 511, col: 6, offset: 13794},
 						run: (*parser).callonITEM12,
 						expr: &litMatcher{
-							pos:        position{line: 510, col: 6, offset: 13727},
+							pos:        position{line: 511, col: 6, offset: 13794},
 							val:        "ballistae",
 							ignoreCase: true,
 							want:       "\"ballistae\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 511, col: 6, offset: 13778},
+						pos: position{line: 512, col: 6, offset: 13845},
 						run: (*parser).callonITEM14,
 						expr: &litMatcher{
-							pos:        position{line: 511, col: 6, offset: 13778},
+							pos:        position{line: 512, col: 6, offset: 13845},
 							val:        "bark",
 							ignoreCase: true,
 							want:       "\"bark\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 512, col: 6, offset: 13824},
+						pos: position{line: 513, col: 6, offset: 13891},
 						run: (*parser).callonITEM16,
 						expr: &litMatcher{
-							pos:        position{line: 512, col: 6, offset: 13824},
+							pos:        position{line: 513, col: 6, offset: 13891},
 							val:        "barrel",
 							ignoreCase: true,
 							want:       "\"barrel\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 513, col: 6, offset: 13872},
+						pos: position{line: 514, col: 6, offset: 13939},
 						run: (*parser).callonITEM18,
 						expr: &litMatcher{
-							pos:        position{line: 513, col: 6, offset: 13872},
+							pos:        position{line: 514, col: 6, offset: 13939},
 							val:        "bladder",
 							ignoreCase: true,
 							want:       "\"bladder\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 514, col: 6, offset: 13921},
+						pos: position{line: 515, col: 6, offset: 13988},
 						run: (*parser).callonITEM20,
 						expr: &litMatcher{
-							pos:        position{line: 514, col: 6, offset: 13921},
+							pos:        position{line: 515, col: 6, offset: 13988},
 							val:        "blubber",
 							ignoreCase: true,
 							want:       "\"blubber\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 515, col: 6, offset: 13970},
+						pos: position{line: 516, col: 6, offset: 14037},
 						run: (*parser).callonITEM22,
 						expr: &litMatcher{
-							pos:        position{line: 515, col: 6, offset: 13970},
+							pos:        position{line: 516, col: 6, offset: 14037},
 							val:        "boat",
 							ignoreCase: true,
 							want:       "\"boat\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 516, col: 6, offset: 14016},
+						pos: position{line: 517, col: 6, offset: 14083},
 						run: (*parser).callonITEM24,
 						expr: &litMatcher{
-							pos:        position{line: 516, col: 6, offset: 14016},
+							pos:        position{line: 517, col: 6, offset: 14083},
 							val:        "bonearmour",
 							ignoreCase: true,
 							want:       "\"bonearmour\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 517, col: 6, offset: 14068},
+						pos: position{line: 518, col: 6, offset: 14135},
 						run: (*parser).callonITEM26,
 						expr: &litMatcher{
-							pos:        position{line: 517, col: 6, offset: 14068},
+							pos:        position{line: 518, col: 6, offset: 14135},
 							val:        "bones",
 							ignoreCase: true,
 							want:       "\"bones\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 518, col: 6, offset: 14115},
+						pos: position{line: 519, col: 6, offset: 14182},
 						run: (*parser).callonITEM28,
 						expr: &litMatcher{
-							pos:        position{line: 518, col: 6, offset: 14115},
+							pos:        position{line: 519, col: 6, offset: 14182},
 							val:        "bows",
 							ignoreCase: true,
 							want:       "\"bows\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 519, col: 6, offset: 14161},
+						pos: position{line: 520, col: 6, offset: 14228},
 						run: (*parser).callonITEM30,
 						expr: &litMatcher{
-							pos:        position{line: 519, col: 6, offset: 14161},
+							pos:        position{line: 520, col: 6, offset: 14228},
 							val:        "bread",
 							ignoreCase: true,
 							want:       "\"bread\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 520, col: 6, offset: 14208},
+						pos: position{line: 521, col: 6, offset: 14275},
 						run: (*parser).callonITEM32,
 						expr: &litMatcher{
-							pos:        position{line: 520, col: 6, offset: 14208},
+							pos:        position{line: 521, col: 6, offset: 14275},
 							val:        "breastplate",
 							ignoreCase: true,
 							want:       "\"breastplate\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 521, col: 6, offset: 14261},
+						pos: position{line: 522, col: 6, offset: 14328},
 						run: (*parser).callonITEM34,
 						expr: &litMatcher{
-							pos:        position{line: 521, col: 6, offset: 14261},
+							pos:        position{line: 522, col: 6, offset: 14328},
 							val:        "candle",
 							ignoreCase: true,
 							want:       "\"candle\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 522, col: 6, offset: 14309},
+						pos: position{line: 523, col: 6, offset: 14376},
 						run: (*parser).callonITEM36,
 						expr: &litMatcher{
-							pos:        position{line: 522, col: 6, offset: 14309},
+							pos:        position{line: 523, col: 6, offset: 14376},
 							val:        "canoes",
 							ignoreCase: true,
 							want:       "\"canoes\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 523, col: 6, offset: 14357},
+						pos: position{line: 524, col: 6, offset: 14424},
 						run: (*parser).callonITEM38,
 						expr: &litMatcher{
-							pos:        position{line: 523, col: 6, offset: 14357},
+							pos:        position{line: 524, col: 6, offset: 14424},
 							val:        "carpets",
 							ignoreCase: true,
 							want:       "\"carpets\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 524, col: 6, offset: 14406},
+						pos: position{line: 525, col: 6, offset: 14473},
 						run: (*parser).callonITEM40,
 						expr: &litMatcher{
-							pos:        position{line: 524, col: 6, offset: 14406},
+							pos:        position{line: 525, col: 6, offset: 14473},
 							val:        "catapult",
 							ignoreCase: true,
 							want:       "\"catapult\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 525, col: 6, offset: 14456},
+						pos: position{line: 526, col: 6, offset: 14523},
 						run: (*parser).callonITEM42,
 						expr: &litMatcher{
-							pos:        position{line: 525, col: 6, offset: 14456},
+							pos:        position{line: 526, col: 6, offset: 14523},
 							val:        "cattle",
 							ignoreCase: true,
 							want:       "\"cattle\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 526, col: 6, offset: 14504},
+						pos: position{line: 527, col: 6, offset: 14571},
 						run: (*parser).callonITEM44,
 						expr: &litMatcher{
-							pos:        position{line: 526, col: 6, offset: 14504},
+							pos:        position{line: 527, col: 6, offset: 14571},
 							val:        "cauldrons",
 							ignoreCase: true,
 							want:       "\"cauldrons\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 527, col: 6, offset: 14555},
+						pos: position{line: 528, col: 6, offset: 14622},
 						run: (*parser).callonITEM46,
 						expr: &litMatcher{
-							pos:        position{line: 527, col: 6, offset: 14555},
+							pos:        position{line: 528, col: 6, offset: 14622},
 							val:        "chain",
 							ignoreCase: true,
 							want:       "\"chain\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 528, col: 6, offset: 14602},
+						pos: position{line: 529, col: 6, offset: 14669},
 						run: (*parser).callonITEM48,
 						expr: &litMatcher{
-							pos:        position{line: 528, col: 6, offset: 14602},
+							pos:        position{line: 529, col: 6, offset: 14669},
 							val:        "china",
 							ignoreCase: true,
 							want:       "\"china\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 529, col: 6, offset: 14649},
+						pos: position{line: 530, col: 6, offset: 14716},
 						run: (*parser).callonITEM50,
 						expr: &litMatcher{
-							pos:        position{line: 529, col: 6, offset: 14649},
+							pos:        position{line: 530, col: 6, offset: 14716},
 							val:        "clay",
 							ignoreCase: true,
 							want:       "\"clay\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 530, col: 6, offset: 14695},
+						pos: position{line: 531, col: 6, offset: 14762},
 						run: (*parser).callonITEM52,
 						expr: &litMatcher{
-							pos:        position{line: 530, col: 6, offset: 14695},
+							pos:        position{line: 531, col: 6, offset: 14762},
 							val:        "cloth",
 							ignoreCase: true,
 							want:       "\"cloth\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 531, col: 6, offset: 14742},
+						pos: position{line: 532, col: 6, offset: 14809},
 						run: (*parser).callonITEM54,
 						expr: &litMatcher{
-							pos:        position{line: 531, col: 6, offset: 14742},
+							pos:        position{line: 532, col: 6, offset: 14809},
 							val:        "clubs",
 							ignoreCase: true,
 							want:       "\"clubs\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 532, col: 6, offset: 14789},
+						pos: position{line: 533, col: 6, offset: 14856},
 						run: (*parser).callonITEM56,
 						expr: &litMatcher{
-							pos:        position{line: 532, col: 6, offset: 14789},
+							pos:        position{line: 533, col: 6, offset: 14856},
 							val:        "coal",
 							ignoreCase: true,
 							want:       "\"coal\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 533, col: 6, offset: 14835},
+						pos: position{line: 534, col: 6, offset: 14902},
 						run: (*parser).callonITEM58,
 						expr: &litMatcher{
-							pos:        position{line: 533, col: 6, offset: 14835},
+							pos:        position{line: 534, col: 6, offset: 14902},
 							val:        "coffee",
 							ignoreCase: true,
 							want:       "\"coffee\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 534, col: 6, offset: 14883},
+						pos: position{line: 535, col: 6, offset: 14950},
 						run: (*parser).callonITEM60,
 						expr: &litMatcher{
-							pos:        position{line: 534, col: 6, offset: 14883},
+							pos:        position{line: 535, col: 6, offset: 14950},
 							val:        "coins",
 							ignoreCase: true,
 							want:       "\"coins\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 535, col: 6, offset: 14930},
+						pos: position{line: 536, col: 6, offset: 14997},
 						run: (*parser).callonITEM62,
 						expr: &litMatcher{
-							pos:        position{line: 535, col: 6, offset: 14930},
+							pos:        position{line: 536, col: 6, offset: 14997},
 							val:        "cotton",
 							ignoreCase: true,
 							want:       "\"cotton\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 536, col: 6, offset: 14978},
+						pos: position{line: 537, col: 6, offset: 15045},
 						run: (*parser).callonITEM64,
 						expr: &litMatcher{
-							pos:        position{line: 536, col: 6, offset: 14978},
+							pos:        position{line: 537, col: 6, offset: 15045},
 							val:        "cuirass",
 							ignoreCase: true,
 							want:       "\"cuirass\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 537, col: 6, offset: 15027},
+						pos: position{line: 538, col: 6, offset: 15094},
 						run: (*parser).callonITEM66,
 						expr: &litMatcher{
-							pos:        position{line: 537, col: 6, offset: 15027},
+							pos:        position{line: 538, col: 6, offset: 15094},
 							val:        "cuirboilli",
 							ignoreCase: true,
 							want:       "\"cuirboilli\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 538, col: 6, offset: 15079},
+						pos: position{line: 539, col: 6, offset: 15146},
 						run: (*parser).callonITEM68,
 						expr: &litMatcher{
-							pos:        position{line: 538, col: 6, offset: 15079},
+							pos:        position{line: 539, col: 6, offset: 15146},
 							val:        "diamond",
 							ignoreCase: true,
 							want:       "\"diamond\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 539, col: 6, offset: 15128},
+						pos: position{line: 540, col: 6, offset: 15195},
 						run: (*parser).callonITEM70,
 						expr: &litMatcher{
-							pos:        position{line: 539, col: 6, offset: 15128},
+							pos:        position{line: 540, col: 6, offset: 15195},
 							val:        "diamonds",
 							ignoreCase: true,
 							want:       "\"diamonds\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 540, col: 6, offset: 15178},
+						pos: position{line: 541, col: 6, offset: 15245},
 						run: (*parser).callonITEM72,
 						expr: &litMatcher{
-							pos:        position{line: 540, col: 6, offset: 15178},
+							pos:        position{line: 541, col: 6, offset: 15245},
 							val:        "drum",
 							ignoreCase: true,
 							want:       "\"drum\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 541, col: 6, offset: 15224},
+						pos: position{line: 542, col: 6, offset: 15291},
 						run: (*parser).callonITEM74,
 						expr: &litMatcher{
-							pos:        position{line: 541, col: 6, offset: 15224},
+							pos:        position{line: 542, col: 6, offset: 15291},
 							val:        "elephant",
 							ignoreCase: true,
 							want:       "\"elephant\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 542, col: 6, offset: 15274},
+						pos: position{line: 543, col: 6, offset: 15341},
 						run: (*parser).callonITEM76,
 						expr: &litMatcher{
-							pos:        position{line: 542, col: 6, offset: 15274},
+							pos:        position{line: 543, col: 6, offset: 15341},
 							val:        "falchion",
 							ignoreCase: true,
 							want:       "\"falchion\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 543, col: 6, offset: 15324},
+						pos: position{line: 544, col: 6, offset: 15391},
 						run: (*parser).callonITEM78,
 						expr: &litMatcher{
-							pos:        position{line: 543, col: 6, offset: 15324},
+							pos:        position{line: 544, col: 6, offset: 15391},
 							val:        "fish",
 							ignoreCase: true,
 							want:       "\"fish\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 544, col: 6, offset: 15370},
+						pos: position{line: 545, col: 6, offset: 15437},
 						run: (*parser).callonITEM80,
 						expr: &litMatcher{
-							pos:        position{line: 544, col: 6, offset: 15370},
+							pos:        position{line: 545, col: 6, offset: 15437},
 							val:        "flax",
 							ignoreCase: true,
 							want:       "\"flax\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 545, col: 6, offset: 15416},
+						pos: position{line: 546, col: 6, offset: 15483},
 						run: (*parser).callonITEM82,
 						expr: &litMatcher{
-							pos:        position{line: 545, col: 6, offset: 15416},
+							pos:        position{line: 546, col: 6, offset: 15483},
 							val:        "flour",
 							ignoreCase: true,
 							want:       "\"flour\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 546, col: 6, offset: 15463},
+						pos: position{line: 547, col: 6, offset: 15530},
 						run: (*parser).callonITEM84,
 						expr: &litMatcher{
-							pos:        position{line: 546, col: 6, offset: 15463},
+							pos:        position{line: 547, col: 6, offset: 15530},
 							val:        "flute",
 							ignoreCase: true,
 							want:       "\"flute\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 547, col: 6, offset: 15510},
+						pos: position{line: 548, col: 6, offset: 15577},
 						run: (*parser).callonITEM86,
 						expr: &litMatcher{
-							pos:        position{line: 547, col: 6, offset: 15510},
+							pos:        position{line: 548, col: 6, offset: 15577},
 							val:        "fodder",
 							ignoreCase: true,
 							want:       "\"fodder\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 548, col: 6, offset: 15558},
+						pos: position{line: 549, col: 6, offset: 15625},
 						run: (*parser).callonITEM88,
 						expr: &litMatcher{
-							pos:        position{line: 548, col: 6, offset: 15558},
+							pos:        position{line: 549, col: 6, offset: 15625},
 							val:        "frame",
 							ignoreCase: true,
 							want:       "\"frame\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 549, col: 6, offset: 15605},
+						pos: position{line: 550, col: 6, offset: 15672},
 						run: (*parser).callonITEM90,
 						expr: &litMatcher{
-							pos:        position{line: 549, col: 6, offset: 15605},
+							pos:        position{line: 550, col: 6, offset: 15672},
 							val:        "frankincense",
 							ignoreCase: true,
 							want:       "\"frankincense\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 550, col: 6, offset: 15659},
+						pos: position{line: 551, col: 6, offset: 15726},
 						run: (*parser).callonITEM92,
 						expr: &litMatcher{
-							pos:        position{line: 550, col: 6, offset: 15659},
+							pos:        position{line: 551, col: 6, offset: 15726},
 							val:        "fur",
 							ignoreCase: true,
 							want:       "\"fur\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 551, col: 6, offset: 15704},
+						pos: position{line: 552, col: 6, offset: 15771},
 						run: (*parser).callonITEM94,
 						expr: &litMatcher{
-							pos:        position{line: 551, col: 6, offset: 15704},
+							pos:        position{line: 552, col: 6, offset: 15771},
 							val:        "glasspipe",
 							ignoreCase: true,
 							want:       "\"glasspipe\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 552, col: 6, offset: 15755},
+						pos: position{line: 553, col: 6, offset: 15822},
 						run: (*parser).callonITEM96,
 						expr: &litMatcher{
-							pos:        position{line: 552, col: 6, offset: 15755},
+							pos:        position{line: 553, col: 6, offset: 15822},
 							val:        "goats",
 							ignoreCase: true,
 							want:       "\"goats\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 553, col: 6, offset: 15802},
+						pos: position{line: 554, col: 6, offset: 15869},
 						run: (*parser).callonITEM98,
 						expr: &litMatcher{
-							pos:        position{line: 553, col: 6, offset: 15802},
+							pos:        position{line: 554, col: 6, offset: 15869},
 							val:        "gold",
 							ignoreCase: true,
 							want:       "\"gold\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 554, col: 6, offset: 15848},
+						pos: position{line: 555, col: 6, offset: 15915},
 						run: (*parser).callonITEM100,
 						expr: &litMatcher{
-							pos:        position{line: 554, col: 6, offset: 15848},
+							pos:        position{line: 555, col: 6, offset: 15915},
 							val:        "grain",
 							ignoreCase: true,
 							want:       "\"grain\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 555, col: 6, offset: 15895},
+						pos: position{line: 556, col: 6, offset: 15962},
 						run: (*parser).callonITEM102,
 						expr: &litMatcher{
-							pos:        position{line: 555, col: 6, offset: 15895},
+							pos:        position{line: 556, col: 6, offset: 15962},
 							val:        "grape",
 							ignoreCase: true,
 							want:       "\"grape\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 556, col: 6, offset: 15942},
+						pos: position{line: 557, col: 6, offset: 16009},
 						run: (*parser).callonITEM104,
 						expr: &litMatcher{
-							pos:        position{line: 556, col: 6, offset: 15942},
+							pos:        position{line: 557, col: 6, offset: 16009},
 							val:        "gut",
 							ignoreCase: true,
 							want:       "\"gut\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 557, col: 6, offset: 15987},
+						pos: position{line: 558, col: 6, offset: 16054},
 						run: (*parser).callonITEM106,
 						expr: &litMatcher{
-							pos:        position{line: 557, col: 6, offset: 15987},
+							pos:        position{line: 558, col: 6, offset: 16054},
 							val:        "hbow",
 							ignoreCase: true,
 							want:       "\"hbow\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 558, col: 6, offset: 16033},
+						pos: position{line: 559, col: 6, offset: 16100},
 						run: (*parser).callonITEM108,
 						expr: &litMatcher{
-							pos:        position{line: 558, col: 6, offset: 16033},
+							pos:        position{line: 559, col: 6, offset: 16100},
 							val:        "harp",
 							ignoreCase: true,
 							want:       "\"harp\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 559, col: 6, offset: 16079},
+						pos: position{line: 560, col: 6, offset: 16146},
 						run: (*parser).callonITEM110,
 						expr: &litMatcher{
-							pos:        position{line: 559, col: 6, offset: 16079},
+							pos:        position{line: 560, col: 6, offset: 16146},
 							val:        "haube",
 							ignoreCase: true,
 							want:       "\"haube\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 560, col: 6, offset: 16126},
+						pos: position{line: 561, col: 6, offset: 16193},
 						run: (*parser).callonITEM112,
 						expr: &litMatcher{
-							pos:        position{line: 560, col: 6, offset: 16126},
+							pos:        position{line: 561, col: 6, offset: 16193},
 							val:        "heaters",
 							ignoreCase: true,
 							want:       "\"heaters\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 561, col: 6, offset: 16175},
+						pos: position{line: 562, col: 6, offset: 16242},
 						run: (*parser).callonITEM114,
 						expr: &litMatcher{
-							pos:        position{line: 561, col: 6, offset: 16175},
+							pos:        position{line: 562, col: 6, offset: 16242},
 							val:        "helm",
 							ignoreCase: true,
 							want:       "\"helm\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 562, col: 6, offset: 16221},
+						pos: position{line: 563, col: 6, offset: 16288},
 						run: (*parser).callonITEM116,
 						expr: &litMatcher{
-							pos:        position{line: 562, col: 6, offset: 16221},
+							pos:        position{line: 563, col: 6, offset: 16288},
 							val:        "herbs",
 							ignoreCase: true,
 							want:       "\"herbs\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 563, col: 6, offset: 16268},
+						pos: position{line: 564, col: 6, offset: 16335},
 						run: (*parser).callonITEM118,
 						expr: &litMatcher{
-							pos:        position{line: 563, col: 6, offset: 16268},
+							pos:        position{line: 564, col: 6, offset: 16335},
 							val:        "hive",
 							ignoreCase: true,
 							want:       "\"hive\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 564, col: 6, offset: 16314},
+						pos: position{line: 565, col: 6, offset: 16381},
 						run: (*parser).callonITEM120,
 						expr: &litMatcher{
-							pos:        position{line: 564, col: 6, offset: 16314},
+							pos:        position{line: 565, col: 6, offset: 16381},
 							val:        "hoe",
 							ignoreCase: true,
 							want:       "\"hoe\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 565, col: 6, offset: 16359},
+						pos: position{line: 566, col: 6, offset: 16426},
 						run: (*parser).callonITEM122,
 						expr: &litMatcher{
-							pos:        position{line: 565, col: 6, offset: 16359},
+							pos:        position{line: 566, col: 6, offset: 16426},
 							val:        "honey",
 							ignoreCase: true,
 							want:       "\"honey\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 566, col: 6, offset: 16406},
+						pos: position{line: 567, col: 6, offset: 16473},
 						run: (*parser).callonITEM124,
 						expr: &litMatcher{
-							pos:        position{line: 566, col: 6, offset: 16406},
+							pos:        position{line: 567, col: 6, offset: 16473},
 							val:        "hood",
 							ignoreCase: true,
 							want:       "\"hood\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 567, col: 6, offset: 16452},
+						pos: position{line: 568, col: 6, offset: 16519},
 						run: (*parser).callonITEM126,
 						expr: &litMatcher{
-							pos:        position{line: 567, col: 6, offset: 16452},
+							pos:        position{line: 568, col: 6, offset: 16519},
 							val:        "horn",
 							ignoreCase: true,
 							want:       "\"horn\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 568, col: 6, offset: 16498},
+						pos: position{line: 569, col: 6, offset: 16565},
 						run: (*parser).callonITEM128,
 						expr: &litMatcher{
-							pos:        position{line: 568, col: 6, offset: 16498},
+							pos:        position{line: 569, col: 6, offset: 16565},
 							val:        "horses",
 							ignoreCase: true,
 							want:       "\"horses\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 569, col: 6, offset: 16546},
+						pos: position{line: 570, col: 6, offset: 16613},
 						run: (*parser).callonITEM130,
 						expr: &litMatcher{
-							pos:        position{line: 569, col: 6, offset: 16546},
+							pos:        position{line: 570, col: 6, offset: 16613},
 							val:        "jade",
 							ignoreCase: true,
 							want:       "\"jade\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 570, col: 6, offset: 16592},
+						pos: position{line: 571, col: 6, offset: 16659},
 						run: (*parser).callonITEM132,
 						expr: &litMatcher{
-							pos:        position{line: 570, col: 6, offset: 16592},
+							pos:        position{line: 571, col: 6, offset: 16659},
 							val:        "jerkin",
 							ignoreCase: true,
 							want:       "\"jerkin\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 571, col: 6, offset: 16640},
+						pos: position{line: 572, col: 6, offset: 16707},
 						run: (*parser).callonITEM134,
 						expr: &litMatcher{
-							pos:        position{line: 571, col: 6, offset: 16640},
+							pos:        position{line: 572, col: 6, offset: 16707},
 							val:        "kayak",
 							ignoreCase: true,
 							want:       "\"kayak\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 572, col: 6, offset: 16687},
+						pos: position{line: 573, col: 6, offset: 16754},
 						run: (*parser).callonITEM136,
 						expr: &litMatcher{
-							pos:        position{line: 572, col: 6, offset: 16687},
+							pos:        position{line: 573, col: 6, offset: 16754},
 							val:        "ladder",
 							ignoreCase: true,
 							want:       "\"ladder\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 573, col: 6, offset: 16735},
+						pos: position{line: 574, col: 6, offset: 16802},
 						run: (*parser).callonITEM138,
 						expr: &litMatcher{
-							pos:        position{line: 573, col: 6, offset: 16735},
+							pos:        position{line: 574, col: 6, offset: 16802},
 							val:        "leather",
 							ignoreCase: true,
 							want:       "\"leather\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 574, col: 6, offset: 16784},
+						pos: position{line: 575, col: 6, offset: 16851},
 						run: (*parser).callonITEM140,
 						expr: &litMatcher{
-							pos:        position{line: 574, col: 6, offset: 16784},
+							pos:        position{line: 575, col: 6, offset: 16851},
 							val:        "logs",
 							ignoreCase: true,
 							want:       "\"logs\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 575, col: 6, offset: 16830},
+						pos: position{line: 576, col: 6, offset: 16897},
 						run: (*parser).callonITEM142,
 						expr: &litMatcher{
-							pos:        position{line: 575, col: 6, offset: 16830},
+							pos:        position{line: 576, col: 6, offset: 16897},
 							val:        "lute",
 							ignoreCase: true,
 							want:       "\"lute\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 576, col: 6, offset: 16876},
+						pos: position{line: 577, col: 6, offset: 16943},
 						run: (*parser).callonITEM144,
 						expr: &litMatcher{
-							pos:        position{line: 576, col: 6, offset: 16876},
+							pos:        position{line: 577, col: 6, offset: 16943},
 							val:        "mace",
 							ignoreCase: true,
 							want:       "\"mace\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 577, col: 6, offset: 16922},
+						pos: position{line: 578, col: 6, offset: 16989},
 						run: (*parser).callonITEM146,
 						expr: &litMatcher{
-							pos:        position{line: 577, col: 6, offset: 16922},
+							pos:        position{line: 578, col: 6, offset: 16989},
 							val:        "mattock",
 							ignoreCase: true,
 							want:       "\"mattock\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 578, col: 6, offset: 16971},
+						pos: position{line: 579, col: 6, offset: 17038},
 						run: (*parser).callonITEM148,
 						expr: &litMatcher{
-							pos:        position{line: 578, col: 6, offset: 16971},
+							pos:        position{line: 579, col: 6, offset: 17038},
 							val:        "metal",
 							ignoreCase: true,
 							want:       "\"metal\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 579, col: 6, offset: 17018},
+						pos: position{line: 580, col: 6, offset: 17085},
 						run: (*parser).callonITEM150,
 						expr: &litMatcher{
-							pos:        position{line: 579, col: 6, offset: 17018},
+							pos:        position{line: 580, col: 6, offset: 17085},
 							val:        "millstone",
 							ignoreCase: true,
 							want:       "\"millstone\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 580, col: 6, offset: 17069},
+						pos: position{line: 581, col: 6, offset: 17136},
 						run: (*parser).callonITEM152,
 						expr: &litMatcher{
-							pos:        position{line: 580, col: 6, offset: 17069},
+							pos:        position{line: 581, col: 6, offset: 17136},
 							val:        "musk",
 							ignoreCase: true,
 							want:       "\"musk\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 581, col: 6, offset: 17115},
+						pos: position{line: 582, col: 6, offset: 17182},
 						run: (*parser).callonITEM154,
 						expr: &litMatcher{
-							pos:        position{line: 581, col: 6, offset: 17115},
+							pos:        position{line: 582, col: 6, offset: 17182},
 							val:        "net",
 							ignoreCase: true,
 							want:       "\"net\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 582, col: 6, offset: 17160},
+						pos: position{line: 583, col: 6, offset: 17227},
 						run: (*parser).callonITEM156,
 						expr: &litMatcher{
-							pos:        position{line: 582, col: 6, offset: 17160},
+							pos:        position{line: 583, col: 6, offset: 17227},
 							val:        "oar",
 							ignoreCase: true,
 							want:       "\"oar\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 583, col: 6, offset: 17205},
+						pos: position{line: 584, col: 6, offset: 17272},
 						run: (*parser).callonITEM158,
 						expr: &litMatcher{
-							pos:        position{line: 583, col: 6, offset: 17205},
+							pos:        position{line: 584, col: 6, offset: 17272},
 							val:        "oil",
 							ignoreCase: true,
 							want:       "\"oil\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 584, col: 6, offset: 17250},
+						pos: position{line: 585, col: 6, offset: 17317},
 						run: (*parser).callonITEM160,
 						expr: &litMatcher{
-							pos:        position{line: 584, col: 6, offset: 17250},
+							pos:        position{line: 585, col: 6, offset: 17317},
 							val:        "olives",
 							ignoreCase: true,
 							want:       "\"olives\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 585, col: 6, offset: 17298},
+						pos: position{line: 586, col: 6, offset: 17365},
 						run: (*parser).callonITEM162,
 						expr: &litMatcher{
-							pos:        position{line: 585, col: 6, offset: 17298},
+							pos:        position{line: 586, col: 6, offset: 17365},
 							val:        "opium",
 							ignoreCase: true,
 							want:       "\"opium\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 586, col: 6, offset: 17345},
+						pos: position{line: 587, col: 6, offset: 17412},
 						run: (*parser).callonITEM164,
 						expr: &litMatcher{
-							pos:        position{line: 586, col: 6, offset: 17345},
+							pos:        position{line: 587, col: 6, offset: 17412},
 							val:        "ores",
 							ignoreCase: true,
 							want:       "\"ores\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 587, col: 6, offset: 17391},
+						pos: position{line: 588, col: 6, offset: 17458},
 						run: (*parser).callonITEM166,
 						expr: &litMatcher{
-							pos:        position{line: 587, col: 6, offset: 17391},
+							pos:        position{line: 588, col: 6, offset: 17458},
 							val:        "paddle",
 							ignoreCase: true,
 							want:       "\"paddle\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 588, col: 6, offset: 17439},
+						pos: position{line: 589, col: 6, offset: 17506},
 						run: (*parser).callonITEM168,
 						expr: &litMatcher{
-							pos:        position{line: 588, col: 6, offset: 17439},
+							pos:        position{line: 589, col: 6, offset: 17506},
 							val:        "palanquin",
 							ignoreCase: true,
 							want:       "\"palanquin\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 589, col: 6, offset: 17490},
+						pos: position{line: 590, col: 6, offset: 17557},
 						run: (*parser).callonITEM170,
 						expr: &litMatcher{
-							pos:        position{line: 589, col: 6, offset: 17490},
+							pos:        position{line: 590, col: 6, offset: 17557},
 							val:        "parchment",
 							ignoreCase: true,
 							want:       "\"parchment\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 590, col: 6, offset: 17541},
+						pos: position{line: 591, col: 6, offset: 17608},
 						run: (*parser).callonITEM172,
 						expr: &litMatcher{
-							pos:        position{line: 590, col: 6, offset: 17541},
+							pos:        position{line: 591, col: 6, offset: 17608},
 							val:        "pavis",
 							ignoreCase: true,
 							want:       "\"pavis\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 591, col: 6, offset: 17588},
+						pos: position{line: 592, col: 6, offset: 17655},
 						run: (*parser).callonITEM174,
 						expr: &litMatcher{
-							pos:        position{line: 591, col: 6, offset: 17588},
+							pos:        position{line: 592, col: 6, offset: 17655},
 							val:        "pearls",
 							ignoreCase: true,
 							want:       "\"pearls\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 592, col: 6, offset: 17636},
+						pos: position{line: 593, col: 6, offset: 17703},
 						run: (*parser).callonITEM176,
 						expr: &litMatcher{
-							pos:        position{line: 592, col: 6, offset: 17636},
+							pos:        position{line: 593, col: 6, offset: 17703},
 							val:        "pellets",
 							ignoreCase: true,
 							want:       "\"pellets\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 593, col: 6, offset: 17685},
+						pos: position{line: 594, col: 6, offset: 17752},
 						run: (*parser).callonITEM178,
 						expr: &litMatcher{
-							pos:        position{line: 593, col: 6, offset: 17685},
+							pos:        position{line: 594, col: 6, offset: 17752},
 							val:        "people",
 							ignoreCase: true,
 							want:       "\"people\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 594, col: 6, offset: 17733},
+						pos: position{line: 595, col: 6, offset: 17800},
 						run: (*parser).callonITEM180,
 						expr: &litMatcher{
-							pos:        position{line: 594, col: 6, offset: 17733},
+							pos:        position{line: 595, col: 6, offset: 17800},
 							val:        "pewter",
 							ignoreCase: true,
 							want:       "\"pewter\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 595, col: 6, offset: 17781},
+						pos: position{line: 596, col: 6, offset: 17848},
 						run: (*parser).callonITEM182,
 						expr: &litMatcher{
-							pos:        position{line: 595, col: 6, offset: 17781},
+							pos:        position{line: 596, col: 6, offset: 17848},
 							val:        "picks",
 							ignoreCase: true,
 							want:       "\"picks\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 596, col: 6, offset: 17828},
+						pos: position{line: 597, col: 6, offset: 17895},
 						run: (*parser).callonITEM184,
 						expr: &litMatcher{
-							pos:        position{line: 596, col: 6, offset: 17828},
+							pos:        position{line: 597, col: 6, offset: 17895},
 							val:        "plows",
 							ignoreCase: true,
 							want:       "\"plows\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 597, col: 6, offset: 17875},
+						pos: position{line: 598, col: 6, offset: 17942},
 						run: (*parser).callonITEM186,
 						expr: &litMatcher{
-							pos:        position{line: 597, col: 6, offset: 17875},
+							pos:        position{line: 598, col: 6, offset: 17942},
 							val:        "provisions",
 							ignoreCase: true,
 							want:       "\"provisions\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 598, col: 6, offset: 17927},
+						pos: position{line: 599, col: 6, offset: 17994},
 						run: (*parser).callonITEM188,
 						expr: &litMatcher{
-							pos:        position{line: 598, col: 6, offset: 17927},
+							pos:        position{line: 599, col: 6, offset: 17994},
 							val:        "quarrel",
 							ignoreCase: true,
 							want:       "\"quarrel\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 599, col: 6, offset: 17976},
+						pos: position{line: 600, col: 6, offset: 18043},
 						run: (*parser).callonITEM190,
 						expr: &litMatcher{
-							pos:        position{line: 599, col: 6, offset: 17976},
+							pos:        position{line: 600, col: 6, offset: 18043},
 							val:        "rake",
 							ignoreCase: true,
 							want:       "\"rake\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 600, col: 6, offset: 18022},
+						pos: position{line: 601, col: 6, offset: 18089},
 						run: (*parser).callonITEM192,
 						expr: &litMatcher{
-							pos:        position{line: 600, col: 6, offset: 18022},
+							pos:        position{line: 601, col: 6, offset: 18089},
 							val:        "ram",
 							ignoreCase: true,
 							want:       "\"ram\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 601, col: 6, offset: 18067},
+						pos: position{line: 602, col: 6, offset: 18134},
 						run: (*parser).callonITEM194,
 						expr: &litMatcher{
-							pos:        position{line: 601, col: 6, offset: 18067},
+							pos:        position{line: 602, col: 6, offset: 18134},
 							val:        "ramp",
 							ignoreCase: true,
 							want:       "\"ramp\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 602, col: 6, offset: 18113},
+						pos: position{line: 603, col: 6, offset: 18180},
 						run: (*parser).callonITEM196,
 						expr: &litMatcher{
-							pos:        position{line: 602, col: 6, offset: 18113},
+							pos:        position{line: 603, col: 6, offset: 18180},
 							val:        "ring",
 							ignoreCase: true,
 							want:       "\"ring\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 603, col: 6, offset: 18159},
+						pos: position{line: 604, col: 6, offset: 18226},
 						run: (*parser).callonITEM198,
 						expr: &litMatcher{
-							pos:        position{line: 603, col: 6, offset: 18159},
+							pos:        position{line: 604, col: 6, offset: 18226},
 							val:        "rope",
 							ignoreCase: true,
 							want:       "\"rope\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 604, col: 6, offset: 18205},
+						pos: position{line: 605, col: 6, offset: 18272},
 						run: (*parser).callonITEM200,
 						expr: &litMatcher{
-							pos:        position{line: 604, col: 6, offset: 18205},
+							pos:        position{line: 605, col: 6, offset: 18272},
 							val:        "rug",
 							ignoreCase: true,
 							want:       "\"rug\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 605, col: 6, offset: 18250},
+						pos: position{line: 606, col: 6, offset: 18317},
 						run: (*parser).callonITEM202,
 						expr: &litMatcher{
-							pos:        position{line: 605, col: 6, offset: 18250},
+							pos:        position{line: 606, col: 6, offset: 18317},
 							val:        "saddle",
 							ignoreCase: true,
 							want:       "\"saddle\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 606, col: 6, offset: 18298},
+						pos: position{line: 607, col: 6, offset: 18365},
 						run: (*parser).callonITEM204,
 						expr: &litMatcher{
-							pos:        position{line: 606, col: 6, offset: 18298},
+							pos:        position{line: 607, col: 6, offset: 18365},
 							val:        "saddlebag",
 							ignoreCase: true,
 							want:       "\"saddlebag\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 607, col: 6, offset: 18349},
+						pos: position{line: 608, col: 6, offset: 18416},
 						run: (*parser).callonITEM206,
 						expr: &litMatcher{
-							pos:        position{line: 607, col: 6, offset: 18349},
+							pos:        position{line: 608, col: 6, offset: 18416},
 							val:        "salt",
 							ignoreCase: true,
 							want:       "\"salt\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 608, col: 6, offset: 18395},
+						pos: position{line: 609, col: 6, offset: 18462},
 						run: (*parser).callonITEM208,
 						expr: &litMatcher{
-							pos:        position{line: 608, col: 6, offset: 18395},
+							pos:        position{line: 609, col: 6, offset: 18462},
 							val:        "sand",
 							ignoreCase: true,
 							want:       "\"sand\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 609, col: 6, offset: 18441},
+						pos: position{line: 610, col: 6, offset: 18508},
 						run: (*parser).callonITEM210,
 						expr: &litMatcher{
-							pos:        position{line: 609, col: 6, offset: 18441},
+							pos:        position{line: 610, col: 6, offset: 18508},
 							val:        "scale",
 							ignoreCase: true,
 							want:       "\"scale\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 610, col: 6, offset: 18488},
+						pos: position{line: 611, col: 6, offset: 18555},
 						run: (*parser).callonITEM212,
 						expr: &litMatcher{
-							pos:        position{line: 610, col: 6, offset: 18488},
+							pos:        position{line: 611, col: 6, offset: 18555},
 							val:        "sculpture",
 							ignoreCase: true,
 							want:       "\"sculpture\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 611, col: 6, offset: 18539},
+						pos: position{line: 612, col: 6, offset: 18606},
 						run: (*parser).callonITEM214,
 						expr: &litMatcher{
-							pos:        position{line: 611, col: 6, offset: 18539},
+							pos:        position{line: 612, col: 6, offset: 18606},
 							val:        "scutum",
 							ignoreCase: true,
 							want:       "\"scutum\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 612, col: 6, offset: 18587},
+						pos: position{line: 613, col: 6, offset: 18654},
 						run: (*parser).callonITEM216,
 						expr: &litMatcher{
-							pos:        position{line: 612, col: 6, offset: 18587},
+							pos:        position{line: 613, col: 6, offset: 18654},
 							val:        "scythe",
 							ignoreCase: true,
 							want:       "\"scythe\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 613, col: 6, offset: 18635},
+						pos: position{line: 614, col: 6, offset: 18702},
 						run: (*parser).callonITEM218,
 						expr: &litMatcher{
-							pos:        position{line: 613, col: 6, offset: 18635},
+							pos:        position{line: 614, col: 6, offset: 18702},
 							val:        "shackle",
 							ignoreCase: true,
 							want:       "\"shackle\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 614, col: 6, offset: 18684},
+						pos: position{line: 615, col: 6, offset: 18751},
 						run: (*parser).callonITEM220,
 						expr: &litMatcher{
-							pos:        position{line: 614, col: 6, offset: 18684},
+							pos:        position{line: 615, col: 6, offset: 18751},
 							val:        "shaft",
 							ignoreCase: true,
 							want:       "\"shaft\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 615, col: 6, offset: 18731},
+						pos: position{line: 616, col: 6, offset: 18798},
 						run: (*parser).callonITEM222,
 						expr: &litMatcher{
-							pos:        position{line: 615, col: 6, offset: 18731},
+							pos:        position{line: 616, col: 6, offset: 18798},
 							val:        "shield",
 							ignoreCase: true,
 							want:       "\"shield\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 616, col: 6, offset: 18779},
+						pos: position{line: 617, col: 6, offset: 18846},
 						run: (*parser).callonITEM224,
 						expr: &litMatcher{
-							pos:        position{line: 616, col: 6, offset: 18779},
+							pos:        position{line: 617, col: 6, offset: 18846},
 							val:        "shovel",
 							ignoreCase: true,
 							want:       "\"shovel\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 617, col: 6, offset: 18827},
+						pos: position{line: 618, col: 6, offset: 18894},
 						run: (*parser).callonITEM226,
 						expr: &litMatcher{
-							pos:        position{line: 617, col: 6, offset: 18827},
+							pos:        position{line: 618, col: 6, offset: 18894},
 							val:        "silk",
 							ignoreCase: true,
 							want:       "\"silk\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 618, col: 6, offset: 18873},
+						pos: position{line: 619, col: 6, offset: 18940},
 						run: (*parser).callonITEM228,
 						expr: &litMatcher{
-							pos:        position{line: 618, col: 6, offset: 18873},
+							pos:        position{line: 619, col: 6, offset: 18940},
 							val:        "silver",
 							ignoreCase: true,
 							want:       "\"silver\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 619, col: 6, offset: 18921},
+						pos: position{line: 620, col: 6, offset: 18988},
 						run: (*parser).callonITEM230,
 						expr: &litMatcher{
-							pos:        position{line: 619, col: 6, offset: 18921},
+							pos:        position{line: 620, col: 6, offset: 18988},
 							val:        "skin",
 							ignoreCase: true,
 							want:       "\"skin\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 620, col: 6, offset: 18967},
+						pos: position{line: 621, col: 6, offset: 19034},
 						run: (*parser).callonITEM232,
 						expr: &litMatcher{
-							pos:        position{line: 620, col: 6, offset: 18967},
+							pos:        position{line: 621, col: 6, offset: 19034},
 							val:        "slaves",
 							ignoreCase: true,
 							want:       "\"slaves\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 621, col: 6, offset: 19015},
+						pos: position{line: 622, col: 6, offset: 19082},
 						run: (*parser).callonITEM234,
 						expr: &litMatcher{
-							pos:        position{line: 621, col: 6, offset: 19015},
+							pos:        position{line: 622, col: 6, offset: 19082},
 							val:        "slings",
 							ignoreCase: true,
 							want:       "\"slings\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 622, col: 6, offset: 19063},
+						pos: position{line: 623, col: 6, offset: 19130},
 						run: (*parser).callonITEM236,
 						expr: &litMatcher{
-							pos:        position{line: 622, col: 6, offset: 19063},
+							pos:        position{line: 623, col: 6, offset: 19130},
 							val:        "snare",
 							ignoreCase: true,
 							want:       "\"snare\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 623, col: 6, offset: 19110},
+						pos: position{line: 624, col: 6, offset: 19177},
 						run: (*parser).callonITEM238,
 						expr: &litMatcher{
-							pos:        position{line: 623, col: 6, offset: 19110},
+							pos:        position{line: 624, col: 6, offset: 19177},
 							val:        "spear",
 							ignoreCase: true,
 							want:       "\"spear\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 624, col: 6, offset: 19157},
+						pos: position{line: 625, col: 6, offset: 19224},
 						run: (*parser).callonITEM240,
 						expr: &litMatcher{
-							pos:        position{line: 624, col: 6, offset: 19157},
+							pos:        position{line: 625, col: 6, offset: 19224},
 							val:        "spetum",
 							ignoreCase: true,
 							want:       "\"spetum\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 625, col: 6, offset: 19205},
+						pos: position{line: 626, col: 6, offset: 19272},
 						run: (*parser).callonITEM242,
 						expr: &litMatcher{
-							pos:        position{line: 625, col: 6, offset: 19205},
+							pos:        position{line: 626, col: 6, offset: 19272},
 							val:        "spice",
 							ignoreCase: true,
 							want:       "\"spice\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 626, col: 6, offset: 19252},
+						pos: position{line: 627, col: 6, offset: 19319},
 						run: (*parser).callonITEM244,
 						expr: &litMatcher{
-							pos:        position{line: 626, col: 6, offset: 19252},
+							pos:        position{line: 627, col: 6, offset: 19319},
 							val:        "statue",
 							ignoreCase: true,
 							want:       "\"statue\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 627, col: 6, offset: 19300},
+						pos: position{line: 628, col: 6, offset: 19367},
 						run: (*parser).callonITEM246,
 						expr: &litMatcher{
-							pos:        position{line: 627, col: 6, offset: 19300},
+							pos:        position{line: 628, col: 6, offset: 19367},
 							val:        "stave",
 							ignoreCase: true,
 							want:       "\"stave\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 628, col: 6, offset: 19347},
+						pos: position{line: 629, col: 6, offset: 19414},
 						run: (*parser).callonITEM248,
 						expr: &litMatcher{
-							pos:        position{line: 628, col: 6, offset: 19347},
+							pos:        position{line: 629, col: 6, offset: 19414},
 							val:        "stones",
 							ignoreCase: true,
 							want:       "\"stones\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 629, col: 6, offset: 19395},
+						pos: position{line: 630, col: 6, offset: 19462},
 						run: (*parser).callonITEM250,
 						expr: &litMatcher{
-							pos:        position{line: 629, col: 6, offset: 19395},
+							pos:        position{line: 630, col: 6, offset: 19462},
 							val:        "string",
 							ignoreCase: true,
 							want:       "\"string\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 630, col: 6, offset: 19443},
+						pos: position{line: 631, col: 6, offset: 19510},
 						run: (*parser).callonITEM252,
 						expr: &litMatcher{
-							pos:        position{line: 630, col: 6, offset: 19443},
+							pos:        position{line: 631, col: 6, offset: 19510},
 							val:        "sugar",
 							ignoreCase: true,
 							want:       "\"sugar\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 631, col: 6, offset: 19490},
+						pos: position{line: 632, col: 6, offset: 19557},
 						run: (*parser).callonITEM254,
 						expr: &litMatcher{
-							pos:        position{line: 631, col: 6, offset: 19490},
+							pos:        position{line: 632, col: 6, offset: 19557},
 							val:        "sword",
 							ignoreCase: true,
 							want:       "\"sword\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 632, col: 6, offset: 19537},
+						pos: position{line: 633, col: 6, offset: 19604},
 						run: (*parser).callonITEM256,
 						expr: &litMatcher{
-							pos:        position{line: 632, col: 6, offset: 19537},
+							pos:        position{line: 633, col: 6, offset: 19604},
 							val:        "tapestries",
 							ignoreCase: true,
 							want:       "\"tapestries\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 633, col: 6, offset: 19589},
+						pos: position{line: 634, col: 6, offset: 19656},
 						run: (*parser).callonITEM258,
 						expr: &litMatcher{
-							pos:        position{line: 633, col: 6, offset: 19589},
+							pos:        position{line: 634, col: 6, offset: 19656},
 							val:        "tea",
 							ignoreCase: true,
 							want:       "\"tea\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 634, col: 6, offset: 19634},
+						pos: position{line: 635, col: 6, offset: 19701},
 						run: (*parser).callonITEM260,
 						expr: &litMatcher{
-							pos:        position{line: 634, col: 6, offset: 19634},
+							pos:        position{line: 635, col: 6, offset: 19701},
 							val:        "tobacco",
 							ignoreCase: true,
 							want:       "\"tobacco\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 635, col: 6, offset: 19683},
+						pos: position{line: 636, col: 6, offset: 19750},
 						run: (*parser).callonITEM262,
 						expr: &litMatcher{
-							pos:        position{line: 635, col: 6, offset: 19683},
+							pos:        position{line: 636, col: 6, offset: 19750},
 							val:        "trap",
 							ignoreCase: true,
 							want:       "\"trap\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 636, col: 6, offset: 19729},
+						pos: position{line: 637, col: 6, offset: 19796},
 						run: (*parser).callonITEM264,
 						expr: &litMatcher{
-							pos:        position{line: 636, col: 6, offset: 19729},
+							pos:        position{line: 637, col: 6, offset: 19796},
 							val:        "trews",
 							ignoreCase: true,
 							want:       "\"trews\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 637, col: 6, offset: 19776},
+						pos: position{line: 638, col: 6, offset: 19843},
 						run: (*parser).callonITEM266,
 						expr: &litMatcher{
-							pos:        position{line: 637, col: 6, offset: 19776},
+							pos:        position{line: 638, col: 6, offset: 19843},
 							val:        "trinket",
 							ignoreCase: true,
 							want:       "\"trinket\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 638, col: 6, offset: 19825},
+						pos: position{line: 639, col: 6, offset: 19892},
 						run: (*parser).callonITEM268,
 						expr: &litMatcher{
-							pos:        position{line: 638, col: 6, offset: 19825},
+							pos:        position{line: 639, col: 6, offset: 19892},
 							val:        "trumpet",
 							ignoreCase: true,
 							want:       "\"trumpet\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 639, col: 6, offset: 19874},
+						pos: position{line: 640, col: 6, offset: 19941},
 						run: (*parser).callonITEM270,
 						expr: &litMatcher{
-							pos:        position{line: 639, col: 6, offset: 19874},
+							pos:        position{line: 640, col: 6, offset: 19941},
 							val:        "urn",
 							ignoreCase: true,
 							want:       "\"urn\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 640, col: 6, offset: 19919},
+						pos: position{line: 641, col: 6, offset: 19986},
 						run: (*parser).callonITEM272,
 						expr: &litMatcher{
-							pos:        position{line: 640, col: 6, offset: 19919},
+							pos:        position{line: 641, col: 6, offset: 19986},
 							val:        "wagons",
 							ignoreCase: true,
 							want:       "\"wagons\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 641, col: 6, offset: 19967},
+						pos: position{line: 642, col: 6, offset: 20034},
 						run: (*parser).callonITEM274,
 						expr: &litMatcher{
-							pos:        position{line: 641, col: 6, offset: 19967},
+							pos:        position{line: 642, col: 6, offset: 20034},
 							val:        "wax",
 							ignoreCase: true,
 							want:       "\"wax\"i",
@@ -4267,12 +4277,12 @@ var g = &grammar{
 		},
 		{
 			name: "DOTSPLAT",
-			pos:  position{line: 643, col: 1, offset: 20009},
+			pos:  position{line: 644, col: 1, offset: 20076},
 			expr: &actionExpr{
-				pos: position{line: 643, col: 13, offset: 20021},
+				pos: position{line: 644, col: 13, offset: 20088},
 				run: (*parser).callonDOTSPLAT1,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 643, col: 13, offset: 20021},
+					pos: position{line: 644, col: 13, offset: 20088},
 					expr: &anyMatcher{
 						line: 643, col: 13, offset: 20021,
 					},
@@ -4281,21 +4291,21 @@ var g = &grammar{
 		},
 		{
 			name: "MONTH",
-			pos:  position{line: 647, col: 1, offset: 20060},
+			pos:  position{line: 648, col: 1, offset: 20127},
 			expr: &actionExpr{
-				pos: position{line: 647, col: 10, offset: 20069},
+				pos: position{line: 648, col: 10, offset: 20136},
 				run: (*parser).callonMONTH1,
 				expr: &seqExpr{
-					pos: position{line: 647, col: 10, offset: 20069},
+					pos: position{line: 648, col: 10, offset: 20136},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 647, col: 10, offset: 20069},
+							pos:  position{line: 648, col: 10, offset: 20136},
 							name: "DIGIT",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 647, col: 16, offset: 20075},
+							pos: position{line: 648, col: 16, offset: 20142},
 							expr: &ruleRefExpr{
-								pos:  position{line: 647, col: 16, offset: 20075},
+								pos:  position{line: 648, col: 16, offset: 20142},
 								name: "DIGIT",
 							},
 						},
@@ -4305,14 +4315,14 @@ var g = &grammar{
 		},
 		{
 			name: "NUMBER",
-			pos:  position{line: 652, col: 1, offset: 20151},
+			pos:  position{line: 653, col: 1, offset: 20218},
 			expr: &actionExpr{
-				pos: position{line: 652, col: 11, offset: 20161},
+				pos: position{line: 653, col: 11, offset: 20228},
 				run: (*parser).callonNUMBER1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 652, col: 11, offset: 20161},
+					pos: position{line: 653, col: 11, offset: 20228},
 					expr: &charClassMatcher{
-						pos:        position{line: 652, col: 11, offset: 20161},
+						pos:        position{line: 653, col: 11, offset: 20228},
 						val:        "[0-9]",
 						ranges:     []rune{'0', '9'},
 						ignoreCase: false,
@@ -4323,205 +4333,205 @@ var g = &grammar{
 		},
 		{
 			name: "RESOURCE",
-			pos:  position{line: 657, col: 1, offset: 20237},
+			pos:  position{line: 658, col: 1, offset: 20304},
 			expr: &choiceExpr{
-				pos: position{line: 657, col: 13, offset: 20249},
+				pos: position{line: 658, col: 13, offset: 20316},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 657, col: 13, offset: 20249},
+						pos: position{line: 658, col: 13, offset: 20316},
 						run: (*parser).callonRESOURCE2,
 						expr: &litMatcher{
-							pos:        position{line: 657, col: 13, offset: 20249},
+							pos:        position{line: 658, col: 13, offset: 20316},
 							val:        "coal",
 							ignoreCase: true,
 							want:       "\"Coal\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 659, col: 5, offset: 20294},
+						pos: position{line: 660, col: 5, offset: 20361},
 						run: (*parser).callonRESOURCE4,
 						expr: &litMatcher{
-							pos:        position{line: 659, col: 5, offset: 20294},
+							pos:        position{line: 660, col: 5, offset: 20361},
 							val:        "copper ore",
 							ignoreCase: true,
 							want:       "\"Copper Ore\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 661, col: 5, offset: 20350},
+						pos: position{line: 662, col: 5, offset: 20417},
 						run: (*parser).callonRESOURCE6,
 						expr: &litMatcher{
-							pos:        position{line: 661, col: 5, offset: 20350},
+							pos:        position{line: 662, col: 5, offset: 20417},
 							val:        "diamond",
 							ignoreCase: true,
 							want:       "\"Diamond\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 663, col: 5, offset: 20401},
+						pos: position{line: 664, col: 5, offset: 20468},
 						run: (*parser).callonRESOURCE8,
 						expr: &litMatcher{
-							pos:        position{line: 663, col: 5, offset: 20401},
+							pos:        position{line: 664, col: 5, offset: 20468},
 							val:        "frankincense",
 							ignoreCase: true,
 							want:       "\"Frankincense\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 665, col: 5, offset: 20462},
+						pos: position{line: 666, col: 5, offset: 20529},
 						run: (*parser).callonRESOURCE10,
 						expr: &litMatcher{
-							pos:        position{line: 665, col: 5, offset: 20462},
+							pos:        position{line: 666, col: 5, offset: 20529},
 							val:        "gold",
 							ignoreCase: true,
 							want:       "\"Gold\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 667, col: 5, offset: 20507},
+						pos: position{line: 668, col: 5, offset: 20574},
 						run: (*parser).callonRESOURCE12,
 						expr: &litMatcher{
-							pos:        position{line: 667, col: 5, offset: 20507},
+							pos:        position{line: 668, col: 5, offset: 20574},
 							val:        "iron ore",
 							ignoreCase: true,
 							want:       "\"Iron Ore\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 669, col: 5, offset: 20559},
+						pos: position{line: 670, col: 5, offset: 20626},
 						run: (*parser).callonRESOURCE14,
 						expr: &litMatcher{
-							pos:        position{line: 669, col: 5, offset: 20559},
+							pos:        position{line: 670, col: 5, offset: 20626},
 							val:        "jade",
 							ignoreCase: true,
 							want:       "\"Jade\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 671, col: 5, offset: 20604},
+						pos: position{line: 672, col: 5, offset: 20671},
 						run: (*parser).callonRESOURCE16,
 						expr: &litMatcher{
-							pos:        position{line: 671, col: 5, offset: 20604},
+							pos:        position{line: 672, col: 5, offset: 20671},
 							val:        "kaolin",
 							ignoreCase: true,
 							want:       "\"Kaolin\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 673, col: 5, offset: 20653},
+						pos: position{line: 674, col: 5, offset: 20720},
 						run: (*parser).callonRESOURCE18,
 						expr: &litMatcher{
-							pos:        position{line: 673, col: 5, offset: 20653},
+							pos:        position{line: 674, col: 5, offset: 20720},
 							val:        "lead ore",
 							ignoreCase: true,
 							want:       "\"Lead Ore\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 675, col: 5, offset: 20705},
+						pos: position{line: 676, col: 5, offset: 20772},
 						run: (*parser).callonRESOURCE20,
 						expr: &litMatcher{
-							pos:        position{line: 675, col: 5, offset: 20705},
+							pos:        position{line: 676, col: 5, offset: 20772},
 							val:        "limestone",
 							ignoreCase: true,
 							want:       "\"Limestone\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 677, col: 5, offset: 20760},
+						pos: position{line: 678, col: 5, offset: 20827},
 						run: (*parser).callonRESOURCE22,
 						expr: &litMatcher{
-							pos:        position{line: 677, col: 5, offset: 20760},
+							pos:        position{line: 678, col: 5, offset: 20827},
 							val:        "nickel ore",
 							ignoreCase: true,
 							want:       "\"Nickel Ore\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 679, col: 5, offset: 20816},
+						pos: position{line: 680, col: 5, offset: 20883},
 						run: (*parser).callonRESOURCE24,
 						expr: &litMatcher{
-							pos:        position{line: 679, col: 5, offset: 20816},
+							pos:        position{line: 680, col: 5, offset: 20883},
 							val:        "pearls",
 							ignoreCase: true,
 							want:       "\"Pearls\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 681, col: 5, offset: 20865},
+						pos: position{line: 682, col: 5, offset: 20932},
 						run: (*parser).callonRESOURCE26,
 						expr: &litMatcher{
-							pos:        position{line: 681, col: 5, offset: 20865},
+							pos:        position{line: 682, col: 5, offset: 20932},
 							val:        "pyrite",
 							ignoreCase: true,
 							want:       "\"Pyrite\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 683, col: 5, offset: 20914},
+						pos: position{line: 684, col: 5, offset: 20981},
 						run: (*parser).callonRESOURCE28,
 						expr: &litMatcher{
-							pos:        position{line: 683, col: 5, offset: 20914},
+							pos:        position{line: 684, col: 5, offset: 20981},
 							val:        "rubies",
 							ignoreCase: true,
 							want:       "\"Rubies\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 685, col: 5, offset: 20963},
+						pos: position{line: 686, col: 5, offset: 21030},
 						run: (*parser).callonRESOURCE30,
 						expr: &litMatcher{
-							pos:        position{line: 685, col: 5, offset: 20963},
+							pos:        position{line: 686, col: 5, offset: 21030},
 							val:        "salt",
 							ignoreCase: true,
 							want:       "\"Salt\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 687, col: 5, offset: 21008},
+						pos: position{line: 688, col: 5, offset: 21075},
 						run: (*parser).callonRESOURCE32,
 						expr: &litMatcher{
-							pos:        position{line: 687, col: 5, offset: 21008},
+							pos:        position{line: 688, col: 5, offset: 21075},
 							val:        "silver",
 							ignoreCase: true,
 							want:       "\"Silver\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 689, col: 5, offset: 21057},
+						pos: position{line: 690, col: 5, offset: 21124},
 						run: (*parser).callonRESOURCE34,
 						expr: &litMatcher{
-							pos:        position{line: 689, col: 5, offset: 21057},
+							pos:        position{line: 690, col: 5, offset: 21124},
 							val:        "sulphur",
 							ignoreCase: true,
 							want:       "\"Sulphur\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 691, col: 5, offset: 21108},
+						pos: position{line: 692, col: 5, offset: 21175},
 						run: (*parser).callonRESOURCE36,
 						expr: &litMatcher{
-							pos:        position{line: 691, col: 5, offset: 21108},
+							pos:        position{line: 692, col: 5, offset: 21175},
 							val:        "tin ore",
 							ignoreCase: true,
 							want:       "\"Tin Ore\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 693, col: 5, offset: 21158},
+						pos: position{line: 694, col: 5, offset: 21225},
 						run: (*parser).callonRESOURCE38,
 						expr: &litMatcher{
-							pos:        position{line: 693, col: 5, offset: 21158},
+							pos:        position{line: 694, col: 5, offset: 21225},
 							val:        "vanadium ore",
 							ignoreCase: true,
 							want:       "\"Vanadium Ore\"i",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 695, col: 5, offset: 21218},
+						pos: position{line: 696, col: 5, offset: 21285},
 						run: (*parser).callonRESOURCE40,
 						expr: &litMatcher{
-							pos:        position{line: 695, col: 5, offset: 21218},
+							pos:        position{line: 696, col: 5, offset: 21285},
 							val:        "zinc ore",
 							ignoreCase: true,
 							want:       "\"Zinc Ore\"i",
@@ -4532,345 +4542,345 @@ var g = &grammar{
 		},
 		{
 			name: "TERRAIN",
-			pos:  position{line: 699, col: 1, offset: 21269},
+			pos:  position{line: 700, col: 1, offset: 21336},
 			expr: &choiceExpr{
-				pos: position{line: 699, col: 12, offset: 21280},
+				pos: position{line: 700, col: 12, offset: 21347},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 699, col: 12, offset: 21280},
+						pos: position{line: 700, col: 12, offset: 21347},
 						run: (*parser).callonTERRAIN2,
 						expr: &litMatcher{
-							pos:        position{line: 699, col: 12, offset: 21280},
+							pos:        position{line: 700, col: 12, offset: 21347},
 							val:        "ALPS",
 							ignoreCase: false,
 							want:       "\"ALPS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 701, col: 5, offset: 21334},
+						pos: position{line: 702, col: 5, offset: 21401},
 						run: (*parser).callonTERRAIN4,
 						expr: &litMatcher{
-							pos:        position{line: 701, col: 5, offset: 21334},
+							pos:        position{line: 702, col: 5, offset: 21401},
 							val:        "ARID HILLS",
 							ignoreCase: false,
 							want:       "\"ARID HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 703, col: 5, offset: 21387},
+						pos: position{line: 704, col: 5, offset: 21454},
 						run: (*parser).callonTERRAIN6,
 						expr: &litMatcher{
-							pos:        position{line: 703, col: 5, offset: 21387},
+							pos:        position{line: 704, col: 5, offset: 21454},
 							val:        "ARID TUNDRA",
 							ignoreCase: false,
 							want:       "\"ARID TUNDRA\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 705, col: 5, offset: 21440},
+						pos: position{line: 706, col: 5, offset: 21507},
 						run: (*parser).callonTERRAIN8,
 						expr: &litMatcher{
-							pos:        position{line: 705, col: 5, offset: 21440},
+							pos:        position{line: 706, col: 5, offset: 21507},
 							val:        "ARID",
 							ignoreCase: false,
 							want:       "\"ARID\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 707, col: 5, offset: 21486},
+						pos: position{line: 708, col: 5, offset: 21553},
 						run: (*parser).callonTERRAIN10,
 						expr: &litMatcher{
-							pos:        position{line: 707, col: 5, offset: 21486},
+							pos:        position{line: 708, col: 5, offset: 21553},
 							val:        "BRUSH FLAT",
 							ignoreCase: false,
 							want:       "\"BRUSH FLAT\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 709, col: 5, offset: 21539},
+						pos: position{line: 710, col: 5, offset: 21606},
 						run: (*parser).callonTERRAIN12,
 						expr: &litMatcher{
-							pos:        position{line: 709, col: 5, offset: 21539},
+							pos:        position{line: 710, col: 5, offset: 21606},
 							val:        "BRUSH HILLS",
 							ignoreCase: false,
 							want:       "\"BRUSH HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 711, col: 5, offset: 21594},
+						pos: position{line: 712, col: 5, offset: 21661},
 						run: (*parser).callonTERRAIN14,
 						expr: &litMatcher{
-							pos:        position{line: 711, col: 5, offset: 21594},
+							pos:        position{line: 712, col: 5, offset: 21661},
 							val:        "BRUSH",
 							ignoreCase: false,
 							want:       "\"BRUSH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 713, col: 5, offset: 21642},
+						pos: position{line: 714, col: 5, offset: 21709},
 						run: (*parser).callonTERRAIN16,
 						expr: &litMatcher{
-							pos:        position{line: 713, col: 5, offset: 21642},
+							pos:        position{line: 714, col: 5, offset: 21709},
 							val:        "CONIFER HILLS",
 							ignoreCase: false,
 							want:       "\"CONIFER HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 715, col: 5, offset: 21701},
+						pos: position{line: 716, col: 5, offset: 21768},
 						run: (*parser).callonTERRAIN18,
 						expr: &litMatcher{
-							pos:        position{line: 715, col: 5, offset: 21701},
+							pos:        position{line: 716, col: 5, offset: 21768},
 							val:        "DECIDUOUS FOREST",
 							ignoreCase: false,
 							want:       "\"DECIDUOUS FOREST\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 717, col: 5, offset: 21764},
+						pos: position{line: 718, col: 5, offset: 21831},
 						run: (*parser).callonTERRAIN20,
 						expr: &litMatcher{
-							pos:        position{line: 717, col: 5, offset: 21764},
+							pos:        position{line: 718, col: 5, offset: 21831},
 							val:        "DECIDUOUS HILLS",
 							ignoreCase: false,
 							want:       "\"DECIDUOUS HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 719, col: 5, offset: 21827},
+						pos: position{line: 720, col: 5, offset: 21894},
 						run: (*parser).callonTERRAIN22,
 						expr: &litMatcher{
-							pos:        position{line: 719, col: 5, offset: 21827},
+							pos:        position{line: 720, col: 5, offset: 21894},
 							val:        "DECIDUOUS",
 							ignoreCase: false,
 							want:       "\"DECIDUOUS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 721, col: 5, offset: 21883},
+						pos: position{line: 722, col: 5, offset: 21950},
 						run: (*parser).callonTERRAIN24,
 						expr: &litMatcher{
-							pos:        position{line: 721, col: 5, offset: 21883},
+							pos:        position{line: 722, col: 5, offset: 21950},
 							val:        "DESERT",
 							ignoreCase: false,
 							want:       "\"DESERT\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 723, col: 5, offset: 21933},
+						pos: position{line: 724, col: 5, offset: 22000},
 						run: (*parser).callonTERRAIN26,
 						expr: &litMatcher{
-							pos:        position{line: 723, col: 5, offset: 21933},
+							pos:        position{line: 724, col: 5, offset: 22000},
 							val:        "GRASSY HILLS PLATEAU",
 							ignoreCase: false,
 							want:       "\"GRASSY HILLS PLATEAU\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 725, col: 5, offset: 22005},
+						pos: position{line: 726, col: 5, offset: 22072},
 						run: (*parser).callonTERRAIN28,
 						expr: &litMatcher{
-							pos:        position{line: 725, col: 5, offset: 22005},
+							pos:        position{line: 726, col: 5, offset: 22072},
 							val:        "GRASSY HILLS",
 							ignoreCase: false,
 							want:       "\"GRASSY HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 727, col: 5, offset: 22062},
+						pos: position{line: 728, col: 5, offset: 22129},
 						run: (*parser).callonTERRAIN30,
 						expr: &litMatcher{
-							pos:        position{line: 727, col: 5, offset: 22062},
+							pos:        position{line: 728, col: 5, offset: 22129},
 							val:        "HIGH SNOWY MOUNTAINS",
 							ignoreCase: false,
 							want:       "\"HIGH SNOWY MOUNTAINS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 729, col: 5, offset: 22134},
+						pos: position{line: 730, col: 5, offset: 22201},
 						run: (*parser).callonTERRAIN32,
 						expr: &litMatcher{
-							pos:        position{line: 729, col: 5, offset: 22134},
+							pos:        position{line: 730, col: 5, offset: 22201},
 							val:        "JUNGLE HILLS",
 							ignoreCase: false,
 							want:       "\"JUNGLE HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 731, col: 5, offset: 22191},
+						pos: position{line: 732, col: 5, offset: 22258},
 						run: (*parser).callonTERRAIN34,
 						expr: &litMatcher{
-							pos:        position{line: 731, col: 5, offset: 22191},
+							pos:        position{line: 732, col: 5, offset: 22258},
 							val:        "JUNGLE",
 							ignoreCase: false,
 							want:       "\"JUNGLE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 733, col: 5, offset: 22241},
+						pos: position{line: 734, col: 5, offset: 22308},
 						run: (*parser).callonTERRAIN36,
 						expr: &litMatcher{
-							pos:        position{line: 733, col: 5, offset: 22241},
+							pos:        position{line: 734, col: 5, offset: 22308},
 							val:        "LAKE",
 							ignoreCase: false,
 							want:       "\"LAKE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 735, col: 5, offset: 22288},
+						pos: position{line: 736, col: 5, offset: 22355},
 						run: (*parser).callonTERRAIN38,
 						expr: &litMatcher{
-							pos:        position{line: 735, col: 5, offset: 22288},
+							pos:        position{line: 736, col: 5, offset: 22355},
 							val:        "LOW ARID MOUNTAINS",
 							ignoreCase: false,
 							want:       "\"LOW ARID MOUNTAINS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 737, col: 5, offset: 22356},
+						pos: position{line: 738, col: 5, offset: 22423},
 						run: (*parser).callonTERRAIN40,
 						expr: &litMatcher{
-							pos:        position{line: 737, col: 5, offset: 22356},
+							pos:        position{line: 738, col: 5, offset: 22423},
 							val:        "LOW CONIFER MOUNTAINS",
 							ignoreCase: false,
 							want:       "\"LOW CONIFER MOUNTAINS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 739, col: 5, offset: 22430},
+						pos: position{line: 740, col: 5, offset: 22497},
 						run: (*parser).callonTERRAIN42,
 						expr: &litMatcher{
-							pos:        position{line: 739, col: 5, offset: 22430},
+							pos:        position{line: 740, col: 5, offset: 22497},
 							val:        "LOW JUNGLE MOUNTAINS",
 							ignoreCase: false,
 							want:       "\"LOW JUNGLE MOUNTAINS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 741, col: 5, offset: 22502},
+						pos: position{line: 742, col: 5, offset: 22569},
 						run: (*parser).callonTERRAIN44,
 						expr: &litMatcher{
-							pos:        position{line: 741, col: 5, offset: 22502},
+							pos:        position{line: 742, col: 5, offset: 22569},
 							val:        "LOW SNOWY MOUNTAINS",
 							ignoreCase: false,
 							want:       "\"LOW SNOWY MOUNTAINS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 743, col: 5, offset: 22572},
+						pos: position{line: 744, col: 5, offset: 22639},
 						run: (*parser).callonTERRAIN46,
 						expr: &litMatcher{
-							pos:        position{line: 743, col: 5, offset: 22572},
+							pos:        position{line: 744, col: 5, offset: 22639},
 							val:        "LOW VOLCANIC MOUNTAINS",
 							ignoreCase: false,
 							want:       "\"LOW VOLCANIC MOUNTAINS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 745, col: 5, offset: 22648},
+						pos: position{line: 746, col: 5, offset: 22715},
 						run: (*parser).callonTERRAIN48,
 						expr: &litMatcher{
-							pos:        position{line: 745, col: 5, offset: 22648},
+							pos:        position{line: 746, col: 5, offset: 22715},
 							val:        "LOW VOLCANO MOUNTAINS",
 							ignoreCase: false,
 							want:       "\"LOW VOLCANO MOUNTAINS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 747, col: 5, offset: 22723},
+						pos: position{line: 748, col: 5, offset: 22790},
 						run: (*parser).callonTERRAIN50,
 						expr: &litMatcher{
-							pos:        position{line: 747, col: 5, offset: 22723},
+							pos:        position{line: 748, col: 5, offset: 22790},
 							val:        "OCEAN",
 							ignoreCase: false,
 							want:       "\"OCEAN\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 749, col: 5, offset: 22772},
+						pos: position{line: 750, col: 5, offset: 22839},
 						run: (*parser).callonTERRAIN52,
 						expr: &litMatcher{
-							pos:        position{line: 749, col: 5, offset: 22772},
+							pos:        position{line: 750, col: 5, offset: 22839},
 							val:        "PLATEAU GRASSY HILLS",
 							ignoreCase: false,
 							want:       "\"PLATEAU GRASSY HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 751, col: 5, offset: 22844},
+						pos: position{line: 752, col: 5, offset: 22911},
 						run: (*parser).callonTERRAIN54,
 						expr: &litMatcher{
-							pos:        position{line: 751, col: 5, offset: 22844},
+							pos:        position{line: 752, col: 5, offset: 22911},
 							val:        "PLATEAU PRAIRIE",
 							ignoreCase: false,
 							want:       "\"PLATEAU PRAIRIE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 753, col: 5, offset: 22911},
+						pos: position{line: 754, col: 5, offset: 22978},
 						run: (*parser).callonTERRAIN56,
 						expr: &litMatcher{
-							pos:        position{line: 753, col: 5, offset: 22911},
+							pos:        position{line: 754, col: 5, offset: 22978},
 							val:        "POLAR ICE",
 							ignoreCase: false,
 							want:       "\"POLAR ICE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 755, col: 5, offset: 22966},
+						pos: position{line: 756, col: 5, offset: 23033},
 						run: (*parser).callonTERRAIN58,
 						expr: &litMatcher{
-							pos:        position{line: 755, col: 5, offset: 22966},
+							pos:        position{line: 756, col: 5, offset: 23033},
 							val:        "PRAIRIE",
 							ignoreCase: false,
 							want:       "\"PRAIRIE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 757, col: 5, offset: 23018},
+						pos: position{line: 758, col: 5, offset: 23085},
 						run: (*parser).callonTERRAIN60,
 						expr: &litMatcher{
-							pos:        position{line: 757, col: 5, offset: 23018},
+							pos:        position{line: 758, col: 5, offset: 23085},
 							val:        "ROCKY HILLS",
 							ignoreCase: false,
 							want:       "\"ROCKY HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 759, col: 5, offset: 23073},
+						pos: position{line: 760, col: 5, offset: 23140},
 						run: (*parser).callonTERRAIN62,
 						expr: &litMatcher{
-							pos:        position{line: 759, col: 5, offset: 23073},
+							pos:        position{line: 760, col: 5, offset: 23140},
 							val:        "SNOWY HILLS",
 							ignoreCase: false,
 							want:       "\"SNOWY HILLS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 761, col: 5, offset: 23128},
+						pos: position{line: 762, col: 5, offset: 23195},
 						run: (*parser).callonTERRAIN64,
 						expr: &litMatcher{
-							pos:        position{line: 761, col: 5, offset: 23128},
+							pos:        position{line: 762, col: 5, offset: 23195},
 							val:        "SWAMP",
 							ignoreCase: false,
 							want:       "\"SWAMP\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 763, col: 5, offset: 23176},
+						pos: position{line: 764, col: 5, offset: 23243},
 						run: (*parser).callonTERRAIN66,
 						expr: &litMatcher{
-							pos:        position{line: 763, col: 5, offset: 23176},
+							pos:        position{line: 764, col: 5, offset: 23243},
 							val:        "TUNDRA",
 							ignoreCase: false,
 							want:       "\"TUNDRA\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 765, col: 5, offset: 23226},
+						pos: position{line: 766, col: 5, offset: 23293},
 						run: (*parser).callonTERRAIN68,
 						expr: &litMatcher{
-							pos:        position{line: 765, col: 5, offset: 23226},
+							pos:        position{line: 766, col: 5, offset: 23293},
 							val:        "UNKNOWN",
 							ignoreCase: false,
 							want:       "\"UNKNOWN\"",
@@ -4881,305 +4891,305 @@ var g = &grammar{
 		},
 		{
 			name: "TERRAIN_CODE",
-			pos:  position{line: 769, col: 1, offset: 23271},
+			pos:  position{line: 770, col: 1, offset: 23338},
 			expr: &choiceExpr{
-				pos: position{line: 769, col: 17, offset: 23287},
+				pos: position{line: 770, col: 17, offset: 23354},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 769, col: 17, offset: 23287},
+						pos: position{line: 770, col: 17, offset: 23354},
 						run: (*parser).callonTERRAIN_CODE2,
 						expr: &litMatcher{
-							pos:        position{line: 769, col: 17, offset: 23287},
+							pos:        position{line: 770, col: 17, offset: 23354},
 							val:        "ALPS",
 							ignoreCase: false,
 							want:       "\"ALPS\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 770, col: 5, offset: 23337},
+						pos: position{line: 771, col: 5, offset: 23404},
 						run: (*parser).callonTERRAIN_CODE4,
 						expr: &litMatcher{
-							pos:        position{line: 770, col: 5, offset: 23337},
+							pos:        position{line: 771, col: 5, offset: 23404},
 							val:        "GHP",
 							ignoreCase: false,
 							want:       "\"GHP\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 771, col: 5, offset: 23388},
+						pos: position{line: 772, col: 5, offset: 23455},
 						run: (*parser).callonTERRAIN_CODE6,
 						expr: &litMatcher{
-							pos:        position{line: 771, col: 5, offset: 23388},
+							pos:        position{line: 772, col: 5, offset: 23455},
 							val:        "HSM",
 							ignoreCase: false,
 							want:       "\"HSM\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 772, col: 5, offset: 23439},
+						pos: position{line: 773, col: 5, offset: 23506},
 						run: (*parser).callonTERRAIN_CODE8,
 						expr: &litMatcher{
-							pos:        position{line: 772, col: 5, offset: 23439},
+							pos:        position{line: 773, col: 5, offset: 23506},
 							val:        "LAM",
 							ignoreCase: false,
 							want:       "\"LAM\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 773, col: 5, offset: 23488},
+						pos: position{line: 774, col: 5, offset: 23555},
 						run: (*parser).callonTERRAIN_CODE10,
 						expr: &litMatcher{
-							pos:        position{line: 773, col: 5, offset: 23488},
+							pos:        position{line: 774, col: 5, offset: 23555},
 							val:        "LCM",
 							ignoreCase: false,
 							want:       "\"LCM\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 774, col: 5, offset: 23540},
+						pos: position{line: 775, col: 5, offset: 23607},
 						run: (*parser).callonTERRAIN_CODE12,
 						expr: &litMatcher{
-							pos:        position{line: 774, col: 5, offset: 23540},
+							pos:        position{line: 775, col: 5, offset: 23607},
 							val:        "LJM",
 							ignoreCase: false,
 							want:       "\"LJM\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 775, col: 5, offset: 23591},
+						pos: position{line: 776, col: 5, offset: 23658},
 						run: (*parser).callonTERRAIN_CODE14,
 						expr: &litMatcher{
-							pos:        position{line: 775, col: 5, offset: 23591},
+							pos:        position{line: 776, col: 5, offset: 23658},
 							val:        "LSM",
 							ignoreCase: false,
 							want:       "\"LSM\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 776, col: 5, offset: 23641},
+						pos: position{line: 777, col: 5, offset: 23708},
 						run: (*parser).callonTERRAIN_CODE16,
 						expr: &litMatcher{
-							pos:        position{line: 776, col: 5, offset: 23641},
+							pos:        position{line: 777, col: 5, offset: 23708},
 							val:        "LVM",
 							ignoreCase: false,
 							want:       "\"LVM\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 777, col: 5, offset: 23694},
+						pos: position{line: 778, col: 5, offset: 23761},
 						run: (*parser).callonTERRAIN_CODE18,
 						expr: &litMatcher{
-							pos:        position{line: 777, col: 5, offset: 23694},
+							pos:        position{line: 778, col: 5, offset: 23761},
 							val:        "PGH",
 							ignoreCase: false,
 							want:       "\"PGH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 778, col: 5, offset: 23745},
+						pos: position{line: 779, col: 5, offset: 23812},
 						run: (*parser).callonTERRAIN_CODE20,
 						expr: &litMatcher{
-							pos:        position{line: 778, col: 5, offset: 23745},
+							pos:        position{line: 779, col: 5, offset: 23812},
 							val:        "PPR",
 							ignoreCase: false,
 							want:       "\"PPR\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 779, col: 5, offset: 23796},
+						pos: position{line: 780, col: 5, offset: 23863},
 						run: (*parser).callonTERRAIN_CODE22,
 						expr: &litMatcher{
-							pos:        position{line: 779, col: 5, offset: 23796},
+							pos:        position{line: 780, col: 5, offset: 23863},
 							val:        "AH",
 							ignoreCase: false,
 							want:       "\"AH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 780, col: 5, offset: 23837},
+						pos: position{line: 781, col: 5, offset: 23904},
 						run: (*parser).callonTERRAIN_CODE24,
 						expr: &litMatcher{
-							pos:        position{line: 780, col: 5, offset: 23837},
+							pos:        position{line: 781, col: 5, offset: 23904},
 							val:        "AR",
 							ignoreCase: false,
 							want:       "\"AR\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 781, col: 5, offset: 23877},
+						pos: position{line: 782, col: 5, offset: 23944},
 						run: (*parser).callonTERRAIN_CODE26,
 						expr: &litMatcher{
-							pos:        position{line: 781, col: 5, offset: 23877},
+							pos:        position{line: 782, col: 5, offset: 23944},
 							val:        "BF",
 							ignoreCase: false,
 							want:       "\"BF\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 782, col: 5, offset: 23918},
+						pos: position{line: 783, col: 5, offset: 23985},
 						run: (*parser).callonTERRAIN_CODE28,
 						expr: &litMatcher{
-							pos:        position{line: 782, col: 5, offset: 23918},
+							pos:        position{line: 783, col: 5, offset: 23985},
 							val:        "BH",
 							ignoreCase: false,
 							want:       "\"BH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 783, col: 5, offset: 23960},
+						pos: position{line: 784, col: 5, offset: 24027},
 						run: (*parser).callonTERRAIN_CODE30,
 						expr: &litMatcher{
-							pos:        position{line: 783, col: 5, offset: 23960},
+							pos:        position{line: 784, col: 5, offset: 24027},
 							val:        "CH",
 							ignoreCase: false,
 							want:       "\"CH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 784, col: 5, offset: 24004},
+						pos: position{line: 785, col: 5, offset: 24071},
 						run: (*parser).callonTERRAIN_CODE32,
 						expr: &litMatcher{
-							pos:        position{line: 784, col: 5, offset: 24004},
+							pos:        position{line: 785, col: 5, offset: 24071},
 							val:        "DE",
 							ignoreCase: false,
 							want:       "\"DE\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 785, col: 5, offset: 24046},
+						pos: position{line: 786, col: 5, offset: 24113},
 						run: (*parser).callonTERRAIN_CODE34,
 						expr: &litMatcher{
-							pos:        position{line: 785, col: 5, offset: 24046},
+							pos:        position{line: 786, col: 5, offset: 24113},
 							val:        "DF",
 							ignoreCase: false,
 							want:       "\"DF\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 786, col: 5, offset: 24091},
+						pos: position{line: 787, col: 5, offset: 24158},
 						run: (*parser).callonTERRAIN_CODE36,
 						expr: &litMatcher{
-							pos:        position{line: 786, col: 5, offset: 24091},
+							pos:        position{line: 787, col: 5, offset: 24158},
 							val:        "DH",
 							ignoreCase: false,
 							want:       "\"DH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 787, col: 5, offset: 24137},
+						pos: position{line: 788, col: 5, offset: 24204},
 						run: (*parser).callonTERRAIN_CODE38,
 						expr: &litMatcher{
-							pos:        position{line: 787, col: 5, offset: 24137},
+							pos:        position{line: 788, col: 5, offset: 24204},
 							val:        "GH",
 							ignoreCase: false,
 							want:       "\"GH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 788, col: 5, offset: 24180},
+						pos: position{line: 789, col: 5, offset: 24247},
 						run: (*parser).callonTERRAIN_CODE40,
 						expr: &litMatcher{
-							pos:        position{line: 788, col: 5, offset: 24180},
+							pos:        position{line: 789, col: 5, offset: 24247},
 							val:        "JG",
 							ignoreCase: false,
 							want:       "\"JG\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 789, col: 5, offset: 24222},
+						pos: position{line: 790, col: 5, offset: 24289},
 						run: (*parser).callonTERRAIN_CODE42,
 						expr: &litMatcher{
-							pos:        position{line: 789, col: 5, offset: 24222},
+							pos:        position{line: 790, col: 5, offset: 24289},
 							val:        "JH",
 							ignoreCase: false,
 							want:       "\"JH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 790, col: 5, offset: 24265},
+						pos: position{line: 791, col: 5, offset: 24332},
 						run: (*parser).callonTERRAIN_CODE44,
 						expr: &litMatcher{
-							pos:        position{line: 790, col: 5, offset: 24265},
+							pos:        position{line: 791, col: 5, offset: 24332},
 							val:        "PI",
 							ignoreCase: false,
 							want:       "\"PI\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 791, col: 5, offset: 24309},
+						pos: position{line: 792, col: 5, offset: 24376},
 						run: (*parser).callonTERRAIN_CODE46,
 						expr: &litMatcher{
-							pos:        position{line: 791, col: 5, offset: 24309},
+							pos:        position{line: 792, col: 5, offset: 24376},
 							val:        "PR",
 							ignoreCase: false,
 							want:       "\"PR\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 792, col: 5, offset: 24352},
+						pos: position{line: 793, col: 5, offset: 24419},
 						run: (*parser).callonTERRAIN_CODE48,
 						expr: &litMatcher{
-							pos:        position{line: 792, col: 5, offset: 24352},
+							pos:        position{line: 793, col: 5, offset: 24419},
 							val:        "RH",
 							ignoreCase: false,
 							want:       "\"RH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 793, col: 5, offset: 24394},
+						pos: position{line: 794, col: 5, offset: 24461},
 						run: (*parser).callonTERRAIN_CODE50,
 						expr: &litMatcher{
-							pos:        position{line: 793, col: 5, offset: 24394},
+							pos:        position{line: 794, col: 5, offset: 24461},
 							val:        "SH",
 							ignoreCase: false,
 							want:       "\"SH\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 794, col: 5, offset: 24436},
+						pos: position{line: 795, col: 5, offset: 24503},
 						run: (*parser).callonTERRAIN_CODE52,
 						expr: &litMatcher{
-							pos:        position{line: 794, col: 5, offset: 24436},
+							pos:        position{line: 795, col: 5, offset: 24503},
 							val:        "SW",
 							ignoreCase: false,
 							want:       "\"SW\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 795, col: 5, offset: 24477},
+						pos: position{line: 796, col: 5, offset: 24544},
 						run: (*parser).callonTERRAIN_CODE54,
 						expr: &litMatcher{
-							pos:        position{line: 795, col: 5, offset: 24477},
+							pos:        position{line: 796, col: 5, offset: 24544},
 							val:        "TU",
 							ignoreCase: false,
 							want:       "\"TU\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 796, col: 5, offset: 24519},
+						pos: position{line: 797, col: 5, offset: 24586},
 						run: (*parser).callonTERRAIN_CODE56,
 						expr: &litMatcher{
-							pos:        position{line: 796, col: 5, offset: 24519},
+							pos:        position{line: 797, col: 5, offset: 24586},
 							val:        "D",
 							ignoreCase: false,
 							want:       "\"D\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 797, col: 5, offset: 24563},
+						pos: position{line: 798, col: 5, offset: 24630},
 						run: (*parser).callonTERRAIN_CODE58,
 						expr: &litMatcher{
-							pos:        position{line: 797, col: 5, offset: 24563},
+							pos:        position{line: 798, col: 5, offset: 24630},
 							val:        "L",
 							ignoreCase: false,
 							want:       "\"L\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 798, col: 5, offset: 24603},
+						pos: position{line: 799, col: 5, offset: 24670},
 						run: (*parser).callonTERRAIN_CODE60,
 						expr: &litMatcher{
-							pos:        position{line: 798, col: 5, offset: 24603},
+							pos:        position{line: 799, col: 5, offset: 24670},
 							val:        "O",
 							ignoreCase: false,
 							want:       "\"O\"",
@@ -5190,43 +5200,50 @@ var g = &grammar{
 		},
 		{
 			name: "UNIT_ID",
-			pos:  position{line: 801, col: 1, offset: 24643},
+			pos:  position{line: 802, col: 1, offset: 24710},
 			expr: &actionExpr{
-				pos: position{line: 801, col: 12, offset: 24654},
+				pos: position{line: 802, col: 12, offset: 24721},
 				run: (*parser).callonUNIT_ID1,
 				expr: &seqExpr{
-					pos: position{line: 801, col: 12, offset: 24654},
+					pos: position{line: 802, col: 12, offset: 24721},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 801, col: 12, offset: 24654},
+							pos:  position{line: 802, col: 12, offset: 24721},
 							name: "DIGIT",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 801, col: 18, offset: 24660},
+							pos:  position{line: 802, col: 18, offset: 24727},
 							name: "DIGIT",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 801, col: 24, offset: 24666},
+							pos:  position{line: 802, col: 24, offset: 24733},
 							name: "DIGIT",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 801, col: 30, offset: 24672},
+							pos:  position{line: 802, col: 30, offset: 24739},
 							name: "DIGIT",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 801, col: 36, offset: 24678},
+							pos: position{line: 802, col: 36, offset: 24745},
+							expr: &ruleRefExpr{
+								pos:  position{line: 802, col: 36, offset: 24745},
+								name: "DIGIT",
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 802, col: 43, offset: 24752},
 							expr: &seqExpr{
-								pos: position{line: 801, col: 37, offset: 24679},
+								pos: position{line: 802, col: 44, offset: 24753},
 								exprs: []any{
 									&charClassMatcher{
-										pos:        position{line: 801, col: 37, offset: 24679},
+										pos:        position{line: 802, col: 44, offset: 24753},
 										val:        "[cefg]",
 										chars:      []rune{'c', 'e', 'f', 'g'},
 										ignoreCase: false,
 										inverted:   false,
 									},
 									&charClassMatcher{
-										pos:        position{line: 801, col: 44, offset: 24686},
+										pos:        position{line: 802, col: 51, offset: 24760},
 										val:        "[1-9]",
 										ranges:     []rune{'1', '9'},
 										ignoreCase: false,
@@ -5241,45 +5258,45 @@ var g = &grammar{
 		},
 		{
 			name: "WINDSTRENGTH",
-			pos:  position{line: 805, col: 1, offset: 24732},
+			pos:  position{line: 806, col: 1, offset: 24806},
 			expr: &choiceExpr{
-				pos: position{line: 805, col: 17, offset: 24748},
+				pos: position{line: 806, col: 17, offset: 24822},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 805, col: 17, offset: 24748},
+						pos: position{line: 806, col: 17, offset: 24822},
 						run: (*parser).callonWINDSTRENGTH2,
 						expr: &litMatcher{
-							pos:        position{line: 805, col: 17, offset: 24748},
+							pos:        position{line: 806, col: 17, offset: 24822},
 							val:        "CALM",
 							ignoreCase: false,
 							want:       "\"CALM\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 807, col: 5, offset: 24788},
+						pos: position{line: 808, col: 5, offset: 24862},
 						run: (*parser).callonWINDSTRENGTH4,
 						expr: &litMatcher{
-							pos:        position{line: 807, col: 5, offset: 24788},
+							pos:        position{line: 808, col: 5, offset: 24862},
 							val:        "MILD",
 							ignoreCase: false,
 							want:       "\"MILD\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 809, col: 5, offset: 24828},
+						pos: position{line: 810, col: 5, offset: 24902},
 						run: (*parser).callonWINDSTRENGTH6,
 						expr: &litMatcher{
-							pos:        position{line: 809, col: 5, offset: 24828},
+							pos:        position{line: 810, col: 5, offset: 24902},
 							val:        "STRONG",
 							ignoreCase: false,
 							want:       "\"STRONG\"",
 						},
 					},
 					&actionExpr{
-						pos: position{line: 811, col: 5, offset: 24872},
+						pos: position{line: 812, col: 5, offset: 24946},
 						run: (*parser).callonWINDSTRENGTH8,
 						expr: &litMatcher{
-							pos:        position{line: 811, col: 5, offset: 24872},
+							pos:        position{line: 812, col: 5, offset: 24946},
 							val:        "GALE",
 							ignoreCase: false,
 							want:       "\"GALE\"",
@@ -5290,29 +5307,29 @@ var g = &grammar{
 		},
 		{
 			name: "YEAR",
-			pos:  position{line: 815, col: 1, offset: 24911},
+			pos:  position{line: 816, col: 1, offset: 24985},
 			expr: &actionExpr{
-				pos: position{line: 815, col: 9, offset: 24919},
+				pos: position{line: 816, col: 9, offset: 24993},
 				run: (*parser).callonYEAR1,
 				expr: &seqExpr{
-					pos: position{line: 815, col: 9, offset: 24919},
+					pos: position{line: 816, col: 9, offset: 24993},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 815, col: 9, offset: 24919},
+							pos:  position{line: 816, col: 9, offset: 24993},
 							name: "DIGIT",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 815, col: 15, offset: 24925},
+							pos:  position{line: 816, col: 15, offset: 24999},
 							name: "DIGIT",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 815, col: 21, offset: 24931},
+							pos:  position{line: 816, col: 21, offset: 25005},
 							name: "DIGIT",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 815, col: 27, offset: 24937},
+							pos: position{line: 816, col: 27, offset: 25011},
 							expr: &ruleRefExpr{
-								pos:  position{line: 815, col: 27, offset: 24937},
+								pos:  position{line: 816, col: 27, offset: 25011},
 								name: "DIGIT",
 							},
 						},
@@ -5322,9 +5339,9 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 821, col: 1, offset: 25014},
+			pos:  position{line: 822, col: 1, offset: 25088},
 			expr: &notExpr{
-				pos: position{line: 821, col: 10, offset: 25023},
+				pos: position{line: 822, col: 10, offset: 25097},
 				expr: &anyMatcher{
 					line: 821, col: 11, offset: 25024,
 				},
@@ -5332,9 +5349,9 @@ var g = &grammar{
 		},
 		{
 			name: "DIGIT",
-			pos:  position{line: 822, col: 1, offset: 25026},
+			pos:  position{line: 823, col: 1, offset: 25100},
 			expr: &charClassMatcher{
-				pos:        position{line: 822, col: 10, offset: 25035},
+				pos:        position{line: 823, col: 10, offset: 25109},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -5343,9 +5360,9 @@ var g = &grammar{
 		},
 		{
 			name: "LETTER",
-			pos:  position{line: 823, col: 1, offset: 25041},
+			pos:  position{line: 824, col: 1, offset: 25115},
 			expr: &charClassMatcher{
-				pos:        position{line: 823, col: 10, offset: 25050},
+				pos:        position{line: 824, col: 10, offset: 25124},
 				val:        "[A-Z]",
 				ranges:     []rune{'A', 'Z'},
 				ignoreCase: false,
@@ -5354,11 +5371,11 @@ var g = &grammar{
 		},
 		{
 			name: "SP",
-			pos:  position{line: 824, col: 1, offset: 25056},
+			pos:  position{line: 825, col: 1, offset: 25130},
 			expr: &oneOrMoreExpr{
-				pos: position{line: 824, col: 10, offset: 25065},
+				pos: position{line: 825, col: 10, offset: 25139},
 				expr: &charClassMatcher{
-					pos:        position{line: 824, col: 10, offset: 25065},
+					pos:        position{line: 825, col: 10, offset: 25139},
 					val:        "[ \\t]",
 					chars:      []rune{' ', '\t'},
 					ignoreCase: false,
@@ -5368,11 +5385,11 @@ var g = &grammar{
 		},
 		{
 			name: "_",
-			pos:  position{line: 825, col: 1, offset: 25072},
+			pos:  position{line: 826, col: 1, offset: 25146},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 825, col: 10, offset: 25081},
+				pos: position{line: 826, col: 10, offset: 25155},
 				expr: &charClassMatcher{
-					pos:        position{line: 825, col: 10, offset: 25081},
+					pos:        position{line: 826, col: 10, offset: 25155},
 					val:        "[ \\t]",
 					chars:      []rune{' ', '\t'},
 					ignoreCase: false,
@@ -6205,23 +6222,22 @@ func (p *parser) callonTribeMovement1() (any, error) {
 	return p.cur.onTribeMovement1(stack["results"])
 }
 
-func (c *current) onTurnInfo1(cd, nt any) (any, error) {
+func (c *current) onTurnInfo1(cd, ts, tw, nt any) (any, error) {
+	ti := TurnInfo_t{
+		CurrentTurn: cd.(Date_t),
+		Season:      string(ts.([]byte)),
+		Weather:     string(tw.([]byte)),
+	}
 	if nt != nil {
-		return TurnInfo_t{
-			CurrentTurn: cd.(Date_t),
-			NextTurn:    nt.(Date_t),
-		}, nil
+		ti.NextTurn = nt.(Date_t)
 	}
-
-	return TurnInfo_t{
-		CurrentTurn: cd.(Date_t),
-	}, nil
+	return ti, nil
 }
 
 func (p *parser) callonTurnInfo1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onTurnInfo1(stack["cd"], stack["nt"])
+	return p.cur.onTurnInfo1(stack["cd"], stack["ts"], stack["tw"], stack["nt"])
 }
 
 func (c *current) onCurrentTurn1(cd any) (any, error) {
@@ -5,17 +5,19 @@ package bistre
 import (
 	"bytes"
 	"fmt"
-	"log"
+	"log/slog"
 	"regexp"
 	"strings"
+
+	"github.com/mdhender/tnrpt/model"
 )
 
 // hexReportToNodes converts a hex report into a linked list of nodes
 // where each node contains all the arguments for each component of
 // the hex report.
-func hexReportToNodes(hexReport []byte, debugNodes bool, experimentalUnitSplit bool) (root *node) {
+func hexReportToNodes(logger *slog.Logger, hexReport []byte, debugNodes bool, experimentalUnitSplit bool) (root *node) {
 	if debugNodes {
-		log.Printf("parser: root: before split %s\n", string(hexReport))
+		logger.Debug("parser: nodes: before split", "text", string(hexReport))
 	}
 
 	var tail *node
@@ -47,7 +49,7 @@ func hexReportToNodes(hexReport []byte, debugNodes bool, experimentalUnitSplit b
 	}
 
 	if debugNodes {
-		log.Printf("parser: root: after split %s\n", printNodes(root))
+		logger.Debug("parser: nodes: after split", "nodes", printNodes(root))
 	}
 
 	// experimental: if the last node in a list is a unit, split it out
@@ -77,7 +79,7 @@ func hexReportToNodes(hexReport []byte, debugNodes bool, experimentalUnitSplit b
 			// stay on this node because we may have multiple units at the end
 		}
 		if foundUnits != 0 {
-			log.Printf("parser: experiment: %d units split %s\n", foundUnits, printNodes(root))
+			logger.Debug("parser: nodes: units split", "units", foundUnits, "nodes", printNodes(root))
 		}
 	}
 
@@ -165,7 +167,7 @@ func hexReportToNodes(hexReport []byte, debugNodes bool, experimentalUnitSplit b
 	}
 
 	if debugNodes {
-		log.Printf("parser: root: after consolidating %s\n", printNodes(root))
+		logger.Debug("parser: nodes: after consolidating", "nodes", printNodes(root))
 	}
 
 	return root
@@ -327,8 +329,8 @@ func (n *node) isUnitId() bool {
 var (
 	rxFindQuantityItem = regexp.MustCompile(`^Find [0-9]+ [a-zA-Z][a-zA-Z ]+`)
 	rxQuantityItem     = regexp.MustCompile(`^[0-9]+ [a-zA-Z][a-zA-Z ]+`)
-	rxUnitId           = regexp.MustCompile(`^[0-9][0-9][0-9][0-9]([cefg][0-9])?`)
-	rxTextUnitId       = regexp.MustCompile(`^(.*)\s+([0-9][0-9][0-9][0-9]([cefg][0-9])?)$`)
+	rxUnitId           = regexp.MustCompile(`^` + model.TribePattern + `([cefg][0-9])?`)
+	rxTextUnitId       = regexp.MustCompile(`^(.*)\s+(` + model.TribePattern + `([cefg][0-9])?)$`)
 )
 
 func isDirDashTerrain(text []byte) bool {
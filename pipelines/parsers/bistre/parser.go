@@ -5,7 +5,7 @@ package bistre
 import (
 	"bytes"
 	"fmt"
-	"log"
+	"log/slog"
 	"regexp"
 	"sort"
 	"strconv"
@@ -17,6 +17,8 @@ import (
 	"github.com/mdhender/tnrpt/coords"
 	"github.com/mdhender/tnrpt/direction"
 	"github.com/mdhender/tnrpt/edges"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
 	"github.com/mdhender/tnrpt/norm"
 	"github.com/mdhender/tnrpt/resources"
 	"github.com/mdhender/tnrpt/results"
@@ -26,22 +28,60 @@ import (
 )
 
 var (
-	rxCourierSection  = regexp.MustCompile(`^Courier \d{4}c\d, `)
-	rxElementSection  = regexp.MustCompile(`^Element \d{4}e\d, `)
-	rxFleetSection    = regexp.MustCompile(`^Fleet \d{4}f\d, `)
+	rxCourierSection  = regexp.MustCompile(`^Courier ` + model.TribePattern + `c\d, `)
+	rxElementSection  = regexp.MustCompile(`^Element ` + model.TribePattern + `e\d, `)
+	rxFleetSection    = regexp.MustCompile(`^Fleet ` + model.TribePattern + `f\d, `)
 	rxFleetMovement   = regexp.MustCompile(`^(CALM|MILD|STRONG|GALE)\s(NE|SE|SW|NW|N|S)\sFleet\sMovement:\sMove\s`)
-	rxGarrisonSection = regexp.MustCompile(`^Garrison \d{4}g\d, `)
+	rxGarrisonSection = regexp.MustCompile(`^Garrison ` + model.TribePattern + `g\d, `)
 	rxScoutLine       = regexp.MustCompile(`^Scout \d:Scout `)
-	rxTribeSection    = regexp.MustCompile(`^Tribe \d{4}, `)
+	rxTribeSection    = regexp.MustCompile(`^Tribe ` + model.TribePattern + `, `)
 )
 
 const (
 	LastTurnCurrentLocationObscured = "0902-01"
 )
 
+// sectionUnitId returns the unit ID from a line that matched one of the
+// section patterns, e.g. "0987c1" from "Courier 0987c1, , Current Hex = ...".
+func sectionUnitId(line []byte) UnitId_t {
+	start := bytes.IndexByte(line, ' ') + 1
+	return UnitId_t(line[start : start+bytes.IndexByte(line[start:], ',')])
+}
+
+// Version is the version of the parser's output. Bump it when a change to
+// the parser changes the Turn_t that ParseInput returns for the same input,
+// so that cached parse results (see the pipeline's parse stage) aren't reused.
+var Version = semver.Version{Major: 1, Minor: 2}
+
+// ParseConfig holds the toggles for ParseInput. The zero value is the
+// default: lone dashes are errors, debugging is off, the experimental
+// fixes are disabled, and messages go to slog.Default().
 type ParseConfig struct {
 	Version semver.Version
-	Ignore  struct {
+	// Logger receives the parser's messages. Each record carries the file,
+	// unit, and line it is about.
+	Logger *slog.Logger
+	// AcceptLoneDash ignores a lone "-" in a movement step instead of
+	// failing the parse.
+	AcceptLoneDash bool
+	// Debug turns on logging for each part of the parser. The messages
+	// are logged at debug level.
+	Debug struct {
+		Parser        bool
+		Sections      bool
+		Steps         bool
+		Nodes         bool
+		FleetMovement bool
+	}
+	Experimental struct {
+		// UnitSplit splits a unit ID at the end of a node's text into
+		// a node of its own.
+		UnitSplit bool
+		// ScoutStill marks a scout whose first step is "Still" as not
+		// having moved.
+		ScoutStill bool
+	}
+	Ignore struct {
 		Scouts bool
 		Logged struct {
 			Scouts bool
@@ -49,29 +89,19 @@ type ParseConfig struct {
 	}
 }
 
-func ParseInput(fid, tid string, input []byte, acceptLoneDash, debugParser, debugSections, debugSteps, debugNodes, debugFleetMovement bool, experimentalUnitSplit, experimentalScoutStill bool, cfg ParseConfig) (*Turn_t, error) {
-	//if tid == "0903-04" {
-	//	debugParser = true
-	//	debugSections = true
-	//	debugSteps = true
-	//	debugFleetMovement = true
-	//}
-	debugfm := func(format string, args ...any) {
-		if debugFleetMovement {
-			log.Printf(format, args...)
-		}
-	}
-	debugp := func(format string, args ...any) {
-		if debugParser {
-			log.Printf(format, args...)
-		}
+func ParseInput(fid, tid string, input []byte, cfg ParseConfig) (*Turn_t, error) {
+	acceptLoneDash := cfg.AcceptLoneDash
+	debugParser, debugSections, debugSteps, debugNodes, debugFleetMovement := cfg.Debug.Parser, cfg.Debug.Sections, cfg.Debug.Steps, cfg.Debug.Nodes, cfg.Debug.FleetMovement
+	experimentalUnitSplit, experimentalScoutStill := cfg.Experimental.UnitSplit, cfg.Experimental.ScoutStill
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
 	}
-	debugs := func(format string, args ...any) {
-		if debugSections {
-			log.Printf(format, args...)
-		}
+	logger = logger.With(logging.KeyFile, fid)
+	if debugParser {
+		logger.Debug("parser: input", "bytes", len(input))
 	}
-	debugp("%s: parser: %8d bytes\n", fid, len(input))
 
 	t := &Turn_t{
 		UnitMoves: map[UnitId_t]*Moves_t{},
@@ -85,105 +115,105 @@ func ParseInput(fid, tid string, input []byte, acceptLoneDash, debugParser, debu
 			continue
 		}
 		lineNo := n + 1
+		// at returns the logger for this line of the current unit's section
+		at := func() *slog.Logger {
+			return logger.With("unit", unitId, "line", lineNo)
+		}
 
 		if rxCourierSection.Match(line) {
-			unitId = UnitId_t(line[8:14])
-			debugs("%s: %d: found %q\n", fid, lineNo, unitId)
-			location, err := ParseLocationLine(fid, tid, unitId, lineNo, line, debugParser)
+			unitId = sectionUnitId(line)
+			if debugSections {
+				at().Debug("parser: found section")
+			}
+			location, err := ParseLocationLine(at(), fid, tid, unitId, lineNo, line, debugParser)
 			if err != nil {
-				log.Printf("%s: %s: %d: location %q: %v\n", fid, unitId, lineNo, slug(line, 14), err)
+				at().Error("parser: location", "text", slug(line, 14), "err", err)
 				return t, err
-			} else if _, ok := t.UnitMoves[unitId]; ok {
-				log.Printf("%s: %s: %d: location %q\n", fid, unitId, lineNo, slug(line, 14))
-				return t, fmt.Errorf("duplicate unit in turn")
 			} else if t.Id > LastTurnCurrentLocationObscured && strings.HasPrefix(location.CurrentHex, "##") {
-				log.Printf("info: last turn current location is obscured is %s\n", LastTurnCurrentLocationObscured)
-				log.Printf("%s: %s: %d: location %q\n", fid, unitId, lineNo, location.CurrentHex)
+				at().Error("parser: current location is obscured", "turn", t.Id, "hex", location.CurrentHex, "last_obscured_turn", LastTurnCurrentLocationObscured)
 				return t, fmt.Errorf("current location is obscured")
 			}
-			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
-			t.UnitMoves[moves.UnitId] = moves
+			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, LineNo: lineNo, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
+			t.addMoves(moves)
 			scriesLinePrefix = []byte(fmt.Sprintf("%s Scry: ", unitId))
 			statusLinePrefix = []byte(fmt.Sprintf("%s Status: ", unitId))
 		} else if rxElementSection.Match(line) {
-			unitId = UnitId_t(line[8:14])
-			debugs("%s: %d: found %q\n", fid, lineNo, unitId)
-			location, err := ParseLocationLine(fid, tid, unitId, lineNo, line, debugParser)
+			unitId = sectionUnitId(line)
+			if debugSections {
+				at().Debug("parser: found section")
+			}
+			location, err := ParseLocationLine(at(), fid, tid, unitId, lineNo, line, debugParser)
 			if err != nil {
-				log.Printf("%s: %s: %d: location %q: %v\n", fid, unitId, lineNo, slug(line, 14), err)
+				at().Error("parser: location", "text", slug(line, 14), "err", err)
 				return t, err
-			} else if _, ok := t.UnitMoves[unitId]; ok {
-				log.Printf("%s: %s: %d: location %q\n", fid, unitId, lineNo, slug(line, 14))
-				return t, fmt.Errorf("duplicate unit in turn")
 			}
-			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
-			t.UnitMoves[moves.UnitId] = moves
+			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, LineNo: lineNo, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
+			t.addMoves(moves)
 			scriesLinePrefix = []byte(fmt.Sprintf("%s Scry: ", unitId))
 			statusLinePrefix = []byte(fmt.Sprintf("%s Status: ", unitId))
 		} else if rxFleetSection.Match(line) {
-			unitId = UnitId_t(line[6:12])
-			debugs("%s: %d: found %q\n", fid, lineNo, unitId)
-			location, err := ParseLocationLine(fid, tid, unitId, lineNo, line, debugParser)
+			unitId = sectionUnitId(line)
+			if debugSections {
+				at().Debug("parser: found section")
+			}
+			location, err := ParseLocationLine(at(), fid, tid, unitId, lineNo, line, debugParser)
 			if err != nil {
-				log.Printf("%s: %s: %d: location %q: %v\n", fid, unitId, lineNo, slug(line, 12), err)
+				at().Error("parser: location", "text", slug(line, 12), "err", err)
 				return t, err
-			} else if _, ok := t.UnitMoves[unitId]; ok {
-				log.Printf("%s: %s: %d: location %q\n", fid, unitId, lineNo, slug(line, 12))
-				return t, fmt.Errorf("duplicate unit in turn")
 			}
-			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
-			t.UnitMoves[moves.UnitId] = moves
+			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, LineNo: lineNo, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
+			t.addMoves(moves)
 			statusLinePrefix = []byte(fmt.Sprintf("%s Status: ", unitId))
 		} else if rxGarrisonSection.Match(line) {
-			unitId = UnitId_t(line[9:15])
-			debugs("%s: %d: found %q\n", fid, lineNo, unitId)
-			location, err := ParseLocationLine(fid, tid, unitId, lineNo, line, debugParser)
+			unitId = sectionUnitId(line)
+			if debugSections {
+				at().Debug("parser: found section")
+			}
+			location, err := ParseLocationLine(at(), fid, tid, unitId, lineNo, line, debugParser)
 			if err != nil {
-				log.Printf("%s: %s: %d: location %q: %v\n", fid, unitId, lineNo, slug(line, 15), err)
+				at().Error("parser: location", "text", slug(line, 15), "err", err)
 				return t, err
-			} else if _, ok := t.UnitMoves[unitId]; ok {
-				log.Printf("%s: %s: %d: location %q\n", fid, unitId, lineNo, slug(line, 15))
-				return t, fmt.Errorf("duplicate unit in turn")
 			}
-			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
-			t.UnitMoves[moves.UnitId] = moves
+			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, LineNo: lineNo, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
+			t.addMoves(moves)
 			scriesLinePrefix = []byte(fmt.Sprintf("%s Scry: ", unitId))
 			statusLinePrefix = []byte(fmt.Sprintf("%s Status: ", unitId))
 		} else if rxTribeSection.Match(line) {
-			unitId = UnitId_t(line[6:10])
-			debugs("%s: %d: found %q\n", fid, lineNo, unitId)
-			location, err := ParseLocationLine(fid, tid, unitId, lineNo, line, debugParser)
+			unitId = sectionUnitId(line)
+			if debugSections {
+				at().Debug("parser: found section")
+			}
+			location, err := ParseLocationLine(at(), fid, tid, unitId, lineNo, line, debugParser)
 			if err != nil {
-				log.Printf("%s: %s: %d: location %q: %v\n", fid, unitId, lineNo, slug(line, 10), err)
+				at().Error("parser: location", "text", slug(line, 10), "err", err)
 				return t, err
-			} else if _, ok := t.UnitMoves[unitId]; ok {
-				log.Printf("%s: %s: %d: location %q\n", fid, unitId, lineNo, slug(line, 10))
-				return t, fmt.Errorf("duplicate unit in turn")
 			}
-			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
-			t.UnitMoves[moves.UnitId] = moves
+			moves = &Moves_t{TurnId: t.Id, UnitId: unitId, LineNo: lineNo, PreviousHex: location.PreviousHex, CurrentHex: location.CurrentHex}
+			t.addMoves(moves)
 			scriesLinePrefix = []byte(fmt.Sprintf("%s Scry: ", unitId))
 			statusLinePrefix = []byte(fmt.Sprintf("%s Status: ", unitId))
 		} else if moves == nil {
-			log.Printf("%s: %s: %d: found line outside of section: %q\n", fid, unitId, lineNo, slug(line, 20))
+			at().Warn("parser: line outside of section", "text", slug(line, 20))
 		} else if bytes.HasPrefix(line, []byte("Current Turn ")) {
-			debugs("%s: %d: found %q\n", fid, lineNo, slug(line, 19))
+			if debugSections {
+				at().Debug("parser: found turn info", "text", slug(line, 19))
+			}
 			if va, err := Parse(fid, line, Entrypoint("TurnInfo")); err != nil {
-				log.Printf("%s: %s: %d: error parsing turn info", fid, unitId, lineNo)
+				at().Error("parser: turn info", "err", err)
 				return t, err
 			} else if turnInfo, ok := va.(TurnInfo_t); !ok {
-				log.Printf("%s: %s: %d: error parsing turn info", fid, unitId, lineNo)
-				log.Printf("error: parser.TurnInfo_t, got %T\n", va)
-				log.Printf("please report this error\n")
+				at().Error("parser: turn info: please report this error", "want", "TurnInfo_t", "got", fmt.Sprintf("%T", va))
 				panic(fmt.Sprintf("unexpected type %T", va))
 			} else {
 				if t.Id == "" {
 					t.Year, t.Month = turnInfo.CurrentTurn.Year, turnInfo.CurrentTurn.Month
 					t.Id = fmt.Sprintf("%04d-%02d", t.Year, t.Month)
 				}
+				if t.Season == "" {
+					t.Season, t.Weather = turnInfo.Season, turnInfo.Weather
+				}
 				if turnInfo.CurrentTurn.Year != t.Year || turnInfo.CurrentTurn.Month != t.Month {
-					log.Printf("%s: %s: %d: current turn: %04d-%02d", fid, unitId, lineNo, t.Year, t.Month)
-					log.Printf("%s: %s: %d:    unit turn: %04d-%02d", fid, unitId, lineNo, turnInfo.CurrentTurn.Year, turnInfo.CurrentTurn.Month)
+					at().Error("parser: turn mismatch", "turn", t.Id, "unit_turn", fmt.Sprintf("%04d-%02d", turnInfo.CurrentTurn.Year, turnInfo.CurrentTurn.Month))
 					return t, fmt.Errorf("turn mismatch in report")
 				}
 			}
@@ -195,8 +225,7 @@ func ParseInput(fid, tid string, input []byte, acceptLoneDash, debugParser, debu
 			}
 			id = bytes.ToLower(bytes.TrimSpace(id))
 			name = bytes.TrimSpace(name)
-			//log.Printf("%s: %s: %d: current turn: %04d-%02d", fid, unitId, lineNo, t.Year, t.Month)
-			log.Printf("%s: %s: %d: special name: %q -> %q", fid, unitId, lineNo, id, name)
+			at().Info("parser: special name", "id", string(id), "name", string(name))
 			if t.SpecialNames == nil {
 				t.SpecialNames = make(map[string]*Special_t)
 			}
@@ -210,8 +239,10 @@ func ParseInput(fid, tid string, input []byte, acceptLoneDash, debugParser, debu
 			if !ok {
 				pfx = []byte(slug(line, 23))
 			}
-			debugfm("%s: %s: %d: found %q\n", fid, unitId, lineNo, pfx)
-			unitMoves, err := ParseFleetMovementLine(fid, tid, unitId, lineNo, line, acceptLoneDash, debugFleetMovement || debugSteps, debugFleetMovement || debugNodes, debugFleetMovement, experimentalUnitSplit)
+			if debugFleetMovement {
+				at().Debug("parser: found fleet movement", "text", string(pfx))
+			}
+			unitMoves, err := ParseFleetMovementLine(at(), fid, tid, unitId, lineNo, line, acceptLoneDash, debugFleetMovement || debugSteps, debugFleetMovement || debugNodes, debugFleetMovement, experimentalUnitSplit)
 			if err != nil {
 				return t, err
 			}
@@ -219,32 +250,38 @@ func ParseInput(fid, tid string, input []byte, acceptLoneDash, debugParser, debu
 				moves.Moves = append(moves.Moves, unitMoves...)
 			}
 		} else if bytes.HasPrefix(line, []byte("Tribe Follows ")) {
-			debugs("%s: %s: %d: found %q\n", fid, unitId, lineNo, slug(line, 13))
+			if debugSections {
+				at().Debug("parser: found follows", "text", slug(line, 13))
+			}
 			if moves.Follows != "" {
-				log.Printf("error: %s: %s: %d: found multiple follows\n", fid, unitId, lineNo)
+				at().Error("parser: multiple follows")
 				return t, fmt.Errorf("multiple follows")
 			}
-			followMove, err := ParseTribeFollowsLine(fid, tid, unitId, lineNo, line, false)
+			followMove, err := ParseTribeFollowsLine(at(), fid, tid, unitId, lineNo, line, false)
 			if err != nil {
 				return t, err
 			}
 			moves.Follows = followMove.Follows
 			moves.Moves = append(moves.Moves, followMove)
 		} else if bytes.HasPrefix(line, []byte("Tribe Goes to ")) {
-			debugs("%s: %s: %d: found %q\n", fid, unitId, lineNo, slug(line, 14))
+			if debugSections {
+				at().Debug("parser: found goes to", "text", slug(line, 14))
+			}
 			if moves.GoesTo != "" {
-				log.Printf("error: %s: %s: %d: found multiple goes to\n", fid, unitId, lineNo)
+				at().Error("parser: multiple goes to")
 				return t, fmt.Errorf("multiple goes to")
 			}
-			goesToMove, err := ParseTribeGoesToLine(fid, tid, unitId, lineNo, line, false)
+			goesToMove, err := ParseTribeGoesToLine(at(), fid, tid, unitId, lineNo, line, false)
 			if err != nil {
 				return t, err
 			}
 			moves.GoesTo = goesToMove.GoesTo
 			moves.Moves = append(moves.Moves, goesToMove)
 		} else if bytes.HasPrefix(line, []byte("Tribe Movement: ")) {
-			debugs("%s: %s: %d: found %q\n", fid, unitId, lineNo, slug(line, 14))
-			unitMoves, err := ParseTribeMovementLine(fid, tid, unitId, lineNo, line, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit)
+			if debugSections {
+				at().Debug("parser: found tribe movement", "text", slug(line, 14))
+			}
+			unitMoves, err := ParseTribeMovementLine(at(), fid, tid, unitId, lineNo, line, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit)
 			if err != nil {
 				return t, err
 			}
@@ -254,29 +291,35 @@ func ParseInput(fid, tid string, input []byte, acceptLoneDash, debugParser, debu
 		} else if rxScoutLine.Match(line) {
 			if cfg.Ignore.Scouts {
 				if !cfg.Ignore.Logged.Scouts {
-					log.Printf("%s: %s: %d: ignoring scouts\n", fid, unitId, lineNo)
+					at().Info("parser: ignoring scouts")
 					cfg.Ignore.Logged.Scouts = true
 				}
 			} else {
-				debugs("%s: %s: %d: found %q\n", fid, unitId, lineNo, slug(line, 14))
-				scoutMoves, err := ParseScoutMovementLine(fid, tid, unitId, lineNo, line, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit, experimentalScoutStill)
+				if debugSections {
+					at().Debug("parser: found scout", "text", slug(line, 14))
+				}
+				scoutMoves, err := ParseScoutMovementLine(at(), fid, tid, unitId, lineNo, line, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit, experimentalScoutStill)
 				if err != nil {
-					log.Printf("%s: %s: %d: %s\n", fid, unitId, lineNo, err)
+					at().Error("parser: scout", "err", err)
 					return t, err
 				}
 				moves.Scouts = append(moves.Scouts, scoutMoves)
 			}
 		} else if bytes.HasPrefix(line, scriesLinePrefix) {
-			debugs("%s: %s: %d: found %q\n", fid, unitId, lineNo, scriesLinePrefix)
-			scry, err := ParseScryLine(fid, tid, unitId, lineNo, line, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit, experimentalScoutStill)
+			if debugSections {
+				at().Debug("parser: found scry", "text", string(scriesLinePrefix))
+			}
+			scry, err := ParseScryLine(at(), fid, tid, unitId, lineNo, line, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit, experimentalScoutStill)
 			if err != nil {
 				return t, err
 			}
 			//log.Printf("scries %q %d\n", scry.Type, len(scry.Moves))
 			moves.Scries = append(moves.Scries, scry)
 		} else if bytes.HasPrefix(line, statusLinePrefix) {
-			debugs("%s: %s: %d: found %q\n", fid, unitId, lineNo, statusLinePrefix)
-			statusMoves, err := ParseStatusLine(fid, tid, unitId, lineNo, line, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit)
+			if debugSections {
+				at().Debug("parser: found status", "text", string(statusLinePrefix))
+			}
+			statusMoves, err := ParseStatusLine(at(), fid, tid, unitId, lineNo, line, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit)
 			if err != nil {
 				return t, err
 			}
@@ -288,7 +331,11 @@ func ParseInput(fid, tid string, input []byte, acceptLoneDash, debugParser, debu
 
 	// stuff the turn id into all the moves so that sammy can sort them later
 	turnId := fmt.Sprintf("%04d-%02d", t.Year, t.Month)
+	sections := append([]*Moves_t(nil), t.Duplicates...)
 	for _, v := range t.UnitMoves {
+		sections = append(sections, v)
+	}
+	for _, v := range sections {
 		v.TurnId = turnId
 		for _, move := range v.Moves {
 			move.TurnId = turnId
@@ -388,19 +435,17 @@ type Step_t struct {
 
 // ParseFleetMovementLine parses a fleet movement line.
 // It returns the generic struct that covers all the known movement steps and cases.
-func ParseFleetMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves bool, experimentalUnitSplit bool) ([]*Move_t, error) {
+func ParseFleetMovementLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves bool, experimentalUnitSplit bool) ([]*Move_t, error) {
 	if va, err := Parse(fid, line, Entrypoint("FleetMovement")); err != nil {
 		return nil, err
 	} else if mt, ok := va.(Movement_t); !ok {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
-		log.Printf("error: want Movement_t, got %T\n", va)
-		log.Printf("please report this error\n")
+		logger.Error("parser: please report this error", "text", string(line), "want", "Movement_t", "got", fmt.Sprintf("%T", va))
 		panic(fmt.Errorf("unexpected type %T\n", va))
 	} else {
 		line = mt.Text
 	}
 	if debugSteps {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, slug(line, 44))
+		logger.Debug("parser: fleet movement", "text", slug(line, 44))
 	}
 
 	// remove the prefix and trim the line.
@@ -409,24 +454,21 @@ func ParseFleetMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line [
 	}
 	line = bytes.TrimPrefix(line, []byte{'M', 'o', 'v', 'e'})
 
-	return parseMovementLine(fid, tid, unitId, lineNo, line, false, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves, experimentalUnitSplit, false)
+	return parseMovementLine(logger, fid, tid, unitId, lineNo, line, false, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves, experimentalUnitSplit, false)
 }
 
-func ParseLocationLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, debug bool) (Location_t, error) {
+func ParseLocationLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, debug bool) (Location_t, error) {
 	if va, err := Parse(fid, line, Entrypoint("Location")); err != nil {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, slug(line, 14))
 		return Location_t{}, err
 	} else if location, ok := va.(Location_t); !ok {
-		log.Printf("%s: %s: %d: location: %q\n", fid, unitId, lineNo, slug(line, 15))
-		log.Printf("error: invalid type\n")
-		log.Printf("please report this error")
+		logger.Error("parser: please report this error", "text", slug(line, 15), "want", "Location_t", "got", fmt.Sprintf("%T", va))
 		panic(fmt.Errorf("want Location_t, got %T", va))
 	} else {
 		return location, nil
 	}
 }
 
-func ParseScoutMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes bool, experimentalUnitSplit, cleanUpScoutStill bool) (*Scout_t, error) {
+func ParseScoutMovementLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes bool, experimentalUnitSplit, cleanUpScoutStill bool) (*Scout_t, error) {
 	scout := &Scout_t{
 		TurnId: tid,
 		LineNo: lineNo,
@@ -436,21 +478,18 @@ func ParseScoutMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line [
 	if va, err := Parse(fid, line, Entrypoint("ScoutMovement")); err != nil {
 		return nil, err
 	} else if mt, ok := va.(Movement_t); !ok {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
-		log.Printf("error: want Movement_t, got %T\n", va)
-		log.Printf("please report this error\n")
+		logger.Error("parser: please report this error", "text", string(line), "want", "Movement_t", "got", fmt.Sprintf("%T", va))
 		panic(fmt.Errorf("unexpected type %T\n", va))
 	} else {
 		scout.No = mt.ScoutNo
 		line = mt.Text
 	}
 	if debugSteps {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
+		logger.Debug("parser: scout", "text", string(line))
 	}
 
 	// remove the prefix and trim the line
 	if !bytes.HasPrefix(line, []byte{'S', 'c', 'o', 'u', 't'}) {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
 		return nil, fmt.Errorf("expected 'Scout', found '%s'", slug(line, 8))
 	}
 	line = bytes.TrimSpace(bytes.TrimPrefix(line, []byte{'S', 'c', 'o', 'u', 't'}))
@@ -464,9 +503,9 @@ func ParseScoutMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line [
 	}
 
 	// parse the moves and then update each with the turn we did the scouting in
-	moves, err := parseMovementLine(fid, tid, unitId, lineNo, line, true, acceptLoneDash, debugSteps, debugNodes, false, experimentalUnitSplit, cleanUpScoutStill)
+	moves, err := parseMovementLine(logger, fid, tid, unitId, lineNo, line, true, acceptLoneDash, debugSteps, debugNodes, false, experimentalUnitSplit, cleanUpScoutStill)
 	if err != nil {
-		log.Printf("%s: %s: %d: %q: %v\n", fid, unitId, lineNo, line, err)
+		logger.Error("parser: scout", "text", string(line), "err", err)
 		return nil, err
 	}
 	for _, move := range moves {
@@ -479,24 +518,23 @@ func ParseScoutMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line [
 }
 
 // ParseScryLine expects input to be like a Status line or Scout line.
-func ParseScryLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit, experimentalScoutStill bool) (*Scry_t, error) {
-	log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
+func ParseScryLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit, experimentalScoutStill bool) (*Scry_t, error) {
+	if debugSteps {
+		logger.Debug("parser: scry", "text", string(line))
+	}
 	va, err := Parse(fid, line, Entrypoint("ScryLine"))
 	if err != nil {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, string(line))
-		log.Printf("status %v\n", err)
+		logger.Error("parser: scry", "text", string(line), "err", err)
 		return nil, err
 	}
 	s, ok := va.(*Scry_t)
 	if !ok {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
-		log.Printf("error: want *Scry_t, got %T\n", va)
-		log.Printf("please report this error\n")
+		logger.Error("parser: please report this error", "text", string(line), "want", "*Scry_t", "got", fmt.Sprintf("%T", va))
 		panic(fmt.Errorf("unexpected type %T\n", va))
 	}
 	s.Coordinates, err = coords.NewWorldMapCoord(s.Origin)
 	if err != nil {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, s.Origin)
+		logger.Error("parser: scry origin", "origin", s.Origin, "err", err)
 		panic(err)
 	}
 	//log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, s.Location.CurrentHex())
@@ -504,64 +542,53 @@ func ParseScryLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, ac
 		s.Type = unit_movement.Scouts
 		//log.Printf("scry: unit %q: origin %q: text %q: type %v\n", s.UnitId, s.Origin, s.Text, s.Type)
 		hack := fmt.Sprintf("Scout 1:%s", s.Text)
-		s.Scouts, err = ParseScoutMovementLine(fid, tid, unitId, lineNo, []byte(hack), acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit, experimentalScoutStill)
+		s.Scouts, err = ParseScoutMovementLine(logger, fid, tid, unitId, lineNo, []byte(hack), acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit, experimentalScoutStill)
 		if err != nil {
-			log.Printf("scry: %v\n", err)
+			logger.Error("parser: scry scouts", "err", err)
 		}
 	} else {
 		s.Type = unit_movement.Status
 		//log.Printf("scry: unit %q: origin %q: text %q: type %v\n", s.UnitId, s.Origin, s.Text, s.Type)
 		hack := fmt.Sprintf("%s %s", s.UnitId, s.Text)
-		s.Moves, err = ParseStatusLine(fid, tid, unitId, lineNo, []byte(hack), acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit)
+		s.Moves, err = ParseStatusLine(logger, fid, tid, unitId, lineNo, []byte(hack), acceptLoneDash, debugSteps, debugNodes, experimentalUnitSplit)
 	}
 
 	return s, err
 }
 
-func ParseStatusLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes bool, experimentalUnitSplit bool) ([]*Move_t, error) {
+func ParseStatusLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes bool, experimentalUnitSplit bool) ([]*Move_t, error) {
 	if debugSteps {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
+		logger.Debug("parser: status", "text", string(line))
 	}
 	if va, err := Parse(fid, line, Entrypoint("StatusLine")); err != nil {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, string(line))
-		log.Printf("status %v\n", err)
-
 		var column int
 		if fields := strings.Split(err.Error(), ":"); len(fields) > 2 && fields[0] == fid {
 			if columns := strings.Fields(fields[2]); len(columns) == 2 {
 				column, _ = strconv.Atoi(columns[0])
 			}
 		}
-		log.Printf("error: parsing status line\n")
-		log.Printf("error: file id  %q\n", fid)
-		log.Printf("error: line no  %d\n", lineNo)
-		if column != 0 {
-			log.Printf("error: near col %d\n", column)
-		}
-		log.Printf("error: input is %q\n", line)
+		logger.Error("parser: status", "text", string(line), "column", column, "err", err)
 		// is the line valid UTF-8?
 		wl := []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_.,()#:/ \t\r\n")
 		for n := 0; n < len(line); n++ {
 			ch := line[n]
 			if bytes.IndexByte(wl, ch) == -1 {
-				log.Printf("error: column %d: invalid character %q\n", n+1, string([]byte{ch}))
+				logger.Error("parser: status: invalid character", "column", n+1, "char", string([]byte{ch}))
 			}
 		}
 		return nil, err
 	} else if mt, ok := va.(Movement_t); !ok {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
-		log.Printf("error: want Movement_t, got %T\n", va)
-		log.Printf("please report this error\n")
+		logger.Error("parser: please report this error", "text", string(line), "want", "Movement_t", "got", fmt.Sprintf("%T", va))
 		panic(fmt.Errorf("unexpected type %T\n", va))
 	} else {
 		line = mt.Text
 	}
 	if debugSteps {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
+		logger.Debug("parser: status", "text", string(line))
 	}
 
 	// status lines have to be tagged since they are reported as scouting lines
-	moves, err := parseMovementLine(fid, tid, unitId, lineNo, line, false, acceptLoneDash, debugSteps, debugNodes, false, experimentalUnitSplit, false)
+	moves, err := parseMovementLine(logger, fid, tid, unitId, lineNo, line, false, acceptLoneDash, debugSteps, debugNodes, false, experimentalUnitSplit, false)
 	if len(moves) > 0 && moves[0].Result == results.Succeeded {
 		moves[0].Result = results.StatusLine
 		//log.Printf("status: %s: %s: %s: %d: %d: %q\n", fid, tid, unitId, lineNo, len(moves), string(line))
@@ -569,20 +596,18 @@ func ParseStatusLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte,
 	return moves, err
 }
 
-func ParseTribeFollowsLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, debug bool) (*Move_t, error) {
+func ParseTribeFollowsLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, debug bool) (*Move_t, error) {
 	var follows UnitId_t
 	if va, err := Parse(fid, line, Entrypoint("TribeFollows")); err != nil {
 		return nil, err
 	} else if mt, ok := va.(Movement_t); !ok {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
-		log.Printf("error: want Movement_t, got %T\n", va)
-		log.Printf("please report this error\n")
+		logger.Error("parser: please report this error", "text", string(line), "want", "Movement_t", "got", fmt.Sprintf("%T", va))
 		panic(fmt.Errorf("unexpected type %T\n", va))
 	} else {
 		follows = mt.Follows
 	}
 	if debug {
-		log.Printf("parser: %s: %s: %d: follows %q\n", fid, unitId, lineNo, follows)
+		logger.Debug("parser: follows", "follows", follows)
 	}
 
 	return &Move_t{
@@ -596,20 +621,18 @@ func ParseTribeFollowsLine(fid, tid string, unitId UnitId_t, lineNo int, line []
 	}, nil
 }
 
-func ParseTribeGoesToLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, debug bool) (*Move_t, error) {
+func ParseTribeGoesToLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, debug bool) (*Move_t, error) {
 	var goesTo string
 	if va, err := Parse(fid, line, Entrypoint("TribeGoesTo")); err != nil {
 		return nil, err
 	} else if mt, ok := va.(Movement_t); !ok {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
-		log.Printf("error: want Movement_t, got %T\n", va)
-		log.Printf("please report this error\n")
+		logger.Error("parser: please report this error", "text", string(line), "want", "Movement_t", "got", fmt.Sprintf("%T", va))
 		panic(fmt.Errorf("unexpected type %T\n", va))
 	} else {
 		goesTo = mt.GoesTo
 	}
 	if debug {
-		log.Printf("%s: %s: %d: goes to %q\n", fid, unitId, lineNo, goesTo)
+		logger.Debug("parser: goes to", "goes_to", goesTo)
 	}
 
 	return &Move_t{
@@ -623,19 +646,17 @@ func ParseTribeGoesToLine(fid, tid string, unitId UnitId_t, lineNo int, line []b
 	}, nil
 }
 
-func ParseTribeMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes bool, experimentalUnitSplit bool) ([]*Move_t, error) {
+func ParseTribeMovementLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, acceptLoneDash, debugSteps, debugNodes bool, experimentalUnitSplit bool) ([]*Move_t, error) {
 	if va, err := Parse(fid, line, Entrypoint("TribeMovement")); err != nil {
 		return nil, err
 	} else if mt, ok := va.(Movement_t); !ok {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
-		log.Printf("error: want Movement_t, got %T\n", va)
-		log.Printf("please report this error\n")
+		logger.Error("parser: please report this error", "text", string(line), "want", "Movement_t", "got", fmt.Sprintf("%T", va))
 		panic(fmt.Errorf("unexpected type %T\n", va))
 	} else {
 		line = mt.Text
 	}
 	if debugSteps {
-		log.Printf("%s: %s: %d: %q\n", fid, unitId, lineNo, line)
+		logger.Debug("parser: tribe movement", "text", string(line))
 	}
 
 	// remove the "Move" prefix from the line if it exists. if the line is actually the wagons error, then
@@ -648,7 +669,7 @@ func ParseTribeMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line [
 		return nil, fmt.Errorf("%d: Tribe Movement: expected 'Move', found '%s'", lineNo, slug(line, 8))
 	}
 
-	moves, err := parseMovementLine(fid, tid, unitId, lineNo, line, false, acceptLoneDash, debugSteps, debugNodes, false, experimentalUnitSplit, false)
+	moves, err := parseMovementLine(logger, fid, tid, unitId, lineNo, line, false, acceptLoneDash, debugSteps, debugNodes, false, experimentalUnitSplit, false)
 	if err != nil {
 		return nil, err
 	}
@@ -661,7 +682,7 @@ func ParseTribeMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line [
 
 // parseMovementLine parses all the moves on a single line.
 // it returns a slice containing the results for each move or an error.
-func parseMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte, isScout bool, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves bool, experimentalUnitSplit, scoutStill bool) ([]*Move_t, error) {
+func parseMovementLine(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo int, line []byte, isScout bool, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves bool, experimentalUnitSplit, scoutStill bool) ([]*Move_t, error) {
 	var moves []*Move_t
 
 	//doLog := bytes.Contains(line, []byte{'0', '9', '8', '7'}) || bytes.Contains(line, []byte(`Pass SW 0134`))
@@ -691,7 +712,7 @@ func parseMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte
 
 	for _, move := range splitMoves(fid, tid, unitId, lineNo, line) {
 		if debugSteps {
-			log.Printf("%s: %s: %d: step %d: %q\n", fid, unitId, lineNo, move.StepNo, move.Line)
+			logger.Debug("parser: move", "step", move.StepNo, "text", string(move.Line))
 		}
 		move.Debug.FleetMoves = debugFleetMoves
 
@@ -714,17 +735,17 @@ func parseMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte
 			// it does, so there must be observations of the outer ring, too
 			innerRing, outerRing, ok = bytes.Cut(innerRing, []byte{')', '('})
 			if !ok {
-				log.Printf("%s: %s: %d: step %d: iring %q\n", fid, unitId, lineNo, move.StepNo, innerRing)
+				logger.Error("parser: inner ring contains '-(' but not ')('", "step", move.StepNo, "text", string(innerRing))
 				return nil, fmt.Errorf("inner ring contains '-(' but not ')(")
 			}
 			// outer ring must end with a closing parentheses
 			if bytes.IndexByte(outerRing, ')') == -1 {
-				log.Printf("%s: %s: %d: step %d: oring %q\n", fid, unitId, lineNo, move.StepNo, outerRing)
+				logger.Error("parser: outer ring missing ')'", "step", move.StepNo, "text", string(outerRing))
 				return nil, fmt.Errorf("outer ring missing ')'")
 			}
 			// outer ring must end with a closing parentheses
 			if outerRing[len(outerRing)-1] != ')' {
-				log.Printf("%s: %s: %d: step %d: oring %q\n", fid, unitId, lineNo, move.StepNo, outerRing)
+				logger.Error("parser: outer ring contains text after ')'", "step", move.StepNo, "text", string(outerRing))
 				return nil, fmt.Errorf("outer ring contains text after ')'")
 			}
 			// remove that parentheses to make later processing simpler
@@ -741,10 +762,10 @@ func parseMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte
 		// because that is the move that we're returning.
 		if len(thisHex) != 0 {
 			if debugSteps {
-				log.Printf("%s: %s: %d: step %d: dirt %q\n", fid, unitId, lineNo, move.StepNo, slug(thisHex, 44))
+				logger.Debug("parser: dirt", "step", move.StepNo, "text", slug(thisHex, 44))
 			}
 
-			mt, err := parseMove(fid, tid, unitId, move.LineNo, move.StepNo, thisHex, isScout, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves, experimentalUnitSplit)
+			mt, err := parseMove(logger, fid, tid, unitId, move.LineNo, move.StepNo, thisHex, isScout, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves, experimentalUnitSplit)
 			if err != nil {
 				return nil, err
 			}
@@ -755,7 +776,7 @@ func parseMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte
 		// hexes, so each observation will update the border for this move.
 		if len(innerRing) != 0 {
 			if debugSteps {
-				log.Printf("%s: %s: %d: step %d: deck %q\n", fid, unitId, lineNo, move.StepNo, slug(innerRing, 44))
+				logger.Debug("parser: deck", "step", move.StepNo, "text", slug(innerRing, 44))
 			}
 
 			for no, obs := range bytes.Split(innerRing, []byte{','}) {
@@ -764,14 +785,10 @@ func parseMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte
 					continue
 				}
 				if va, err := Parse(fid, obs, Entrypoint("DeckObservation")); err != nil {
-					log.Printf("%s: %s: %d: step %d: deck %q\n", fid, unitId, lineNo, move.StepNo, slug(innerRing, 44))
-					log.Printf("%s: %s: %d: step %d: deck %d: obs %q\n", fid, unitId, lineNo, move.StepNo, no+1, obs)
+					logger.Error("parser: deck observation", "step", move.StepNo, "deck", no+1, "text", string(obs), "err", err)
 					return nil, err
 				} else if deckObservation, ok := va.(NearHorizon_t); !ok {
-					log.Printf("%s: %s: %d: step %d: deck %q\n", fid, unitId, lineNo, move.StepNo, slug(innerRing, 44))
-					log.Printf("%s: %s: %d: step %d: deck %d: obs %q\n", fid, unitId, lineNo, move.StepNo, no+1, obs)
-					log.Printf("error: want NearHorizon_t, got %T\n", va)
-					log.Printf("please report this error\n")
+					logger.Error("parser: please report this error", "step", move.StepNo, "deck", no+1, "text", string(obs), "want", "NearHorizon_t", "got", fmt.Sprintf("%T", va))
 					panic(fmt.Sprintf("unexpected type %T", va))
 				} else {
 					move.Report.MergeBorders(&Border_t{
@@ -787,7 +804,7 @@ func parseMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte
 		// these should only be "unknown land" and "unknown water" values.
 		if len(outerRing) != 0 {
 			if debugSteps {
-				log.Printf("%s: %s: %d: step %d: crow %q\n", fid, unitId, lineNo, move.StepNo, slug(outerRing, 44))
+				logger.Debug("parser: crow", "step", move.StepNo, "text", slug(outerRing, 44))
 			}
 
 			for nn, orStep := range bytes.Split(outerRing, []byte{','}) {
@@ -797,12 +814,10 @@ func parseMovementLine(fid, tid string, unitId UnitId_t, lineNo int, line []byte
 				}
 				crowNo := nn + 1
 				if va, err := Parse(fid, orStep, Entrypoint("CrowsNestObservation")); err != nil {
-					log.Printf("%s: %s: %d: step %d: crow %d: %q\n", fid, unitId, lineNo, move.StepNo, crowNo, orStep)
+					logger.Error("parser: crow's nest observation", "step", move.StepNo, "crow", crowNo, "text", string(orStep), "err", err)
 					return nil, err
 				} else if fh, ok := va.(FarHorizon_t); !ok {
-					log.Printf("%s: %s: %d: step %d: crow %d: %q\n", fid, unitId, lineNo, move.StepNo, crowNo, orStep)
-					log.Printf("error: want FarHorizon_t, got %T", va)
-					log.Printf("please report this error")
+					logger.Error("parser: please report this error", "step", move.StepNo, "crow", crowNo, "text", string(orStep), "want", "FarHorizon_t", "got", fmt.Sprintf("%T", va))
 					panic(fmt.Errorf("unexpected type %T", va))
 				} else {
 					move.Report.mergeFarHorizons(fh)
@@ -868,12 +883,12 @@ func errslug(text []byte, width int) string {
 }
 
 // parseMove parses a single step of a move, returning the results or an error
-func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte, isScout bool, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves bool, experimentalUnitSplit bool) (*Move_t, error) {
+func parseMove(logger *slog.Logger, fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte, isScout bool, acceptLoneDash, debugSteps, debugNodes, debugFleetMoves bool, experimentalUnitSplit bool) (*Move_t, error) {
 
 	//debugSteps, debugNodes = true, true
 	line = bytes.TrimSpace(bytes.TrimRight(line, ","))
 	if debugSteps {
-		log.Printf("%s: %s: %d: step %d: %q\n", fid, unitId, lineNo, stepNo, line)
+		logger.Debug("parser: step", "step", stepNo, "text", string(line))
 	}
 
 	m := &Move_t{UnitId: unitId, LineNo: lineNo, StepNo: stepNo, Line: line, Report: &Report_t{TurnId: tid, UnitId: unitId}}
@@ -882,10 +897,10 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 	// each move should find at most one settlement
 	var settlement *Settlement_t
 
-	root := hexReportToNodes(line, debugNodes, experimentalUnitSplit)
+	root := hexReportToNodes(logger, line, debugNodes, experimentalUnitSplit)
 	steps, err := nodesToSteps(root)
 	if err != nil {
-		log.Printf("parser: %s: %s: %d: step %d: %q\n", fid, unitId, lineNo, stepNo, line)
+		logger.Error("parser: step", "step", stepNo, "text", string(line), "err", err)
 		return nil, err
 	}
 
@@ -893,7 +908,7 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 	for n, subStep := range steps {
 		subStepNo := n + 1
 		if debugSteps {
-			log.Printf("parser: %s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+			logger.Debug("parser: sub-step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 		}
 
 		// check for scout being still
@@ -911,15 +926,13 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 						// ignore lone dashes
 						continue
 					}
-					log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
-					log.Printf("error: found lone dash on line; it must be removed\n")
+					logger.Error("parser: found lone dash on line; it must be removed", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 					return nil, fmt.Errorf("error parsing step")
 				}
 				if subStep[1] == '(' {
 					// probably a fleet movement result?
 				} else {
-					log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
-					log.Printf("error: found dash prefix on result; it must be removed\n")
+					logger.Error("parser: found dash prefix on result; it must be removed", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 					return nil, fmt.Errorf("error parsing step")
 				}
 			}
@@ -933,19 +946,19 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 				}
 			}
 			if err != nil {
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
-				log.Printf("error: %v\n", err)
+				logger.Error("parser: step", "step", stepNo, "sub", subStepNo, "text", string(subStep), "err", err)
 				return nil, fmt.Errorf("error parsing step")
 			}
 		}
 		switch v := obj.(type) {
 		case *BlockedByEdge_t:
 			if m.Result != results.Unknown { // only allowed at the beginning of the step
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: blocked by must start sub-step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("blocked by must start sub-step")
 			}
 			m.Advance = v.Direction
 			m.Result = results.Failed
+			m.FailPhrase = string(subStep)
 			m.Report.MergeBorders(&Border_t{
 				Direction: v.Direction,
 				Edge:      v.Edge,
@@ -954,7 +967,7 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 			// log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
 		case DirectionTerrain_t:
 			if m.Result != results.Unknown { // only allowed at the beginning of the step
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: multiple direction-terrain forbidden", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("multiple direction-terrain forbidden")
 			}
 			m.Advance = v.Direction
@@ -962,7 +975,7 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 			m.Report.Terrain = v.Terrain
 		case []*Edge_t:
 			if m.Result == results.Unknown {
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: edges forbidden at beginning of step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("edges forbidden at beginning of step")
 			}
 			for _, edge := range v {
@@ -973,16 +986,17 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 			}
 		case *Exhausted_t:
 			if m.Result != results.Unknown { // only allowed at the beginning of the step
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: exhaustion must start step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("exhaustion must start step")
 			}
 			m.Advance = v.Direction
 			m.Result = results.Failed
+			m.FailPhrase = string(subStep)
 			// fleet movements can end up exhausted in an unknown direction and with no terrain.
 			// if we were smart enough to look back at the wind direction, we could use that,
 			// but we're not, and we still wouldn't know what to do with the terrain.
 			if v.Direction == direction.Unknown && v.Terrain == terrain.Blank {
-				log.Printf("%s: %s: %d: step %d: sub %d: %q: fleet exhausted?\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Warn("parser: fleet exhausted?", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 			} else {
 				m.Report.MergeBorders(&Border_t{
 					Direction: v.Direction,
@@ -999,13 +1013,13 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 			// log.Printf("%s: %s: %d: step %d: sub %d: %q: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep, m.Result)
 		case FoundUnit_t:
 			if m.Result == results.Unknown {
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: units forbidden at beginning of step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return m, fmt.Errorf("units forbidden at beginning of step")
 			}
 			m.Report.MergeEncounters(&Encounter_t{TurnId: tid, UnitId: v.Id})
 		case []FoundUnit_t:
 			if m.Result == results.Unknown {
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: units forbidden at beginning of step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("units forbidden at beginning of step")
 			}
 			for _, unit := range v {
@@ -1014,12 +1028,14 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 		case InsufficientCapacity_t: // ignore
 			//log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
 			m.Result, m.Still = results.Failed, true
+			m.FailPhrase = string(subStep)
 		case Longhouse_t: // ignore
 		case MissingEdge_t:
 			m.Result, m.Still, m.Advance = results.Failed, true, v.Direction
+			m.FailPhrase = string(subStep)
 		case []*Neighbor_t:
 			if m.Result == results.Unknown {
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: neighbors forbidden at beginning of step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("neighbors forbidden at beginning of step")
 			}
 			for _, neighbor := range v {
@@ -1039,43 +1055,37 @@ func parseMove(fid, tid string, unitId UnitId_t, lineNo, stepNo int, line []byte
 			}
 		case *ProhibitedFrom_t:
 			if m.Result != results.Unknown { // only allowed at the beginning of the step
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: prohibition must start step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("prohibition must start step")
 			}
 			m.Advance = v.Direction
 			m.Result = results.Failed
+			m.FailPhrase = string(subStep)
 			m.Report.MergeBorders(&Border_t{
 				Direction: v.Direction,
 				Terrain:   v.Terrain,
 			})
 		case resources.Resource_e:
 			if m.Result == results.Unknown {
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: resources forbidden at beginning of step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("resources forbidden at beginning of step")
 			}
 			m.Report.MergeResources(v)
 		case *Settlement_t:
 			if m.Result == results.Unknown {
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: settlement forbidden at beginning of step", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("settlement forbidden at beginning of step")
 			}
 			m.Report.MergeSettlements(v)
 		case terrain.Terrain_e:
 			if m.Result != results.Unknown { // valid only at the beginning of the step for status line
-				log.Printf("%s: %s: %d: step %d: sub %d: %q\n", fid, unitId, lineNo, stepNo, subStepNo, subStep)
+				logger.Error("parser: terrain must start status", "step", stepNo, "sub", subStepNo, "text", string(subStep))
 				return nil, fmt.Errorf("terrain must start status")
 			}
 			m.Result, m.Still = results.Succeeded, true
 			m.Report.Terrain = v
 		default:
-			log.Printf("error: unexpected input while parsing movement\n")
-			log.Printf("error: turn %q\n", fid)
-			log.Printf("error: unit %q\n", unitId)
-			log.Printf("error: input: line %d\n", lineNo)
-			log.Printf("error: input: text %s\n", errslug(line, 58))
-			log.Printf("error: move: step %d: sub-step %d\n", stepNo, subStepNo)
-			log.Printf("error: found type %T\n", v)
-			log.Printf("please report this error\n")
+			logger.Error("parser: unexpected input while parsing movement: please report this error", "step", stepNo, "sub", subStepNo, "text", errslug(line, 58), "got", fmt.Sprintf("%T", v))
 			panic(fmt.Sprintf("unexpected %T", v))
 		}
 	}
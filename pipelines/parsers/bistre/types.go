@@ -25,17 +25,36 @@ type Turn_t struct {
 	Year  int
 	Month int
 
+	// Season and Weather are taken from the "Current Turn" line, e.g. "Winter" and "FINE".
+	Season  string
+	Weather string
+
 	// UnitMoves holds the units that moved in this turn
 	UnitMoves            map[UnitId_t]*Moves_t
 	SortedMoves          []*Moves_t
 	MovesSortedByElement []*Moves_t
 
+	// Duplicates holds the later sections for units that already have a
+	// section in UnitMoves, in the order they appear in the report.
+	// The adapters decide which section to keep.
+	Duplicates []*Moves_t
+
 	// SpecialNames holds the names of the hexes that are special.
 	// It's a hack to get around the fact that the parser doesn't know about the hexes.
 	// They are added to the map when parsing and are forced to lower case.
 	SpecialNames map[string]*Special_t
 
-	Next, Prev *Turn_t
+	Next, Prev *Turn_t `json:"-"`
+}
+
+// addMoves adds a unit's section to the turn. If the unit already has a
+// section, the new one is added to Duplicates instead.
+func (t *Turn_t) addMoves(moves *Moves_t) {
+	if _, ok := t.UnitMoves[moves.UnitId]; ok {
+		t.Duplicates = append(t.Duplicates, moves)
+		return
+	}
+	t.UnitMoves[moves.UnitId] = moves
 }
 
 func (t *Turn_t) FromMayBeObscured() bool {
@@ -99,6 +118,7 @@ func (t *Turn_t) SortMovesByElement() {
 type Moves_t struct {
 	TurnId string
 	UnitId UnitId_t // unit that is moving
+	LineNo int      // line of the unit's section header in the input
 
 	// all the moves made this turn
 	Moves   []*Move_t
@@ -139,6 +159,9 @@ type Move_t struct {
 	// Result should be failed, succeeded, or vanished
 	Result results.Result_e
 
+	// FailPhrase is the GM's text for why the step failed, e.g. "No Ford on River to N of HEX".
+	FailPhrase string
+
 	Report *Report_t // all observations made by the unit at the end of this move
 
 	LineNo int
@@ -151,12 +174,12 @@ type Move_t struct {
 	FromCoordinates coords.WorldMapCoord // the tile the unit starts the move in
 	ToCoordinates   coords.WorldMapCoord // the tile the unit ends the move in
 
-	// Debug settings
+	// Debug settings; they link moves together, so they aren't serialized
 	Debug struct {
 		FleetMoves bool
 		PriorMove  *Move_t
 		NextMove   *Move_t
-	}
+	} `json:"-"`
 }
 
 // Report_t represents the observations made by a unit.
@@ -403,21 +426,33 @@ type Special_t struct {
 type UnitId_t string
 
 func (u UnitId_t) InClan(clan UnitId_t) bool {
-	if len(u) != 4 {
+	if len(u) != u.tribeLen() {
 		return u.Parent().Parent() == clan
 	}
 	return u.Parent() == clan
 }
 
 func (u UnitId_t) IsFleet() bool {
-	return len(u) == 6 && u[4] == 'f'
+	n := u.tribeLen()
+	return len(u) == n+2 && u[n] == 'f'
 }
 
 func (u UnitId_t) Parent() UnitId_t {
-	if len(u) == 4 {
+	n := u.tribeLen()
+	if len(u) == n {
 		return "0" + u[1:]
 	}
-	return u[:4]
+	return u[:n]
+}
+
+// tribeLen returns the length of the tribe part of the ID, its leading
+// digits: 4 in most games, 5 in games with 5-digit clans.
+func (u UnitId_t) tribeLen() int {
+	n := 0
+	for n < len(u) && '0' <= u[n] && u[n] <= '9' {
+		n++
+	}
+	return n
 }
 
 func (u UnitId_t) String() string {
@@ -6,6 +6,7 @@ package docx
 
 import (
 	"archive/zip"
+	"bufio"
 	"bytes"
 	"encoding/xml"
 	"errors"
@@ -13,8 +14,15 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
+
+	"github.com/maloquacious/semver"
 )
 
+// Version is the version of the extracted text. Bump it when a change to the
+// extractor changes the Text it returns for the same file, so that cached
+// parse results (see the pipeline's parse stage) aren't reused.
+var Version = semver.Version{Major: 1}
+
 type Docx struct {
 	Source string
 	Text   []byte
@@ -52,16 +60,60 @@ func parseReader(r *bytes.Reader) ([]byte, error) {
 				return nil, errors.Join(ErrBadInput, err)
 			}
 			defer rc.Close()
-			text, err := translateWordXML(rc)
-			if err != nil {
+			buf := &bytes.Buffer{}
+			if err := translateWordXML(buf, rc); err != nil {
 				return nil, errors.Join(ErrBadInput, err)
 			}
-			return text, nil
+			return buf.Bytes(), nil
 		}
 	}
 	return nil, errors.Join(ErrNotAWordDocument, ErrWordXmlDocumentNotFound)
 }
 
+// WriteText extracts the text of a .docx file like ParseReader, but writes
+// it to w as it is extracted instead of returning it, for documents too big
+// to hold in memory twice over (a GM's combined file, say).
+//
+// Besides the zip directory, it holds the decompressor's 32KB window, the
+// XML token being decoded, a 4KB output buffer, and, when trimming, the
+// current line of text; not the document or its text. r is read at random,
+// so it should be a file rather than a bytes.Reader of the whole document
+// to get the benefit.
+//
+// Errors in the document wrap ErrNotAWordDocument or ErrBadInput; errors
+// from w are returned as they are.
+func WriteText(w io.Writer, r io.ReaderAt, size int64, trimLeading, trimTrailing bool) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return errors.Join(ErrNotAWordDocument, ErrorUncompressFailed, err)
+	}
+	for _, file := range zr.File {
+		if file.Name != "word/document.xml" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return errors.Join(ErrBadInput, err)
+		}
+		defer rc.Close()
+		bw := bufio.NewWriter(w)
+		var tw textWriter = bw
+		var lt *lineTrimmer
+		if trimLeading || trimTrailing {
+			lt = &lineTrimmer{w: bw, leading: trimLeading, trailing: trimTrailing}
+			tw = lt
+		}
+		if err := translateWordXML(tw, rc); err != nil {
+			return errors.Join(ErrBadInput, err)
+		}
+		if lt != nil {
+			lt.endLine(false)
+		}
+		return bw.Flush() // bufio keeps the first write error
+	}
+	return errors.Join(ErrNotAWordDocument, ErrWordXmlDocumentNotFound)
+}
+
 // parsePath is a helper function. It opens and parses a .docx file. Returns
 // the body text with whitespace preserved from <w:t xml:space="preserve">
 // plus tabs and line breaks.  Injects a line-feed at the end of every paragraph.
@@ -78,22 +130,28 @@ func parsePath(path string) ([]byte, error) {
 				return nil, fmt.Errorf("open document.xml: %w", err)
 			}
 			defer rc.Close()
-			text, err := translateWordXML(rc)
-			if err != nil {
+			buf := &bytes.Buffer{}
+			if err := translateWordXML(buf, rc); err != nil {
 				return nil, err
 			}
-			return text, nil
+			return buf.Bytes(), nil
 		}
 	}
 	return nil, errors.New("word/document.xml not found")
 }
 
-// translateWordXML actually walks the XML and builds the text.
-func translateWordXML(r io.Reader) ([]byte, error) {
+// textWriter is what translateWordXML writes the text to.
+type textWriter interface {
+	io.ByteWriter
+	io.StringWriter
+}
+
+// translateWordXML actually walks the XML and writes the text to buf.
+// Errors writing to buf are left for the caller to find.
+func translateWordXML(buf textWriter, r io.Reader) error {
 	dec := xml.NewDecoder(r)
 
 	var (
-		buf      = &bytes.Buffer{}
 		preserve = false // xml:space="preserve" on current <w:t>
 		inT      = false // we're inside a <w:t>
 
@@ -108,7 +166,7 @@ func translateWordXML(r io.Reader) ([]byte, error) {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("xml decode: %w", err)
+			return fmt.Errorf("xml decode: %w", err)
 		}
 
 		switch se := tok.(type) {
@@ -167,29 +225,61 @@ func translateWordXML(r io.Reader) ([]byte, error) {
 		}
 	}
 
-	return buf.Bytes(), nil
+	return nil
 }
 
 func trimOptions(text []byte, trimLeading, trimTrailing bool) []byte {
 	if trimLeading == false && trimTrailing == false {
 		return text
 	}
-	const asciiSpace = " \t\n\v\f\r"
 	lines := bytes.Split(text, []byte{'\n'})
+	for i, line := range lines {
+		lines[i] = trimLine(line, trimLeading, trimTrailing)
+	}
+	return bytes.Join(lines, []byte{'\n'})
+}
+
+func trimLine(line []byte, trimLeading, trimTrailing bool) []byte {
+	const asciiSpace = " \t\n\v\f\r"
 	if trimLeading && trimTrailing {
-		for i, line := range lines {
-			lines[i] = bytes.TrimSpace(line)
-		}
+		return bytes.TrimSpace(line)
 	} else if trimLeading {
-		for i, line := range lines {
-			lines[i] = bytes.TrimLeft(line, asciiSpace)
-		}
+		return bytes.TrimLeft(line, asciiSpace)
+	}
+	return bytes.TrimRight(line, asciiSpace)
+}
+
+// lineTrimmer trims the text written to it line by line, the way
+// trimOptions does, holding only the current line.
+type lineTrimmer struct {
+	w                 *bufio.Writer
+	line              []byte
+	leading, trailing bool
+}
+
+func (t *lineTrimmer) WriteByte(c byte) error {
+	if c == '\n' {
+		t.endLine(true)
 	} else {
-		for i, line := range lines {
-			lines[i] = bytes.TrimRight(line, asciiSpace)
-		}
+		t.line = append(t.line, c)
 	}
-	return bytes.Join(lines, []byte{'\n'})
+	return nil
+}
+
+func (t *lineTrimmer) WriteString(s string) (int, error) {
+	for i := 0; i < len(s); i++ {
+		t.WriteByte(s[i])
+	}
+	return len(s), nil
+}
+
+// endLine writes the trimmed line, with a line-feed if newline is set.
+func (t *lineTrimmer) endLine(newline bool) {
+	t.w.Write(trimLine(t.line, t.leading, t.trailing))
+	if newline {
+		t.w.WriteByte('\n')
+	}
+	t.line = t.line[:0]
 }
 
 type Error string
@@ -0,0 +1,37 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package docx_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+)
+
+func TestWriteText(t *testing.T) {
+	data, err := os.ReadFile("../../../testdata/0301.0899-12.0987.docx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trim := range []struct{ leading, trailing bool }{{false, false}, {true, false}, {false, true}, {true, true}} {
+		want, err := docx.ParseReader(bytes.NewReader(data), trim.leading, trim.trailing, true, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got bytes.Buffer
+		if err := docx.WriteText(&got, bytes.NewReader(data), int64(len(data)), trim.leading, trim.trailing); err != nil {
+			t.Fatalf("trim %+v: %v", trim, err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Text) {
+			t.Errorf("trim %+v: streamed text differs from ParseReader's (%d bytes, want %d)", trim, got.Len(), len(want.Text))
+		}
+	}
+
+	err = docx.WriteText(&bytes.Buffer{}, bytes.NewReader([]byte("not a zip")), 9, true, true)
+	if !errors.Is(err, docx.ErrNotAWordDocument) {
+		t.Errorf("not a docx: got %v, want ErrNotAWordDocument", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"regexp"
+)
+
+// EmailWidth is the column that mail programs usually wrap plain text at.
+const EmailWidth = 72
+
+// FromEmail undoes what mail programs do to a turn report sent as the body
+// of an email, so that the splitter sees one report line per line:
+//
+//   - quoted-printable is decoded, which also joins the lines it broke
+//     with a soft line break ("=" at the end of the line)
+//   - quote prefixes ("> " and "> > ") are stripped
+//   - lines the mail program wrapped at width are joined again
+//
+// A line is taken to be the wrapped end of the line before it when it doesn't
+// start a report line (a unit header, turn, movement, scout, scry, or status
+// line) and the line before it was too long for the line's first word to fit
+// within width. A width of 0 or less means EmailWidth.
+func FromEmail(text []byte, width int) ([]byte, error) {
+	if width <= 0 {
+		width = EmailWidth
+	}
+
+	text = bytes.ReplaceAll(text, []byte{CR, LF}, []byte{LF})
+	decoded, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(text)))
+	if err != nil {
+		return nil, fmt.Errorf("email: quoted-printable: %w", err)
+	}
+
+	var lines [][]byte
+	for _, line := range bytes.Split(decoded, []byte{LF}) {
+		line = stripQuotePrefix(line)
+		if n := len(lines); n != 0 && isWrapped(lines[n-1], line, width) {
+			prev := bytes.TrimRight(lines[n-1], " \t")
+			lines[n-1] = append(append(prev, ' '), bytes.TrimLeft(line, " \t")...)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return bytes.Join(lines, []byte{LF}), nil
+}
+
+// stripQuotePrefix removes the ">" markers that mail programs put in front
+// of quoted (forwarded or replied-to) lines, along with the space after each.
+func stripQuotePrefix(line []byte) []byte {
+	for len(line) != 0 && line[0] == '>' {
+		line = line[1:]
+		if len(line) != 0 && line[0] == ' ' {
+			line = line[1:]
+		}
+	}
+	return line
+}
+
+// isWrapped returns true if line looks like the part of prev that the mail
+// program moved to a new line because it didn't fit within width.
+func isWrapped(prev, line []byte, width int) bool {
+	prev = bytes.TrimRight(prev, " \t")
+	line = bytes.TrimLeft(line, " \t")
+	if len(prev) == 0 || len(line) == 0 || startsReportLine(line) {
+		return false
+	}
+	word := line
+	if i := bytes.IndexAny(line, " \t"); i != -1 {
+		word = line[:i]
+	}
+	return len(prev)+1+len(word) > width
+}
+
+// startsReportLine returns true if the line is one the splitter keeps.
+func startsReportLine(line []byte) bool {
+	if reCurrentTurn.Match(line) || isUnitLine(line) {
+		return true
+	}
+	for _, re := range []*regexp.Regexp{
+		reClanSection, reCourierSection, reElementSection, reFleetSection, reGarrisonSection, reTribeSection,
+	} {
+		if re.Match(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/pipelines/parsers/report"
+)
+
+func TestFromEmail(t *testing.T) {
+	// a report forwarded as a quoted, quoted-printable email body wrapped at 72
+	email := strings.Join([]string{
+		"> Tribe 0987, , Current Hex =3D QQ 1010, (Previous Hex =3D QQ 1010)",
+		"> Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/20=",
+		"25",
+		"> Tribe Movement: Move NE-PR, River S, \\NE-GH, River S, \\NE-PR, River SE,",
+		"> \\N-PR",
+		"> ",
+		"> Humans",
+		"> 0987 Status: PRAIRIE, River S, 0987",
+	}, "\r\n")
+	want := strings.Join([]string{
+		"Tribe 0987, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)",
+		"Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025",
+		"Tribe Movement: Move NE-PR, River S, \\NE-GH, River S, \\NE-PR, River SE, \\N-PR",
+		"",
+		"Humans",
+		"0987 Status: PRAIRIE, River S, 0987",
+	}, "\n")
+
+	text, err := report.FromEmail([]byte(email), 0)
+	if err != nil {
+		t.Fatalf("FromEmail: %v", err)
+	}
+	if got := string(text); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	rpt, err := report.ParseReportText(&docx.Docx{Source: "0987.txt", Text: text}, true, true, true, false, false)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	if len(rpt.Sections) != 1 || len(rpt.Sections[0].Lines) != 4 {
+		t.Fatalf("expected one section of 4 lines, got %+v", rpt.Sections)
+	}
+}
+
+func TestFromEmail_ShortLinesAreKept(t *testing.T) {
+	// "Humans" would have fit on the line before it, so it wasn't wrapped
+	text := "Tribe Movement: Move NE-PR\nHumans 100\n"
+	got, err := report.FromEmail([]byte(text), 0)
+	if err != nil {
+		t.Fatalf("FromEmail: %v", err)
+	}
+	if string(got) != text {
+		t.Errorf("got %q, want %q", got, text)
+	}
+}
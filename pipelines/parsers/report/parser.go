@@ -11,9 +11,16 @@ import (
 	"path/filepath"
 	"regexp"
 
+	"github.com/maloquacious/semver"
+	"github.com/mdhender/tnrpt/model"
 	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
 )
 
+// Version is the version of the splitter's output. Bump it when a change to
+// the splitter changes the Sections it returns for the same text, so that
+// cached parse results (see the pipeline's parse stage) aren't reused.
+var Version = semver.Version{Major: 1}
+
 func ParseReportText(d *docx.Docx, normalizeCRLF, normalizeCR, quiet, verbose, debug bool) (Report, error) {
 	r := Report{
 		Path: filepath.Dir(d.Source),
@@ -34,70 +41,65 @@ func ParseReportText(d *docx.Docx, normalizeCRLF, normalizeCR, quiet, verbose, d
 		text = bytes.ReplaceAll(text, []byte{CR}, []byte{LF})
 	}
 
+	// A unit header always starts a new section, so the splitter recovers at
+	// the next header when a section is cut short (for example, by a courier
+	// section interleaved with its parent's). Unit lines found outside of a
+	// section are skipped and reported as warnings.
 	var section *Section
-	for _, line := range bytes.Split(text, []byte{LF}) {
+	var skipped [][]byte
+	var skippedLineNo int
+	var orphanTurnLine []byte
+	closeSkipped := func() {
+		if len(skipped) != 0 {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("line %d: skipped %d line(s) outside of a unit section: %q", skippedLineNo, len(skipped), slug(skipped[0], 40)))
+			skipped = nil
+		}
+	}
+	openSection := func(unitId, kind string, line []byte) {
+		closeSkipped()
+		if section != nil {
+			r.Sections = append(r.Sections, section)
+		}
+		section = &Section{
+			UnitId: unitId,
+			Kind:   kind,
+			Lines:  [][]byte{line},
+		}
+	}
+	for n, line := range bytes.Split(text, []byte{LF}) {
 		if idx := reClanSection.FindSubmatchIndex(line); idx != nil {
-			if section != nil {
-				r.Sections = append(r.Sections, section)
-			}
-			section = &Section{
-				UnitId: string(line[idx[2]:idx[3]]),
-				Kind:   "clan",
-				Lines:  [][]byte{line},
-			}
+			openSection(string(line[idx[2]:idx[3]]), "clan", line)
 			continue
 		} else if idx = reCourierSection.FindSubmatchIndex(line); idx != nil {
-			if section != nil {
-				r.Sections = append(r.Sections, section)
-			}
-			section = &Section{
-				UnitId: string(line[idx[2]:idx[3]]),
-				Kind:   "courier",
-				Lines:  [][]byte{line},
-			}
+			openSection(string(line[idx[2]:idx[3]]), "courier", line)
 			continue
 		} else if idx = reElementSection.FindSubmatchIndex(line); idx != nil {
-			if section != nil {
-				r.Sections = append(r.Sections, section)
-			}
-			section = &Section{
-				UnitId: string(line[idx[2]:idx[3]]),
-				Kind:   "element",
-				Lines:  [][]byte{line},
-			}
+			openSection(string(line[idx[2]:idx[3]]), "element", line)
 			continue
 		} else if idx = reFleetSection.FindSubmatchIndex(line); idx != nil {
-			if section != nil {
-				r.Sections = append(r.Sections, section)
-			}
-			section = &Section{
-				UnitId: string(line[idx[2]:idx[3]]),
-				Kind:   "fleet",
-				Lines:  [][]byte{line},
-			}
+			openSection(string(line[idx[2]:idx[3]]), "fleet", line)
 			continue
 		} else if idx = reGarrisonSection.FindSubmatchIndex(line); idx != nil {
-			if section != nil {
-				r.Sections = append(r.Sections, section)
-			}
-			section = &Section{
-				UnitId: string(line[idx[2]:idx[3]]),
-				Kind:   "garrison",
-				Lines:  [][]byte{line},
-			}
+			openSection(string(line[idx[2]:idx[3]]), "garrison", line)
 			continue
 		} else if idx = reTribeSection.FindSubmatchIndex(line); idx != nil {
-			if section != nil {
-				r.Sections = append(r.Sections, section)
-			}
-			section = &Section{
-				UnitId: string(line[idx[2]:idx[3]]),
-				Kind:   "tribe",
-				Lines:  [][]byte{line},
-			}
+			openSection(string(line[idx[2]:idx[3]]), "tribe", line)
+			continue
 		}
 
 		if section == nil {
+			if reCurrentTurn.Match(line) {
+				// a turn line ahead of its unit header; keep it in case
+				// no section has one of its own
+				if orphanTurnLine == nil {
+					orphanTurnLine = line
+				}
+			} else if isUnitLine(line) {
+				if skipped == nil {
+					skippedLineNo = n + 1
+				}
+				skipped = append(skipped, line)
+			}
 			continue
 		}
 
@@ -166,6 +168,7 @@ func ParseReportText(d *docx.Docx, normalizeCRLF, normalizeCR, quiet, verbose, d
 			section = nil
 		}
 	}
+	closeSkipped()
 	if section != nil {
 		r.Sections = append(r.Sections, section)
 	}
@@ -174,23 +177,69 @@ func ParseReportText(d *docx.Docx, normalizeCRLF, normalizeCR, quiet, verbose, d
 		return Report{}, fmt.Errorf("invalid report: no sections")
 	}
 
+	var turnLine []byte
 	for _, section := range r.Sections {
-		if r.TurnNo == "" {
+		if section.TurnNo == "" {
+			continue
+		} else if r.TurnNo == "" {
 			r.TurnNo = section.TurnNo
+			for _, line := range section.Lines {
+				if reCurrentTurn.Match(line) {
+					turnLine = line
+					break
+				}
+			}
 			continue
 		}
 		if r.TurnNo != section.TurnNo {
 			return Report{}, fmt.Errorf("invalid report: multiple turns")
 		}
 	}
+	if r.TurnNo == "" && orphanTurnLine != nil {
+		idx := reCurrentTurn.FindSubmatchIndex(orphanTurnLine)
+		r.TurnNo, turnLine = string(orphanTurnLine[idx[2]:idx[3]]), orphanTurnLine
+	}
 
 	if r.TurnNo == "" {
 		return Report{}, fmt.Errorf("invalid report: no turn info")
 	}
 
+	// GM outputs sometimes drop the turn line on later pages. Those sections
+	// get the report's turn line so that the parser sees a complete section.
+	for _, section := range r.Sections {
+		if section.TurnNo != "" {
+			continue
+		}
+		r.Warnings = append(r.Warnings, fmt.Sprintf("%s %s: no turn line, using turn %s", section.Kind, section.UnitId, r.TurnNo))
+		section.TurnNo = r.TurnNo
+		section.Lines = append([][]byte{section.Lines[0], turnLine}, section.Lines[1:]...)
+	}
+
 	return r, nil
 }
 
+// isUnitLine returns true if the line belongs in a unit section.
+func isUnitLine(line []byte) bool {
+	for _, re := range []*regexp.Regexp{
+		reClanScry, reCourierScry, reElementScry, reFleetScry, reGarrisonScry, reTribeScry,
+		reFleetMovement, reTribeFollows, reTribeGoesTo, reTribeMovement, reScout,
+		reClanStatus, reCourierStatus, reElementStatus, reFleetStatus, reGarrisonStatus, reTribeStatus,
+	} {
+		if re.Match(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// slug returns the first n bytes of the line.
+func slug(line []byte, n int) []byte {
+	if len(line) < n {
+		return line
+	}
+	return line[:n]
+}
+
 const (
 	CR = 0x0d // carriage return
 	LF = 0x0a // line feed
@@ -198,23 +247,23 @@ const (
 
 var (
 	// Tribe 0987, , Current Hex = QQ 0909, (Previous Hex = QQ 1010)
-	reClanSection     = regexp.MustCompile(`^Tribe\s(0\d{3}),`)
-	reCourierSection  = regexp.MustCompile(`^Courier\s(\d{4}c[1-9]),`)
-	reElementSection  = regexp.MustCompile(`^Element\s(\d{4}e[1-9]),`)
-	reFleetSection    = regexp.MustCompile(`^Fleet\s(\d{4}f[1-9]),`)
-	reGarrisonSection = regexp.MustCompile(`^Garrison\s(\d{4}g[1-9]),`)
-	reTribeSection    = regexp.MustCompile(`^Tribe\s(\d{4}),`)
+	reClanSection     = regexp.MustCompile(`^Tribe\s(` + model.ClanPattern + `),`)
+	reCourierSection  = regexp.MustCompile(`^Courier\s(` + model.TribePattern + `c[1-9]),`)
+	reElementSection  = regexp.MustCompile(`^Element\s(` + model.TribePattern + `e[1-9]),`)
+	reFleetSection    = regexp.MustCompile(`^Fleet\s(` + model.TribePattern + `f[1-9]),`)
+	reGarrisonSection = regexp.MustCompile(`^Garrison\s(` + model.TribePattern + `g[1-9]),`)
+	reTribeSection    = regexp.MustCompile(`^Tribe\s(` + model.TribePattern + `),`)
 
 	// Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025
 	reCurrentTurn = regexp.MustCompile(`^Current\sTurn\s(\d{3,4}-\d{2})\s\(#\d+\),`)
 
 	// 0987 Scry: QQ 1010:
-	reClanScry     = regexp.MustCompile(`^0\d{3}\sScry:`)
-	reCourierScry  = regexp.MustCompile(`^\d{4}c[1-9]\sScry:`)
-	reElementScry  = regexp.MustCompile(`^\d{4}e[1-9]\sScry:`)
-	reFleetScry    = regexp.MustCompile(`^\d{4}f[1-9]\sScry:`)
-	reGarrisonScry = regexp.MustCompile(`^\d{4}g[1-9]\sScry:`)
-	reTribeScry    = regexp.MustCompile(`^\d{4}\sScry:`)
+	reClanScry     = regexp.MustCompile(`^` + model.ClanPattern + `\sScry:`)
+	reCourierScry  = regexp.MustCompile(`^` + model.TribePattern + `c[1-9]\sScry:`)
+	reElementScry  = regexp.MustCompile(`^` + model.TribePattern + `e[1-9]\sScry:`)
+	reFleetScry    = regexp.MustCompile(`^` + model.TribePattern + `f[1-9]\sScry:`)
+	reGarrisonScry = regexp.MustCompile(`^` + model.TribePattern + `g[1-9]\sScry:`)
+	reTribeScry    = regexp.MustCompile(`^` + model.TribePattern + `\sScry:`)
 
 	// CALM NE Fleet Movement:
 	reFleetMovement = regexp.MustCompile(`^(CALM|MILD|STRONG|GALE)\s[NS][EW]?\sFleet\sMovement:`)
@@ -232,12 +281,12 @@ var (
 	reScout = regexp.MustCompile(`^Scout\s\d:Scout`)
 
 	// 0987 Status:
-	reClanStatus     = regexp.MustCompile(`^0\d{3}\sStatus:`)
-	reCourierStatus  = regexp.MustCompile(`^\d{4}c[1-9]\sStatus:`)
-	reElementStatus  = regexp.MustCompile(`^\d{4}e[1-9]\sStatus:`)
-	reFleetStatus    = regexp.MustCompile(`^\d{4}f[1-9]\sStatus:`)
-	reGarrisonStatus = regexp.MustCompile(`^\d{4}g[1-9]\sStatus:`)
-	reTribeStatus    = regexp.MustCompile(`^\d{4}\sStatus:`)
+	reClanStatus     = regexp.MustCompile(`^` + model.ClanPattern + `\sStatus:`)
+	reCourierStatus  = regexp.MustCompile(`^` + model.TribePattern + `c[1-9]\sStatus:`)
+	reElementStatus  = regexp.MustCompile(`^` + model.TribePattern + `e[1-9]\sStatus:`)
+	reFleetStatus    = regexp.MustCompile(`^` + model.TribePattern + `f[1-9]\sStatus:`)
+	reGarrisonStatus = regexp.MustCompile(`^` + model.TribePattern + `g[1-9]\sStatus:`)
+	reTribeStatus    = regexp.MustCompile(`^` + model.TribePattern + `\sStatus:`)
 )
 
 type Report struct {
@@ -245,6 +294,7 @@ type Report struct {
 	Name     string
 	TurnNo   string
 	Sections []*Section
+	Warnings []string // problems the splitter recovered from
 }
 
 type Section struct {
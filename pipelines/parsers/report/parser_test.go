@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/pipelines/parsers/report"
+)
+
+func TestParseReportText_Recovers(t *testing.T) {
+	text := strings.Join([]string{
+		"Tribe 0987, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)",
+		"Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025",
+		"Tribe Movement: Move NE-PR",
+		// a courier section interleaved with its parent's
+		"Courier 0987c1, , Current Hex = QQ 1011, (Previous Hex = QQ 1011)",
+		"Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025",
+		"0987c1 Status: PRAIRIE, 0987c1",
+		// the rest of the tribe's section
+		"Scout 1:Scout N-PR",
+		"0987 Status: PRAIRIE, 0987",
+		// a later page without its turn line
+		"Element 0987e1, , Current Hex = QQ 1012, (Previous Hex = QQ 1012)",
+		"0987e1 Status: PRAIRIE, 0987e1",
+	}, "\n")
+	rpt, err := report.ParseReportText(&docx.Docx{Source: "0987.docx", Text: []byte(text)}, true, true, true, false, false)
+	if err != nil {
+		t.Fatalf("expected the splitter to recover, got %v", err)
+	}
+	if rpt.TurnNo != "899-12" {
+		t.Errorf("turn: expected %q, got %q", "899-12", rpt.TurnNo)
+	}
+
+	var units []string
+	for _, section := range rpt.Sections {
+		units = append(units, section.UnitId)
+	}
+	if got := strings.Join(units, ","); got != "0987,0987c1,0987e1" {
+		t.Fatalf("sections: expected %q, got %q", "0987,0987c1,0987e1", got)
+	}
+	element := rpt.Sections[2]
+	if element.TurnNo != "899-12" || len(element.Lines) != 3 || !strings.HasPrefix(string(element.Lines[1]), "Current Turn 899-12") {
+		t.Errorf("element: expected the report's turn line, got %q %q", element.TurnNo, element.Lines)
+	}
+
+	if len(rpt.Warnings) != 2 {
+		t.Fatalf("warnings: expected 2, got %q", rpt.Warnings)
+	}
+	if !strings.HasPrefix(rpt.Warnings[0], "line 7: skipped 2 line(s)") {
+		t.Errorf("warnings: expected the skipped block, got %q", rpt.Warnings[0])
+	}
+	if !strings.Contains(rpt.Warnings[1], "element 0987e1: no turn line") {
+		t.Errorf("warnings: expected the missing turn line, got %q", rpt.Warnings[1])
+	}
+}
+
+func TestParseReportText_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name, text, want string
+	}{
+		{"no sections", "Scout 1:Scout N-PR", "no sections"},
+		{"no turn", "Tribe 0987, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)\n0987 Status: PRAIRIE, 0987", "no turn info"},
+		{"multiple turns", strings.Join([]string{
+			"Tribe 0987, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)",
+			"Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025",
+			"0987 Status: PRAIRIE, 0987",
+			"Element 0987e1, , Current Hex = QQ 1012, (Previous Hex = QQ 1012)",
+			"Current Turn 900-01 (#1), Spring, FINE Next Turn 900-02 (#2), 5/12/2025",
+			"0987e1 Status: PRAIRIE, 0987e1",
+		}, "\n"), "multiple turns"},
+	} {
+		_, err := report.ParseReportText(&docx.Docx{Source: "0987.docx", Text: []byte(tc.text)}, true, true, true, false, false)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected %q, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestReport_UnitFiles(t *testing.T) {
+	text := strings.Join([]string{
+		"Tribe 0987, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)",
+		"Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025",
+		"Tribe Movement: Move NE-PR",
+		"Courier 0987c1, , Current Hex = QQ 1011, (Previous Hex = QQ 1011)",
+		"Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025",
+		"0987c1 Status: PRAIRIE, 0987c1",
+		// the tribe's section continued on a later page
+		"Tribe 0987, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)",
+		"Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025",
+		"0987 Status: PRAIRIE, 0987",
+	}, "\n")
+	rpt, err := report.ParseReportText(&docx.Docx{Source: "0987.docx", Text: []byte(text)}, true, true, true, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := rpt.UnitFiles()
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "0899-12.0987.report.txt,0899-12.0987c1.report.txt" {
+		t.Fatalf("names: got %q", got)
+	}
+	if files[0].Kind != "clan" || strings.Count(string(files[0].Text), "Current Turn") != 2 || !strings.HasSuffix(string(files[0].Text), "0987 Status: PRAIRIE, 0987\n") {
+		t.Errorf("clan: got %s %q, want both of its sections", files[0].Kind, files[0].Text)
+	}
+	if files[1].Kind != "courier" || strings.Count(string(files[1].Text), "\n") != 3 {
+		t.Errorf("courier: got %s %q, want its 3 lines", files[1].Kind, files[1].Text)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// UnitFile is a report's sections for one unit, as a file of its own.
+type UnitFile struct {
+	UnitId string
+	Kind   string
+	Name   string // e.g., "0900-01.0987e1.report.txt"
+	Text   []byte
+}
+
+// UnitFiles groups the report's sections by unit, in the order the units
+// first appear. A unit whose section was cut short by another's gets all of
+// its sections in one file. Each file is named for the turn and unit the way
+// report files are, so that it can be parsed or uploaded on its own.
+func (r Report) UnitFiles() []UnitFile {
+	turn := r.TurnNo
+	if turnNo, err := model.ParseTurnNo(r.TurnNo); err == nil {
+		turn = turnNo.String()
+	}
+
+	var files []UnitFile
+	index := map[string]int{}
+	for _, section := range r.Sections {
+		i, ok := index[section.UnitId]
+		if !ok {
+			i = len(files)
+			index[section.UnitId] = i
+			files = append(files, UnitFile{
+				UnitId: section.UnitId,
+				Kind:   section.Kind,
+				Name:   fmt.Sprintf("%s.%s.report.txt", turn, section.UnitId),
+			})
+		}
+		files[i].Text = append(files[i].Text, bytes.Join(section.Lines, []byte{LF})...)
+		files[i].Text = append(files[i].Text, LF)
+	}
+	return files
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/spf13/afero"
+)
+
+// ArchiveDir is the directory, relative to the data directory, that holds
+// archived report files. An archived file keeps its original relative path
+// with a ".gz" suffix, e.g. "batches/1/0512.docx" -> "archive/batches/1/0512.docx.gz".
+const ArchiveDir = "archive"
+
+// ArchiveService moves old report file blobs into compressed storage and back.
+// Only the original upload is archived; extracted rows in the database are kept.
+type ArchiveService struct {
+	store   ArchiveStore
+	dataDir string
+	fs      afero.Fs
+}
+
+// ArchiveStore defines the store operations needed by ArchiveService.
+type ArchiveStore interface {
+	GetReportFilesWithPath(ctx context.Context) ([]*model.ReportFile, error)
+	UpdateReportFilePath(ctx context.Context, id int64, fsPath string) error
+}
+
+// NewArchiveService creates a new ArchiveService.
+func NewArchiveService(store ArchiveStore, dataDir string) *ArchiveService {
+	return &ArchiveService{
+		store:   store,
+		dataDir: dataDir,
+		fs:      afero.NewOsFs(),
+	}
+}
+
+// SetFS sets the filesystem the data directory is on (see NewFS).
+// The default is the OS filesystem.
+func (s *ArchiveService) SetFS(fs afero.Fs) {
+	s.fs = fs
+}
+
+// ArchiveResult summarizes an archive or restore run.
+type ArchiveResult struct {
+	Files   int   // files moved (or that would be moved, for a dry run)
+	Bytes   int64 // uncompressed size of the files moved
+	Skipped int   // files that were in scope but could not be moved
+}
+
+// IsArchivedPath returns true if fsPath points into the archive directory.
+func IsArchivedPath(fsPath string) bool {
+	return strings.HasPrefix(path.Clean(filepath.ToSlash(fsPath)), ArchiveDir+"/")
+}
+
+// Archive compresses the blobs of report files created before cutoff into the
+// archive directory and updates their fs_path. Files already archived are ignored.
+// The new file is written and the database updated before the original is removed,
+// so an interrupted run never loses a blob.
+func (s *ArchiveService) Archive(ctx context.Context, cutoff time.Time, dryRun bool) (*ArchiveResult, error) {
+	rfs, err := s.store.GetReportFilesWithPath(ctx)
+	if err != nil {
+		return nil, &ErrDatabase{Op: "get report files", Err: err}
+	}
+
+	result := &ArchiveResult{}
+	for _, rf := range rfs {
+		if IsArchivedPath(rf.FsPath) || !rf.CreatedAt.Before(cutoff) {
+			continue
+		}
+		archivedPath := path.Join(ArchiveDir, filepath.ToSlash(rf.FsPath)) + ".gz"
+		n, err := s.move(ctx, rf, archivedPath, compress, dryRun)
+		if err != nil {
+			if _, ok := err.(*ErrDatabase); ok {
+				return result, err
+			}
+			logging.FromContext(ctx).Warn("pipeline: archive: skipping", logging.KeyReportFileID, rf.ID, "err", err)
+			result.Skipped++
+			continue
+		}
+		result.Files++
+		result.Bytes += n
+	}
+	return result, nil
+}
+
+// Restore decompresses archived report files back to their original location
+// and updates their fs_path. If ids is empty, every archived file is restored.
+func (s *ArchiveService) Restore(ctx context.Context, ids []int64, dryRun bool) (*ArchiveResult, error) {
+	rfs, err := s.store.GetReportFilesWithPath(ctx)
+	if err != nil {
+		return nil, &ErrDatabase{Op: "get report files", Err: err}
+	}
+	wanted := map[int64]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	result := &ArchiveResult{}
+	for _, rf := range rfs {
+		if !IsArchivedPath(rf.FsPath) || (len(wanted) != 0 && !wanted[rf.ID]) {
+			continue
+		}
+		originalPath := strings.TrimSuffix(strings.TrimPrefix(path.Clean(filepath.ToSlash(rf.FsPath)), ArchiveDir+"/"), ".gz")
+		n, err := s.move(ctx, rf, originalPath, decompress, dryRun)
+		if err != nil {
+			if _, ok := err.(*ErrDatabase); ok {
+				return result, err
+			}
+			logging.FromContext(ctx).Warn("pipeline: restore: skipping", logging.KeyReportFileID, rf.ID, "err", err)
+			result.Skipped++
+			continue
+		}
+		result.Files++
+		result.Bytes += n
+	}
+	return result, nil
+}
+
+// move copies rf's blob to newPath through transform, points the row at the
+// new path, and removes the old blob. It returns the uncompressed size.
+func (s *ArchiveService) move(ctx context.Context, rf *model.ReportFile, newPath string, transform func([]byte) ([]byte, int64, error), dryRun bool) (int64, error) {
+	oldFull := filepath.Join(s.dataDir, filepath.FromSlash(rf.FsPath))
+	newFull := filepath.Join(s.dataDir, filepath.FromSlash(newPath))
+	fsys := ContextFS(ctx, s.fs)
+
+	data, err := afero.ReadFile(fsys, oldFull)
+	if err != nil {
+		return 0, &ErrWriteFile{Op: "read", Path: oldFull, Err: err}
+	}
+	out, size, err := transform(data)
+	if err != nil {
+		return 0, &ErrWriteFile{Op: "transform", Path: oldFull, Err: err}
+	}
+	if dryRun {
+		return size, nil
+	}
+
+	if err := fsys.MkdirAll(filepath.Dir(newFull), 0755); err != nil {
+		return 0, &ErrWriteFile{Op: "mkdir", Path: filepath.Dir(newFull), Err: err}
+	}
+	if err := afero.WriteFile(fsys, newFull, out, 0644); err != nil {
+		return 0, &ErrWriteFile{Op: "write", Path: newFull, Err: err}
+	}
+	if err := s.store.UpdateReportFilePath(ctx, rf.ID, newPath); err != nil {
+		_ = fsys.Remove(newFull)
+		return 0, &ErrDatabase{Op: "update fs_path", Err: err}
+	}
+	if err := fsys.Remove(oldFull); err != nil {
+		return 0, &ErrWriteFile{Op: "remove", Path: oldFull, Err: err}
+	}
+	rf.FsPath = newPath
+	return size, nil
+}
+
+func compress(data []byte) ([]byte, int64, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), int64(len(data)), nil
+}
+
+func decompress(data []byte) ([]byte, int64, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gunzip: %w", err)
+	}
+	return out, int64(len(out)), nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/afero"
+)
+
+func TestArchiveService_ArchiveAndRestore(t *testing.T) {
+	ctx := context.Background()
+	sqlStore, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer sqlStore.Close()
+
+	now := time.Now().UTC()
+	fs := afero.NewMemMapFs()
+	for _, f := range []struct {
+		name    string
+		created time.Time
+	}{
+		{"old.docx", now.AddDate(0, -12, 0)},
+		{"new.docx", now},
+	} {
+		fsPath := "batches/1/" + f.name
+		if err := afero.WriteFile(fs, "/data/"+fsPath, []byte("contents of "+f.name), 0644); err != nil {
+			t.Fatalf("write %s: %v", f.name, err)
+		}
+		if _, err := sqlStore.InsertReportFileWithBatch(ctx, &model.ReportFile{
+			Game:      "0301",
+			ClanNo:    "0512",
+			TurnNo:    89912,
+			Name:      f.name,
+			SHA256:    f.name,
+			Mime:      "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			CreatedAt: f.created,
+			FsPath:    fsPath,
+		}); err != nil {
+			t.Fatalf("insert report file: %v", err)
+		}
+	}
+
+	svc := stages.NewArchiveService(sqlStore, "/data")
+	svc.SetFS(fs)
+
+	result, err := svc.Archive(ctx, now.AddDate(0, -6, 0), false)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if result.Files != 1 || result.Skipped != 0 {
+		t.Fatalf("archive: got %+v, want 1 file", result)
+	}
+	rf, err := sqlStore.GetReportFileByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("get report file: %v", err)
+	}
+	if rf.FsPath != "archive/batches/1/old.docx.gz" {
+		t.Errorf("archived fs_path: got %q", rf.FsPath)
+	}
+	if exists, _ := afero.Exists(fs, "/data/batches/1/old.docx"); exists {
+		t.Errorf("original blob was not removed")
+	}
+	if exists, _ := afero.Exists(fs, "/data/batches/1/new.docx"); !exists {
+		t.Errorf("recent blob was archived")
+	}
+
+	result, err = svc.Restore(ctx, nil, false)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.Files != 1 {
+		t.Fatalf("restore: got %+v, want 1 file", result)
+	}
+	rf, err = sqlStore.GetReportFileByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("get report file: %v", err)
+	}
+	if rf.FsPath != "batches/1/old.docx" {
+		t.Errorf("restored fs_path: got %q", rf.FsPath)
+	}
+	data, err := afero.ReadFile(fs, "/data/batches/1/old.docx")
+	if err != nil {
+		t.Fatalf("read restored blob: %v", err)
+	}
+	if string(data) != "contents of old.docx" {
+		t.Errorf("restored contents: got %q", data)
+	}
+}
+
+func TestIsArchivedPath(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"archive/batches/1/a.docx.gz", true},
+		{"batches/1/a.docx", false},
+		{"archived/a.docx", false},
+	} {
+		if got := stages.IsArchivedPath(tc.path); got != tc.want {
+			t.Errorf("IsArchivedPath(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
@@ -2,7 +2,11 @@
 
 package stages
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/mdhender/tnrpt/cerrs"
+)
 
 // ErrWriteFile is returned when file I/O operations fail.
 type ErrWriteFile struct {
@@ -19,6 +23,10 @@ func (e *ErrWriteFile) Unwrap() error {
 	return e.Err
 }
 
+func (e *ErrWriteFile) ErrorCode() cerrs.Code {
+	return cerrs.CodeWriteFile
+}
+
 // ErrDatabase is returned when database operations fail.
 type ErrDatabase struct {
 	Op  string
@@ -33,6 +41,15 @@ func (e *ErrDatabase) Unwrap() error {
 	return e.Err
 }
 
+// ErrorCode returns the store's code for the failure (e.g., DATABASE_BUSY)
+// if it has one, and DATABASE otherwise.
+func (e *ErrDatabase) ErrorCode() cerrs.Code {
+	if code := cerrs.CodeOf(e.Err); code != cerrs.CodeUnknown && code != "" {
+		return code
+	}
+	return cerrs.CodeDatabase
+}
+
 // ErrDocxCorrupt is returned when DOCX extraction fails due to corruption.
 type ErrDocxCorrupt struct {
 	Path string
@@ -47,6 +64,10 @@ func (e *ErrDocxCorrupt) Unwrap() error {
 	return e.Err
 }
 
+func (e *ErrDocxCorrupt) ErrorCode() cerrs.Code {
+	return cerrs.CodeDocxCorrupt
+}
+
 // ErrParseSyntax is returned when the bistre parser encounters syntax errors.
 type ErrParseSyntax struct {
 	Line int
@@ -60,27 +81,43 @@ func (e *ErrParseSyntax) Error() string {
 	return fmt.Sprintf("parse syntax error: %s", e.Msg)
 }
 
-// Error code constants for database storage.
+func (e *ErrParseSyntax) ErrorCode() cerrs.Code {
+	return cerrs.CodeParseSyntax
+}
+
+// ErrChecksum is returned when a stored file's content doesn't match the
+// SHA-256 recorded when it was ingested.
+type ErrChecksum struct {
+	Path string
+	Want string
+	Got  string
+}
+
+func (e *ErrChecksum) Error() string {
+	return fmt.Sprintf("checksum mismatch %s: want sha256 %s, got %s", e.Path, e.Want, e.Got)
+}
+
+func (e *ErrChecksum) ErrorCode() cerrs.Code {
+	return cerrs.CodeChecksum
+}
+
+// Error code constants for database storage. They are the codes in the cerrs
+// package; errors from the store and adapters carry them too.
 const (
-	ErrCodeWriteFile    = "WRITE_FILE"
-	ErrCodeDatabase     = "DATABASE"
-	ErrCodeDocxCorrupt  = "DOCX_CORRUPT"
-	ErrCodeParseSyntax  = "PARSE_SYNTAX_ERROR"
-	ErrCodeUnknown      = "UNKNOWN"
+	ErrCodeWriteFile     = string(cerrs.CodeWriteFile)
+	ErrCodeDatabase      = string(cerrs.CodeDatabase)
+	ErrCodeDocxCorrupt   = string(cerrs.CodeDocxCorrupt)
+	ErrCodeParseSyntax   = string(cerrs.CodeParseSyntax)
+	ErrCodeUnitIDInvalid = string(cerrs.CodeUnitIDInvalid)
+	ErrCodeChecksum      = string(cerrs.CodeChecksum)
+	ErrCodeClanMismatch  = string(cerrs.CodeClanMismatch)
+	ErrCodeUnknown       = string(cerrs.CodeUnknown)
 )
 
-// ErrorCode returns the error code string for a given error.
+// ErrorCode returns the error code string for a given error (see cerrs.CodeOf).
 func ErrorCode(err error) string {
-	switch err.(type) {
-	case *ErrWriteFile:
-		return ErrCodeWriteFile
-	case *ErrDatabase:
-		return ErrCodeDatabase
-	case *ErrDocxCorrupt:
-		return ErrCodeDocxCorrupt
-	case *ErrParseSyntax:
-		return ErrCodeParseSyntax
-	default:
+	if err == nil {
 		return ErrCodeUnknown
 	}
+	return string(cerrs.CodeOf(err))
 }
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+func TestErrorCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{nil, stages.ErrCodeUnknown},
+		{errors.New("boom"), stages.ErrCodeUnknown},
+		{&stages.ErrWriteFile{Op: "write", Path: "x", Err: errors.New("disk full")}, stages.ErrCodeWriteFile},
+		{&stages.ErrDatabase{Op: "insert", Err: errors.New("boom")}, stages.ErrCodeDatabase},
+		{&stages.ErrParseSyntax{Msg: "bad"}, stages.ErrCodeParseSyntax},
+		{fmt.Errorf("persist: %w", &model.UnitIDError{UnitID: "x", Reason: "bad"}), stages.ErrCodeUnitIDInvalid},
+	} {
+		if got := stages.ErrorCode(tc.err); got != tc.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+// TestErrorCode_Store checks that the store's errors reach the work table
+// with a code that says what went wrong, not just DATABASE.
+func TestErrorCode_Store(t *testing.T) {
+	ctx := context.Background()
+	s, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// a job for a report file that doesn't exist breaks a foreign key, which
+	// is a bug in the caller, not bad input
+	_, err = s.InsertWork(ctx, &model.Work{ReportFileID: 999, Stage: model.WorkStageParse, Status: model.WorkStatusQueued, AvailableAt: time.Now()})
+	if got := stages.ErrorCode(&stages.ErrDatabase{Op: "insert work", Err: err}); got != "DATABASE" {
+		t.Errorf("missing report file: code = %q, want %q (%v)", got, "DATABASE", err)
+	}
+
+	// the same unit twice in one extract is a duplicate row
+	rf := &model.ReportFile{Game: "0301", ClanNo: "0987", TurnNo: 89912, Name: "a.docx", SHA256: "abc", Mime: "text/plain", CreatedAt: time.Now()}
+	if err := s.AddReportFile(rf); err != nil {
+		t.Fatal(err)
+	}
+	unit := &model.UnitX{UnitID: "0987", ClanID: "0987", TurnNo: 89912, StartTN: "QQ 1010", EndTN: "QQ 1010"}
+	err = s.AddReport(&model.ReportX{ReportFileID: rf.ID, Game: "0301", ClanNo: "0987", TurnNo: 89912, CreatedAt: time.Now(), Units: []*model.UnitX{unit, unit}})
+	if got := stages.ErrorCode(err); got != "CONFLICT" {
+		t.Errorf("duplicate unit: code = %q, want %q (%v)", got, "CONFLICT", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// Faults forces stages to fail, so that tests can drive the retry, status,
+// and notification paths without corrupt files or a locked database.
+// ProcessJob asks Fault before running a stage; a non-nil error fails the
+// job as if the stage had returned it. Production workers have no Faults.
+type Faults interface {
+	Fault(stage string, rf *model.ReportFile) error
+}
+
+// SetFaults sets the failures to inject for testing (see FaultInjector).
+func (w *WorkerService) SetFaults(f Faults) {
+	w.faults = f
+}
+
+// FaultKind is a failure a FaultInjector can force.
+type FaultKind string
+
+const (
+	FaultDocxCorrupt  FaultKind = "docx-corrupt"  // DOCX_CORRUPT
+	FaultParseSyntax  FaultKind = "parse-syntax"  // PARSE_SYNTAX_ERROR
+	FaultDatabaseBusy FaultKind = "database-busy" // DATABASE_BUSY
+)
+
+// errInjected is wrapped in every injected failure.
+var errInjected = errors.New("injected fault")
+
+// FaultInjector fails chosen stages for chosen report files. It is safe for
+// concurrent use by several workers.
+type FaultInjector struct {
+	mu     sync.Mutex
+	faults []*injectedFault
+}
+
+type injectedFault struct {
+	stage string
+	name  string // report file name; empty for every file
+	kind  FaultKind
+	times int // failures left; negative for no limit
+}
+
+// Add fails the stage for the report file with the given name (or every
+// file, if name is empty) the next times runs, or every run if times is
+// zero. Faults are matched in the order they were added.
+func (fi *FaultInjector) Add(stage, name string, kind FaultKind, times int) {
+	if times <= 0 {
+		times = -1
+	}
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.faults = append(fi.faults, &injectedFault{stage: stage, name: name, kind: kind, times: times})
+}
+
+// Fault implements Faults.
+func (fi *FaultInjector) Fault(stage string, rf *model.ReportFile) error {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	for _, f := range fi.faults {
+		if f.stage != stage || (f.name != "" && f.name != rf.Name) || f.times == 0 {
+			continue
+		}
+		if f.times > 0 {
+			f.times--
+		}
+		switch f.kind {
+		case FaultDocxCorrupt:
+			return &ErrDocxCorrupt{Path: rf.FsPath, Err: errInjected}
+		case FaultParseSyntax:
+			return &ErrParseSyntax{Msg: errInjected.Error()}
+		case FaultDatabaseBusy:
+			return &ErrDatabase{Op: stage, Err: cerrs.Wrap(cerrs.CodeDatabaseBusy, "injected", errInjected)}
+		}
+		return errInjected
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// NewFS returns the filesystem that the pipeline's storage paths (the data
+// directory, drop directories, import sources, and checkpoints) are on.
+// If root is empty, it is the OS filesystem. If root is an object store
+// location like "s3://bucket/prefix", it is an S3FS (see S3ConfigFromURL).
+// Otherwise every path is taken relative to root, so "/data" is root/data
+// and paths can't escape root.
+//
+// Any afero.Fs works with the services' SetFS methods, e.g. an in-memory
+// filesystem for tests.
+func NewFS(root string) (afero.Fs, error) {
+	if root == "" {
+		return afero.NewOsFs(), nil
+	} else if strings.HasPrefix(root, "s3://") {
+		cfg, err := S3ConfigFromURL(root)
+		if err != nil {
+			return nil, err
+		}
+		return NewS3FS(cfg)
+	}
+	return afero.NewBasePathFs(afero.NewOsFs(), root), nil
+}
+
+// ContextFS returns fsys with its network requests bound to ctx, so that they
+// stop when ctx is cancelled (see S3FS.WithContext). Other filesystems are
+// returned as they are.
+func ContextFS(ctx context.Context, fsys afero.Fs) afero.Fs {
+	if s, ok := fsys.(*S3FS); ok {
+		return s.WithContext(ctx)
+	}
+	return fsys
+}
+
+// URLSigner is implemented by filesystems that can hand out time-limited
+// download links for their files, like S3FS.
+type URLSigner interface {
+	SignedURL(name string, ttl time.Duration) (string, error)
+}
+
+// VerifySHA256 returns an *ErrChecksum if the file's content doesn't hash to
+// want, the hex SHA-256 recorded when the file was ingested. Filesystems that
+// can hash a file without holding it in memory, like S3FS, are asked to.
+func VerifySHA256(fsys afero.Fs, name, want string) error {
+	var got string
+	if h, ok := fsys.(interface{ SHA256(string) (string, error) }); ok {
+		sum, err := h.SHA256(name)
+		if err != nil {
+			return &ErrWriteFile{Op: "read", Path: name, Err: err}
+		}
+		got = sum
+	} else {
+		data, err := afero.ReadFile(fsys, name)
+		if err != nil {
+			return &ErrWriteFile{Op: "read", Path: name, Err: err}
+		}
+		hash := sha256.Sum256(data)
+		got = hex.EncodeToString(hash[:])
+	}
+	if !strings.EqualFold(got, want) {
+		return &ErrChecksum{Path: name, Want: want, Got: got}
+	}
+	return nil
+}
+
+// checkSHA256 returns an *ErrChecksum if data read from path doesn't hash to
+// want. An empty want isn't checked.
+func checkSHA256(path string, data []byte, want string) error {
+	if want == "" {
+		return nil
+	}
+	hash := sha256.Sum256(data)
+	if got := hex.EncodeToString(hash[:]); !strings.EqualFold(got, want) {
+		return &ErrChecksum{Path: path, Want: want, Got: got}
+	}
+	return nil
+}
+
+// checkSHA256Reader is checkSHA256 for content read from r, which is read to
+// the end without being held in memory.
+func checkSHA256Reader(path string, r io.Reader, want string) error {
+	if want == "" {
+		return nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return &ErrWriteFile{Op: "read", Path: path, Err: err}
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(got, want) {
+		return &ErrChecksum{Path: path, Want: want, Got: got}
+	}
+	return nil
+}
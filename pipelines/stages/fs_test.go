@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	"github.com/spf13/afero"
+)
+
+func TestNewFS_Root(t *testing.T) {
+	root := t.TempDir()
+	fsys, err := stages.NewFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.MkdirAll("/data/0301", 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := afero.WriteFile(fsys, "/data/0301/report.txt", []byte("hello"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "data", "0301", "report.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("expected the file under the root, got %q, %v", data, err)
+	}
+	if _, err := fsys.Stat("/../outside.txt"); err == nil {
+		t.Errorf("expected paths outside the root to fail")
+	}
+}
+
+func TestNewFS_S3(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := stages.NewFS("s3://reports/prod"); err == nil {
+		t.Errorf("expected an error without credentials")
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	fsys, err := stages.NewFS("s3://reports/prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fsys.(*stages.S3FS); !ok {
+		t.Errorf("expected an S3FS, got %T", fsys)
+	}
+}
+
+func TestVerifySHA256(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/data/report.txt", []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("hello"))
+	if err := stages.VerifySHA256(fsys, "/data/report.txt", hex.EncodeToString(hash[:])); err != nil {
+		t.Errorf("expected a match, got %v", err)
+	}
+
+	if err := afero.WriteFile(fsys, "/data/report.txt", []byte("hullo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := stages.VerifySHA256(fsys, "/data/report.txt", hex.EncodeToString(hash[:]))
+	var errChecksum *stages.ErrChecksum
+	if !errors.As(err, &errChecksum) {
+		t.Fatalf("expected ErrChecksum, got %v", err)
+	}
+	if code := stages.ErrorCode(err); code != stages.ErrCodeChecksum {
+		t.Errorf("error code: expected %q, got %q", stages.ErrCodeChecksum, code)
+	}
+
+	if err := stages.VerifySHA256(fsys, "/data/missing.txt", hex.EncodeToString(hash[:])); err == nil || errors.As(err, &errChecksum) {
+		t.Errorf("missing file: expected a read error, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/afero"
+)
+
+// GameImportDir is the directory, relative to the data directory, that holds
+// the blobs of imported games, e.g. "games/0301/12/0512.docx" for the file
+// that had ID 12 in the exporting database.
+const GameImportDir = "games"
+
+// gameArchiveManifest is the name of the archive entry that holds the
+// store.GameArchive. Each report file's blob is stored under "files/<id>/",
+// with its text, if any, next to it.
+const gameArchiveManifest = "game.json"
+
+// GameArchiveService writes a whole game, with its report files, to a zip
+// archive and creates a game from one, so that a campaign can be handed to a
+// new GM or moved to a new server.
+type GameArchiveService struct {
+	store   GameArchiveStore
+	dataDir string
+	fs      afero.Fs
+}
+
+// GameArchiveStore defines the store operations needed by GameArchiveService.
+type GameArchiveStore interface {
+	ExportGame(ctx context.Context, gameID string, redact bool) (*store.GameArchive, error)
+	ImportGame(ctx context.Context, actor string, a *store.GameArchive, grantGM bool) (*store.GameImport, error)
+}
+
+// NewGameArchiveService creates a new GameArchiveService.
+func NewGameArchiveService(store GameArchiveStore, dataDir string) *GameArchiveService {
+	return &GameArchiveService{
+		store:   store,
+		dataDir: dataDir,
+		fs:      afero.NewOsFs(),
+	}
+}
+
+// SetFS sets the filesystem the data directory is on (see NewFS).
+// The default is the OS filesystem.
+func (s *GameArchiveService) SetFS(fs afero.Fs) {
+	s.fs = fs
+}
+
+// Export writes a game to w as a zip archive. Archived blobs are stored
+// uncompressed. A report file whose blob is missing is still listed, so its
+// reports survive, and is logged; it has no blob after an import.
+func (s *GameArchiveService) Export(ctx context.Context, gameID string, redact bool, w io.Writer) (*store.GameArchive, error) {
+	a, err := s.store.ExportGame(ctx, gameID, redact)
+	if err != nil {
+		return nil, err
+	}
+	fsys := ContextFS(ctx, s.fs)
+	zw := zip.NewWriter(w)
+	for _, rf := range a.Files {
+		origPath := rf.FsPath
+		dir := "files/" + strconv.FormatInt(rf.ID, 10) + "/"
+		rf.FsPath = ""
+		if origPath == "" {
+			continue
+		}
+		full := filepath.Join(s.dataDir, filepath.FromSlash(origPath))
+		data, err := afero.ReadFile(fsys, full)
+		if errors.Is(err, fs.ErrNotExist) {
+			logging.FromContext(ctx).Warn("game: export: blob missing", logging.KeyReportFileID, rf.ID, logging.KeyFile, origPath)
+			continue
+		} else if err != nil {
+			return nil, &ErrWriteFile{Op: "read", Path: full, Err: err}
+		}
+		if IsArchivedPath(origPath) {
+			if data, _, err = decompress(data); err != nil {
+				return nil, &ErrWriteFile{Op: "decompress", Path: full, Err: err}
+			}
+			origPath = strings.TrimSuffix(strings.TrimPrefix(path.Clean(filepath.ToSlash(origPath)), ArchiveDir+"/"), ".gz")
+		}
+		rf.FsPath = dir + path.Base(filepath.ToSlash(origPath))
+		if err := writeZipEntry(zw, rf.FsPath, data); err != nil {
+			return nil, err
+		}
+
+		// the text the extract stage wrote for a DOCX; it can be extracted
+		// again, so it's fine if it's missing
+		textPath := ReportTextPath(&model.ReportFile{FsPath: origPath})
+		if textPath == origPath {
+			continue
+		}
+		text, err := afero.ReadFile(fsys, filepath.Join(s.dataDir, filepath.FromSlash(textPath)))
+		if err != nil {
+			continue
+		}
+		if err := writeZipEntry(zw, ReportTextPath(rf), text); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal game: %w", err)
+	}
+	if err := writeZipEntry(zw, gameArchiveManifest, append(manifest, '\n')); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close archive: %w", err)
+	}
+	return a, nil
+}
+
+func writeZipEntry(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("archive %s: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("archive %s: %w", name, err)
+	}
+	return nil
+}
+
+// Import creates a game from a zip archive written by Export. The blobs are
+// copied under GameImportDir before the database is written and are removed
+// again if the import fails. It fails if the game's directory already exists.
+// The archive's GMs are only made GMs with grantGM (see store.ImportGame).
+func (s *GameArchiveService) Import(ctx context.Context, actor string, r io.ReaderAt, size int64, grantGM bool) (*store.GameImport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	entries := map[string]*zip.File{}
+	for _, zf := range zr.File {
+		entries[zf.Name] = zf
+	}
+	zf, ok := entries[gameArchiveManifest]
+	if !ok {
+		return nil, fmt.Errorf("open archive: %s is missing; not a game archive?", gameArchiveManifest)
+	}
+	data, err := readZipEntry(zf)
+	if err != nil {
+		return nil, err
+	}
+	var a store.GameArchive
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", gameArchiveManifest, err)
+	}
+	if a.Game.ID == "" || strings.ContainsAny(a.Game.ID, `/\.`) {
+		return nil, fmt.Errorf("parse %s: invalid game id %q", gameArchiveManifest, a.Game.ID)
+	}
+
+	fsys := ContextFS(ctx, s.fs)
+	gameDir := filepath.Join(s.dataDir, GameImportDir, a.Game.ID)
+	if _, err := fsys.Stat(gameDir); err == nil {
+		return nil, &ErrWriteFile{Op: "import", Path: gameDir, Err: fs.ErrExist}
+	}
+	for _, rf := range a.Files {
+		entry := rf.FsPath
+		rf.FsPath = ""
+		if entry == "" {
+			continue
+		}
+		zf, ok := entries[entry]
+		if !ok {
+			return nil, s.abortImport(fsys, gameDir, fmt.Errorf("open archive: %s is missing", entry))
+		}
+		name := path.Base(entry)
+		if name == "." || name == ".." || name == "/" {
+			return nil, s.abortImport(fsys, gameDir, fmt.Errorf("open archive: invalid entry %q", entry))
+		}
+		rf.FsPath = path.Join(GameImportDir, a.Game.ID, strconv.FormatInt(rf.ID, 10), name)
+		if err := s.copyZipEntry(fsys, zf, rf.FsPath); err != nil {
+			return nil, s.abortImport(fsys, gameDir, err)
+		}
+		if zf, ok := entries[ReportTextPath(&model.ReportFile{FsPath: entry})]; ok && zf.Name != entry {
+			if err := s.copyZipEntry(fsys, zf, ReportTextPath(rf)); err != nil {
+				return nil, s.abortImport(fsys, gameDir, err)
+			}
+		}
+	}
+
+	result, err := s.store.ImportGame(ctx, actor, &a, grantGM)
+	if err != nil {
+		return nil, s.abortImport(fsys, gameDir, err)
+	}
+	return result, nil
+}
+
+// abortImport removes the blobs a failed Import copied and returns err.
+func (s *GameArchiveService) abortImport(fsys afero.Fs, gameDir string, err error) error {
+	if rmErr := fsys.RemoveAll(gameDir); rmErr != nil {
+		return fmt.Errorf("%w (removing %s: %v)", err, gameDir, rmErr)
+	}
+	return err
+}
+
+func (s *GameArchiveService) copyZipEntry(fsys afero.Fs, zf *zip.File, fsPath string) error {
+	data, err := readZipEntry(zf)
+	if err != nil {
+		return err
+	}
+	full := filepath.Join(s.dataDir, filepath.FromSlash(fsPath))
+	if err := fsys.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return &ErrWriteFile{Op: "mkdir", Path: filepath.Dir(full), Err: err}
+	}
+	if err := afero.WriteFile(fsys, full, data, 0644); err != nil {
+		return &ErrWriteFile{Op: "write", Path: full, Err: err}
+	}
+	return nil
+}
+
+func readZipEntry(zf *zip.File) ([]byte, error) {
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %s: %w", zf.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %s: %w", zf.Name, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/afero"
+)
+
+func TestGameArchiveService_ExportImport(t *testing.T) {
+	ctx := context.Background()
+	open := func(name string) *store.SQLiteStore {
+		path := filepath.Join(t.TempDir(), name)
+		if err := store.InitDatabase(path); err != nil {
+			t.Fatalf("init %s: %v", name, err)
+		}
+		s, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+	src, dst := open("src.db"), open("dst.db")
+	fs := afero.NewMemMapFs()
+
+	// a game with one player and one parsed report
+	if _, err := src.CreateGame(ctx, "0301", "test game"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.AddGameTurn(ctx, "0301", 89912, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.ImportUsers(ctx, "test", []store.UserRecord{
+		{Handle: "alice", UserName: "Alice", Email: "alice@example.com", Roles: []string{"user", "gm"}, Clans: []store.UserClanNo{{Game: "0301", Clan: 987}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{"0899-12.0987.docx": "docx bytes", "0899-12.0987.report.txt": "report text"} {
+		if err := afero.WriteFile(fs, "/src/batches/1/"+name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rf := &model.ReportFile{Game: "0301", ClanNo: "0987", TurnNo: 89912, Name: "0899-12.0987.docx", SHA256: "abc",
+		Mime: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", CreatedAt: time.Now().UTC(), FsPath: "batches/1/0899-12.0987.docx"}
+	if _, err := src.InsertReportFileWithBatch(ctx, rf); err != nil {
+		t.Fatal(err)
+	}
+	rxID, err := src.InsertReportExtract(ctx, &model.ReportX{ReportFileID: rf.ID, Game: "0301", ClanNo: "0987", TurnNo: 89912, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uxID, err := src.InsertUnitExtract(ctx, &model.UnitX{ReportXID: rxID, UnitID: "0987", ClanID: "987", TurnNo: 89912, StartTN: "QQ 1010", EndTN: "QQ 1009"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actID, err := src.InsertAct(ctx, &model.Act{UnitXID: uxID, Seq: 1, Kind: model.ActKindMove, Ok: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.InsertStep(ctx, &model.Step{ActID: actID, Seq: 1, Kind: model.StepKindAdv, Dir: "N", Ok: true, Terr: "PR",
+		Enc: &model.Enc{Units: []*model.UnitSeen{{UnitID: "0123"}}}, Borders: []*model.BorderObs{{Dir: "N", Kind: "River"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.RebuildDerived(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	exporter := stages.NewGameArchiveService(src, "/src")
+	exporter.SetFS(fs)
+	a, err := exporter.Export(ctx, "0301", true, &buf)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(a.Users) != 1 || a.Users[0].Email != "" || a.Users[0].PasswordHash != "" {
+		t.Errorf("export: users = %+v, want alice redacted", a.Users)
+	}
+	if a.Tiles == 0 {
+		t.Fatalf("export: no tiles")
+	}
+
+	// another game in the destination whose tiles outlive its reports, as
+	// they do until a rebuild; the import must only rebuild its own game
+	if _, err := dst.CreateGame(ctx, "0302", "other game"); err != nil {
+		t.Fatal(err)
+	}
+	other := &model.ReportFile{Game: "0302", ClanNo: "0512", TurnNo: 89912, Name: "0899-12.0512.report.txt", SHA256: "def",
+		Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: "batches/1/0899-12.0512.report.txt"}
+	if _, err := dst.InsertReportFileWithBatch(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	otherRx, err := dst.InsertReportExtract(ctx, &model.ReportX{ReportFileID: other.ID, Game: "0302", ClanNo: "0512", TurnNo: 89912, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherUx, err := dst.InsertUnitExtract(ctx, &model.UnitX{ReportXID: otherRx, UnitID: "0512", ClanID: "512", TurnNo: 89912, StartTN: "QQ 1010", EndTN: "QQ 1010"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAct, err := dst.InsertAct(ctx, &model.Act{UnitXID: otherUx, Seq: 1, Kind: model.ActKindMove, Ok: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.InsertStep(ctx, &model.Step{ActID: otherAct, Seq: 1, Kind: model.StepKindStill, Ok: true, Terr: "PR"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.RebuildDerived(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.ReassignReportFile(ctx, "test", other.ID, "0302", "0512", 89912, true); err != nil {
+		t.Fatal(err)
+	}
+
+	importer := stages.NewGameArchiveService(dst, "/dst")
+	importer.SetFS(fs)
+	result, err := importer.Import(ctx, "test", bytes.NewReader(buf.Bytes()), int64(buf.Len()), false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if want := (store.GameImport{Users: 1, Files: 1, Reports: 1, Units: 1, Tiles: a.Tiles}); *result != want {
+		t.Errorf("import: got %+v, want %+v", *result, want)
+	}
+	for name, want := range map[string]string{"0899-12.0987.docx": "docx bytes", "0899-12.0987.report.txt": "report text"} {
+		data, err := afero.ReadFile(fs, "/dst/games/0301/"+strconv.FormatInt(rf.ID, 10)+"/"+name)
+		if err != nil || string(data) != want {
+			t.Errorf("import: %s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+
+	if isGM, err := dst.IsUserGM(ctx, "alice"); err != nil || isGM {
+		t.Errorf("import: alice is a gm (%v), want the role left out without grantGM", err)
+	}
+	if c, err := dst.ExportGame(ctx, "0302", false); err != nil || c.Tiles == 0 {
+		t.Errorf("import: game 0302 has %d tiles (%v), want its tiles left alone", c.Tiles, err)
+	}
+
+	b, err := dst.ExportGame(ctx, "0301", false)
+	if err != nil {
+		t.Fatalf("export imported game: %v", err)
+	}
+	if len(b.Users) != 1 || len(b.Users[0].Clans) != 1 || b.Users[0].Clans[0].Clan != 987 {
+		t.Errorf("imported users = %+v", b.Users)
+	}
+	if len(b.Reports) != 1 || len(b.Reports[0].Units) != 1 || len(b.Reports[0].Units[0].Acts) != 1 {
+		t.Fatalf("imported reports = %+v", b.Reports)
+	}
+	st := b.Reports[0].Units[0].Acts[0].Steps[0]
+	if st.Terr != "PR" || st.Enc == nil || len(st.Enc.Units) != 1 || len(st.Borders) != 1 {
+		t.Errorf("imported step = %+v", st)
+	}
+
+	if _, err := importer.Import(ctx, "test", bytes.NewReader(buf.Bytes()), int64(buf.Len()), false); err == nil {
+		t.Errorf("second import: got nil error, want an existing game")
+	}
+}
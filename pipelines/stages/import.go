@@ -0,0 +1,468 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/spf13/afero"
+)
+
+// ImportGuess is what is known about the report in an archived file.
+// Fields that could not be worked out are empty (or zero for TurnNo).
+type ImportGuess struct {
+	Game   string       `json:"game,omitempty"`
+	ClanNo string       `json:"clan,omitempty"`
+	TurnNo model.TurnNo `json:"turn,omitempty"`
+}
+
+// Complete returns true if the game, clan, and turn are all known.
+func (g ImportGuess) Complete() bool {
+	return g.Game != "" && g.ClanNo != "" && g.TurnNo.Valid()
+}
+
+// merge fills the fields of g that are empty from h.
+func (g ImportGuess) merge(h ImportGuess) ImportGuess {
+	if g.Game == "" {
+		g.Game = h.Game
+	}
+	if g.ClanNo == "" {
+		g.ClanNo = h.ClanNo
+	}
+	if !g.TurnNo.Valid() {
+		g.TurnNo = h.TurnNo
+	}
+	return g
+}
+
+var (
+	reImportLabeled = regexp.MustCompile(`(game|clan|tribe)[-_ ]?(\d{1,4})(?:\D|$)`)
+	reImportTurn    = regexp.MustCompile(`(?:^|\D)(\d{3,4})[-_. ](\d{1,2})(?:\D|$)`)
+	reImportTurnInt = regexp.MustCompile(`(?:^|\D)(\d{5,6})(?:\D|$)`)
+	reImportNumber  = regexp.MustCompile(`\d+`)
+)
+
+// InferReportPath guesses the game, clan, and turn of a report from its path
+// in an archive. Drop-folder names (see ParseReportFilename) are recognized
+// first. Otherwise every directory and the file name are searched for labeled
+// numbers ("game 301", "clan-0512"), turns ("0899-12", "899_12", "89912"),
+// and four-digit numbers with a leading zero. The turn closest to the file
+// name wins. Of the unlabeled four-digit numbers, the one nearest the file
+// is taken as the clan and the one nearest the root as the game, so
+// "0301/0512/0899-12.docx" and "0301.0512.0899-12.docx" both work.
+func InferReportPath(rel string) ImportGuess {
+	rel = filepath.ToSlash(rel)
+	if game, clanNo, turnNo, ok := ParseReportFilename(path.Base(rel)); ok {
+		return ImportGuess{Game: game, ClanNo: clanNo, TurnNo: turnNo}
+	}
+
+	var g ImportGuess
+	var ids []string
+	segments := strings.Split(strings.TrimSuffix(rel, path.Ext(rel)), "/")
+	for _, seg := range segments {
+		seg = strings.ToLower(seg)
+		for _, m := range reImportLabeled.FindAllStringSubmatch(seg, -1) {
+			n, _ := strconv.Atoi(m[2])
+			switch m[1] {
+			case "game":
+				g.Game = fmt.Sprintf("%04d", n)
+			default:
+				g.ClanNo = fmt.Sprintf("%04d", n)
+			}
+		}
+		seg = reImportLabeled.ReplaceAllString(seg, " ")
+		if t, rest, ok := findImportTurn(seg); ok {
+			g.TurnNo, seg = t, rest
+		}
+		for _, n := range reImportNumber.FindAllString(seg, -1) {
+			if len(n) == 4 && n[0] == '0' {
+				ids = append(ids, n)
+			}
+		}
+	}
+
+	if len(ids) > 0 && g.ClanNo == "" {
+		g.ClanNo = ids[len(ids)-1]
+		ids = ids[:len(ids)-1]
+	}
+	if len(ids) > 0 && g.Game == "" {
+		g.Game = ids[0]
+	}
+	return g
+}
+
+// findImportTurn returns the last valid turn in seg and seg with it removed.
+func findImportTurn(seg string) (model.TurnNo, string, bool) {
+	for _, re := range []*regexp.Regexp{reImportTurn, reImportTurnInt} {
+		matches := re.FindAllStringSubmatchIndex(seg, -1)
+		for i := len(matches) - 1; i >= 0; i-- {
+			m := matches[i]
+			var t model.TurnNo
+			if re == reImportTurn {
+				year, _ := strconv.Atoi(seg[m[2]:m[3]])
+				month, _ := strconv.Atoi(seg[m[4]:m[5]])
+				t = model.NewTurnNo(year, month)
+			} else {
+				t, _ = model.ParseTurnNo(seg[m[2]:m[3]])
+			}
+			if t.Valid() {
+				return t, seg[:m[2]] + " " + seg[m[1]:], true
+			}
+		}
+	}
+	return 0, seg, false
+}
+
+// ImportMapping overrides what is inferred for archive paths that start
+// with Prefix. Empty fields in the mapping are still inferred.
+type ImportMapping struct {
+	Prefix string
+	ImportGuess
+}
+
+// ReadImportMappings reads a mapping file. Each line is a CSV record of
+// "path-prefix,game,clan,turn"; empty fields are left to inference and
+// lines starting with '#' are comments. When several prefixes match a path,
+// the longest one wins.
+func ReadImportMappings(r io.Reader) ([]ImportMapping, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 4
+	cr.TrimLeadingSpace = true
+	var list []ImportMapping
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("mapping: %w", err)
+		}
+		m := ImportMapping{Prefix: filepath.ToSlash(rec[0])}
+		m.Game, m.ClanNo = rec[1], rec[2]
+		if rec[3] != "" {
+			if m.TurnNo, err = model.ParseTurnNo(rec[3]); err != nil {
+				return nil, fmt.Errorf("mapping: %s: %w", rec[0], err)
+			}
+		}
+		list = append(list, m)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return len(list[i].Prefix) > len(list[j].Prefix)
+	})
+	return list, nil
+}
+
+// ImportCheckpoint records the archive files that have been imported, so an
+// interrupted import can pick up where it stopped. It is saved as JSON after
+// every file.
+type ImportCheckpoint struct {
+	Source string                  `json:"source"`
+	Files  map[string]ImportedFile `json:"files"`
+	fs     afero.Fs
+	path   string
+}
+
+// ImportedFile is the checkpoint entry for one archive file.
+type ImportedFile struct {
+	ImportGuess
+	ReportFileID int64 `json:"reportFileId"`
+	Duplicate    bool  `json:"duplicate,omitempty"`
+}
+
+// LoadImportCheckpoint reads the checkpoint at path, or starts a new one if
+// the file does not exist. A checkpoint written for a different source is
+// an error, so two imports can't share one by accident.
+func LoadImportCheckpoint(fs afero.Fs, path, source string) (*ImportCheckpoint, error) {
+	cp := &ImportCheckpoint{Source: source, Files: map[string]ImportedFile{}, fs: fs, path: path}
+	data, err := afero.ReadFile(fs, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	} else if err != nil {
+		return nil, &ErrWriteFile{Op: "read", Path: path, Err: err}
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	if cp.Source != source {
+		return nil, fmt.Errorf("checkpoint %s is for %q, not %q", path, cp.Source, source)
+	}
+	if cp.Files == nil {
+		cp.Files = map[string]ImportedFile{}
+	}
+	return cp, nil
+}
+
+// Save writes the checkpoint to a temporary file and renames it into place,
+// so an interruption never leaves a truncated checkpoint.
+func (cp *ImportCheckpoint) Save() error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := cp.path + ".tmp"
+	if err := afero.WriteFile(cp.fs, tmp, data, 0644); err != nil {
+		return &ErrWriteFile{Op: "write", Path: tmp, Err: err}
+	}
+	if err := cp.fs.Rename(tmp, cp.path); err != nil {
+		return &ErrWriteFile{Op: "rename", Path: cp.path, Err: err}
+	}
+	return nil
+}
+
+// ImportResolver is asked about files whose game, clan, or turn could not be
+// inferred. It returns the completed guess, or false to skip the file.
+type ImportResolver func(path string, guess ImportGuess) (ImportGuess, bool)
+
+// ImportService walks a folder, or a ZIP file, of old turn reports and
+// ingests every .docx and .txt file it can place (see IngestService).
+type ImportService struct {
+	ingest    *IngestService
+	createdBy string
+	fs        afero.Fs
+	defaults  ImportGuess
+	mappings  []ImportMapping
+	resolve   ImportResolver
+}
+
+// NewImportService creates a new ImportService.
+func NewImportService(ingest *IngestService, createdBy string) *ImportService {
+	return &ImportService{
+		ingest:    ingest,
+		createdBy: createdBy,
+		fs:        afero.NewOsFs(),
+	}
+}
+
+// SetFS sets the filesystem that import sources are read from (see NewFS).
+// The default is the OS filesystem.
+func (s *ImportService) SetFS(fs afero.Fs) {
+	s.fs = fs
+}
+
+// SetDefaults sets the game, clan, or turn used when they can't be inferred.
+func (s *ImportService) SetDefaults(g ImportGuess) {
+	s.defaults = g
+}
+
+// SetMappings sets the mapping file entries, which win over inference.
+func (s *ImportService) SetMappings(m []ImportMapping) {
+	s.mappings = m
+}
+
+// SetResolver sets the function asked about files that still can't be placed.
+func (s *ImportService) SetResolver(r ImportResolver) {
+	s.resolve = r
+}
+
+// ImportSummary reconciles an import run.
+type ImportSummary struct {
+	Ingested   int                 `json:"ingested"`   // files added to the pipeline by this run
+	Duplicates int                 `json:"duplicates"` // files whose contents were already in the database
+	Resumed    int                 `json:"resumed"`    // files skipped because the checkpoint has them
+	Ignored    int                 `json:"ignored"`    // files that aren't reports (by extension)
+	Unresolved []string            `json:"unresolved"` // reports that couldn't be placed; add them to a mapping file
+	Clans      []ImportClanSummary `json:"clans"`      // every clan in the checkpoint, including earlier runs
+}
+
+// ImportClanSummary lists the turns imported for one clan and the gaps
+// between the first and last of them.
+type ImportClanSummary struct {
+	Game    string         `json:"game"`
+	ClanNo  string         `json:"clan"`
+	Files   int            `json:"files"`
+	First   model.TurnNo   `json:"first"`
+	Last    model.TurnNo   `json:"last"`
+	Missing []model.TurnNo `json:"missing,omitempty"`
+}
+
+// Import ingests the reports under src, which is a directory or a .zip file.
+// Files are visited in lexical order and recorded in cp after each one; files
+// cp already has are skipped. Each file is ingested in a batch of its own.
+func (s *ImportService) Import(ctx context.Context, src string, cp *ImportCheckpoint) (*ImportSummary, error) {
+	sum := &ImportSummary{}
+	visit := func(rel string, open func() ([]byte, error)) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		base := path.Base(rel)
+		ext := strings.ToLower(path.Ext(base))
+		if strings.HasPrefix(base, ".") || strings.HasPrefix(rel, "__MACOSX/") || (ext != ".docx" && ext != ".txt") {
+			sum.Ignored++
+			return nil
+		}
+		if _, ok := cp.Files[rel]; ok {
+			sum.Resumed++
+			return nil
+		}
+
+		guess := s.lookup(rel).merge(InferReportPath(rel)).merge(s.defaults)
+		if !guess.Complete() && s.resolve != nil {
+			var ok bool
+			if guess, ok = s.resolve(rel, guess); !ok {
+				guess = ImportGuess{}
+			}
+		}
+		if !guess.Complete() {
+			logging.FromContext(ctx).Warn("import: archive: can't place report", logging.KeyFile, rel)
+			sum.Unresolved = append(sum.Unresolved, rel)
+			return nil
+		}
+
+		data, err := open()
+		if err != nil {
+			return err
+		}
+		_, results, err := s.ingest.IngestBatch(ctx, guess.Game, guess.ClanNo, guess.TurnNo, s.createdBy, []IngestRequest{
+			{Filename: base, Data: data},
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		entry := ImportedFile{ImportGuess: guess}
+		for _, r := range results {
+			entry.ReportFileID, entry.Duplicate = r.ReportFileID, r.Duplicate
+			if r.Duplicate {
+				sum.Duplicates++
+			} else {
+				sum.Ingested++
+			}
+		}
+		cp.Files[rel] = entry
+		return cp.Save()
+	}
+
+	var err error
+	if strings.EqualFold(filepath.Ext(src), ".zip") {
+		err = s.walkZip(src, visit)
+	} else {
+		err = s.walkDir(src, visit)
+	}
+	sum.Clans = cp.Reconcile()
+	return sum, err
+}
+
+// lookup returns the longest mapping that matches rel.
+func (s *ImportService) lookup(rel string) ImportGuess {
+	for _, m := range s.mappings {
+		if strings.HasPrefix(rel, m.Prefix) {
+			return m.ImportGuess
+		}
+	}
+	return ImportGuess{}
+}
+
+func (s *ImportService) walkDir(root string, visit func(string, func() ([]byte, error)) error) error {
+	return afero.Walk(s.fs, root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return &ErrWriteFile{Op: "walk", Path: p, Err: err}
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		return visit(filepath.ToSlash(rel), func() ([]byte, error) {
+			data, err := afero.ReadFile(s.fs, p)
+			if err != nil {
+				return nil, &ErrWriteFile{Op: "read", Path: p, Err: err}
+			}
+			return data, nil
+		})
+	})
+}
+
+// walkZip reads entries one at a time, so the archive can be larger than memory.
+func (s *ImportService) walkZip(src string, visit func(string, func() ([]byte, error)) error) error {
+	f, err := s.fs.Open(src)
+	if err != nil {
+		return &ErrWriteFile{Op: "open", Path: src, Err: err}
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return &ErrWriteFile{Op: "stat", Path: src, Err: err}
+	}
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	entries := append([]*zip.File(nil), zr.File...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	for _, zf := range entries {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		err := visit(zf.Name, func() ([]byte, error) {
+			rc, err := zf.Open()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", src, zf.Name, err)
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reconcile summarizes the checkpoint by clan, ordered by game and clan.
+func (cp *ImportCheckpoint) Reconcile() []ImportClanSummary {
+	type key struct{ game, clanNo string }
+	turns := map[key]map[model.TurnNo]bool{}
+	files := map[key]int{}
+	for _, f := range cp.Files {
+		k := key{f.Game, f.ClanNo}
+		if turns[k] == nil {
+			turns[k] = map[model.TurnNo]bool{}
+		}
+		turns[k][f.TurnNo] = true
+		files[k]++
+	}
+
+	var list []ImportClanSummary
+	for k, seen := range turns {
+		cs := ImportClanSummary{Game: k.game, ClanNo: k.clanNo, Files: files[k]}
+		for t := range seen {
+			if cs.First == 0 || t < cs.First {
+				cs.First = t
+			}
+			if t > cs.Last {
+				cs.Last = t
+			}
+		}
+		for t := cs.First; t < cs.Last; t = t.Next() {
+			if !seen[t] {
+				cs.Missing = append(cs.Missing, t)
+			}
+		}
+		list = append(list, cs)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Game != list[j].Game {
+			return list[i].Game < list[j].Game
+		}
+		return list[i].ClanNo < list[j].ClanNo
+	})
+	return list
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	"github.com/spf13/afero"
+)
+
+func TestInferReportPath(t *testing.T) {
+	testCases := []struct {
+		path string
+		want stages.ImportGuess
+	}{
+		{path: "old/0301.0899-12.0512.docx", want: stages.ImportGuess{Game: "0301", ClanNo: "0512", TurnNo: 89912}},
+		{path: "0301/0512/0899-12.docx", want: stages.ImportGuess{Game: "0301", ClanNo: "0512", TurnNo: 89912}},
+		{path: "0301/0512.0900-01.report.txt", want: stages.ImportGuess{Game: "0301", ClanNo: "0512", TurnNo: 90001}},
+		{path: "Game 301/2019/Clan 512/turn 900_02.docx", want: stages.ImportGuess{Game: "0301", ClanNo: "0512", TurnNo: 90002}},
+		{path: "reports/0512/90003.txt", want: stages.ImportGuess{ClanNo: "0512", TurnNo: 90003}},
+		{path: "reports/0512/notes.txt", want: stages.ImportGuess{ClanNo: "0512"}},
+		{path: "misc/readme.txt", want: stages.ImportGuess{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			if got := stages.InferReportPath(tc.path); got != tc.want {
+				t.Errorf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReadImportMappings_LongestPrefixFirst(t *testing.T) {
+	mappings, err := stages.ReadImportMappings(strings.NewReader(`# prefix,game,clan,turn
+old/,0301,,
+old/clan-b/,0301,0987,0900-01
+`))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(mappings) != 2 {
+		t.Fatalf("expected 2 mappings, got %d", len(mappings))
+	}
+	if mappings[0].Prefix != "old/clan-b/" || mappings[0].ClanNo != "0987" || mappings[0].TurnNo != 90001 {
+		t.Errorf("expected clan-b mapping first, got %+v", mappings[0])
+	}
+}
+
+func TestImportService_ImportDirResumes(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore()
+	fs := afero.NewMemMapFs()
+
+	ingest := stages.NewIngestService(store, "/data")
+	ingest.SetFS(fs)
+	importer := stages.NewImportService(ingest, "test-import")
+	importer.SetFS(fs)
+	importer.SetDefaults(stages.ImportGuess{Game: "0301"})
+
+	_ = afero.WriteFile(fs, "/archive/0512/0899-12.docx", []byte("turn 1"), 0644)
+	_ = afero.WriteFile(fs, "/archive/0512/0900-02.docx", []byte("turn 3"), 0644)
+	_ = afero.WriteFile(fs, "/archive/0512/copy of 0900-02.docx", []byte("turn 3"), 0644)
+	_ = afero.WriteFile(fs, "/archive/0512/notes.txt", []byte("which turn?"), 0644)
+	_ = afero.WriteFile(fs, "/archive/0512/map.png", []byte("image"), 0644)
+
+	cp, err := stages.LoadImportCheckpoint(fs, "/data/import.json", "/archive")
+	if err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	sum, err := importer.Import(ctx, "/archive", cp)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if sum.Ingested != 2 || sum.Duplicates != 1 || sum.Ignored != 1 || len(sum.Unresolved) != 1 {
+		t.Errorf("expected 2 ingested, 1 duplicate, 1 ignored, 1 unresolved, got %+v", sum)
+	}
+	if len(sum.Clans) != 1 || len(sum.Clans[0].Missing) != 1 || sum.Clans[0].Missing[0] != 90001 {
+		t.Errorf("expected clan 0512 to be missing 0900-01, got %+v", sum.Clans)
+	}
+
+	// a new run with a resolver picks up only what the checkpoint doesn't have
+	cp, err = stages.LoadImportCheckpoint(fs, "/data/import.json", "/archive")
+	if err != nil {
+		t.Fatalf("reload checkpoint: %v", err)
+	}
+	importer.SetResolver(func(path string, guess stages.ImportGuess) (stages.ImportGuess, bool) {
+		guess.TurnNo = 90001
+		return guess, true
+	})
+	sum, err = importer.Import(ctx, "/archive", cp)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if sum.Resumed != 3 || sum.Ingested != 1 {
+		t.Errorf("expected 3 resumed and 1 ingested, got %+v", sum)
+	}
+	if len(sum.Clans) != 1 || len(sum.Clans[0].Missing) != 0 {
+		t.Errorf("expected no missing turns, got %+v", sum.Clans)
+	}
+	if len(store.reportFiles) != 3 {
+		t.Errorf("expected 3 report files, got %d", len(store.reportFiles))
+	}
+}
+
+func TestImportService_ImportZip(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore()
+	fs := afero.NewMemMapFs()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range map[string]string{
+		"__MACOSX/0301/._0512.0899-12.docx": "resource fork",
+		"0301/0512.0899-12.docx":            "docx content",
+		"0301/0987.0899-12.report.txt":      "text content",
+	} {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte(body))
+	}
+	_ = zw.Close()
+	_ = afero.WriteFile(fs, "/uploads/old.zip", buf.Bytes(), 0644)
+
+	ingest := stages.NewIngestService(store, "/data")
+	ingest.SetFS(fs)
+	importer := stages.NewImportService(ingest, "test-import")
+	importer.SetFS(fs)
+
+	cp, err := stages.LoadImportCheckpoint(fs, "/data/import.json", "/uploads/old.zip")
+	if err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	sum, err := importer.Import(ctx, "/uploads/old.zip", cp)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if sum.Ingested != 2 || sum.Ignored != 1 {
+		t.Errorf("expected 2 ingested and 1 ignored, got %+v", sum)
+	}
+	for _, rf := range store.reportFiles {
+		if rf.Game != "0301" || rf.TurnNo != model.FirstTurnNo {
+			t.Errorf("%s: expected game 0301 turn 0899-12, got %s %s", rf.Name, rf.Game, rf.TurnNo)
+		}
+	}
+
+	if _, err := stages.LoadImportCheckpoint(fs, "/data/import.json", "/archive"); err == nil {
+		t.Errorf("expected an error loading a checkpoint for another source")
+	}
+}
@@ -9,8 +9,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
-	"time"
 
+	"github.com/mdhender/tnrpt/clock"
 	"github.com/mdhender/tnrpt/model"
 	"github.com/spf13/afero"
 )
@@ -20,6 +20,7 @@ type IngestService struct {
 	store   IngestStore
 	dataDir string
 	fs      afero.Fs
+	clock   clock.Clock
 }
 
 // IngestStore defines the store operations needed by IngestService.
@@ -37,21 +38,28 @@ func NewIngestService(store IngestStore, dataDir string) *IngestService {
 		store:   store,
 		dataDir: dataDir,
 		fs:      afero.NewOsFs(),
+		clock:   clock.Real,
 	}
 }
 
-// SetFS sets the filesystem for testing.
+// SetFS sets the filesystem the data directory is on (see NewFS).
+// The default is the OS filesystem.
 func (s *IngestService) SetFS(fs afero.Fs) {
 	s.fs = fs
 }
 
+// SetClock sets the clock for testing.
+func (s *IngestService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 // IngestRequest contains the parameters for ingesting a file.
 type IngestRequest struct {
-	Game     string // e.g., "0301"
-	ClanNo   string // e.g., "0512"
-	TurnNo   int    // e.g., 89912 (year 899, month 12)
-	Filename string // original filename
-	Data     []byte // file content
+	Game     string       // e.g., "0301"
+	ClanNo   string       // e.g., "0512"
+	TurnNo   model.TurnNo // e.g., 89912 (year 899, month 12)
+	Filename string       // original filename
+	Data     []byte       // file content
 }
 
 // IngestResult contains the result of an ingest operation.
@@ -64,6 +72,13 @@ type IngestResult struct {
 // IngestFile ingests a single file into the pipeline.
 // Returns IngestResult with Duplicate=true if the file already exists (idempotent no-op).
 func (s *IngestService) IngestFile(ctx context.Context, batchID int64, req IngestRequest) (*IngestResult, error) {
+	return s.ingestFile(ctx, batchID, req, 1)
+}
+
+// ingestFile ingests the seq'th file of a batch. Every file after the first
+// gets seq in its standard name, so that files in one batch never share a
+// name (or the .report.txt and .map.svg files derived from it).
+func (s *IngestService) ingestFile(ctx context.Context, batchID int64, req IngestRequest, seq int) (*IngestResult, error) {
 	hash := sha256.Sum256(req.Data)
 	hashStr := hex.EncodeToString(hash[:])
 
@@ -80,15 +95,16 @@ func (s *IngestService) IngestFile(ctx context.Context, batchID int64, req Inges
 
 	ext := strings.ToLower(filepath.Ext(req.Filename))
 	mime := detectMime(ext)
-	stdName := formatStandardFilename(req.Game, req.TurnNo, req.ClanNo, ext)
+	stdName := formatStandardFilename(req.Game, req.TurnNo, req.ClanNo, seq, ext)
 
 	fsPath := filepath.Join("batches", fmt.Sprintf("%d", batchID), stdName)
 	fullPath := filepath.Join(s.dataDir, fsPath)
 
-	if err := s.fs.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+	fsys := ContextFS(ctx, s.fs)
+	if err := fsys.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 		return nil, &ErrWriteFile{Op: "mkdir", Path: filepath.Dir(fullPath), Err: err}
 	}
-	if err := afero.WriteFile(s.fs, fullPath, req.Data, 0644); err != nil {
+	if err := afero.WriteFile(fsys, fullPath, req.Data, 0644); err != nil {
 		return nil, &ErrWriteFile{Op: "write", Path: fullPath, Err: err}
 	}
 
@@ -99,7 +115,7 @@ func (s *IngestService) IngestFile(ctx context.Context, batchID int64, req Inges
 		Name:      stdName,
 		SHA256:    hashStr,
 		Mime:      mime,
-		CreatedAt: time.Now().UTC(),
+		CreatedAt: s.clock.Now().UTC(),
 		FsPath:    fsPath,
 		BatchID:   &batchID,
 	}
@@ -114,7 +130,7 @@ func (s *IngestService) IngestFile(ctx context.Context, batchID int64, req Inges
 		Stage:        stage,
 		Status:       model.WorkStatusQueued,
 		Attempt:      0,
-		AvailableAt:  time.Now().UTC(),
+		AvailableAt:  s.clock.Now().UTC(),
 	}
 	workID, err := s.store.InsertWork(ctx, work)
 	if err != nil {
@@ -129,13 +145,13 @@ func (s *IngestService) IngestFile(ctx context.Context, batchID int64, req Inges
 }
 
 // IngestBatch creates a batch and ingests multiple files.
-func (s *IngestService) IngestBatch(ctx context.Context, game, clanNo string, turnNo int, createdBy string, files []IngestRequest) (int64, []IngestResult, error) {
+func (s *IngestService) IngestBatch(ctx context.Context, game, clanNo string, turnNo model.TurnNo, createdBy string, files []IngestRequest) (int64, []IngestResult, error) {
 	batch := &model.UploadBatch{
 		Game:      game,
 		ClanNo:    clanNo,
 		TurnNo:    turnNo,
 		CreatedBy: createdBy,
-		CreatedAt: time.Now().UTC(),
+		CreatedAt: s.clock.Now().UTC(),
 	}
 	batchID, err := s.store.InsertUploadBatch(ctx, batch)
 	if err != nil {
@@ -143,11 +159,11 @@ func (s *IngestService) IngestBatch(ctx context.Context, game, clanNo string, tu
 	}
 
 	var results []IngestResult
-	for _, file := range files {
+	for i, file := range files {
 		file.Game = game
 		file.ClanNo = clanNo
 		file.TurnNo = turnNo
-		result, err := s.IngestFile(ctx, batchID, file)
+		result, err := s.ingestFile(ctx, batchID, file, i+1)
 		if err != nil {
 			return batchID, results, err
 		}
@@ -158,11 +174,13 @@ func (s *IngestService) IngestBatch(ctx context.Context, game, clanNo string, tu
 }
 
 // formatStandardFilename generates the standard filename: GGGG.YYYY-MM.CCCC.{ext}
-// Example: 0301.899-12.0512.docx
-func formatStandardFilename(game string, turnNo int, clanNo string, ext string) string {
-	year := turnNo / 100
-	month := turnNo % 100
-	return fmt.Sprintf("%s.%03d-%02d.%s%s", game, year, month, clanNo, ext)
+// for the first file of a batch, and GGGG.YYYY-MM.CCCC.{seq}.{ext} for the rest.
+// Example: 0301.899-12.0512.docx, 0301.899-12.0512.2.docx
+func formatStandardFilename(game string, turnNo model.TurnNo, clanNo string, seq int, ext string) string {
+	if seq > 1 {
+		return fmt.Sprintf("%s.%03d-%02d.%s.%d%s", game, turnNo.Year(), turnNo.Month(), clanNo, seq, ext)
+	}
+	return fmt.Sprintf("%s.%03d-%02d.%s%s", game, turnNo.Year(), turnNo.Month(), clanNo, ext)
 }
 
 // determineStage returns the initial pipeline stage based on file extension.
@@ -275,4 +275,24 @@ func TestIngestService_IngestBatch(t *testing.T) {
 	if parseCount != 1 {
 		t.Errorf("expected 1 parse job, got %d", parseCount)
 	}
+
+	// files with the same extension must not overwrite each other
+	wantPaths := []string{
+		"batches/1/0301.899-12.0512.docx",
+		"batches/1/0301.899-12.0512.2.docx",
+		"batches/1/0301.899-12.0512.3.txt",
+	}
+	for i, res := range results {
+		rf := store.reportFiles[res.ReportFileID]
+		if rf.FsPath != wantPaths[i] {
+			t.Errorf("file %d: fs_path = %q, want %q", i, rf.FsPath, wantPaths[i])
+			continue
+		}
+		data, err := afero.ReadFile(fs, "/data/"+rf.FsPath)
+		if err != nil {
+			t.Errorf("file %d: %v", i, err)
+		} else if string(data) != string(files[i].Data) {
+			t.Errorf("file %d: stored %q, want %q", i, data, files[i].Data)
+		}
+	}
 }
@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+var (
+	// rxMasterSection matches a unit section header, capturing the tribe
+	// number. All but its first digit are the clan (1987 and 0987c1 are
+	// both units of clan 0987).
+	rxMasterSection = regexp.MustCompile(`^(?:Courier|Element|Fleet|Garrison|Tribe) (` + model.TribePattern + `)(?:[cefg]\d)?, `)
+	rxMasterTurn    = regexp.MustCompile(`^Current Turn (\d{3,4}-\d{2}) `)
+)
+
+// ClanReport is one clan's part of a GM master file.
+type ClanReport struct {
+	ClanNo    string       // e.g., "0987"
+	TurnNo    model.TurnNo // from the clan's "Current Turn" line; zero if there isn't one
+	FirstLine int          // 1-based line of the clan's first section in the master file
+	Text      []byte
+}
+
+// SplitMasterReport splits a GM master file, the text reports of many clans
+// one after another, into one report per clan. A clan's report starts at the
+// first section header of a unit in that clan and runs until a section for
+// another clan starts; anything before the first header (a GM banner, say)
+// is dropped.
+//
+// Each clan's sections must be together. A clan that shows up again after
+// another clan's sections is an error rather than a guess, since putting a
+// unit in the wrong clan is worse than not loading it.
+//
+// For files too big to hold every clan's report at once, see ScanMasterReport.
+func SplitMasterReport(data []byte) ([]ClanReport, error) {
+	var reports []ClanReport
+	err := ScanMasterReport(bytes.NewReader(data), func(cr ClanReport) error {
+		reports = append(reports, cr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// ScanMasterReport splits a GM master file read from r like
+// SplitMasterReport, but calls fn with each clan's report as soon as it ends
+// instead of returning them all. It holds one clan's report at a time, plus
+// the line each clan started on, so memory is bounded by the biggest clan
+// rather than the file. fn may keep the report it is given.
+//
+// The reports before an error in the file have already been passed to fn.
+// An error from fn stops the scan and is returned as it is.
+func ScanMasterReport(r io.Reader, fn func(ClanReport) error) error {
+	seen := map[string]int{} // clan -> its first line
+	var cur *ClanReport
+	br := bufio.NewReader(r)
+	for i := 0; ; i++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("master: line %d: %w", i+1, err)
+		}
+		if len(line) == 0 && err != nil {
+			break
+		}
+		text := bytes.TrimRight(line, "\r\n")
+		if m := rxMasterSection.FindSubmatch(text); m != nil {
+			clanNo := "0" + string(m[1][1:])
+			if cur == nil || cur.ClanNo != clanNo {
+				if cur != nil {
+					if err := fn(*cur); err != nil {
+						return err
+					}
+				}
+				if first, ok := seen[clanNo]; ok {
+					return fmt.Errorf("master: line %d: clan %s starts again after line %d; its sections must be together", i+1, clanNo, first)
+				}
+				seen[clanNo] = i + 1
+				cur = &ClanReport{ClanNo: clanNo, FirstLine: i + 1}
+			}
+		} else if m := rxMasterTurn.FindSubmatch(text); m != nil && cur != nil && cur.TurnNo == 0 {
+			turnNo, err := model.ParseTurnNo(string(m[1]))
+			if err != nil {
+				return fmt.Errorf("master: line %d: %w", i+1, err)
+			}
+			cur.TurnNo = turnNo
+		}
+		if cur != nil {
+			cur.Text = append(cur.Text, line...)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if cur == nil {
+		return fmt.Errorf("master: no unit sections found")
+	}
+	return fn(*cur)
+}
+
+// MasterResult is what became of one clan's report from a master file.
+type MasterResult struct {
+	ClanReport
+	BatchID int64
+	IngestResult
+}
+
+// IngestMaster splits a GM master file (see SplitMasterReport) and ingests
+// each clan's report in a batch of its own, so every report is attributed
+// to its clan. The reports are saved as text and queued for the parse stage.
+//
+// If turnNo is zero, each clan's turn is taken from its "Current Turn"
+// line. Otherwise every clan that has that line must agree with it. All
+// clans are checked before anything is ingested.
+func (s *IngestService) IngestMaster(ctx context.Context, game string, turnNo model.TurnNo, createdBy, filename string, data []byte) ([]MasterResult, error) {
+	return s.IngestMasterReader(ctx, game, turnNo, createdBy, filename, bytes.NewReader(data))
+}
+
+// IngestMasterReader is IngestMaster for a master file read from r. It reads
+// the file twice, once to check every clan and again to ingest them, and
+// holds only one clan's report at a time (see ScanMasterReport), so a big
+// file can be ingested from disk without loading it.
+func (s *IngestService) IngestMasterReader(ctx context.Context, game string, turnNo model.TurnNo, createdBy, filename string, r io.ReadSeeker) ([]MasterResult, error) {
+	turnOf := func(cr ClanReport) (model.TurnNo, error) {
+		switch {
+		case cr.TurnNo == 0 && turnNo == 0:
+			return 0, fmt.Errorf("master: clan %s: no Current Turn line; give the turn", cr.ClanNo)
+		case cr.TurnNo == 0:
+			return turnNo, nil
+		case turnNo != 0 && cr.TurnNo != turnNo:
+			return 0, fmt.Errorf("master: clan %s: report is for turn %s, not %s", cr.ClanNo, cr.TurnNo, turnNo)
+		}
+		return cr.TurnNo, nil
+	}
+	err := ScanMasterReport(r, func(cr ClanReport) error {
+		_, err := turnOf(cr)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("master: %w", err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	var results []MasterResult
+	err = ScanMasterReport(r, func(cr ClanReport) error {
+		cr.TurnNo, _ = turnOf(cr) // checked above
+		batchID, ingested, err := s.IngestBatch(ctx, game, cr.ClanNo, cr.TurnNo, createdBy, []IngestRequest{
+			{Filename: base + "." + cr.ClanNo + ".txt", Data: cr.Text},
+		})
+		if err != nil {
+			return fmt.Errorf("master: clan %s: %w", cr.ClanNo, err)
+		}
+		results = append(results, MasterResult{ClanReport: cr, BatchID: batchID, IngestResult: ingested[0]})
+		return nil
+	})
+	return results, err
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	"github.com/spf13/afero"
+)
+
+const masterText = "GM master file for turn 900-01\r\n" +
+	"\r\n" +
+	"Tribe 0512, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)\r\n" +
+	"Current Turn 900-01 (#1), Winter, FINE Next Turn 900-02 (#2), 15/12/2025\r\n" +
+	"\r\n" +
+	"Courier 0512c1, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)\r\n" +
+	"\r\n" +
+	"Tribe 0987, , Current Hex = QQ 1212, (Previous Hex = QQ 1212)\r\n" +
+	"Current Turn 900-01 (#1), Winter, FINE Next Turn 900-02 (#2), 15/12/2025\r\n" +
+	"\r\n" +
+	"Tribe 1987, , Current Hex = QQ 1213, (Previous Hex = QQ 1213)\r\n"
+
+func TestSplitMasterReport(t *testing.T) {
+	reports, err := stages.SplitMasterReport([]byte(masterText))
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 clans, got %d", len(reports))
+	}
+	if reports[0].ClanNo != "0512" || reports[0].FirstLine != 3 || reports[0].TurnNo != 90001 {
+		t.Errorf("first clan: got %s at line %d, turn %s", reports[0].ClanNo, reports[0].FirstLine, reports[0].TurnNo)
+	}
+	if !strings.HasPrefix(string(reports[0].Text), "Tribe 0512, ") || !strings.Contains(string(reports[0].Text), "Courier 0512c1") {
+		t.Errorf("first clan: expected its tribe and courier, got %q", reports[0].Text)
+	}
+	if strings.Contains(string(reports[0].Text), "0987") {
+		t.Errorf("first clan: text runs into the next clan: %q", reports[0].Text)
+	}
+	if reports[1].ClanNo != "0987" || !strings.Contains(string(reports[1].Text), "Tribe 1987") {
+		t.Errorf("second clan: expected 0987 with tribe 1987, got %s %q", reports[1].ClanNo, reports[1].Text)
+	}
+}
+
+func TestSplitMasterReport_Errors(t *testing.T) {
+	for name, text := range map[string]string{
+		"no sections":     "just a note from the GM\n",
+		"clan split up":   "Tribe 0512, , Current Hex = QQ 1010\nTribe 0987, , Current Hex = QQ 1212\nElement 0512e1, , Current Hex = QQ 1010\n",
+		"bad turn number": "Tribe 0512, , Current Hex = QQ 1010\nCurrent Turn 900-13 (#1), Winter\n",
+	} {
+		if _, err := stages.SplitMasterReport([]byte(text)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestScanMasterReport(t *testing.T) {
+	var clans []string
+	stop := errors.New("stop")
+	err := stages.ScanMasterReport(strings.NewReader(masterText), func(cr stages.ClanReport) error {
+		clans = append(clans, cr.ClanNo)
+		if cr.ClanNo == "0512" && !strings.HasSuffix(string(cr.Text), "Courier 0512c1, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)\r\n\r\n") {
+			t.Errorf("first clan: got %q", cr.Text)
+		}
+		return stop
+	})
+	if err != stop || len(clans) != 1 {
+		t.Errorf("expected the scan to stop after the first clan, got %v after %v", err, clans)
+	}
+
+	// a file without a final line-feed keeps its last line
+	reports, err := stages.SplitMasterReport([]byte(strings.TrimSuffix(masterText, "\r\n")))
+	if err != nil || !strings.HasSuffix(string(reports[1].Text), "(Previous Hex = QQ 1213)") {
+		t.Errorf("last clan: got %v", err)
+	}
+}
+
+func TestIngestService_IngestMaster(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore()
+	fs := afero.NewMemMapFs()
+
+	svc := stages.NewIngestService(store, "/data")
+	svc.SetFS(fs)
+
+	if _, err := svc.IngestMaster(ctx, "0301", 90002, "test", "master.txt", []byte(masterText)); err == nil {
+		t.Fatalf("expected an error when the turn disagrees with the report")
+	}
+	if len(store.batches) != 0 {
+		t.Fatalf("expected nothing ingested after a turn mismatch, got %d batches", len(store.batches))
+	}
+
+	results, err := svc.IngestMaster(ctx, "0301", 0, "test", "master.txt", []byte(masterText))
+	if err != nil {
+		t.Fatalf("ingest master: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		batch := store.batches[r.BatchID]
+		rf := store.reportFiles[r.ReportFileID]
+		if batch == nil || rf == nil {
+			t.Fatalf("clan %s: batch or report file missing", r.ClanNo)
+		}
+		if batch.ClanNo != r.ClanNo || rf.ClanNo != r.ClanNo || rf.TurnNo != 90001 {
+			t.Errorf("clan %s: batch clan %s, file clan %s turn %s", r.ClanNo, batch.ClanNo, rf.ClanNo, rf.TurnNo)
+		}
+		if store.work[r.WorkID].Stage != model.WorkStageParse {
+			t.Errorf("clan %s: expected parse work, got %s", r.ClanNo, store.work[r.WorkID].Stage)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/pipelines/parsers/report"
+)
+
+// ParseCacheVersion is the version part of a parse cache key. The cached
+// result depends on the parser and on the programs that made its input: the
+// docx extractor and, for reports that are split into sections first, the
+// report splitter. A change to any of them bumps its Version, and entries
+// made under the old versions are no longer found.
+func ParseCacheVersion() string {
+	return fmt.Sprintf("bistre %s, docx %s, report %s", bistre.Version, docx.Version, report.Version)
+}
+
+// parseCacheKey is the parse cache version for a parse with cfg. The
+// experimental fixes change the parser's output, so results made with them
+// are cached apart from the default ones.
+func parseCacheKey(cfg bistre.ParseConfig) string {
+	key := ParseCacheVersion()
+	if cfg.Experimental.UnitSplit {
+		key += ", unit-split"
+	}
+	if cfg.Experimental.ScoutStill {
+		key += ", scout-still"
+	}
+	return key
+}
+
+// EncodeParseCache returns a parse result as gzipped JSON for the parse cache.
+func EncodeParseCache(turn *bistre.Turn_t) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(turn); err != nil {
+		return nil, fmt.Errorf("encode parse cache: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("encode parse cache: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeParseCache returns the parse result from an EncodeParseCache entry.
+func DecodeParseCache(data []byte) (*bistre.Turn_t, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode parse cache: %w", err)
+	}
+	js, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("decode parse cache: %w", err)
+	}
+	var turn bistre.Turn_t
+	if err := json.Unmarshal(js, &turn); err != nil {
+		return nil, fmt.Errorf("decode parse cache: %w", err)
+	}
+	if turn.UnitMoves == nil {
+		turn.UnitMoves = map[bistre.UnitId_t]*bistre.Moves_t{}
+	}
+	return &turn, nil
+}
+
+// cachedParse returns the cached parse result for the report's content and
+// the cache version (see parseCacheKey), or nil if there isn't one. The cache
+// is optimistic: an entry that can't be read is logged and ignored, and the
+// report is parsed again.
+func (w *WorkerService) cachedParse(ctx context.Context, rf *model.ReportFile, version string) *bistre.Turn_t {
+	if rf.SHA256 == "" {
+		return nil
+	}
+	data, err := w.store.ParseCache(ctx, rf.SHA256, version)
+	if err != nil {
+		logging.FromContext(ctx).Warn("pipeline: parse cache", logging.KeyReportFileID, rf.ID, "err", err)
+		return nil
+	} else if data == nil {
+		return nil
+	}
+	turn, err := DecodeParseCache(data)
+	if err != nil {
+		logging.FromContext(ctx).Warn("pipeline: parse cache", logging.KeyReportFileID, rf.ID, "err", err)
+		return nil
+	}
+	logging.FromContext(ctx).Debug("pipeline: parse cache hit", logging.KeyReportFileID, rf.ID, logging.KeyFile, rf.Name)
+	return turn
+}
+
+// saveParse adds a parse result to the cache. Failing to save it isn't an
+// error; the next report with the same content is parsed again.
+func (w *WorkerService) saveParse(ctx context.Context, rf *model.ReportFile, version string, turn *bistre.Turn_t) {
+	if rf.SHA256 == "" {
+		return
+	}
+	data, err := EncodeParseCache(turn)
+	if err == nil {
+		err = w.store.SaveParseCache(ctx, rf.SHA256, version, data, w.clock.Now())
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("pipeline: parse cache", logging.KeyReportFileID, rf.ID, "err", err)
+	}
+}
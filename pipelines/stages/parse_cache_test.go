@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/afero"
+)
+
+// TestParseCache_RoundTrip checks that a cached parse result adapts to the
+// same extract as the result it was made from.
+func TestParseCache_RoundTrip(t *testing.T) {
+	for _, name := range []string{"0899-12.0987.report.txt", "0900-01.0987.report.txt"} {
+		data, err := os.ReadFile(filepath.Join("..", "..", "testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parse := func() *bistre.Turn_t {
+			turn, err := bistre.ParseInput(name, "", data, bistre.ParseConfig{AcceptLoneDash: true})
+			if err != nil {
+				t.Fatalf("%s: parse: %v", name, err)
+			}
+			return turn
+		}
+		cached, err := stages.EncodeParseCache(parse())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		turn, err := stages.DecodeParseCache(cached)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		extract := func(turn *bistre.Turn_t) string {
+			rx, err := adapters.BistreTurnToModelReportX(name, turn, "0301", "0987", model.DefaultUnitIDRules, nil)
+			if err != nil {
+				t.Fatalf("%s: adapt: %v", name, err)
+			}
+			if len(rx.Units) == 0 {
+				t.Fatalf("%s: expected units", name)
+			}
+			rx.CreatedAt = time.Time{}
+			sort.Slice(rx.Units, func(i, j int) bool { return rx.Units[i].UnitID < rx.Units[j].UnitID })
+			js, err := json.Marshal(rx)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			return string(js)
+		}
+		if want, got := extract(parse()), extract(turn); got != want {
+			t.Errorf("%s: cached result adapts differently:\n got %s\nwant %s", name, got, want)
+		}
+	}
+}
+
+func TestWorkerService_ExecuteParse_Cache(t *testing.T) {
+	ctx := context.Background()
+	sqlStore, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer sqlStore.Close()
+
+	report, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0899-12.0987.report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(report)
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/data/batches/1/0301.0899-12.0987.report.txt", report, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the same report in another game, where extraction went wrong; only the
+	// cached result can give it units
+	if err := afero.WriteFile(fsys, "/data/batches/2/0302.0899-12.0987.report.txt", []byte("garbage\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	worker := stages.NewWorkerService(sqlStore, "/data", "test")
+	worker.SetFS(fsys)
+	units := map[string]int{}
+	for _, tc := range []struct {
+		game, path string
+	}{
+		{"0301", "batches/1/0301.0899-12.0987.report.txt"},
+		{"0302", "batches/2/0302.0899-12.0987.docx"},
+	} {
+		rf := &model.ReportFile{
+			Game:      tc.game,
+			ClanNo:    "0987",
+			TurnNo:    89912,
+			Name:      filepath.Base(tc.path),
+			SHA256:    hex.EncodeToString(hash[:]),
+			Mime:      "text/plain",
+			CreatedAt: time.Now().UTC(),
+			FsPath:    tc.path,
+		}
+		if rf.ID, err = sqlStore.InsertReportFileWithBatch(ctx, rf); err != nil {
+			t.Fatalf("insert report file: %v", err)
+		}
+		if err := worker.ExecuteParse(ctx, &model.Work{ReportFileID: rf.ID}, rf); err != nil {
+			t.Fatalf("%s: parse: %v", tc.game, err)
+		}
+		rxs, err := sqlStore.ReportExtractsByGameTurn(ctx, tc.game, 89912)
+		if err != nil || len(rxs) != 1 {
+			t.Fatalf("%s: expected one extract, got %d, %v", tc.game, len(rxs), err)
+		}
+		units[tc.game] = len(rxs[0].Units)
+	}
+	if units["0301"] == 0 || units["0302"] != units["0301"] {
+		t.Errorf("expected the cached parse to give the same units, got %v", units)
+	}
+
+	if data, err := sqlStore.ParseCache(ctx, hex.EncodeToString(hash[:]), stages.ParseCacheVersion()); err != nil || data == nil {
+		t.Errorf("expected a cache entry, got %v", err)
+	}
+}
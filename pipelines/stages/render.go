@@ -0,0 +1,283 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/terrain"
+	"github.com/spf13/afero"
+)
+
+// ExecuteRender draws the clan's known map as of the report's turn and writes
+// it next to the report as a .map.svg file. The map is built by walking every
+// step in the clan's reports up to the turn (see model.NewKnownMap), so it
+// includes the report that was just parsed. The file is recorded in the store
+// so the web server can link it from the turn pages.
+func (w *WorkerService) ExecuteRender(ctx context.Context, job *model.Work, rf *model.ReportFile) error {
+	clanNo, err := strconv.Atoi(rf.ClanNo)
+	if err != nil {
+		return fmt.Errorf("invalid clan %q", rf.ClanNo)
+	}
+
+	km, err := w.store.KnownMapByGameClan(ctx, rf.Game, clanNo, rf.TurnNo)
+	if err != nil {
+		return &ErrDatabase{Op: "load known map", Err: err}
+	}
+	title := fmt.Sprintf("Game %s clan %s turn %s", rf.Game, rf.ClanNo, rf.TurnNo)
+	svg := RenderMapSVG(km, title)
+
+	ext := filepath.Ext(rf.FsPath)
+	relPath := strings.TrimSuffix(rf.FsPath, ext) + ".map.svg"
+	fullPath := filepath.Join(w.dataDir, relPath)
+	if err := afero.WriteFile(ContextFS(ctx, w.fs), fullPath, svg, 0644); err != nil {
+		return &ErrWriteFile{Op: "write", Path: fullPath, Err: err}
+	}
+
+	if err := w.store.SaveClanMap(ctx, &model.ClanMap{
+		ReportFileID: rf.ID,
+		Game:         rf.Game,
+		ClanNo:       clanNo,
+		TurnNo:       rf.TurnNo,
+		FsPath:       relPath,
+		Hexes:        len(km.Hexes()),
+		CreatedAt:    w.clock.Now().UTC(),
+	}); err != nil {
+		return &ErrDatabase{Op: "save clan map", Err: err}
+	}
+	return nil
+}
+
+// mapHexSize is the distance in pixels from a hex's center to its corners.
+const mapHexSize = 12.0
+
+// terrainFill is the color each kind of terrain is drawn in.
+func terrainFill(t terrain.Terrain_e) string {
+	switch {
+	case t == terrain.WaterOcean:
+		return "#2b6cb0"
+	case t.IsAnyWater():
+		return "#63b3ed"
+	case t.IsAnyMountain() || t == terrain.UnknownMountain:
+		return "#8d6e63"
+	case t.IsSwamp() || t == terrain.UnknownJungleSwamp:
+		return "#4a7c59"
+	case t.IsJungle():
+		return "#2f855a"
+	case t == terrain.FlatPolarIce || t == terrain.HillsSnowy || t == terrain.FlatTundra:
+		return "#e2e8f0"
+	case t == terrain.FlatDesert || t == terrain.FlatArid || t == terrain.HillsArid:
+		return "#ecc94b"
+	case t == terrain.FlatDeciduous || t == terrain.HillsDeciduous || t == terrain.HillsConifer:
+		return "#38a169"
+	case t.IsAnyLand():
+		return "#9ae6b4"
+	}
+	return "#cbd5e0"
+}
+
+// RenderMapSVG draws the hexes with known terrain as flat-top hexes in an SVG
+// image, labeled with their terrain code, with known rivers along their
+// edges. Each hex has a title with its coordinates. Hexes only seen from a
+// neighboring hex (see model.HexInferred) are faded with a dashed outline.
+// Hexes are placed on the global map, so maps that cross grids are drawn as
+// one piece.
+func RenderMapSVG(km *model.KnownMap, title string) []byte {
+	type placed struct {
+		tn   model.TNCoord
+		x, y float64
+	}
+	var hexes []placed
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, tn := range km.Hexes() {
+		x, y, ok := hexCenter(tn)
+		if !ok {
+			continue
+		}
+		hexes = append(hexes, placed{tn, x, y})
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+
+	var b bytes.Buffer
+	if len(hexes) == 0 {
+		b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="320" height="40" viewBox="0 0 320 40">` + "\n")
+		fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+		b.WriteString(`<text x="8" y="24" font-family="sans-serif" font-size="14">No hexes have been observed yet.</text>` + "\n")
+		b.WriteString("</svg>\n")
+		return b.Bytes()
+	}
+
+	const margin = 2 * mapHexSize
+	width, height := maxX-minX+2*margin, maxY-minY+2*margin
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="%.1f %.1f %.1f %.1f">`+"\n",
+		width, height, minX-margin, minY-margin, width, height)
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	b.WriteString(`<g stroke="#4a5568" stroke-width="0.5" font-family="sans-serif" font-size="6" text-anchor="middle">` + "\n")
+	for _, h := range hexes {
+		t := km.Terrain(h.tn)
+		title, faded := fmt.Sprintf("%s %s", h.tn, t), ""
+		if km.Knowledge(h.tn) == model.HexInferred {
+			title += " (seen from a neighboring hex)"
+			faded = ` fill-opacity="0.5" stroke-dasharray="2 1"`
+		}
+		fmt.Fprintf(&b, `<g><title>%s</title><polygon points="%s" fill="%s"%s/><text x="%.1f" y="%.1f" stroke="none">%s</text></g>`+"\n",
+			title, hexPoints(h.x, h.y), terrainFill(t), faded, h.x, h.y+2, t)
+	}
+	b.WriteString("</g>\n")
+
+	// rivers are drawn on top so neighboring hexes don't cover them
+	b.WriteString(`<g stroke="#1a365d" stroke-width="2" stroke-linecap="round">` + "\n")
+	for _, h := range hexes {
+		for i, d := range direction.Directions {
+			if !km.River(h.tn, d) {
+				continue
+			}
+			// draw each river once, from the hex it's north, northeast, or southeast of
+			if to, err := km.Neighbor(h.tn, d); err == nil && i >= 3 {
+				if _, _, ok := hexCenter(to); ok && km.Terrain(to) != terrain.Blank {
+					continue
+				}
+			}
+			x1, y1 := hexCorner(h.x, h.y, edgeCorners[d][0])
+			x2, y2 := hexCorner(h.x, h.y, edgeCorners[d][1])
+			fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"/>`+"\n", x1, y1, x2, y2)
+		}
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.Bytes()
+}
+
+// RenderPublicMapSVG draws a clan's public turn summary (see
+// model.PublicSummary) like RenderMapSVG, but with hexes placed relative to
+// the summary's origin and no coordinates anywhere in the image. Hexes first
+// seen in the turn are outlined, and the clan's units are drawn as numbered
+// markers. A hex's title lists what was seen in it during the turn.
+func RenderPublicMapSVG(ps *model.PublicSummary, title string) []byte {
+	var b bytes.Buffer
+	if len(ps.Hexes) == 0 {
+		b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="320" height="40" viewBox="0 0 320 40">` + "\n")
+		fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+		b.WriteString(`<text x="8" y="24" font-family="sans-serif" font-size="14">No hexes have been observed yet.</text>` + "\n")
+		b.WriteString("</svg>\n")
+		return b.Bytes()
+	}
+
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, h := range ps.Hexes {
+		x, y := publicHexCenter(h.X, h.Y)
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	const margin = 2 * mapHexSize
+	width, height := maxX-minX+2*margin, maxY-minY+2*margin
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="%.1f %.1f %.1f %.1f">`+"\n",
+		width, height, minX-margin, minY-margin, width, height)
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	b.WriteString(`<g stroke="#4a5568" stroke-width="0.5" font-family="sans-serif" font-size="6" text-anchor="middle">` + "\n")
+	for _, h := range ps.Hexes {
+		x, y := publicHexCenter(h.X, h.Y)
+		var seen []string
+		if h.Terrain != terrain.Blank {
+			seen = append(seen, h.Terrain.String())
+		}
+		seen = append(seen, h.Settlements...)
+		seen = append(seen, h.Resources...)
+		if h.Encounters > 0 {
+			seen = append(seen, fmt.Sprintf("%d units seen", h.Encounters))
+		}
+		label := ""
+		if h.Terrain != terrain.Blank {
+			label = h.Terrain.String()
+		}
+		fmt.Fprintf(&b, `<g><title>%s</title><polygon points="%s" fill="%s"/><text x="%.1f" y="%.1f" stroke="none">%s</text></g>`+"\n",
+			html.EscapeString(strings.Join(seen, ", ")), hexPoints(x, y), terrainFill(h.Terrain), x, y+2, label)
+	}
+	b.WriteString("</g>\n")
+
+	// outline the hexes first seen in the turn on top of their neighbors
+	b.WriteString(`<g fill="none" stroke="#c53030" stroke-width="1.5">` + "\n")
+	for _, h := range ps.Hexes {
+		if h.New {
+			x, y := publicHexCenter(h.X, h.Y)
+			fmt.Fprintf(&b, `<polygon points="%s"/>`+"\n", hexPoints(x, y))
+		}
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString(`<g stroke="#1a365d" stroke-width="2" stroke-linecap="round">` + "\n")
+	for _, h := range ps.Hexes {
+		x, y := publicHexCenter(h.X, h.Y)
+		for _, d := range h.Rivers {
+			x1, y1 := hexCorner(x, y, edgeCorners[d][0])
+			x2, y2 := hexCorner(x, y, edgeCorners[d][1])
+			fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"/>`+"\n", x1, y1, x2, y2)
+		}
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString(`<g fill="#ffffff" stroke="#1a202c" stroke-width="0.75" font-family="sans-serif" font-size="5" text-anchor="middle">` + "\n")
+	for i, u := range ps.Units {
+		x, y := publicHexCenter(u.X, u.Y)
+		y -= mapHexSize / 2 // above the terrain label
+		fmt.Fprintf(&b, `<g><title>%s</title><circle cx="%.1f" cy="%.1f" r="3.5"/><text x="%.1f" y="%.1f" fill="#1a202c" stroke="none">%d</text></g>`+"\n",
+			html.EscapeString(fmt.Sprintf("%s (%s), moved %d", u.Label, u.Kind, u.Moves)), x, y, x, y+1.75, i+1)
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.Bytes()
+}
+
+// publicHexCenter is hexCenter for a hex in a public summary, whose columns
+// keep the parity of the global map's.
+func publicHexCenter(col, row int) (x, y float64) {
+	return 1.5 * mapHexSize * float64(col), math.Sqrt(3) * mapHexSize * (float64(row) + 0.5*float64(col%2))
+}
+
+// hexCenter returns the pixel center of a hex on the global map. TribeNet maps
+// are flat-top hexes in columns, with even-numbered columns shoved down half
+// a hex. Grids are 30 columns wide and 21 rows tall.
+func hexCenter(tn model.TNCoord) (x, y float64, ok bool) {
+	grid, col, row, err := tn.Parse()
+	if err != nil || len(grid) != 2 || grid[0] < 'A' || grid[0] > 'Z' || grid[1] < 'A' || grid[1] > 'Z' {
+		return 0, 0, false
+	}
+	gc := int(grid[1]-'A')*30 + col - 1
+	gr := int(grid[0]-'A')*21 + row - 1
+	x = 1.5 * mapHexSize * float64(gc)
+	y = math.Sqrt(3) * mapHexSize * (float64(gr) + 0.5*float64(gc%2))
+	return x, y, true
+}
+
+// edgeCorners are the corners, as multiples of 60 degrees clockwise from
+// east, at the ends of the edge crossed when leaving a hex in a direction.
+var edgeCorners = map[direction.Direction_e][2]int{
+	direction.North:     {4, 5},
+	direction.NorthEast: {5, 0},
+	direction.SouthEast: {0, 1},
+	direction.South:     {1, 2},
+	direction.SouthWest: {2, 3},
+	direction.NorthWest: {3, 4},
+}
+
+func hexCorner(x, y float64, i int) (float64, float64) {
+	rad := math.Pi / 3 * float64(i)
+	return x + mapHexSize*math.Cos(rad), y + mapHexSize*math.Sin(rad)
+}
+
+func hexPoints(x, y float64) string {
+	var pts []string
+	for i := 0; i < 6; i++ {
+		cx, cy := hexCorner(x, y, i)
+		pts = append(pts, fmt.Sprintf("%.1f,%.1f", cx, cy))
+	}
+	return strings.Join(pts, " ")
+}
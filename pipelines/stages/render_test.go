@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+)
+
+func TestRenderMapSVG(t *testing.T) {
+	status := func(tn model.TNCoord, terr string, borders ...*model.BorderObs) *model.UnitX {
+		return &model.UnitX{UnitID: "0987", TurnNo: 90001, StartTN: tn, EndTN: tn, Acts: []*model.Act{
+			{Seq: 1, Kind: model.ActKindStatus, Steps: []*model.Step{{Seq: 1, Kind: model.StepKindObs, Terr: terr, Borders: borders}}},
+		}}
+	}
+	km := model.NewKnownMap([]*model.UnitX{
+		status("QQ 1010", "PR", &model.BorderObs{Dir: "N", Kind: "River"}),
+		status("QQ 1009", "GH", &model.BorderObs{Dir: "N", Kind: "O"}),
+	}, model.DefaultWorldRules)
+
+	svg := string(stages.RenderMapSVG(km, "Clan 0987 & friends"))
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Fatalf("not an svg document:\n%s", svg)
+	}
+	for _, want := range []string{"<title>Clan 0987 &amp; friends</title>", "<title>QQ 1010 PR</title>", "<title>QQ 1009 GH</title>", "<title>QQ 1008 O (seen from a neighboring hex)</title>"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("svg does not contain %q", want)
+		}
+	}
+	if got := strings.Count(svg, "<polygon "); got != 3 {
+		t.Errorf("hexes drawn = %d, want 3", got)
+	}
+	if got := strings.Count(svg, `stroke-dasharray`); got != 1 {
+		t.Errorf("inferred hexes drawn = %d, want 1", got)
+	}
+	// the river is seen from one side and known from both; it is drawn once
+	if got := strings.Count(svg, "<line "); got != 1 {
+		t.Errorf("rivers drawn = %d, want 1", got)
+	}
+
+	empty := string(stages.RenderMapSVG(model.NewKnownMap(nil, model.DefaultWorldRules), "empty"))
+	if !strings.Contains(empty, "No hexes have been observed yet.") {
+		t.Errorf("empty map:\n%s", empty)
+	}
+}
@@ -0,0 +1,845 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mdhender/tnrpt/clock"
+	"github.com/spf13/afero"
+)
+
+// S3Config is where an S3FS keeps its objects and how it signs requests.
+type S3Config struct {
+	Endpoint     string // e.g. "https://s3.us-east-1.amazonaws.com"
+	PathStyle    bool   // put the bucket in the path instead of the host name
+	Region       string
+	Bucket       string
+	Prefix       string // prepended to every key, e.g. "prod"
+	AccessKey    string
+	SecretKey    string
+	SessionToken string // optional
+}
+
+// S3ConfigFromURL returns the config for a location like "s3://bucket/prefix".
+// Credentials and the region come from the usual AWS environment variables
+// (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION).
+// Set AWS_ENDPOINT_URL_S3 (or AWS_ENDPOINT_URL) to use an S3-compatible store
+// such as MinIO; requests to it are path-style.
+func S3ConfigFromURL(rawURL string) (S3Config, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return S3Config{}, fmt.Errorf("s3: %w", err)
+	} else if u.Scheme != "s3" || u.Host == "" {
+		return S3Config{}, fmt.Errorf("s3: %q: want s3://bucket/prefix", rawURL)
+	}
+	cfg := S3Config{
+		Region:       os.Getenv("AWS_REGION"),
+		Bucket:       u.Host,
+		Prefix:       strings.Trim(u.Path, "/"),
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint = os.Getenv("AWS_ENDPOINT_URL_S3"); cfg.Endpoint == "" {
+		cfg.Endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	if cfg.Endpoint != "" {
+		cfg.PathStyle = true
+	} else {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return S3Config{}, fmt.Errorf("s3: %q: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set", rawURL)
+	}
+	return cfg, nil
+}
+
+// S3FS is an afero.Fs that keeps files as objects in an S3-compatible bucket,
+// so the pipeline services and web handlers can use object storage through
+// their SetFS methods.
+//
+// A file is read into memory when it is opened and written to the bucket when
+// it is closed. Directories are key prefixes; Mkdir and MkdirAll write an empty
+// "dir/" marker so that an empty directory can still be listed. Rename is a
+// copy and a delete. Permissions, owners, and times aren't kept.
+//
+// Each object is stored with its SHA-256 in the "sha256" metadata field, for
+// other tools; SHA256 hashes the content itself (see VerifySHA256).
+//
+// The afero methods don't take a context. Requests are made with the one given
+// to WithContext, and each gives up after s3Timeout either way.
+type S3FS struct {
+	cfg    S3Config
+	host   string // host name requests are sent to
+	base   string // URL path of the bucket, "" or "/bucket"
+	scheme string
+	client *http.Client
+	clock  clock.Clock
+	ctx    context.Context
+}
+
+// s3Timeout bounds each request to the bucket, so a stalled endpoint can't
+// hang a worker or a web request.
+const s3Timeout = time.Minute
+
+// NewS3FS returns a filesystem backed by the bucket in cfg.
+func NewS3FS(cfg S3Config) (*S3FS, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("s3: endpoint: %w", err)
+	} else if u.Host == "" {
+		return nil, fmt.Errorf("s3: endpoint %q: missing host", cfg.Endpoint)
+	} else if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3: missing bucket")
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	s := &S3FS{cfg: cfg, scheme: u.Scheme, client: &http.Client{Timeout: s3Timeout}, clock: clock.Real, ctx: context.Background()}
+	if cfg.PathStyle {
+		s.host, s.base = u.Host, "/"+cfg.Bucket
+	} else {
+		s.host = cfg.Bucket + "." + u.Host
+	}
+	return s, nil
+}
+
+// SetClient sets the HTTP client used to reach the bucket.
+func (s *S3FS) SetClient(c *http.Client) {
+	s.client = c
+}
+
+// SetClock sets the clock used to sign requests, for testing.
+func (s *S3FS) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// WithContext returns a copy of s that makes its requests, and those of the
+// files it opens, with ctx.
+func (s *S3FS) WithContext(ctx context.Context) *S3FS {
+	c := *s
+	c.ctx = ctx
+	return &c
+}
+
+// Name implements afero.Fs.
+func (s *S3FS) Name() string {
+	return "S3FS"
+}
+
+// key returns the object key for a file name. Names are taken from the root
+// of the bucket's prefix, so "data/x.docx" and "/data/x.docx" are the same
+// file and ".." can't leave the prefix.
+func (s *S3FS) key(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if s.cfg.Prefix == "" {
+		return name
+	} else if name == "" {
+		return s.cfg.Prefix
+	}
+	return s.cfg.Prefix + "/" + name
+}
+
+// dirPrefix returns the prefix that the keys of a directory's entries start with.
+func (s *S3FS) dirPrefix(name string) string {
+	if key := s.key(name); key != "" {
+		return key + "/"
+	}
+	return ""
+}
+
+// Create implements afero.Fs.
+func (s *S3FS) Create(name string) (afero.File, error) {
+	return s.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// Mkdir implements afero.Fs.
+func (s *S3FS) Mkdir(name string, _ os.FileMode) error {
+	prefix := s.dirPrefix(name)
+	if prefix == "" {
+		return nil
+	}
+	if err := s.put(s.ctx, prefix, nil, nil); err != nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	return nil
+}
+
+// MkdirAll implements afero.Fs. Parent directories are implied by the key.
+func (s *S3FS) MkdirAll(name string, perm os.FileMode) error {
+	if fi, err := s.Stat(name); err == nil && fi.IsDir() {
+		return nil
+	}
+	return s.Mkdir(name, perm)
+}
+
+// Open implements afero.Fs.
+func (s *S3FS) Open(name string) (afero.File, error) {
+	return s.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile implements afero.Fs.
+func (s *S3FS) OpenFile(name string, flag int, _ os.FileMode) (afero.File, error) {
+	ctx := s.ctx
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	f := &s3File{fs: s, name: name, key: s.key(name), writable: writable}
+	if writable && flag&os.O_TRUNC != 0 {
+		f.modTime, f.dirty = s.clock.Now(), true
+		return f, nil
+	}
+	data, modTime, err := s.get(ctx, f.key)
+	if err == nil {
+		f.data, f.modTime = data, modTime
+		if flag&os.O_APPEND != 0 {
+			f.offset = int64(len(data))
+		}
+		return f, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	if writable && flag&os.O_CREATE != 0 {
+		f.modTime, f.dirty = s.clock.Now(), true
+		return f, nil
+	}
+	if !writable {
+		if fi, err := s.Stat(name); err == nil && fi.IsDir() {
+			return &s3File{fs: s, name: name, key: f.key, dir: true, modTime: fi.ModTime()}, nil
+		}
+	}
+	return nil, &os.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Remove implements afero.Fs.
+func (s *S3FS) Remove(name string) error {
+	fi, err := s.Stat(name)
+	if err != nil {
+		return err
+	}
+	key := s.key(name)
+	if fi.IsDir() {
+		entries, err := s.list(s.ctx, s.dirPrefix(name), 2)
+		if err != nil {
+			return &os.PathError{Op: "remove", Path: name, Err: err}
+		} else if len(entries) != 0 {
+			return &os.PathError{Op: "remove", Path: name, Err: syscall.ENOTEMPTY}
+		}
+		key = s.dirPrefix(name)
+	}
+	if err := s.delete(s.ctx, key); err != nil {
+		return &os.PathError{Op: "remove", Path: name, Err: err}
+	}
+	return nil
+}
+
+// RemoveAll implements afero.Fs.
+func (s *S3FS) RemoveAll(name string) error {
+	ctx := s.ctx
+	keys, err := s.listKeys(ctx, s.dirPrefix(name))
+	if err != nil {
+		return &os.PathError{Op: "removeall", Path: name, Err: err}
+	}
+	if key := s.key(name); key != "" {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		if err := s.delete(ctx, key); err != nil {
+			return &os.PathError{Op: "removeall", Path: name, Err: err}
+		}
+	}
+	return nil
+}
+
+// Rename implements afero.Fs. Only files can be renamed.
+func (s *S3FS) Rename(oldname, newname string) error {
+	ctx := s.ctx
+	oldKey, newKey := s.key(oldname), s.key(newname)
+	if oldKey == newKey {
+		return nil
+	}
+	source := "/" + s.cfg.Bucket + "/" + escapePath(oldKey)
+	if err := s.put(ctx, newKey, nil, http.Header{"X-Amz-Copy-Source": {source}}); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	if err := s.delete(ctx, oldKey); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	return nil
+}
+
+// Stat implements afero.Fs.
+func (s *S3FS) Stat(name string) (os.FileInfo, error) {
+	ctx := s.ctx
+	key := s.key(name)
+	base := path.Base("/" + key)
+	if key != "" {
+		resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+		if err != nil {
+			return nil, &os.PathError{Op: "stat", Path: name, Err: err}
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+			return &s3FileInfo{name: base, size: resp.ContentLength, modTime: modTime}, nil
+		}
+		entries, err := s.list(ctx, key+"/", 1)
+		if err != nil {
+			return nil, &os.PathError{Op: "stat", Path: name, Err: err}
+		} else if len(entries) == 0 {
+			if exists, err := s.exists(ctx, key+"/"); err != nil {
+				return nil, &os.PathError{Op: "stat", Path: name, Err: err}
+			} else if !exists {
+				return nil, &os.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+			}
+		}
+	}
+	return &s3FileInfo{name: base, dir: true}, nil
+}
+
+// Chmod implements afero.Fs. Modes aren't kept.
+func (s *S3FS) Chmod(string, os.FileMode) error {
+	return nil
+}
+
+// Chown implements afero.Fs. Owners aren't kept.
+func (s *S3FS) Chown(string, int, int) error {
+	return nil
+}
+
+// Chtimes implements afero.Fs. Times aren't kept.
+func (s *S3FS) Chtimes(string, time.Time, time.Time) error {
+	return nil
+}
+
+// SHA256 returns the hex SHA-256 of a file's content, hashing the object as
+// it downloads instead of holding it in memory. The "sha256" metadata isn't
+// trusted: it says what was written, not what is stored now.
+func (s *S3FS) SHA256(name string) (string, error) {
+	resp, err := s.do(s.ctx, http.MethodGet, s.key(name), nil, nil, nil)
+	if err != nil {
+		return "", &os.PathError{Op: "sha256", Path: name, Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", &os.PathError{Op: "sha256", Path: name, Err: fs.ErrNotExist}
+	} else if resp.StatusCode != http.StatusOK {
+		return "", &os.PathError{Op: "sha256", Path: name, Err: s3Error(resp)}
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", &os.PathError{Op: "sha256", Path: name, Err: err}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// SignedURL returns a URL that anyone can use to download the file until the
+// ttl runs out, without credentials.
+func (s *S3FS) SignedURL(name string, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > 7*24*time.Hour {
+		return "", fmt.Errorf("s3: signed url: ttl %s: must be up to 7 days", ttl)
+	}
+	now := s.clock.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.cfg.AccessKey + "/" + scope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(int(ttl / time.Second))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	if s.cfg.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	uri := s.uri(s.key(name))
+	canonical := strings.Join([]string{
+		http.MethodGet, uri, canonicalQuery(query), "host:" + s.host + "\n", "host", "UNSIGNED-PAYLOAD",
+	}, "\n")
+	signature := s.signature(now, scope, canonical)
+	return s.scheme + "://" + s.host + uri + "?" + canonicalQuery(query) + "&X-Amz-Signature=" + signature, nil
+}
+
+// uri returns the escaped URL path of an object.
+func (s *S3FS) uri(key string) string {
+	return s.base + "/" + escapePath(key)
+}
+
+// signature returns the SigV4 signature of a canonical request.
+func (s *S3FS) signature(now time.Time, scope, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format("20060102"))
+	for _, part := range []string{s.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+// do sends a signed request for an object (or, with an empty key, the bucket).
+func (s *S3FS) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	now := s.clock.Now().UTC()
+	hash := sha256.Sum256(body)
+	payload := hex.EncodeToString(hash[:])
+	uri := s.uri(key)
+	if key == "" {
+		uri = s.base + "/"
+	}
+	rawURL := s.scheme + "://" + s.host + uri
+	if len(query) != 0 {
+		rawURL += "?" + canonicalQuery(query)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+
+	// sign the host and every x-amz header
+	signed := map[string]string{"host": s.host}
+	for k, v := range req.Header {
+		if lk := strings.ToLower(k); strings.HasPrefix(lk, "x-amz-") {
+			signed[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for k := range signed {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var headers strings.Builder
+	for _, k := range names {
+		headers.WriteString(k + ":" + signed[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical := strings.Join([]string{method, uri, canonicalQuery(query), headers.String(), signedHeaders, payload}, "\n")
+	scope := now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, s.signature(now, scope, canonical)))
+	return s.client.Do(req)
+}
+
+// get returns an object's content and when it was last modified.
+func (s *S3FS) get(ctx context.Context, key string) ([]byte, time.Time, error) {
+	if key == "" {
+		return nil, time.Time{}, fs.ErrNotExist
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, time.Time{}, fs.ErrNotExist
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, s3Error(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return data, modTime, nil
+}
+
+// put writes an object, recording its SHA-256 in the object's metadata.
+// With an X-Amz-Copy-Source header it copies that object instead.
+func (s *S3FS) put(ctx context.Context, key string, data []byte, header http.Header) error {
+	if header == nil {
+		hash := sha256.Sum256(data)
+		header = http.Header{"X-Amz-Meta-Sha256": {hex.EncodeToString(hash[:])}}
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, nil, header, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	// a copy can fail after the 200 status has been sent
+	if header.Get("X-Amz-Copy-Source") != "" {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		} else if bytes.Contains(body, []byte("<Error>")) {
+			return fmt.Errorf("s3: copy: %s", body)
+		}
+	}
+	return nil
+}
+
+// delete removes an object. Removing an object that doesn't exist isn't an error.
+func (s *S3FS) delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// exists returns true if there is an object with the key.
+func (s *S3FS) exists(ctx context.Context, key string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("s3: %s", resp.Status)
+	}
+	return true, nil
+}
+
+// s3ListResult is the response to a ListObjectsV2 request.
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns the files and directories directly under a prefix, stopping
+// after max entries if max is positive. A directory's own marker is skipped.
+func (s *S3FS) list(ctx context.Context, prefix string, max int) ([]os.FileInfo, error) {
+	var entries []os.FileInfo
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+	for {
+		var result s3ListResult
+		if err := s.listPage(ctx, query, &result); err != nil {
+			return nil, err
+		}
+		for _, c := range result.Contents {
+			if c.Key != prefix {
+				entries = append(entries, &s3FileInfo{name: strings.TrimPrefix(c.Key, prefix), size: c.Size, modTime: c.LastModified})
+			}
+		}
+		for _, p := range result.CommonPrefixes {
+			entries = append(entries, &s3FileInfo{name: strings.TrimSuffix(strings.TrimPrefix(p.Prefix, prefix), "/"), dir: true})
+		}
+		if (max > 0 && len(entries) >= max) || !result.IsTruncated {
+			return entries, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// listKeys returns every key under a prefix, at any depth.
+func (s *S3FS) listKeys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		var result s3ListResult
+		if err := s.listPage(ctx, query, &result); err != nil {
+			return nil, err
+		}
+		for _, c := range result.Contents {
+			keys = append(keys, c.Key)
+		}
+		if !result.IsTruncated {
+			return keys, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (s *S3FS) listPage(ctx context.Context, query url.Values, result *s3ListResult) error {
+	resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("s3: list: %w", err)
+	}
+	return nil
+}
+
+// s3Error returns an error with the status and the start of the response body.
+func s3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if len(body) == 0 {
+		return fmt.Errorf("s3: %s", resp.Status)
+	}
+	return fmt.Errorf("s3: %s: %s", resp.Status, bytes.TrimSpace(body))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath escapes each segment of an object key the way SigV4 expects.
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escapeSigV4(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the query string sorted and escaped the way SigV4 expects.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, escapeSigV4(k)+"="+escapeSigV4(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapeSigV4 percent-encodes everything but the unreserved characters.
+func escapeSigV4(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// s3File is an open file or directory in an S3FS.
+type s3File struct {
+	fs       *S3FS
+	name     string
+	key      string
+	dir      bool
+	data     []byte
+	offset   int64
+	modTime  time.Time
+	writable bool
+	dirty    bool // written to the bucket on Sync and Close
+	closed   bool
+	entries  []os.FileInfo // directory entries not yet returned by Readdir
+	listed   bool
+}
+
+func (f *s3File) Name() string {
+	return f.name
+}
+
+func (f *s3File) Close() error {
+	if f.closed {
+		return afero.ErrFileClosed
+	}
+	err := f.Sync()
+	f.closed = true
+	return err
+}
+
+func (f *s3File) Sync() error {
+	if f.closed {
+		return afero.ErrFileClosed
+	} else if !f.dirty {
+		return nil
+	}
+	if err := f.fs.put(f.fs.ctx, f.key, f.data, nil); err != nil {
+		return &os.PathError{Op: "sync", Path: f.name, Err: err}
+	}
+	f.dirty = false
+	return nil
+}
+
+func (f *s3File) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *s3File) ReadAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, afero.ErrFileClosed
+	} else if f.dir {
+		return 0, &os.PathError{Op: "read", Path: f.name, Err: syscall.EISDIR}
+	} else if off < 0 {
+		return 0, &os.PathError{Op: "readat", Path: f.name, Err: errors.New("negative offset")}
+	} else if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *s3File) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, afero.ErrFileClosed
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += int64(len(f.data))
+	}
+	if offset < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: errors.New("negative position")}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *s3File) Write(p []byte) (int, error) {
+	n, err := f.WriteAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *s3File) WriteAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, afero.ErrFileClosed
+	} else if !f.writable {
+		return 0, &os.PathError{Op: "write", Path: f.name, Err: syscall.EBADF}
+	} else if off < 0 {
+		return 0, &os.PathError{Op: "writeat", Path: f.name, Err: errors.New("negative offset")}
+	}
+	if end := off + int64(len(p)); end > int64(len(f.data)) {
+		f.data = append(f.data, make([]byte, end-int64(len(f.data)))...)
+	}
+	copy(f.data[off:], p)
+	f.dirty = true
+	return len(p), nil
+}
+
+func (f *s3File) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *s3File) Truncate(size int64) error {
+	if f.closed {
+		return afero.ErrFileClosed
+	} else if !f.writable {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: syscall.EBADF}
+	} else if size < 0 {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: syscall.EINVAL}
+	}
+	if size <= int64(len(f.data)) {
+		f.data = f.data[:size]
+	} else {
+		f.data = append(f.data, make([]byte, size-int64(len(f.data)))...)
+	}
+	f.dirty = true
+	return nil
+}
+
+func (f *s3File) Stat() (os.FileInfo, error) {
+	if f.closed {
+		return nil, afero.ErrFileClosed
+	}
+	return &s3FileInfo{name: path.Base("/" + f.key), size: int64(len(f.data)), modTime: f.modTime, dir: f.dir}, nil
+}
+
+// Readdir returns the directory's entries sorted by name.
+func (f *s3File) Readdir(count int) ([]os.FileInfo, error) {
+	if f.closed {
+		return nil, afero.ErrFileClosed
+	} else if !f.dir {
+		return nil, &os.PathError{Op: "readdir", Path: f.name, Err: syscall.ENOTDIR}
+	}
+	if !f.listed {
+		entries, err := f.fs.list(f.fs.ctx, f.fs.dirPrefix(f.name), 0)
+		if err != nil {
+			return nil, &os.PathError{Op: "readdir", Path: f.name, Err: err}
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+		f.entries, f.listed = entries, true
+	}
+	if count <= 0 {
+		entries := f.entries
+		f.entries = nil
+		return entries, nil
+	} else if len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	count = min(count, len(f.entries))
+	entries := f.entries[:count]
+	f.entries = f.entries[count:]
+	return entries, nil
+}
+
+func (f *s3File) Readdirnames(n int) ([]string, error) {
+	entries, err := f.Readdir(n)
+	names := make([]string, len(entries))
+	for i, fi := range entries {
+		names[i] = fi.Name()
+	}
+	return names, err
+}
+
+// s3FileInfo describes an object or a key prefix.
+type s3FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (fi *s3FileInfo) Name() string       { return fi.name }
+func (fi *s3FileInfo) Size() int64        { return fi.size }
+func (fi *s3FileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *s3FileInfo) IsDir() bool        { return fi.dir }
+func (fi *s3FileInfo) Sys() any           { return nil }
+
+func (fi *s3FileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/spf13/afero"
+)
+
+var (
+	// Drop-folder report names: GGGG.YYYY-MM.CCCC.docx or GGGG.YYYY-MM.CCCC.report.txt
+	// (the year may be 3 or 4 digits, e.g. 0301.899-12.0512.docx; the clan, 4 or 5).
+	reWatchFilename = regexp.MustCompile(`^(\d{4})\.(\d{3,4})-(\d{2})\.(` + model.ClanPattern + `)\.(docx|report\.txt)$`)
+)
+
+// WatchService polls a drop directory for new report files and ingests them.
+// Metadata (game, turn, clan) is inferred from the file name.
+type WatchService struct {
+	ingest    *IngestService
+	dir       string
+	createdBy string
+	fs        afero.Fs
+	settle    bool
+	seen      map[string]fileStamp // file name -> stamp when last processed
+	pending   map[string]fileStamp // file name -> stamp on the last scan, for files not processed yet
+}
+
+// fileStamp is what a scan sees of a file. A file whose stamp hasn't changed
+// between two scans is assumed to be done being written.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// NewWatchService creates a new WatchService for the given drop directory.
+func NewWatchService(ingest *IngestService, dir, createdBy string) *WatchService {
+	return &WatchService{
+		ingest:    ingest,
+		dir:       dir,
+		createdBy: createdBy,
+		fs:        afero.NewOsFs(),
+		settle:    true,
+		seen:      make(map[string]fileStamp),
+		pending:   make(map[string]fileStamp),
+	}
+}
+
+// SetFS sets the filesystem the drop directory is on (see NewFS).
+// The default is the OS filesystem.
+func (w *WatchService) SetFS(fs afero.Fs) {
+	w.fs = fs
+}
+
+// SetSettle sets whether a file must look the same on two scans in a row
+// before it is ingested, so a report that is still being copied into the
+// drop directory isn't ingested half-written. The default is true; one-off
+// scans of a directory nothing is writing to can turn it off.
+func (w *WatchService) SetSettle(settle bool) {
+	w.settle = settle
+}
+
+// WatchResult contains the outcome of ingesting one file from the drop directory.
+type WatchResult struct {
+	Filename string
+	BatchID  int64
+	IngestResult
+}
+
+// Scan ingests every report file in the drop directory that has not been seen,
+// or that has changed since it was last seen, once its size and modification
+// time are the same as on the previous scan (see SetSettle). Files that do not
+// match the naming convention are logged once and skipped. A file that can't
+// be read or ingested (database locked) is logged and tried again on the next
+// scan; the rest of the directory is still scanned.
+func (w *WatchService) Scan(ctx context.Context) ([]WatchResult, error) {
+	fsys := ContextFS(ctx, w.fs)
+	entries, err := afero.ReadDir(fsys, w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("readdir %s: %w", w.dir, err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	var results []WatchResult
+	present := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		present[name] = true
+		stamp := fileStamp{size: entry.Size(), modTime: entry.ModTime()}
+		if seen, ok := w.seen[name]; ok && seen.same(stamp) {
+			continue
+		}
+
+		game, clanNo, turnNo, ok := ParseReportFilename(name)
+		if !ok {
+			w.seen[name] = stamp
+			logging.FromContext(ctx).Warn("pipeline: watch: skipping: name must be GGGG.YYYY-MM.CCCC.docx or GGGG.YYYY-MM.CCCC.report.txt", logging.KeyFile, name)
+			continue
+		}
+
+		if last, ok := w.pending[name]; w.settle && (!ok || !last.same(stamp)) {
+			w.pending[name] = stamp // still being written, or new since the last scan
+			continue
+		}
+
+		path := filepath.Join(w.dir, name)
+		data, err := afero.ReadFile(fsys, path)
+		if err != nil {
+			logging.FromContext(ctx).Error("pipeline: watch: will retry", logging.KeyFile, name, "err", fmt.Errorf("read %s: %w", path, err))
+			continue
+		}
+
+		batchID, ingested, err := w.ingest.IngestBatch(ctx, game, clanNo, turnNo, w.createdBy, []IngestRequest{
+			{Filename: name, Data: data},
+		})
+		if err != nil {
+			logging.FromContext(ctx).Error("pipeline: watch: will retry", logging.KeyFile, name, "err", err)
+			continue
+		}
+		w.seen[name] = stamp
+		delete(w.pending, name)
+		for _, r := range ingested {
+			results = append(results, WatchResult{Filename: name, BatchID: batchID, IngestResult: r})
+		}
+	}
+
+	// forget files that were removed before they settled
+	for name := range w.pending {
+		if !present[name] {
+			delete(w.pending, name)
+		}
+	}
+
+	return results, nil
+}
+
+func (s fileStamp) same(o fileStamp) bool {
+	return s.size == o.size && s.modTime.Equal(o.modTime)
+}
+
+// ParseReportFilename extracts game, clan, and turn number from a drop-folder file name.
+// Example: "0301.0899-12.0512.docx" returns ("0301", "0512", 89912, true).
+func ParseReportFilename(name string) (game, clanNo string, turnNo model.TurnNo, ok bool) {
+	matches := reWatchFilename.FindStringSubmatch(name)
+	if matches == nil {
+		return "", "", 0, false
+	}
+	year, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return "", "", 0, false
+	}
+	month, err := strconv.Atoi(matches[3])
+	if err != nil {
+		return "", "", 0, false
+	}
+	turnNo = model.NewTurnNo(year, month)
+	if !turnNo.Valid() {
+		return "", "", 0, false
+	}
+	return matches[1], matches[4], turnNo, true
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stages_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	"github.com/spf13/afero"
+)
+
+func TestParseReportFilename(t *testing.T) {
+	testCases := []struct {
+		name   string
+		game   string
+		clanNo string
+		turnNo model.TurnNo
+		ok     bool
+	}{
+		{name: "0301.0899-12.0512.docx", game: "0301", clanNo: "0512", turnNo: 89912, ok: true},
+		{name: "0301.899-12.0512.docx", game: "0301", clanNo: "0512", turnNo: 89912, ok: true},
+		{name: "0301.0900-01.0987.report.txt", game: "0301", clanNo: "0987", turnNo: 90001, ok: true},
+		{name: "0301.0900-01.01987.report.txt", game: "0301", clanNo: "01987", turnNo: 90001, ok: true},
+		{name: "0301.0900-13.0987.report.txt"},
+		{name: "0301.0900-01.1987.docx"},
+		{name: "0301.0900-01.001987.docx"},
+		{name: "0987.docx"},
+		{name: "notes.txt"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			game, clanNo, turnNo, ok := stages.ParseReportFilename(tc.name)
+			if ok != tc.ok {
+				t.Fatalf("ok: expected %v, got %v", tc.ok, ok)
+			}
+			if game != tc.game || clanNo != tc.clanNo || turnNo != tc.turnNo {
+				t.Errorf("expected (%q, %q, %d), got (%q, %q, %d)", tc.game, tc.clanNo, tc.turnNo, game, clanNo, turnNo)
+			}
+		})
+	}
+}
+
+func TestWatchService_ScanIngestsNewFilesOnce(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore()
+	fs := afero.NewMemMapFs()
+
+	ingest := stages.NewIngestService(store, "/data")
+	ingest.SetFS(fs)
+	watcher := stages.NewWatchService(ingest, "/inbox", "test-watch")
+	watcher.SetFS(fs)
+
+	_ = afero.WriteFile(fs, "/inbox/0301.0899-12.0512.docx", []byte("docx content"), 0644)
+	_ = afero.WriteFile(fs, "/inbox/0301.0899-12.0987.report.txt", []byte("text content"), 0644)
+	_ = afero.WriteFile(fs, "/inbox/readme.md", []byte("ignored"), 0644)
+
+	// the first scan only notes the files; they are ingested once they settle
+	results, err := watcher.Scan(ctx)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected no results before the files settle, got %d", len(results))
+	}
+	results, err = watcher.Scan(ctx)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	for _, r := range results {
+		rf := store.reportFiles[r.ReportFileID]
+		if rf == nil {
+			t.Fatalf("%s: report file not found in store", r.Filename)
+		}
+		if rf.Game != "0301" || rf.TurnNo != 89912 {
+			t.Errorf("%s: expected game 0301 turn 89912, got %s %d", r.Filename, rf.Game, rf.TurnNo)
+		}
+		if batch := store.batches[r.BatchID]; batch == nil || batch.CreatedBy != "test-watch" {
+			t.Errorf("%s: expected batch created by 'test-watch'", r.Filename)
+		}
+	}
+
+	stageOf := map[string]string{}
+	for _, w := range store.work {
+		stageOf[store.reportFiles[w.ReportFileID].ClanNo] = w.Stage
+	}
+	if stageOf["0512"] != model.WorkStageExtract {
+		t.Errorf("expected docx to queue extract, got %q", stageOf["0512"])
+	}
+	if stageOf["0987"] != model.WorkStageParse {
+		t.Errorf("expected text to queue parse, got %q", stageOf["0987"])
+	}
+
+	results, err = watcher.Scan(ctx)
+	if err != nil {
+		t.Fatalf("second scan: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no results on second scan, got %d", len(results))
+	}
+	if len(store.batches) != 2 {
+		t.Errorf("expected 2 batches, got %d", len(store.batches))
+	}
+}
+
+// lockedStore fails to create batches for one clan, like a database that is
+// locked while that clan's report is dropped.
+type lockedStore struct {
+	*mockStore
+	clanNo string
+}
+
+func (s *lockedStore) InsertUploadBatch(ctx context.Context, batch *model.UploadBatch) (int64, error) {
+	if batch.ClanNo == s.clanNo {
+		return 0, errors.New("database is locked")
+	}
+	return s.mockStore.InsertUploadBatch(ctx, batch)
+}
+
+func TestWatchService_ScanRetriesFailedFiles(t *testing.T) {
+	ctx := context.Background()
+	store := &lockedStore{mockStore: newMockStore(), clanNo: "0512"}
+	fs := afero.NewMemMapFs()
+
+	ingest := stages.NewIngestService(store, "/data")
+	ingest.SetFS(fs)
+	watcher := stages.NewWatchService(ingest, "/inbox", "test-watch")
+	watcher.SetFS(fs)
+
+	_ = afero.WriteFile(fs, "/inbox/0301.0899-12.0512.docx", []byte("docx content"), 0644)
+	_ = afero.WriteFile(fs, "/inbox/0301.0899-12.0987.report.txt", []byte("text content"), 0644)
+	watcher.SetSettle(false)
+
+	results, err := watcher.Scan(ctx)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(results) != 1 || results[0].Filename != "0301.0899-12.0987.report.txt" {
+		t.Fatalf("expected only the text report to be ingested, got %+v", results)
+	}
+
+	store.clanNo = ""
+	results, err = watcher.Scan(ctx)
+	if err != nil {
+		t.Fatalf("second scan: %v", err)
+	}
+	if len(results) != 1 || results[0].Filename != "0301.0899-12.0512.docx" {
+		t.Fatalf("expected the failed docx to be retried, got %+v", results)
+	}
+}
+
+// TestWatchService_ScanWaitsForCopies drops a report in two writes, like a
+// slow copy, and checks that it is ingested once, after it stops growing.
+func TestWatchService_ScanWaitsForCopies(t *testing.T) {
+	ctx := context.Background()
+	store := newMockStore()
+	fs := afero.NewMemMapFs()
+
+	ingest := stages.NewIngestService(store, "/data")
+	ingest.SetFS(fs)
+	watcher := stages.NewWatchService(ingest, "/inbox", "test-watch")
+	watcher.SetFS(fs)
+
+	const name = "/inbox/0301.0899-12.0987.report.txt"
+	scan := func(want int) {
+		t.Helper()
+		results, err := watcher.Scan(ctx)
+		if err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if len(results) != want {
+			t.Fatalf("expected %d results, got %d", want, len(results))
+		}
+	}
+
+	_ = afero.WriteFile(fs, name, []byte("Tribe 0987"), 0644)
+	scan(0)
+	_ = afero.WriteFile(fs, name, []byte("Tribe 0987, , Current Hex = QQ 1010"), 0644)
+	scan(0) // still growing
+	scan(1)
+	scan(0)
+
+	if len(store.reportFiles) != 1 {
+		t.Fatalf("expected 1 report file, got %d", len(store.reportFiles))
+	}
+	for _, rf := range store.reportFiles {
+		data, err := afero.ReadFile(fs, "/data/"+rf.FsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "Tribe 0987, , Current Hex = QQ 1010" {
+			t.Errorf("ingested %q, want the whole file", data)
+		}
+	}
+}
@@ -3,8 +3,8 @@
 package stages
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,18 +12,27 @@ import (
 	"time"
 
 	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/clock"
+	"github.com/mdhender/tnrpt/logging"
 	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/norm"
+	"github.com/mdhender/tnrpt/pipelines/lint"
 	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
 	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/tracing"
 	"github.com/spf13/afero"
 )
 
 // WorkerService claims and executes pipeline jobs.
 type WorkerService struct {
-	store    WorkerStore
-	dataDir  string
-	workerID string
-	fs       afero.Fs
+	store      WorkerStore
+	dataDir    string
+	workerID   string
+	fs         afero.Fs
+	clock      clock.Clock
+	renderAuto bool   // queue a render job after each parse
+	faults     Faults // failures forced by tests; see SetFaults
+	linter     *lint.Linter
 }
 
 // WorkerStore defines the store operations needed by WorkerService.
@@ -32,12 +41,35 @@ type WorkerStore interface {
 	FinishWork(ctx context.Context, id int64, status, errorCode, errorMsg string) error
 	InsertWork(ctx context.Context, work *model.Work) (int64, error)
 	GetReportFileByID(ctx context.Context, id int64) (*model.ReportFile, error)
+	UnitIDRules(ctx context.Context, gameID string) (model.UnitIDRules, error)
+	FlagsForGame(ctx context.Context, gameID string) (model.Flags, error)
+
+	// For linting parsed reports
+	LintConfigForGame(ctx context.Context, gameID string) (model.LintConfig, error)
+	WorldRules(ctx context.Context, gameID string) (model.WorldRules, error)
 
 	// For parsing stage - persist extracted data
 	InsertReportExtract(ctx context.Context, rx *model.ReportX) (int64, error)
 	InsertUnitExtract(ctx context.Context, ux *model.UnitX) (int64, error)
 	InsertAct(ctx context.Context, act *model.Act) (int64, error)
 	InsertStep(ctx context.Context, step *model.Step) (int64, error)
+
+	// Derived data refreshed after a parse
+	RefreshUnitEvents(ctx context.Context, game string) (int, error)
+
+	// Timings recorded after a parse
+	SetReportTimings(ctx context.Context, rxID int64, t model.ParseTimings) error
+
+	// Corrections recorded when a parse replaces a clan's earlier report
+	RecordCorrection(ctx context.Context, rxID int64) (*model.ReportCorrection, error)
+
+	// Parse results cached by content hash and parser version
+	ParseCache(ctx context.Context, sha256, parserVersion string) ([]byte, error)
+	SaveParseCache(ctx context.Context, sha256, parserVersion string, data []byte, createdAt time.Time) error
+
+	// For the render stage
+	KnownMapByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) (*model.KnownMap, error)
+	SaveClanMap(ctx context.Context, cm *model.ClanMap) error
 }
 
 // NewWorkerService creates a new WorkerService.
@@ -51,14 +83,34 @@ func NewWorkerService(store WorkerStore, dataDir, workerID string) *WorkerServic
 		dataDir:  dataDir,
 		workerID: workerID,
 		fs:       afero.NewOsFs(),
+		clock:    clock.Real,
+		linter:   lint.Default(),
 	}
 }
 
-// SetFS sets the filesystem for testing.
+// SetFS sets the filesystem the data directory is on (see NewFS).
+// The default is the OS filesystem.
 func (w *WorkerService) SetFS(fs afero.Fs) {
 	w.fs = fs
 }
 
+// SetClock sets the clock for testing.
+func (w *WorkerService) SetClock(c clock.Clock) {
+	w.clock = c
+}
+
+// SetRenderAuto turns on queueing a render job for each report that parses,
+// so every clan gets a fresh map image for the turn.
+func (w *WorkerService) SetRenderAuto(on bool) {
+	w.renderAuto = on
+}
+
+// SetLinter sets the lint rules run over each parsed report.
+// The default is lint.Default.
+func (w *WorkerService) SetLinter(l *lint.Linter) {
+	w.linter = l
+}
+
 // WorkResult represents the outcome of executing a job.
 type WorkResult struct {
 	Success      bool
@@ -73,71 +125,196 @@ func (w *WorkerService) ClaimJob(ctx context.Context, stage string) (*model.Work
 }
 
 // ExecuteExtract reads a DOCX file, extracts text, and writes it to a .report.txt file.
+// The text is written as it is extracted, so neither the document nor its text is
+// held in memory (see docx.WriteText).
 // If the file is already a .txt file, this is a no-op (skip extraction).
 // On success, creates a 'parse' work row for the next stage.
 func (w *WorkerService) ExecuteExtract(ctx context.Context, job *model.Work, rf *model.ReportFile) error {
 	fullPath := filepath.Join(w.dataDir, rf.FsPath)
 	ext := strings.ToLower(filepath.Ext(rf.FsPath))
 
+	if IsArchivedPath(rf.FsPath) {
+		return &ErrWriteFile{Op: "read", Path: fullPath, Err: fmt.Errorf("report file is archived, restore it first")}
+	}
 	if ext == ".txt" {
-		return w.queueParseStage(ctx, job.ReportFileID)
+		return w.queueStage(ctx, job.ReportFileID, model.WorkStageParse)
 	}
 
-	data, err := afero.ReadFile(w.fs, fullPath)
+	fsys := ContextFS(ctx, w.fs)
+	f, err := fsys.Open(fullPath)
 	if err != nil {
 		return &ErrWriteFile{Op: "read", Path: fullPath, Err: err}
 	}
-
-	parsed, err := docx.ParseReader(bytes.NewReader(data), true, true, true, false, false)
+	defer f.Close()
+	info, err := f.Stat()
 	if err != nil {
-		return &ErrDocxCorrupt{Path: fullPath, Err: err}
+		return &ErrWriteFile{Op: "read", Path: fullPath, Err: err}
+	}
+	if err := checkSHA256Reader(fullPath, f, rf.SHA256); err != nil {
+		return err
 	}
 
+	// the text is streamed from the document to the file (see docx.WriteText)
 	txtPath := strings.TrimSuffix(fullPath, ext) + ".report.txt"
-	if err := afero.WriteFile(w.fs, txtPath, parsed.Text, 0644); err != nil {
+	out, err := fsys.OpenFile(txtPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return &ErrWriteFile{Op: "write", Path: txtPath, Err: err}
+	}
+	err = docx.WriteText(out, f, info.Size(), true, true)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = fsys.Remove(txtPath) // don't leave half a report for the parse stage
+		if errors.Is(err, docx.ErrNotAWordDocument) || errors.Is(err, docx.ErrBadInput) {
+			return &ErrDocxCorrupt{Path: fullPath, Err: err}
+		}
 		return &ErrWriteFile{Op: "write", Path: txtPath, Err: err}
 	}
 
-	return w.queueParseStage(ctx, job.ReportFileID)
+	return w.queueStage(ctx, job.ReportFileID, model.WorkStageParse)
 }
 
 // ExecuteParse reads extracted text and parses it using the bistre parser.
-// The parsed data is stored in the model tables.
+// Text reports saved as UTF-16 or Windows-1252 are converted to UTF-8 first
+// (see norm.ToUTF8), with a warning in the log.
+// A report whose content was parsed before by the same parser version reuses
+// the cached result instead (see EncodeParseCache).
+// The parsed data is stored in the model tables, along with how long the parse
+// and store took. The adapter converts and stores units as it goes, so the
+// store time includes the conversion. A report that replaces one the clan
+// already had for the turn is recorded as a correction (see WorkerStore.RecordCorrection).
+// A report with units of another clan than the file's fails with
+// CLAN_MISMATCH unless the game allows it.
+// The game's feature flags turn on the parser's experimental fixes and
+// partial persistence (see model.FeatureFlags).
+// The game's lint rules are run over the parsed report and what they find is
+// logged; lint problems don't fail the parse.
+// With render-auto on, a 'render' work row is created for the next stage.
 func (w *WorkerService) ExecuteParse(ctx context.Context, job *model.Work, rf *model.ReportFile) error {
 	txtPath := w.findTextFile(rf)
 	if txtPath == "" {
 		return &ErrWriteFile{Op: "find", Path: rf.FsPath, Err: fmt.Errorf("no text file found")}
 	}
 
-	data, err := afero.ReadFile(w.fs, txtPath)
+	data, err := afero.ReadFile(ContextFS(ctx, w.fs), txtPath)
 	if err != nil {
 		return &ErrWriteFile{Op: "read", Path: txtPath, Err: err}
 	}
+	if strings.EqualFold(filepath.Ext(rf.FsPath), ".txt") {
+		// a text report is parsed straight from the ingested file
+		if err := checkSHA256(txtPath, data, rf.SHA256); err != nil {
+			return err
+		}
+	}
+	var enc norm.Encoding
+	if data, enc = norm.ToUTF8(data); enc != norm.EncodingUTF8 {
+		logging.FromContext(ctx).Warn("pipeline: parse: text converted to UTF-8", logging.KeyReportFileID, rf.ID, logging.KeyFile, rf.Name, "encoding", enc)
+	}
 
 	fid := rf.Name
-	tid := formatTurnID(rf.TurnNo)
-
-	turn, err := bistre.ParseInput(
-		fid, tid, data,
-		true,  // acceptLoneDash
-		false, // debugParser
-		false, // debugSections
-		false, // debugSteps
-		false, // debugNodes
-		false, // debugFleetMovement
-		false, // experimentalUnitSplit
-		false, // experimentalScoutStill
-		bistre.ParseConfig{},
-	)
+	tid := rf.TurnNo.String()
+
+	flags, err := w.store.FlagsForGame(ctx, rf.Game)
 	if err != nil {
-		return &ErrParseSyntax{Line: 0, Msg: err.Error()}
+		return &ErrDatabase{Op: "load feature flags", Err: err}
+	}
+	cfg := bistre.ParseConfig{AcceptLoneDash: true, Logger: logging.FromContext(ctx)}
+	cfg.Experimental.UnitSplit = flags.On(model.FlagParserUnitSplit)
+	cfg.Experimental.ScoutStill = flags.On(model.FlagParserScoutStill)
+	cacheVersion := parseCacheKey(cfg)
+
+	var timings model.ParseTimings
+	started := time.Now()
+	turn := w.cachedParse(ctx, rf, cacheVersion)
+	if turn == nil {
+		turn, err = bistre.ParseInput(fid, tid, data, cfg)
+		if err != nil {
+			return &ErrParseSyntax{Line: 0, Msg: err.Error()}
+		}
+		w.saveParse(ctx, rf, cacheVersion, turn)
 	}
+	timings.Parse = time.Since(started)
 
-	_, err = adapters.BistreTurnToStoreWithReportFile(ctx, w.store, rf, turn)
+	ids, err := w.store.UnitIDRules(ctx, rf.Game)
 	if err != nil {
+		return &ErrDatabase{Op: "load unit id rules", Err: err}
+	}
+	if err := ids.ValidateClan(rf.ClanNo); err != nil {
+		return err // UNIT_ID_INVALID
+	}
+	var unitIDs []string
+	for id := range turn.UnitMoves {
+		unitIDs = append(unitIDs, string(id))
+	}
+	if err := ids.CheckClan(rf.ClanNo, unitIDs); err != nil {
+		if !flags.On(model.FlagParseClanMismatch) {
+			return err // CLAN_MISMATCH
+		}
+		logging.FromContext(ctx).Warn("pipeline: parse: clan mismatch allowed", logging.KeyReportFileID, rf.ID, logging.KeyFile, rf.Name, "err", err)
+	}
+
+	started = time.Now()
+	res, err := adapters.PersistWithReportFile(ctx, w.store, rf, turn, adapters.Options{UnitIDs: ids, Now: w.clock.Now, Partial: flags.On(model.FlagParsePartial)})
+	var uerr *model.UnitIDError
+	if errors.As(err, &uerr) {
+		return err // UNIT_ID_INVALID
+	} else if err != nil {
 		return &ErrDatabase{Op: "persist parse result", Err: err}
 	}
+	rxID := res.ReportXID
+	timings.Store = time.Since(started)
+	for _, warning := range res.Warnings {
+		logging.FromContext(ctx).Warn("pipeline: parse", logging.KeyReportFileID, rf.ID, logging.KeyFile, rf.Name, "warning", warning)
+	}
+	for _, uerr := range res.Errors {
+		logging.FromContext(ctx).Warn("pipeline: parse: unit skipped", logging.KeyReportFileID, rf.ID, logging.KeyFile, rf.Name, "err", uerr)
+	}
+	if err := w.lint(ctx, rf, turn, data, ids); err != nil {
+		return err
+	}
+
+	if err := w.store.SetReportTimings(ctx, rxID, timings); err != nil {
+		return &ErrDatabase{Op: "record parse timings", Err: err}
+	}
+	if _, err := w.store.RecordCorrection(ctx, rxID); err != nil {
+		return &ErrDatabase{Op: "record correction", Err: err}
+	}
+
+	if _, err := w.store.RefreshUnitEvents(ctx, rf.Game); err != nil {
+		return &ErrDatabase{Op: "refresh unit events", Err: err}
+	}
 
+	if w.renderAuto {
+		return w.queueStage(ctx, job.ReportFileID, model.WorkStageRender)
+	}
+	return nil
+}
+
+// lint runs the game's lint rules over a parsed report and logs the
+// diagnostics. A report with units that don't convert, which the parse only
+// keeps with parse.partial on, isn't linted.
+func (w *WorkerService) lint(ctx context.Context, rf *model.ReportFile, turn *bistre.Turn_t, text []byte, ids model.UnitIDRules) error {
+	cfg, err := w.store.LintConfigForGame(ctx, rf.Game)
+	if err != nil {
+		return &ErrDatabase{Op: "load lint rules", Err: err}
+	}
+	world, err := w.store.WorldRules(ctx, rf.Game)
+	if err != nil {
+		return &ErrDatabase{Op: "load world rules", Err: err}
+	}
+	rx, err := adapters.BistreTurnToModelReportX(rf.Name, turn, rf.Game, rf.ClanNo, ids, w.clock.Now)
+	if err != nil {
+		logging.FromContext(ctx).Warn("pipeline: lint: skipped", logging.KeyReportFileID, rf.ID, logging.KeyFile, rf.Name, "err", err)
+		return nil
+	}
+	for _, d := range w.linter.Run(&lint.Report{RX: rx, Text: text, World: world}, cfg) {
+		log := logging.FromContext(ctx).Warn
+		if d.Severity == model.LintInfo {
+			log = logging.FromContext(ctx).Info
+		}
+		log("pipeline: lint", logging.KeyReportFileID, rf.ID, logging.KeyFile, rf.Name, "diagnostic", d.String())
+	}
 	return nil
 }
 
@@ -190,70 +367,89 @@ func (w *WorkerService) ProcessJob(ctx context.Context, stage string) (bool, err
 		return true, fmt.Errorf("report file %d not found", job.ReportFileID)
 	}
 
+	log := logging.FromContext(ctx).With(logging.KeyStage, stage, logging.KeyReportFileID, rf.ID)
+	ctx, span := tracing.Start(ctx, "pipeline "+stage,
+		tracing.String(logging.KeyStage, stage),
+		tracing.String(logging.KeyGame, rf.Game),
+		tracing.String(logging.KeyClan, rf.ClanNo),
+		tracing.String(logging.KeyTurn, rf.TurnNo.String()),
+		tracing.Int(logging.KeyReportFileID, rf.ID))
+	defer span.End()
+	log.Debug("pipeline: job claimed", logging.KeyFile, rf.Name)
+	started := w.clock.Now()
+
 	var execErr error
-	switch stage {
-	case model.WorkStageExtract:
+	if w.faults != nil {
+		execErr = w.faults.Fault(stage, rf)
+	}
+	switch {
+	case execErr != nil:
+		log.Debug("pipeline: fault injected", "code", ErrorCode(execErr))
+	case stage == model.WorkStageExtract:
 		execErr = w.ExecuteExtract(ctx, job, rf)
-	case model.WorkStageParse:
+	case stage == model.WorkStageParse:
 		execErr = w.ExecuteParse(ctx, job, rf)
+	case stage == model.WorkStageRender:
+		execErr = w.ExecuteRender(ctx, job, rf)
 	default:
 		execErr = fmt.Errorf("unknown stage: %s", stage)
 	}
 
 	if execErr != nil {
+		span.SetAttrs(tracing.String("error.type", ErrorCode(execErr)))
+		span.SetError(execErr)
 		w.FinishJob(ctx, job, WorkResult{
 			Success:      false,
 			ErrorCode:    ErrorCode(execErr),
 			ErrorMessage: execErr.Error(),
 		})
+		log.Debug("pipeline: job failed", "code", ErrorCode(execErr), "err", execErr)
 		return true, execErr
 	}
 
 	if err := w.FinishJob(ctx, job, WorkResult{Success: true}); err != nil {
 		return true, fmt.Errorf("finish job: %w", err)
 	}
+	log.Debug("pipeline: job done", "elapsed", w.clock.Now().Sub(started))
 
 	return true, nil
 }
 
-// queueParseStage creates a work row for the parse stage.
-func (w *WorkerService) queueParseStage(ctx context.Context, reportFileID int64) error {
+// queueStage creates a work row for the next stage.
+func (w *WorkerService) queueStage(ctx context.Context, reportFileID int64, stage string) error {
 	work := &model.Work{
 		ReportFileID: reportFileID,
-		Stage:        model.WorkStageParse,
+		Stage:        stage,
 		Status:       model.WorkStatusQueued,
 		Attempt:      0,
-		AvailableAt:  time.Now().UTC(),
+		AvailableAt:  w.clock.Now().UTC(),
 	}
 	_, err := w.store.InsertWork(ctx, work)
 	if err != nil {
-		return &ErrDatabase{Op: "insert parse work", Err: err}
+		return &ErrDatabase{Op: "insert " + stage + " work", Err: err}
 	}
 	return nil
 }
 
-// findTextFile locates the text file for parsing.
-// For DOCX files, looks for the .report.txt file; for .txt files, uses the original.
+// findTextFile locates the text file for parsing (see ReportTextPath),
+// or returns "" if a DOCX file hasn't been extracted yet.
 func (w *WorkerService) findTextFile(rf *model.ReportFile) string {
-	fullPath := filepath.Join(w.dataDir, rf.FsPath)
-	ext := strings.ToLower(filepath.Ext(rf.FsPath))
-
-	if ext == ".txt" {
-		return fullPath
+	txtPath := filepath.Join(w.dataDir, ReportTextPath(rf))
+	if !strings.EqualFold(filepath.Ext(rf.FsPath), ".txt") {
+		if exists, _ := afero.Exists(w.fs, txtPath); !exists {
+			return ""
+		}
 	}
-
-	txtPath := strings.TrimSuffix(fullPath, ext) + ".report.txt"
-	exists, _ := afero.Exists(w.fs, txtPath)
-	if exists {
-		return txtPath
-	}
-
-	return ""
+	return txtPath
 }
 
-// formatTurnID converts a turn number (e.g., 89912) to turn ID format (e.g., "0899-12").
-func formatTurnID(turnNo int) string {
-	year := turnNo / 100
-	month := turnNo % 100
-	return fmt.Sprintf("%04d-%02d", year, month)
+// ReportTextPath returns the path, relative to the data directory, of the
+// text the parse stage reads for a report file: the file itself for a .txt
+// report, or the .report.txt the extract stage writes next to a DOCX file.
+func ReportTextPath(rf *model.ReportFile) string {
+	ext := filepath.Ext(rf.FsPath)
+	if strings.EqualFold(ext, ".txt") {
+		return rf.FsPath
+	}
+	return strings.TrimSuffix(rf.FsPath, ext) + ".report.txt"
 }
@@ -3,13 +3,25 @@
 package stages_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
+	"unicode/utf16"
 
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/clock"
 	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
+	"github.com/mdhender/tnrpt/pipelines/stages"
 	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/afero"
 )
 
 func TestWorkerService_ClaimJob_AtomicLocking(t *testing.T) {
@@ -356,3 +368,334 @@ func TestResetFailedWork_ClearsErrorFields(t *testing.T) {
 		t.Errorf("expected status 'running', got %q", reclaimedWork.Status)
 	}
 }
+
+func TestClaimWork_HonorsPause(t *testing.T) {
+	ctx := context.Background()
+	sqlStore, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer sqlStore.Close()
+
+	rfID, err := sqlStore.InsertReportFile(ctx, &model.ReportFile{
+		Game:      "0301",
+		ClanNo:    "0512",
+		TurnNo:    89912,
+		Name:      "test.docx",
+		SHA256:    "pause123",
+		Mime:      "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		CreatedAt: time.Now().UTC(),
+		FsPath:    "batches/1/test.docx",
+	})
+	if err != nil {
+		t.Fatalf("insert report file: %v", err)
+	}
+	_, err = sqlStore.InsertWork(ctx, &model.Work{
+		ReportFileID: rfID,
+		Stage:        model.WorkStageExtract,
+		Status:       model.WorkStatusQueued,
+		AvailableAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("insert work: %v", err)
+	}
+
+	for _, stage := range []string{"", model.WorkStageExtract} {
+		if err := sqlStore.PauseWork(ctx, "test", stage, "migration"); err != nil {
+			t.Fatalf("pause %q: %v", stage, err)
+		}
+		work, err := sqlStore.ClaimWork(ctx, model.WorkStageExtract, "worker-1")
+		if err != nil {
+			t.Fatalf("claim work: %v", err)
+		}
+		if work != nil {
+			t.Fatalf("pause %q: claimed job %d", stage, work.ID)
+		}
+		if ok, err := sqlStore.ResumeWork(ctx, "test", stage); err != nil || !ok {
+			t.Fatalf("resume %q: got %v, %v", stage, ok, err)
+		}
+	}
+
+	// pausing another stage leaves extract alone
+	if err := sqlStore.PauseWork(ctx, "test", model.WorkStageParse, ""); err != nil {
+		t.Fatalf("pause parse: %v", err)
+	}
+	pauses, err := sqlStore.WorkPauses(ctx)
+	if err != nil {
+		t.Fatalf("work pauses: %v", err)
+	}
+	if len(pauses) != 1 || pauses[0].Stage != model.WorkStageParse || pauses[0].PausedBy != "test" {
+		t.Errorf("work pauses: got %+v", pauses)
+	}
+	work, err := sqlStore.ClaimWork(ctx, model.WorkStageExtract, "worker-1")
+	if err != nil {
+		t.Fatalf("claim work: %v", err)
+	}
+	if work == nil {
+		t.Fatal("expected to claim work while only parse is paused")
+	}
+	if ok, _ := sqlStore.ResumeWork(ctx, "test", ""); ok {
+		t.Error("resume all: expected false when only parse is paused")
+	}
+}
+
+func TestWorkerService_ExecuteParse_ClanMismatch(t *testing.T) {
+	ctx := context.Background()
+	sqlStore, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer sqlStore.Close()
+
+	// clan 0987's report, filed under clan 0512
+	report, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0900-01.0987.report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(report)
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/data/batches/1/0303.0900-01.0512.report.txt", report, 0644); err != nil {
+		t.Fatal(err)
+	}
+	rf := &model.ReportFile{Game: "0303", ClanNo: "0512", TurnNo: 90001, Name: "0303.0900-01.0512.report.txt",
+		SHA256: hex.EncodeToString(hash[:]), Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: "batches/1/0303.0900-01.0512.report.txt"}
+	if rf.ID, err = sqlStore.InsertReportFileWithBatch(ctx, rf); err != nil {
+		t.Fatalf("insert report file: %v", err)
+	}
+
+	worker := stages.NewWorkerService(sqlStore, "/data", "test")
+	worker.SetFS(fsys)
+	err = worker.ExecuteParse(ctx, &model.Work{ReportFileID: rf.ID}, rf)
+	if code := stages.ErrorCode(err); code != stages.ErrCodeClanMismatch {
+		t.Fatalf("parse: code = %q, want %q (%v)", code, stages.ErrCodeClanMismatch, err)
+	}
+
+	if err := sqlStore.SetFeatureFlag(ctx, "test", model.FlagParseClanMismatch, "0303", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := worker.ExecuteParse(ctx, &model.Work{ReportFileID: rf.ID}, rf); err != nil {
+		t.Errorf("parse with the flag set: %v", err)
+	}
+}
+
+func TestWorkerService_ExecuteParse_UTF16(t *testing.T) {
+	ctx := context.Background()
+	sqlStore, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer sqlStore.Close()
+
+	// the report as Notepad saves "Unicode" text
+	text, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0900-01.0987.report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(string(text))) {
+		report = append(report, byte(u), byte(u>>8))
+	}
+	hash := sha256.Sum256(report)
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/data/batches/1/0304.0900-01.0987.report.txt", report, 0644); err != nil {
+		t.Fatal(err)
+	}
+	rf := &model.ReportFile{Game: "0304", ClanNo: "0987", TurnNo: 90001, Name: "0304.0900-01.0987.report.txt",
+		SHA256: hex.EncodeToString(hash[:]), Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: "batches/1/0304.0900-01.0987.report.txt"}
+	if rf.ID, err = sqlStore.InsertReportFileWithBatch(ctx, rf); err != nil {
+		t.Fatalf("insert report file: %v", err)
+	}
+
+	worker := stages.NewWorkerService(sqlStore, "/data", "test")
+	worker.SetFS(fsys)
+	if err := worker.ExecuteParse(ctx, &model.Work{ReportFileID: rf.ID}, rf); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	units, err := sqlStore.UnitsByGameClan("0304", 987, 90001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(units) == 0 {
+		t.Errorf("parse: no units stored")
+	}
+}
+
+// TestWorkerService_ExecuteParse_Clock checks that the parse stage stamps the
+// report with the worker's clock.
+func TestWorkerService_ExecuteParse_Clock(t *testing.T) {
+	ctx := context.Background()
+	sqlStore, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer sqlStore.Close()
+
+	report, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0900-01.0987.report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(report)
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/data/batches/1/0305.0900-01.0987.report.txt", report, 0644); err != nil {
+		t.Fatal(err)
+	}
+	rf := &model.ReportFile{Game: "0305", ClanNo: "0987", TurnNo: 90001, Name: "0305.0900-01.0987.report.txt",
+		SHA256: hex.EncodeToString(hash[:]), Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: "batches/1/0305.0900-01.0987.report.txt"}
+	if rf.ID, err = sqlStore.InsertReportFileWithBatch(ctx, rf); err != nil {
+		t.Fatalf("insert report file: %v", err)
+	}
+
+	now := time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC)
+	worker := stages.NewWorkerService(sqlStore, "/data", "test")
+	worker.SetFS(fsys)
+	worker.SetClock(clock.NewFixed(now))
+	if err := worker.ExecuteParse(ctx, &model.Work{ReportFileID: rf.ID}, rf); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	rxs, err := sqlStore.ReportExtractsByGameTurn(ctx, "0305", 90001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rxs) != 1 || !rxs[0].CreatedAt.Equal(now) {
+		t.Errorf("reports = %v, want one created at %v", rxs, now)
+	}
+}
+
+func TestWorkerService_ProcessJob_InjectedFaults(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "faults.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	sqlStore, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer sqlStore.Close()
+
+	report, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0900-01.0987.report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(report)
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/data/batches/1/0305.0900-01.0987.report.txt", report, 0644); err != nil {
+		t.Fatal(err)
+	}
+	rf := &model.ReportFile{Game: "0305", ClanNo: "0987", TurnNo: 90001, Name: "0305.0900-01.0987.report.txt",
+		SHA256: hex.EncodeToString(hash[:]), Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: "batches/1/0305.0900-01.0987.report.txt"}
+	if rf.ID, err = sqlStore.InsertReportFileWithBatch(ctx, rf); err != nil {
+		t.Fatalf("insert report file: %v", err)
+	}
+	if _, err := sqlStore.InsertWork(ctx, &model.Work{ReportFileID: rf.ID, Stage: model.WorkStageParse,
+		Status: model.WorkStatusQueued, AvailableAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("insert work: %v", err)
+	}
+
+	faults := &stages.FaultInjector{}
+	faults.Add(model.WorkStageParse, "other.report.txt", stages.FaultParseSyntax, 0)
+	faults.Add(model.WorkStageParse, rf.Name, stages.FaultDatabaseBusy, 1)
+	worker := stages.NewWorkerService(sqlStore, "/data", "test")
+	worker.SetFS(fsys)
+	worker.SetFaults(faults)
+
+	// the first run fails as if the database were locked
+	if _, err := worker.ProcessJob(ctx, model.WorkStageParse); stages.ErrorCode(err) != string(cerrs.CodeDatabaseBusy) {
+		t.Fatalf("first run: got %v, want %s", err, cerrs.CodeDatabaseBusy)
+	}
+	failed, err := sqlStore.GetFailedWork(ctx, model.WorkStageParse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].ErrorCode == nil || *failed[0].ErrorCode != string(cerrs.CodeDatabaseBusy) {
+		t.Fatalf("failed work: got %+v, want one DATABASE_BUSY job", failed)
+	}
+
+	// the retry gets past the fault and parses the report
+	if n, err := sqlStore.ResetFailedWork(ctx, model.WorkStageParse); err != nil || n != 1 {
+		t.Fatalf("reset: got %d, %v", n, err)
+	}
+	if _, err := worker.ProcessJob(ctx, model.WorkStageParse); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if units, err := sqlStore.UnitsByGameClan("0305", 987, 90001); err != nil || len(units) == 0 {
+		t.Errorf("retry: got %d units, %v; want the report's units", len(units), err)
+	}
+
+	for _, tc := range []struct {
+		kind stages.FaultKind
+		want cerrs.Code
+	}{
+		{stages.FaultDocxCorrupt, cerrs.CodeDocxCorrupt},
+		{stages.FaultParseSyntax, cerrs.CodeParseSyntax},
+		{stages.FaultDatabaseBusy, cerrs.CodeDatabaseBusy},
+	} {
+		fi := &stages.FaultInjector{}
+		fi.Add(model.WorkStageExtract, "", tc.kind, 0)
+		for range 2 {
+			if code := stages.ErrorCode(fi.Fault(model.WorkStageExtract, rf)); code != string(tc.want) {
+				t.Errorf("%s: code = %q, want %q", tc.kind, code, tc.want)
+			}
+		}
+		if err := fi.Fault(model.WorkStageParse, rf); err != nil {
+			t.Errorf("%s: parse: got %v, want no fault", tc.kind, err)
+		}
+	}
+}
+
+func TestWorkerService_ExecuteExtract(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "extract.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	sqlStore, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer sqlStore.Close()
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0301.0899-12.0987.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := docx.ParseReader(bytes.NewReader(data), true, true, true, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := afero.NewMemMapFs()
+	worker := stages.NewWorkerService(sqlStore, "/data", "test")
+	worker.SetFS(fsys)
+	extract := func(name string, data []byte, sha string) error {
+		if err := afero.WriteFile(fsys, "/data/batches/1/"+name, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		rf := &model.ReportFile{Game: "0301", ClanNo: "0987", TurnNo: 89912, Name: name, SHA256: sha,
+			Mime: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", CreatedAt: time.Now().UTC(), FsPath: "batches/1/" + name}
+		if rf.ID, err = sqlStore.InsertReportFileWithBatch(ctx, rf); err != nil {
+			t.Fatalf("insert report file: %v", err)
+		}
+		return worker.ExecuteExtract(ctx, &model.Work{ReportFileID: rf.ID, Stage: model.WorkStageExtract}, rf)
+	}
+
+	hash := sha256.Sum256(data)
+	if err := extract("good.docx", data, hex.EncodeToString(hash[:])); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	got, err := afero.ReadFile(fsys, "/data/batches/1/good.report.txt")
+	if err != nil || !bytes.Equal(got, want.Text) {
+		t.Errorf("extract: report text differs from the document's (%v)", err)
+	}
+
+	var corrupt *stages.ErrDocxCorrupt
+	if err := extract("bad.docx", []byte("not a word document"), ""); !errors.As(err, &corrupt) {
+		t.Errorf("corrupt docx: got %v, want ErrDocxCorrupt", err)
+	}
+	if exists, _ := afero.Exists(fsys, "/data/batches/1/bad.report.txt"); exists {
+		t.Errorf("corrupt docx: a partial report was left behind")
+	}
+	var checksum *stages.ErrChecksum
+	if err := extract("changed.docx", data, "abc123"); !errors.As(err, &checksum) {
+		t.Errorf("checksum mismatch: got %v, want ErrChecksum", err)
+	}
+}
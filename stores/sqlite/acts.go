@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/cerrs"
 	"github.com/mdhender/tnrpt/model"
 )
 
@@ -27,7 +29,7 @@ func (s *SQLiteStore) InsertReportFile(ctx context.Context, rf *model.ReportFile
 		rf.CreatedAt.Format(time.RFC3339),
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert report_file: %w", err)
+		return 0, dbError("insert report_file", err)
 	}
 	return result.LastInsertId()
 }
@@ -35,27 +37,89 @@ func (s *SQLiteStore) InsertReportFile(ctx context.Context, rf *model.ReportFile
 // InsertReportExtract inserts a ReportX and returns its assigned ID.
 func (s *SQLiteStore) InsertReportExtract(ctx context.Context, rx *model.ReportX) (int64, error) {
 	const query = `
-		INSERT INTO report_extracts (report_file_id, game, clan_no, turn_no, created_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO report_extracts (report_file_id, game, clan_no, turn_no, season, weather, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 	result, err := s.db.ExecContext(ctx, query,
 		rx.ReportFileID,
 		rx.Game,
 		rx.ClanNo,
 		rx.TurnNo,
+		nullString(rx.Season),
+		nullString(rx.Weather),
 		rx.CreatedAt.Format(time.RFC3339),
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert report_extract: %w", err)
+		return 0, dbError("insert report_extract", err)
 	}
 	return result.LastInsertId()
 }
 
+// execer runs statements on the database, or in a transaction (see WithUnitTx).
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
 // InsertUnitExtract inserts a UnitX and returns its assigned ID.
 func (s *SQLiteStore) InsertUnitExtract(ctx context.Context, ux *model.UnitX) (int64, error) {
+	return execUnitExtract(ctx, s.db, ux)
+}
+
+// InsertAct inserts an Act and returns its assigned ID.
+func (s *SQLiteStore) InsertAct(ctx context.Context, act *model.Act) (int64, error) {
+	return execAct(ctx, s.db, act)
+}
+
+// InsertStep inserts a Step and its child records, returning the step's assigned ID.
+func (s *SQLiteStore) InsertStep(ctx context.Context, step *model.Step) (int64, error) {
+	return execStep(ctx, s.db, step)
+}
+
+// WithUnitTx runs fn with a store that writes in a transaction, and commits
+// the transaction if fn succeeds. If fn fails, none of the unit's rows are
+// kept. adapters.PersistWithReportFile writes each unit this way.
+func (s *SQLiteStore) WithUnitTx(ctx context.Context, fn func(adapters.UnitStore) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+	if err := fn(unitTx{tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("commit", err)
+	}
+	return nil
+}
+
+// unitTx is the adapters.UnitStore that WithUnitTx hands out.
+type unitTx struct {
+	tx *sql.Tx
+}
+
+func (u unitTx) InsertUnitExtract(ctx context.Context, ux *model.UnitX) (int64, error) {
+	return execUnitExtract(ctx, u.tx, ux)
+}
+
+func (u unitTx) InsertAct(ctx context.Context, act *model.Act) (int64, error) {
+	return execAct(ctx, u.tx, act)
+}
+
+func (u unitTx) InsertStep(ctx context.Context, step *model.Step) (int64, error) {
+	return execStep(ctx, u.tx, step)
+}
+
+func execUnitExtract(ctx context.Context, db execer, ux *model.UnitX) (int64, error) {
 	// Parse TNCoord to grid/col/row
-	startGrid, startCol, startRow := parseTNCoord(ux.StartTN)
-	endGrid, endCol, endRow := parseTNCoord(ux.EndTN)
+	startGrid, startCol, startRow, err := ux.StartTN.Parse()
+	if err != nil {
+		return 0, cerrs.Wrap(cerrs.CodeInvalidInput, fmt.Sprintf("insert unit_extract: unit %s: start", ux.UnitID), err)
+	}
+	endGrid, endCol, endRow, err := ux.EndTN.Parse()
+	if err != nil {
+		return 0, cerrs.Wrap(cerrs.CodeInvalidInput, fmt.Sprintf("insert unit_extract: unit %s: end", ux.UnitID), err)
+	}
 
 	const query = `
 		INSERT INTO unit_extracts (
@@ -74,7 +138,7 @@ func (s *SQLiteStore) InsertUnitExtract(ctx context.Context, ux *model.UnitX) (i
 		srcNote = sql.NullString{String: ux.Src.Note, Valid: ux.Src.Note != ""}
 	}
 
-	result, err := s.db.ExecContext(ctx, query,
+	result, err := db.ExecContext(ctx, query,
 		ux.ReportXID,
 		ux.UnitID,
 		ux.ClanID,
@@ -89,15 +153,17 @@ func (s *SQLiteStore) InsertUnitExtract(ctx context.Context, ux *model.UnitX) (i
 		srcNote,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert unit_extract: %w", err)
+		return 0, dbError("insert unit_extract", err)
 	}
 	return result.LastInsertId()
 }
 
-// InsertAct inserts an Act and returns its assigned ID.
-func (s *SQLiteStore) InsertAct(ctx context.Context, act *model.Act) (int64, error) {
+func execAct(ctx context.Context, db execer, act *model.Act) (int64, error) {
 	// Parse dest TNCoord for goto acts
-	destGrid, destCol, destRow := parseTNCoord(act.DestTN)
+	destGrid, destCol, destRow, err := act.DestTN.Parse()
+	if err != nil {
+		return 0, cerrs.Wrap(cerrs.CodeInvalidInput, fmt.Sprintf("insert act: act %d: dest", act.Seq), err)
+	}
 
 	const query = `
 		INSERT INTO acts (
@@ -126,7 +192,7 @@ func (s *SQLiteStore) InsertAct(ctx context.Context, act *model.Act) (int64, err
 		srcNote = sql.NullString{String: act.Src.Note, Valid: act.Src.Note != ""}
 	}
 
-	result, err := s.db.ExecContext(ctx, query,
+	result, err := db.ExecContext(ctx, query,
 		act.UnitXID,
 		act.Seq,
 		string(act.Kind),
@@ -143,20 +209,19 @@ func (s *SQLiteStore) InsertAct(ctx context.Context, act *model.Act) (int64, err
 		srcNote,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert act: %w", err)
+		return 0, dbError("insert act", err)
 	}
 	return result.LastInsertId()
 }
 
-// InsertStep inserts a Step and its child records, returning the step's assigned ID.
-func (s *SQLiteStore) InsertStep(ctx context.Context, step *model.Step) (int64, error) {
+func execStep(ctx context.Context, db execer, step *model.Step) (int64, error) {
 	const query = `
 		INSERT INTO steps (
 			act_id, seq, kind, ok, note,
-			dir, fail_why, terr, special, label,
+			dir, fail_why, fail_raw, terr, special, label,
 			src_doc_id, src_turn_no, src_unit_id, src_act_seq, src_step_seq, src_note
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	var ok sql.NullInt64
@@ -173,7 +238,7 @@ func (s *SQLiteStore) InsertStep(ctx context.Context, step *model.Step) (int64,
 		srcNote = sql.NullString{String: step.Src.Note, Valid: step.Src.Note != ""}
 	}
 
-	result, err := s.db.ExecContext(ctx, query,
+	result, err := db.ExecContext(ctx, query,
 		step.ActID,
 		step.Seq,
 		string(step.Kind),
@@ -181,6 +246,7 @@ func (s *SQLiteStore) InsertStep(ctx context.Context, step *model.Step) (int64,
 		nullString(step.Note),
 		nullString(step.Dir),
 		nullString(step.FailWhy),
+		nullString(step.FailRaw),
 		nullString(step.Terr),
 		boolToInt(step.Special),
 		nullString(step.Label),
@@ -192,24 +258,24 @@ func (s *SQLiteStore) InsertStep(ctx context.Context, step *model.Step) (int64,
 		srcNote,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert step: %w", err)
+		return 0, dbError("insert step", err)
 	}
 
 	stepID, err := result.LastInsertId()
 	if err != nil {
-		return 0, fmt.Errorf("get step id: %w", err)
+		return 0, dbError("get step id", err)
 	}
 
 	// Insert child records for encounters
 	if step.Enc != nil {
-		if err := s.insertStepEncounters(ctx, stepID, step.Enc); err != nil {
+		if err := insertStepEncounters(ctx, db, stepID, step.Enc); err != nil {
 			return 0, err
 		}
 	}
 
 	// Insert borders
 	for _, border := range step.Borders {
-		if err := s.insertStepBorder(ctx, stepID, border); err != nil {
+		if err := insertStepBorder(ctx, db, stepID, border); err != nil {
 			return 0, err
 		}
 	}
@@ -238,6 +304,8 @@ func (s *SQLiteStore) TableStats(ctx context.Context) (map[string]int64, error)
 		"render_jobs",
 		"render_job_units",
 		"render_job_turns",
+		"clan_maps",
+		"parse_cache",
 	}
 
 	stats := make(map[string]int64, len(tables))
@@ -245,7 +313,7 @@ func (s *SQLiteStore) TableStats(ctx context.Context) (map[string]int64, error)
 		var count int64
 		query := `SELECT COUNT(*) ` + `FROM ` + table
 		if err := s.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
-			return nil, fmt.Errorf("count %s: %w", table, err)
+			return nil, dbError(fmt.Sprintf("count %s", table), err)
 		}
 		stats[table] = count
 	}
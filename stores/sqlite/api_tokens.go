@@ -0,0 +1,136 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+)
+
+// API token scopes.
+const (
+	APIScopeGM = "gm" // GM automation, e.g. report ingest
+)
+
+// APITokenPrefix starts every API token so that leaked tokens are easy to spot.
+const APITokenPrefix = "tnrpt_"
+
+// APIToken describes an API token. The token itself is never stored.
+type APIToken struct {
+	ID         int64
+	Handle     string
+	Name       string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt time.Time // zero if never used
+	Revoked    bool
+}
+
+// CreateAPIToken creates a named API token for the user with the given scopes.
+// Only a hash of the token is stored, so the returned value can't be recovered later.
+// It returns an error if the user already has a token with that name.
+func (s *SQLiteStore) CreateAPIToken(ctx context.Context, handle, name string, scopes []string) (string, error) {
+	if name == "" {
+		return "", cerrs.New(cerrs.CodeInvalidInput, "api token: missing name")
+	}
+	for _, scope := range scopes {
+		if scope != APIScopeGM {
+			return "", cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("api token: unknown scope %q", scope))
+		}
+	}
+	token := APITokenPrefix + s.ids.NewID(32)
+
+	const query = `
+		INSERT INTO api_tokens (token_hash, user_handle, name, scopes, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	now := s.now().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx, query, hashLoginToken(token), handle, name, strings.Join(scopes, ","), now); err != nil {
+		return "", dbError("insert api token", err)
+	}
+	return token, nil
+}
+
+// APITokenUser returns the handle of the active user who owns the token, if
+// the token hasn't been revoked and has the scope, and records that the token
+// was used. It returns an empty handle otherwise.
+func (s *SQLiteStore) APITokenUser(ctx context.Context, token, scope string) (string, error) {
+	const query = `
+		SELECT id, user_handle, scopes FROM api_tokens
+		WHERE token_hash = ? AND revoked_at IS NULL
+	`
+	var id int64
+	var handle, scopes string
+	err := s.db.QueryRowContext(ctx, query, hashLoginToken(token)).Scan(&id, &handle, &scopes)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", dbError("query api token", err)
+	}
+	if !slices.Contains(strings.Split(scopes, ","), scope) {
+		return "", nil
+	}
+	if active, err := s.isUserActive(ctx, handle); err != nil {
+		return "", err
+	} else if !active {
+		return "", nil
+	}
+
+	now := s.now().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx, `UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, now, id); err != nil {
+		return "", dbError("update api token", err)
+	}
+	return handle, nil
+}
+
+// RevokeAPIToken revokes the user's token with the given name.
+// It returns false if the user has no such token that is still active.
+func (s *SQLiteStore) RevokeAPIToken(ctx context.Context, handle, name string) (bool, error) {
+	const query = `UPDATE api_tokens SET revoked_at = ? WHERE user_handle = ? AND name = ? AND revoked_at IS NULL`
+	now := s.now().Format(time.RFC3339)
+	result, err := s.db.ExecContext(ctx, query, now, handle, name)
+	if err != nil {
+		return false, dbError("revoke api token", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, dbError("rows affected", err)
+	}
+	return n > 0, nil
+}
+
+// APITokens returns every API token, sorted by user and name.
+func (s *SQLiteStore) APITokens(ctx context.Context) ([]APIToken, error) {
+	const query = `
+		SELECT id, user_handle, name, scopes, created_at, COALESCE(last_used_at, ''), revoked_at IS NOT NULL
+		FROM api_tokens
+		ORDER BY user_handle, name
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("query api tokens", err)
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		var t APIToken
+		var scopes, createdAt, lastUsedAt string
+		if err := rows.Scan(&t.ID, &t.Handle, &t.Name, &scopes, &createdAt, &lastUsedAt, &t.Revoked); err != nil {
+			return nil, dbError("scan api token", err)
+		}
+		if scopes != "" {
+			t.Scopes = strings.Split(scopes, ",")
+		}
+		t.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		t.LastUsedAt, _ = time.Parse(time.RFC3339, lastUsedAt)
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Audit log actions.
+const (
+	AuditTurnActivate = "turn.activate" // a GM made a turn active
+	AuditTurnAdvance  = "turn.advance"  // the server activated the next turn when orders were due
+	AuditOrdersImport = "orders.import" // a clan's orders for a turn were imported
+)
+
+// AuditActorSystem is the actor for changes the server makes on its own.
+const AuditActorSystem = "system"
+
+// AuditEntry is one row of the audit log.
+type AuditEntry struct {
+	ID        int64
+	CreatedAt time.Time
+	Actor     string
+	Action    string
+	GameID    string
+	Detail    string
+}
+
+// AuditLog returns the most recent audit log entries for a game, newest first.
+// An empty gameID returns entries for all games.
+func (s *SQLiteStore) AuditLog(ctx context.Context, gameID string, limit int) ([]AuditEntry, error) {
+	const query = `
+		SELECT id, created_at, actor, action, COALESCE(game_id, ''), detail
+		FROM audit_log
+		WHERE ? = '' OR game_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`
+	rows, err := s.db.QueryContext(ctx, query, gameID, gameID, limit)
+	if err != nil {
+		return nil, dbError("query audit log", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		var createdAt string
+		if err := rows.Scan(&e.ID, &createdAt, &e.Actor, &e.Action, &e.GameID, &e.Detail); err != nil {
+			return nil, dbError("scan audit log", err)
+		}
+		e.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (s *SQLiteStore) insertAuditLog(ctx context.Context, tx *sql.Tx, actor, action, gameID, detail string) error {
+	const query = `
+		INSERT INTO audit_log (created_at, actor, action, game_id, detail)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	now := s.now().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx, query, now, actor, action, nullString(gameID), detail); err != nil {
+		return dbError("insert audit log", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// RecordCorrection compares the report extract rxID with the latest earlier
+// extract of the same clan's turn that came from a different report file and
+// saves what changed (see model.DiffReports). It returns nil if the clan had
+// no earlier report for the turn. A correction that changed nothing is still
+// recorded, with no changes.
+func (s *SQLiteStore) RecordCorrection(ctx context.Context, rxID int64) (*model.ReportCorrection, error) {
+	after, err := s.reportExtract(ctx, rxID)
+	if err != nil {
+		return nil, err
+	}
+
+	const previousQuery = `
+		SELECT id
+		FROM report_extracts
+		WHERE game = ? AND clan_no = ? AND turn_no = ? AND report_file_id != ? AND id < ?
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	var prevID int64
+	err = s.db.QueryRowContext(ctx, previousQuery, after.Game, after.ClanNo, after.TurnNo, after.ReportFileID, after.ID).Scan(&prevID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, dbError("query previous report extract", err)
+	}
+	before, err := s.reportExtract(ctx, prevID)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &model.ReportCorrection{
+		Game:            after.Game,
+		ClanNo:          after.ClanNo,
+		TurnNo:          after.TurnNo,
+		OldReportFileID: before.ReportFileID,
+		NewReportFileID: after.ReportFileID,
+		CreatedAt:       s.now(),
+		Changes:         model.DiffReports(before, after),
+	}
+	changes, err := json.Marshal(rc.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	const insertQuery = `
+		INSERT INTO report_corrections (game, clan_no, turn_no, old_report_file_id, new_report_file_id, created_at, changes_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := s.db.ExecContext(ctx, insertQuery,
+		rc.Game, rc.ClanNo, rc.TurnNo, rc.OldReportFileID, rc.NewReportFileID,
+		rc.CreatedAt.Format(time.RFC3339), string(changes))
+	if err != nil {
+		return nil, dbError("insert report correction", err)
+	}
+	if rc.ID, err = result.LastInsertId(); err != nil {
+		return nil, dbError("insert report correction", err)
+	}
+	return rc, nil
+}
+
+// ReportCorrections returns a game's report corrections, newest first.
+func (s *SQLiteStore) ReportCorrections(ctx context.Context, game string) ([]*model.ReportCorrection, error) {
+	const query = `
+		SELECT rc.id, rc.game, rc.clan_no, rc.turn_no,
+		       rc.old_report_file_id, COALESCE(rf_old.name, ''),
+		       rc.new_report_file_id, rf_new.name,
+		       rc.created_at, rc.changes_json
+		FROM report_corrections rc
+		JOIN report_files rf_new ON rf_new.id = rc.new_report_file_id
+		LEFT JOIN report_files rf_old ON rf_old.id = rc.old_report_file_id
+		WHERE rc.game = ?
+		ORDER BY rc.id DESC
+	`
+	rows, err := s.db.QueryContext(ctx, query, game)
+	if err != nil {
+		return nil, dbError("query report corrections", err)
+	}
+	defer rows.Close()
+
+	var corrections []*model.ReportCorrection
+	for rows.Next() {
+		var rc model.ReportCorrection
+		var oldID sql.NullInt64
+		var createdAt, changes string
+		if err := rows.Scan(&rc.ID, &rc.Game, &rc.ClanNo, &rc.TurnNo,
+			&oldID, &rc.OldName, &rc.NewReportFileID, &rc.NewName,
+			&createdAt, &changes); err != nil {
+			return nil, dbError("scan report correction", err)
+		}
+		rc.OldReportFileID = oldID.Int64
+		rc.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		if err := json.Unmarshal([]byte(changes), &rc.Changes); err != nil {
+			return nil, dbError("decode report correction", err)
+		}
+		corrections = append(corrections, &rc)
+	}
+	return corrections, rows.Err()
+}
+
+// reportExtract returns a report extract with its units, acts, and steps loaded.
+func (s *SQLiteStore) reportExtract(ctx context.Context, id int64) (*model.ReportX, error) {
+	const query = `
+		SELECT id, report_file_id, game, clan_no, turn_no, season, weather
+		FROM report_extracts
+		WHERE id = ?
+	`
+	var rx model.ReportX
+	var season, weather sql.NullString
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&rx.ID, &rx.ReportFileID, &rx.Game, &rx.ClanNo, &rx.TurnNo, &season, &weather)
+	if err != nil {
+		return nil, dbError("query report extract", err)
+	}
+	rx.Season, rx.Weather = season.String, weather.String
+
+	const unitsQuery = `
+		SELECT id, report_x_id, unit_id, turn_no,
+		       start_grid, start_col, start_row,
+		       end_grid, end_col, end_row
+		FROM unit_extracts
+		WHERE report_x_id = ?
+		ORDER BY unit_id
+	`
+	if rx.Units, err = s.queryUnitsWithArgs(unitsQuery, rx.ID); err != nil {
+		return nil, err
+	}
+	return &rx, nil
+}
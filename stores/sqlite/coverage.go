@@ -0,0 +1,28 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// CoverageByGameClan returns how often the clan's units have been in each hex and
+// when each was last observed. If asOf is non-zero, turns after asOf are ignored.
+func (s *SQLiteStore) CoverageByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]model.HexCoverage, error) {
+	rows, err := s.unitsWithSteps(ctx, `r.game = ? AND u.clan_id = ? AND (? = 0 OR u.turn_no <= ?)`,
+		gameID, formatClanNo(clanNo), asOf, asOf)
+	if err != nil {
+		return nil, err
+	}
+	world, err := s.WorldRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	units := make([]*model.UnitX, len(rows))
+	for i, du := range rows {
+		units[i] = du.unit
+	}
+	return model.Coverage(units, world), nil
+}
@@ -0,0 +1,514 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mdhender/tnrpt/coords"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// DerivedStats counts the rows written by RebuildDerived.
+type DerivedStats struct {
+	Games      int
+	Steps      int // steps placed on a hex
+	Tiles      int
+	UnitEvents int
+	Replaced   []TableRows // rows in each derived table before the rebuild
+}
+
+// TableRows is the number of rows in a table.
+type TableRows struct {
+	Table string
+	Rows  int64
+}
+
+// derivedTables are the tables RebuildDerived replaces, children first in
+// case foreign keys aren't enforced.
+var derivedTables = []string{"step_tiles", "tile_src", "tile_borders", "tile_rsrc", "tile_sets", "tile_units", "tiles", "unit_events"}
+
+// RebuildDerived throws away the derived tables (tiles and their contents, and
+// unit_events) and regenerates them from the report extracts, acts, and steps.
+// Use it after fixing a bug in how derived data is computed; the originals are
+// not re-parsed.
+//
+// Tiles are placed by walking each unit's steps from its starting coordinate
+// (see model.ResolveSteps). When several reports observe a hex, the most recent
+// turn wins; observations from the same turn are merged. Terrain is the
+// exception: when sightings disagree, the one with the best confidence score
+// wins (see model.BestTerrain), and the score is stored with it. Every
+// contributing step is recorded in tile_src, with the parts of the tile it
+// set (see model.TileAttr), and linked to the tile in step_tiles.
+func (s *SQLiteStore) RebuildDerived(ctx context.Context) (DerivedStats, error) {
+	return s.rebuildDerived(ctx, "", false)
+}
+
+// RebuildDerivedGame is RebuildDerived for one game; the derived rows of
+// other games are left alone.
+func (s *SQLiteStore) RebuildDerivedGame(ctx context.Context, gameID string) (DerivedStats, error) {
+	return s.rebuildDerived(ctx, gameID, false)
+}
+
+// RebuildDerivedDryRun does the work of RebuildDerived without writing
+// anything. The stats are the rows a rebuild would delete and the tiles it
+// would write; unit events aren't counted.
+func (s *SQLiteStore) RebuildDerivedDryRun(ctx context.Context) (DerivedStats, error) {
+	return s.rebuildDerived(ctx, "", true)
+}
+
+// rebuildDerived rebuilds the derived tables for gameID, or for every game
+// if gameID is empty.
+func (s *SQLiteStore) rebuildDerived(ctx context.Context, gameID string, dryRun bool) (DerivedStats, error) {
+	var stats DerivedStats
+
+	filter, args := "", []any{}
+	if gameID != "" {
+		filter, args = `r.game = ?`, []any{gameID}
+	}
+	units, err := s.unitsWithSteps(ctx, filter, args...)
+	if err != nil {
+		return stats, err
+	}
+	enc, borders, err := s.derivedEncounters(ctx, filter, args...)
+	if err != nil {
+		return stats, err
+	}
+
+	layout := coords.NewTribeNetLayout()
+	type tileKey struct{ game, hex string }
+	tiles := map[tileKey]*model.Tile{}
+	turnOf := map[tileKey]model.TurnNo{}
+	terrs := map[tileKey][]model.TerrainSighting{}
+	// the terrain each source saw, to find the ones that set the tile's
+	srcTerr := map[*model.TileSrc]string{}
+	var order []tileKey // insert tiles in the order they were first seen
+	var games []string
+	worlds := map[string]model.WorldRules{}
+	for _, du := range units {
+		if len(games) == 0 || games[len(games)-1] != du.game {
+			games = append(games, du.game)
+		}
+		world, ok := worlds[du.game]
+		if !ok {
+			if world, err = s.WorldRules(ctx, du.game); err != nil {
+				return stats, err
+			}
+			worlds[du.game] = world
+		}
+		for _, sa := range model.ResolveSteps(du.unit, world) {
+			st := sa.Step
+			e, b := enc[st.ID], borders[st.ID]
+			if st.Terr == "" && !st.Special && e == nil && b == nil {
+				continue // nothing observed
+			}
+			if sa.TN.IsObscured() {
+				continue // can't be placed on the map
+			}
+			hex, err := layout.CoordToHex(sa.TN)
+			if err != nil {
+				continue
+			}
+			stats.Steps++
+
+			key := tileKey{game: du.game, hex: hex.ConciseString()}
+			t, ok := tiles[key]
+			if !ok {
+				t = &model.Tile{Hex: hex, TN: sa.TN}
+				tiles[key], order = t, append(order, key)
+			}
+			if du.unit.TurnNo > turnOf[key] {
+				// units are walked in turn order, so a newer observation replaces what was seen before
+				turnOf[key] = du.unit.TurnNo
+				t.Units, t.Sets, t.Rsrc, t.Borders = nil, nil, nil, nil
+				for _, src := range t.Src {
+					src.Attrs = dropTileAttrs(src.Attrs, model.TileAttrUnits, model.TileAttrSettlements, model.TileAttrResources, model.TileAttrBorders)
+				}
+			}
+			src := &model.TileSrc{
+				DocID:   du.docID,
+				StepID:  st.ID,
+				UnitID:  du.unit.UnitID,
+				TurnNo:  du.unit.TurnNo,
+				ActSeq:  sa.ActSeq,
+				StepSeq: sa.StepSeq,
+			}
+			if st.Terr != "" {
+				terrs[key] = append(terrs[key], model.TerrainSighting{Terr: st.Terr, TurnNo: du.unit.TurnNo, ActKind: sa.ActKind, StepKind: st.Kind})
+				srcTerr[src] = st.Terr
+			}
+			if st.Special {
+				t.SpecialLabel = st.Label
+				for _, prev := range t.Src {
+					prev.Attrs = dropTileAttrs(prev.Attrs, model.TileAttrSpecial)
+				}
+				src.Attrs = append(src.Attrs, model.TileAttrSpecial)
+			}
+			if e != nil {
+				t.Units = append(t.Units, e.Units...)
+				t.Sets = append(t.Sets, e.Sets...)
+				t.Rsrc = append(t.Rsrc, e.Rsrc...)
+				if len(e.Units) != 0 {
+					src.Attrs = append(src.Attrs, model.TileAttrUnits)
+				}
+				if len(e.Sets) != 0 {
+					src.Attrs = append(src.Attrs, model.TileAttrSettlements)
+				}
+				if len(e.Rsrc) != 0 {
+					src.Attrs = append(src.Attrs, model.TileAttrResources)
+				}
+			}
+			t.Borders = append(t.Borders, b...)
+			if len(b) != 0 {
+				src.Attrs = append(src.Attrs, model.TileAttrBorders)
+			}
+			t.Src = append(t.Src, src)
+		}
+	}
+
+	for _, table := range derivedTables {
+		tr := TableRows{Table: table}
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table+derivedGameFilter(table, gameID), args...).Scan(&tr.Rows); err != nil {
+			return stats, dbError(fmt.Sprintf("count %s", table), err)
+		}
+		stats.Replaced = append(stats.Replaced, tr)
+	}
+	if dryRun {
+		stats.Tiles, stats.Games = len(order), len(games)
+		return stats, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, dbError("begin", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range derivedTables {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+derivedGameFilter(table, gameID), args...); err != nil {
+			return stats, dbError(fmt.Sprintf("delete %s", table), err)
+		}
+	}
+	for _, key := range order {
+		best, _ := model.BestTerrain(terrs[key])
+		tiles[key].Terr = best.Terr
+		for _, src := range tiles[key].Src {
+			if best.Terr != "" && srcTerr[src] == best.Terr {
+				src.Attrs = slices.Insert(src.Attrs, 0, model.TileAttrTerrain)
+			}
+		}
+		if err := insertTile(ctx, tx, key.game, turnOf[key], best.Score, tiles[key]); err != nil {
+			return stats, err
+		}
+		stats.Tiles++
+	}
+	if err := bumpGeneration(ctx, tx); err != nil {
+		return stats, err
+	}
+	if err := tx.Commit(); err != nil {
+		return stats, dbError("commit", err)
+	}
+
+	for _, game := range games {
+		n, err := s.RefreshUnitEvents(ctx, game)
+		if err != nil {
+			return stats, dbError(fmt.Sprintf("%s: unit events", game), err)
+		}
+		stats.UnitEvents += n
+	}
+	stats.Games = len(games)
+	return stats, nil
+}
+
+// derivedGameFilter returns the WHERE clause that limits a derived table to
+// one game, or nothing if gameID is empty. The game is its only parameter.
+func derivedGameFilter(table, gameID string) string {
+	switch {
+	case gameID == "":
+		return ""
+	case table == "tiles" || table == "unit_events":
+		return ` WHERE game = ?`
+	default:
+		return ` WHERE tile_id IN (SELECT id FROM tiles WHERE game = ?)`
+	}
+}
+
+type derivedUnit struct {
+	game  string
+	docID int64 // report_file_id
+	unit  *model.UnitX
+}
+
+// unitsWithSteps loads units with their acts and steps (but not encounters,
+// borders, or notes), ordered by game and turn. filter, if not empty, is added
+// to the WHERE clause and may refer to the report (r) and unit (u) columns.
+// It reads everything in one query rather than unit by unit since callers walk
+// whole games or clans.
+func (s *SQLiteStore) unitsWithSteps(ctx context.Context, filter string, args ...any) ([]*derivedUnit, error) {
+	query := `
+		SELECT r.game, r.report_file_id,
+		       u.id, u.unit_id, u.turn_no,
+		       u.start_grid, u.start_col, u.start_row,
+		       u.end_grid, u.end_col, u.end_row,
+		       a.id, a.seq, a.kind, a.dest_grid, a.dest_col, a.dest_row,
+		       st.id, st.seq, st.kind, st.ok, st.dir, st.fail_why, st.terr, st.special, st.label
+		FROM unit_extracts u
+		JOIN report_extracts r ON u.report_x_id = r.id
+		LEFT JOIN acts a ON a.unit_x_id = u.id
+		LEFT JOIN steps st ON st.act_id = a.id
+	`
+	if filter != "" {
+		query += ` WHERE ` + filter
+	}
+	query += ` ORDER BY r.game, u.turn_no, r.id, u.id, a.seq, st.seq`
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError("query units", err)
+	}
+	defer rows.Close()
+
+	var units []*derivedUnit
+	var du *derivedUnit
+	var act *model.Act
+	for rows.Next() {
+		var game string
+		var docID, unitXID int64
+		var unitID string
+		var turnNo model.TurnNo
+		var startGrid, endGrid string
+		var startCol, startRow, endCol, endRow int
+		var actID, actSeq, destCol, destRow, stepID, stepSeq, ok, special sql.NullInt64
+		var actKind, destGrid, stepKind, dir, failWhy, terr, label sql.NullString
+		if err := rows.Scan(
+			&game, &docID,
+			&unitXID, &unitID, &turnNo,
+			&startGrid, &startCol, &startRow,
+			&endGrid, &endCol, &endRow,
+			&actID, &actSeq, &actKind, &destGrid, &destCol, &destRow,
+			&stepID, &stepSeq, &stepKind, &ok, &dir, &failWhy, &terr, &special, &label,
+		); err != nil {
+			return nil, dbError("scan unit", err)
+		}
+
+		if du == nil || du.unit.ID != unitXID {
+			du = &derivedUnit{game: game, docID: docID, unit: &model.UnitX{
+				ID:      unitXID,
+				UnitID:  unitID,
+				TurnNo:  turnNo,
+				StartTN: model.NewTNCoord(startGrid, startCol, startRow),
+				EndTN:   model.NewTNCoord(endGrid, endCol, endRow),
+			}}
+			units, act = append(units, du), nil
+		}
+		if !actID.Valid {
+			continue
+		}
+		if act == nil || act.ID != actID.Int64 {
+			act = &model.Act{ID: actID.Int64, Seq: int(actSeq.Int64), Kind: model.ActKind(actKind.String)}
+			if destGrid.Valid {
+				act.DestTN = model.NewTNCoord(destGrid.String, int(destCol.Int64), int(destRow.Int64))
+			}
+			du.unit.Acts = append(du.unit.Acts, act)
+		}
+		if !stepID.Valid {
+			continue
+		}
+		act.Steps = append(act.Steps, &model.Step{
+			ID:      stepID.Int64,
+			ActID:   act.ID,
+			Seq:     int(stepSeq.Int64),
+			Kind:    model.StepKind(stepKind.String),
+			Ok:      ok.Valid && ok.Int64 == 1,
+			Dir:     dir.String,
+			FailWhy: failWhy.String,
+			Terr:    terr.String,
+			Special: special.Int64 == 1,
+			Label:   label.String,
+		})
+	}
+	return units, rows.Err()
+}
+
+// derivedEncounters loads step encounters and borders, keyed by step id.
+// filter works as for unitsWithSteps; if empty, every step is loaded.
+func (s *SQLiteStore) derivedEncounters(ctx context.Context, filter string, args ...any) (map[int64]*model.Enc, map[int64][]*model.BorderObs, error) {
+	enc := map[int64]*model.Enc{}
+	encOf := func(stepID int64) *model.Enc {
+		if enc[stepID] == nil {
+			enc[stepID] = &model.Enc{}
+		}
+		return enc[stepID]
+	}
+	borders := map[int64][]*model.BorderObs{}
+
+	// each query reads a child table aliased x; scope limits it to the filter
+	scope := ` ORDER BY x.id`
+	if filter != "" {
+		scope = `
+			JOIN steps st ON x.step_id = st.id
+			JOIN acts a ON st.act_id = a.id
+			JOIN unit_extracts u ON a.unit_x_id = u.id
+			JOIN report_extracts r ON u.report_x_id = r.id
+			WHERE ` + filter + `
+			ORDER BY x.id`
+	}
+
+	type loader struct {
+		query string
+		scan  func(rows *sql.Rows) error
+	}
+	loaders := []loader{
+		{`SELECT x.step_id, x.unit_id, x.name, x.clan_no FROM step_enc_units x`, func(rows *sql.Rows) error {
+			var stepID int64
+			var u model.UnitSeen
+			var name, clanNo sql.NullString
+			if err := rows.Scan(&stepID, &u.UnitID, &name, &clanNo); err != nil {
+				return err
+			}
+			u.Name, u.ClanNo = name.String, clanNo.String
+			e := encOf(stepID)
+			e.Units = append(e.Units, &u)
+			return nil
+		}},
+		{`SELECT x.step_id, x.name, x.kind, x.clan_no FROM step_enc_sets x`, func(rows *sql.Rows) error {
+			var stepID int64
+			var set model.SettleSeen
+			var kind, clanNo sql.NullString
+			if err := rows.Scan(&stepID, &set.Name, &kind, &clanNo); err != nil {
+				return err
+			}
+			set.Kind, set.ClanNo = kind.String, clanNo.String
+			e := encOf(stepID)
+			e.Sets = append(e.Sets, &set)
+			return nil
+		}},
+		{`SELECT x.step_id, x.kind, x.qty FROM step_enc_rsrc x`, func(rows *sql.Rows) error {
+			var stepID int64
+			var r model.RsrcSeen
+			var qty sql.NullInt64
+			if err := rows.Scan(&stepID, &r.Kind, &qty); err != nil {
+				return err
+			}
+			r.Qty = int(qty.Int64)
+			e := encOf(stepID)
+			e.Rsrc = append(e.Rsrc, &r)
+			return nil
+		}},
+		{`SELECT x.step_id, x.dir, x.kind FROM step_borders x`, func(rows *sql.Rows) error {
+			var stepID int64
+			var b model.BorderObs
+			if err := rows.Scan(&stepID, &b.Dir, &b.Kind); err != nil {
+				return err
+			}
+			borders[stepID] = append(borders[stepID], &b)
+			return nil
+		}},
+	}
+	for _, l := range loaders {
+		rows, err := s.db.QueryContext(ctx, l.query+scope, args...)
+		if err != nil {
+			return nil, nil, dbError("query encounters", err)
+		}
+		for rows.Next() {
+			if err := l.scan(rows); err != nil {
+				rows.Close()
+				return nil, nil, dbError("scan encounter", err)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, nil, dbError("query encounters", err)
+		}
+	}
+	return enc, borders, nil
+}
+
+func insertTile(ctx context.Context, tx *sql.Tx, game string, turnNo model.TurnNo, terrScore int, t *model.Tile) error {
+	var grid sql.NullString
+	var col, row sql.NullInt64
+	if t.TN != "" {
+		g, c, r, err := t.TN.Parse()
+		if err != nil {
+			return fmt.Errorf("insert tile: %w", err)
+		}
+		grid, col, row = nullString(g), sql.NullInt64{Int64: int64(c), Valid: true}, sql.NullInt64{Int64: int64(r), Valid: true}
+	}
+	var score sql.NullInt64
+	if t.Terr != "" {
+		score = sql.NullInt64{Int64: int64(terrScore), Valid: true}
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO tiles (game, hex, grid, col, row, turn_no, terr, terr_score, special_label) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		game, t.Hex.ConciseString(), grid, col, row, turnNo, nullString(t.Terr), score, nullString(t.SpecialLabel))
+	if err != nil {
+		return dbError("insert tile", err)
+	}
+	tileID, err := res.LastInsertId()
+	if err != nil {
+		return dbError("insert tile", err)
+	}
+	for _, u := range t.Units {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tile_units (tile_id, unit_id, name, clan_no) VALUES (?, ?, ?, ?)`,
+			tileID, u.UnitID, nullString(u.Name), nullString(u.ClanNo)); err != nil {
+			return dbError("insert tile unit", err)
+		}
+	}
+	for _, set := range t.Sets {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tile_sets (tile_id, name, kind, clan_no) VALUES (?, ?, ?, ?)`,
+			tileID, set.Name, nullString(set.Kind), nullString(set.ClanNo)); err != nil {
+			return dbError("insert tile settlement", err)
+		}
+	}
+	for _, r := range t.Rsrc {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tile_rsrc (tile_id, kind, qty) VALUES (?, ?, ?)`,
+			tileID, r.Kind, r.Qty); err != nil {
+			return dbError("insert tile resource", err)
+		}
+	}
+	for _, b := range t.Borders {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tile_borders (tile_id, dir, kind) VALUES (?, ?, ?)`,
+			tileID, b.Dir, b.Kind); err != nil {
+			return dbError("insert tile border", err)
+		}
+	}
+	for _, src := range t.Src {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tile_src (tile_id, doc_id, unit_id, turn_no, act_seq, step_seq, attrs) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			tileID, src.DocID, src.UnitID, src.TurnNo, src.ActSeq, src.StepSeq, nullString(joinTileAttrs(src.Attrs))); err != nil {
+			return dbError("insert tile source", err)
+		}
+		if src.StepID != 0 {
+			if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO step_tiles (step_id, tile_id) VALUES (?, ?)`, src.StepID, tileID); err != nil {
+				return dbError("insert step tile", err)
+			}
+		}
+	}
+	return nil
+}
+
+// dropTileAttrs removes attributes that a newer source has replaced.
+func dropTileAttrs(attrs []model.TileAttr, drop ...model.TileAttr) []model.TileAttr {
+	return slices.DeleteFunc(attrs, func(a model.TileAttr) bool { return slices.Contains(drop, a) })
+}
+
+// joinTileAttrs and splitTileAttrs convert between a TileSrc's attributes
+// and the tile_src.attrs column.
+func joinTileAttrs(attrs []model.TileAttr) string {
+	parts := make([]string, len(attrs))
+	for i, a := range attrs {
+		parts[i] = string(a)
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitTileAttrs(s string) []model.TileAttr {
+	var attrs []model.TileAttr
+	for _, part := range strings.Split(s, ",") {
+		if part != "" {
+			attrs = append(attrs, model.TileAttr(part))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// dbError wraps an error from the database with the cerrs code for it, so
+// that callers can tell a locked database or a duplicate row from any other
+// failure. Other constraint failures (a missing parent, a NULL) are bugs in
+// the store, not in the caller's input, so they are plain database errors.
+// An error that already has a code, like one from another store method,
+// keeps it. The message is "op: err", the same as fmt.Errorf("op: %w", err).
+func dbError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var coder cerrs.Coder
+	if errors.As(err, &coder) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	code := cerrs.CodeDatabase
+	var se *sqlite.Error
+	if errors.As(err, &se) {
+		// extended result codes keep the primary code in the low byte
+		switch se.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+			code = cerrs.CodeDatabaseBusy
+		case sqlite3.SQLITE_CONSTRAINT:
+			if se.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || se.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+				code = cerrs.CodeConflict
+			}
+		}
+	} else if errors.Is(err, sql.ErrNoRows) {
+		code = cerrs.CodeNotFound
+	}
+	return cerrs.Wrap(code, op, err)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// Audit log actions for feature flags.
+const (
+	AuditFlagSet   = "flag.set"   // a feature flag was turned on or off
+	AuditFlagClear = "flag.clear" // a feature flag setting was removed
+)
+
+// FeatureFlags returns every flag setting, the deployment's first, then by
+// game and name.
+func (s *SQLiteStore) FeatureFlags(ctx context.Context) ([]model.FeatureFlag, error) {
+	const query = `
+		SELECT name, game_id, enabled, updated_by, updated_at
+		FROM feature_flags
+		ORDER BY game_id, name
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("query feature flags", err)
+	}
+	defer rows.Close()
+
+	var flags []model.FeatureFlag
+	for rows.Next() {
+		var f model.FeatureFlag
+		var updatedAt string
+		if err := rows.Scan(&f.Name, &f.GameID, &f.Enabled, &f.UpdatedBy, &updatedAt); err != nil {
+			return nil, dbError("scan feature flag", err)
+		}
+		f.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		flags = append(flags, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("iterate feature flags", err)
+	}
+	return flags, nil
+}
+
+// FlagsForGame returns the flags in effect for the game (see model.ResolveFlags).
+// An empty gameID returns the deployment's flags.
+func (s *SQLiteStore) FlagsForGame(ctx context.Context, gameID string) (model.Flags, error) {
+	set, err := s.FeatureFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.ResolveFlags(set, gameID), nil
+}
+
+// SetFeatureFlag turns a flag on or off for the deployment, or for one game
+// if gameID isn't empty.
+func (s *SQLiteStore) SetFeatureFlag(ctx context.Context, actor, name, gameID string, enabled bool) error {
+	if !model.IsFeatureFlag(name) {
+		return cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("unknown feature flag %q", name))
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+		INSERT INTO feature_flags (name, game_id, enabled, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name, game_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`
+	if _, err := tx.ExecContext(ctx, query, name, gameID, enabled, actor, s.now().Format(time.RFC3339)); err != nil {
+		return dbError("set feature flag", err)
+	}
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditFlagSet, gameID, name+" "+state); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("commit", err)
+	}
+	return nil
+}
+
+// ClearFeatureFlag removes a flag's setting for the deployment or the game,
+// so the game falls back to the deployment's setting and the deployment to
+// off. Returns false if the flag wasn't set.
+func (s *SQLiteStore) ClearFeatureFlag(ctx context.Context, actor, name, gameID string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM feature_flags WHERE name = ? AND game_id = ?`, name, gameID)
+	if err != nil {
+		return false, dbError("clear feature flag", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditFlagClear, gameID, name); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, dbError("commit", err)
+	}
+	return true, nil
+}
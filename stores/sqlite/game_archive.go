@@ -0,0 +1,495 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// AuditGameImport is the audit log action for a game imported from an archive.
+const AuditGameImport = "game.import"
+
+// GameArchiveFormat is the version of GameArchive that ExportGame writes.
+// ImportGame refuses archives from a later version.
+const GameArchiveFormat = 1
+
+// GameArchive is everything stored for one game, for handing a campaign to
+// another GM or server (see ExportGame and ImportGame).
+//
+// Report files keep the IDs they had in the exporting database so that
+// reports can refer to them; ImportGame assigns new ones. Tiles and unit
+// events are derived from the reports, so only the number of tiles is
+// carried, as a check on the import: ImportGame warns if it derives a
+// different number.
+type GameArchive struct {
+	Format     int                 `json:"format"`
+	ExportedAt time.Time           `json:"exported-at"`
+	Game       ArchiveGame         `json:"game"`
+	Users      []UserRecord        `json:"users"` // users with a clan in the game; clans in other games are left out
+	Files      []*model.ReportFile `json:"files"`
+	Reports    []*model.ReportX    `json:"reports"` // with units, acts, steps, encounters, and borders
+	Orders     []ArchiveOrders     `json:"orders,omitempty"`
+	Tiles      int                 `json:"tiles"`
+}
+
+// ArchiveGame is a game's settings and turns in a GameArchive.
+type ArchiveGame struct {
+	ID          string        `json:"id"`
+	Description string        `json:"description,omitempty"`
+	AutoAdvance bool          `json:"auto-advance,omitempty"`
+	ClanDigits  int           `json:"clan-digits"`
+	GridRows    int           `json:"grid-rows"`
+	GridCols    int           `json:"grid-cols"`
+	WrapRows    bool          `json:"wrap-rows,omitempty"`
+	WrapCols    bool          `json:"wrap-cols,omitempty"`
+	Turns       []ArchiveTurn `json:"turns"`
+}
+
+// ArchiveTurn is a game turn in a GameArchive.
+type ArchiveTurn struct {
+	TurnNo  model.TurnNo `json:"turn-no"`
+	Active  bool         `json:"active,omitempty"`
+	DueDate time.Time    `json:"due-date,omitzero"` // UTC
+}
+
+// ArchiveOrders is a clan's imported orders for a turn in a GameArchive.
+type ArchiveOrders struct {
+	ClanNo int           `json:"clan-no"`
+	TurnNo model.TurnNo  `json:"turn-no"`
+	Orders []model.Order `json:"orders"`
+}
+
+// GameImport summarizes an ImportGame call.
+type GameImport struct {
+	Users   int `json:"users"` // users created; users that already existed are only given their clans
+	Files   int `json:"files"`
+	Reports int `json:"reports"`
+	Units   int `json:"units"`
+	Tiles   int `json:"tiles"` // tiles derived for the game after the import
+}
+
+// ExportGame reads everything stored for a game into a GameArchive. With
+// redact set, users' email addresses and password hashes are left out; the
+// users are still listed so that clans keep their owners. It fails with
+// cerrs.CodeNotFound if the game doesn't exist.
+//
+// The report files' blobs are not read; see stages.ExportGame.
+func (s *SQLiteStore) ExportGame(ctx context.Context, gameID string, redact bool) (*GameArchive, error) {
+	a := &GameArchive{Format: GameArchiveFormat, ExportedAt: s.now().UTC()}
+	g := &a.Game
+	const gameQuery = `SELECT id, COALESCE(description, ''), auto_advance, clan_digits, grid_rows, grid_cols, wrap_rows, wrap_cols FROM games WHERE id = ?`
+	err := s.db.QueryRowContext(ctx, gameQuery, gameID).
+		Scan(&g.ID, &g.Description, &g.AutoAdvance, &g.ClanDigits, &g.GridRows, &g.GridCols, &g.WrapRows, &g.WrapCols)
+	if err == sql.ErrNoRows {
+		return nil, cerrs.New(cerrs.CodeNotFound, fmt.Sprintf("game %s not found", gameID))
+	} else if err != nil {
+		return nil, dbError("query game", err)
+	}
+
+	all, err := s.GetAllGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range all {
+		if game.ID != gameID {
+			continue
+		}
+		for _, t := range game.Turns {
+			g.Turns = append(g.Turns, ArchiveTurn{TurnNo: t.TurnNo, Active: t.IsActive, DueDate: t.DueDate})
+		}
+	}
+
+	users, err := s.ExportUsers(ctx, !redact)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		var clans []UserClanNo
+		for _, c := range u.Clans {
+			if c.Game == gameID {
+				clans = append(clans, c)
+			}
+		}
+		if len(clans) == 0 {
+			continue
+		}
+		u.Clans = clans
+		if redact {
+			u.Email = ""
+		}
+		a.Users = append(a.Users, u)
+	}
+
+	const filesQuery = `
+		SELECT id, game, clan_no, turn_no, name, sha256, mime, created_at, fs_path, batch_id
+		FROM report_files
+		WHERE game = ?
+		ORDER BY id
+	`
+	rows, err := s.db.QueryContext(ctx, filesQuery, gameID)
+	if err != nil {
+		return nil, dbError("query report files", err)
+	}
+	a.Files, err = scanReportFiles(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	turns, err := s.reportTurns(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	for _, turnNo := range turns {
+		reports, err := s.ReportExtractsByGameTurn(ctx, gameID, turnNo)
+		if err != nil {
+			return nil, err
+		}
+		a.Reports = append(a.Reports, reports...)
+	}
+	enc, borders, err := s.derivedEncounters(ctx, `r.game = ?`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rx := range a.Reports {
+		for _, u := range rx.Units {
+			for _, act := range u.Acts {
+				for _, st := range act.Steps {
+					st.Enc, st.Borders = enc[st.ID], borders[st.ID]
+				}
+			}
+		}
+		rx.SetKeys()
+	}
+
+	if a.Orders, err = s.archiveOrders(ctx, gameID); err != nil {
+		return nil, err
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tiles WHERE game = ?`, gameID).Scan(&a.Tiles); err != nil {
+		return nil, dbError("count tiles", err)
+	}
+	return a, nil
+}
+
+// reportTurns returns the turns a game has report extracts for, in order.
+func (s *SQLiteStore) reportTurns(ctx context.Context, gameID string) ([]model.TurnNo, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT turn_no FROM report_extracts WHERE game = ? ORDER BY turn_no`, gameID)
+	if err != nil {
+		return nil, dbError("query report turns", err)
+	}
+	defer rows.Close()
+	var turns []model.TurnNo
+	for rows.Next() {
+		var turnNo model.TurnNo
+		if err := rows.Scan(&turnNo); err != nil {
+			return nil, dbError("scan report turn", err)
+		}
+		turns = append(turns, turnNo)
+	}
+	return turns, rows.Err()
+}
+
+// archiveOrders returns a game's imported orders, grouped by clan and turn.
+func (s *SQLiteStore) archiveOrders(ctx context.Context, gameID string) ([]ArchiveOrders, error) {
+	const query = `
+		SELECT clan_no, turn_no, unit_id, line_no, kind, scout_no, dirs, target, dest
+		FROM orders
+		WHERE game_id = ?
+		ORDER BY clan_no, turn_no, line_no, id
+	`
+	rows, err := s.db.QueryContext(ctx, query, gameID)
+	if err != nil {
+		return nil, dbError("query orders", err)
+	}
+	defer rows.Close()
+	var list []ArchiveOrders
+	for rows.Next() {
+		var clanNo int
+		var turnNo model.TurnNo
+		var o model.Order
+		var dirs, dest string
+		if err := rows.Scan(&clanNo, &turnNo, &o.UnitID, &o.LineNo, &o.Kind, &o.ScoutNo, &dirs, &o.Target, &dest); err != nil {
+			return nil, dbError("scan order", err)
+		}
+		if dirs != "" {
+			o.Dirs = strings.Split(dirs, "-")
+		}
+		o.DestTN = model.TNCoord(dest)
+		if n := len(list); n == 0 || list[n-1].ClanNo != clanNo || list[n-1].TurnNo != turnNo {
+			list = append(list, ArchiveOrders{ClanNo: clanNo, TurnNo: turnNo})
+		}
+		list[len(list)-1].Orders = append(list[len(list)-1].Orders, o)
+	}
+	return list, rows.Err()
+}
+
+// ImportGame creates a game from a GameArchive and records the import, made
+// by actor, in the audit log. It fails with cerrs.CodeConflict if the game
+// already exists and with cerrs.CodeInvalidInput if the archive doesn't hold
+// together.
+//
+// Users that don't exist are created with the archive's roles, except that
+// the "gm" role is only granted with grantGM: a GM of the exporting server
+// is not trusted to be one here. Users without a password hash can't log in
+// until one is set. Users that already exist keep their details and roles
+// and are only given their clans.
+//
+// The report files must already point at their blobs under the data
+// directory (see stages.ImportGame). Reports are written file by file; if
+// one fails, everything the import wrote is removed. The game's derived
+// tables are rebuilt at the end.
+func (s *SQLiteStore) ImportGame(ctx context.Context, actor string, a *GameArchive, grantGM bool) (*GameImport, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	g := a.Game
+	ids := model.UnitIDRules{ClanDigits: g.ClanDigits}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM games WHERE id = ?)`, g.ID).Scan(&exists); err != nil {
+		return nil, dbError("query game", err)
+	} else if exists {
+		return nil, cerrs.New(cerrs.CodeConflict, fmt.Sprintf("game %s already exists", g.ID))
+	}
+	const gameQuery = `
+		INSERT INTO games (id, description, auto_advance, clan_digits, grid_rows, grid_cols, wrap_rows, wrap_cols)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, gameQuery, g.ID, nullString(g.Description), g.AutoAdvance, g.ClanDigits, g.GridRows, g.GridCols, g.WrapRows, g.WrapCols); err != nil {
+		return nil, dbError("insert game", err)
+	}
+	for _, t := range g.Turns {
+		const turnQuery = `INSERT INTO game_turns (game_id, turn_id, year, month, is_active, due_date) VALUES (?, ?, ?, ?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, turnQuery, g.ID, t.TurnNo, t.TurnNo.Year(), t.TurnNo.Month(), t.Active, formatDueDate(t.DueDate)); err != nil {
+			return nil, dbError(fmt.Sprintf("insert turn %s", t.TurnNo), err)
+		}
+	}
+
+	result := &GameImport{}
+	var created []string
+	now := s.now().Format(time.RFC3339)
+	for _, u := range a.Users {
+		var found bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE handle = ?)`, u.Handle).Scan(&found); err != nil {
+			return nil, dbError(fmt.Sprintf("query user %s", u.Handle), err)
+		}
+		if !found {
+			hash, userName, createdAt := u.PasswordHash, u.UserName, u.CreatedAt
+			if hash == "" {
+				hash = invalidPasswordHash
+			}
+			if userName == "" {
+				userName = u.Handle
+			}
+			if createdAt == "" {
+				createdAt = now
+			}
+			const userQuery = `INSERT INTO users (handle, user_name, email, timezone, password_hash, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+			if _, err := tx.ExecContext(ctx, userQuery, u.Handle, userName, nullString(u.Email), nullString(u.Timezone), hash, createdAt); err != nil {
+				return nil, dbError(fmt.Sprintf("insert user %s", u.Handle), err)
+			}
+			for _, role := range u.Roles {
+				if role == "gm" && !grantGM {
+					continue
+				}
+				if _, err := tx.ExecContext(ctx, `INSERT INTO user_roles (user_handle, role) VALUES (?, ?) ON CONFLICT DO NOTHING`, u.Handle, role); err != nil {
+					return nil, dbError(fmt.Sprintf("insert role for %s", u.Handle), err)
+				}
+			}
+			created = append(created, u.Handle)
+		}
+		for _, c := range u.Clans {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO game_clans (game_id, user_handle, clan_no) VALUES (?, ?, ?)`, g.ID, u.Handle, c.Clan); err != nil {
+				return nil, dbError(fmt.Sprintf("assign %s to clan %d", u.Handle, c.Clan), err)
+			}
+		}
+	}
+	result.Users = len(created)
+
+	for _, list := range a.Orders {
+		const orderQuery = `
+			INSERT INTO orders (game_id, clan_no, turn_no, unit_id, line_no, kind, scout_no, dirs, target, dest, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`
+		for _, o := range list.Orders {
+			if _, err := tx.ExecContext(ctx, orderQuery, g.ID, list.ClanNo, list.TurnNo, o.UnitID, o.LineNo, string(o.Kind), o.ScoutNo,
+				strings.Join(o.Dirs, "-"), o.Target, string(o.DestTN), now); err != nil {
+				return nil, dbError(fmt.Sprintf("insert order: %s", o.UnitID), err)
+			}
+		}
+	}
+
+	detail := fmt.Sprintf("imported game %s exported %s: %d report files, %d reports, %d new users",
+		g.ID, a.ExportedAt.UTC().Format(time.RFC3339), len(a.Files), len(a.Reports), len(created))
+	if err := s.insertAuditLog(ctx, tx, actor, AuditGameImport, g.ID, detail); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("commit", err)
+	}
+
+	if err := s.importGameReports(ctx, a, ids, result); err != nil {
+		if cleanupErr := s.removeImportedGame(ctx, g.ID, created); cleanupErr != nil {
+			return nil, fmt.Errorf("%w (removing the partial import: %v)", err, cleanupErr)
+		}
+		return nil, err
+	}
+
+	stats, err := s.RebuildDerivedGame(ctx, g.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.Tiles = stats.Tiles
+	if result.Tiles != a.Tiles {
+		logging.FromContext(ctx).Warn("store: import game: tile count differs from the archive", logging.KeyGame, g.ID, "tiles", result.Tiles, "archive_tiles", a.Tiles)
+	}
+	return result, nil
+}
+
+// importGameReports writes an archive's report files and reports, giving the
+// units, acts, and steps provenance that points at their new report file.
+func (s *SQLiteStore) importGameReports(ctx context.Context, a *GameArchive, ids model.UnitIDRules, result *GameImport) error {
+	fileIDs := map[int64]int64{}
+	for _, rf := range a.Files {
+		old := rf.ID
+		rf.Game, rf.BatchID = a.Game.ID, nil
+		if _, err := s.InsertReportFileWithBatch(ctx, rf); err != nil {
+			return err
+		}
+		fileIDs[old] = rf.ID
+		result.Files++
+	}
+
+	for _, rx := range a.Reports {
+		rx.Game, rx.ReportFileID = a.Game.ID, fileIDs[rx.ReportFileID]
+		rxID, err := s.InsertReportExtract(ctx, rx)
+		if err != nil {
+			return err
+		}
+		for _, u := range rx.Units {
+			clanID, err := ids.ClanID(u.UnitID)
+			if err != nil {
+				return cerrs.Wrap(cerrs.CodeInvalidInput, fmt.Sprintf("import game: report %s", rx.Key), err)
+			}
+			u.ReportXID, u.ClanID = rxID, clanID
+			u.Src = &model.SrcRef{DocID: rx.ReportFileID, UnitID: u.UnitID, TurnNo: u.TurnNo}
+			uxID, err := s.InsertUnitExtract(ctx, u)
+			if err != nil {
+				return err
+			}
+			for _, act := range u.Acts {
+				act.UnitXID = uxID
+				act.Src = &model.SrcRef{DocID: rx.ReportFileID, UnitID: u.UnitID, TurnNo: u.TurnNo, ActSeq: act.Seq}
+				actID, err := s.InsertAct(ctx, act)
+				if err != nil {
+					return err
+				}
+				for _, st := range act.Steps {
+					st.ActID = actID
+					st.Src = &model.SrcRef{DocID: rx.ReportFileID, UnitID: u.UnitID, TurnNo: u.TurnNo, ActSeq: act.Seq, StepSeq: st.Seq}
+					if _, err := s.InsertStep(ctx, st); err != nil {
+						return err
+					}
+				}
+			}
+			result.Units++
+		}
+		result.Reports++
+	}
+	return nil
+}
+
+// removeImportedGame undoes a failed ImportGame: the game, its report files
+// and everything parsed from them, and the users the import created.
+func (s *SQLiteStore) removeImportedGame(ctx context.Context, gameID string, users []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM report_files WHERE game = ?`, gameID); err != nil {
+		return dbError("delete report files", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM games WHERE id = ?`, gameID); err != nil {
+		return dbError("delete game", err)
+	}
+	for _, handle := range users {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE handle = ?`, handle); err != nil {
+			return dbError(fmt.Sprintf("delete user %s", handle), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("commit", err)
+	}
+	return nil
+}
+
+// validate checks that an archive can be imported before anything is written.
+func (a *GameArchive) validate() error {
+	invalid := func(format string, args ...any) error {
+		return cerrs.New(cerrs.CodeInvalidInput, "import game: "+fmt.Sprintf(format, args...))
+	}
+	if a.Format < 1 || a.Format > GameArchiveFormat {
+		return invalid("archive format %d is not supported (want 1 to %d)", a.Format, GameArchiveFormat)
+	}
+	g := a.Game
+	if g.ID == "" {
+		return invalid("missing game id")
+	}
+	if g.ClanDigits < model.MinClanDigits || g.ClanDigits > model.MaxClanDigits {
+		return invalid("clan digits must be %d or %d", model.MinClanDigits, model.MaxClanDigits)
+	}
+	world := model.WorldRules{GridRows: g.GridRows, GridCols: g.GridCols, WrapRows: g.WrapRows, WrapCols: g.WrapCols}
+	if err := world.Validate(); err != nil {
+		return invalid("%v", err)
+	}
+
+	seen := map[string]bool{}
+	clans := map[int]string{}
+	for _, u := range a.Users {
+		if u.Handle == "" {
+			return invalid("user with no handle")
+		} else if seen[u.Handle] {
+			return invalid("user %s is listed twice", u.Handle)
+		} else if u.PasswordHash != "" && !isBcryptHash(u.PasswordHash) {
+			return invalid("user %s: password-hash is not a bcrypt hash", u.Handle)
+		}
+		seen[u.Handle] = true
+		for _, c := range u.Clans {
+			if c.Game != g.ID {
+				return invalid("user %s has a clan in game %s", u.Handle, c.Game)
+			} else if owner, ok := clans[c.Clan]; ok {
+				return invalid("clan %d belongs to both %s and %s", c.Clan, owner, u.Handle)
+			}
+			clans[c.Clan] = u.Handle
+		}
+	}
+
+	files := map[int64]bool{}
+	for _, rf := range a.Files {
+		if files[rf.ID] {
+			return invalid("report file %d is listed twice", rf.ID)
+		}
+		files[rf.ID] = true
+	}
+	for _, rx := range a.Reports {
+		if !files[rx.ReportFileID] {
+			return invalid("report for clan %s turn %s refers to report file %d, which isn't in the archive", rx.ClanNo, rx.TurnNo, rx.ReportFileID)
+		}
+	}
+	return nil
+}
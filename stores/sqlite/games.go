@@ -0,0 +1,230 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// Games and turns are seeded from games.json at startup; these methods let a GM
+// change them while the server is running. Note that a restart re-applies
+// games.json, so edits to turns listed there are overwritten.
+
+// CreateGame adds a game. It returns false if a game with that id already exists.
+func (s *SQLiteStore) CreateGame(ctx context.Context, id, description string) (bool, error) {
+	const query = `INSERT INTO games (id, description) VALUES (?, ?) ON CONFLICT(id) DO NOTHING`
+	result, err := s.db.ExecContext(ctx, query, id, nullString(description))
+	if err != nil {
+		return false, dbError("create game", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, dbError("rows affected", err)
+	}
+	return n > 0, nil
+}
+
+// AddGameTurn adds a turn to a game, inactive and with an optional due date.
+// It returns false if the game already has the turn.
+func (s *SQLiteStore) AddGameTurn(ctx context.Context, gameID string, turnNo model.TurnNo, due time.Time) (bool, error) {
+	const query = `
+		INSERT INTO game_turns (game_id, turn_id, year, month, is_active, due_date)
+		VALUES (?, ?, ?, ?, 0, ?)
+		ON CONFLICT(game_id, turn_id) DO NOTHING
+	`
+	result, err := s.db.ExecContext(ctx, query, gameID, turnNo, turnNo.Year(), turnNo.Month(), formatDueDate(due))
+	if err != nil {
+		return false, dbError("add game turn", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, dbError("rows affected", err)
+	}
+	return n > 0, nil
+}
+
+// SetActiveTurn makes turnNo the game's only active turn and records the change,
+// made by actor, in the audit log.
+// It returns false, changing nothing, if the game doesn't have the turn.
+func (s *SQLiteStore) SetActiveTurn(ctx context.Context, actor, gameID string, turnNo model.TurnNo) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM game_turns WHERE game_id = ? AND turn_id = ?`, gameID, turnNo).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, dbError("query game turn", err)
+	}
+
+	const query = `UPDATE game_turns SET is_active = (id = ?) WHERE game_id = ? AND is_active != (id = ?)`
+	if _, err := tx.ExecContext(ctx, query, id, gameID, id); err != nil {
+		return false, dbError("set active turn", err)
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditTurnActivate, gameID, "activated turn "+turnNo.String()); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, dbError("commit", err)
+	}
+	return true, nil
+}
+
+// SetGameAutoAdvance turns automatic activation of the next turn on or off for a game.
+// It returns false if the game doesn't exist.
+func (s *SQLiteStore) SetGameAutoAdvance(ctx context.Context, gameID string, on bool) (bool, error) {
+	const query = `UPDATE games SET auto_advance = ? WHERE id = ?`
+	result, err := s.db.ExecContext(ctx, query, on, gameID)
+	if err != nil {
+		return false, dbError("set auto advance", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, dbError("rows affected", err)
+	}
+	return n > 0, nil
+}
+
+// TurnAdvance is a game's active turn being moved to the next turn because
+// its orders were due.
+type TurnAdvance struct {
+	GameID  string
+	From    model.TurnNo
+	To      model.TurnNo
+	DueDate time.Time // when orders for From were due
+}
+
+// AdvanceDueTurns activates the next turn of every auto-advancing game whose
+// active turn had orders due at or before now, recording each change in the
+// audit log as actor "system". Games without a later turn are left alone.
+func (s *SQLiteStore) AdvanceDueTurns(ctx context.Context, now time.Time) ([]TurnAdvance, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+		SELECT gt.game_id, gt.turn_id, gt.due_date,
+		       (SELECT MIN(n.turn_id) FROM game_turns n WHERE n.game_id = gt.game_id AND n.turn_id > gt.turn_id)
+		FROM game_turns gt
+		JOIN games g ON g.id = gt.game_id
+		WHERE g.auto_advance = 1 AND gt.is_active = 1 AND gt.due_date IS NOT NULL
+		ORDER BY gt.game_id, gt.turn_id DESC
+	`
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("query due turns", err)
+	}
+	var advances []TurnAdvance
+	seen := map[string]bool{}
+	for rows.Next() {
+		var ta TurnAdvance
+		var due string
+		var next sql.NullInt64
+		if err := rows.Scan(&ta.GameID, &ta.From, &due, &next); err != nil {
+			rows.Close()
+			return nil, dbError("scan due turn", err)
+		}
+		if seen[ta.GameID] {
+			continue // only the latest active turn counts
+		}
+		seen[ta.GameID] = true
+		if ta.DueDate, err = time.Parse(time.RFC3339, due); err != nil {
+			logging.FromContext(ctx).Warn("store: invalid due date", logging.KeyGame, ta.GameID, logging.KeyTurn, ta.From.String(), "due", due)
+			continue
+		}
+		if ta.DueDate.After(now) || !next.Valid {
+			continue
+		}
+		ta.To = model.TurnNo(next.Int64)
+		advances = append(advances, ta)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, dbError("close rows", err)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("iterate due turns", err)
+	}
+
+	const update = `UPDATE game_turns SET is_active = (turn_id = ?) WHERE game_id = ? AND is_active != (turn_id = ?)`
+	for _, ta := range advances {
+		if _, err := tx.ExecContext(ctx, update, ta.To, ta.GameID, ta.To); err != nil {
+			return nil, dbError(fmt.Sprintf("advance game %s", ta.GameID), err)
+		}
+		detail := fmt.Sprintf("advanced from turn %s to %s; orders were due %s", ta.From, ta.To, ta.DueDate.Format(time.RFC3339))
+		if err := s.insertAuditLog(ctx, tx, AuditActorSystem, AuditTurnAdvance, ta.GameID, detail); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("commit", err)
+	}
+	return advances, nil
+}
+
+// UnitIDRules returns the unit ID rules for a game, or the default rules if
+// the game doesn't exist.
+func (s *SQLiteStore) UnitIDRules(ctx context.Context, gameID string) (model.UnitIDRules, error) {
+	var rules model.UnitIDRules
+	err := s.db.QueryRowContext(ctx, `SELECT clan_digits FROM games WHERE id = ?`, gameID).Scan(&rules.ClanDigits)
+	if err == sql.ErrNoRows {
+		return model.DefaultUnitIDRules, nil
+	} else if err != nil {
+		return model.UnitIDRules{}, dbError("query unit id rules", err)
+	}
+	return rules, nil
+}
+
+// WorldRules returns the world map rules for a game, or the default rules if
+// the game doesn't exist.
+func (s *SQLiteStore) WorldRules(ctx context.Context, gameID string) (model.WorldRules, error) {
+	var world model.WorldRules
+	err := s.db.QueryRowContext(ctx, `SELECT grid_rows, grid_cols, wrap_rows, wrap_cols FROM games WHERE id = ?`, gameID).
+		Scan(&world.GridRows, &world.GridCols, &world.WrapRows, &world.WrapCols)
+	if err == sql.ErrNoRows {
+		return model.DefaultWorldRules, nil
+	} else if err != nil {
+		return model.WorldRules{}, dbError("query world rules", err)
+	}
+	return world, nil
+}
+
+// SetTurnDueDate sets or, if due is zero, clears the date orders are due for a turn.
+// It returns false if the game doesn't have the turn.
+func (s *SQLiteStore) SetTurnDueDate(ctx context.Context, gameID string, turnNo model.TurnNo, due time.Time) (bool, error) {
+	const query = `UPDATE game_turns SET due_date = ? WHERE game_id = ? AND turn_id = ?`
+	result, err := s.db.ExecContext(ctx, query, formatDueDate(due), gameID, turnNo)
+	if err != nil {
+		return false, dbError("set due date", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, dbError("rows affected", err)
+	}
+	return n > 0, nil
+}
+
+// ParseDueDate parses a due date in the games.json format,
+// "2025/11/15 18:00:00 Australia/Sydney", and returns it in UTC.
+func ParseDueDate(s string) (time.Time, error) {
+	return parseDueDateWithTimezone(s)
+}
+
+// formatDueDate returns the value stored in game_turns.due_date; NULL for the zero time.
+func formatDueDate(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.UTC().Format(time.RFC3339), Valid: true}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Generation returns the store generation counter and when it last changed.
+// Triggers bump the counter whenever reports, units, turns, clan membership,
+// shares, or user preferences change, and the writers of derived data (tiles
+// and unit events) bump it with bumpGeneration, so two reads that return the
+// same generation saw the same data.
+func (s *SQLiteStore) Generation(ctx context.Context) (int64, time.Time, error) {
+	const query = `SELECT gen, updated_at FROM store_generation WHERE id = 1`
+	var gen int64
+	var updatedAt string
+	if err := s.db.QueryRowContext(ctx, query).Scan(&gen, &updatedAt); err != nil {
+		return 0, time.Time{}, dbError("generation", err)
+	}
+	t, err := time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("generation: updated_at: %w", err)
+	}
+	return gen, t, nil
+}
+
+// bumpGeneration marks the store changed. Derived tables are rewritten
+// wholesale, so they bump the counter once per rebuild rather than by trigger.
+func bumpGeneration(ctx context.Context, db execer) error {
+	const query = `UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1`
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return dbError("bump generation", err)
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mdhender/tnrpt/model"
 	"github.com/mdhender/tnrpt/web/auth"
 )
 
@@ -28,6 +29,12 @@ type jsonUser struct {
 type jsonGame struct {
 	ID          string `json:"id"`
 	Description string `json:"description"`
+	AutoAdvance bool   `json:"auto-advance"` // activate the next turn when orders are due
+	ClanDigits  int    `json:"clan-digits"`  // digits in a tribe id; 4 if not set
+	GridRows    int    `json:"grid-rows"`    // grid row letters in the world map; 26 if not set
+	GridCols    int    `json:"grid-cols"`    // grid column letters in the world map; 26 if not set
+	WrapRows    bool   `json:"wrap-rows"`    // moving off the north or south edge wraps
+	WrapCols    bool   `json:"wrap-cols"`    // moving off the east or west edge wraps
 	Clans       []struct {
 		Handle string `json:"handle"`
 		Clan   int    `json:"clan"`
@@ -41,7 +48,10 @@ type jsonGame struct {
 	} `json:"turns"`
 }
 
-func loadUsersFromJSON(ctx context.Context, db *sql.DB, path string) error {
+// invalidPasswordHash is a bcrypt hash that will never match any password.
+const invalidPasswordHash = "$2a$10$INVALID.HASH.THAT.WILL.NEVER.MATCH.ANY.PASSWORD.EVER"
+
+func loadUsersFromJSON(ctx context.Context, db *sql.DB, path string, createdAt time.Time) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read users file: %w", err)
@@ -52,9 +62,7 @@ func loadUsersFromJSON(ctx context.Context, db *sql.DB, path string) error {
 		return fmt.Errorf("parse users json: %w", err)
 	}
 
-	now := time.Now().Format(time.RFC3339)
-	// Invalid bcrypt hash that will never match any password
-	const invalidHash = "$2a$10$INVALID.HASH.THAT.WILL.NEVER.MATCH.ANY.PASSWORD.EVER"
+	now := createdAt.Format(time.RFC3339)
 
 	for _, ju := range users {
 		isActive := hasRole(ju.Roles, "active")
@@ -68,7 +76,7 @@ func loadUsersFromJSON(ctx context.Context, db *sql.DB, path string) error {
 			}
 		} else {
 			// Inactive users get an invalid hash so they can never log in
-			hash = invalidHash
+			hash = invalidPasswordHash
 		}
 
 		userName := ju.UserName
@@ -86,7 +94,7 @@ func loadUsersFromJSON(ctx context.Context, db *sql.DB, path string) error {
 				password_hash = excluded.password_hash
 		`, ju.Handle, userName, ju.Email, ju.Timezone, hash, now)
 		if err != nil {
-			return fmt.Errorf("insert user %s: %w", ju.Handle, err)
+			return dbError(fmt.Sprintf("insert user %s", ju.Handle), err)
 		}
 
 		// Delete existing roles and insert new ones
@@ -97,7 +105,7 @@ func loadUsersFromJSON(ctx context.Context, db *sql.DB, path string) error {
 				ON CONFLICT DO NOTHING
 			`, ju.Handle, role)
 			if err != nil {
-				return fmt.Errorf("insert role for %s: %w", ju.Handle, err)
+				return dbError(fmt.Sprintf("insert role for %s", ju.Handle), err)
 			}
 		}
 	}
@@ -117,21 +125,31 @@ func loadGamesFromJSON(ctx context.Context, db *sql.DB, path string) error {
 	}
 
 	for _, jg := range games {
+		if jg.ClanDigits == 0 {
+			jg.ClanDigits = model.DefaultUnitIDRules.ClanDigits
+		} else if jg.ClanDigits < model.MinClanDigits || jg.ClanDigits > model.MaxClanDigits {
+			return fmt.Errorf("game %s: clan-digits must be %d or %d", jg.ID, model.MinClanDigits, model.MaxClanDigits)
+		}
+		world := model.WorldRules{GridRows: jg.GridRows, GridCols: jg.GridCols, WrapRows: jg.WrapRows, WrapCols: jg.WrapCols}
+		if err := world.Validate(); err != nil {
+			return fmt.Errorf("game %s: %w", jg.ID, err)
+		}
 		_, err = db.ExecContext(ctx, `
-			INSERT INTO games (id, description) VALUES (?, ?)
-			ON CONFLICT(id) DO UPDATE SET description = excluded.description
-		`, jg.ID, jg.Description)
+			INSERT INTO games (id, description, auto_advance, clan_digits, grid_rows, grid_cols, wrap_rows, wrap_cols) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET description = excluded.description, auto_advance = excluded.auto_advance, clan_digits = excluded.clan_digits,
+				grid_rows = excluded.grid_rows, grid_cols = excluded.grid_cols, wrap_rows = excluded.wrap_rows, wrap_cols = excluded.wrap_cols
+		`, jg.ID, jg.Description, jg.AutoAdvance, jg.ClanDigits, world.Rows(), world.Cols(), world.WrapRows, world.WrapCols)
 		if err != nil {
-			return fmt.Errorf("insert game %s: %w", jg.ID, err)
+			return dbError(fmt.Sprintf("insert game %s", jg.ID), err)
 		}
 
 		for _, c := range jg.Clans {
 			_, err = db.ExecContext(ctx, `
 				INSERT INTO game_clans (game_id, user_handle, clan_no) VALUES (?, ?, ?)
-				ON CONFLICT(game_id, user_handle) DO UPDATE SET clan_no = excluded.clan_no
+				ON CONFLICT(game_id, clan_no) DO UPDATE SET user_handle = excluded.user_handle
 			`, jg.ID, c.Handle, c.Clan)
 			if err != nil {
-				return fmt.Errorf("insert game clan %s/%s: %w", jg.ID, c.Handle, err)
+				return dbError(fmt.Sprintf("insert game clan %s/%s", jg.ID, c.Handle), err)
 			}
 		}
 
@@ -157,7 +175,7 @@ func loadGamesFromJSON(ctx context.Context, db *sql.DB, path string) error {
 				ON CONFLICT(game_id, turn_id) DO UPDATE SET year = excluded.year, month = excluded.month, is_active = excluded.is_active, due_date = excluded.due_date
 			`, jg.ID, t.ID, t.Year, t.Month, isActive, dueDateUTC)
 			if err != nil {
-				return fmt.Errorf("insert game turn %s/%d: %w", jg.ID, t.ID, err)
+				return dbError(fmt.Sprintf("insert game turn %s/%d", jg.ID, t.ID), err)
 			}
 		}
 	}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// IDByKey returns the database ID of the report extract, unit, act, or step
+// with the natural key k, or 0 if there is none. If a clan has more than one
+// extract for the turn, the most recent one is used.
+func (s *SQLiteStore) IDByKey(ctx context.Context, k model.Key) (int64, error) {
+	if (k.UnitID == "" && k.ActSeq != 0) || (k.ActSeq == 0 && k.StepSeq != 0) {
+		return 0, fmt.Errorf("key %s: incomplete", k)
+	}
+
+	query := `SELECT r.id`
+	from := `
+		FROM report_extracts r`
+	where := `
+		WHERE r.game = ? AND r.clan_no = ? AND r.turn_no = ?`
+	args := []any{k.Game, k.ClanNo, k.TurnNo}
+	if k.UnitID != "" {
+		query = `SELECT u.id`
+		from += `
+		JOIN unit_extracts u ON u.report_x_id = r.id`
+		where += ` AND u.unit_id = ?`
+		args = append(args, k.UnitID)
+	}
+	if k.ActSeq != 0 {
+		query = `SELECT a.id`
+		from += `
+		JOIN acts a ON a.unit_x_id = u.id`
+		where += ` AND a.seq = ?`
+		args = append(args, k.ActSeq)
+	}
+	if k.StepSeq != 0 {
+		query = `SELECT st.id`
+		from += `
+		JOIN steps st ON st.act_id = a.id`
+		where += ` AND st.seq = ?`
+		args = append(args, k.StepSeq)
+	}
+	query += from + where + `
+		ORDER BY r.id DESC
+		LIMIT 1`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, dbError(fmt.Sprintf("query key %s", k), err)
+	}
+	return id, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// RefreshUnitEvents recomputes the unit_events rows for a game from the units
+// in its report extracts. It returns the number of events stored.
+// Call it after reports for the game are added or removed.
+func (s *SQLiteStore) RefreshUnitEvents(ctx context.Context, game string) (int, error) {
+	const query = `
+		SELECT DISTINCT u.clan_id, u.turn_no, u.unit_id
+		FROM unit_extracts u
+		JOIN report_extracts r ON u.report_x_id = r.id
+		WHERE r.game = ?
+	`
+	rows, err := s.db.QueryContext(ctx, query, game)
+	if err != nil {
+		return 0, dbError("query units", err)
+	}
+	clans := map[string]map[model.TurnNo][]string{}
+	for rows.Next() {
+		var clanID, unitID string
+		var turnNo model.TurnNo
+		if err := rows.Scan(&clanID, &turnNo, &unitID); err != nil {
+			rows.Close()
+			return 0, dbError("scan unit", err)
+		}
+		if clans[clanID] == nil {
+			clans[clanID] = map[model.TurnNo][]string{}
+		}
+		clans[clanID][turnNo] = append(clans[clanID][turnNo], unitID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, dbError("query units", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, dbError("begin", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM unit_events WHERE game = ?`, game); err != nil {
+		return 0, dbError("delete unit events", err)
+	}
+	const insert = `
+		INSERT INTO unit_events (game, clan_id, unit_id, turn_no, kind)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	n := 0
+	for clanID, units := range clans {
+		for _, ev := range model.UnitEvents(units) {
+			if _, err := tx.ExecContext(ctx, insert, game, clanID, ev.UnitID, ev.TurnNo, ev.Kind); err != nil {
+				return 0, dbError("insert unit event", err)
+			}
+			n++
+		}
+	}
+	if err := bumpGeneration(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, dbError("commit", err)
+	}
+	return n, nil
+}
+
+// UnitEventsByGameClan returns the units a clan created or disbanded.
+// If turnNo is non-zero, only events in that turn are returned.
+func (s *SQLiteStore) UnitEventsByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]model.UnitEvent, error) {
+	const query = `
+		SELECT unit_id, turn_no, kind
+		FROM unit_events
+		WHERE game = ? AND clan_id = ? AND (? = 0 OR turn_no = ?)
+		ORDER BY turn_no, kind, unit_id
+	`
+	rows, err := s.db.Query(query, gameID, formatClanNo(clanNo), turnNo, turnNo)
+	if err != nil {
+		return nil, dbError("query unit events", err)
+	}
+	defer rows.Close()
+
+	var events []model.UnitEvent
+	for rows.Next() {
+		var ev model.UnitEvent
+		if err := rows.Scan(&ev.UnitID, &ev.TurnNo, &ev.Kind); err != nil {
+			return nil, dbError("scan unit event", err)
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// Audit log actions for lint rule settings.
+const (
+	AuditLintSet   = "lint.set"   // a lint rule was configured
+	AuditLintClear = "lint.clear" // a lint rule setting was removed
+)
+
+// LintSettings returns every lint rule setting, the deployment's first, then
+// by game and rule.
+func (s *SQLiteStore) LintSettings(ctx context.Context) ([]model.LintSetting, error) {
+	const query = `
+		SELECT rule, game_id, enabled, severity, lim, updated_by, updated_at
+		FROM lint_rules
+		ORDER BY game_id, rule
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("query lint rules", err)
+	}
+	defer rows.Close()
+
+	var set []model.LintSetting
+	for rows.Next() {
+		var ls model.LintSetting
+		var updatedAt string
+		if err := rows.Scan(&ls.Rule, &ls.GameID, &ls.Enabled, &ls.Severity, &ls.Limit, &ls.UpdatedBy, &updatedAt); err != nil {
+			return nil, dbError("scan lint rule", err)
+		}
+		ls.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		set = append(set, ls)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("iterate lint rules", err)
+	}
+	return set, nil
+}
+
+// LintConfigForGame returns the lint settings in effect for the game (see
+// model.ResolveLintConfig). An empty gameID returns the deployment's.
+func (s *SQLiteStore) LintConfigForGame(ctx context.Context, gameID string) (model.LintConfig, error) {
+	set, err := s.LintSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.ResolveLintConfig(set, gameID), nil
+}
+
+// SetLintRule configures a lint rule for the deployment, or for one game if
+// ls.GameID isn't empty. The store doesn't know which rules exist, since
+// rules are added in Go; callers check the name against their linter.
+func (s *SQLiteStore) SetLintRule(ctx context.Context, actor string, ls model.LintSetting) error {
+	if strings.TrimSpace(ls.Rule) == "" {
+		return cerrs.New(cerrs.CodeInvalidInput, "lint rule name is required")
+	} else if ls.Limit < 0 {
+		return cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("lint rule %s: limit %d must not be negative", ls.Rule, ls.Limit))
+	} else if ls.Severity != "" {
+		if _, err := model.ParseLintSeverity(string(ls.Severity)); err != nil {
+			return cerrs.Wrap(cerrs.CodeInvalidInput, "lint rule "+ls.Rule, err)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+		INSERT INTO lint_rules (rule, game_id, enabled, severity, lim, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(rule, game_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			severity = excluded.severity,
+			lim = excluded.lim,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`
+	if _, err := tx.ExecContext(ctx, query, ls.Rule, ls.GameID, ls.Enabled, ls.Severity, ls.Limit, actor, s.now().Format(time.RFC3339)); err != nil {
+		return dbError("set lint rule", err)
+	}
+	detail := ls.Rule + " off"
+	if ls.Enabled {
+		detail = ls.Rule + " on"
+		if ls.Severity != "" {
+			detail += " severity " + string(ls.Severity)
+		}
+		if ls.Limit > 0 {
+			detail += fmt.Sprintf(" limit %d", ls.Limit)
+		}
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditLintSet, ls.GameID, detail); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("commit", err)
+	}
+	return nil
+}
+
+// ClearLintRule removes a lint rule's setting for the deployment or the game,
+// so the game falls back to the deployment's setting and the deployment to
+// the rule's defaults. Returns false if the rule wasn't set.
+func (s *SQLiteStore) ClearLintRule(ctx context.Context, actor, rule, gameID string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM lint_rules WHERE rule = ? AND game_id = ?`, rule, gameID)
+	if err != nil {
+		return false, dbError("clear lint rule", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditLintClear, gameID, rule); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, dbError("commit", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// TestLintRules checks that a game's lint setting wins over the deployment's
+// and that clearing it falls back.
+func TestLintRules(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "lint.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.SetLintRule(ctx, "cli:test", model.LintSetting{Rule: "scout.max-moves", Enabled: true, Limit: 9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetLintRule(ctx, "cli:test", model.LintSetting{Rule: "scout.max-moves", GameID: "0301", Enabled: false}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetLintRule(ctx, "cli:test", model.LintSetting{Rule: "scout.max-moves", Severity: "loud"}); cerrs.CodeOf(err) != cerrs.CodeInvalidInput {
+		t.Errorf("bad severity: err = %v, want invalid input", err)
+	}
+
+	cfg, err := s.LintConfigForGame(ctx, "0301")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ls := cfg["scout.max-moves"]; ls.Enabled || ls.GameID != "0301" {
+		t.Errorf("game 0301: %+v, want the game's setting", ls)
+	}
+	cfg, err = s.LintConfigForGame(ctx, "0302")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ls := cfg["scout.max-moves"]; !ls.Enabled || ls.Limit != 9 || ls.UpdatedBy != "cli:test" {
+		t.Errorf("game 0302: %+v, want the deployment's setting", ls)
+	}
+
+	if found, err := s.ClearLintRule(ctx, "cli:test", "scout.max-moves", "0301"); err != nil || !found {
+		t.Fatalf("clear: found = %v, err = %v", found, err)
+	}
+	if found, err := s.ClearLintRule(ctx, "cli:test", "scout.max-moves", "0301"); err != nil || found {
+		t.Errorf("clear again: found = %v, err = %v", found, err)
+	}
+	cfg, err = s.LintConfigForGame(ctx, "0301")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ls := cfg["scout.max-moves"]; !ls.Enabled || ls.Limit != 9 {
+		t.Errorf("game 0301 after clear: %+v, want the deployment's setting", ls)
+	}
+}
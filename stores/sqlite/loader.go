@@ -4,33 +4,35 @@ package store
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/logging"
 	"github.com/mdhender/tnrpt/model"
 	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
 	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
 	"github.com/mdhender/tnrpt/pipelines/parsers/report"
+	"github.com/spf13/afero"
 )
 
 var (
-	reDocxReportFileName = regexp.MustCompile(`^\d{4}.\d{4}-\d{2}.0\d{3}.docx$`)
-	reTextReportFileName = regexp.MustCompile(`^\d{4}.\d{4}-\d{2}.0\d{3}.report.txt$`)
+	reDocxReportFileName = regexp.MustCompile(`^\d{4}.\d{4}-\d{2}.` + model.ClanPattern + `.docx$`)
+	reTextReportFileName = regexp.MustCompile(`^\d{4}.\d{4}-\d{2}.` + model.ClanPattern + `.report.txt$`)
 )
 
 // LoadDocxFromDir loads all .docx files from a directory into the store.
 // File names are expected to follow the pattern: GGGG.YYYY-MM.CCCC.docx
 // where GGGG is game, YYYY-MM is turn, CCCC is clan.
-func LoadDocxFromDir(s model.Store, dir string) error {
-	entries, err := os.ReadDir(dir)
+// The directory is read from fsys (see stages.NewFS).
+func LoadDocxFromDir(fsys afero.Fs, s *SQLiteStore, dir string) error {
+	entries, err := afero.ReadDir(fsys, dir)
 	if err != nil {
 		return fmt.Errorf("read dir: %w", err)
 	}
@@ -48,41 +50,52 @@ func LoadDocxFromDir(s model.Store, dir string) error {
 		}
 
 		path := filepath.Join(dir, name)
-		if err := LoadDocxFile(s, path); err != nil {
-			log.Printf("store: load %s: %v", name, err)
+		if err := LoadDocxFile(fsys, s, path); err != nil {
+			slog.Warn("store: load", logging.KeyFile, name, "err", err)
 			failed++
 			continue
 		}
 		loaded++
 	}
 
-	log.Printf("store: loaded %d docx files (%d failed) from %s", loaded, failed, dir)
+	slog.Info("store: loaded docx files", "loaded", loaded, "failed", failed, "dir", dir)
 	return nil
 }
 
-// LoadDocxFile loads a single .docx file into the store.
-func LoadDocxFile(s model.Store, path string) error {
+// LoadDocxFile loads a single .docx file from fsys into the store.
+func LoadDocxFile(fsys afero.Fs, s *SQLiteStore, path string) error {
 	name := filepath.Base(path)
 	if !reDocxReportFileName.MatchString(strings.ToLower(name)) {
 		return fmt.Errorf("invalid report file name")
 	}
 	game, clanNo := parseFilename(name)
+	ids, err := s.UnitIDRules(context.Background(), game)
+	if err != nil {
+		return dbError("load unit id rules", err)
+	}
+	if err := ids.ValidateClan(clanNo); err != nil {
+		return err
+	}
 
-	data, err := os.ReadFile(path)
+	data, err := afero.ReadFile(fsys, path)
 	if err != nil {
 		return fmt.Errorf("read file: %w", err)
 	}
 	hash := sha256.Sum256(data)
 
-	doc, err := docx.ParsePath(path, true, true, true, false, false)
+	doc, err := docx.ParseReader(bytes.NewReader(data), true, true, true, false, false)
 	if err != nil {
 		return fmt.Errorf("parse docx: %w", err)
 	}
+	doc.Source = filepath.Clean(path)
 
 	rpt, err := report.ParseReportText(doc, true, true, true, false, false)
 	if err != nil {
 		return fmt.Errorf("parse report: %w", err)
 	}
+	for _, warning := range rpt.Warnings {
+		slog.Warn("store: load", logging.KeyFile, name, "warning", warning)
+	}
 
 	var text []byte
 	for _, section := range rpt.Sections {
@@ -90,7 +103,7 @@ func LoadDocxFile(s model.Store, path string) error {
 		text = append(text, '\n')
 	}
 
-	turn, err := bistre.ParseInput(rpt.Name, rpt.TurnNo, text, false, false, false, false, false, false, false, false, bistre.ParseConfig{})
+	turn, err := bistre.ParseInput(rpt.Name, rpt.TurnNo, text, bistre.ParseConfig{})
 	if err != nil {
 		return fmt.Errorf("parse input: %w", err)
 	}
@@ -98,7 +111,7 @@ func LoadDocxFile(s model.Store, path string) error {
 		return fmt.Errorf("parser returned nil")
 	}
 
-	turnNo := 100*turn.Year + turn.Month
+	turnNo := model.NewTurnNo(turn.Year, turn.Month)
 	rf := &model.ReportFile{
 		Game:      game,
 		ClanNo:    clanNo,
@@ -106,13 +119,13 @@ func LoadDocxFile(s model.Store, path string) error {
 		Name:      name,
 		SHA256:    hex.EncodeToString(hash[:]),
 		Mime:      "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
-		CreatedAt: time.Now().UTC(),
+		CreatedAt: s.now(),
 	}
 	if err := s.AddReportFile(rf); err != nil {
-		return fmt.Errorf("add report file: %w", err)
+		return dbError("add report file", err)
 	}
 
-	rx, err := adapters.BistreTurnToModelReportX(name, turn, game, clanNo)
+	rx, err := adapters.BistreTurnToModelReportX(name, turn, game, clanNo, ids, s.now)
 	if err != nil {
 		return fmt.Errorf("adapt to model: %w", err)
 	}
@@ -125,7 +138,7 @@ func LoadDocxFile(s model.Store, path string) error {
 	return nil
 }
 
-var filenameRe = regexp.MustCompile(`^(\d{4})\.(\d{3,4}-\d{2})\.(\d{4})\.docx$`)
+var filenameRe = regexp.MustCompile(`^(\d{4})\.(\d{3,4}-\d{2})\.(` + model.ClanPattern + `)\.docx$`)
 
 func parseFilename(name string) (game, clan string) {
 	matches := filenameRe.FindStringSubmatch(name)
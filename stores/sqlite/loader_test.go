@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/clock"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/spf13/afero"
+)
+
+// TestLoadDocxFile_Clock checks that loading a report stamps the report file
+// and the report with the store's clock.
+func TestLoadDocxFile_Clock(t *testing.T) {
+	ctx := context.Background()
+	s, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	now := time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC)
+	s.SetClock(clock.NewFixed(now))
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0301.0899-12.0987.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/reports/0301.0899-12.0987.docx", data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.LoadDocxFile(fsys, s, "/reports/0301.0899-12.0987.docx"); err != nil {
+		t.Fatal(err)
+	}
+
+	rfs, err := s.GetReportFilesByGameTurn(ctx, "0301", 89912)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rfs) != 1 || !rfs[0].CreatedAt.Equal(now) {
+		t.Errorf("report files = %v, want one created at %v", rfs, now)
+	}
+	rxs, err := s.ReportExtractsByGameTurn(ctx, "0301", 89912)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rxs) != 1 || !rxs[0].CreatedAt.Equal(now) {
+		t.Errorf("reports = %v, want one created at %v", rxs, now)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// UserEmail returns the handle and email address of the user with the given
+// handle or email address. It returns empty strings if there is no such user.
+// The email is empty if the user hasn't given one.
+func (s *SQLiteStore) UserEmail(ctx context.Context, handleOrEmail string) (handle, email string, err error) {
+	const query = `
+		SELECT handle, email FROM users
+		WHERE handle = ? OR (email IS NOT NULL AND email != '' AND lower(email) = lower(?))
+		ORDER BY handle = ? DESC
+		LIMIT 1
+	`
+	handleOrEmail = strings.TrimSpace(handleOrEmail)
+	var mail sql.NullString
+	err = s.db.QueryRowContext(ctx, query, handleOrEmail, handleOrEmail, handleOrEmail).Scan(&handle, &mail)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", dbError("query user email", err)
+	}
+	return handle, mail.String, nil
+}
+
+// CreateLoginToken creates a one-time login token for the user that expires after ttl.
+// Only a hash of the token is stored, so the returned value can't be recovered later.
+func (s *SQLiteStore) CreateLoginToken(ctx context.Context, handle string, ttl time.Duration) (string, error) {
+	token := s.ids.NewID(32)
+
+	now := s.now()
+	// clear out this user's spent tokens so the table doesn't grow without bound
+	const purge = `DELETE FROM login_tokens WHERE user_handle = ? AND (used_at IS NOT NULL OR expires_at <= ?)`
+	if _, err := s.db.ExecContext(ctx, purge, handle, now.Format(time.RFC3339)); err != nil {
+		return "", dbError("purge login tokens", err)
+	}
+	const query = `
+		INSERT INTO login_tokens (token_hash, user_handle, created_at, expires_at)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := s.db.ExecContext(ctx, query, hashLoginToken(token), handle, now.Format(time.RFC3339), now.Add(ttl).Format(time.RFC3339)); err != nil {
+		return "", dbError("insert login token", err)
+	}
+	return token, nil
+}
+
+// ConsumeLoginToken marks the token as used and returns the user it logs in,
+// in the first of the user's games. It returns nil if the token is unknown,
+// expired, already used, or belongs to a user who is no longer active.
+func (s *SQLiteStore) ConsumeLoginToken(ctx context.Context, token string) (*auth.User, error) {
+	now := s.now().Format(time.RFC3339)
+	const query = `
+		UPDATE login_tokens SET used_at = ?
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+		RETURNING user_handle
+	`
+	var handle string
+	err := s.db.QueryRowContext(ctx, query, now, hashLoginToken(token), now).Scan(&handle)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError("consume login token", err)
+	}
+
+	if active, err := s.isUserActive(ctx, handle); err != nil {
+		return nil, err
+	} else if !active {
+		return nil, nil
+	}
+
+	const userQuery = `SELECT user_name FROM users WHERE handle = ?`
+	user := &auth.User{Handle: handle}
+	if err := s.db.QueryRowContext(ctx, userQuery, handle).Scan(&user.UserName); err != nil {
+		return nil, dbError("query user", err)
+	}
+	games, err := s.GetGamesForUser(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range games {
+		if user.GameID == "" {
+			user.GameID, user.ClanNo = g.GameID, g.ClanNo
+		}
+		if g.GameID == user.GameID {
+			user.Clans = append(user.Clans, g.ClanNo)
+		}
+	}
+	return user, nil
+}
+
+func hashLoginToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
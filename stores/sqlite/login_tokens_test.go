@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/clock"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// TestLoginTokens checks that a login token logs its user in once, and
+// not at all once it has expired.
+func TestLoginTokens(t *testing.T) {
+	ctx := context.Background()
+	s, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	now := clock.NewFixed(time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC))
+	s.SetClock(now)
+	if _, err := s.ImportUsers(ctx, "test", []store.UserRecord{
+		{Handle: "clan0987", Email: "clan0987@example.com", Roles: []string{"active", "user"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := s.CreateLoginToken(ctx, "clan0987", 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, err := s.ConsumeLoginToken(ctx, "not-"+token); err != nil || user != nil {
+		t.Errorf("unknown token: got %v, %v, want no user", user, err)
+	}
+	user, err := s.ConsumeLoginToken(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user == nil || user.Handle != "clan0987" {
+		t.Fatalf("first use: got %+v, want clan0987", user)
+	}
+	if user, err := s.ConsumeLoginToken(ctx, token); err != nil || user != nil {
+		t.Errorf("second use: got %+v, %v, want no user", user, err)
+	}
+
+	token, err = s.CreateLoginToken(ctx, "clan0987", 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now.Advance(30 * time.Minute)
+	if user, err := s.ConsumeLoginToken(ctx, token); err != nil || user != nil {
+		t.Errorf("expired token: got %+v, %v, want no user", user, err)
+	}
+}
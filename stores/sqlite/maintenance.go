@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// orphanRule describes a child table whose rows must reference an existing parent row.
+type orphanRule struct {
+	table  string // child table
+	column string // foreign key column in the child table
+	parent string // parent table
+	key    string // key column in the parent table
+}
+
+// orphanRules lists the foreign keys checked for orphans.
+// Parents come before their children so that deleting an orphaned parent
+// cascades to its children before the children are checked.
+var orphanRules = []orphanRule{
+	{table: "report_files", column: "batch_id", parent: "upload_batches", key: "id"},
+	{table: "report_extracts", column: "report_file_id", parent: "report_files", key: "id"},
+	{table: "report_corrections", column: "new_report_file_id", parent: "report_files", key: "id"},
+	{table: "unit_extracts", column: "report_x_id", parent: "report_extracts", key: "id"},
+	{table: "acts", column: "unit_x_id", parent: "unit_extracts", key: "id"},
+	{table: "steps", column: "act_id", parent: "acts", key: "id"},
+	{table: "step_enc_units", column: "step_id", parent: "steps", key: "id"},
+	{table: "step_enc_sets", column: "step_id", parent: "steps", key: "id"},
+	{table: "step_enc_rsrc", column: "step_id", parent: "steps", key: "id"},
+	{table: "step_borders", column: "step_id", parent: "steps", key: "id"},
+	{table: "work", column: "report_file_id", parent: "report_files", key: "id"},
+	{table: "tile_units", column: "tile_id", parent: "tiles", key: "id"},
+	{table: "tile_sets", column: "tile_id", parent: "tiles", key: "id"},
+	{table: "tile_rsrc", column: "tile_id", parent: "tiles", key: "id"},
+	{table: "tile_borders", column: "tile_id", parent: "tiles", key: "id"},
+	{table: "tile_src", column: "tile_id", parent: "tiles", key: "id"},
+	{table: "step_tiles", column: "step_id", parent: "steps", key: "id"},
+	{table: "step_tiles", column: "tile_id", parent: "tiles", key: "id"},
+	{table: "render_job_units", column: "job_id", parent: "render_jobs", key: "id"},
+	{table: "render_job_turns", column: "job_id", parent: "render_jobs", key: "id"},
+	{table: "clan_maps", column: "report_file_id", parent: "report_files", key: "id"},
+	{table: "user_roles", column: "user_handle", parent: "users", key: "handle"},
+	{table: "user_prefs", column: "user_handle", parent: "users", key: "handle"},
+	{table: "login_tokens", column: "user_handle", parent: "users", key: "handle"},
+	{table: "sessions", column: "user_handle", parent: "users", key: "handle"},
+	{table: "api_tokens", column: "user_handle", parent: "users", key: "handle"},
+	{table: "game_clans", column: "game_id", parent: "games", key: "id"},
+	{table: "game_clans", column: "user_handle", parent: "users", key: "handle"},
+	{table: "game_turns", column: "game_id", parent: "games", key: "id"},
+	{table: "clan_shares", column: "game_id", parent: "games", key: "id"},
+	{table: "clan_public_redactions", column: "game_id", parent: "games", key: "id"},
+	{table: "orders", column: "game_id", parent: "games", key: "id"},
+}
+
+// OrphanCount is the number of rows in Table whose Column does not match a row in Parent.
+type OrphanCount struct {
+	Table  string
+	Column string
+	Parent string
+	Count  int64
+}
+
+func (r orphanRule) where() string {
+	return fmt.Sprintf("%s IS NOT NULL AND %s NOT IN (SELECT %s FROM %s)", r.column, r.column, r.key, r.parent)
+}
+
+// ForeignKeysEnabled reports whether the connection enforces foreign keys (and so cascades deletes).
+func (s *SQLiteStore) ForeignKeysEnabled(ctx context.Context) (bool, error) {
+	var enabled int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+		return false, dbError("foreign_keys", err)
+	}
+	return enabled == 1, nil
+}
+
+// FindOrphans counts orphaned rows left behind by historical partial writes
+// or by writes made while foreign keys were not enforced.
+func (s *SQLiteStore) FindOrphans(ctx context.Context) ([]OrphanCount, error) {
+	var counts []OrphanCount
+	for _, r := range orphanRules {
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", r.table, r.where())
+		var n int64
+		if err := s.db.QueryRowContext(ctx, query).Scan(&n); err != nil {
+			return nil, dbError(fmt.Sprintf("count orphans in %s", r.table), err)
+		}
+		counts = append(counts, OrphanCount{Table: r.table, Column: r.column, Parent: r.parent, Count: n})
+	}
+	return counts, nil
+}
+
+// DeleteOrphans removes orphaned rows in a single transaction and returns
+// the number of rows deleted for each rule. Rows removed by cascading deletes
+// are not counted.
+func (s *SQLiteStore) DeleteOrphans(ctx context.Context) ([]OrphanCount, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("begin", err)
+	}
+	defer tx.Rollback()
+
+	var counts []OrphanCount
+	for _, r := range orphanRules {
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s", r.table, r.where())
+		result, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			return nil, dbError(fmt.Sprintf("delete orphans in %s", r.table), err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, dbError("rows affected", err)
+		}
+		counts = append(counts, OrphanCount{Table: r.table, Column: r.column, Parent: r.parent, Count: n})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("commit", err)
+	}
+	return counts, nil
+}
+
+// MaintenanceResult reports what Maintain did.
+type MaintenanceResult struct {
+	JournalMode  string // "wal" for file databases, "memory" for in-memory ones
+	WALPages     int    // pages in the write-ahead log before the checkpoint
+	Checkpointed int    // pages copied back into the database file
+	Busy         bool   // the checkpoint could not finish because of readers or writers
+}
+
+// Maintain keeps a long-running database fast: it lets SQLite refresh the
+// query planner's statistics (PRAGMA optimize, then ANALYZE) and copies the
+// write-ahead log back into the database file. The checkpoint is PASSIVE, so
+// it never waits on readers or writers; pages it can't copy are left for the
+// next run. Callers should run it when the server is quiet.
+func (s *SQLiteStore) Maintain(ctx context.Context) (*MaintenanceResult, error) {
+	result := &MaintenanceResult{}
+	if err := s.db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&result.JournalMode); err != nil {
+		return nil, dbError("journal_mode", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "PRAGMA optimize"); err != nil {
+		return nil, dbError("optimize", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return nil, dbError("analyze", err)
+	}
+	if result.JournalMode != "wal" {
+		return result, nil
+	}
+	var busy int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA wal_checkpoint(PASSIVE)").Scan(&busy, &result.WALPages, &result.Checkpointed); err != nil {
+		return nil, dbError("checkpoint WAL", err)
+	}
+	result.Busy = busy != 0
+	return result, nil
+}
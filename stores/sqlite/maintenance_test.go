@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+func TestMaintain(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "maintain.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.CreateGame(ctx, "0301", "test game"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Maintain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JournalMode != "wal" {
+		t.Errorf("journal mode: got %q, want wal", result.JournalMode)
+	}
+	if result.WALPages == 0 || result.Checkpointed != result.WALPages || result.Busy {
+		t.Errorf("checkpoint: got %+v, want every WAL page checkpointed", result)
+	}
+
+	// an in-memory store has no WAL to checkpoint
+	mem, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+	if result, err := mem.Maintain(ctx); err != nil {
+		t.Fatal(err)
+	} else if result.JournalMode == "wal" || result.WALPages != 0 {
+		t.Errorf("in-memory: got %+v, want no checkpoint", result)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// SaveClanMap records a rendered map, replacing any earlier map for the same
+// game, clan, and turn.
+func (s *SQLiteStore) SaveClanMap(ctx context.Context, cm *model.ClanMap) error {
+	const query = `
+		INSERT INTO clan_maps (report_file_id, game, clan_no, turn_no, fs_path, hexes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(game, clan_no, turn_no) DO UPDATE SET
+			report_file_id = excluded.report_file_id,
+			fs_path = excluded.fs_path,
+			hexes = excluded.hexes,
+			created_at = excluded.created_at
+	`
+	if _, err := s.db.ExecContext(ctx, query,
+		cm.ReportFileID, cm.Game, cm.ClanNo, cm.TurnNo, cm.FsPath, cm.Hexes, cm.CreatedAt.UTC().Format(time.RFC3339),
+	); err != nil {
+		return dbError("save clan map", err)
+	}
+	return nil
+}
+
+// ClanMap returns the map rendered for a clan and turn, or nil if there isn't one.
+func (s *SQLiteStore) ClanMap(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) (*model.ClanMap, error) {
+	const query = `
+		SELECT id, report_file_id, game, clan_no, turn_no, fs_path, hexes, created_at
+		FROM clan_maps
+		WHERE game = ? AND clan_no = ? AND turn_no = ?
+	`
+	var cm model.ClanMap
+	var createdAt string
+	if err := s.db.QueryRowContext(ctx, query, gameID, clanNo, turnNo).Scan(
+		&cm.ID, &cm.ReportFileID, &cm.Game, &cm.ClanNo, &cm.TurnNo, &cm.FsPath, &cm.Hexes, &createdAt,
+	); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, dbError("get clan map", err)
+	}
+	cm.CreatedAt = parseTime(createdAt)
+	return &cm, nil
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// migration brings a database from the previous schema version to Version.
+//
+// schema.sql always describes the latest version, but it only creates the
+// tables, indexes, and triggers that are missing. Every change to a table
+// that already exists needs a migration here as well, or databases created
+// by an older schema.sql fail with "no such column".
+//
+// A migration that rebuilds a table (the only way to drop a constraint in
+// SQLite) sets Rebuild so that it runs with foreign keys off; see
+// https://www.sqlite.org/lang_altertable.html#otheralter. Indexes and triggers
+// dropped along with the old table are recreated by schema.sql afterwards.
+type migration struct {
+	Version int
+	Name    string
+	Rebuild bool
+	Stmts   []string
+}
+
+// migrations are applied in order; the schema version is the last Version.
+var migrations = []migration{
+	{Version: 1, Name: "steps.fail_raw", Stmts: []string{
+		`ALTER TABLE steps ADD COLUMN fail_raw TEXT`,
+	}},
+	{Version: 2, Name: "report_extracts.season, weather", Stmts: []string{
+		`ALTER TABLE report_extracts ADD COLUMN season TEXT`,
+		`ALTER TABLE report_extracts ADD COLUMN weather TEXT`,
+	}},
+	// tiles are derived data (see RebuildDerived) that older versions never
+	// wrote, so they are emptied rather than copied; rebuild-derived refills them
+	{Version: 3, Name: "tiles per game", Rebuild: true, Stmts: []string{
+		`DELETE FROM tile_src`,
+		`DELETE FROM tile_borders`,
+		`DELETE FROM tile_rsrc`,
+		`DELETE FROM tile_sets`,
+		`DELETE FROM tile_units`,
+		`CREATE TABLE tiles_new (
+			id            INTEGER PRIMARY KEY,
+			game          TEXT NOT NULL,
+			hex           TEXT NOT NULL,
+			turn_no       INTEGER,
+			terr          TEXT,
+			special_label TEXT,
+			UNIQUE(game, hex)
+		)`,
+		`DROP TABLE tiles`,
+		`ALTER TABLE tiles_new RENAME TO tiles`,
+	}},
+	{Version: 4, Name: "games.auto_advance", Stmts: []string{
+		`ALTER TABLE games ADD COLUMN auto_advance INTEGER NOT NULL DEFAULT 0`,
+	}},
+	{Version: 5, Name: "report_extracts timings", Stmts: []string{
+		`ALTER TABLE report_extracts ADD COLUMN extract_ms INTEGER`,
+		`ALTER TABLE report_extracts ADD COLUMN split_ms INTEGER`,
+		`ALTER TABLE report_extracts ADD COLUMN parse_ms INTEGER`,
+		`ALTER TABLE report_extracts ADD COLUMN adapt_ms INTEGER`,
+		`ALTER TABLE report_extracts ADD COLUMN store_ms INTEGER`,
+	}},
+	{Version: 6, Name: "games.clan_digits", Stmts: []string{
+		`ALTER TABLE games ADD COLUMN clan_digits INTEGER NOT NULL DEFAULT 4`,
+	}},
+	{Version: 7, Name: "tiles.grid, col, row", Stmts: []string{
+		`ALTER TABLE tiles ADD COLUMN grid TEXT`,
+		`ALTER TABLE tiles ADD COLUMN col INTEGER`,
+		`ALTER TABLE tiles ADD COLUMN row INTEGER`,
+	}},
+	// SQLite can't drop the old UNIQUE(game_id, user_handle), so the table is rebuilt
+	{Version: 8, Name: "game_clans: several clans per user", Rebuild: true, Stmts: []string{
+		`CREATE TABLE game_clans_new (
+			id          INTEGER PRIMARY KEY,
+			game_id     TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+			user_handle TEXT NOT NULL REFERENCES users(handle) ON DELETE CASCADE,
+			clan_no     INTEGER NOT NULL,
+			UNIQUE(game_id, clan_no)
+		)`,
+		`INSERT INTO game_clans_new (id, game_id, user_handle, clan_no) SELECT id, game_id, user_handle, clan_no FROM game_clans`,
+		`DROP TABLE game_clans`,
+		`ALTER TABLE game_clans_new RENAME TO game_clans`,
+	}},
+	{Version: 9, Name: "tiles.terr_score", Stmts: []string{
+		`ALTER TABLE tiles ADD COLUMN terr_score INTEGER`,
+	}},
+	{Version: 10, Name: "games grid size and wrapping", Stmts: []string{
+		`ALTER TABLE games ADD COLUMN grid_rows INTEGER NOT NULL DEFAULT 26`,
+		`ALTER TABLE games ADD COLUMN grid_cols INTEGER NOT NULL DEFAULT 26`,
+		`ALTER TABLE games ADD COLUMN wrap_rows INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE games ADD COLUMN wrap_cols INTEGER NOT NULL DEFAULT 0`,
+	}},
+	// available_at is the best guess for jobs queued before the column existed
+	{Version: 11, Name: "work.queued_at", Stmts: []string{
+		`ALTER TABLE work ADD COLUMN queued_at TEXT`,
+		`UPDATE work SET queued_at = available_at`,
+	}},
+	// sessions were kept in memory and ended when the server restarted
+	{Version: 12, Name: "sessions", Stmts: []string{
+		`CREATE TABLE sessions (
+			id_hash     TEXT PRIMARY KEY,
+			ref         TEXT NOT NULL UNIQUE,
+			user_handle TEXT NOT NULL REFERENCES users(handle) ON DELETE CASCADE,
+			user_json   TEXT NOT NULL,
+			created_at  TEXT NOT NULL,
+			expires_at  TEXT NOT NULL,
+			ip          TEXT NOT NULL,
+			user_agent  TEXT NOT NULL
+		)`,
+	}},
+	{Version: 13, Name: "report_corrections", Stmts: []string{
+		`CREATE TABLE report_corrections (
+			id                 INTEGER PRIMARY KEY,
+			game               TEXT NOT NULL,
+			clan_no            TEXT NOT NULL,
+			turn_no            INTEGER NOT NULL,
+			old_report_file_id INTEGER REFERENCES report_files(id) ON DELETE SET NULL,
+			new_report_file_id INTEGER NOT NULL REFERENCES report_files(id) ON DELETE CASCADE,
+			created_at         TEXT NOT NULL,
+			changes_json       TEXT NOT NULL
+		)`,
+	}},
+	{Version: 14, Name: "clan_public_redactions", Stmts: []string{
+		`CREATE TABLE clan_public_redactions (
+			game_id          TEXT    NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+			clan_no          INTEGER NOT NULL,
+			hide_terrain     INTEGER NOT NULL DEFAULT 0,
+			hide_rivers      INTEGER NOT NULL DEFAULT 0,
+			hide_settlements INTEGER NOT NULL DEFAULT 0,
+			hide_resources   INTEGER NOT NULL DEFAULT 0,
+			hide_encounters  INTEGER NOT NULL DEFAULT 0,
+			hide_units       INTEGER NOT NULL DEFAULT 0,
+			updated_at       TEXT    NOT NULL,
+			PRIMARY KEY (game_id, clan_no)
+		)`,
+	}},
+	{Version: 15, Name: "work_pauses", Stmts: []string{
+		`CREATE TABLE work_pauses (
+			stage     TEXT PRIMARY KEY,
+			reason    TEXT NOT NULL DEFAULT '',
+			paused_by TEXT NOT NULL,
+			paused_at TEXT NOT NULL
+		)`,
+	}},
+	{Version: 16, Name: "feature_flags", Stmts: []string{
+		`CREATE TABLE feature_flags (
+			name       TEXT    NOT NULL,
+			game_id    TEXT    NOT NULL DEFAULT '',
+			enabled    INTEGER NOT NULL,
+			updated_by TEXT    NOT NULL,
+			updated_at TEXT    NOT NULL,
+			PRIMARY KEY (name, game_id)
+		)`,
+	}},
+	{Version: 17, Name: "tile_src.attrs", Stmts: []string{
+		`ALTER TABLE tile_src ADD COLUMN attrs TEXT`,
+	}},
+	{Version: 18, Name: "removal_requests", Stmts: []string{
+		`CREATE TABLE removal_requests (
+			id             INTEGER PRIMARY KEY,
+			report_file_id INTEGER REFERENCES report_files(id) ON DELETE SET NULL,
+			game           TEXT NOT NULL,
+			clan_no        TEXT NOT NULL,
+			turn_no        INTEGER NOT NULL,
+			file_name      TEXT NOT NULL,
+			requested_by   TEXT NOT NULL,
+			reason         TEXT NOT NULL DEFAULT '',
+			created_at     TEXT NOT NULL,
+			status         TEXT NOT NULL DEFAULT 'open',
+			decided_by     TEXT,
+			decided_at     TEXT,
+			note           TEXT NOT NULL DEFAULT ''
+		)`,
+	}},
+	{Version: 19, Name: "lint_rules", Stmts: []string{
+		`CREATE TABLE lint_rules (
+			rule       TEXT    NOT NULL,
+			game_id    TEXT    NOT NULL DEFAULT '',
+			enabled    INTEGER NOT NULL,
+			severity   TEXT    NOT NULL DEFAULT '',
+			lim        INTEGER NOT NULL DEFAULT 0,
+			updated_by TEXT    NOT NULL,
+			updated_at TEXT    NOT NULL,
+			PRIMARY KEY (rule, game_id)
+		)`,
+	}},
+	// the adapters wrote "exhaust", "blocked", and "terrain" before fail_why
+	// held model.FailReason codes
+	{Version: 20, Name: "steps.fail_why reason codes", Stmts: []string{
+		`UPDATE steps SET fail_why = CASE fail_why
+			WHEN 'exhaust' THEN 'exhausted'
+			WHEN 'blocked' THEN 'blocked-by-unit'
+			WHEN 'terrain' THEN 'prohibited'
+			ELSE fail_why END
+		WHERE fail_why IN ('exhaust', 'blocked', 'terrain')`,
+	}},
+}
+
+// schemaVersion is the version of schema.sql, recorded in PRAGMA user_version.
+func schemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// PendingMigration is a migration that opening a database would apply.
+type PendingMigration struct {
+	Version int
+	Name    string
+	Stmts   []string
+}
+
+// PendingMigrations reads the schema version of the database at path and
+// returns the migrations that opening it would apply, without applying them.
+// The database is opened read-only. A database without tables would get
+// schema.sql instead, which is returned as the only pending migration.
+func PendingMigrations(ctx context.Context, path string) (version int, pending []PendingMigration, err error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, nil, fmt.Errorf("database file does not exist: %s", path)
+	}
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return 0, nil, fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return 0, nil, dbError("read schema version", err)
+	}
+	if version > schemaVersion() {
+		return version, nil, fmt.Errorf("database schema version %d is newer than this program's %d", version, schemaVersion())
+	}
+	if version == 0 {
+		var tables int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'report_files'`).Scan(&tables); err != nil {
+			return 0, nil, dbError("read schema", err)
+		}
+		if tables == 0 {
+			return 0, []PendingMigration{{Version: schemaVersion(), Name: "schema.sql", Stmts: []string{schemaSQL}}}, nil
+		}
+	}
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, PendingMigration{Version: m.Version, Name: m.Name, Stmts: m.Stmts})
+		}
+	}
+	return version, pending, nil
+}
+
+// migrate creates the schema in a new database, or brings a database created
+// by an older schema.sql up to date. A database without a report_files table
+// is new; one that has tables but a user_version of 0 predates versioning and
+// gets every migration.
+func migrate(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	defer conn.Close()
+
+	var version int
+	if err := conn.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return dbError("read schema version", err)
+	}
+	if version > schemaVersion() {
+		return fmt.Errorf("database schema version %d is newer than this program's %d", version, schemaVersion())
+	}
+
+	if version == 0 {
+		var tables int
+		if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'report_files'`).Scan(&tables); err != nil {
+			return dbError("read schema", err)
+		}
+		if tables == 0 {
+			if _, err := conn.ExecContext(ctx, schemaSQL); err != nil {
+				return dbError("exec schema", err)
+			}
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, schemaVersion())); err != nil {
+				return dbError("set schema version", err)
+			}
+			return nil
+		}
+	}
+
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		if err := m.apply(ctx, conn); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+
+	// create the tables, indexes, and triggers added since the database was made
+	if _, err := conn.ExecContext(ctx, schemaSQL); err != nil {
+		return dbError("exec schema", err)
+	}
+	return nil
+}
+
+// apply runs the migration's statements and records its version in one transaction.
+func (m migration) apply(ctx context.Context, conn *sql.Conn) error {
+	if m.Rebuild {
+		// foreign_keys can't be changed inside a transaction
+		if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.Stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if m.Rebuild {
+		var table string
+		var rowid sql.NullInt64
+		var parent string
+		var fkid int
+		err := tx.QueryRowContext(ctx, `PRAGMA foreign_key_check`).Scan(&table, &rowid, &parent, &fkid)
+		if err == nil {
+			return fmt.Errorf("foreign key check: %s row %d references a missing %s row", table, rowid.Int64, parent)
+		} else if err != sql.ErrNoRows {
+			return fmt.Errorf("foreign key check: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, m.Version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
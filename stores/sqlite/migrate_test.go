@@ -0,0 +1,227 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestMigrate_Unversioned opens a database made by the schema.sql that
+// predates migrations and checks that it ends up with the same tables,
+// columns, and indexes as a new database.
+func TestMigrate_Unversioned(t *testing.T) {
+	dir := t.TempDir()
+
+	oldPath := filepath.Join(dir, "old.db")
+	oldSchema, err := os.ReadFile(filepath.Join("testdata", "schema_v0.sql"))
+	if err != nil {
+		t.Fatalf("read old schema: %v", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+oldPath+"?_pragma=foreign_keys(ON)")
+	if err != nil {
+		t.Fatalf("open old database: %v", err)
+	}
+	if _, err := db.Exec(string(oldSchema)); err != nil {
+		t.Fatalf("exec old schema: %v", err)
+	}
+	db.Close()
+
+	s, err := NewSQLiteStoreWithConfig(StoreConfig{Path: oldPath})
+	if err != nil {
+		t.Fatalf("open and migrate: %v", err)
+	}
+	migrated := describeSchema(t, s.db)
+	s.Close()
+
+	newPath := filepath.Join(dir, "new.db")
+	if err := InitDatabase(newPath); err != nil {
+		t.Fatalf("init database: %v", err)
+	}
+	db, err = sql.Open("sqlite", "file:"+newPath)
+	if err != nil {
+		t.Fatalf("open new database: %v", err)
+	}
+	defer db.Close()
+	fresh := describeSchema(t, db)
+
+	for table, want := range fresh {
+		if got := migrated[table]; !slices.Equal(got, want) {
+			t.Errorf("%s: migrated\n\t%v\nwant\n\t%v", table, got, want)
+		}
+	}
+	for table := range migrated {
+		if _, ok := fresh[table]; !ok {
+			t.Errorf("%s: in the migrated database only", table)
+		}
+	}
+
+	// opening it again is a no-op
+	s, err = NewSQLiteStoreWithConfig(StoreConfig{Path: oldPath})
+	if err != nil {
+		t.Fatalf("open again: %v", err)
+	}
+	s.Close()
+}
+
+// TestMigrate_FailWhy checks that the failure codes the adapters wrote
+// before model.FailReason are rewritten to the reason codes.
+func TestMigrate_FailWhy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	oldSchema, err := os.ReadFile(filepath.Join("testdata", "schema_v0.sql"))
+	if err != nil {
+		t.Fatalf("read old schema: %v", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(ON)")
+	if err != nil {
+		t.Fatalf("open old database: %v", err)
+	}
+	for _, stmt := range []string{
+		string(oldSchema),
+		`INSERT INTO report_files (id, game, clan_no, turn_no, name, sha256, mime, created_at) VALUES (1, '0301', '0987', 89912, 'r.txt', 'abc', 'text/plain', '2025-01-01T00:00:00Z')`,
+		`INSERT INTO report_extracts (id, report_file_id, game, clan_no, turn_no, created_at) VALUES (1, 1, '0301', '0987', 89912, '2025-01-01T00:00:00Z')`,
+		`INSERT INTO unit_extracts (id, report_x_id, unit_id, clan_id, turn_no, start_grid, start_col, start_row, end_grid, end_col, end_row) VALUES (1, 1, '0987', '0987', 89912, 'QQ', 10, 10, 'QQ', 10, 10)`,
+		`INSERT INTO acts (id, unit_x_id, seq, kind) VALUES (1, 1, 1, 'move')`,
+		`INSERT INTO steps (act_id, seq, kind, ok, dir, fail_why) VALUES (1, 1, 'adv', 0, 'N', 'exhaust'), (1, 2, 'adv', 0, 'N', 'blocked'), (1, 3, 'adv', 0, 'N', 'terrain'), (1, 4, 'adv', 0, 'N', 'ocean'), (1, 5, 'adv', 1, 'N', NULL)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seed old database: %v", err)
+		}
+	}
+	db.Close()
+
+	s, err := NewSQLiteStoreWithConfig(StoreConfig{Path: path})
+	if err != nil {
+		t.Fatalf("open and migrate: %v", err)
+	}
+	defer s.Close()
+	rows, err := s.db.Query(`SELECT COALESCE(fail_why, '') FROM steps ORDER BY seq`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var why string
+		if err := rows.Scan(&why); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, why)
+	}
+	want := []string{"exhausted", "blocked-by-unit", "prohibited", "ocean", ""}
+	if !slices.Equal(got, want) {
+		t.Errorf("fail_why = %q, want %q", got, want)
+	}
+}
+
+// TestPendingMigrations checks that the migrations for an unversioned
+// database are listed without being applied, and that none are left after
+// the store is opened.
+func TestPendingMigrations(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "old.db")
+	oldSchema, err := os.ReadFile(filepath.Join("testdata", "schema_v0.sql"))
+	if err != nil {
+		t.Fatalf("read old schema: %v", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("open old database: %v", err)
+	}
+	if _, err := db.Exec(string(oldSchema)); err != nil {
+		t.Fatalf("exec old schema: %v", err)
+	}
+	db.Close()
+
+	version, pending, err := PendingMigrations(ctx, path)
+	if err != nil {
+		t.Fatalf("pending migrations: %v", err)
+	}
+	if version != 0 || len(pending) != len(migrations) {
+		t.Fatalf("pending = version %d, %d migrations, want version 0, %d migrations", version, len(pending), len(migrations))
+	}
+	if pending[0].Version != 1 || pending[len(pending)-1].Version != schemaVersion() {
+		t.Errorf("pending versions = %d..%d, want 1..%d", pending[0].Version, pending[len(pending)-1].Version, schemaVersion())
+	}
+	if version, _, err := PendingMigrations(ctx, path); err != nil || version != 0 {
+		t.Fatalf("after dry run: version %d, err %v, want version 0", version, err)
+	}
+
+	s, err := NewSQLiteStoreWithConfig(StoreConfig{Path: path})
+	if err != nil {
+		t.Fatalf("open and migrate: %v", err)
+	}
+	s.Close()
+	version, pending, err = PendingMigrations(ctx, path)
+	if err != nil {
+		t.Fatalf("pending migrations: %v", err)
+	}
+	if version != schemaVersion() || len(pending) != 0 {
+		t.Errorf("after open: version %d, %d pending, want version %d, none", version, len(pending), schemaVersion())
+	}
+}
+
+// describeSchema returns the schema version and, for each table, its sorted
+// columns and indexes, ignoring column order (ALTER TABLE appends columns).
+func describeSchema(t *testing.T, db *sql.DB) map[string][]string {
+	t.Helper()
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatalf("user_version: %v", err)
+	}
+	schema := map[string][]string{"user_version": {fmt.Sprint(version)}}
+
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("scan table: %v", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	for _, table := range tables {
+		var desc []string
+		cols, err := db.Query(`SELECT name, type, "notnull", COALESCE(dflt_value, ''), pk FROM pragma_table_info(?)`, table)
+		if err != nil {
+			t.Fatalf("%s: columns: %v", table, err)
+		}
+		for cols.Next() {
+			var name, typ, dflt string
+			var notNull, pk int
+			if err := cols.Scan(&name, &typ, &notNull, &dflt, &pk); err != nil {
+				t.Fatalf("%s: scan column: %v", table, err)
+			}
+			desc = append(desc, fmt.Sprintf("column %s %s notnull=%d default=%q pk=%d", name, typ, notNull, dflt, pk))
+		}
+		cols.Close()
+
+		idxs, err := db.Query(`SELECT il."unique", (SELECT group_concat(name, ',') FROM pragma_index_info(il.name)) FROM pragma_index_list(?) AS il`, table)
+		if err != nil {
+			t.Fatalf("%s: indexes: %v", table, err)
+		}
+		for idxs.Next() {
+			var unique int
+			var columns sql.NullString
+			if err := idxs.Scan(&unique, &columns); err != nil {
+				t.Fatalf("%s: scan index: %v", table, err)
+			}
+			desc = append(desc, fmt.Sprintf("index (%s) unique=%d", columns.String, unique))
+		}
+		idxs.Close()
+
+		slices.Sort(desc)
+		schema[table] = desc
+	}
+	return schema
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// ReplaceOrders stores a clan's orders for a turn, replacing any orders
+// imported for that turn before. It returns false if the game doesn't exist.
+func (s *SQLiteStore) ReplaceOrders(ctx context.Context, actor, gameID string, clanNo int, turnNo model.TurnNo, orders []model.Order) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM games WHERE id = ?`, gameID).Scan(&exists); err != nil {
+		return false, dbError("query game", err)
+	} else if exists == 0 {
+		return false, nil
+	}
+
+	const deleteQuery = `DELETE FROM orders WHERE game_id = ? AND clan_no = ? AND turn_no = ?`
+	if _, err := tx.ExecContext(ctx, deleteQuery, gameID, clanNo, turnNo); err != nil {
+		return false, dbError("delete orders", err)
+	}
+
+	const insertQuery = `
+		INSERT INTO orders (game_id, clan_no, turn_no, unit_id, line_no, kind, scout_no, dirs, target, dest, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	now := s.now().Format(time.RFC3339)
+	for _, o := range orders {
+		if _, err := tx.ExecContext(ctx, insertQuery, gameID, clanNo, turnNo, o.UnitID, o.LineNo, string(o.Kind), o.ScoutNo,
+			strings.Join(o.Dirs, "-"), o.Target, string(o.DestTN), now); err != nil {
+			return false, dbError(fmt.Sprintf("insert order: %s", o.UnitID), err)
+		}
+	}
+
+	detail := fmt.Sprintf("imported %d orders for clan %d turn %s", len(orders), clanNo, turnNo)
+	if err := s.insertAuditLog(ctx, tx, actor, AuditOrdersImport, gameID, detail); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, dbError("commit", err)
+	}
+	return true, nil
+}
+
+// OrdersByGameClanTurn returns a clan's imported orders for a turn, in the
+// order they were written in the orders file.
+func (s *SQLiteStore) OrdersByGameClanTurn(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) ([]model.Order, error) {
+	const query = `
+		SELECT unit_id, line_no, kind, scout_no, dirs, target, dest
+		FROM orders
+		WHERE game_id = ? AND clan_no = ? AND turn_no = ?
+		ORDER BY line_no
+	`
+	rows, err := s.db.QueryContext(ctx, query, gameID, clanNo, turnNo)
+	if err != nil {
+		return nil, dbError("query orders", err)
+	}
+	defer rows.Close()
+
+	var orders []model.Order
+	for rows.Next() {
+		var o model.Order
+		var kind, dirs, dest string
+		if err := rows.Scan(&o.UnitID, &o.LineNo, &kind, &o.ScoutNo, &dirs, &o.Target, &dest); err != nil {
+			return nil, dbError("scan order", err)
+		}
+		o.Kind = model.ActKind(kind)
+		if dirs != "" {
+			o.Dirs = strings.Split(dirs, "-")
+		}
+		o.DestTN = model.TNCoord(dest)
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+// OrderCheck compares a clan's imported orders for a turn with the turn's
+// parsed results. It returns nil if no orders were imported.
+func (s *SQLiteStore) OrderCheck(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) ([]model.OrderDivergence, error) {
+	orders, err := s.OrdersByGameClanTurn(ctx, gameID, clanNo, turnNo)
+	if err != nil || len(orders) == 0 {
+		return nil, err
+	}
+	units, err := s.UnitsByGameClan(gameID, clanNo, turnNo)
+	if err != nil {
+		return nil, err
+	}
+	return model.CheckOrders(orders, units), nil
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ParseCache returns the cached parse result for a report's content hash and
+// parser version, or nil if there isn't one.
+func (s *SQLiteStore) ParseCache(ctx context.Context, sha256, parserVersion string) ([]byte, error) {
+	const query = `SELECT turn_json FROM parse_cache WHERE sha256 = ? AND parser_version = ?`
+	var data []byte
+	if err := s.db.QueryRowContext(ctx, query, sha256, parserVersion).Scan(&data); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, dbError("get parse cache", err)
+	}
+	return data, nil
+}
+
+// SaveParseCache records a parse result, replacing any earlier result for the
+// same content hash and parser version.
+func (s *SQLiteStore) SaveParseCache(ctx context.Context, sha256, parserVersion string, data []byte, createdAt time.Time) error {
+	const query = `
+		INSERT INTO parse_cache (sha256, parser_version, turn_json, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(sha256, parser_version) DO UPDATE SET
+			turn_json = excluded.turn_json,
+			created_at = excluded.created_at
+	`
+	if _, err := s.db.ExecContext(ctx, query, sha256, parserVersion, data, createdAt.UTC().Format(time.RFC3339)); err != nil {
+		return dbError("save parse cache", err)
+	}
+	return nil
+}
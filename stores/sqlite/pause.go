@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// Audit log actions for pausing the work queue.
+const (
+	AuditWorkPause  = "work.pause"  // a stage, or the whole queue, was paused
+	AuditWorkResume = "work.resume" // a paused stage, or the whole queue, was resumed
+)
+
+// PauseWork stops workers from claiming jobs for stage, or for every stage if
+// stage is empty, until ResumeWork is called. Running jobs aren't touched.
+// Pausing a stage that is already paused replaces the reason.
+func (s *SQLiteStore) PauseWork(ctx context.Context, actor, stage, reason string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+		INSERT INTO work_pauses (stage, reason, paused_by, paused_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(stage) DO UPDATE SET
+			reason = excluded.reason,
+			paused_by = excluded.paused_by,
+			paused_at = excluded.paused_at
+	`
+	if _, err := tx.ExecContext(ctx, query, stage, reason, actor, s.now().Format(time.RFC3339)); err != nil {
+		return dbError("pause work", err)
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditWorkPause, "", pauseDetail(stage, reason)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return dbError("commit", err)
+	}
+	return nil
+}
+
+// ResumeWork lifts a pause set by PauseWork. Resuming "" lifts only the pause
+// on every stage, not pauses on single stages. Returns false if stage wasn't
+// paused.
+func (s *SQLiteStore) ResumeWork(ctx context.Context, actor, stage string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM work_pauses WHERE stage = ?`, stage)
+	if err != nil {
+		return false, dbError("resume work", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditWorkResume, "", pauseDetail(stage, "")); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, dbError("commit", err)
+	}
+	return true, nil
+}
+
+// WorkPauses returns the paused stages, the pause on every stage first.
+func (s *SQLiteStore) WorkPauses(ctx context.Context) ([]model.WorkPause, error) {
+	const query = `
+		SELECT stage, reason, paused_by, paused_at
+		FROM work_pauses
+		ORDER BY stage
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("query work pauses", err)
+	}
+	defer rows.Close()
+
+	var pauses []model.WorkPause
+	for rows.Next() {
+		var p model.WorkPause
+		var pausedAt string
+		if err := rows.Scan(&p.Stage, &p.Reason, &p.PausedBy, &pausedAt); err != nil {
+			return nil, dbError("scan work pause", err)
+		}
+		p.PausedAt, _ = time.Parse(time.RFC3339, pausedAt)
+		pauses = append(pauses, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("iterate work pauses", err)
+	}
+	return pauses, nil
+}
+
+// pauseDetail describes a pause for the audit log.
+func pauseDetail(stage, reason string) string {
+	detail := "all stages"
+	if stage != "" {
+		detail = "stage " + stage
+	}
+	if reason != "" {
+		detail += ": " + reason
+	}
+	return detail
+}
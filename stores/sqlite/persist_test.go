@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// TestPersistPartial persists a report whose second unit fails after its
+// unit extract is written, and checks that none of that unit's rows remain.
+func TestPersistPartial(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "partial.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	rf := &model.ReportFile{Game: "0301", ClanNo: "0987", TurnNo: 89912, Name: "0899-12.0987.report.txt", SHA256: "abc",
+		Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: "0899-12.0987.report.txt"}
+	if rf.ID, err = s.InsertReportFile(ctx, rf); err != nil {
+		t.Fatal(err)
+	}
+
+	turn := &bistre.Turn_t{Id: "0899-12", Year: 899, Month: 12, UnitMoves: map[bistre.UnitId_t]*bistre.Moves_t{}}
+	for _, id := range []bistre.UnitId_t{"0987", "0987c1", "0987e1"} {
+		turn.UnitMoves[id] = &bistre.Moves_t{TurnId: turn.Id, UnitId: id, PreviousHex: "QQ 1010", CurrentHex: "QQ 1010"}
+	}
+	// the courier's goto can't be stored, so it fails after its unit extract is written
+	turn.UnitMoves["0987c1"].GoesTo = "not a hex"
+
+	res, err := adapters.PersistWithReportFile(ctx, s, rf, turn, adapters.Options{Sorted: true, Partial: true})
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if res.Units != 2 || len(res.Errors) != 1 {
+		t.Fatalf("expected 2 units and 1 error, got %d units and %v", res.Units, res.Errors)
+	}
+
+	units, err := s.Units("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, u := range units {
+		got = append(got, u.UnitID)
+	}
+	if len(got) != 2 || got[0] != "0987" || got[1] != "0987e1" {
+		t.Errorf("units = %v, want [0987 0987e1]", got)
+	}
+	stats, err := s.TableStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["unit_extracts"] != 2 || stats["acts"] != 0 {
+		t.Errorf("unit_extracts = %d, acts = %d, want 2 and 0", stats["unit_extracts"], stats["acts"])
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// KnownMapByGameClan builds the clan's knowledge of terrain, rivers, and fords
+// from its reports, for planning moves. If asOf is non-zero, turns after asOf are ignored.
+func (s *SQLiteStore) KnownMapByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) (*model.KnownMap, error) {
+	units, err := s.unitsWithBorders(ctx, gameID, clanNo, asOf)
+	if err != nil {
+		return nil, err
+	}
+	world, err := s.WorldRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewKnownMap(units, world), nil
+}
+
+// HexNeighborhoodByGameClan returns the hexes next to c with what the clan's
+// reports say about each: observed, inferred from a neighboring hex, or
+// unknown (see model.KnownMap.Neighborhood). If asOf is non-zero, turns after
+// asOf are ignored.
+func (s *SQLiteStore) HexNeighborhoodByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo, c model.TNCoord) ([]model.HexNeighbor, error) {
+	if err := c.Validate(); err != nil {
+		return nil, cerrs.Wrap(cerrs.CodeInvalidInput, "hex neighborhood", err)
+	}
+	km, err := s.KnownMapByGameClan(ctx, gameID, clanNo, asOf)
+	if err != nil {
+		return nil, err
+	}
+	return km.Neighborhood(c), nil
+}
+
+// TerrainTransitionsByGameClan counts the clan's move steps by unit kind and
+// the terrain moved from and into, and how many succeeded (see
+// model.TerrainTransitions). If asOf is non-zero, turns after asOf are ignored.
+func (s *SQLiteStore) TerrainTransitionsByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]model.TerrainTransition, error) {
+	units, err := s.unitsWithBorders(ctx, gameID, clanNo, asOf)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.UnitIDRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	world, err := s.WorldRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return model.TerrainTransitions(units, model.NewKnownMap(units, world), rules), nil
+}
+
+// unitsWithBorders returns the clan's units with their acts and steps, and the
+// borders each step observed. If asOf is non-zero, turns after asOf are ignored.
+func (s *SQLiteStore) unitsWithBorders(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]*model.UnitX, error) {
+	clanStr := formatClanNo(clanNo)
+	rows, err := s.unitsWithSteps(ctx, `r.game = ? AND u.clan_id = ? AND (? = 0 OR u.turn_no <= ?)`,
+		gameID, clanStr, asOf, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+		SELECT sb.step_id, sb.dir, sb.kind
+		FROM step_borders sb
+		JOIN steps st ON sb.step_id = st.id
+		JOIN acts a ON st.act_id = a.id
+		JOIN unit_extracts u ON a.unit_x_id = u.id
+		JOIN report_extracts r ON u.report_x_id = r.id
+		WHERE r.game = ? AND u.clan_id = ? AND (? = 0 OR u.turn_no <= ?)
+		ORDER BY sb.id
+	`
+	brows, err := s.db.QueryContext(ctx, query, gameID, clanStr, asOf, asOf)
+	if err != nil {
+		return nil, dbError("query borders", err)
+	}
+	defer brows.Close()
+	borders := map[int64][]*model.BorderObs{}
+	for brows.Next() {
+		var stepID int64
+		var b model.BorderObs
+		if err := brows.Scan(&stepID, &b.Dir, &b.Kind); err != nil {
+			return nil, dbError("scan border", err)
+		}
+		borders[stepID] = append(borders[stepID], &b)
+	}
+	if err := brows.Err(); err != nil {
+		return nil, dbError("query borders", err)
+	}
+
+	units := make([]*model.UnitX, len(rows))
+	for i, du := range rows {
+		for _, act := range du.unit.Acts {
+			for _, st := range act.Steps {
+				st.Borders = borders[st.ID]
+			}
+		}
+		units[i] = du.unit
+	}
+	return units, nil
+}
+
+// UnitLocation returns the hex a clan's unit ended its most recent reported turn in,
+// and that turn. It returns an empty coordinate if the unit isn't in the clan's reports.
+// If asOf is non-zero, turns after asOf are ignored.
+func (s *SQLiteStore) UnitLocation(ctx context.Context, gameID string, clanNo int, unitID string, asOf model.TurnNo) (model.TNCoord, model.TurnNo, error) {
+	const query = `
+		SELECT u.turn_no, u.end_grid, u.end_col, u.end_row
+		FROM unit_extracts u
+		JOIN report_extracts r ON u.report_x_id = r.id
+		WHERE r.game = ? AND u.clan_id = ? AND u.unit_id = ? AND (? = 0 OR u.turn_no <= ?)
+		ORDER BY u.turn_no DESC, u.id DESC
+		LIMIT 1
+	`
+	var turnNo model.TurnNo
+	var grid string
+	var col, row int
+	err := s.db.QueryRowContext(ctx, query, gameID, formatClanNo(clanNo), unitID, asOf, asOf).Scan(&turnNo, &grid, &col, &row)
+	if err == sql.ErrNoRows {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, dbError("query unit location", err)
+	}
+	return model.NewTNCoord(grid, col, row), turnNo, nil
+}
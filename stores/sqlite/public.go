@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// PublicRedaction returns what the clan leaves out of its public turn
+// summaries. A clan that hasn't set it gets the zero value.
+func (s *SQLiteStore) PublicRedaction(ctx context.Context, gameID string, clanNo int) (model.PublicRedaction, error) {
+	const query = `
+		SELECT hide_terrain, hide_rivers, hide_settlements, hide_resources, hide_encounters, hide_units
+		FROM clan_public_redactions
+		WHERE game_id = ? AND clan_no = ?
+	`
+	var r model.PublicRedaction
+	err := s.db.QueryRowContext(ctx, query, gameID, clanNo).Scan(
+		&r.HideTerrain, &r.HideRivers, &r.HideSettlements, &r.HideResources, &r.HideEncounters, &r.HideUnits)
+	if err == sql.ErrNoRows {
+		return model.PublicRedaction{}, nil
+	}
+	if err != nil {
+		return model.PublicRedaction{}, dbError("query public redaction", err)
+	}
+	return r, nil
+}
+
+// SetPublicRedaction saves what the clan leaves out of its public turn summaries.
+func (s *SQLiteStore) SetPublicRedaction(ctx context.Context, gameID string, clanNo int, r model.PublicRedaction) error {
+	const query = `
+		INSERT INTO clan_public_redactions (game_id, clan_no, hide_terrain, hide_rivers, hide_settlements, hide_resources, hide_encounters, hide_units, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(game_id, clan_no) DO UPDATE SET
+			hide_terrain = excluded.hide_terrain,
+			hide_rivers = excluded.hide_rivers,
+			hide_settlements = excluded.hide_settlements,
+			hide_resources = excluded.hide_resources,
+			hide_encounters = excluded.hide_encounters,
+			hide_units = excluded.hide_units,
+			updated_at = excluded.updated_at
+	`
+	_, err := s.db.ExecContext(ctx, query, gameID, clanNo,
+		r.HideTerrain, r.HideRivers, r.HideSettlements, r.HideResources, r.HideEncounters, r.HideUnits,
+		s.now().Format(time.RFC3339))
+	if err != nil {
+		return dbError("save public redaction", err)
+	}
+	return nil
+}
+
+// PublicSummaryByGameClan builds the clan's redacted summary of turnNo for
+// sharing publicly, leaving out what the clan's PublicRedaction says to
+// (see model.NewPublicSummary).
+func (s *SQLiteStore) PublicSummaryByGameClan(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) (*model.PublicSummary, error) {
+	redaction, err := s.PublicRedaction(ctx, gameID, clanNo)
+	if err != nil {
+		return nil, err
+	}
+	units, err := s.unitsWithEncounters(ctx, `r.game = ? AND u.clan_id = ? AND u.turn_no <= ?`,
+		gameID, formatClanNo(clanNo), turnNo)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.UnitIDRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	world, err := s.WorldRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewPublicSummary(units, rules, world, turnNo, redaction), nil
+}
@@ -0,0 +1,398 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// MaxQueryRows caps the rows a query builder query returns.
+const MaxQueryRows = 1000
+
+// Query is a question built from the query builder's form: which entity, the
+// fields to show, filters, and a sort. RunQuery checks every name against the
+// entity's fields and passes values as parameters, so nothing the user types
+// becomes SQL.
+type Query struct {
+	Entity  string        `json:"entity"`
+	Columns []string      `json:"columns,omitempty"` // all fields if empty
+	Filters []QueryFilter `json:"filters,omitempty"` // all must match
+	Sort    string        `json:"sort,omitempty"`
+	Desc    bool          `json:"desc,omitempty"`
+	Limit   int           `json:"limit,omitempty"` // 1..MaxQueryRows; default 100
+	Dedup   bool          `json:"dedup,omitempty"` // one row per sighting; see QueryEntity.Dedups
+}
+
+// QueryFilter compares a field with a value.
+type QueryFilter struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// QueryFieldKind says how a field's values are parsed, compared, and shown.
+type QueryFieldKind string
+
+const (
+	QueryText   QueryFieldKind = "text"
+	QueryNumber QueryFieldKind = "number"
+	QueryTurn   QueryFieldKind = "turn" // shown and entered as "0899-12"
+	QueryBool   QueryFieldKind = "bool" // shown and entered as "yes" or "no"
+)
+
+// QueryField is a field of a query builder entity.
+type QueryField struct {
+	Name      string
+	Label     string
+	Kind      QueryFieldKind
+	expr      string
+	aggregate bool // counts or picks from the rows a deduplicated row stands for
+}
+
+// QueryEntity is something the query builder can list. Its rows are always
+// limited to one game and clan.
+type QueryEntity struct {
+	Name   string
+	Label  string
+	Fields []QueryField
+	from   string // joins that bring in report_extracts r and unit_extracts u
+	dedup  string // GROUP BY for a deduplicated query; empty if it can't be
+}
+
+// Field returns the entity's field with the given name.
+func (e *QueryEntity) Field(name string) (QueryField, bool) {
+	i := slices.IndexFunc(e.Fields, func(f QueryField) bool { return f.Name == name })
+	if i < 0 {
+		return QueryField{}, false
+	}
+	return e.Fields[i], true
+}
+
+// Dedups reports whether the entity's rows can be deduplicated. For
+// encounters, a unit seen again from the same hex in the same act (a scout
+// that stays put, or steps that report a neighbour over and over) is one
+// sighting; its row shows the first step, the last, and how many steps saw
+// it. A step that wasn't placed on a hex is a sighting of its own.
+func (e *QueryEntity) Dedups() bool {
+	return e.dedup != ""
+}
+
+// FieldsFor returns the fields a query can use: the entity's fields or, for a
+// deduplicated query, those with the step replaced by the first and last
+// steps and a count of the steps.
+func (e *QueryEntity) FieldsFor(q Query) []QueryField {
+	if !q.Dedup || !e.Dedups() {
+		return e.Fields
+	}
+	var fields []QueryField
+	for _, f := range e.Fields {
+		if f.Name == "step" {
+			fields = append(fields,
+				QueryField{Name: "step", Label: "First step", Kind: QueryNumber, expr: "MIN(st.seq)", aggregate: true},
+				QueryField{Name: "last-step", Label: "Last step", Kind: QueryNumber, expr: "MAX(st.seq)", aggregate: true})
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return append(fields, QueryField{Name: "count", Label: "Times seen", Kind: QueryNumber, expr: "COUNT(*)", aggregate: true})
+}
+
+func (e *QueryEntity) field(q Query, name string) (QueryField, bool) {
+	fields := e.FieldsFor(q)
+	i := slices.IndexFunc(fields, func(f QueryField) bool { return f.Name == name })
+	if i < 0 {
+		return QueryField{}, false
+	}
+	return fields[i], true
+}
+
+// QueryOps are the comparisons a filter can make, in the order the form lists
+// them, with their labels. "contains" and "starts" only apply to text fields.
+var QueryOps = []struct{ Op, Label string }{
+	{"eq", "="}, {"ne", "≠"}, {"lt", "<"}, {"le", "≤"}, {"gt", ">"}, {"ge", "≥"},
+	{"contains", "contains"}, {"starts", "starts with"},
+}
+
+var queryOpSQL = map[string]string{"eq": "=", "ne": "!=", "lt": "<", "le": "<=", "gt": ">", "ge": ">="}
+
+const (
+	queryUnitFrom = `unit_extracts u JOIN report_extracts r ON u.report_x_id = r.id`
+	queryStepJoin = ` JOIN acts a ON st.act_id = a.id JOIN unit_extracts u ON a.unit_x_id = u.id JOIN report_extracts r ON u.report_x_id = r.id`
+	queryStepFrom = `steps st` + queryStepJoin
+	queryTileJoin = ` LEFT JOIN step_tiles stt ON stt.step_id = st.id LEFT JOIN tiles t ON stt.tile_id = t.id`
+)
+
+// queryUnitFields are the fields every entity has, from the unit and report.
+var queryUnitFields = []QueryField{
+	{Name: "unit", Label: "Unit", Kind: QueryText, expr: "u.unit_id"},
+	{Name: "turn", Label: "Turn", Kind: QueryTurn, expr: "u.turn_no"},
+}
+
+// queryStepFields locate an encounter in its unit's moves.
+var queryStepFields = []QueryField{
+	{Name: "act", Label: "Act", Kind: QueryNumber, expr: "a.seq"},
+	{Name: "step", Label: "Step", Kind: QueryNumber, expr: "st.seq"},
+}
+
+// QueryEntities are the entities the query builder offers.
+var QueryEntities = []*QueryEntity{
+	{Name: "units", Label: "Units", from: queryUnitFrom, Fields: slices.Concat(queryUnitFields, []QueryField{
+		{Name: "start", Label: "Start", Kind: QueryText, expr: "u.start_grid || ' ' || printf('%02d%02d', u.start_col, u.start_row)"},
+		{Name: "end", Label: "End", Kind: QueryText, expr: "u.end_grid || ' ' || printf('%02d%02d', u.end_col, u.end_row)"},
+		{Name: "acts", Label: "Acts", Kind: QueryNumber, expr: "(SELECT COUNT(*) FROM acts a WHERE a.unit_x_id = u.id)"},
+	})},
+	{Name: "steps", Label: "Steps", from: queryStepFrom, Fields: slices.Concat(queryUnitFields, queryStepFields, []QueryField{
+		{Name: "act-kind", Label: "Act kind", Kind: QueryText, expr: "a.kind"},
+		{Name: "kind", Label: "Step kind", Kind: QueryText, expr: "st.kind"},
+		{Name: "dir", Label: "Direction", Kind: QueryText, expr: "st.dir"},
+		{Name: "ok", Label: "Ok", Kind: QueryBool, expr: "st.ok"},
+		{Name: "fail", Label: "Failure", Kind: QueryText, expr: "st.fail_why"},
+		{Name: "terrain", Label: "Terrain", Kind: QueryText, expr: "st.terr"},
+		{Name: "label", Label: "Label", Kind: QueryText, expr: "st.label"},
+	})},
+	{Name: "encounters", Label: "Units encountered", from: `step_enc_units e JOIN steps st ON e.step_id = st.id` + queryStepJoin + queryTileJoin,
+		dedup: "a.id, e.unit_id, COALESCE(stt.tile_id, -st.id)",
+		Fields: slices.Concat(queryUnitFields, queryStepFields, []QueryField{
+			{Name: "hex", Label: "Hex", Kind: QueryText, expr: "t.grid || ' ' || printf('%02d%02d', t.col, t.row)"},
+			{Name: "seen", Label: "Unit seen", Kind: QueryText, expr: "e.unit_id"},
+			{Name: "name", Label: "Name", Kind: QueryText, expr: "e.name"},
+			{Name: "clan", Label: "Clan", Kind: QueryText, expr: "e.clan_no"},
+		})},
+	{Name: "settlements", Label: "Settlements", from: `step_enc_sets e JOIN steps st ON e.step_id = st.id` + queryStepJoin, Fields: slices.Concat(queryUnitFields, queryStepFields, []QueryField{
+		{Name: "name", Label: "Name", Kind: QueryText, expr: "e.name"},
+		{Name: "kind", Label: "Kind", Kind: QueryText, expr: "e.kind"},
+		{Name: "clan", Label: "Clan", Kind: QueryText, expr: "e.clan_no"},
+	})},
+	{Name: "resources", Label: "Resources", from: `step_enc_rsrc e JOIN steps st ON e.step_id = st.id` + queryStepJoin, Fields: slices.Concat(queryUnitFields, queryStepFields, []QueryField{
+		{Name: "resource", Label: "Resource", Kind: QueryText, expr: "e.kind"},
+		{Name: "qty", Label: "Quantity", Kind: QueryNumber, expr: "e.qty"},
+	})},
+}
+
+// QueryEntityByName returns the entity with the given name, or nil.
+func QueryEntityByName(name string) *QueryEntity {
+	i := slices.IndexFunc(QueryEntities, func(e *QueryEntity) bool { return e.Name == name })
+	if i < 0 {
+		return nil
+	}
+	return QueryEntities[i]
+}
+
+// RunQuery runs a query builder query over a clan's data in a game. The
+// result's columns are the fields' labels. A query that names something the
+// entity doesn't have, or a value that doesn't suit its field, is an
+// INVALID_INPUT error.
+func (s *SQLiteStore) RunQuery(ctx context.Context, gameID string, clanNo int, q Query) (*QueryResult, error) {
+	e := QueryEntityByName(q.Entity)
+	if e == nil {
+		return nil, cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("query: unknown entity %q", q.Entity))
+	}
+	query, args, fields, err := e.compile(q, gameID, formatClanNo(clanNo))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError("query "+e.Name, err)
+	}
+	defer rows.Close()
+
+	result := &QueryResult{}
+	for _, f := range fields {
+		result.Columns = append(result.Columns, f.Label)
+	}
+	for rows.Next() {
+		values := make([]any, len(fields))
+		ptrs := make([]any, len(fields))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, dbError("scan "+e.Name, err)
+		}
+		row := make([]string, len(fields))
+		for i, v := range values {
+			row[i] = formatQueryValue(fields[i].Kind, v)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("query "+e.Name, err)
+	}
+	return result, nil
+}
+
+// compile turns a query into SQL with its arguments and the fields it selects.
+// A filter on a field that is counted or picked from a deduplicated row's
+// steps applies to the row, not the steps. A deduplicated query always shows
+// the count, since its rows mean little without it.
+func (e *QueryEntity) compile(q Query, gameID, clanID string) (string, []any, []QueryField, error) {
+	if q.Dedup && !e.Dedups() {
+		return "", nil, nil, cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("query: %s can't be deduplicated", e.Name))
+	}
+	fields := e.FieldsFor(q)
+	if len(q.Columns) != 0 {
+		fields = nil
+		for _, name := range q.Columns {
+			f, ok := e.field(q, name)
+			if !ok {
+				return "", nil, nil, e.unknownField(name)
+			}
+			fields = append(fields, f)
+		}
+		if q.Dedup && !slices.Contains(q.Columns, "count") {
+			count, _ := e.field(q, "count")
+			fields = append(fields, count)
+		}
+	}
+	var exprs []string
+	for _, f := range fields {
+		exprs = append(exprs, f.expr)
+	}
+
+	where := []string{"r.game = ?", "u.clan_id = ?"}
+	args := []any{gameID, clanID}
+	var having []string
+	var havingArgs []any
+	for _, flt := range q.Filters {
+		f, ok := e.field(q, flt.Field)
+		if !ok {
+			return "", nil, nil, e.unknownField(flt.Field)
+		}
+		cond, arg, err := f.condition(flt.Op, flt.Value)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if f.aggregate {
+			having, havingArgs = append(having, cond), append(havingArgs, arg)
+		} else {
+			where, args = append(where, cond), append(args, arg)
+		}
+	}
+	args = append(args, havingArgs...)
+
+	// the entity's natural order breaks ties, so the results are stable
+	order := []string{"u.turn_no", "u.unit_id"}
+	if strings.Contains(e.from, "steps st") {
+		if q.Dedup {
+			order = append(order, "a.seq", "MIN(st.seq)")
+		} else {
+			order = append(order, "a.seq", "st.seq")
+		}
+	}
+	if q.Sort != "" {
+		f, ok := e.field(q, q.Sort)
+		if !ok {
+			return "", nil, nil, e.unknownField(q.Sort)
+		}
+		dir := ""
+		if q.Desc {
+			dir = " DESC"
+		}
+		order = append([]string{f.expr + dir}, order...)
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	limit = min(limit, MaxQueryRows)
+
+	query := "SELECT " + strings.Join(exprs, ", ") + " FROM " + e.from +
+		" WHERE " + strings.Join(where, " AND ")
+	if q.Dedup {
+		query += " GROUP BY " + e.dedup
+		if len(having) != 0 {
+			query += " HAVING " + strings.Join(having, " AND ")
+		}
+	}
+	query += " ORDER BY " + strings.Join(order, ", ") +
+		" LIMIT " + strconv.Itoa(limit)
+	return query, args, fields, nil
+}
+
+func (e *QueryEntity) unknownField(name string) error {
+	return cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("query: %s have no field %q", e.Name, name))
+}
+
+// condition returns the WHERE condition for a filter on the field and its argument.
+func (f QueryField) condition(op, value string) (string, any, error) {
+	value = strings.TrimSpace(value)
+	switch op {
+	case "contains", "starts":
+		if f.Kind != QueryText {
+			return "", nil, cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("query: %s: %q only applies to text", f.Label, op))
+		}
+		pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value) + "%"
+		if op == "contains" {
+			pattern = "%" + pattern
+		}
+		return f.expr + ` LIKE ? ESCAPE '\'`, pattern, nil
+	}
+	sqlOp, ok := queryOpSQL[op]
+	if !ok {
+		return "", nil, cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("query: unknown comparison %q", op))
+	}
+
+	var arg any
+	switch f.Kind {
+	case QueryText:
+		arg = value
+	case QueryNumber:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", nil, cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("query: %s: %q is not a number", f.Label, value))
+		}
+		arg = n
+	case QueryTurn:
+		turnNo, err := model.ParseTurnNo(value)
+		if err != nil {
+			return "", nil, cerrs.Wrap(cerrs.CodeInvalidInput, "query: "+f.Label, err)
+		}
+		arg = int(turnNo)
+	case QueryBool:
+		switch strings.ToLower(value) {
+		case "yes", "true", "1":
+			arg = 1
+		case "no", "false", "0":
+			arg = 0
+		default:
+			return "", nil, cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("query: %s: %q is not yes or no", f.Label, value))
+		}
+		if sqlOp != "=" && sqlOp != "!=" {
+			return "", nil, cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("query: %s can only be compared with = or ≠", f.Label))
+		}
+		// ok is NULL for steps that don't succeed or fail
+		return "COALESCE(" + f.expr + ", 0) " + sqlOp + " ?", arg, nil
+	}
+	return f.expr + " " + sqlOp + " ?", arg, nil
+}
+
+// formatQueryValue shows a value the way it's entered in a filter.
+func formatQueryValue(kind QueryFieldKind, v any) string {
+	if v == nil {
+		return ""
+	}
+	switch kind {
+	case QueryTurn:
+		if n, ok := v.(int64); ok {
+			return model.TurnNo(n).String()
+		}
+	case QueryBool:
+		if n, ok := v.(int64); ok {
+			if n != 0 {
+				return "yes"
+			}
+			return "no"
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// TestRunQuery_Dedup has a scout see the same unit twice from its starting
+// hex and once more after stepping north, and checks that deduplication
+// shows one row per hex with the steps that saw it.
+func TestRunQuery_Dedup(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "query.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.CreateGame(ctx, "0301", "test game"); err != nil {
+		t.Fatal(err)
+	}
+
+	rf := &model.ReportFile{Game: "0301", ClanNo: "0987", TurnNo: 89912, Name: "0987.report.txt", SHA256: "0987",
+		Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: "0987.report.txt"}
+	if _, err := s.InsertReportFileWithBatch(ctx, rf); err != nil {
+		t.Fatal(err)
+	}
+	rxID, err := s.InsertReportExtract(ctx, &model.ReportX{ReportFileID: rf.ID, Game: "0301", ClanNo: "0987", TurnNo: 89912, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uxID, err := s.InsertUnitExtract(ctx, &model.UnitX{ReportXID: rxID, UnitID: "0987s1", ClanID: "987", TurnNo: 89912, StartTN: "QQ 1010", EndTN: "QQ 1009"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actID, err := s.InsertAct(ctx, &model.Act{UnitXID: uxID, Seq: 1, Kind: model.ActKindScout, Ok: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := func() *model.Enc { return &model.Enc{Units: []*model.UnitSeen{{UnitID: "0123"}}} }
+	for _, st := range []*model.Step{
+		{ActID: actID, Seq: 1, Kind: model.StepKindStill, Ok: true, Terr: "PR", Enc: seen()},
+		{ActID: actID, Seq: 2, Kind: model.StepKindStill, Ok: true, Terr: "PR", Enc: seen()},
+		{ActID: actID, Seq: 3, Kind: model.StepKindAdv, Dir: "N", Ok: true, Terr: "GH", Enc: seen()},
+	} {
+		if _, err := s.InsertStep(ctx, st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.RebuildDerived(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	q := store.Query{Entity: "encounters", Columns: []string{"seen", "hex", "step"}}
+	result, err := s.RunQuery(ctx, "0301", 987, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 3 {
+		t.Fatalf("encounters: got %d rows, want one per step", len(result.Rows))
+	}
+
+	q.Dedup = true
+	result, err = s.RunQuery(ctx, "0301", 987, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"0123", "QQ 1010", "1", "2"}, {"0123", "QQ 1009", "3", "1"}}
+	if !slices.Equal(result.Columns, []string{"Unit seen", "Hex", "First step", "Times seen"}) || len(result.Rows) != len(want) {
+		t.Fatalf("deduplicated: got %v %v, want %v", result.Columns, result.Rows, want)
+	}
+	for i := range want {
+		if !slices.Equal(result.Rows[i], want[i]) {
+			t.Errorf("deduplicated row %d: got %v, want %v", i, result.Rows[i], want[i])
+		}
+	}
+
+	q.Filters = []store.QueryFilter{{Field: "count", Op: "ge", Value: "2"}}
+	if result, err = s.RunQuery(ctx, "0301", 987, q); err != nil || len(result.Rows) != 1 {
+		t.Errorf("filter on the count: got %v, %v; want the repeated sighting", result, err)
+	}
+	if _, err := s.RunQuery(ctx, "0301", 987, store.Query{Entity: "units", Dedup: true}); err == nil {
+		t.Errorf("deduplicated units: want an error")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// AuditReportReassign is the audit log action for moving a report file to
+// another game, clan, or turn.
+const AuditReportReassign = "report.reassign"
+
+// ReassignReportFile attributes report file id to another game, clan, and turn
+// and records the change, made by actor, in the audit log. The file's report
+// extracts move with it. Maps rendered from the file and the corrections it
+// took part in were made for the old attribution, so they are removed; the
+// corrections are recorded again against the new one.
+//
+// With reparse set, the file's extracts are removed instead of moved and its
+// parse job is queued again, so the report is parsed under the new
+// attribution. A file that hasn't reached the parse stage yet is parsed once
+// its extract job finishes.
+//
+// Without reparse, the moved extracts must belong to the new clan, as the
+// parse stage would require; it fails with a *model.ClanMismatchError
+// (cerrs.CodeClanMismatch) if any of the file's units belongs to another.
+//
+// It returns the report file as it was before the change. It fails with
+// cerrs.CodeNotFound if the file or the game doesn't exist, and with
+// cerrs.CodeConflict if a worker is processing the file.
+func (s *SQLiteStore) ReassignReportFile(ctx context.Context, actor string, id int64, gameID, clanNo string, turnNo model.TurnNo, reparse bool) (*model.ReportFile, error) {
+	old, err := s.GetReportFileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if old == nil {
+		return nil, cerrs.New(cerrs.CodeNotFound, fmt.Sprintf("report file %d not found", id))
+	}
+	if !reparse {
+		ids, err := s.UnitIDRules(ctx, gameID)
+		if err != nil {
+			return nil, err
+		}
+		unitIDs, err := s.reportUnitIDs(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := ids.CheckClan(clanNo, unitIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM games WHERE id = ?`, gameID).Scan(&exists); err != nil {
+		return nil, dbError("query game", err)
+	} else if exists == 0 {
+		return nil, cerrs.New(cerrs.CodeNotFound, fmt.Sprintf("game %s not found", gameID))
+	}
+	var running int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM work WHERE report_file_id = ? AND status = 'running'`, id).Scan(&running); err != nil {
+		return nil, dbError("query running work", err)
+	} else if running != 0 {
+		return nil, cerrs.New(cerrs.CodeConflict, fmt.Sprintf("report file %d is being processed", id))
+	}
+
+	const fileQuery = `UPDATE report_files SET game = ?, clan_no = ?, turn_no = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, fileQuery, gameID, clanNo, turnNo, id); err != nil {
+		return nil, dbError("update report_file", err)
+	}
+	const correctionsQuery = `DELETE FROM report_corrections WHERE old_report_file_id = ? OR new_report_file_id = ?`
+	if _, err := tx.ExecContext(ctx, correctionsQuery, id, id); err != nil {
+		return nil, dbError("delete report corrections", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM clan_maps WHERE report_file_id = ?`, id); err != nil {
+		return nil, dbError("delete clan maps", err)
+	}
+
+	if reparse {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM report_extracts WHERE report_file_id = ?`, id); err != nil {
+			return nil, dbError("delete report extracts", err)
+		}
+		// the parse queues a new render job when the server renders automatically
+		if _, err := tx.ExecContext(ctx, `DELETE FROM work WHERE report_file_id = ? AND stage = ?`, id, model.WorkStageRender); err != nil {
+			return nil, dbError("delete render work", err)
+		}
+		const workQuery = `
+			UPDATE work
+			SET status = 'queued',
+			    available_at = ?,
+			    queued_at = ?,
+			    locked_by = NULL,
+			    locked_at = NULL,
+			    finished_at = NULL,
+			    error_code = NULL,
+			    error_message = NULL
+			WHERE report_file_id = ?
+			  AND stage = ?
+		`
+		now := s.now().Format(time.RFC3339)
+		if _, err := tx.ExecContext(ctx, workQuery, now, now, id, model.WorkStageParse); err != nil {
+			return nil, dbError("queue parse work", err)
+		}
+	} else {
+		const extractsQuery = `UPDATE report_extracts SET game = ?, clan_no = ?, turn_no = ? WHERE report_file_id = ?`
+		if _, err := tx.ExecContext(ctx, extractsQuery, gameID, clanNo, turnNo, id); err != nil {
+			return nil, dbError("update report extracts", err)
+		}
+		const unitsQuery = `
+			UPDATE unit_extracts
+			SET turn_no = ?
+			WHERE report_x_id IN (SELECT id FROM report_extracts WHERE report_file_id = ?)
+		`
+		if _, err := tx.ExecContext(ctx, unitsQuery, turnNo, id); err != nil {
+			return nil, dbError("update unit extracts", err)
+		}
+	}
+
+	detail := fmt.Sprintf("moved report file %d (%s) from game %s clan %s turn %s to game %s clan %s turn %s",
+		id, old.Name, old.Game, old.ClanNo, old.TurnNo, gameID, clanNo, turnNo)
+	if reparse {
+		detail += " and queued it to parse again"
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditReportReassign, gameID, detail); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("commit", err)
+	}
+
+	if !reparse {
+		rxIDs, err := s.reportExtractIDs(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, rxID := range rxIDs {
+			if _, err := s.RecordCorrection(ctx, rxID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	games := []string{gameID}
+	if old.Game != gameID {
+		games = append(games, old.Game)
+	}
+	for _, game := range games {
+		if _, err := s.RefreshUnitEvents(ctx, game); err != nil {
+			return nil, err
+		}
+	}
+	return old, nil
+}
+
+// reportExtractIDs returns the ids of the extracts parsed from a report file.
+func (s *SQLiteStore) reportExtractIDs(ctx context.Context, reportFileID int64) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM report_extracts WHERE report_file_id = ? ORDER BY id`, reportFileID)
+	if err != nil {
+		return nil, dbError("query report extracts", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, dbError("scan report extract", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// reportUnitIDs returns the ids of the units parsed from a report file.
+func (s *SQLiteStore) reportUnitIDs(ctx context.Context, reportFileID int64) ([]string, error) {
+	const query = `
+		SELECT DISTINCT ux.unit_id
+		FROM unit_extracts ux
+		JOIN report_extracts rx ON rx.id = ux.report_x_id
+		WHERE rx.report_file_id = ?
+		ORDER BY ux.unit_id
+	`
+	rows, err := s.db.QueryContext(ctx, query, reportFileID)
+	if err != nil {
+		return nil, dbError("query unit extracts", err)
+	}
+	defer rows.Close()
+
+	var unitIDs []string
+	for rows.Next() {
+		var unitID string
+		if err := rows.Scan(&unitID); err != nil {
+			return nil, dbError("scan unit extract", err)
+		}
+		unitIDs = append(unitIDs, unitID)
+	}
+	return unitIDs, rows.Err()
+}
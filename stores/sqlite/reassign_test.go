@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// TestReassignReportFile moves a parsed report to another turn, refuses to
+// move it to a clan its units don't belong to, and then queues it to parse
+// again under that clan, checking the audit log after each change.
+func TestReassignReportFile(t *testing.T) {
+	ctx := context.Background()
+	s, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.CreateGame(ctx, "0301", "test game"); err != nil {
+		t.Fatal(err)
+	}
+
+	batchID, err := s.InsertUploadBatch(ctx, &model.UploadBatch{Game: "0301", ClanNo: "0987", TurnNo: 89912, CreatedBy: "test", CreatedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rf := &model.ReportFile{Game: "0301", ClanNo: "0987", TurnNo: 89912, Name: "0301.899-12.0987.report.txt", SHA256: "0987",
+		Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: "batches/1/0301.899-12.0987.report.txt", BatchID: &batchID}
+	if _, err := s.InsertReportFileWithBatch(ctx, rf); err != nil {
+		t.Fatal(err)
+	}
+	rxID, err := s.InsertReportExtract(ctx, &model.ReportX{ReportFileID: rf.ID, Game: "0301", ClanNo: "0987", TurnNo: 89912, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unitID := range []string{"0987", "1987e1"} {
+		if _, err := s.InsertUnitExtract(ctx, &model.UnitX{ReportXID: rxID, UnitID: unitID, ClanID: "987", TurnNo: 89912, StartTN: "QQ 1010", EndTN: "QQ 1010"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.InsertWork(ctx, &model.Work{ReportFileID: rf.ID, Stage: model.WorkStageParse, Status: model.WorkStatusOk, AvailableAt: time.Now().UTC()}); err != nil {
+		t.Fatal(err)
+	}
+	lastAudit := func() store.AuditEntry {
+		t.Helper()
+		entries, err := s.AuditLog(ctx, "0301", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 0 {
+			t.Fatal("audit log: no entries")
+		}
+		return entries[0]
+	}
+
+	// move: the extracts follow the file to the new turn
+	old, err := s.ReassignReportFile(ctx, "gm", rf.ID, "0301", "0987", 90001, false)
+	if err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if old.TurnNo != 89912 {
+		t.Errorf("move: returned turn %s, want the old turn 0899-12", old.TurnNo)
+	}
+	if rxs, err := s.ReportExtractsByGameTurn(ctx, "0301", 90001); err != nil || len(rxs) != 1 {
+		t.Errorf("move: got %d extracts in 0900-01 (%v), want 1", len(rxs), err)
+	}
+	if e := lastAudit(); e.Action != store.AuditReportReassign || e.Actor != "gm" || !strings.Contains(e.Detail, "to game 0301 clan 0987 turn 0900-01") {
+		t.Errorf("move: audit entry = %+v", e)
+	}
+
+	// the parsed units belong to clan 0987, so they can't simply be moved to 0512
+	if _, err := s.ReassignReportFile(ctx, "gm", rf.ID, "0301", "0512", 90001, false); cerrs.CodeOf(err) != cerrs.CodeClanMismatch {
+		t.Fatalf("move to another clan: got %v, want a clan mismatch", err)
+	}
+	if got, err := s.GetReportFileByID(ctx, rf.ID); err != nil || got.ClanNo != "0987" {
+		t.Errorf("move to another clan: file is now %+v (%v), want it left in clan 0987", got, err)
+	}
+
+	// reparse: the extracts are dropped and the parse job is queued again
+	if _, err := s.ReassignReportFile(ctx, "gm", rf.ID, "0301", "0512", 90001, true); err != nil {
+		t.Fatalf("reparse: %v", err)
+	}
+	if got, err := s.GetReportFileByID(ctx, rf.ID); err != nil || got.ClanNo != "0512" {
+		t.Errorf("reparse: file is now %+v (%v), want clan 0512", got, err)
+	}
+	if rxs, err := s.ReportExtractsByGameTurn(ctx, "0301", 90001); err != nil || len(rxs) != 0 {
+		t.Errorf("reparse: got %d extracts (%v), want none", len(rxs), err)
+	}
+	works, err := s.GetWorkByBatch(ctx, batchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(works) != 1 || works[0].Stage != model.WorkStageParse || works[0].Status != model.WorkStatusQueued {
+		t.Errorf("reparse: work = %+v, want the parse job queued", works)
+	}
+	if e := lastAudit(); e.Action != store.AuditReportReassign || !strings.Contains(e.Detail, "clan 0512") || !strings.Contains(e.Detail, "queued it to parse again") {
+		t.Errorf("reparse: audit entry = %+v", e)
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// Audit log actions for removing report files.
+const (
+	AuditReportPurge   = "report.purge"
+	AuditRemovalReject = "removal.reject"
+)
+
+// ReportPurge describes a report file removed by PurgeReportFile. The store
+// only removes rows; the caller deletes the files it names from storage.
+type ReportPurge struct {
+	File     *model.ReportFile
+	MapPaths []string // maps rendered from the file, relative to the data dir
+}
+
+// PurgeReportFile removes report file id and everything derived from it: its
+// extracts, corrections, maps, work, and the tile sources that cite it. The
+// change, made by actor, is recorded in the audit log. Tiles keep the terrain
+// merged from the file until the derived tables are rebuilt.
+//
+// It fails with cerrs.CodeNotFound if the file doesn't exist, and with
+// cerrs.CodeConflict if a worker is processing the file.
+func (s *SQLiteStore) PurgeReportFile(ctx context.Context, actor string, id int64) (*ReportPurge, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	purge, err := s.purgeReportFile(ctx, tx, actor, id, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("commit", err)
+	}
+	if _, err := s.RefreshUnitEvents(ctx, purge.File.Game); err != nil {
+		return nil, err
+	}
+	return purge, nil
+}
+
+// purgeReportFile removes a report file inside tx. The note is added to the
+// audit log entry.
+func (s *SQLiteStore) purgeReportFile(ctx context.Context, tx *sql.Tx, actor string, id int64, note string) (*ReportPurge, error) {
+	rf, err := s.GetReportFileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if rf == nil {
+		return nil, cerrs.New(cerrs.CodeNotFound, fmt.Sprintf("report file %d not found", id))
+	}
+
+	var running int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM work WHERE report_file_id = ? AND status = 'running'`, id).Scan(&running); err != nil {
+		return nil, dbError("query running work", err)
+	} else if running != 0 {
+		return nil, cerrs.New(cerrs.CodeConflict, fmt.Sprintf("report file %d is being processed", id))
+	}
+
+	purge := &ReportPurge{File: rf}
+	rows, err := tx.QueryContext(ctx, `SELECT fs_path FROM clan_maps WHERE report_file_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, dbError("query clan maps", err)
+	}
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			rows.Close()
+			return nil, dbError("scan clan map", err)
+		}
+		purge.MapPaths = append(purge.MapPaths, path)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, dbError("query clan maps", err)
+	}
+
+	// tile_src.doc_id isn't a foreign key, so it doesn't cascade
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tile_src WHERE doc_id = ?`, id); err != nil {
+		return nil, dbError("delete tile sources", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM report_files WHERE id = ?`, id); err != nil {
+		return nil, dbError("delete report_file", err)
+	}
+
+	detail := fmt.Sprintf("purged report file %d (%s) of clan %s turn %s", id, rf.Name, rf.ClanNo, rf.TurnNo)
+	if note != "" {
+		detail += ": " + note
+	}
+	if err := s.insertAuditLog(ctx, tx, actor, AuditReportPurge, rf.Game, detail); err != nil {
+		return nil, err
+	}
+	return purge, nil
+}
+
+// RequestRemoval records actor's request that a GM remove report file id.
+// It fails with cerrs.CodeNotFound if the file doesn't exist, and with
+// cerrs.CodeConflict if the file already has an open request.
+func (s *SQLiteStore) RequestRemoval(ctx context.Context, actor string, reportFileID int64, reason string) (*model.RemovalRequest, error) {
+	rf, err := s.GetReportFileByID(ctx, reportFileID)
+	if err != nil {
+		return nil, err
+	} else if rf == nil {
+		return nil, cerrs.New(cerrs.CodeNotFound, fmt.Sprintf("report file %d not found", reportFileID))
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	var open int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM removal_requests WHERE report_file_id = ? AND status = ?`, reportFileID, model.RemovalOpen).Scan(&open); err != nil {
+		return nil, dbError("query removal requests", err)
+	} else if open != 0 {
+		return nil, cerrs.New(cerrs.CodeConflict, fmt.Sprintf("report file %d already has a removal request", reportFileID))
+	}
+
+	req := &model.RemovalRequest{
+		ReportFileID: rf.ID,
+		Game:         rf.Game,
+		ClanNo:       rf.ClanNo,
+		TurnNo:       rf.TurnNo,
+		FileName:     rf.Name,
+		RequestedBy:  actor,
+		Reason:       strings.TrimSpace(reason),
+		CreatedAt:    s.now(),
+		Status:       model.RemovalOpen,
+	}
+	const query = `
+		INSERT INTO removal_requests (report_file_id, game, clan_no, turn_no, file_name, requested_by, reason, created_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := tx.ExecContext(ctx, query, req.ReportFileID, req.Game, req.ClanNo, req.TurnNo, req.FileName,
+		req.RequestedBy, req.Reason, req.CreatedAt.Format(time.RFC3339), req.Status)
+	if err != nil {
+		return nil, dbError("insert removal request", err)
+	}
+	if req.ID, err = res.LastInsertId(); err != nil {
+		return nil, dbError("insert removal request", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("commit", err)
+	}
+	return req, nil
+}
+
+const removalColumns = `id, report_file_id, game, clan_no, turn_no, file_name, requested_by, reason, created_at, status, decided_by, decided_at, note`
+
+// RemovalRequests returns the removal requests for a game, open ones first and
+// then newest first. An empty clanNo returns every clan's requests.
+func (s *SQLiteStore) RemovalRequests(ctx context.Context, gameID, clanNo string) ([]*model.RemovalRequest, error) {
+	query := `SELECT ` + removalColumns + ` FROM removal_requests WHERE game = ?`
+	args := []any{gameID}
+	if clanNo != "" {
+		query += ` AND clan_no = ?`
+		args = append(args, clanNo)
+	}
+	query += ` ORDER BY status != 'open', created_at DESC, id DESC`
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError("query removal requests", err)
+	}
+	defer rows.Close()
+
+	var list []*model.RemovalRequest
+	for rows.Next() {
+		req, err := scanRemovalRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("query removal requests", err)
+	}
+	return list, nil
+}
+
+// ApproveRemoval purges the report file named by removal request id and marks
+// the request approved by actor, with note as the reply to the player. It
+// fails with cerrs.CodeNotFound if the request doesn't exist, with
+// cerrs.CodeConflict if it has already been decided or a worker is processing
+// the file, and otherwise as PurgeReportFile does.
+func (s *SQLiteStore) ApproveRemoval(ctx context.Context, actor string, id int64, note string) (*model.RemovalRequest, *ReportPurge, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	req, err := s.openRemovalRequest(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var purge *ReportPurge
+	if req.ReportFileID != 0 {
+		if purge, err = s.purgeReportFile(ctx, tx, actor, req.ReportFileID, fmt.Sprintf("removal request %d from %s", id, req.RequestedBy)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := s.decideRemoval(ctx, tx, actor, req, model.RemovalApproved, note); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, dbError("commit", err)
+	}
+	req.ReportFileID = 0
+
+	if purge != nil {
+		if _, err := s.RefreshUnitEvents(ctx, req.Game); err != nil {
+			return nil, nil, err
+		}
+	}
+	return req, purge, nil
+}
+
+// RejectRemoval marks removal request id rejected by actor, with note as the
+// reply to the player, and records the decision in the audit log. It fails
+// with cerrs.CodeNotFound if the request doesn't exist and with
+// cerrs.CodeConflict if it has already been decided.
+func (s *SQLiteStore) RejectRemoval(ctx context.Context, actor string, id int64, note string) (*model.RemovalRequest, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	req, err := s.openRemovalRequest(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decideRemoval(ctx, tx, actor, req, model.RemovalRejected, note); err != nil {
+		return nil, err
+	}
+	detail := fmt.Sprintf("kept report file %d (%s) of clan %s turn %s after removal request %d from %s",
+		req.ReportFileID, req.FileName, req.ClanNo, req.TurnNo, id, req.RequestedBy)
+	if err := s.insertAuditLog(ctx, tx, actor, AuditRemovalReject, req.Game, detail); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, dbError("commit", err)
+	}
+	return req, nil
+}
+
+// openRemovalRequest reads removal request id inside tx and checks that it's
+// still waiting for a GM.
+func (s *SQLiteStore) openRemovalRequest(ctx context.Context, tx *sql.Tx, id int64) (*model.RemovalRequest, error) {
+	req, err := scanRemovalRequest(tx.QueryRowContext(ctx, `SELECT `+removalColumns+` FROM removal_requests WHERE id = ?`, id))
+	if cerrs.CodeOf(err) == cerrs.CodeNotFound {
+		return nil, cerrs.New(cerrs.CodeNotFound, fmt.Sprintf("removal request %d not found", id))
+	} else if err != nil {
+		return nil, err
+	} else if req.Status != model.RemovalOpen {
+		return nil, cerrs.New(cerrs.CodeConflict, fmt.Sprintf("removal request %d was already %s", id, req.Status))
+	}
+	return req, nil
+}
+
+// decideRemoval records the GM's decision on req and updates it to match.
+func (s *SQLiteStore) decideRemoval(ctx context.Context, tx *sql.Tx, actor string, req *model.RemovalRequest, status model.RemovalStatus, note string) error {
+	now := s.now()
+	note = strings.TrimSpace(note)
+	const query = `UPDATE removal_requests SET status = ?, decided_by = ?, decided_at = ?, note = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, status, actor, now.Format(time.RFC3339), note, req.ID); err != nil {
+		return dbError("update removal request", err)
+	}
+	req.Status, req.DecidedBy, req.DecidedAt, req.Note = status, actor, &now, note
+	return nil
+}
+
+// scanRemovalRequest scans a row of removalColumns. It returns a
+// cerrs.CodeNotFound error if there is no row.
+func scanRemovalRequest(row interface{ Scan(...any) error }) (*model.RemovalRequest, error) {
+	var req model.RemovalRequest
+	var reportFileID sql.NullInt64
+	var createdAt string
+	var decidedBy, decidedAt sql.NullString
+	err := row.Scan(&req.ID, &reportFileID, &req.Game, &req.ClanNo, &req.TurnNo, &req.FileName, &req.RequestedBy,
+		&req.Reason, &createdAt, &req.Status, &decidedBy, &decidedAt, &req.Note)
+	if err == sql.ErrNoRows {
+		return nil, cerrs.New(cerrs.CodeNotFound, "removal request not found")
+	} else if err != nil {
+		return nil, dbError("scan removal request", err)
+	}
+	req.ReportFileID = reportFileID.Int64
+	req.CreatedAt = parseTime(createdAt)
+	req.DecidedBy = decidedBy.String
+	req.DecidedAt = parseTimePtr(decidedAt)
+	return &req, nil
+}
+
+// GMEmails returns the email addresses of the users with the gm role, for
+// notifications. GMs without an address are left out.
+func (s *SQLiteStore) GMEmails(ctx context.Context) ([]string, error) {
+	const query = `
+		SELECT DISTINCT u.email
+		FROM users u
+		JOIN user_roles r ON r.user_handle = u.handle
+		WHERE r.role = 'gm' AND u.email IS NOT NULL AND u.email != ''
+		ORDER BY u.email
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("query gm emails", err)
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, dbError("scan gm email", err)
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// TestRemovalRequests has a player ask for two reports to be removed and
+// checks that approving one purges the file while rejecting the other keeps it.
+func TestRemovalRequests(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "removals.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.CreateGame(ctx, "0301", "test game"); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []*model.ReportFile
+	for _, turnNo := range []model.TurnNo{89912, 90001} {
+		rf := &model.ReportFile{Game: "0301", ClanNo: "0987", TurnNo: turnNo, Name: turnNo.String() + ".0987.report.txt", SHA256: turnNo.String(),
+			Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: turnNo.String() + ".0987.report.txt"}
+		if _, err := s.InsertReportFileWithBatch(ctx, rf); err != nil {
+			t.Fatal(err)
+		}
+		rxID, err := s.InsertReportExtract(ctx, &model.ReportX{ReportFileID: rf.ID, Game: "0301", ClanNo: "0987", TurnNo: turnNo, CreatedAt: time.Now().UTC()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.InsertUnitExtract(ctx, &model.UnitX{ReportXID: rxID, UnitID: "0987", ClanID: "987", TurnNo: turnNo, StartTN: "QQ 1010", EndTN: "QQ 1010"}); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, rf)
+	}
+
+	purged, err := s.RequestRemoval(ctx, "clan0987", files[0].ID, " not ours ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged.Reason != "not ours" || purged.FileName != files[0].Name || purged.Status != model.RemovalOpen {
+		t.Errorf("request: got %+v", purged)
+	}
+	if _, err := s.RequestRemoval(ctx, "clan0987", files[0].ID, "again"); cerrs.CodeOf(err) != cerrs.CodeConflict {
+		t.Errorf("second request: got %v, want a conflict", err)
+	}
+	kept, err := s.RequestRemoval(ctx, "clan0987", files[1].ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, purge, err := s.ApproveRemoval(ctx, "gm", purged.ID, "done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Status != model.RemovalApproved || req.DecidedBy != "gm" || purge == nil || purge.File.ID != files[0].ID {
+		t.Errorf("approve: got %+v, %+v", req, purge)
+	}
+	if rf, err := s.GetReportFileByID(ctx, files[0].ID); err != nil || rf != nil {
+		t.Errorf("approved: report file is still there (%v)", err)
+	}
+	if rxs, err := s.ReportExtractsByGameTurn(ctx, "0301", files[0].TurnNo); err != nil || len(rxs) != 0 {
+		t.Errorf("approved: got %d extracts (%v), want none", len(rxs), err)
+	}
+	if _, _, err := s.ApproveRemoval(ctx, "gm", purged.ID, ""); cerrs.CodeOf(err) != cerrs.CodeConflict {
+		t.Errorf("approving twice: got %v, want a conflict", err)
+	}
+
+	if _, err := s.RejectRemoval(ctx, "gm", kept.ID, "it's yours"); err != nil {
+		t.Fatal(err)
+	}
+	if rf, err := s.GetReportFileByID(ctx, files[1].ID); err != nil || rf == nil {
+		t.Errorf("rejected: report file is gone (%v)", err)
+	}
+
+	requests, err := s.RemovalRequests(ctx, "0301", "0987")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("requests: got %d, want 2", len(requests))
+	}
+	for _, r := range requests {
+		if r.ID == purged.ID && (r.ReportFileID != 0 || r.Status != model.RemovalApproved || r.Note != "done") {
+			t.Errorf("approved request: got %+v", r)
+		} else if r.ID == kept.ID && (r.ReportFileID != files[1].ID || r.Status != model.RemovalRejected || r.DecidedAt == nil) {
+			t.Errorf("rejected request: got %+v", r)
+		}
+	}
+
+	entries, err := s.AuditLog(ctx, "0301", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := map[string]bool{}
+	for _, e := range entries {
+		actions[e.Action] = true
+	}
+	if !actions[store.AuditReportPurge] || !actions[store.AuditRemovalReject] {
+		t.Errorf("audit log: got %+v, want a purge and a rejection", entries)
+	}
+}
@@ -38,11 +38,55 @@ CREATE TABLE IF NOT EXISTS report_extracts (
                                                game           TEXT NOT NULL,
                                                clan_no        TEXT NOT NULL,
                                                turn_no        INTEGER NOT NULL,
-                                               created_at     TEXT NOT NULL
+                                               season         TEXT,          -- from the "Current Turn" line, e.g. "Winter"
+                                               weather        TEXT,          -- e.g. "FINE"
+                                               created_at     TEXT NOT NULL,
+                                               -- how long each step took, in milliseconds; NULL if not measured
+                                               extract_ms     INTEGER,       -- DOCX to text
+                                               split_ms       INTEGER,       -- text to report sections
+                                               parse_ms       INTEGER,       -- bistre parser
+                                               adapt_ms       INTEGER,       -- parser output to model types
+                                               store_ms       INTEGER        -- database writes
 );
 CREATE INDEX IF NOT EXISTS idx_report_extracts_report_file_id ON report_extracts(report_file_id);
 CREATE INDEX IF NOT EXISTS idx_report_extracts_game_turn_clan ON report_extracts(game, turn_no, clan_no);
 
+-- A clan's report for a turn replaced by another report file, and what the
+-- new file changed in the parsed units, acts, and steps. The changes are kept
+-- with the row so the record outlives the old report file.
+CREATE TABLE IF NOT EXISTS report_corrections (
+                                                  id                 INTEGER PRIMARY KEY,
+                                                  game               TEXT NOT NULL,
+                                                  clan_no            TEXT NOT NULL,
+                                                  turn_no            INTEGER NOT NULL,
+                                                  old_report_file_id INTEGER REFERENCES report_files(id) ON DELETE SET NULL,
+                                                  new_report_file_id INTEGER NOT NULL REFERENCES report_files(id) ON DELETE CASCADE,
+                                                  created_at         TEXT NOT NULL, -- ISO8601 UTC
+                                                  changes_json       TEXT NOT NULL  -- []model.ReportChange
+);
+CREATE INDEX IF NOT EXISTS idx_report_corrections_game_turn_clan ON report_corrections(game, turn_no, clan_no);
+
+-- Players' requests that a GM remove a report file from their clan (another
+-- clan's report uploaded as theirs, say). A GM approves one, which purges the
+-- file, or rejects it. The file's attribution is copied so the request still
+-- reads once the file is gone.
+CREATE TABLE IF NOT EXISTS removal_requests (
+                                                id             INTEGER PRIMARY KEY,
+                                                report_file_id INTEGER REFERENCES report_files(id) ON DELETE SET NULL, -- NULL once purged
+                                                game           TEXT NOT NULL,
+                                                clan_no        TEXT NOT NULL,
+                                                turn_no        INTEGER NOT NULL,
+                                                file_name      TEXT NOT NULL,
+                                                requested_by   TEXT NOT NULL,
+                                                reason         TEXT NOT NULL DEFAULT '',
+                                                created_at     TEXT NOT NULL, -- ISO8601 UTC
+                                                status         TEXT NOT NULL DEFAULT 'open', -- open|approved|rejected
+                                                decided_by     TEXT,
+                                                decided_at     TEXT,          -- ISO8601 UTC
+                                                note           TEXT NOT NULL DEFAULT ''      -- the GM's reply
+);
+CREATE INDEX IF NOT EXISTS idx_removal_requests_game_status ON removal_requests(game, status);
+
 -- One row per unit section in an extract
 CREATE TABLE IF NOT EXISTS unit_extracts (
                                              id           INTEGER PRIMARY KEY,
@@ -107,7 +151,8 @@ CREATE TABLE IF NOT EXISTS steps (
 
     -- adv payload
                                      dir       TEXT,
-                                     fail_why  TEXT,
+                                     fail_why  TEXT,          -- canonical code (model.FailReason)
+                                     fail_raw  TEXT,          -- original failure phrase from the report
 
     -- obs payload (flattened; details in child tables)
                                      terr      TEXT,
@@ -166,10 +211,16 @@ CREATE INDEX IF NOT EXISTS idx_step_borders_step ON step_borders(step_id);
 -- Walker output: tiles keyed by hex coordinate
 CREATE TABLE IF NOT EXISTS tiles (
                                      id            INTEGER PRIMARY KEY,
+                                     game          TEXT NOT NULL,
                                      hex           TEXT NOT NULL, -- hexg.Hex.ConciseString() format
+                                     grid          TEXT,          -- TribeNet coordinate of the hex, e.g. "QQ"
+                                     col           INTEGER,
+                                     row           INTEGER,
+                                     turn_no       INTEGER,       -- most recent turn the tile was observed
                                      terr          TEXT,
+                                     terr_score    INTEGER,       -- confidence in terr, 0..100; see model.BestTerrain
                                      special_label TEXT,
-                                     UNIQUE(hex)
+                                     UNIQUE(game, hex)
 );
 CREATE INDEX IF NOT EXISTS idx_tiles_hex ON tiles(hex);
 
@@ -219,11 +270,20 @@ CREATE TABLE IF NOT EXISTS tile_src (
                                         turn_no  INTEGER,
                                         act_seq  INTEGER,
                                         step_seq INTEGER,
-                                        note     TEXT
+                                        note     TEXT,
+                                        attrs    TEXT  -- model.TileAttr list, comma separated
 );
 CREATE INDEX IF NOT EXISTS idx_tile_src_tile ON tile_src(tile_id);
 CREATE INDEX IF NOT EXISTS idx_tile_src_doc ON tile_src(doc_id);
 
+-- Links each step placed by the walker to the tile it observed
+CREATE TABLE IF NOT EXISTS step_tiles (
+                                          step_id INTEGER NOT NULL REFERENCES steps(id) ON DELETE CASCADE,
+                                          tile_id INTEGER NOT NULL REFERENCES tiles(id) ON DELETE CASCADE,
+                                          PRIMARY KEY (step_id, tile_id)
+);
+CREATE INDEX IF NOT EXISTS idx_step_tiles_tile ON step_tiles(tile_id);
+
 -- Render jobs (optional persistence)
 CREATE TABLE IF NOT EXISTS render_jobs (
                                            id         INTEGER PRIMARY KEY,
@@ -235,6 +295,18 @@ CREATE TABLE IF NOT EXISTS render_jobs (
 );
 CREATE INDEX IF NOT EXISTS idx_render_jobs_game_clan ON render_jobs(game, clan_no);
 
+-- Units created or disbanded between a clan's reports (derived from unit_extracts; see RefreshUnitEvents)
+CREATE TABLE IF NOT EXISTS unit_events (
+                                           id      INTEGER PRIMARY KEY,
+                                           game    TEXT    NOT NULL,
+                                           clan_id TEXT    NOT NULL,
+                                           unit_id TEXT    NOT NULL,
+                                           turn_no INTEGER NOT NULL,
+                                           kind    TEXT    NOT NULL CHECK (kind IN ('created', 'disbanded')),
+                                           UNIQUE(game, unit_id, turn_no, kind)
+);
+CREATE INDEX IF NOT EXISTS idx_unit_events_game_clan_turn ON unit_events(game, clan_id, turn_no);
+
 CREATE TABLE IF NOT EXISTS render_job_units (
                                                 id        INTEGER PRIMARY KEY,
                                                 job_id    INTEGER NOT NULL REFERENCES render_jobs(id) ON DELETE CASCADE,
@@ -249,6 +321,20 @@ CREATE TABLE IF NOT EXISTS render_job_turns (
                                                 UNIQUE(job_id, turn_no)
 );
 
+-- Map images rendered by the pipeline's render stage: the clan's known map as
+-- of the turn, one per clan and turn. Rendering again replaces the row.
+CREATE TABLE IF NOT EXISTS clan_maps (
+                                         id             INTEGER PRIMARY KEY,
+                                         report_file_id INTEGER NOT NULL REFERENCES report_files(id) ON DELETE CASCADE,
+                                         game           TEXT    NOT NULL,
+                                         clan_no        INTEGER NOT NULL,
+                                         turn_no        INTEGER NOT NULL,
+                                         fs_path        TEXT    NOT NULL, -- relative to data-dir; e.g., "batches/1/0512.map.svg"
+                                         hexes          INTEGER NOT NULL, -- hexes with known terrain
+                                         created_at     TEXT    NOT NULL,
+                                         UNIQUE(game, clan_no, turn_no)
+);
+
 -- Users and authentication
 CREATE TABLE IF NOT EXISTS users (
                                      handle        TEXT PRIMARY KEY,
@@ -267,10 +353,23 @@ CREATE TABLE IF NOT EXISTS user_roles (
 );
 CREATE INDEX IF NOT EXISTS idx_user_roles_handle ON user_roles(user_handle);
 
--- Games and clan membership (clan_no is per-game, not per-user)
+-- Per-user display preferences (theme is 'light', 'dark', or '' to follow the browser)
+CREATE TABLE IF NOT EXISTS user_prefs (
+                                          user_handle TEXT PRIMARY KEY REFERENCES users(handle) ON DELETE CASCADE,
+                                          theme       TEXT NOT NULL DEFAULT '',
+                                          updated_at  TEXT NOT NULL
+);
+
+-- Games and clan membership (clan_no is per-game, not per-user; a user may run several clans in a game)
 CREATE TABLE IF NOT EXISTS games (
-                                     id          TEXT PRIMARY KEY,
-                                     description TEXT
+                                     id           TEXT PRIMARY KEY,
+                                     description  TEXT,
+                                     auto_advance INTEGER NOT NULL DEFAULT 0, -- 1: activate the next turn when the active turn's orders are due
+                                     clan_digits  INTEGER NOT NULL DEFAULT 4, -- digits in a tribe id, e.g. 4 for "0987"
+                                     grid_rows    INTEGER NOT NULL DEFAULT 26, -- grid row letters in the world map, A..Z
+                                     grid_cols    INTEGER NOT NULL DEFAULT 26, -- grid column letters in the world map, A..Z
+                                     wrap_rows    INTEGER NOT NULL DEFAULT 0, -- 1: moving off the north or south edge wraps
+                                     wrap_cols    INTEGER NOT NULL DEFAULT 0  -- 1: moving off the east or west edge wraps
 );
 
 CREATE TABLE IF NOT EXISTS game_clans (
@@ -278,12 +377,57 @@ CREATE TABLE IF NOT EXISTS game_clans (
                                           game_id     TEXT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
                                           user_handle TEXT NOT NULL REFERENCES users(handle) ON DELETE CASCADE,
                                           clan_no     INTEGER NOT NULL,
-                                          UNIQUE(game_id, user_handle),
                                           UNIQUE(game_id, clan_no)
 );
 CREATE INDEX IF NOT EXISTS idx_game_clans_game ON game_clans(game_id);
 CREATE INDEX IF NOT EXISTS idx_game_clans_user ON game_clans(user_handle);
 
+-- Map sharing between clans (clan_no lets shared_with see its tiles in the alliance view)
+CREATE TABLE IF NOT EXISTS clan_shares (
+                                           id          INTEGER PRIMARY KEY,
+                                           game_id     TEXT    NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+                                           clan_no     INTEGER NOT NULL,
+                                           shared_with INTEGER NOT NULL,
+                                           created_at  TEXT    NOT NULL,
+                                           UNIQUE(game_id, clan_no, shared_with)
+);
+CREATE INDEX IF NOT EXISTS idx_clan_shares_with ON clan_shares(game_id, shared_with);
+
+-- What a clan leaves out of the turn summaries it shares publicly (see
+-- model.PublicRedaction). A clan without a row shows everything but
+-- coordinates and unit IDs.
+CREATE TABLE IF NOT EXISTS clan_public_redactions (
+                                                      game_id          TEXT    NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+                                                      clan_no          INTEGER NOT NULL,
+                                                      hide_terrain     INTEGER NOT NULL DEFAULT 0,
+                                                      hide_rivers      INTEGER NOT NULL DEFAULT 0,
+                                                      hide_settlements INTEGER NOT NULL DEFAULT 0,
+                                                      hide_resources   INTEGER NOT NULL DEFAULT 0,
+                                                      hide_encounters  INTEGER NOT NULL DEFAULT 0,
+                                                      hide_units       INTEGER NOT NULL DEFAULT 0,
+                                                      updated_at       TEXT    NOT NULL,
+                                                      PRIMARY KEY (game_id, clan_no)
+);
+
+-- Movement orders players submitted, imported to cross-check against the
+-- turn's results (see model.CheckOrders). Importing a clan's orders for a
+-- turn replaces the ones already there.
+CREATE TABLE IF NOT EXISTS orders (
+                                      id         INTEGER PRIMARY KEY,
+                                      game_id    TEXT    NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+                                      clan_no    INTEGER NOT NULL,
+                                      turn_no    INTEGER NOT NULL, -- e.g., 90001 for year 900 month 1
+                                      unit_id    TEXT    NOT NULL,
+                                      line_no    INTEGER NOT NULL, -- line in the orders file
+                                      kind       TEXT    NOT NULL CHECK (kind IN ('follow', 'goto', 'move', 'scout')),
+                                      scout_no   INTEGER NOT NULL DEFAULT 0,
+                                      dirs       TEXT    NOT NULL DEFAULT '', -- e.g., "NE-N-still"
+                                      target     TEXT    NOT NULL DEFAULT '', -- follow
+                                      dest       TEXT    NOT NULL DEFAULT '', -- goto, e.g., "QQ 1010"
+                                      created_at TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_orders_turn ON orders(game_id, clan_no, turn_no);
+
 --  Copyright (c) 2025 Michael D Henderson. All rights reserved.
 
 -- Game turns (year/month, is_active, due_date in UTC)
@@ -305,11 +449,12 @@ CREATE TABLE IF NOT EXISTS work (
                                     id             INTEGER PRIMARY KEY,
                                     report_file_id INTEGER NOT NULL REFERENCES report_files(id) ON DELETE CASCADE,
 
-                                    stage          TEXT    NOT NULL,                  -- 'extract', 'parse'
+                                    stage          TEXT    NOT NULL,                  -- 'extract', 'parse', 'render'
                                     status         TEXT    NOT NULL DEFAULT 'queued', -- queued|running|ok|failed
 
                                     attempt        INTEGER NOT NULL DEFAULT 0,
                                     available_at   TEXT    NOT NULL,                  -- ISO8601 UTC
+                                    queued_at      TEXT,                              -- when the job last entered the queue
                                     locked_by      TEXT,                              -- worker ID
                                     locked_at      TEXT,                              -- ISO8601 UTC
                                     started_at     TEXT,                              -- first execution time
@@ -322,3 +467,198 @@ CREATE TABLE IF NOT EXISTS work (
 );
 CREATE INDEX IF NOT EXISTS idx_work_ready ON work(status, stage, available_at);
 CREATE INDEX IF NOT EXISTS idx_work_file ON work(report_file_id);
+
+-- Experimental behaviors turned on or off without a rebuild (see
+-- model.FeatureFlags). game_id '' is the deployment's setting; a game's
+-- setting wins over it. Not tied to games so a setting can be made before
+-- the game is created.
+CREATE TABLE IF NOT EXISTS feature_flags (
+                                             name       TEXT    NOT NULL,
+                                             game_id    TEXT    NOT NULL DEFAULT '',
+                                             enabled    INTEGER NOT NULL,
+                                             updated_by TEXT    NOT NULL,
+                                             updated_at TEXT    NOT NULL,
+                                             PRIMARY KEY (name, game_id)
+);
+
+-- Lint rule settings for the deployment (game_id '') or one game; a game's
+-- setting wins over it. Rules without a setting run with their defaults.
+CREATE TABLE IF NOT EXISTS lint_rules (
+                                          rule       TEXT    NOT NULL,
+                                          game_id    TEXT    NOT NULL DEFAULT '',
+                                          enabled    INTEGER NOT NULL,
+                                          severity   TEXT    NOT NULL DEFAULT '', -- '' for the rule's own
+                                          lim        INTEGER NOT NULL DEFAULT 0,  -- 0 for the rule's own
+                                          updated_by TEXT    NOT NULL,
+                                          updated_at TEXT    NOT NULL,
+                                          PRIMARY KEY (rule, game_id)
+);
+
+-- Stages whose jobs workers must not claim, e.g. during a migration or a data
+-- repair. The stage '' pauses every stage.
+CREATE TABLE IF NOT EXISTS work_pauses (
+                                           stage     TEXT PRIMARY KEY,
+                                           reason    TEXT NOT NULL DEFAULT '',
+                                           paused_by TEXT NOT NULL,
+                                           paused_at TEXT NOT NULL
+);
+
+-- Parse results cached by the pipeline's parse stage, keyed by the report's
+-- content hash and the parser version, so the same report ingested again (in
+-- another game, or after being deleted) isn't parsed again. Not tied to
+-- report_files so that deleting the report keeps the entry.
+CREATE TABLE IF NOT EXISTS parse_cache (
+                                           sha256         TEXT    NOT NULL,
+                                           parser_version TEXT    NOT NULL,
+                                           turn_json      BLOB    NOT NULL, -- gzipped JSON of the parser's Turn_t
+                                           created_at     TEXT    NOT NULL,
+                                           PRIMARY KEY (sha256, parser_version)
+);
+
+-- Store generation: bumped by triggers whenever data the web pages are rendered from changes.
+-- The derived tables (tiles, step_tiles, unit_events) are bumped once per rebuild by the store instead.
+-- Handlers use it for ETag/Last-Modified so unchanged pages can be answered with 304 Not Modified.
+CREATE TABLE IF NOT EXISTS store_generation (
+                                                id         INTEGER PRIMARY KEY CHECK (id = 1),
+                                                gen        INTEGER NOT NULL,
+                                                updated_at TEXT    NOT NULL -- ISO8601 UTC
+);
+INSERT OR IGNORE INTO store_generation (id, gen, updated_at) VALUES (1, 1, strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+CREATE TRIGGER IF NOT EXISTS trg_report_extracts_gen_insert AFTER INSERT ON report_extracts BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_report_extracts_gen_update AFTER UPDATE ON report_extracts BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_report_extracts_gen_delete AFTER DELETE ON report_extracts BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_unit_extracts_gen_insert AFTER INSERT ON unit_extracts BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_unit_extracts_gen_update AFTER UPDATE ON unit_extracts BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_unit_extracts_gen_delete AFTER DELETE ON unit_extracts BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_shares_gen_insert AFTER INSERT ON clan_shares BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_shares_gen_update AFTER UPDATE ON clan_shares BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_shares_gen_delete AFTER DELETE ON clan_shares BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_public_redactions_gen_insert AFTER INSERT ON clan_public_redactions BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_public_redactions_gen_update AFTER UPDATE ON clan_public_redactions BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_public_redactions_gen_delete AFTER DELETE ON clan_public_redactions BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_orders_gen_insert AFTER INSERT ON orders BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_orders_gen_delete AFTER DELETE ON orders BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_maps_gen_insert AFTER INSERT ON clan_maps BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_maps_gen_update AFTER UPDATE ON clan_maps BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_clan_maps_gen_delete AFTER DELETE ON clan_maps BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_game_turns_gen_insert AFTER INSERT ON game_turns BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_game_turns_gen_update AFTER UPDATE ON game_turns BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_game_turns_gen_delete AFTER DELETE ON game_turns BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_game_clans_gen_insert AFTER INSERT ON game_clans BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_game_clans_gen_update AFTER UPDATE ON game_clans BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_game_clans_gen_delete AFTER DELETE ON game_clans BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_user_prefs_gen_insert AFTER INSERT ON user_prefs BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_user_prefs_gen_update AFTER UPDATE ON user_prefs BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS trg_user_prefs_gen_delete AFTER DELETE ON user_prefs BEGIN
+    UPDATE store_generation SET gen = gen + 1, updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = 1;
+END;
+
+-- One-time login links (only the SHA-256 of the token is stored; see CreateLoginToken)
+CREATE TABLE IF NOT EXISTS login_tokens (
+                                            token_hash  TEXT PRIMARY KEY,
+                                            user_handle TEXT NOT NULL REFERENCES users(handle) ON DELETE CASCADE,
+                                            created_at  TEXT NOT NULL, -- ISO8601 UTC
+                                            expires_at  TEXT NOT NULL, -- ISO8601 UTC
+                                            used_at     TEXT           -- set when the link is used
+);
+CREATE INDEX IF NOT EXISTS idx_login_tokens_user ON login_tokens(user_handle);
+
+-- Login sessions (only the SHA-256 of the session cookie is stored; see InsertSession)
+CREATE TABLE IF NOT EXISTS sessions (
+                                        id_hash     TEXT PRIMARY KEY,
+                                        ref         TEXT NOT NULL UNIQUE,
+                                        user_handle TEXT NOT NULL REFERENCES users(handle) ON DELETE CASCADE,
+                                        user_json   TEXT NOT NULL, -- auth.User: the game and clans picked at login
+                                        created_at  TEXT NOT NULL, -- ISO8601 UTC
+                                        expires_at  TEXT NOT NULL, -- ISO8601 UTC
+                                        ip          TEXT NOT NULL,
+                                        user_agent  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_handle);
+
+-- API tokens for scripts. Only a hash of the token is stored; scopes is a
+-- comma-separated list of capabilities, e.g. "gm".
+CREATE TABLE IF NOT EXISTS api_tokens (
+                                          id           INTEGER PRIMARY KEY,
+                                          token_hash   TEXT NOT NULL UNIQUE,
+                                          user_handle  TEXT NOT NULL REFERENCES users(handle) ON DELETE CASCADE,
+                                          name         TEXT NOT NULL,
+                                          scopes       TEXT NOT NULL,
+                                          created_at   TEXT NOT NULL, -- ISO8601 UTC
+                                          last_used_at TEXT,          -- ISO8601 UTC
+                                          revoked_at   TEXT,          -- ISO8601 UTC; set when the token is revoked
+                                          UNIQUE(user_handle, name)
+);
+
+-- Audit log of changes made by GMs or by the server itself (actor "system").
+CREATE TABLE IF NOT EXISTS audit_log (
+                                         id         INTEGER PRIMARY KEY,
+                                         created_at TEXT NOT NULL, -- ISO8601 UTC
+                                         actor      TEXT NOT NULL, -- user handle, or "system"
+                                         action     TEXT NOT NULL, -- e.g., "turn.activate"
+                                         game_id    TEXT,
+                                         detail     TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_game ON audit_log(game_id, created_at);
+
+-- Requests served by the web server, kept only when the server runs with
+-- usage statistics turned on. Nothing here leaves the database.
+CREATE TABLE IF NOT EXISTS request_log (
+                                           id          INTEGER PRIMARY KEY,
+                                           created_at  TEXT NOT NULL,    -- ISO8601 UTC
+                                           user_handle TEXT,             -- NULL when not logged in
+                                           route       TEXT NOT NULL,    -- the matched route pattern, e.g., "/units/{id}"
+                                           status      INTEGER NOT NULL, -- HTTP status code
+                                           duration_ms INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_request_log_created ON request_log(created_at);
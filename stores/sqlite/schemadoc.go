@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SchemaTable describes one table in the live database.
+type SchemaTable struct {
+	Name        string
+	Columns     []SchemaColumn
+	ForeignKeys []SchemaForeignKey
+	Indexes     []SchemaIndex
+}
+
+// SchemaColumn describes a column, as reported by PRAGMA table_info.
+type SchemaColumn struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Default string
+	PK      bool
+}
+
+// SchemaForeignKey describes a column that references another table.
+type SchemaForeignKey struct {
+	Column   string
+	Table    string // referenced table
+	To       string // referenced column
+	OnDelete string
+}
+
+// SchemaIndex describes an index on a table.
+type SchemaIndex struct {
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+// DescribeSchema introspects sqlite_master and the table pragmas and returns
+// every user table, in name order.
+func (s *SQLiteStore) DescribeSchema(ctx context.Context) ([]SchemaTable, error) {
+	names, err := s.queryStrings(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, dbError("describe schema", err)
+	}
+
+	var tables []SchemaTable
+	for _, name := range names {
+		t := SchemaTable{Name: name}
+
+		rows, err := s.db.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?)`, name)
+		if err != nil {
+			return nil, dbError(fmt.Sprintf("describe %s: columns", name), err)
+		}
+		for rows.Next() {
+			var c SchemaColumn
+			var dflt *string
+			var pk int
+			if err := rows.Scan(&c.Name, &c.Type, &c.NotNull, &dflt, &pk); err != nil {
+				rows.Close()
+				return nil, dbError(fmt.Sprintf("describe %s: columns", name), err)
+			}
+			if dflt != nil {
+				c.Default = *dflt
+			}
+			c.PK = pk > 0
+			t.Columns = append(t.Columns, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, dbError(fmt.Sprintf("describe %s: columns", name), err)
+		}
+
+		rows, err = s.db.QueryContext(ctx, `SELECT "from", "table", "to", on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`, name)
+		if err != nil {
+			return nil, dbError(fmt.Sprintf("describe %s: foreign keys", name), err)
+		}
+		for rows.Next() {
+			var fk SchemaForeignKey
+			var to *string
+			if err := rows.Scan(&fk.Column, &fk.Table, &to, &fk.OnDelete); err != nil {
+				rows.Close()
+				return nil, dbError(fmt.Sprintf("describe %s: foreign keys", name), err)
+			}
+			if to != nil {
+				fk.To = *to
+			}
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, dbError(fmt.Sprintf("describe %s: foreign keys", name), err)
+		}
+
+		indexes, err := s.queryStrings(ctx, `SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL ORDER BY name`, name)
+		if err != nil {
+			return nil, dbError(fmt.Sprintf("describe %s: indexes", name), err)
+		}
+		for _, index := range indexes {
+			idx := SchemaIndex{Name: index}
+			if err := s.db.QueryRowContext(ctx, `SELECT "unique" FROM pragma_index_list(?) WHERE name = ?`, name, index).Scan(&idx.Unique); err != nil {
+				return nil, dbError(fmt.Sprintf("describe %s: index %s", name, index), err)
+			}
+			if idx.Columns, err = s.queryStrings(ctx, `SELECT name FROM pragma_index_info(?) ORDER BY seqno`, index); err != nil {
+				return nil, dbError(fmt.Sprintf("describe %s: index %s", name, index), err)
+			}
+			t.Indexes = append(t.Indexes, idx)
+		}
+
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+func (s *SQLiteStore) queryStrings(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+// WriteSchemaMarkdown writes the tables as a Markdown document with one section per table.
+func WriteSchemaMarkdown(w io.Writer, tables []SchemaTable) error {
+	var sb strings.Builder
+	sb.WriteString("# Database Schema\n\n")
+	sb.WriteString("Generated from the live database by `tnrpt db schema`. Do not edit.\n\n")
+	for _, t := range tables {
+		fmt.Fprintf(&sb, "- [%s](#%s)\n", t.Name, t.Name)
+	}
+	for _, t := range tables {
+		fmt.Fprintf(&sb, "\n## %s\n\n", t.Name)
+		sb.WriteString("| Column | Type | Null | Default | Key |\n")
+		sb.WriteString("|--------|------|------|---------|-----|\n")
+		refs := map[string]SchemaForeignKey{}
+		for _, fk := range t.ForeignKeys {
+			refs[fk.Column] = fk
+		}
+		for _, c := range t.Columns {
+			null := "yes"
+			if c.NotNull || c.PK {
+				null = "no"
+			}
+			var keys []string
+			if c.PK {
+				keys = append(keys, "PK")
+			}
+			if fk, ok := refs[c.Name]; ok {
+				key := fmt.Sprintf("FK → [%s](#%s)", fk.Table, fk.Table)
+				if fk.To != "" {
+					key = fmt.Sprintf("FK → [%s](#%s).%s", fk.Table, fk.Table, fk.To)
+				}
+				if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+					key += " on delete " + strings.ToLower(fk.OnDelete)
+				}
+				keys = append(keys, key)
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", c.Name, c.Type, null, c.Default, strings.Join(keys, ", "))
+		}
+		if len(t.Indexes) > 0 {
+			sb.WriteString("\nIndexes:\n\n")
+			for _, idx := range t.Indexes {
+				unique := ""
+				if idx.Unique {
+					unique = " (unique)"
+				}
+				fmt.Fprintf(&sb, "- `%s` on %s%s\n", idx.Name, strings.Join(idx.Columns, ", "), unique)
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteSchemaDot writes the tables as a Graphviz entity-relationship diagram.
+// Render it with, for example, `dot -Tsvg schema.dot -o schema.svg`.
+func WriteSchemaDot(w io.Writer, tables []SchemaTable) error {
+	var sb strings.Builder
+	sb.WriteString("digraph schema {\n")
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=plaintext, fontname=\"Helvetica\", fontsize=10];\n")
+	sb.WriteString("\tedge [arrowhead=crow, arrowtail=none, fontname=\"Helvetica\", fontsize=8];\n")
+	for _, t := range tables {
+		fmt.Fprintf(&sb, "\t%q [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">", t.Name)
+		fmt.Fprintf(&sb, "<tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>", t.Name)
+		for _, c := range t.Columns {
+			label := c.Name
+			if c.PK {
+				label = "<u>" + label + "</u>"
+			}
+			fmt.Fprintf(&sb, "<tr><td align=\"left\" port=%q>%s <i>%s</i></td></tr>", c.Name, label, strings.ToLower(c.Type))
+		}
+		sb.WriteString("</table>>];\n")
+	}
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			fmt.Fprintf(&sb, "\t%q:%q -> %q;\n", t.Name, fk.Column, fk.Table)
+		}
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// The methods in this file make SQLiteStore an auth.SessionBackend, so that
+// sessions survive a server restart.
+var _ auth.SessionBackend = (*SQLiteStore)(nil)
+
+// InsertSession stores a new session, first deleting expired ones.
+// Only a hash of the session ID is stored, as with login tokens.
+func (s *SQLiteStore) InsertSession(ctx context.Context, session *auth.Session) error {
+	user, err := json.Marshal(session.User)
+	if err != nil {
+		return fmt.Errorf("encode session user: %w", err)
+	}
+	const purge = `DELETE FROM sessions WHERE expires_at <= ?`
+	if _, err := s.db.ExecContext(ctx, purge, s.now().Format(time.RFC3339)); err != nil {
+		return dbError("purge sessions", err)
+	}
+	const query = `
+		INSERT INTO sessions (id_hash, ref, user_handle, user_json, created_at, expires_at, ip, user_agent)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = s.db.ExecContext(ctx, query,
+		hashLoginToken(session.ID), session.Ref, session.User.Handle, string(user),
+		session.CreatedAt.UTC().Format(time.RFC3339), session.ExpiresAt.UTC().Format(time.RFC3339),
+		session.IP, session.UserAgent)
+	return dbError("insert session", err)
+}
+
+// GetSession returns the session with the ID, or nil if there is none.
+func (s *SQLiteStore) GetSession(ctx context.Context, id string) (*auth.Session, error) {
+	const query = `
+		SELECT ref, user_json, created_at, expires_at, ip, user_agent
+		FROM sessions WHERE id_hash = ?
+	`
+	session, err := scanSession(s.db.QueryRowContext(ctx, query, hashLoginToken(id)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError("get session", err)
+	}
+	session.ID = id
+	return session, nil
+}
+
+func (s *SQLiteStore) DeleteSession(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id_hash = ?`, hashLoginToken(id))
+	return dbError("delete session", err)
+}
+
+// SessionsForUser returns the user's sessions that expire after now, newest
+// first. Their IDs are empty: only a hash of each is stored.
+func (s *SQLiteStore) SessionsForUser(ctx context.Context, handle string, now time.Time) ([]*auth.Session, error) {
+	const query = `
+		SELECT ref, user_json, created_at, expires_at, ip, user_agent
+		FROM sessions WHERE user_handle = ? AND expires_at > ?
+		ORDER BY created_at DESC, rowid DESC
+	`
+	rows, err := s.db.QueryContext(ctx, query, handle, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, dbError("query sessions", err)
+	}
+	defer rows.Close()
+
+	var list []*auth.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, dbError("scan session", err)
+		}
+		list = append(list, session)
+	}
+	return list, dbError("query sessions", rows.Err())
+}
+
+func (s *SQLiteStore) RevokeSession(ctx context.Context, handle, ref string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_handle = ? AND ref = ?`, handle, ref)
+	if err != nil {
+		return false, dbError("revoke session", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, dbError("revoke session", err)
+	}
+	return n > 0, nil
+}
+
+func (s *SQLiteStore) RevokeSessions(ctx context.Context, handle string) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_handle = ?`, handle)
+	if err != nil {
+		return 0, dbError("revoke sessions", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, dbError("revoke sessions", err)
+	}
+	return int(n), nil
+}
+
+func scanSession(row interface{ Scan(...any) error }) (*auth.Session, error) {
+	var session auth.Session
+	var user, created, expires string
+	if err := row.Scan(&session.Ref, &user, &created, &expires, &session.IP, &session.UserAgent); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(user), &session.User); err != nil {
+		return nil, err
+	}
+	session.CreatedAt = parseTime(created)
+	session.ExpiresAt = parseTime(expires)
+	return &session, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/clock"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// TestSessions checks that sessions kept in the database survive the store
+// being closed and opened again, as they do a server restart, and that they
+// end when revoked.
+func TestSessions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	now := clock.NewFixed(time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC))
+	open := func() (*store.SQLiteStore, *auth.SessionStore) {
+		t.Helper()
+		s, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path, Clock: now})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions := auth.NewSessionStore()
+		sessions.SetBackend(s)
+		sessions.SetClock(now)
+		return s, sessions
+	}
+
+	s, sessions := open()
+	if _, err := s.ImportUsers(ctx, "test", []store.UserRecord{
+		{Handle: "clan0987", Roles: []string{"active", "user"}},
+		{Handle: "gm", Roles: []string{"active", "gm"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	user := auth.User{Handle: "clan0987", UserName: "Clan 987", GameID: "0301", ClanNo: 987, Clans: []int{987}}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	kept, err := sessions.Create(user, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := sessions.Create(user, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := sessions.Create(auth.User{Handle: "gm", UserName: "Game Master"}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, sessions = open()
+	defer s.Close()
+	got, err := sessions.Get(ctx, kept.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Ref != kept.Ref || got.User.Handle != user.Handle || got.User.ClanNo != user.ClanNo || len(got.User.Clans) != 1 {
+		t.Fatalf("after reopen: session = %+v, want %+v", got, kept)
+	}
+
+	if ok, err := sessions.Revoke(ctx, "clan0987", revoked.Ref); err != nil || !ok {
+		t.Fatalf("revoke: %v, %v", ok, err)
+	}
+	if got, err := sessions.Get(ctx, revoked.ID); err != nil || got != nil {
+		t.Errorf("revoked session: got %+v, %v, want none", got, err)
+	}
+	if ok, err := sessions.Revoke(ctx, "gm", kept.Ref); err != nil || ok {
+		t.Errorf("revoking another user's session: got %v, %v, want false", ok, err)
+	}
+
+	if n, err := sessions.RevokeAll(ctx, "clan0987"); err != nil || n != 1 {
+		t.Errorf("revoke all: got %d, %v, want 1", n, err)
+	}
+	if got, err := sessions.Get(ctx, kept.ID); err != nil || got != nil {
+		t.Errorf("after revoke all: got %+v, %v, want none", got, err)
+	}
+	if got, err := sessions.Get(ctx, other.ID); err != nil || got == nil {
+		t.Errorf("another user's session: got %+v, %v, want it kept", got, err)
+	}
+
+	now.Advance(auth.DefaultCookieConfig().Lifetime + time.Second)
+	if got, err := sessions.Get(ctx, other.ID); err != nil || got != nil {
+		t.Errorf("expired session: got %+v, %v, want none", got, err)
+	}
+}
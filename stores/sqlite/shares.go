@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+)
+
+// GrantClanShare lets sharedWith include clanNo's tiles in its alliance view.
+// Granting an existing share is not an error.
+func (s *SQLiteStore) GrantClanShare(ctx context.Context, gameID string, clanNo, sharedWith int) error {
+	if clanNo == sharedWith {
+		return cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("share: clan %d can't share with itself", clanNo))
+	}
+	const query = `
+		INSERT INTO clan_shares (game_id, clan_no, shared_with, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(game_id, clan_no, shared_with) DO NOTHING
+	`
+	if _, err := s.db.ExecContext(ctx, query, gameID, clanNo, sharedWith, s.now().Format(time.RFC3339)); err != nil {
+		return dbError("grant share", err)
+	}
+	return nil
+}
+
+// RevokeClanShare removes a share. It returns false if there was nothing to revoke.
+func (s *SQLiteStore) RevokeClanShare(ctx context.Context, gameID string, clanNo, sharedWith int) (bool, error) {
+	const query = `DELETE FROM clan_shares WHERE game_id = ? AND clan_no = ? AND shared_with = ?`
+	result, err := s.db.ExecContext(ctx, query, gameID, clanNo, sharedWith)
+	if err != nil {
+		return false, dbError("revoke share", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, dbError("rows affected", err)
+	}
+	return n > 0, nil
+}
+
+// AlliedClans returns the clans that have shared their tiles with clanNo, in clan order.
+func (s *SQLiteStore) AlliedClans(ctx context.Context, gameID string, clanNo int) ([]int, error) {
+	const query = `
+		SELECT clan_no FROM clan_shares
+		WHERE game_id = ? AND shared_with = ?
+		ORDER BY clan_no
+	`
+	rows, err := s.db.QueryContext(ctx, query, gameID, clanNo)
+	if err != nil {
+		return nil, dbError("query allied clans", err)
+	}
+	defer rows.Close()
+
+	var clans []int
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			return nil, dbError("scan allied clan", err)
+		}
+		clans = append(clans, n)
+	}
+	return clans, rows.Err()
+}
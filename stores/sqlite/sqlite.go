@@ -8,9 +8,13 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/clock"
 	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/tracing"
 	"github.com/mdhender/tnrpt/web/auth"
 	_ "modernc.org/sqlite"
 )
@@ -21,7 +25,9 @@ var schemaSQL string
 // SQLiteStore is a SQLite-backed store for turn report data.
 // It wraps an in-memory SQLite database with foreign key support.
 type SQLiteStore struct {
-	db *sql.DB
+	db    *sql.DB
+	clock clock.Clock
+	ids   clock.IDs
 }
 
 // StoreConfig holds configuration for creating a SQLiteStore.
@@ -30,15 +36,16 @@ type StoreConfig struct {
 	// If empty, an in-memory database is used.
 	Path string
 
-	// InitSchema controls whether to run schema initialization.
-	// For file-based mode, this should typically be false since the server
-	// expects the database to already exist with schema applied.
-	InitSchema bool
+	// Clock timestamps the rows the store creates. If nil, clock.Real is used.
+	Clock clock.Clock
+
+	// IDs generates login and API tokens. If nil, clock.Random is used.
+	IDs clock.IDs
 }
 
 // NewSQLiteStore creates a new in-memory SQLite store with schema loaded.
 func NewSQLiteStore() (*SQLiteStore, error) {
-	return NewSQLiteStoreWithConfig(StoreConfig{InitSchema: true})
+	return NewSQLiteStoreWithConfig(StoreConfig{})
 }
 
 // NewSQLiteStoreWithConfig creates a SQLite store based on the provided configuration.
@@ -65,20 +72,32 @@ func NewSQLiteStoreWithConfig(cfg StoreConfig) (*SQLiteStore, error) {
 		)
 	}
 
-	db, err := sql.Open("sqlite", dsn)
+	// with tracing on, queries made for a request or pipeline stage get spans
+	var db *sql.DB
+	var err error
+	if tracing.Enabled() {
+		db, err = tracing.OpenDB("sqlite", dsn)
+	} else {
+		db, err = sql.Open("sqlite", dsn)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	// Initialize schema if requested (always true for in-memory, configurable for file-based)
-	if cfg.InitSchema || cfg.Path == "" {
-		if _, err := db.Exec(schemaSQL); err != nil {
-			db.Close()
-			return nil, fmt.Errorf("exec schema: %w", err)
-		}
+	// Create the schema, or bring a database made by an older schema.sql up to date
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
 	}
 
-	return &SQLiteStore{db: db}, nil
+	s := &SQLiteStore{db: db, clock: cfg.Clock, ids: cfg.IDs}
+	if s.clock == nil {
+		s.clock = clock.Real
+	}
+	if s.ids == nil {
+		s.ids = clock.Random
+	}
+	return s, nil
 }
 
 // InitDatabase creates a new SQLite database file and initializes the schema.
@@ -103,8 +122,8 @@ func InitDatabase(path string) error {
 	defer db.Close()
 
 	// Run the embedded schema to create tables
-	if _, err := db.Exec(schemaSQL); err != nil {
-		return fmt.Errorf("exec schema: %w", err)
+	if err := migrate(context.Background(), db); err != nil {
+		return err
 	}
 
 	return nil
@@ -131,17 +150,27 @@ func CompactDatabase(path string) error {
 
 	// Checkpoint WAL to merge all changes into the main database file
 	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
-		return fmt.Errorf("checkpoint WAL: %w", err)
+		return dbError("checkpoint WAL", err)
 	}
 
 	// VACUUM rebuilds the database file, repacking it into minimal disk space
 	if _, err := db.Exec("VACUUM"); err != nil {
-		return fmt.Errorf("vacuum: %w", err)
+		return dbError("vacuum", err)
 	}
 
 	return nil
 }
 
+// SetClock sets the clock used to timestamp rows, for testing.
+func (s *SQLiteStore) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time from the store's clock.
+func (s *SQLiteStore) now() time.Time {
+	return s.clock.Now().UTC()
+}
+
 // Close closes the database connection.
 func (s *SQLiteStore) Close() error {
 	if s.db != nil {
@@ -154,28 +183,33 @@ func (s *SQLiteStore) Close() error {
 type Game struct {
 	ID          string
 	Description string
+	AutoAdvance bool // activate the next turn when the active turn's orders are due
+	UnitIDs     model.UnitIDRules
+	World       model.WorldRules
 	Turns       []GameTurn
 }
 
 // GameTurn represents a turn in a game.
 type GameTurn struct {
-	TurnNo   int
+	TurnNo   model.TurnNo
 	IsActive bool
+	DueDate  time.Time // orders due, in UTC; zero if not set
 }
 
 // GetAllGames returns all games with their turns.
 func (s *SQLiteStore) GetAllGames(ctx context.Context) ([]Game, error) {
-	const gameQuery = `SELECT id, COALESCE(description, id) FROM games ORDER BY id`
+	const gameQuery = `SELECT id, COALESCE(description, id), auto_advance, clan_digits, grid_rows, grid_cols, wrap_rows, wrap_cols FROM games ORDER BY id`
 	rows, err := s.db.QueryContext(ctx, gameQuery)
 	if err != nil {
-		return nil, fmt.Errorf("query games: %w", err)
+		return nil, dbError("query games", err)
 	}
 	defer rows.Close()
 
 	var games []Game
 	for rows.Next() {
 		var g Game
-		if err := rows.Scan(&g.ID, &g.Description); err != nil {
+		if err := rows.Scan(&g.ID, &g.Description, &g.AutoAdvance, &g.UnitIDs.ClanDigits,
+			&g.World.GridRows, &g.World.GridCols, &g.World.WrapRows, &g.World.WrapCols); err != nil {
 			return nil, err
 		}
 		g.Turns = []GameTurn{}
@@ -186,24 +220,29 @@ func (s *SQLiteStore) GetAllGames(ctx context.Context) ([]Game, error) {
 	}
 
 	// Load turns for each game
-	const turnQuery = `SELECT turn_id, is_active FROM game_turns WHERE game_id = ? ORDER BY turn_id`
+	const turnQuery = `SELECT turn_id, is_active, due_date FROM game_turns WHERE game_id = ? ORDER BY turn_id`
 	for i, game := range games {
 		turnRows, err := s.db.QueryContext(ctx, turnQuery, game.ID)
 		if err != nil {
-			return nil, fmt.Errorf("query turns for game %s: %w", game.ID, err)
+			return nil, dbError(fmt.Sprintf("query turns for game %s", game.ID), err)
 		}
 		defer turnRows.Close()
 
 		for turnRows.Next() {
-			var turnNo int
+			var turnNo model.TurnNo
 			var isActive int
-			if err := turnRows.Scan(&turnNo, &isActive); err != nil {
+			var dueDate sql.NullString
+			if err := turnRows.Scan(&turnNo, &isActive, &dueDate); err != nil {
 				return nil, err
 			}
-			games[i].Turns = append(games[i].Turns, GameTurn{
+			gt := GameTurn{
 				TurnNo:   turnNo,
 				IsActive: isActive == 1,
-			})
+			}
+			if dueDate.Valid {
+				gt.DueDate, _ = time.Parse(time.RFC3339, dueDate.String)
+			}
+			games[i].Turns = append(games[i].Turns, gt)
 		}
 		if err := turnRows.Err(); err != nil {
 			return nil, err
@@ -230,11 +269,11 @@ func (s *SQLiteStore) AddReportFile(rf *model.ReportFile) error {
 		rf.CreatedAt.Format(time.RFC3339),
 	)
 	if err != nil {
-		return fmt.Errorf("insert report_file: %w", err)
+		return dbError("insert report_file", err)
 	}
 	rf.ID, err = result.LastInsertId()
 	if err != nil {
-		return fmt.Errorf("get report_file id: %w", err)
+		return dbError("get report_file id", err)
 	}
 	return nil
 }
@@ -281,33 +320,48 @@ func (s *SQLiteStore) AddReport(rx *model.ReportX) error {
 		}
 	}
 
+	if _, err := s.RefreshUnitEvents(ctx, rx.Game); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (s *SQLiteStore) insertReportExtract(ctx context.Context, rx *model.ReportX) (int64, error) {
 	const query = `
-		INSERT INTO report_extracts (report_file_id, game, clan_no, turn_no, created_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO report_extracts (report_file_id, game, clan_no, turn_no, season, weather, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 	result, err := s.db.ExecContext(ctx, query,
 		rx.ReportFileID,
 		rx.Game,
 		rx.ClanNo,
 		rx.TurnNo,
+		nullString(rx.Season),
+		nullString(rx.Weather),
 		rx.CreatedAt.Format(time.RFC3339),
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert report_extract: %w", err)
+		return 0, dbError("insert report_extract", err)
 	}
 	return result.LastInsertId()
 }
 
 func (s *SQLiteStore) insertUnitExtract(ctx context.Context, ux *model.UnitX) (int64, error) {
-	startGrid, startCol, startRow := parseTNCoord(ux.StartTN)
-	endGrid, endCol, endRow := parseTNCoord(ux.EndTN)
+	startGrid, startCol, startRow, err := ux.StartTN.Parse()
+	if err != nil {
+		return 0, cerrs.Wrap(cerrs.CodeInvalidInput, fmt.Sprintf("insert unit_extract: unit %s: start", ux.UnitID), err)
+	}
+	endGrid, endCol, endRow, err := ux.EndTN.Parse()
+	if err != nil {
+		return 0, cerrs.Wrap(cerrs.CodeInvalidInput, fmt.Sprintf("insert unit_extract: unit %s: end", ux.UnitID), err)
+	}
 	clanID := ux.ClanID
 	if clanID == "" {
-		clanID = extractClanID(ux.UnitID)
+		clanID, err = model.DefaultUnitIDRules.ClanID(ux.UnitID)
+		if err != nil {
+			return 0, fmt.Errorf("insert unit_extract: %w", err)
+		}
 	}
 
 	const query = `
@@ -342,20 +396,16 @@ func (s *SQLiteStore) insertUnitExtract(ctx context.Context, ux *model.UnitX) (i
 		srcNote,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert unit_extract: %w", err)
+		return 0, dbError("insert unit_extract", err)
 	}
 	return result.LastInsertId()
 }
 
-func extractClanID(unitID string) string {
-	if len(unitID) >= 4 {
-		return unitID[1:4]
-	}
-	return ""
-}
-
 func (s *SQLiteStore) insertAct(ctx context.Context, act *model.Act) (int64, error) {
-	destGrid, destCol, destRow := parseTNCoord(act.DestTN)
+	destGrid, destCol, destRow, err := act.DestTN.Parse()
+	if err != nil {
+		return 0, cerrs.Wrap(cerrs.CodeInvalidInput, fmt.Sprintf("insert act: act %d: dest", act.Seq), err)
+	}
 
 	const query = `
 		INSERT INTO acts (
@@ -400,7 +450,7 @@ func (s *SQLiteStore) insertAct(ctx context.Context, act *model.Act) (int64, err
 		srcNote,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert act: %w", err)
+		return 0, dbError("insert act", err)
 	}
 	return result.LastInsertId()
 }
@@ -409,10 +459,10 @@ func (s *SQLiteStore) insertStep(ctx context.Context, step *model.Step) (int64,
 	const query = `
 		INSERT INTO steps (
 			act_id, seq, kind, ok, note,
-			dir, fail_why, terr, special, label,
+			dir, fail_why, fail_raw, terr, special, label,
 			src_doc_id, src_turn_no, src_unit_id, src_act_seq, src_step_seq, src_note
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	ok := sql.NullInt64{Int64: boolToInt(step.Ok), Valid: true}
@@ -436,6 +486,7 @@ func (s *SQLiteStore) insertStep(ctx context.Context, step *model.Step) (int64,
 		nullString(step.Note),
 		nullString(step.Dir),
 		nullString(step.FailWhy),
+		nullString(step.FailRaw),
 		nullString(step.Terr),
 		boolToInt(step.Special),
 		nullString(step.Label),
@@ -447,24 +498,24 @@ func (s *SQLiteStore) insertStep(ctx context.Context, step *model.Step) (int64,
 		srcNote,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert step: %w", err)
+		return 0, dbError("insert step", err)
 	}
 
 	stepID, err := result.LastInsertId()
 	if err != nil {
-		return 0, fmt.Errorf("get step id: %w", err)
+		return 0, dbError("get step id", err)
 	}
 
 	// Insert child records for encounters
 	if step.Enc != nil {
-		if err := s.insertStepEncounters(ctx, stepID, step.Enc); err != nil {
+		if err := insertStepEncounters(ctx, s.db, stepID, step.Enc); err != nil {
 			return 0, err
 		}
 	}
 
 	// Insert borders
 	for _, border := range step.Borders {
-		if err := s.insertStepBorder(ctx, stepID, border); err != nil {
+		if err := insertStepBorder(ctx, s.db, stepID, border); err != nil {
 			return 0, err
 		}
 	}
@@ -472,35 +523,35 @@ func (s *SQLiteStore) insertStep(ctx context.Context, step *model.Step) (int64,
 	return stepID, nil
 }
 
-func (s *SQLiteStore) insertStepEncounters(ctx context.Context, stepID int64, enc *model.Enc) error {
+func insertStepEncounters(ctx context.Context, db execer, stepID int64, enc *model.Enc) error {
 	for _, u := range enc.Units {
 		const query = `INSERT INTO step_enc_units (step_id, unit_id, name, clan_no) VALUES (?, ?, ?, ?)`
-		if _, err := s.db.ExecContext(ctx, query, stepID, u.UnitID, nullString(u.Name), nullString(u.ClanNo)); err != nil {
-			return fmt.Errorf("insert step_enc_unit: %w", err)
+		if _, err := db.ExecContext(ctx, query, stepID, u.UnitID, nullString(u.Name), nullString(u.ClanNo)); err != nil {
+			return dbError("insert step_enc_unit", err)
 		}
 	}
 
 	for _, st := range enc.Sets {
 		const query = `INSERT INTO step_enc_sets (step_id, name, kind, clan_no) VALUES (?, ?, ?, ?)`
-		if _, err := s.db.ExecContext(ctx, query, stepID, st.Name, nullString(st.Kind), nullString(st.ClanNo)); err != nil {
-			return fmt.Errorf("insert step_enc_set: %w", err)
+		if _, err := db.ExecContext(ctx, query, stepID, st.Name, nullString(st.Kind), nullString(st.ClanNo)); err != nil {
+			return dbError("insert step_enc_set", err)
 		}
 	}
 
 	for _, r := range enc.Rsrc {
 		const query = `INSERT INTO step_enc_rsrc (step_id, kind, qty) VALUES (?, ?, ?)`
-		if _, err := s.db.ExecContext(ctx, query, stepID, r.Kind, nullInt(r.Qty)); err != nil {
-			return fmt.Errorf("insert step_enc_rsrc: %w", err)
+		if _, err := db.ExecContext(ctx, query, stepID, r.Kind, nullInt(r.Qty)); err != nil {
+			return dbError("insert step_enc_rsrc", err)
 		}
 	}
 
 	return nil
 }
 
-func (s *SQLiteStore) insertStepBorder(ctx context.Context, stepID int64, border *model.BorderObs) error {
+func insertStepBorder(ctx context.Context, db execer, stepID int64, border *model.BorderObs) error {
 	const query = `INSERT INTO step_borders (step_id, dir, kind) VALUES (?, ?, ?)`
-	if _, err := s.db.ExecContext(ctx, query, stepID, border.Dir, border.Kind); err != nil {
-		return fmt.Errorf("insert step_border: %w", err)
+	if _, err := db.ExecContext(ctx, query, stepID, border.Dir, border.Kind); err != nil {
+		return dbError("insert step_border", err)
 	}
 	return nil
 }
@@ -530,7 +581,7 @@ func (s *SQLiteStore) Units(orderBy string) ([]*model.UnitX, error) {
 }
 
 // UnitsByTurn returns units filtered by turn number.
-func (s *SQLiteStore) UnitsByTurn(turnNo int) ([]*model.UnitX, error) {
+func (s *SQLiteStore) UnitsByTurn(turnNo model.TurnNo) ([]*model.UnitX, error) {
 
 	const query = `
 		SELECT id, report_x_id, unit_id, turn_no,
@@ -545,7 +596,7 @@ func (s *SQLiteStore) UnitsByTurn(turnNo int) ([]*model.UnitX, error) {
 }
 
 // UnitsByClan returns units filtered by clan ID.
-func (s *SQLiteStore) UnitsByClan(clanID string, turnNo int) ([]*model.UnitX, error) {
+func (s *SQLiteStore) UnitsByClan(clanID string, turnNo model.TurnNo) ([]*model.UnitX, error) {
 
 	if len(clanID) < 3 {
 		return nil, nil
@@ -577,7 +628,7 @@ func (s *SQLiteStore) UnitsByClan(clanID string, turnNo int) ([]*model.UnitX, er
 }
 
 // UnitsByGameClan returns units filtered by game and clan number.
-func (s *SQLiteStore) UnitsByGameClan(gameID string, clanNo int, turnNo int) ([]*model.UnitX, error) {
+func (s *SQLiteStore) UnitsByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]*model.UnitX, error) {
 	clanStr := formatClanNo(clanNo)
 
 	if turnNo > 0 {
@@ -606,6 +657,55 @@ func (s *SQLiteStore) UnitsByGameClan(gameID string, clanNo int, turnNo int) ([]
 	return s.queryUnitsWithArgs(query, gameID, clanStr)
 }
 
+// ReportExtractsByGameTurn returns every clan's report extract for a game and turn,
+// with units, acts, and steps loaded, in clan order.
+func (s *SQLiteStore) ReportExtractsByGameTurn(ctx context.Context, game string, turnNo model.TurnNo) ([]*model.ReportX, error) {
+	const query = `
+		SELECT id, report_file_id, game, clan_no, turn_no, season, weather, created_at
+		FROM report_extracts
+		WHERE game = ? AND turn_no = ?
+		ORDER BY clan_no, id
+	`
+	rows, err := s.db.QueryContext(ctx, query, game, turnNo)
+	if err != nil {
+		return nil, dbError("query report extracts", err)
+	}
+	var reports []*model.ReportX
+	for rows.Next() {
+		var rx model.ReportX
+		var season, weather sql.NullString
+		var createdAt string
+		if err := rows.Scan(&rx.ID, &rx.ReportFileID, &rx.Game, &rx.ClanNo, &rx.TurnNo, &season, &weather, &createdAt); err != nil {
+			rows.Close()
+			return nil, dbError("scan report extract", err)
+		}
+		rx.Season, rx.Weather = season.String, weather.String
+		if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+			rx.CreatedAt = t
+		}
+		reports = append(reports, &rx)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, dbError("query report extracts", err)
+	}
+
+	const unitsQuery = `
+		SELECT id, report_x_id, unit_id, turn_no,
+		       start_grid, start_col, start_row,
+		       end_grid, end_col, end_row
+		FROM unit_extracts
+		WHERE report_x_id = ?
+		ORDER BY unit_id
+	`
+	for _, rx := range reports {
+		if rx.Units, err = s.queryUnitsWithArgs(unitsQuery, rx.ID); err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
+
 // UnitByID returns a single unit by database ID.
 func (s *SQLiteStore) UnitByID(id int64) (*model.UnitX, error) {
 	const query = `
@@ -671,13 +771,21 @@ func (s *SQLiteStore) UnitByIDAndGameClan(id int64, gameID string, clanNo int) (
 	if len(units) == 0 {
 		return nil, nil
 	}
-	return units[0], nil
+
+	// the report file the unit was read from, for links to its text
+	u := units[0]
+	var docID int64
+	if err := s.db.QueryRow(`SELECT report_file_id FROM report_extracts WHERE id = ?`, u.ReportXID).Scan(&docID); err != nil {
+		return nil, dbError("get unit report file", err)
+	}
+	u.Src = &model.SrcRef{DocID: docID, UnitID: u.UnitID, TurnNo: u.TurnNo}
+	return u, nil
 }
 
 func (s *SQLiteStore) queryUnits(query string) ([]*model.UnitX, error) {
 	rows, err := s.db.Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("query units: %w", err)
+		return nil, dbError("query units", err)
 	}
 	defer rows.Close()
 
@@ -687,7 +795,7 @@ func (s *SQLiteStore) queryUnits(query string) ([]*model.UnitX, error) {
 func (s *SQLiteStore) queryUnitsWithArgs(query string, args ...any) ([]*model.UnitX, error) {
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("query units: %w", err)
+		return nil, dbError("query units", err)
 	}
 	defer rows.Close()
 
@@ -706,11 +814,12 @@ func (s *SQLiteStore) scanUnits(rows *sql.Rows) ([]*model.UnitX, error) {
 			&startGrid, &startCol, &startRow,
 			&endGrid, &endCol, &endRow,
 		); err != nil {
-			return nil, fmt.Errorf("scan unit: %w", err)
+			return nil, dbError("scan unit", err)
 		}
 
-		u.StartTN = formatTNCoord(startGrid, startCol, startRow)
-		u.EndTN = formatTNCoord(endGrid, endCol, endRow)
+		u.Kind = model.UnitKindOf(u.UnitID)
+		u.StartTN = model.NewTNCoord(startGrid, startCol, startRow)
+		u.EndTN = model.NewTNCoord(endGrid, endCol, endRow)
 
 		units = append(units, &u)
 	}
@@ -741,7 +850,7 @@ func (s *SQLiteStore) loadActsForUnit(unitID int64) ([]*model.Act, error) {
 
 	rows, err := s.db.Query(query, unitID)
 	if err != nil {
-		return nil, fmt.Errorf("query acts: %w", err)
+		return nil, dbError("query acts", err)
 	}
 	defer rows.Close()
 
@@ -756,14 +865,14 @@ func (s *SQLiteStore) loadActsForUnit(unitID int64) ([]*model.Act, error) {
 			&a.ID, &a.UnitXID, &a.Seq, &a.Kind, &ok, &note,
 			&targetUnitID, &destGrid, &destCol, &destRow,
 		); err != nil {
-			return nil, fmt.Errorf("scan act: %w", err)
+			return nil, dbError("scan act", err)
 		}
 
 		a.Ok = ok.Valid && ok.Int64 == 1
 		a.Note = note.String
 		a.TargetUnitID = targetUnitID.String
 		if destGrid.Valid {
-			a.DestTN = formatTNCoord(destGrid.String, int(destCol.Int64), int(destRow.Int64))
+			a.DestTN = model.NewTNCoord(destGrid.String, int(destCol.Int64), int(destRow.Int64))
 		}
 
 		acts = append(acts, &a)
@@ -786,7 +895,7 @@ func (s *SQLiteStore) loadActsForUnit(unitID int64) ([]*model.Act, error) {
 
 func (s *SQLiteStore) loadStepsForAct(actID int64) ([]*model.Step, error) {
 	const query = `
-		SELECT id, act_id, seq, kind, ok, note, dir, fail_why, terr, special, label
+		SELECT id, act_id, seq, kind, ok, note, dir, fail_why, fail_raw, terr, special, label
 		FROM steps
 		WHERE act_id = ?
 		ORDER BY seq
@@ -794,7 +903,7 @@ func (s *SQLiteStore) loadStepsForAct(actID int64) ([]*model.Step, error) {
 
 	rows, err := s.db.Query(query, actID)
 	if err != nil {
-		return nil, fmt.Errorf("query steps: %w", err)
+		return nil, dbError("query steps", err)
 	}
 	defer rows.Close()
 
@@ -802,20 +911,21 @@ func (s *SQLiteStore) loadStepsForAct(actID int64) ([]*model.Step, error) {
 	for rows.Next() {
 		var st model.Step
 		var ok sql.NullInt64
-		var note, dir, failWhy, terr, label sql.NullString
+		var note, dir, failWhy, failRaw, terr, label sql.NullString
 		var special int
 
 		if err := rows.Scan(
 			&st.ID, &st.ActID, &st.Seq, &st.Kind, &ok, &note,
-			&dir, &failWhy, &terr, &special, &label,
+			&dir, &failWhy, &failRaw, &terr, &special, &label,
 		); err != nil {
-			return nil, fmt.Errorf("scan step: %w", err)
+			return nil, dbError("scan step", err)
 		}
 
 		st.Ok = ok.Valid && ok.Int64 == 1
 		st.Note = note.String
 		st.Dir = dir.String
 		st.FailWhy = failWhy.String
+		st.FailRaw = failRaw.String
 		st.Terr = terr.String
 		st.Special = special == 1
 		st.Label = label.String
@@ -828,19 +938,21 @@ func (s *SQLiteStore) loadStepsForAct(actID int64) ([]*model.Step, error) {
 // Movements returns all movement steps (adv steps with direction).
 type Movement struct {
 	UnitID  string
-	TurnNo  int
+	TurnNo  model.TurnNo
 	ActSeq  int
 	StepSeq int
 	Dir     string
 	Ok      bool
-	FailWhy string
+	FailWhy string // canonical code, see model.FailReason
+	FailRaw string // the report's wording
 	Terr    string
+	Tile    model.TNCoord // hex the step observed, set by MovementsByGameClan; empty if the walker didn't place it
 }
 
 func (s *SQLiteStore) Movements() ([]Movement, error) {
 
 	const query = `
-		SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.terr
+		SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.fail_raw, st.terr
 		FROM steps st
 		JOIN acts a ON st.act_id = a.id
 		JOIN unit_extracts u ON a.unit_x_id = u.id
@@ -850,7 +962,7 @@ func (s *SQLiteStore) Movements() ([]Movement, error) {
 
 	rows, err := s.db.Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("query movements: %w", err)
+		return nil, dbError("query movements", err)
 	}
 	defer rows.Close()
 
@@ -858,14 +970,15 @@ func (s *SQLiteStore) Movements() ([]Movement, error) {
 	for rows.Next() {
 		var m Movement
 		var ok sql.NullInt64
-		var failWhy, terr sql.NullString
+		var failWhy, failRaw, terr sql.NullString
 
-		if err := rows.Scan(&m.UnitID, &m.TurnNo, &m.ActSeq, &m.StepSeq, &m.Dir, &ok, &failWhy, &terr); err != nil {
-			return nil, fmt.Errorf("scan movement: %w", err)
+		if err := rows.Scan(&m.UnitID, &m.TurnNo, &m.ActSeq, &m.StepSeq, &m.Dir, &ok, &failWhy, &failRaw, &terr); err != nil {
+			return nil, dbError("scan movement", err)
 		}
 
 		m.Ok = ok.Valid && ok.Int64 == 1
 		m.FailWhy = failWhy.String
+		m.FailRaw = failRaw.String
 		m.Terr = terr.String
 		movements = append(movements, m)
 	}
@@ -873,7 +986,7 @@ func (s *SQLiteStore) Movements() ([]Movement, error) {
 }
 
 // MovementsByClan returns movement steps filtered by clan ID.
-func (s *SQLiteStore) MovementsByClan(clanID string, turnNo int) ([]Movement, error) {
+func (s *SQLiteStore) MovementsByClan(clanID string, turnNo model.TurnNo) ([]Movement, error) {
 	if len(clanID) < 3 {
 		return nil, nil
 	}
@@ -884,7 +997,7 @@ func (s *SQLiteStore) MovementsByClan(clanID string, turnNo int) ([]Movement, er
 
 	if turnNo > 0 {
 		const query = `
-			SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.terr
+			SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.fail_raw, st.terr
 			FROM steps st
 			JOIN acts a ON st.act_id = a.id
 			JOIN unit_extracts u ON a.unit_x_id = u.id
@@ -895,7 +1008,7 @@ func (s *SQLiteStore) MovementsByClan(clanID string, turnNo int) ([]Movement, er
 		rows, err = s.db.Query(query, clanSuffix, turnNo)
 	} else {
 		const query = `
-			SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.terr
+			SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.fail_raw, st.terr
 			FROM steps st
 			JOIN acts a ON st.act_id = a.id
 			JOIN unit_extracts u ON a.unit_x_id = u.id
@@ -906,7 +1019,7 @@ func (s *SQLiteStore) MovementsByClan(clanID string, turnNo int) ([]Movement, er
 		rows, err = s.db.Query(query, clanSuffix)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("query movements: %w", err)
+		return nil, dbError("query movements", err)
 	}
 	defer rows.Close()
 
@@ -914,14 +1027,15 @@ func (s *SQLiteStore) MovementsByClan(clanID string, turnNo int) ([]Movement, er
 	for rows.Next() {
 		var m Movement
 		var ok sql.NullInt64
-		var failWhy, terr sql.NullString
+		var failWhy, failRaw, terr sql.NullString
 
-		if err := rows.Scan(&m.UnitID, &m.TurnNo, &m.ActSeq, &m.StepSeq, &m.Dir, &ok, &failWhy, &terr); err != nil {
-			return nil, fmt.Errorf("scan movement: %w", err)
+		if err := rows.Scan(&m.UnitID, &m.TurnNo, &m.ActSeq, &m.StepSeq, &m.Dir, &ok, &failWhy, &failRaw, &terr); err != nil {
+			return nil, dbError("scan movement", err)
 		}
 
 		m.Ok = ok.Valid && ok.Int64 == 1
 		m.FailWhy = failWhy.String
+		m.FailRaw = failRaw.String
 		m.Terr = terr.String
 		movements = append(movements, m)
 	}
@@ -929,7 +1043,8 @@ func (s *SQLiteStore) MovementsByClan(clanID string, turnNo int) ([]Movement, er
 }
 
 // MovementsByGameClan returns movement steps filtered by game and clan number.
-func (s *SQLiteStore) MovementsByGameClan(gameID string, clanNo int, turnNo int) ([]Movement, error) {
+// Each step carries the tile it observed, if the walker placed it (see RebuildDerived).
+func (s *SQLiteStore) MovementsByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]Movement, error) {
 	clanStr := formatClanNo(clanNo)
 
 	var rows *sql.Rows
@@ -937,11 +1052,14 @@ func (s *SQLiteStore) MovementsByGameClan(gameID string, clanNo int, turnNo int)
 
 	if turnNo > 0 {
 		const query = `
-			SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.terr
+			SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.fail_raw, st.terr,
+			       t.grid, t.col, t.row
 			FROM steps st
 			JOIN acts a ON st.act_id = a.id
 			JOIN unit_extracts u ON a.unit_x_id = u.id
 			JOIN report_extracts r ON u.report_x_id = r.id
+			LEFT JOIN step_tiles stt ON stt.step_id = st.id
+			LEFT JOIN tiles t ON t.id = stt.tile_id
 			WHERE st.kind = 'adv' AND st.dir IS NOT NULL AND st.dir != ''
 			  AND r.game = ? AND u.clan_id = ? AND u.turn_no = ?
 			ORDER BY u.turn_no, u.unit_id, a.seq, st.seq
@@ -949,11 +1067,14 @@ func (s *SQLiteStore) MovementsByGameClan(gameID string, clanNo int, turnNo int)
 		rows, err = s.db.Query(query, gameID, clanStr, turnNo)
 	} else {
 		const query = `
-			SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.terr
+			SELECT u.unit_id, u.turn_no, a.seq, st.seq, st.dir, st.ok, st.fail_why, st.fail_raw, st.terr,
+			       t.grid, t.col, t.row
 			FROM steps st
 			JOIN acts a ON st.act_id = a.id
 			JOIN unit_extracts u ON a.unit_x_id = u.id
 			JOIN report_extracts r ON u.report_x_id = r.id
+			LEFT JOIN step_tiles stt ON stt.step_id = st.id
+			LEFT JOIN tiles t ON t.id = stt.tile_id
 			WHERE st.kind = 'adv' AND st.dir IS NOT NULL AND st.dir != ''
 			  AND r.game = ? AND u.clan_id = ?
 			ORDER BY u.turn_no, u.unit_id, a.seq, st.seq
@@ -961,7 +1082,7 @@ func (s *SQLiteStore) MovementsByGameClan(gameID string, clanNo int, turnNo int)
 		rows, err = s.db.Query(query, gameID, clanStr)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("query movements: %w", err)
+		return nil, dbError("query movements", err)
 	}
 	defer rows.Close()
 
@@ -969,15 +1090,20 @@ func (s *SQLiteStore) MovementsByGameClan(gameID string, clanNo int, turnNo int)
 	for rows.Next() {
 		var m Movement
 		var ok sql.NullInt64
-		var failWhy, terr sql.NullString
+		var failWhy, failRaw, terr, grid sql.NullString
+		var col, row sql.NullInt64
 
-		if err := rows.Scan(&m.UnitID, &m.TurnNo, &m.ActSeq, &m.StepSeq, &m.Dir, &ok, &failWhy, &terr); err != nil {
-			return nil, fmt.Errorf("scan movement: %w", err)
+		if err := rows.Scan(&m.UnitID, &m.TurnNo, &m.ActSeq, &m.StepSeq, &m.Dir, &ok, &failWhy, &failRaw, &terr, &grid, &col, &row); err != nil {
+			return nil, dbError("scan movement", err)
 		}
 
 		m.Ok = ok.Valid && ok.Int64 == 1
 		m.FailWhy = failWhy.String
+		m.FailRaw = failRaw.String
 		m.Terr = terr.String
+		if grid.Valid {
+			m.Tile = model.NewTNCoord(grid.String, int(col.Int64), int(row.Int64))
+		}
 		movements = append(movements, m)
 	}
 	return movements, rows.Err()
@@ -986,7 +1112,7 @@ func (s *SQLiteStore) MovementsByGameClan(gameID string, clanNo int, turnNo int)
 // Resource represents a resource sighting.
 type Resource struct {
 	UnitID  string
-	TurnNo  int
+	TurnNo  model.TurnNo
 	Kind    string
 	Qty     int
 	Terrain string
@@ -1005,7 +1131,7 @@ func (s *SQLiteStore) Resources() ([]Resource, error) {
 
 	rows, err := s.db.Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("query resources: %w", err)
+		return nil, dbError("query resources", err)
 	}
 	defer rows.Close()
 
@@ -1016,7 +1142,7 @@ func (s *SQLiteStore) Resources() ([]Resource, error) {
 		var terr sql.NullString
 
 		if err := rows.Scan(&r.UnitID, &r.TurnNo, &r.Kind, &qty, &terr); err != nil {
-			return nil, fmt.Errorf("scan resource: %w", err)
+			return nil, dbError("scan resource", err)
 		}
 
 		r.Qty = int(qty.Int64)
@@ -1027,7 +1153,7 @@ func (s *SQLiteStore) Resources() ([]Resource, error) {
 }
 
 // ResourcesByClan returns resources filtered by clan ID.
-func (s *SQLiteStore) ResourcesByClan(clanID string, turnNo int) ([]Resource, error) {
+func (s *SQLiteStore) ResourcesByClan(clanID string, turnNo model.TurnNo) ([]Resource, error) {
 	if len(clanID) < 3 {
 		return nil, nil
 	}
@@ -1060,7 +1186,7 @@ func (s *SQLiteStore) ResourcesByClan(clanID string, turnNo int) ([]Resource, er
 		rows, err = s.db.Query(query, clanSuffix)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("query resources: %w", err)
+		return nil, dbError("query resources", err)
 	}
 	defer rows.Close()
 
@@ -1071,7 +1197,7 @@ func (s *SQLiteStore) ResourcesByClan(clanID string, turnNo int) ([]Resource, er
 		var terr sql.NullString
 
 		if err := rows.Scan(&r.UnitID, &r.TurnNo, &r.Kind, &qty, &terr); err != nil {
-			return nil, fmt.Errorf("scan resource: %w", err)
+			return nil, dbError("scan resource", err)
 		}
 
 		r.Qty = int(qty.Int64)
@@ -1082,7 +1208,7 @@ func (s *SQLiteStore) ResourcesByClan(clanID string, turnNo int) ([]Resource, er
 }
 
 // ResourcesByGameClan returns resources filtered by game and clan number.
-func (s *SQLiteStore) ResourcesByGameClan(gameID string, clanNo int, turnNo int) ([]Resource, error) {
+func (s *SQLiteStore) ResourcesByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]Resource, error) {
 	clanStr := formatClanNo(clanNo)
 
 	var rows *sql.Rows
@@ -1114,7 +1240,45 @@ func (s *SQLiteStore) ResourcesByGameClan(gameID string, clanNo int, turnNo int)
 		rows, err = s.db.Query(query, gameID, clanStr)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("query resources: %w", err)
+		return nil, dbError("query resources", err)
+	}
+	defer rows.Close()
+
+	var resources []Resource
+	for rows.Next() {
+		var r Resource
+		var qty sql.NullInt64
+		var terr sql.NullString
+
+		if err := rows.Scan(&r.UnitID, &r.TurnNo, &r.Kind, &qty, &terr); err != nil {
+			return nil, dbError("scan resource", err)
+		}
+
+		r.Qty = int(qty.Int64)
+		r.Terrain = terr.String
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
+}
+
+// ResourcesByGameClanAsOf returns the resources a clan had found by the end of
+// turn asOf, excluding anything reported in later turns.
+func (s *SQLiteStore) ResourcesByGameClanAsOf(gameID string, clanNo int, asOf model.TurnNo) ([]Resource, error) {
+	clanStr := formatClanNo(clanNo)
+
+	const query = `
+		SELECT u.unit_id, u.turn_no, r.kind, r.qty, st.terr
+		FROM step_enc_rsrc r
+		JOIN steps st ON r.step_id = st.id
+		JOIN acts a ON st.act_id = a.id
+		JOIN unit_extracts u ON a.unit_x_id = u.id
+		JOIN report_extracts re ON u.report_x_id = re.id
+		WHERE re.game = ? AND u.clan_id = ? AND u.turn_no <= ?
+		ORDER BY r.kind, u.turn_no, u.unit_id
+	`
+	rows, err := s.db.Query(query, gameID, clanStr, asOf)
+	if err != nil {
+		return nil, dbError("query resources", err)
 	}
 	defer rows.Close()
 
@@ -1125,7 +1289,7 @@ func (s *SQLiteStore) ResourcesByGameClan(gameID string, clanNo int, turnNo int)
 		var terr sql.NullString
 
 		if err := rows.Scan(&r.UnitID, &r.TurnNo, &r.Kind, &qty, &terr); err != nil {
-			return nil, fmt.Errorf("scan resource: %w", err)
+			return nil, dbError("scan resource", err)
 		}
 
 		r.Qty = int(qty.Int64)
@@ -1137,11 +1301,13 @@ func (s *SQLiteStore) ResourcesByGameClan(gameID string, clanNo int, turnNo int)
 
 // TerrainObs represents an observed terrain.
 type TerrainObs struct {
-	UnitID  string
-	TurnNo  int
-	Terrain string
-	Special bool
-	Label   string
+	UnitID   string
+	UnitKind model.UnitKind
+	ClanID   string // observing clan, set by TerrainObservationsByGameClans
+	TurnNo   model.TurnNo
+	Terrain  string
+	Special  bool
+	Label    string
 }
 
 func (s *SQLiteStore) TerrainObservations() ([]TerrainObs, error) {
@@ -1157,7 +1323,7 @@ func (s *SQLiteStore) TerrainObservations() ([]TerrainObs, error) {
 
 	rows, err := s.db.Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("query terrain: %w", err)
+		return nil, dbError("query terrain", err)
 	}
 	defer rows.Close()
 
@@ -1168,18 +1334,19 @@ func (s *SQLiteStore) TerrainObservations() ([]TerrainObs, error) {
 		var label sql.NullString
 
 		if err := rows.Scan(&t.UnitID, &t.TurnNo, &t.Terrain, &special, &label); err != nil {
-			return nil, fmt.Errorf("scan terrain: %w", err)
+			return nil, dbError("scan terrain", err)
 		}
 
 		t.Special = special == 1
 		t.Label = label.String
+		t.UnitKind = model.UnitKindOf(t.UnitID)
 		obs = append(obs, t)
 	}
 	return obs, rows.Err()
 }
 
 // TerrainObservationsByClan returns terrain observations filtered by clan ID.
-func (s *SQLiteStore) TerrainObservationsByClan(clanID string, turnNo int) ([]TerrainObs, error) {
+func (s *SQLiteStore) TerrainObservationsByClan(clanID string, turnNo model.TurnNo) ([]TerrainObs, error) {
 	if len(clanID) < 3 {
 		return nil, nil
 	}
@@ -1212,7 +1379,7 @@ func (s *SQLiteStore) TerrainObservationsByClan(clanID string, turnNo int) ([]Te
 		rows, err = s.db.Query(query, clanSuffix)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("query terrain: %w", err)
+		return nil, dbError("query terrain", err)
 	}
 	defer rows.Close()
 
@@ -1223,18 +1390,19 @@ func (s *SQLiteStore) TerrainObservationsByClan(clanID string, turnNo int) ([]Te
 		var label sql.NullString
 
 		if err := rows.Scan(&t.UnitID, &t.TurnNo, &t.Terrain, &special, &label); err != nil {
-			return nil, fmt.Errorf("scan terrain: %w", err)
+			return nil, dbError("scan terrain", err)
 		}
 
 		t.Special = special == 1
 		t.Label = label.String
+		t.UnitKind = model.UnitKindOf(t.UnitID)
 		obs = append(obs, t)
 	}
 	return obs, rows.Err()
 }
 
 // TerrainObservationsByGameClan returns terrain observations filtered by game and clan number.
-func (s *SQLiteStore) TerrainObservationsByGameClan(gameID string, clanNo int, turnNo int) ([]TerrainObs, error) {
+func (s *SQLiteStore) TerrainObservationsByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]TerrainObs, error) {
 	clanStr := formatClanNo(clanNo)
 
 	var rows *sql.Rows
@@ -1266,7 +1434,7 @@ func (s *SQLiteStore) TerrainObservationsByGameClan(gameID string, clanNo int, t
 		rows, err = s.db.Query(query, gameID, clanStr)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("query terrain: %w", err)
+		return nil, dbError("query terrain", err)
 	}
 	defer rows.Close()
 
@@ -1277,11 +1445,67 @@ func (s *SQLiteStore) TerrainObservationsByGameClan(gameID string, clanNo int, t
 		var label sql.NullString
 
 		if err := rows.Scan(&t.UnitID, &t.TurnNo, &t.Terrain, &special, &label); err != nil {
-			return nil, fmt.Errorf("scan terrain: %w", err)
+			return nil, dbError("scan terrain", err)
 		}
 
 		t.Special = special == 1
 		t.Label = label.String
+		t.UnitKind = model.UnitKindOf(t.UnitID)
+		obs = append(obs, t)
+	}
+	return obs, rows.Err()
+}
+
+// TerrainObservationsByGameClans returns terrain observations made by any of the
+// given clans, tagged with the observing clan. Used for the combined alliance view.
+// If asOf is true, turnNo is a cutoff and observations from every turn up to and
+// including it are returned; otherwise only turnNo's observations are (all turns if 0).
+func (s *SQLiteStore) TerrainObservationsByGameClans(gameID string, clanNos []int, turnNo model.TurnNo, asOf bool) ([]TerrainObs, error) {
+	if len(clanNos) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(clanNos))
+	args := []any{gameID}
+	for i, clanNo := range clanNos {
+		placeholders[i] = "?"
+		args = append(args, formatClanNo(clanNo))
+	}
+	query := `
+		SELECT u.unit_id, u.clan_id, u.turn_no, st.terr, st.special, st.label
+		FROM steps st
+		JOIN acts a ON st.act_id = a.id
+		JOIN unit_extracts u ON a.unit_x_id = u.id
+		JOIN report_extracts r ON u.report_x_id = r.id
+		WHERE st.terr IS NOT NULL AND st.terr != ''
+		  AND r.game = ? AND u.clan_id IN (` + strings.Join(placeholders, ", ") + `)`
+	if turnNo > 0 && asOf {
+		query += ` AND u.turn_no <= ?`
+		args = append(args, turnNo)
+	} else if turnNo > 0 {
+		query += ` AND u.turn_no = ?`
+		args = append(args, turnNo)
+	}
+	query += ` ORDER BY st.terr, u.turn_no, u.clan_id, u.unit_id`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, dbError("query terrain", err)
+	}
+	defer rows.Close()
+
+	var obs []TerrainObs
+	for rows.Next() {
+		var t TerrainObs
+		var special int
+		var label sql.NullString
+
+		if err := rows.Scan(&t.UnitID, &t.ClanID, &t.TurnNo, &t.Terrain, &special, &label); err != nil {
+			return nil, dbError("scan terrain", err)
+		}
+
+		t.Special = special == 1
+		t.Label = label.String
+		t.UnitKind = model.UnitKindOf(t.UnitID)
 		obs = append(obs, t)
 	}
 	return obs, rows.Err()
@@ -1294,15 +1518,39 @@ type TileDetail struct {
 	Row       int
 	Coord     string
 	Sightings []TileSighting
+
+	// Terrain is the terrain the clan's sightings best support, and Alternates
+	// are the other terrains they reported, best first; see model.BestTerrain.
+	Terrain    model.TerrainChoice
+	Alternates []model.TerrainChoice
+
+	Neighbors []model.HexNeighbor
+	Steps     []TileStep   // steps the walker placed on the tile
+	Sources   []TileSource // the clan's steps in the merged tile's provenance
+}
+
+// TileStep is a step that observed a tile, linked through step_tiles.
+type TileStep struct {
+	UnitXID int64 // unit_extracts.id, for the unit detail page
+	UnitID  string
+	TurnNo  model.TurnNo
+	ActSeq  int
+	StepSeq int
+	Kind    model.StepKind
 }
 
 // TileSighting is a single observation of a tile.
 type TileSighting struct {
-	UnitID  string
-	TurnNo  int
-	Terrain string
-	Special bool
-	Label   string
+	UnitID   string
+	UnitKind model.UnitKind
+	TurnNo   model.TurnNo
+	Terrain  string
+	Special  bool
+	Label    string
+
+	ActKind    model.ActKind
+	StepKind   model.StepKind
+	Confidence int // 0..100; see model.TerrainSighting.Confidence
 }
 
 // TileDetailByCoord returns detailed tile information for a grid location.
@@ -1328,7 +1576,7 @@ func (s *SQLiteStore) TileDetailByCoord(grid string, col, row int, clanID string
 
 	rows, err := s.db.Query(query, clanSuffix, grid, col, row, grid, col, row)
 	if err != nil {
-		return nil, fmt.Errorf("query tile detail: %w", err)
+		return nil, dbError("query tile detail", err)
 	}
 	defer rows.Close()
 
@@ -1345,28 +1593,31 @@ func (s *SQLiteStore) TileDetailByCoord(grid string, col, row int, clanID string
 		var label sql.NullString
 
 		if err := rows.Scan(&s.UnitID, &s.TurnNo, &s.Terrain, &special, &label); err != nil {
-			return nil, fmt.Errorf("scan tile sighting: %w", err)
+			return nil, dbError("scan tile sighting", err)
 		}
 
 		s.Special = special == 1
 		s.Label = label.String
+		s.UnitKind = model.UnitKindOf(s.UnitID)
 		detail.Sightings = append(detail.Sightings, s)
 	}
 	return detail, rows.Err()
 }
 
 // TileDetailByGameClanCoord returns detailed tile information for a grid location, filtered by game and clan.
-func (s *SQLiteStore) TileDetailByGameClanCoord(grid string, col, row int, gameID string, clanNo int) (*TileDetail, error) {
+// If asOf is non-zero, sightings from turns after asOf are excluded.
+func (s *SQLiteStore) TileDetailByGameClanCoord(grid string, col, row int, gameID string, clanNo int, asOf model.TurnNo) (*TileDetail, error) {
 	clanStr := formatClanNo(clanNo)
 
 	const query = `
-		SELECT u.unit_id, u.turn_no, st.terr, st.special, st.label
+		SELECT u.unit_id, u.turn_no, a.kind, st.kind, st.terr, st.special, st.label
 		FROM steps st
 		JOIN acts a ON st.act_id = a.id
 		JOIN unit_extracts u ON a.unit_x_id = u.id
 		JOIN report_extracts r ON u.report_x_id = r.id
 		WHERE st.terr IS NOT NULL AND st.terr != ''
 		  AND r.game = ? AND u.clan_id = ?
+		  AND (? = 0 OR u.turn_no <= ?)
 		  AND (
 		      (u.end_grid = ? AND u.end_col = ? AND u.end_row = ?)
 		      OR (u.start_grid = ? AND u.start_col = ? AND u.start_row = ?)
@@ -1374,9 +1625,9 @@ func (s *SQLiteStore) TileDetailByGameClanCoord(grid string, col, row int, gameI
 		ORDER BY u.turn_no, u.unit_id
 	`
 
-	rows, err := s.db.Query(query, gameID, clanStr, grid, col, row, grid, col, row)
+	rows, err := s.db.Query(query, gameID, clanStr, asOf, asOf, grid, col, row, grid, col, row)
 	if err != nil {
-		return nil, fmt.Errorf("query tile detail: %w", err)
+		return nil, dbError("query tile detail", err)
 	}
 	defer rows.Close()
 
@@ -1392,15 +1643,63 @@ func (s *SQLiteStore) TileDetailByGameClanCoord(grid string, col, row int, gameI
 		var special int
 		var label sql.NullString
 
-		if err := rows.Scan(&sg.UnitID, &sg.TurnNo, &sg.Terrain, &special, &label); err != nil {
-			return nil, fmt.Errorf("scan tile sighting: %w", err)
+		if err := rows.Scan(&sg.UnitID, &sg.TurnNo, &sg.ActKind, &sg.StepKind, &sg.Terrain, &special, &label); err != nil {
+			return nil, dbError("scan tile sighting", err)
 		}
 
 		sg.Special = special == 1
 		sg.Label = label.String
+		sg.UnitKind = model.UnitKindOf(sg.UnitID)
 		detail.Sightings = append(detail.Sightings, sg)
 	}
-	return detail, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, dbError("iterate tile sightings", err)
+	}
+
+	var sightings []model.TerrainSighting
+	var newest model.TurnNo
+	for _, sg := range detail.Sightings {
+		sightings = append(sightings, model.TerrainSighting{Terr: sg.Terrain, TurnNo: sg.TurnNo, ActKind: sg.ActKind, StepKind: sg.StepKind})
+		newest = max(newest, sg.TurnNo)
+	}
+	for i := range detail.Sightings {
+		detail.Sightings[i].Confidence = sightings[i].Confidence(newest)
+	}
+	detail.Terrain, detail.Alternates = model.BestTerrain(sightings)
+	return detail, nil
+}
+
+// TileStepsByGameClanCoord returns the clan's steps that the walker placed on
+// a grid location, in turn order. If asOf is non-zero, steps from turns after
+// asOf are excluded. Tiles are built by RebuildDerived; until it runs, there are none.
+func (s *SQLiteStore) TileStepsByGameClanCoord(grid string, col, row int, gameID string, clanNo int, asOf model.TurnNo) ([]TileStep, error) {
+	const query = `
+		SELECT u.id, u.unit_id, u.turn_no, a.seq, st.seq, st.kind
+		FROM tiles t
+		JOIN step_tiles stt ON stt.tile_id = t.id
+		JOIN steps st ON st.id = stt.step_id
+		JOIN acts a ON st.act_id = a.id
+		JOIN unit_extracts u ON a.unit_x_id = u.id
+		WHERE t.game = ? AND t.grid = ? AND t.col = ? AND t.row = ?
+		  AND u.clan_id = ?
+		  AND (? = 0 OR u.turn_no <= ?)
+		ORDER BY u.turn_no, u.unit_id, a.seq, st.seq
+	`
+	rows, err := s.db.Query(query, gameID, grid, col, row, formatClanNo(clanNo), asOf, asOf)
+	if err != nil {
+		return nil, dbError("query tile steps", err)
+	}
+	defer rows.Close()
+
+	var steps []TileStep
+	for rows.Next() {
+		var ts TileStep
+		if err := rows.Scan(&ts.UnitXID, &ts.UnitID, &ts.TurnNo, &ts.ActSeq, &ts.StepSeq, &ts.Kind); err != nil {
+			return nil, dbError("scan tile step", err)
+		}
+		steps = append(steps, ts)
+	}
+	return steps, rows.Err()
 }
 
 // Stats returns basic statistics about the store.
@@ -1417,21 +1716,21 @@ func (s *SQLiteStore) Stats() model.Stats {
 }
 
 // Turns returns distinct turn numbers in the store.
-func (s *SQLiteStore) Turns() ([]int, error) {
+func (s *SQLiteStore) Turns() ([]model.TurnNo, error) {
 
 	const query = `SELECT DISTINCT turn_no FROM unit_extracts ORDER BY turn_no`
 
 	rows, err := s.db.Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("query turns: %w", err)
+		return nil, dbError("query turns", err)
 	}
 	defer rows.Close()
 
-	var turns []int
+	var turns []model.TurnNo
 	for rows.Next() {
-		var t int
+		var t model.TurnNo
 		if err := rows.Scan(&t); err != nil {
-			return nil, fmt.Errorf("scan turn: %w", err)
+			return nil, dbError("scan turn", err)
 		}
 		turns = append(turns, t)
 	}
@@ -1439,7 +1738,7 @@ func (s *SQLiteStore) Turns() ([]int, error) {
 }
 
 // TurnsByClan returns distinct turn numbers filtered by clan ID.
-func (s *SQLiteStore) TurnsByClan(clanID string) ([]int, error) {
+func (s *SQLiteStore) TurnsByClan(clanID string) ([]model.TurnNo, error) {
 	if len(clanID) < 3 {
 		return nil, nil
 	}
@@ -1449,15 +1748,15 @@ func (s *SQLiteStore) TurnsByClan(clanID string) ([]int, error) {
 
 	rows, err := s.db.Query(query, clanSuffix)
 	if err != nil {
-		return nil, fmt.Errorf("query turns: %w", err)
+		return nil, dbError("query turns", err)
 	}
 	defer rows.Close()
 
-	var turns []int
+	var turns []model.TurnNo
 	for rows.Next() {
-		var t int
+		var t model.TurnNo
 		if err := rows.Scan(&t); err != nil {
-			return nil, fmt.Errorf("scan turn: %w", err)
+			return nil, dbError("scan turn", err)
 		}
 		turns = append(turns, t)
 	}
@@ -1473,27 +1772,6 @@ func formatClanNo(clanNo int) string {
 	return fmt.Sprintf("%d", clanNo)
 }
 
-func parseTNCoord(tn model.TNCoord) (grid string, col, row int) {
-	str := string(tn)
-	if str == "" || str == "N/A" {
-		return "", 0, 0
-	}
-	if len(str) >= 7 && str[2] == ' ' {
-		grid = str[:2]
-		if len(str) >= 7 {
-			fmt.Sscanf(str[3:], "%02d%02d", &col, &row)
-		}
-	}
-	return grid, col, row
-}
-
-func formatTNCoord(grid string, col, row int) model.TNCoord {
-	if grid == "" {
-		return ""
-	}
-	return model.TNCoord(fmt.Sprintf("%s %02d%02d", grid, col, row))
-}
-
 func boolToInt(b bool) int64 {
 	if b {
 		return 1
@@ -1527,7 +1805,7 @@ func (s *SQLiteStore) ValidateCredentials(ctx context.Context, handle, password,
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("query user: %w", err)
+		return nil, dbError("query user", err)
 	}
 
 	// Check if user is active
@@ -1544,25 +1822,29 @@ func (s *SQLiteStore) ValidateCredentials(ctx context.Context, handle, password,
 		return nil, nil
 	}
 
-	// Get clan for this game
-	clanNo, err := s.getClanForUser(ctx, gameID, handle)
+	// Get clans for this game
+	clans, err := s.ClansForUser(ctx, gameID, handle)
 	if err != nil {
 		return nil, err
 	}
 
-	return &auth.User{
+	user := &auth.User{
 		Handle:   dbHandle,
 		UserName: userName,
 		GameID:   gameID,
-		ClanNo:   clanNo,
-	}, nil
+		Clans:    clans,
+	}
+	if len(clans) != 0 {
+		user.ClanNo = clans[0]
+	}
+	return user, nil
 }
 
 func (s *SQLiteStore) isUserActive(ctx context.Context, handle string) (bool, error) {
 	const query = `SELECT role FROM user_roles WHERE user_handle = ?`
 	rows, err := s.db.QueryContext(ctx, query, handle)
 	if err != nil {
-		return false, fmt.Errorf("query roles: %w", err)
+		return false, dbError("query roles", err)
 	}
 	defer rows.Close()
 
@@ -1587,29 +1869,84 @@ func (s *SQLiteStore) IsUserGM(ctx context.Context, handle string) (bool, error)
 		return false, nil
 	}
 	if err != nil {
-		return false, fmt.Errorf("check gm role: %w", err)
+		return false, dbError("check gm role", err)
 	}
 	return true, nil
 }
 
+// ValidTheme returns true if theme is "light", "dark", or empty (follow the browser).
+func ValidTheme(theme string) bool {
+	return theme == "" || theme == "light" || theme == "dark"
+}
+
+// GetUserTheme returns the user's preferred theme, or an empty string if none is set.
+func (s *SQLiteStore) GetUserTheme(ctx context.Context, handle string) (string, error) {
+	const query = `SELECT theme FROM user_prefs WHERE user_handle = ?`
+	var theme string
+	err := s.db.QueryRowContext(ctx, query, handle).Scan(&theme)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", dbError("query theme", err)
+	}
+	return theme, nil
+}
+
+// SetUserTheme saves the user's preferred theme.
+func (s *SQLiteStore) SetUserTheme(ctx context.Context, handle, theme string) error {
+	if !ValidTheme(theme) {
+		return cerrs.New(cerrs.CodeInvalidInput, fmt.Sprintf("theme %q: must be light or dark", theme))
+	}
+	const query = `INSERT INTO user_prefs (user_handle, theme, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(user_handle) DO UPDATE SET theme = excluded.theme, updated_at = excluded.updated_at`
+	if _, err := s.db.ExecContext(ctx, query, handle, theme, s.now().Format(time.RFC3339)); err != nil {
+		return dbError("save theme", err)
+	}
+	return nil
+}
+
+// getClanForUser returns the user's lowest-numbered clan in the game, or 0 if
+// they have none.
 func (s *SQLiteStore) getClanForUser(ctx context.Context, gameID, handle string) (int, error) {
-	const query = `SELECT clan_no FROM game_clans WHERE game_id = ? AND user_handle = ?`
+	const query = `SELECT clan_no FROM game_clans WHERE game_id = ? AND user_handle = ? ORDER BY clan_no LIMIT 1`
 	var clanNo int
 	err := s.db.QueryRowContext(ctx, query, gameID, handle).Scan(&clanNo)
 	if err == sql.ErrNoRows {
 		return 0, nil
 	}
 	if err != nil {
-		return 0, fmt.Errorf("query clan: %w", err)
+		return 0, dbError("query clan", err)
 	}
 	return clanNo, nil
 }
 
 // GetClanForUser returns the clan number for a user in a specific game (exported version).
+// A user with more than one clan in the game gets the lowest-numbered one.
 func (s *SQLiteStore) GetClanForUser(ctx context.Context, gameID, handle string) (int, error) {
 	return s.getClanForUser(ctx, gameID, handle)
 }
 
+// ClansForUser returns every clan a user runs in a game, in clan order.
+func (s *SQLiteStore) ClansForUser(ctx context.Context, gameID, handle string) ([]int, error) {
+	const query = `SELECT clan_no FROM game_clans WHERE game_id = ? AND user_handle = ? ORDER BY clan_no`
+	rows, err := s.db.QueryContext(ctx, query, gameID, handle)
+	if err != nil {
+		return nil, dbError("query clans", err)
+	}
+	defer rows.Close()
+
+	var clans []int
+	for rows.Next() {
+		var clanNo int
+		if err := rows.Scan(&clanNo); err != nil {
+			return nil, dbError("scan clan", err)
+		}
+		clans = append(clans, clanNo)
+	}
+	return clans, rows.Err()
+}
+
 // GetHandleForClan returns the user handle for a clan in a specific game.
 func (s *SQLiteStore) GetHandleForClan(ctx context.Context, gameID string, clanNo int) (string, error) {
 	const query = `SELECT user_handle FROM game_clans WHERE game_id = ? AND clan_no = ?`
@@ -1619,13 +1956,13 @@ func (s *SQLiteStore) GetHandleForClan(ctx context.Context, gameID string, clanN
 		return "", nil
 	}
 	if err != nil {
-		return "", fmt.Errorf("query handle: %w", err)
+		return "", dbError("query handle", err)
 	}
 	return handle, nil
 }
 
 // TurnsByGameClan returns distinct turn numbers filtered by game and clan.
-func (s *SQLiteStore) TurnsByGameClan(gameID string, clanNo int) ([]int, error) {
+func (s *SQLiteStore) TurnsByGameClan(gameID string, clanNo int) ([]model.TurnNo, error) {
 	clanStr := fmt.Sprintf("%d", clanNo)
 	if clanNo < 100 {
 		clanStr = fmt.Sprintf("%03d", clanNo)
@@ -1641,24 +1978,44 @@ func (s *SQLiteStore) TurnsByGameClan(gameID string, clanNo int) ([]int, error)
 
 	rows, err := s.db.Query(query, gameID, clanStr)
 	if err != nil {
-		return nil, fmt.Errorf("query turns: %w", err)
+		return nil, dbError("query turns", err)
 	}
 	defer rows.Close()
 
-	var turns []int
+	var turns []model.TurnNo
 	for rows.Next() {
-		var t int
+		var t model.TurnNo
 		if err := rows.Scan(&t); err != nil {
-			return nil, fmt.Errorf("scan turn: %w", err)
+			return nil, dbError("scan turn", err)
 		}
 		turns = append(turns, t)
 	}
 	return turns, rows.Err()
 }
 
+// TurnConditions returns the season and weather reported for a turn in a game.
+// Both are empty if no report for the turn recorded them.
+func (s *SQLiteStore) TurnConditions(gameID string, turnNo model.TurnNo) (season, weather string, err error) {
+	const query = `
+		SELECT season, weather
+		FROM report_extracts
+		WHERE game = ? AND turn_no = ? AND season IS NOT NULL
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	var ns, nw sql.NullString
+	err = s.db.QueryRow(query, gameID, turnNo).Scan(&ns, &nw)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", dbError("query turn conditions", err)
+	}
+	return ns.String, nw.String, nil
+}
+
 // LoadUsersFromJSON loads users from a JSON file.
 func (s *SQLiteStore) LoadUsersFromJSON(ctx context.Context, path string) error {
-	return loadUsersFromJSON(ctx, s.db, path)
+	return loadUsersFromJSON(ctx, s.db, path, s.now())
 }
 
 // LoadGamesFromJSON loads games from a JSON file.
@@ -1667,6 +2024,7 @@ func (s *SQLiteStore) LoadGamesFromJSON(ctx context.Context, path string) error
 }
 
 // UserGame represents a game the user belongs to with their clan number.
+// A user who runs several clans in a game has one UserGame for each.
 type UserGame struct {
 	GameID      string
 	Description string
@@ -1729,19 +2087,19 @@ func (s *SQLiteStore) ExecRawQuery(ctx context.Context, query string) *QueryResu
 	return result
 }
 
-// GetGamesForUser returns all games a user belongs to, sorted by game ID.
+// GetGamesForUser returns all games a user belongs to, one per clan, sorted by game ID and clan.
 func (s *SQLiteStore) GetGamesForUser(ctx context.Context, handle string) ([]UserGame, error) {
 	const query = `
 		SELECT g.id, g.description, gc.clan_no
 		FROM games g
 		JOIN game_clans gc ON g.id = gc.game_id
 		WHERE gc.user_handle = ?
-		ORDER BY g.id
+		ORDER BY g.id, gc.clan_no
 	`
 
 	rows, err := s.db.QueryContext(ctx, query, handle)
 	if err != nil {
-		return nil, fmt.Errorf("query games: %w", err)
+		return nil, dbError("query games", err)
 	}
 	defer rows.Close()
 
@@ -1750,7 +2108,7 @@ func (s *SQLiteStore) GetGamesForUser(ctx context.Context, handle string) ([]Use
 		var g UserGame
 		var desc sql.NullString
 		if err := rows.Scan(&g.GameID, &desc, &g.ClanNo); err != nil {
-			return nil, fmt.Errorf("scan game: %w", err)
+			return nil, dbError("scan game", err)
 		}
 		g.Description = desc.String
 		games = append(games, g)
@@ -5,7 +5,7 @@ package store
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"sort"
 	"time"
 
 	"github.com/mdhender/tnrpt/model"
@@ -25,7 +25,7 @@ func (s *SQLiteStore) InsertUploadBatch(ctx context.Context, batch *model.Upload
 		batch.CreatedAt.Format(time.RFC3339),
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert upload_batch: %w", err)
+		return 0, dbError("insert upload_batch", err)
 	}
 	return result.LastInsertId()
 }
@@ -49,7 +49,7 @@ func (s *SQLiteStore) GetUploadBatch(ctx context.Context, id int64) (*model.Uplo
 		&createdBy,
 		&createdAt,
 	); err != nil {
-		return nil, fmt.Errorf("get upload_batch: %w", err)
+		return nil, dbError("get upload_batch", err)
 	}
 	if createdBy.Valid {
 		batch.CreatedBy = createdBy.String
@@ -63,8 +63,8 @@ func (s *SQLiteStore) GetUploadBatch(ctx context.Context, id int64) (*model.Uplo
 // InsertWork inserts a Work job and returns its assigned ID.
 func (s *SQLiteStore) InsertWork(ctx context.Context, work *model.Work) (int64, error) {
 	const query = `
-		INSERT INTO work (report_file_id, stage, status, attempt, available_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO work (report_file_id, stage, status, attempt, available_at, queued_at)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 	result, err := s.db.ExecContext(ctx, query,
 		work.ReportFileID,
@@ -72,16 +72,18 @@ func (s *SQLiteStore) InsertWork(ctx context.Context, work *model.Work) (int64,
 		work.Status,
 		work.Attempt,
 		work.AvailableAt.Format(time.RFC3339),
+		s.now().Format(time.RFC3339),
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert work: %w", err)
+		return 0, dbError("insert work", err)
 	}
 	return result.LastInsertId()
 }
 
-// ClaimWork atomically claims a queued job for a stage, returning nil if none available.
+// ClaimWork atomically claims a queued job for a stage, returning nil if none
+// available or the stage is paused (see PauseWork).
 func (s *SQLiteStore) ClaimWork(ctx context.Context, stage, workerID string) (*model.Work, error) {
-	now := time.Now().UTC()
+	now := s.now()
 	nowStr := now.Format(time.RFC3339)
 
 	const query = `
@@ -96,6 +98,7 @@ func (s *SQLiteStore) ClaimWork(ctx context.Context, stage, workerID string) (*m
 			WHERE stage = ?
 			  AND status = 'queued'
 			  AND available_at <= ?
+			  AND NOT EXISTS (SELECT 1 FROM work_pauses WHERE work_pauses.stage IN ('', work.stage))
 			ORDER BY available_at
 			LIMIT 1
 		)
@@ -109,7 +112,7 @@ func (s *SQLiteStore) ClaimWork(ctx context.Context, stage, workerID string) (*m
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("claim work: %w", err)
+		return nil, dbError("claim work", err)
 	}
 	return work, nil
 }
@@ -128,23 +131,47 @@ func (s *SQLiteStore) FinishWork(ctx context.Context, id int64, status, errorCod
 	`
 	_, err := s.db.ExecContext(ctx, query,
 		status,
-		time.Now().UTC().Format(time.RFC3339),
+		s.now().Format(time.RFC3339),
 		nullString(errorCode),
 		nullString(errorMsg),
 		id,
 	)
 	if err != nil {
-		return fmt.Errorf("finish work: %w", err)
+		return dbError("finish work", err)
 	}
 	return nil
 }
 
+// ReleaseWork puts the jobs a worker still has claimed back in the queue, for
+// a worker that stopped before finishing them. It returns how many were released.
+func (s *SQLiteStore) ReleaseWork(ctx context.Context, workerID string) (int, error) {
+	const query = `
+		UPDATE work
+		SET status = 'queued',
+		    available_at = ?,
+		    locked_by = NULL,
+		    locked_at = NULL
+		WHERE status = 'running'
+		  AND locked_by = ?
+	`
+	result, err := s.db.ExecContext(ctx, query, s.now().Format(time.RFC3339), workerID)
+	if err != nil {
+		return 0, dbError("release work", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, dbError("rows affected", err)
+	}
+	return int(n), nil
+}
+
 // ResetFailedWork resets failed jobs for a stage back to queued, returning count reset.
 func (s *SQLiteStore) ResetFailedWork(ctx context.Context, stage string) (int, error) {
 	const query = `
 		UPDATE work
 		SET status = 'queued',
 		    available_at = ?,
+		    queued_at = ?,
 		    locked_by = NULL,
 		    locked_at = NULL,
 		    finished_at = NULL,
@@ -153,13 +180,14 @@ func (s *SQLiteStore) ResetFailedWork(ctx context.Context, stage string) (int, e
 		WHERE stage = ?
 		  AND status = 'failed'
 	`
-	result, err := s.db.ExecContext(ctx, query, time.Now().UTC().Format(time.RFC3339), stage)
+	now := s.now().Format(time.RFC3339)
+	result, err := s.db.ExecContext(ctx, query, now, now, stage)
 	if err != nil {
-		return 0, fmt.Errorf("reset failed work: %w", err)
+		return 0, dbError("reset failed work", err)
 	}
 	n, err := result.RowsAffected()
 	if err != nil {
-		return 0, fmt.Errorf("rows affected: %w", err)
+		return 0, dbError("rows affected", err)
 	}
 	return int(n), nil
 }
@@ -176,7 +204,35 @@ func (s *SQLiteStore) GetFailedWork(ctx context.Context, stage string) ([]model.
 	`
 	rows, err := s.db.QueryContext(ctx, query, stage)
 	if err != nil {
-		return nil, fmt.Errorf("get failed work: %w", err)
+		return nil, dbError("get failed work", err)
+	}
+	defer rows.Close()
+
+	var works []model.Work
+	for rows.Next() {
+		work, err := scanWorkRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		works = append(works, *work)
+	}
+	return works, rows.Err()
+}
+
+// GetWorkByBatch returns all jobs for the report files in a batch,
+// ordered by report file and then by job.
+func (s *SQLiteStore) GetWorkByBatch(ctx context.Context, batchID int64) ([]model.Work, error) {
+	const query = `
+		SELECT w.id, w.report_file_id, w.stage, w.status, w.attempt, w.available_at,
+		       w.locked_by, w.locked_at, w.started_at, w.finished_at, w.error_code, w.error_message
+		FROM work w
+		JOIN report_files rf ON w.report_file_id = rf.id
+		WHERE rf.batch_id = ?
+		ORDER BY w.report_file_id, w.id
+	`
+	rows, err := s.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, dbError("get work by batch", err)
 	}
 	defer rows.Close()
 
@@ -203,7 +259,7 @@ func (s *SQLiteStore) GetWorkSummaryByBatch(ctx context.Context, batchID int64)
 	`
 	rows, err := s.db.QueryContext(ctx, query, batchID)
 	if err != nil {
-		return nil, fmt.Errorf("get work summary: %w", err)
+		return nil, dbError("get work summary", err)
 	}
 	defer rows.Close()
 
@@ -212,7 +268,7 @@ func (s *SQLiteStore) GetWorkSummaryByBatch(ctx context.Context, batchID int64)
 		var stage, status string
 		var cnt int
 		if err := rows.Scan(&stage, &status, &cnt); err != nil {
-			return nil, fmt.Errorf("scan work summary: %w", err)
+			return nil, dbError("scan work summary", err)
 		}
 		if result[stage] == nil {
 			result[stage] = make(map[string]int)
@@ -222,6 +278,200 @@ func (s *SQLiteStore) GetWorkSummaryByBatch(ctx context.Context, batchID int64)
 	return result, rows.Err()
 }
 
+// ListUploadBatches returns the most recent limit upload batches, newest
+// first, with the number of report files in each and of their jobs by status.
+func (s *SQLiteStore) ListUploadBatches(ctx context.Context, limit int) ([]model.BatchStatus, error) {
+	const query = `
+		SELECT b.id, b.game, b.clan_no, b.turn_no, b.created_by, b.created_at,
+		       (SELECT COUNT(*) FROM report_files rf WHERE rf.batch_id = b.id),
+		       COUNT(CASE WHEN w.status = 'queued' THEN 1 END),
+		       COUNT(CASE WHEN w.status = 'running' THEN 1 END),
+		       COUNT(CASE WHEN w.status = 'ok' THEN 1 END),
+		       COUNT(CASE WHEN w.status = 'failed' THEN 1 END)
+		FROM upload_batches b
+		LEFT JOIN report_files rf ON rf.batch_id = b.id
+		LEFT JOIN work w ON w.report_file_id = rf.id
+		GROUP BY b.id
+		ORDER BY b.id DESC
+		LIMIT ?
+	`
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, dbError("list upload batches", err)
+	}
+	defer rows.Close()
+
+	var batches []model.BatchStatus
+	for rows.Next() {
+		var bs model.BatchStatus
+		var createdBy sql.NullString
+		var createdAt string
+		if err := rows.Scan(&bs.ID, &bs.Game, &bs.ClanNo, &bs.TurnNo, &createdBy, &createdAt,
+			&bs.Files, &bs.Queued, &bs.Running, &bs.Ok, &bs.Failed); err != nil {
+			return nil, dbError("scan upload batch", err)
+		}
+		bs.CreatedBy = createdBy.String
+		bs.CreatedAt = parseTime(createdAt)
+		batches = append(batches, bs)
+	}
+	return batches, rows.Err()
+}
+
+// GetReportFilesByBatch returns the report files uploaded in a batch, in upload order.
+func (s *SQLiteStore) GetReportFilesByBatch(ctx context.Context, batchID int64) ([]*model.ReportFile, error) {
+	const query = `
+		SELECT id, game, clan_no, turn_no, name, sha256, mime, created_at, fs_path, batch_id
+		FROM report_files
+		WHERE batch_id = ?
+		ORDER BY id
+	`
+	rows, err := s.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, dbError("get report_files by batch", err)
+	}
+	defer rows.Close()
+	return scanReportFiles(rows)
+}
+
+// ResetFailedWorkByBatch is ResetFailedWork for the jobs of one batch. An
+// empty stage resets failed jobs in every stage.
+func (s *SQLiteStore) ResetFailedWorkByBatch(ctx context.Context, batchID int64, stage string) (int, error) {
+	const query = `
+		UPDATE work
+		SET status = 'queued',
+		    available_at = ?,
+		    queued_at = ?,
+		    locked_by = NULL,
+		    locked_at = NULL,
+		    finished_at = NULL,
+		    error_code = NULL,
+		    error_message = NULL
+		WHERE (? = '' OR stage = ?)
+		  AND status = 'failed'
+		  AND report_file_id IN (SELECT id FROM report_files WHERE batch_id = ?)
+	`
+	now := s.now().Format(time.RFC3339)
+	result, err := s.db.ExecContext(ctx, query, now, now, stage, stage, batchID)
+	if err != nil {
+		return 0, dbError("reset failed work", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, dbError("rows affected", err)
+	}
+	return int(n), nil
+}
+
+// GetWorkMetrics returns throughput and latency metrics per stage for jobs
+// finished since the given time, along with the current backlog.
+// Stages with no finished or queued jobs are still reported.
+func (s *SQLiteStore) GetWorkMetrics(ctx context.Context, since time.Time) ([]model.StageMetrics, error) {
+	now := s.now()
+	window := now.Sub(since)
+
+	metrics := map[string]*model.StageMetrics{}
+	getStage := func(stage string) *model.StageMetrics {
+		m, ok := metrics[stage]
+		if !ok {
+			m = &model.StageMetrics{Stage: stage, FailuresByCode: map[string]int{}}
+			metrics[stage] = m
+		}
+		return m
+	}
+	getStage(model.WorkStageExtract)
+	getStage(model.WorkStageParse)
+	getStage(model.WorkStageRender)
+
+	const finishedQuery = `
+		SELECT stage, status, started_at, finished_at, error_code
+		FROM work
+		WHERE status IN ('ok', 'failed')
+		  AND finished_at >= ?
+	`
+	rows, err := s.db.QueryContext(ctx, finishedQuery, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, dbError("get work metrics", err)
+	}
+	defer rows.Close()
+
+	durations := map[string][]time.Duration{}
+	for rows.Next() {
+		var stage, status string
+		var startedAt, finishedAt, errorCode sql.NullString
+		if err := rows.Scan(&stage, &status, &startedAt, &finishedAt, &errorCode); err != nil {
+			return nil, dbError("scan work metrics", err)
+		}
+		m := getStage(stage)
+		m.Finished++
+		if status == model.WorkStatusFailed {
+			m.Failed++
+			code := errorCode.String
+			if code == "" {
+				code = "UNKNOWN"
+			}
+			m.FailuresByCode[code]++
+		}
+		started, finished := parseTimePtr(startedAt), parseTimePtr(finishedAt)
+		if started != nil && finished != nil && !finished.Before(*started) {
+			durations[stage] = append(durations[stage], finished.Sub(*started))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("get work metrics", err)
+	}
+
+	// backlog age runs from when the oldest job was queued; available_at
+	// can lie in the future for jobs that are waiting out a retry delay
+	const backlogQuery = `
+		SELECT stage, COUNT(*), MIN(COALESCE(queued_at, available_at))
+		FROM work
+		WHERE status = 'queued'
+		GROUP BY stage
+	`
+	backlog, err := s.db.QueryContext(ctx, backlogQuery)
+	if err != nil {
+		return nil, dbError("get work backlog", err)
+	}
+	defer backlog.Close()
+	for backlog.Next() {
+		var stage string
+		var queued int
+		var oldest sql.NullString
+		if err := backlog.Scan(&stage, &queued, &oldest); err != nil {
+			return nil, dbError("scan work backlog", err)
+		}
+		m := getStage(stage)
+		m.Queued = queued
+		if t := parseTimePtr(oldest); t != nil && t.Before(now) {
+			m.BacklogAge = now.Sub(*t)
+		}
+	}
+	if err := backlog.Err(); err != nil {
+		return nil, dbError("get work backlog", err)
+	}
+
+	var result []model.StageMetrics
+	for stage, m := range metrics {
+		if window > 0 {
+			m.JobsPerHour = float64(m.Finished) / window.Hours()
+		}
+		if d := durations[stage]; len(d) > 0 {
+			sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+			var total time.Duration
+			for _, v := range d {
+				total += v
+			}
+			m.AvgDuration = total / time.Duration(len(d))
+			m.P95Duration = d[(len(d)*95+99)/100-1]
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Stage < result[j].Stage
+	})
+	return result, nil
+}
+
 // scanWork scans a Work from a sql.Row.
 func scanWork(row *sql.Row) (*model.Work, error) {
 	var w model.Work
@@ -314,7 +564,7 @@ func (s *SQLiteStore) GetReportFileByID(ctx context.Context, id int64) (*model.R
 	); err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
-		return nil, fmt.Errorf("get report_file by id: %w", err)
+		return nil, dbError("get report_file by id", err)
 	}
 	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 		rf.CreatedAt = t
@@ -353,7 +603,7 @@ func (s *SQLiteStore) GetReportFileBySHA256(ctx context.Context, sha256 string)
 	); err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
-		return nil, fmt.Errorf("get report_file by sha256: %w", err)
+		return nil, dbError("get report_file by sha256", err)
 	}
 	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 		rf.CreatedAt = t
@@ -365,6 +615,131 @@ func (s *SQLiteStore) GetReportFileBySHA256(ctx context.Context, sha256 string)
 	return &rf, nil
 }
 
+// GetReportFilesWithPath returns all report files that have a stored copy under the data directory.
+func (s *SQLiteStore) GetReportFilesWithPath(ctx context.Context) ([]*model.ReportFile, error) {
+	const query = `
+		SELECT id, game, clan_no, turn_no, name, sha256, mime, created_at, fs_path, batch_id
+		FROM report_files
+		WHERE fs_path IS NOT NULL
+		  AND fs_path != ''
+		ORDER BY id
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("get report_files with path", err)
+	}
+	defer rows.Close()
+	return scanReportFiles(rows)
+}
+
+// GetReportFilesWithoutPath returns all report files that have no stored copy under the data directory.
+func (s *SQLiteStore) GetReportFilesWithoutPath(ctx context.Context) ([]*model.ReportFile, error) {
+	const query = `
+		SELECT id, game, clan_no, turn_no, name, sha256, mime, created_at, fs_path, batch_id
+		FROM report_files
+		WHERE fs_path IS NULL
+		   OR fs_path = ''
+		ORDER BY id
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("get report_files without path", err)
+	}
+	defer rows.Close()
+	return scanReportFiles(rows)
+}
+
+// GetReportFilesByGameTurn returns all report files uploaded for a game and turn, in clan order.
+func (s *SQLiteStore) GetReportFilesByGameTurn(ctx context.Context, game string, turnNo model.TurnNo) ([]*model.ReportFile, error) {
+	const query = `
+		SELECT id, game, clan_no, turn_no, name, sha256, mime, created_at, fs_path, batch_id
+		FROM report_files
+		WHERE game = ? AND turn_no = ?
+		ORDER BY clan_no, id
+	`
+	rows, err := s.db.QueryContext(ctx, query, game, turnNo)
+	if err != nil {
+		return nil, dbError("get report_files by game turn", err)
+	}
+	defer rows.Close()
+	return scanReportFiles(rows)
+}
+
+// GetReportFilesByGameClan returns all report files uploaded for a clan in a game, newest turn first.
+func (s *SQLiteStore) GetReportFilesByGameClan(ctx context.Context, game, clanNo string) ([]*model.ReportFile, error) {
+	const query = `
+		SELECT id, game, clan_no, turn_no, name, sha256, mime, created_at, fs_path, batch_id
+		FROM report_files
+		WHERE game = ? AND clan_no = ?
+		ORDER BY turn_no DESC, id
+	`
+	rows, err := s.db.QueryContext(ctx, query, game, clanNo)
+	if err != nil {
+		return nil, dbError("get report_files by game clan", err)
+	}
+	defer rows.Close()
+	return scanReportFiles(rows)
+}
+
+func scanReportFiles(rows *sql.Rows) ([]*model.ReportFile, error) {
+	var rfs []*model.ReportFile
+	for rows.Next() {
+		var rf model.ReportFile
+		var createdAt string
+		var fsPath sql.NullString
+		var batchID sql.NullInt64
+		if err := rows.Scan(
+			&rf.ID,
+			&rf.Game,
+			&rf.ClanNo,
+			&rf.TurnNo,
+			&rf.Name,
+			&rf.SHA256,
+			&rf.Mime,
+			&createdAt,
+			&fsPath,
+			&batchID,
+		); err != nil {
+			return nil, dbError("scan report_file", err)
+		}
+		if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+			rf.CreatedAt = t
+		}
+		rf.FsPath = fsPath.String
+		if batchID.Valid {
+			rf.BatchID = &batchID.Int64
+		}
+		rfs = append(rfs, &rf)
+	}
+	return rfs, rows.Err()
+}
+
+// UpdateReportFileSHA256 replaces the content hash recorded for a report file.
+func (s *SQLiteStore) UpdateReportFileSHA256(ctx context.Context, id int64, sha256 string) error {
+	const query = `
+		UPDATE report_files
+		SET sha256 = ?
+		WHERE id = ?
+	`
+	if _, err := s.db.ExecContext(ctx, query, sha256, id); err != nil {
+		return dbError("update report_file sha256", err)
+	}
+	return nil
+}
+
+// UpdateReportFilePath points a report file at a new location under the data directory.
+func (s *SQLiteStore) UpdateReportFilePath(ctx context.Context, id int64, fsPath string) error {
+	const query = `
+		UPDATE report_files
+		SET fs_path = ?
+		WHERE id = ?
+	`
+	if _, err := s.db.ExecContext(ctx, query, fsPath, id); err != nil {
+		return dbError("update report_file fs_path", err)
+	}
+	return nil
+}
+
 // InsertReportFileWithBatch inserts a report_files row including fs_path and batch_id.
 func (s *SQLiteStore) InsertReportFileWithBatch(ctx context.Context, rf *model.ReportFile) (int64, error) {
 	const query = `
@@ -387,12 +762,29 @@ func (s *SQLiteStore) InsertReportFileWithBatch(ctx context.Context, rf *model.R
 		batchID,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("insert report_file: %w", err)
+		return 0, dbError("insert report_file", err)
 	}
 	id, err := result.LastInsertId()
 	if err != nil {
-		return 0, fmt.Errorf("get report_file id: %w", err)
+		return 0, dbError("get report_file id", err)
 	}
 	rf.ID = id
 	return id, nil
 }
+
+// PendingReports returns how many of the game's report files still have
+// pipeline work queued or running.
+func (s *SQLiteStore) PendingReports(ctx context.Context, gameID string) (int, error) {
+	const query = `
+		SELECT COUNT(DISTINCT w.report_file_id)
+		FROM work w
+		JOIN report_files rf ON rf.id = w.report_file_id
+		WHERE rf.game = ?
+		  AND w.status IN ('queued', 'running')
+	`
+	var n int
+	if err := s.db.QueryRowContext(ctx, query, gameID).Scan(&n); err != nil {
+		return 0, dbError("count pending reports", err)
+	}
+	return n, nil
+}
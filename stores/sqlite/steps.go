@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// EachStepRecord calls fn with every step the clan's units took, flattened to
+// one record per step with the hexes it went from and to (see model.StepRecords),
+// in turn, unit, act, and step order. If turnNo is zero, all turns are returned.
+// Units are loaded a turn at a time, so an export of a long game doesn't hold
+// every turn in memory. It stops at the first error fn returns.
+func (s *SQLiteStore) EachStepRecord(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo, fn func(model.StepRecord) error) error {
+	world, err := s.WorldRules(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	turns := []model.TurnNo{turnNo}
+	if turnNo == 0 {
+		if turns, err = s.TurnsByGameClan(gameID, clanNo); err != nil {
+			return err
+		}
+	}
+	for _, tn := range turns {
+		units, err := s.unitsWithEncounters(ctx, `r.game = ? AND u.clan_id = ? AND u.turn_no = ?`,
+			gameID, formatClanNo(clanNo), tn)
+		if err != nil {
+			return err
+		}
+		for _, u := range units {
+			for _, rec := range model.StepRecords(u, world) {
+				if err := fn(rec); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// MoveBudgetsByGameClan returns what each move and scout act of the clan's
+// units spent in movement points in the turn (see model.MoveBudgets).
+func (s *SQLiteStore) MoveBudgetsByGameClan(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) ([]model.MoveBudget, error) {
+	world, err := s.WorldRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	units, err := s.unitsWithEncounters(ctx, `r.game = ? AND u.clan_id = ? AND u.turn_no = ?`,
+		gameID, formatClanNo(clanNo), turnNo)
+	if err != nil {
+		return nil, err
+	}
+	budgets := []model.MoveBudget{}
+	for _, u := range units {
+		budgets = append(budgets, model.MoveBudgets(u, world)...)
+	}
+	return budgets, nil
+}
+
+// ActivityByGameClan returns the clan's change feed (see model.ActivityFeed).
+// If asOf is non-zero, turns after asOf are ignored.
+func (s *SQLiteStore) ActivityByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]model.Activity, error) {
+	units, err := s.unitsWithEncounters(ctx, `r.game = ? AND u.clan_id = ? AND (? = 0 OR u.turn_no <= ?)`,
+		gameID, formatClanNo(clanNo), asOf, asOf)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.UnitIDRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	world, err := s.WorldRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return model.ActivityFeed(units, rules, world), nil
+}
+
+// MapNewsByGameClan returns each of a clan's steps that observed a hex, with
+// what it told the clan that it didn't already know (see model.MapNewsFeed).
+// If asOf is non-zero, turns after asOf are ignored.
+func (s *SQLiteStore) MapNewsByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]model.StepNews, error) {
+	units, err := s.unitsWithEncounters(ctx, `r.game = ? AND u.clan_id = ? AND (? = 0 OR u.turn_no <= ?)`,
+		gameID, formatClanNo(clanNo), asOf, asOf)
+	if err != nil {
+		return nil, err
+	}
+	world, err := s.WorldRules(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return model.MapNewsFeed(units, world), nil
+}
+
+// unitsWithEncounters loads units with their acts and steps, and each step's
+// encounters and borders. filter works as for unitsWithSteps.
+func (s *SQLiteStore) unitsWithEncounters(ctx context.Context, filter string, args ...any) ([]*model.UnitX, error) {
+	rows, err := s.unitsWithSteps(ctx, filter, args...)
+	if err != nil {
+		return nil, err
+	}
+	enc, borders, err := s.derivedEncounters(ctx, filter, args...)
+	if err != nil {
+		return nil, err
+	}
+	units := make([]*model.UnitX, len(rows))
+	for i, du := range rows {
+		for _, act := range du.unit.Acts {
+			for _, st := range act.Steps {
+				st.Enc, st.Borders = enc[st.ID], borders[st.ID]
+			}
+		}
+		units[i] = du.unit
+	}
+	return units, nil
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/coords"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// TileSource is a step that contributed to a tile, with the parts of the
+// tile it set; see model.TileSrc.
+type TileSource struct {
+	UnitXID int64 // unit_extracts.id, for the unit detail page
+	model.TileSrc
+}
+
+// TileByGameCoord returns the merged tile RebuildDerived wrote for a grid
+// location, with its contents and every source, in turn order. It returns a
+// NOT_FOUND error if no report has placed anything on the location.
+func (s *SQLiteStore) TileByGameCoord(ctx context.Context, gameID string, tn model.TNCoord) (*model.Tile, error) {
+	grid, col, row, err := tn.Parse()
+	if err != nil {
+		return nil, cerrs.Wrap(cerrs.CodeInvalidInput, "tile", err)
+	}
+	hex, err := coords.NewTribeNetLayout().CoordToHex(tn)
+	if err != nil {
+		return nil, cerrs.Wrap(cerrs.CodeInvalidInput, "tile", err)
+	}
+
+	t := &model.Tile{Hex: hex, TN: tn}
+	var terr, label sql.NullString
+	err = s.db.QueryRowContext(ctx, `SELECT id, terr, special_label FROM tiles WHERE game = ? AND grid = ? AND col = ? AND row = ?`,
+		gameID, grid, col, row).Scan(&t.ID, &terr, &label)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, cerrs.New(cerrs.CodeNotFound, "tile "+string(tn)+" not found")
+	} else if err != nil {
+		return nil, dbError("query tile", err)
+	}
+	t.Terr, t.SpecialLabel = terr.String, label.String
+
+	if err := s.scanTileRows(ctx, `SELECT unit_id, name, clan_no FROM tile_units WHERE tile_id = ? ORDER BY id`, t.ID, func(rows *sql.Rows) error {
+		var u model.UnitSeen
+		var name, clanNo sql.NullString
+		if err := rows.Scan(&u.UnitID, &name, &clanNo); err != nil {
+			return err
+		}
+		u.Name, u.ClanNo = name.String, clanNo.String
+		t.Units = append(t.Units, &u)
+		return nil
+	}); err != nil {
+		return nil, dbError("query tile units", err)
+	}
+	if err := s.scanTileRows(ctx, `SELECT name, kind, clan_no FROM tile_sets WHERE tile_id = ? ORDER BY id`, t.ID, func(rows *sql.Rows) error {
+		var set model.SettleSeen
+		var kind, clanNo sql.NullString
+		if err := rows.Scan(&set.Name, &kind, &clanNo); err != nil {
+			return err
+		}
+		set.Kind, set.ClanNo = kind.String, clanNo.String
+		t.Sets = append(t.Sets, &set)
+		return nil
+	}); err != nil {
+		return nil, dbError("query tile settlements", err)
+	}
+	if err := s.scanTileRows(ctx, `SELECT kind, qty FROM tile_rsrc WHERE tile_id = ? ORDER BY id`, t.ID, func(rows *sql.Rows) error {
+		var r model.RsrcSeen
+		if err := rows.Scan(&r.Kind, &r.Qty); err != nil {
+			return err
+		}
+		t.Rsrc = append(t.Rsrc, &r)
+		return nil
+	}); err != nil {
+		return nil, dbError("query tile resources", err)
+	}
+	if err := s.scanTileRows(ctx, `SELECT dir, kind FROM tile_borders WHERE tile_id = ? ORDER BY id`, t.ID, func(rows *sql.Rows) error {
+		var b model.BorderObs
+		if err := rows.Scan(&b.Dir, &b.Kind); err != nil {
+			return err
+		}
+		t.Borders = append(t.Borders, &b)
+		return nil
+	}); err != nil {
+		return nil, dbError("query tile borders", err)
+	}
+	if err := s.scanTileRows(ctx, `SELECT doc_id, unit_id, turn_no, act_seq, step_seq, note, attrs FROM tile_src WHERE tile_id = ? ORDER BY id`, t.ID, func(rows *sql.Rows) error {
+		src, err := scanTileSrc(rows)
+		if err != nil {
+			return err
+		}
+		t.Src = append(t.Src, src)
+		return nil
+	}); err != nil {
+		return nil, dbError("query tile sources", err)
+	}
+	return t, nil
+}
+
+func (s *SQLiteStore) scanTileRows(ctx context.Context, query string, tileID int64, scan func(*sql.Rows) error) error {
+	rows, err := s.db.QueryContext(ctx, query, tileID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanTileSrc(rows *sql.Rows, dest ...any) (*model.TileSrc, error) {
+	src := &model.TileSrc{}
+	var unitID, note, attrs sql.NullString
+	var turnNo, actSeq, stepSeq sql.NullInt64
+	if err := rows.Scan(append(dest, &src.DocID, &unitID, &turnNo, &actSeq, &stepSeq, &note, &attrs)...); err != nil {
+		return nil, err
+	}
+	src.UnitID, src.Note = unitID.String, note.String
+	src.TurnNo, src.ActSeq, src.StepSeq = model.TurnNo(turnNo.Int64), int(actSeq.Int64), int(stepSeq.Int64)
+	src.Attrs = splitTileAttrs(attrs.String)
+	return src, nil
+}
+
+// TileSourcesByGameClanCoord returns the clan's sources for the merged tile
+// at a grid location, in turn order, so that a player can see which of their
+// sightings the map shows. If asOf is non-zero, sources from turns after asOf
+// are excluded. Tiles are built by RebuildDerived; until it runs, there are none.
+func (s *SQLiteStore) TileSourcesByGameClanCoord(grid string, col, row int, gameID string, clanNo int, asOf model.TurnNo) ([]TileSource, error) {
+	const query = `
+		SELECT u.id, ts.doc_id, ts.unit_id, ts.turn_no, ts.act_seq, ts.step_seq, ts.note, ts.attrs
+		FROM tiles t
+		JOIN tile_src ts ON ts.tile_id = t.id
+		JOIN report_extracts r ON r.report_file_id = ts.doc_id
+		JOIN unit_extracts u ON u.report_x_id = r.id AND u.unit_id = ts.unit_id
+		WHERE t.game = ? AND t.grid = ? AND t.col = ? AND t.row = ?
+		  AND u.clan_id = ?
+		  AND (? = 0 OR ts.turn_no <= ?)
+		ORDER BY ts.turn_no, ts.unit_id, ts.act_seq, ts.step_seq
+	`
+	rows, err := s.db.Query(query, gameID, grid, col, row, formatClanNo(clanNo), asOf, asOf)
+	if err != nil {
+		return nil, dbError("query tile sources", err)
+	}
+	defer rows.Close()
+
+	var sources []TileSource
+	for rows.Next() {
+		var ts TileSource
+		src, err := scanTileSrc(rows, &ts.UnitXID)
+		if err != nil {
+			return nil, dbError("scan tile source", err)
+		}
+		ts.TileSrc = *src
+		sources = append(sources, ts)
+	}
+	return sources, rows.Err()
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store_test
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// TestTileProvenance has two clans see the same hex on different turns and
+// checks which parts of the merged tile each sighting is credited with.
+func TestTileProvenance(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tiles.db")
+	if err := store.InitDatabase(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.NewSQLiteStoreWithConfig(store.StoreConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.CreateGame(ctx, "0301", "test game"); err != nil {
+		t.Fatal(err)
+	}
+
+	// each unit starts in QQ 1010 and steps north into QQ 1009
+	observe := func(clan string, turnNo model.TurnNo, terr string, enc *model.Enc, borders []*model.BorderObs) {
+		t.Helper()
+		rf := &model.ReportFile{Game: "0301", ClanNo: clan, TurnNo: turnNo, Name: clan + ".report.txt", SHA256: clan,
+			Mime: "text/plain", CreatedAt: time.Now().UTC(), FsPath: clan + ".report.txt"}
+		if _, err := s.InsertReportFileWithBatch(ctx, rf); err != nil {
+			t.Fatal(err)
+		}
+		rxID, err := s.InsertReportExtract(ctx, &model.ReportX{ReportFileID: rf.ID, Game: "0301", ClanNo: clan, TurnNo: turnNo, CreatedAt: time.Now().UTC()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		uxID, err := s.InsertUnitExtract(ctx, &model.UnitX{ReportXID: rxID, UnitID: clan, ClanID: clan[1:], TurnNo: turnNo, StartTN: "QQ 1010", EndTN: "QQ 1009"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		actID, err := s.InsertAct(ctx, &model.Act{UnitXID: uxID, Seq: 1, Kind: model.ActKindMove, Ok: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.InsertStep(ctx, &model.Step{ActID: actID, Seq: 1, Kind: model.StepKindAdv, Dir: "N", Ok: true, Terr: terr, Enc: enc, Borders: borders}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	observe("0987", 89912, "PR", &model.Enc{Units: []*model.UnitSeen{{UnitID: "0123"}}}, []*model.BorderObs{{Dir: "N", Kind: "River"}})
+	observe("0512", 90001, "GH", &model.Enc{Sets: []*model.SettleSeen{{Name: "Ur"}}}, nil)
+	if _, err := s.RebuildDerived(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tile, err := s.TileByGameCoord(ctx, "0301", "QQ 1009")
+	if err != nil {
+		t.Fatalf("tile: %v", err)
+	}
+	if tile.Terr != "GH" || len(tile.Sets) != 1 || len(tile.Units) != 0 || len(tile.Borders) != 0 {
+		t.Errorf("tile = %+v, want the newer sighting's GH and settlement", tile)
+	}
+	if len(tile.Src) != 2 {
+		t.Fatalf("tile: got %d sources, want 2", len(tile.Src))
+	}
+	// the older sighting was replaced: its units and border cleared, its terrain outweighed
+	if got := tile.Src[0].Attrs; len(got) != 0 {
+		t.Errorf("0987: attrs = %v, want none", got)
+	}
+	if got, want := tile.Src[1].Attrs, []model.TileAttr{model.TileAttrTerrain, model.TileAttrSettlements}; !slices.Equal(got, want) {
+		t.Errorf("0512: attrs = %v, want %v", got, want)
+	}
+
+	sources, err := s.TileSourcesByGameClanCoord("QQ", 10, 9, "0301", 512, 0)
+	if err != nil {
+		t.Fatalf("clan sources: %v", err)
+	}
+	if len(sources) != 1 || sources[0].UnitID != "0512" || sources[0].UnitXID == 0 || len(sources[0].Attrs) != 2 {
+		t.Errorf("clan sources = %+v, want clan 0512's step", sources)
+	}
+
+	if _, err := s.TileByGameCoord(ctx, "0301", "QQ 0101"); err == nil {
+		t.Errorf("unobserved tile: got nil error")
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// SetReportTimings records how long it took to produce a report extract.
+// Steps with a zero duration are stored as not measured.
+func (s *SQLiteStore) SetReportTimings(ctx context.Context, rxID int64, t model.ParseTimings) error {
+	const query = `
+		UPDATE report_extracts
+		SET extract_ms = ?, split_ms = ?, parse_ms = ?, adapt_ms = ?, store_ms = ?
+		WHERE id = ?
+	`
+	_, err := s.db.ExecContext(ctx, query,
+		nullMillis(t.Extract),
+		nullMillis(t.Split),
+		nullMillis(t.Parse),
+		nullMillis(t.Adapt),
+		nullMillis(t.Store),
+		rxID,
+	)
+	if err != nil {
+		return dbError("set report timings", err)
+	}
+	return nil
+}
+
+// SlowestReports returns the timings of the limit report extracts that took
+// longest to produce, slowest first. Reports without timings are skipped.
+//
+// Reports that came through the pipeline are extracted by a separate job, so
+// when the extract step wasn't recorded on the report it is taken from that
+// job's start and finish times (which have a resolution of one second).
+func (s *SQLiteStore) SlowestReports(ctx context.Context, limit int) ([]model.ReportTiming, error) {
+	const query = `
+		SELECT id, game, clan_no, turn_no, name, created_at,
+		       extract_ms, split_ms, parse_ms, adapt_ms, store_ms
+		FROM (
+			SELECT rx.id, rx.game, rx.clan_no, rx.turn_no, rf.name, rx.created_at,
+			       COALESCE(rx.extract_ms, (
+			           SELECT CAST((julianday(w.finished_at) - julianday(w.started_at)) * 86400000 AS INTEGER)
+			           FROM work w
+			           WHERE w.report_file_id = rx.report_file_id AND w.stage = 'extract' AND w.status = 'ok'
+			       )) AS extract_ms,
+			       rx.split_ms, rx.parse_ms, rx.adapt_ms, rx.store_ms
+			FROM report_extracts rx
+			JOIN report_files rf ON rf.id = rx.report_file_id
+			WHERE rx.parse_ms IS NOT NULL OR rx.store_ms IS NOT NULL
+		)
+		ORDER BY COALESCE(extract_ms, 0) + COALESCE(split_ms, 0) + COALESCE(parse_ms, 0)
+		       + COALESCE(adapt_ms, 0) + COALESCE(store_ms, 0) DESC, id
+		LIMIT ?
+	`
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, dbError("query report timings", err)
+	}
+	defer rows.Close()
+
+	var timings []model.ReportTiming
+	for rows.Next() {
+		var rt model.ReportTiming
+		var createdAt string
+		var extract, split, parse, adapt, write sql.NullInt64
+		if err := rows.Scan(&rt.ReportXID, &rt.Game, &rt.ClanNo, &rt.TurnNo, &rt.Name, &createdAt,
+			&extract, &split, &parse, &adapt, &write); err != nil {
+			return nil, dbError("scan report timings", err)
+		}
+		rt.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		rt.Timings = model.ParseTimings{
+			Extract: time.Duration(extract.Int64) * time.Millisecond,
+			Split:   time.Duration(split.Int64) * time.Millisecond,
+			Parse:   time.Duration(parse.Int64) * time.Millisecond,
+			Adapt:   time.Duration(adapt.Int64) * time.Millisecond,
+			Store:   time.Duration(write.Int64) * time.Millisecond,
+		}
+		timings = append(timings, rt)
+	}
+	return timings, rows.Err()
+}
+
+// nullMillis returns d in whole milliseconds, or NULL if d is zero.
+// Steps that were measured but took under a millisecond are stored as 1.
+func nullMillis(d time.Duration) sql.NullInt64 {
+	if d <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: max(d.Milliseconds(), 1), Valid: true}
+}
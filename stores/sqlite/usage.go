@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+)
+
+// RecordRequest adds a served request to the request log. handle is empty
+// for requests that weren't logged in.
+func (s *SQLiteStore) RecordRequest(ctx context.Context, handle, route string, status int, d time.Duration) error {
+	const query = `
+		INSERT INTO request_log (created_at, user_handle, route, status, duration_ms)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	now := s.now().Format(time.RFC3339)
+	if _, err := s.db.ExecContext(ctx, query, now, nullString(handle), route, status, d.Milliseconds()); err != nil {
+		return dbError("record request", err)
+	}
+	return nil
+}
+
+// PruneRequestLog deletes requests logged before the cutoff and returns how
+// many were deleted.
+func (s *SQLiteStore) PruneRequestLog(ctx context.Context, before time.Time) (int64, error) {
+	const query = `DELETE FROM request_log WHERE created_at < ?`
+	res, err := s.db.ExecContext(ctx, query, before.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, dbError("prune request log", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// UsageStats summarizes use of the server since the given time: reports
+// parsed per day (from the report extracts), distinct logged-in users per
+// week, and the limit most requested routes (from the request log).
+// Days and weeks with nothing in them are left out.
+func (s *SQLiteStore) UsageStats(ctx context.Context, since time.Time, limit int) (*model.UsageStats, error) {
+	cutoff := since.UTC().Format(time.RFC3339)
+	stats := &model.UsageStats{Since: since.UTC()}
+
+	const requestsQuery = `SELECT COUNT(*) FROM request_log WHERE created_at >= ?`
+	if err := s.db.QueryRowContext(ctx, requestsQuery, cutoff).Scan(&stats.Requests); err != nil {
+		return nil, dbError("count requests", err)
+	}
+
+	const reportsQuery = `
+		SELECT substr(created_at, 1, 10) AS day, COUNT(*)
+		FROM report_extracts
+		WHERE created_at >= ?
+		GROUP BY day
+		ORDER BY day
+	`
+	rows, err := s.db.QueryContext(ctx, reportsQuery, cutoff)
+	if err != nil {
+		return nil, dbError("query reports per day", err)
+	}
+	for rows.Next() {
+		var dc model.DayCount
+		if err := rows.Scan(&dc.Day, &dc.Count); err != nil {
+			rows.Close()
+			return nil, dbError("scan reports per day", err)
+		}
+		stats.ReportsPerDay = append(stats.ReportsPerDay, dc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, dbError("query reports per day", err)
+	}
+
+	// date(x, 'weekday 0', '-6 days') is the Monday on or before x
+	const usersQuery = `
+		SELECT date(created_at, 'weekday 0', '-6 days') AS week, COUNT(DISTINCT user_handle)
+		FROM request_log
+		WHERE created_at >= ? AND user_handle IS NOT NULL
+		GROUP BY week
+		ORDER BY week
+	`
+	rows, err = s.db.QueryContext(ctx, usersQuery, cutoff)
+	if err != nil {
+		return nil, dbError("query users per week", err)
+	}
+	for rows.Next() {
+		var wc model.WeekCount
+		if err := rows.Scan(&wc.Week, &wc.Users); err != nil {
+			rows.Close()
+			return nil, dbError("scan users per week", err)
+		}
+		stats.UsersPerWeek = append(stats.UsersPerWeek, wc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, dbError("query users per week", err)
+	}
+
+	const routesQuery = `
+		SELECT route, COUNT(*) AS requests, COUNT(DISTINCT user_handle), CAST(AVG(duration_ms) AS INTEGER)
+		FROM request_log
+		WHERE created_at >= ?
+		GROUP BY route
+		ORDER BY requests DESC, route
+		LIMIT ?
+	`
+	rows, err = s.db.QueryContext(ctx, routesQuery, cutoff, limit)
+	if err != nil {
+		return nil, dbError("query busiest pages", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ru model.RouteUsage
+		if err := rows.Scan(&ru.Route, &ru.Requests, &ru.Users, &ru.AvgMillis); err != nil {
+			return nil, dbError("scan busiest pages", err)
+		}
+		stats.BusiestPages = append(stats.BusiestPages, ru)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("query busiest pages", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/cerrs"
+)
+
+// AuditUsersImport is the audit log action for a bulk user import.
+const AuditUsersImport = "users.import"
+
+// ErrInvalidUsers is wrapped by the error ImportUsers returns for input it rejects.
+var ErrInvalidUsers = cerrs.New(cerrs.CodeInvalidInput, "invalid users")
+
+// UserRecord is a user with their roles and clan assignments, in the form
+// used to move users between deployments.
+//
+// PasswordHash is the stored bcrypt hash. It is only exported when asked for,
+// and an import never accepts a plain-text password in its place.
+type UserRecord struct {
+	Handle       string       `json:"handle"`
+	UserName     string       `json:"user-name"`
+	Email        string       `json:"email,omitempty"`
+	Timezone     string       `json:"tz,omitempty"`
+	PasswordHash string       `json:"password-hash,omitempty"`
+	CreatedAt    string       `json:"created-at,omitempty"`
+	Roles        []string     `json:"roles"`
+	Clans        []UserClanNo `json:"clans,omitempty"`
+}
+
+// UserClanNo assigns a user to a clan in a game.
+type UserClanNo struct {
+	Game string `json:"game"`
+	Clan int    `json:"clan"`
+}
+
+// UsersImport summarizes an ImportUsers call.
+type UsersImport struct {
+	Created int // users that didn't exist before
+	Updated int // existing users that were replaced
+}
+
+// ExportUsers returns every user, sorted by handle, with their roles and clans.
+// Password hashes are left empty unless withHashes is true.
+func (s *SQLiteStore) ExportUsers(ctx context.Context, withHashes bool) ([]UserRecord, error) {
+	const query = `
+		SELECT handle, user_name, COALESCE(email, ''), COALESCE(timezone, ''), password_hash, created_at
+		FROM users
+		ORDER BY handle
+	`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, dbError("query users", err)
+	}
+	defer rows.Close()
+
+	var users []UserRecord
+	index := map[string]int{}
+	for rows.Next() {
+		var u UserRecord
+		if err := rows.Scan(&u.Handle, &u.UserName, &u.Email, &u.Timezone, &u.PasswordHash, &u.CreatedAt); err != nil {
+			return nil, dbError("scan user", err)
+		}
+		if !withHashes {
+			u.PasswordHash = ""
+		}
+		u.Roles = []string{}
+		index[u.Handle] = len(users)
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError("iterate users", err)
+	}
+
+	roles, err := s.db.QueryContext(ctx, `SELECT user_handle, role FROM user_roles ORDER BY user_handle, role`)
+	if err != nil {
+		return nil, dbError("query roles", err)
+	}
+	defer roles.Close()
+	for roles.Next() {
+		var handle, role string
+		if err := roles.Scan(&handle, &role); err != nil {
+			return nil, dbError("scan role", err)
+		}
+		if i, ok := index[handle]; ok {
+			users[i].Roles = append(users[i].Roles, role)
+		}
+	}
+	if err := roles.Err(); err != nil {
+		return nil, dbError("iterate roles", err)
+	}
+
+	clans, err := s.db.QueryContext(ctx, `SELECT user_handle, game_id, clan_no FROM game_clans ORDER BY user_handle, game_id, clan_no`)
+	if err != nil {
+		return nil, dbError("query clans", err)
+	}
+	defer clans.Close()
+	for clans.Next() {
+		var handle string
+		var c UserClanNo
+		if err := clans.Scan(&handle, &c.Game, &c.Clan); err != nil {
+			return nil, dbError("scan clan", err)
+		}
+		if i, ok := index[handle]; ok {
+			users[i].Clans = append(users[i].Clans, c)
+		}
+	}
+	return users, clans.Err()
+}
+
+// ImportUsers creates or replaces users, their roles, and their clan
+// assignments in one transaction, and records the import, made by actor, in
+// the audit log. Nothing is written if any user is invalid.
+//
+// A user without a password hash keeps their current password; a new user
+// without one can't log in until a password is set. A user's clans are
+// replaced only in the games listed, and every game must already exist.
+// A clan listed for a user is taken away from whoever had it.
+func (s *SQLiteStore) ImportUsers(ctx context.Context, actor string, users []UserRecord) (UsersImport, error) {
+	var result UsersImport
+	seen := map[string]bool{}
+	for _, u := range users {
+		if u.Handle == "" {
+			return result, fmt.Errorf("%w: missing handle", ErrInvalidUsers)
+		} else if seen[u.Handle] {
+			return result, fmt.Errorf("%w: %s: duplicate handle", ErrInvalidUsers, u.Handle)
+		}
+		seen[u.Handle] = true
+		if u.PasswordHash != "" && !isBcryptHash(u.PasswordHash) {
+			return result, fmt.Errorf("%w: %s: password-hash is not a bcrypt hash", ErrInvalidUsers, u.Handle)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, dbError("begin tx", err)
+	}
+	defer tx.Rollback()
+
+	now := s.now().Format(time.RFC3339)
+	for _, u := range users {
+		var hash string
+		err := tx.QueryRowContext(ctx, `SELECT password_hash FROM users WHERE handle = ?`, u.Handle).Scan(&hash)
+		if err == sql.ErrNoRows {
+			result.Created++
+			hash = invalidPasswordHash
+		} else if err != nil {
+			return result, dbError(fmt.Sprintf("query user %s", u.Handle), err)
+		} else {
+			result.Updated++
+		}
+		if u.PasswordHash != "" {
+			hash = u.PasswordHash
+		}
+		userName, createdAt := u.UserName, u.CreatedAt
+		if userName == "" {
+			userName = u.Handle
+		}
+		if createdAt == "" {
+			createdAt = now
+		}
+
+		const upsert = `
+			INSERT INTO users (handle, user_name, email, timezone, password_hash, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(handle) DO UPDATE SET
+				user_name = excluded.user_name,
+				email = excluded.email,
+				timezone = excluded.timezone,
+				password_hash = excluded.password_hash
+		`
+		if _, err := tx.ExecContext(ctx, upsert, u.Handle, userName, nullString(u.Email), nullString(u.Timezone), hash, createdAt); err != nil {
+			return result, dbError(fmt.Sprintf("upsert user %s", u.Handle), err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_handle = ?`, u.Handle); err != nil {
+			return result, dbError(fmt.Sprintf("delete roles for %s", u.Handle), err)
+		}
+		for _, role := range u.Roles {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO user_roles (user_handle, role) VALUES (?, ?) ON CONFLICT DO NOTHING`, u.Handle, role); err != nil {
+				return result, dbError(fmt.Sprintf("insert role for %s", u.Handle), err)
+			}
+		}
+
+		replaced := map[string]bool{}
+		for _, c := range u.Clans {
+			if !replaced[c.Game] {
+				if _, err := tx.ExecContext(ctx, `DELETE FROM game_clans WHERE game_id = ? AND user_handle = ?`, c.Game, u.Handle); err != nil {
+					return result, dbError(fmt.Sprintf("delete clans for %s in %s", u.Handle, c.Game), err)
+				}
+				replaced[c.Game] = true
+			}
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM games WHERE id = ?)`, c.Game).Scan(&exists); err != nil {
+				return result, dbError(fmt.Sprintf("query game %s", c.Game), err)
+			} else if !exists {
+				return result, fmt.Errorf("%w: %s: game %s does not exist", ErrInvalidUsers, u.Handle, c.Game)
+			}
+			const assign = `
+				INSERT INTO game_clans (game_id, user_handle, clan_no) VALUES (?, ?, ?)
+				ON CONFLICT(game_id, clan_no) DO UPDATE SET user_handle = excluded.user_handle
+			`
+			if _, err := tx.ExecContext(ctx, assign, c.Game, u.Handle, c.Clan); err != nil {
+				return result, dbError(fmt.Sprintf("assign %s to clan %d in %s", u.Handle, c.Clan, c.Game), err)
+			}
+		}
+	}
+
+	handles := make([]string, 0, len(seen))
+	for h := range seen {
+		handles = append(handles, h)
+	}
+	sort.Strings(handles)
+	detail := fmt.Sprintf("imported %d users (%d new): %s", len(users), result.Created, strings.Join(handles, ", "))
+	if err := s.insertAuditLog(ctx, tx, actor, AuditUsersImport, "", detail); err != nil {
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return result, dbError("commit", err)
+	}
+	return result, nil
+}
+
+// isBcryptHash reports whether s looks like a bcrypt hash rather than a
+// plain-text password. The hash given to users who can't log in counts.
+func isBcryptHash(s string) bool {
+	if s == invalidPasswordHash {
+		return true
+	} else if len(s) != 60 {
+		return false
+	}
+	return strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$")
+}
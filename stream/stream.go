@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Package stream writes exports a record at a time, so that a response or a
+// file never has to be built in memory first. Writers flush every few hundred
+// records; if the destination has a Flush method (an HTTP response, say), it
+// is flushed too so the client sees the data as it is made.
+package stream
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// flushEvery is the number of records written between flushes.
+const flushEvery = 256
+
+// flush flushes dst if it can be flushed.
+func flush(dst io.Writer) error {
+	switch f := dst.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
+
+// JSONArray writes values as the elements of a JSON array. The output is
+// the same as encoding the whole slice with json.Encoder, or with
+// json.MarshalIndent(v, "", "  ") plus a newline if indent is set.
+type JSONArray struct {
+	dst    io.Writer
+	w      *bufio.Writer
+	indent bool
+	n      int
+}
+
+// NewJSONArray returns a JSONArray that writes to w. Nothing is written
+// until the first call to Write or Close.
+func NewJSONArray(w io.Writer, indent bool) *JSONArray {
+	return &JSONArray{dst: w, w: bufio.NewWriter(w), indent: indent}
+}
+
+// Write adds v to the array.
+func (a *JSONArray) Write(v any) error {
+	var data []byte
+	var err error
+	if a.indent {
+		data, err = json.MarshalIndent(v, "  ", "  ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	sep := ","
+	if a.n == 0 {
+		sep = "["
+	}
+	if a.indent {
+		sep += "\n  "
+	}
+	a.w.WriteString(sep)
+	if _, err := a.w.Write(data); err != nil {
+		return err
+	}
+	a.n++
+	if a.n%flushEvery == 0 {
+		return a.Flush()
+	}
+	return nil
+}
+
+// Count returns the number of values written.
+func (a *JSONArray) Count() int {
+	return a.n
+}
+
+// Flush writes the buffered values to the destination and flushes it.
+func (a *JSONArray) Flush() error {
+	if err := a.w.Flush(); err != nil {
+		return err
+	}
+	return flush(a.dst)
+}
+
+// Close ends the array and flushes it. It doesn't close the destination.
+func (a *JSONArray) Close() error {
+	switch {
+	case a.n == 0:
+		a.w.WriteString("[]\n")
+	case a.indent:
+		a.w.WriteString("\n]\n")
+	default:
+		a.w.WriteString("]\n")
+	}
+	return a.Flush()
+}
+
+// CSV writes records as CSV rows under a header row.
+type CSV struct {
+	dst io.Writer
+	w   *csv.Writer
+	n   int
+}
+
+// NewCSV returns a CSV that writes to w, starting with header.
+func NewCSV(w io.Writer, header []string) (*CSV, error) {
+	c := &CSV{dst: w, w: csv.NewWriter(w)}
+	if err := c.w.Write(header); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Write adds a row.
+func (c *CSV) Write(record []string) error {
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	c.n++
+	if c.n%flushEvery == 0 {
+		return c.Flush()
+	}
+	return nil
+}
+
+// Count returns the number of rows written, not counting the header.
+func (c *CSV) Count() int {
+	return c.n
+}
+
+// Flush writes the buffered rows to the destination and flushes it.
+func (c *CSV) Flush() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	return flush(c.dst)
+}
+
+// Close flushes the rows. It doesn't close the destination.
+func (c *CSV) Close() error {
+	return c.Flush()
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package stream_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/stream"
+)
+
+type record struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func TestJSONArray_MatchesEncoder(t *testing.T) {
+	for _, n := range []int{0, 1, 3, 600} {
+		var records []record
+		for i := 0; i < n; i++ {
+			records = append(records, record{ID: i, Name: strings.Repeat("x", i%3)})
+		}
+		for _, indent := range []bool{false, true} {
+			var want bytes.Buffer
+			if indent {
+				data, _ := json.MarshalIndent(append([]record{}, records...), "", "  ")
+				want.Write(append(data, '\n'))
+			} else {
+				json.NewEncoder(&want).Encode(append([]record{}, records...))
+			}
+
+			var got bytes.Buffer
+			a := stream.NewJSONArray(&got, indent)
+			for _, rec := range records {
+				if err := a.Write(rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := a.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("n %d, indent %v: got\n%s\nwant\n%s", n, indent, got.String(), want.String())
+			}
+			if a.Count() != n {
+				t.Errorf("n %d: count %d", n, a.Count())
+			}
+		}
+	}
+}
+
+// flushRecorder counts the flushes it is asked for.
+type flushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (f *flushRecorder) Flush() { f.flushes++ }
+
+func TestCSV_Flushes(t *testing.T) {
+	var dst flushRecorder
+	c, err := stream.NewCSV(&dst, []string{"id", "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 600; i++ {
+		if err := c.Write([]string{"1", "a,b"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if dst.flushes != 2 {
+		t.Errorf("expected 2 flushes while writing, got %d", dst.flushes)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(dst.String(), "\n"), "\n")
+	if len(lines) != 601 || lines[0] != "id,name" || lines[1] != `1,"a,b"` {
+		t.Errorf("got %d lines, first %q, %q", len(lines), lines[0], lines[1])
+	}
+}
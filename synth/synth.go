@@ -0,0 +1,347 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Package synth generates synthetic games: turn reports for made-up clans
+// moving over a made-up map. The reports parse like real ones, so stores and
+// handlers can be measured against large games without private player data.
+package synth
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/terrain"
+)
+
+// Pattern is how a unit moves from turn to turn.
+type Pattern string
+
+const (
+	PatternStill  Pattern = "still"  // never moves
+	PatternRandom Pattern = "random" // a random direction every step
+	PatternLine   Pattern = "line"   // keeps a heading, turning at the edge of the world
+	PatternPatrol Pattern = "patrol" // out along a heading one turn, back the next
+	PatternMixed  Pattern = "mixed"  // each unit takes one of the others in turn
+)
+
+// Patterns are the patterns a Config can use.
+var Patterns = []Pattern{PatternStill, PatternRandom, PatternLine, PatternPatrol, PatternMixed}
+
+// MaxUnitsPerClan is the most units a clan can have: ten tribes, each with
+// nine elements and nine couriers.
+const MaxUnitsPerClan = 10 * 19
+
+// Config describes the game to generate. Zero values take the defaults
+// noted on each field.
+type Config struct {
+	Game         string           // default "0301"
+	Clans        int              // default 10; clans are numbered from 0001
+	UnitsPerClan int              // default 5, at most MaxUnitsPerClan
+	Turns        int              // default 12
+	FirstTurn    model.TurnNo     // default 0899-12
+	Pattern      Pattern          // default PatternMixed
+	MaxSteps     int              // most hexes a unit enters in a turn; default 4
+	MovePoints   int              // most movement points a unit spends in a turn; default 15
+	Seed         uint64           // the same seed generates the same game
+	World        model.WorldRules // default model.DefaultWorldRules; no wrapping is simpler to read
+}
+
+// Report is the report for one clan and turn.
+type Report struct {
+	Game   string
+	ClanNo string // e.g. "0001"
+	TurnNo model.TurnNo
+	Text   []byte
+}
+
+// Name returns the drop-folder name of the report,
+// e.g. "0301.0899-12.0001.report.txt" (see stages.ParseReportFilename).
+func (r Report) Name() string {
+	return fmt.Sprintf("%s.%s.%s.report.txt", r.Game, r.TurnNo, r.ClanNo)
+}
+
+// withDefaults returns cfg with zero values replaced by the defaults
+// and checks what is left.
+func (cfg Config) withDefaults() (Config, error) {
+	if cfg.Game == "" {
+		cfg.Game = "0301"
+	}
+	if cfg.Clans == 0 {
+		cfg.Clans = 10
+	}
+	if cfg.UnitsPerClan == 0 {
+		cfg.UnitsPerClan = 5
+	}
+	if cfg.Turns == 0 {
+		cfg.Turns = 12
+	}
+	if cfg.FirstTurn == 0 {
+		cfg.FirstTurn = model.FirstTurnNo
+	}
+	if cfg.Pattern == "" {
+		cfg.Pattern = PatternMixed
+	}
+	if cfg.MaxSteps == 0 {
+		cfg.MaxSteps = 4
+	}
+	if cfg.MovePoints == 0 {
+		cfg.MovePoints = 15
+	}
+	if cfg.World == (model.WorldRules{}) {
+		cfg.World = model.DefaultWorldRules
+	}
+
+	switch {
+	case cfg.Clans < 1 || cfg.Clans > 999:
+		return cfg, fmt.Errorf("clans %d: must be 1..999", cfg.Clans)
+	case cfg.UnitsPerClan < 1 || cfg.UnitsPerClan > MaxUnitsPerClan:
+		return cfg, fmt.Errorf("units per clan %d: must be 1..%d", cfg.UnitsPerClan, MaxUnitsPerClan)
+	case cfg.Turns < 1:
+		return cfg, fmt.Errorf("turns %d: must be at least 1", cfg.Turns)
+	case cfg.MaxSteps < 0 || cfg.MovePoints < 0:
+		return cfg, fmt.Errorf("steps and movement points must not be negative")
+	case !slices.Contains(Patterns, cfg.Pattern):
+		return cfg, fmt.Errorf("pattern %q: must be one of %v", cfg.Pattern, Patterns)
+	}
+	if err := cfg.FirstTurn.Validate(); err != nil {
+		return cfg, err
+	}
+	if last := cfg.lastTurn(); !last.Valid() {
+		return cfg, fmt.Errorf("turns %d: the game would end after %s", cfg.Turns, last)
+	}
+	return cfg, cfg.World.Validate()
+}
+
+// lastTurn returns the last turn the config generates.
+func (cfg Config) lastTurn() model.TurnNo {
+	t := cfg.FirstTurn
+	for i := 1; i < cfg.Turns; i++ {
+		t = t.Next()
+	}
+	return t
+}
+
+// unit is a unit's state between turns.
+type unit struct {
+	id      string
+	clanNo  string
+	kind    string // "Tribe", "Element", or "Courier"
+	at      model.TNCoord
+	pattern Pattern
+	heading direction.Direction_e
+}
+
+// Generate generates the game's reports, a turn at a time and clan by clan
+// within a turn, and passes each to emit. It stops at the first error emit
+// returns.
+func Generate(cfg Config, emit func(Report) error) error {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return err
+	}
+	rng := rand.New(rand.NewPCG(cfg.Seed, 0x74_6e_72_70_74)) // "tnrpt"
+
+	var clans [][]*unit
+	patterns := slices.DeleteFunc(slices.Clone(Patterns), func(p Pattern) bool { return p == PatternMixed })
+	for c := 1; c <= cfg.Clans; c++ {
+		clanNo := fmt.Sprintf("%04d", c)
+		home := randomHex(rng, cfg.World)
+		var units []*unit
+		for i := 0; i < cfg.UnitsPerClan; i++ {
+			u := &unit{id: unitID(clanNo, i), clanNo: clanNo, at: home, pattern: cfg.Pattern, heading: randomDir(rng)}
+			switch {
+			case i%19 == 0:
+				u.kind = "Tribe"
+			case i%19 <= 9:
+				u.kind = "Element"
+			default:
+				u.kind = "Courier"
+			}
+			if u.pattern == PatternMixed {
+				u.pattern = patterns[(c+i)%len(patterns)]
+			}
+			units = append(units, u)
+		}
+		clans = append(clans, units)
+	}
+
+	turnNo := cfg.FirstTurn
+	for turn := 0; turn < cfg.Turns; turn, turnNo = turn+1, turnNo.Next() {
+		moves := map[string][]string{}
+		if turn > 0 {
+			for _, units := range clans {
+				for _, u := range units {
+					moves[u.id] = cfg.move(rng, u, turn)
+				}
+			}
+		}
+
+		// who each unit sees at the end of the turn
+		seen := map[model.TNCoord][]string{}
+		for _, units := range clans {
+			for _, u := range units {
+				seen[u.at] = append(seen[u.at], u.id)
+			}
+		}
+
+		for _, units := range clans {
+			var b strings.Builder
+			for i, u := range units {
+				if i > 0 {
+					b.WriteString("\n")
+				}
+				cfg.writeSection(&b, u, turnNo, moves[u.id], seen[u.at])
+			}
+			err := emit(Report{Game: cfg.Game, ClanNo: units[0].clanNo, TurnNo: turnNo, Text: []byte(b.String())})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// move moves the unit for a turn and returns the steps, e.g. "N-PR".
+func (cfg Config) move(rng *rand.Rand, u *unit, turn int) []string {
+	if u.pattern == PatternStill || cfg.MaxSteps == 0 {
+		return nil
+	}
+	var steps []string
+	spent, turned := 0, false
+	for len(steps) < cfg.MaxSteps {
+		dir := u.heading
+		switch u.pattern {
+		case PatternRandom:
+			dir = randomDir(rng)
+		case PatternPatrol:
+			if turn%2 == 0 {
+				dir = opposite(u.heading)
+			}
+		}
+		next, err := cfg.World.Neighbor(u.at, dir)
+		if err != nil {
+			// the edge of the world; a line turns around, a patrol waits
+			if u.pattern == PatternLine && !turned {
+				u.heading, turned = opposite(u.heading), true
+				continue
+			}
+			break
+		}
+		t := cfg.terrainAt(next)
+		cost, _ := model.MPCost(t, false)
+		if spent+cost > cfg.MovePoints {
+			break
+		}
+		spent += cost
+		steps = append(steps, dir.String()+"-"+t.String())
+		u.at = next
+	}
+	return steps
+}
+
+// writeSection writes the unit's section of its clan's report.
+func (cfg Config) writeSection(b *strings.Builder, u *unit, turnNo model.TurnNo, steps, seen []string) {
+	prev := u.at
+	if len(steps) > 0 {
+		prev = cfg.startOf(u.at, steps)
+	}
+	fmt.Fprintf(b, "%s %s, , Current Hex = %s, (Previous Hex = %s)\n", u.kind, u.id, u.at, prev)
+	fmt.Fprintf(b, "Current Turn %d-%02d (#%d), %s, FINE", turnNo.Year(), turnNo.Month(), turnIndex(turnNo), season(turnNo))
+	if u.kind == "Tribe" {
+		next := turnNo.Next()
+		fmt.Fprintf(b, " Next Turn %d-%02d (#%d), 01/01/2025", next.Year(), next.Month(), turnIndex(next))
+	}
+	b.WriteString("\n")
+	if len(steps) > 0 {
+		// every unit's moves are labeled as a tribe's
+		fmt.Fprintf(b, "Tribe Movement: Move %s\n", strings.Join(steps, `, \`))
+	}
+	fmt.Fprintf(b, "%s Status: %s, %s\n", u.id, longNames[cfg.terrainAt(u.at)], strings.Join(seen, " "))
+}
+
+// startOf walks the steps back from where the unit ended.
+func (cfg Config) startOf(at model.TNCoord, steps []string) model.TNCoord {
+	for i := len(steps) - 1; i >= 0; i-- {
+		dir, _, _ := strings.Cut(steps[i], "-")
+		at, _ = cfg.World.Neighbor(at, opposite(direction.StringToEnum[dir]))
+	}
+	return at
+}
+
+// palette is the land the map is made of, prairie most often. There is no
+// water or high mountains, so every step can be taken.
+var palette = []terrain.Terrain_e{
+	terrain.FlatPrairie, terrain.FlatPrairie, terrain.FlatPrairie, terrain.FlatPrairie,
+	terrain.HillsGrassy, terrain.HillsGrassy, terrain.HillsRocky, terrain.HillsConifer,
+	terrain.FlatSwamp, terrain.FlatDesert, terrain.FlatTundra, terrain.HillsBrush,
+}
+
+// longNames are the names status lines use for the palette.
+var longNames = map[terrain.Terrain_e]string{
+	terrain.FlatPrairie:  "PRAIRIE",
+	terrain.HillsGrassy:  "GRASSY HILLS",
+	terrain.HillsRocky:   "ROCKY HILLS",
+	terrain.HillsConifer: "CONIFER HILLS",
+	terrain.FlatSwamp:    "SWAMP",
+	terrain.FlatDesert:   "DESERT",
+	terrain.FlatTundra:   "TUNDRA",
+	terrain.HillsBrush:   "BRUSH HILLS",
+}
+
+// terrainAt returns the terrain of a hex. It depends only on the seed and the
+// hex, so every unit that visits the hex sees the same terrain.
+func (cfg Config) terrainAt(c model.TNCoord) terrain.Terrain_e {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d %s", cfg.Seed, c)
+	return palette[h.Sum64()%uint64(len(palette))]
+}
+
+// unitID returns the clan's i'th unit: its tribes are 0CCC, 1CCC, ... and
+// each has nine elements and nine couriers, e.g. 0001e1 and 0001c1.
+func unitID(clanNo string, i int) string {
+	tribe := fmt.Sprintf("%d%s", i/19, clanNo[1:])
+	switch n := i % 19; {
+	case n == 0:
+		return tribe
+	case n <= 9:
+		return fmt.Sprintf("%se%d", tribe, n)
+	default:
+		return fmt.Sprintf("%sc%d", tribe, n-9)
+	}
+}
+
+// randomHex returns a hex away from the edges of its map.
+func randomHex(rng *rand.Rand, world model.WorldRules) model.TNCoord {
+	grid := string([]byte{'A' + byte(rng.IntN(world.Rows())), 'A' + byte(rng.IntN(world.Cols()))})
+	return model.NewTNCoord(grid, 3+rng.IntN(26), 3+rng.IntN(17))
+}
+
+func randomDir(rng *rand.Rand) direction.Direction_e {
+	return direction.Directions[rng.IntN(len(direction.Directions))]
+}
+
+func opposite(d direction.Direction_e) direction.Direction_e {
+	i := slices.Index(direction.Directions, d)
+	return direction.Directions[(i+3)%len(direction.Directions)]
+}
+
+// turnIndex returns the turn's number in reports, 0 for 899-12.
+func turnIndex(t model.TurnNo) int {
+	return (t.Year()-899)*12 + t.Month() - 12
+}
+
+func season(t model.TurnNo) string {
+	switch t.Month() {
+	case 12, 1, 2:
+		return "Winter"
+	case 3, 4, 5:
+		return "Spring"
+	case 6, 7, 8:
+		return "Summer"
+	}
+	return "Fall"
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package synth_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	"github.com/mdhender/tnrpt/synth"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := synth.Config{Clans: 3, UnitsPerClan: 21, Turns: 4, Seed: 7}
+	var reports []synth.Report
+	if err := synth.Generate(cfg, func(r synth.Report) error {
+		reports = append(reports, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(reports) != 12 {
+		t.Fatalf("got %d reports, want 12", len(reports))
+	}
+	if got, want := reports[11].Name(), "0301.0900-03.0003.report.txt"; got != want {
+		t.Errorf("last report: name = %q, want %q", got, want)
+	}
+
+	moved, moves := 0, 0
+	for _, r := range reports {
+		moves += bytes.Count(r.Text, []byte("Movement: Move"))
+		turn, err := bistre.ParseInput(r.Name(), r.TurnNo.String(), r.Text, bistre.ParseConfig{})
+		if err != nil {
+			t.Fatalf("%s: parse: %v\n%s", r.Name(), err, r.Text)
+		}
+		if diags := adapters.ResolveDuplicateUnits(turn); len(diags) != 0 {
+			t.Errorf("%s: %v", r.Name(), diags)
+		}
+		rx, err := adapters.BistreTurnToModelReportX(r.Name(), turn, r.Game, r.ClanNo, model.DefaultUnitIDRules, nil)
+		if err != nil {
+			t.Fatalf("%s: adapt: %v", r.Name(), err)
+		}
+		if len(rx.Units) != cfg.UnitsPerClan {
+			t.Errorf("%s: got %d units, want %d", r.Name(), len(rx.Units), cfg.UnitsPerClan)
+		}
+		for _, u := range rx.Units {
+			for _, mb := range model.MoveBudgets(u, model.DefaultWorldRules) {
+				if mb.Issues != nil || mb.Spent > 15 {
+					t.Errorf("%s: %s: budget = %+v", r.Name(), u.UnitID, mb)
+				}
+				moved++
+			}
+		}
+	}
+	if moved == 0 || moved != moves {
+		t.Errorf("parsed %d moves, want %d", moved, moves)
+	}
+
+	again := 0
+	if err := synth.Generate(cfg, func(r synth.Report) error {
+		if !bytes.Equal(r.Text, reports[again].Text) {
+			t.Errorf("%s: differs with the same seed", r.Name())
+		}
+		again++
+		return nil
+	}); err != nil {
+		t.Fatalf("generate again: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Middleware records a server span for each request, named for the method
+// and the route pattern that handled it (e.g., "GET /units/{id}"). A request
+// with a W3C traceparent header continues the caller's trace. Handlers add
+// attributes with FromContext(r.Context()).SetAttrs.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := r.Context()
+		if parent, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+			ctx = context.WithValue(ctx, ctxKey{}, parent)
+		}
+		ctx, span := start(ctx, r.Method, KindServer, []Attr{
+			String("http.request.method", r.Method),
+			String("url.path", r.URL.Path),
+		})
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		r = r.WithContext(ctx)
+		next.ServeHTTP(sw, r)
+
+		// the mux records the pattern on the request it was given
+		if r.Pattern != "" {
+			span.name = r.Pattern
+			if !strings.HasPrefix(r.Pattern, r.Method+" ") {
+				span.name = r.Method + " " + r.Pattern
+			}
+			span.SetAttrs(String("http.route", r.Pattern))
+		}
+		span.SetAttrs(Int("http.response.status_code", int64(sw.status)))
+		if sw.status >= 500 {
+			span.err = http.StatusText(sw.status)
+		}
+	})
+}
+
+// statusWriter remembers the response status for the span.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, for
+// flushing server-sent events.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush flushes the underlying writer if it can be flushed.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// parseTraceparent reads a version 00 traceparent header
+// ("00-<trace id>-<parent id>-<flags>") into a remote parent span.
+func parseTraceparent(h string) (*Span, bool) {
+	parts := strings.Split(h, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return nil, false
+	}
+	var s Span
+	if _, err := hex.Decode(s.traceID[:], []byte(parts[1])); err != nil || s.traceID == [16]byte{} {
+		return nil, false
+	}
+	if _, err := hex.Decode(s.spanID[:], []byte(parts[2])); err != nil || s.spanID == [8]byte{} {
+		return nil, false
+	}
+	return &s, true
+}
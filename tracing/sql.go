@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+)
+
+// maxStatement is the longest db.query.text recorded.
+const maxStatement = 1024
+
+// OpenDB opens a database like sql.Open, but records a client span for each
+// query and exec made on a context that carries a span, so that store
+// queries show up under the request or pipeline stage that made them.
+// Queries made outside of a span are not recorded. Statements prepared with
+// Prepare aren't traced.
+func OpenDB(driverName, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	db.Close()
+	return sql.OpenDB(&connector{driver: drv, system: driverName, dsn: dsn}), nil
+}
+
+type connector struct {
+	driver driver.Driver
+	system string
+	dsn    string
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn driver.Conn
+	var err error
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		var inner driver.Connector
+		if inner, err = dc.OpenConnector(c.dsn); err == nil {
+			conn, err = inner.Connect(ctx)
+		}
+	} else {
+		conn, err = c.driver.Open(c.dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, system: c.system}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// tracedConn passes everything through to the driver's connection. The
+// driver must support contexts (modernc.org/sqlite does).
+type tracedConn struct {
+	driver.Conn
+	system string
+}
+
+func (c *tracedConn) span(ctx context.Context, op, query string) (context.Context, *Span) {
+	if FromContext(ctx) == nil {
+		return ctx, nil
+	}
+	query = strings.TrimSpace(query)
+	if len(query) > maxStatement {
+		query = query[:maxStatement]
+	}
+	return start(ctx, c.system+" "+op, KindClient, []Attr{
+		String("db.system", c.system),
+		String("db.operation.name", op),
+		String("db.query.text", query),
+	})
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.span(ctx, "query", query)
+	defer span.End()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		span.SetError(err)
+	}
+	return rows, err
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.span(ctx, "exec", query)
+	defer span.End()
+	result, err := ec.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		span.SetError(err)
+	}
+	return result, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return pc.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
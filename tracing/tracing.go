@@ -0,0 +1,203 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Package tracing records OpenTelemetry spans for HTTP requests, store
+// queries, and pipeline stages, and exports them to a collector with the
+// OTLP/HTTP JSON protocol, to debug latency in hosted deployments.
+//
+// Tracing is off unless Setup is given an endpoint; see ConfigFromEnv for
+// the environment variables it reads. When it is off, Start returns a nil
+// *Span, and every Span method is a no-op on nil, so callers don't check.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Attr is a span attribute.
+type Attr struct {
+	Key   string
+	Value any // string, int, int64, or bool
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int64) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Kinds of span, as numbered by OTLP.
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// Span is a timed operation in a trace.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte // zero for a root span
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    []Attr
+	err      string
+	ended    atomic.Bool
+	exporter *exporter
+}
+
+type ctxKey struct{}
+
+// FromContext returns the span carried by ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(ctxKey{}).(*Span)
+	return s
+}
+
+// Enabled reports whether spans are being recorded.
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Start begins a span that is a child of the span in ctx, if there is one,
+// and returns a copy of ctx that carries it. If tracing is off, it returns
+// ctx and a nil span.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return start(ctx, name, KindInternal, attrs)
+}
+
+func start(ctx context.Context, name string, kind int, attrs []Attr) (context.Context, *Span) {
+	e := current.Load()
+	if e == nil {
+		return ctx, nil
+	}
+	s := &Span{name: name, kind: kind, start: time.Now(), attrs: attrs, exporter: e}
+	if parent := FromContext(ctx); parent != nil {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	return context.WithValue(ctx, ctxKey{}, s), s
+}
+
+// SetAttrs adds attributes to the span.
+func (s *Span) SetAttrs(attrs ...Attr) {
+	if s == nil || s.ended.Load() {
+		return
+	}
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks the span as failed. A nil err is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil || s.ended.Load() {
+		return
+	}
+	s.err = err.Error()
+}
+
+// End ends the span and queues it for export. Only the first call counts.
+func (s *Span) End() {
+	if s == nil || s.ended.Swap(true) {
+		return
+	}
+	s.end = time.Now()
+	s.exporter.enqueue(s)
+}
+
+// TraceID returns the span's trace id in hex, or "" for a nil span.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// Config is where spans are exported to.
+type Config struct {
+	Endpoint    string            // OTLP/HTTP traces URL, e.g. "http://localhost:4318/v1/traces"; empty turns tracing off
+	Headers     map[string]string // sent with every export, e.g. an API key
+	ServiceName string            // the service.name resource attribute
+	Timeout     time.Duration     // for each export request
+}
+
+// ConfigFromEnv reads the standard OpenTelemetry environment variables:
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT (or OTEL_EXPORTER_OTLP_ENDPOINT, with
+// /v1/traces added), OTEL_EXPORTER_OTLP_HEADERS, OTEL_EXPORTER_OTLP_TIMEOUT
+// (milliseconds), OTEL_SERVICE_NAME, and OTEL_TRACES_EXPORTER, which turns
+// tracing off when it is "none". Only the http/json protocol is supported.
+func ConfigFromEnv(serviceName string) (Config, error) {
+	cfg := Config{ServiceName: serviceName, Timeout: 10 * time.Second}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		cfg.ServiceName = name
+	}
+	if os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return cfg, nil
+	}
+	if protocol := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); protocol != "" && protocol != "http/json" {
+		return cfg, fmt.Errorf("OTEL_EXPORTER_OTLP_PROTOCOL %q: only http/json is supported", protocol)
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		cfg.Endpoint = endpoint
+	} else if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		cfg.Endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+	if cfg.Endpoint != "" {
+		if u, err := url.Parse(cfg.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return cfg, fmt.Errorf("OTLP endpoint %q: must be an http or https URL", cfg.Endpoint)
+		}
+	}
+	if headers := os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"); headers != "" {
+		cfg.Headers = map[string]string{}
+		for _, kv := range strings.Split(headers, ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return cfg, fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS: %q is not key=value", kv)
+			}
+			k, _ = url.QueryUnescape(strings.TrimSpace(k))
+			v, _ = url.QueryUnescape(strings.TrimSpace(v))
+			cfg.Headers[k] = v
+		}
+	}
+	if ms := os.Getenv("OTEL_EXPORTER_OTLP_TIMEOUT"); ms != "" {
+		var n int
+		if _, err := fmt.Sscanf(ms, "%d", &n); err != nil || n <= 0 {
+			return cfg, fmt.Errorf("OTEL_EXPORTER_OTLP_TIMEOUT %q: must be a number of milliseconds", ms)
+		}
+		cfg.Timeout = time.Duration(n) * time.Millisecond
+	}
+	return cfg, nil
+}
+
+// current is the exporter spans are sent to; nil when tracing is off.
+var current atomic.Pointer[exporter]
+
+// Setup starts exporting spans as cfg says and returns a function that
+// flushes the spans not yet exported and turns tracing off again. If
+// cfg.Endpoint is empty, tracing stays off and shutdown does nothing.
+func Setup(cfg Config) (shutdown func(context.Context) error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }
+	}
+	e := newExporter(cfg)
+	current.Store(e)
+	go e.run()
+	return func(ctx context.Context) error {
+		current.CompareAndSwap(e, nil)
+		return e.shutdown(ctx)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mdhender/tnrpt/tracing"
+	_ "modernc.org/sqlite"
+)
+
+// span is the part of an exported OTLP span the test checks.
+type span struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Attributes   []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue string `json:"stringValue"`
+			IntValue    string `json:"intValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+	Status *struct {
+		Code int `json:"code"`
+	} `json:"status"`
+}
+
+func (s span) attr(key string) string {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			return a.Value.StringValue + a.Value.IntValue
+		}
+	}
+	return ""
+}
+
+func TestTracing(t *testing.T) {
+	var mu sync.Mutex
+	spans := map[string]span{}
+	var apiKey, service string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				Resource struct {
+					Attributes []struct {
+						Value struct {
+							StringValue string `json:"stringValue"`
+						} `json:"value"`
+					} `json:"attributes"`
+				} `json:"resource"`
+				ScopeSpans []struct {
+					Spans []span `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if r.URL.Path != "/v1/traces" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		apiKey = r.Header.Get("x-api-key")
+		for _, rs := range req.ResourceSpans {
+			service = rs.Resource.Attributes[0].Value.StringValue
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					spans[s.Name] = s
+				}
+			}
+		}
+	}))
+	defer collector.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL+"/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-api-key=s%20cret")
+	cfg, err := tracing.ConfigFromEnv("tnrpt-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdown := tracing.Setup(cfg)
+
+	db, err := tracing.OpenDB("sqlite", "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE units (id TEXT)"); err != nil { // outside of any span
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /units/{id}", func(w http.ResponseWriter, r *http.Request) {
+		tracing.FromContext(r.Context()).SetAttrs(tracing.String("game", "0301"))
+		ctx, span := tracing.Start(r.Context(), "load unit", tracing.Int("report_file_id", 42))
+		defer span.End()
+		var n int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM units WHERE id = ?", r.PathValue("id")).Scan(&n); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req := httptest.NewRequest(http.MethodGet, "/units/0987", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	tracing.Middleware(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if tracing.Enabled() {
+		t.Errorf("shutdown: tracing still enabled")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if apiKey != "s cret" || service != "tnrpt-test" {
+		t.Errorf("export: got key %q, service %q", apiKey, service)
+	}
+	if len(spans) != 3 {
+		t.Fatalf("spans: got %d %v, want the request, its child, and the query", len(spans), spans)
+	}
+	server, child, query := spans["GET /units/{id}"], spans["load unit"], spans["sqlite query"]
+	if server.TraceID != traceID || server.ParentSpanID != parentID || server.Kind != tracing.KindServer {
+		t.Errorf("server span: got %+v, want it to continue the caller's trace", server)
+	}
+	if server.attr("game") != "0301" || server.attr("http.response.status_code") != "404" || server.Status != nil {
+		t.Errorf("server span: got %+v", server)
+	}
+	if child.TraceID != traceID || child.ParentSpanID != server.SpanID || child.attr("report_file_id") != "42" {
+		t.Errorf("child span: got %+v", child)
+	}
+	if query.ParentSpanID != child.SpanID || query.Kind != tracing.KindClient || query.attr("db.query.text") != "SELECT COUNT(*) FROM units WHERE id = ?" {
+		t.Errorf("query span: got %+v", query)
+	}
+}
+
+func TestTracingOff(t *testing.T) {
+	ctx, span := tracing.Start(context.Background(), "nothing")
+	if span != nil || tracing.FromContext(ctx) != nil {
+		t.Fatalf("got a span with tracing off")
+	}
+	span.SetAttrs(tracing.String("game", "0301")) // no-ops on nil
+	span.End()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318")
+	if _, err := tracing.ConfigFromEnv("test"); err == nil {
+		t.Errorf("endpoint without a scheme: want an error")
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	if _, err := tracing.ConfigFromEnv("test"); err == nil {
+		t.Errorf("grpc protocol: want an error")
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "")
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	if cfg, err := tracing.ConfigFromEnv("test"); err != nil || cfg.Endpoint != "" {
+		t.Errorf("OTEL_TRACES_EXPORTER=none: got %+v, %v; want tracing off", cfg, err)
+	}
+}
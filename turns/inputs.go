@@ -8,11 +8,13 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+
+	"github.com/mdhender/tnrpt/model"
 )
 
 var (
 	// turn report files have names that match the pattern YEAR-MONTH.CLAN_ID.report.txt.
-	rxTurnReportFile = regexp.MustCompile(`^(\d{3,4})-(\d{2})\.(0\d{3})\.report\.txt$`)
+	rxTurnReportFile = regexp.MustCompile(`^(\d{3,4})-(\d{2})\.(` + model.ClanPattern + `)\.report\.txt$`)
 )
 
 // TurnReportFile_t represents a turn report file.
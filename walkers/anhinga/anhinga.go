@@ -3,19 +3,42 @@
 package anhinga
 
 import (
-	"log"
+	"context"
+	"fmt"
 	"sort"
 
+	"github.com/maloquacious/hexg"
 	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/coords"
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/edges"
+	"github.com/mdhender/tnrpt/logging"
 	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/results"
 	"github.com/mdhender/tnrpt/steppers"
+	"github.com/mdhender/tnrpt/terrain"
 )
 
 type Walker struct{}
 
-func Walk(input *tnrpt.Turn_t, nav steppers.Stepper, quiet, verbose, debug bool) ([]*model.Tile, error) {
+// Walk places every unit's moves on the map by walking them backwards from the
+// hex the unit ended the turn in. It returns one tile per move, holding the hex
+// the unit was in after the move, ordered by unit id and then move.
+//
+// If nav is nil, the TribeNet layout is used.
+//
+// Only successful advances change hexes. A follow or goes-to move relocates the
+// unit without a path, so moves before it can't be placed and are skipped.
+// It returns an error if walking back from a unit's current hex doesn't arrive
+// at its previous hex (when the report gives one), or if a unit made an
+// advance it couldn't have (see checkCrossings).
+// Progress is logged through the logger carried by ctx.
+func Walk(ctx context.Context, input *tnrpt.Turn_t, nav steppers.Stepper, quiet, verbose, debug bool) ([]*model.Tile, error) {
 	if !quiet {
-		log.Printf("anhinga: walking %q\n", input.Source)
+		logging.FromContext(ctx).Info("anhinga: walking", logging.KeyFile, input.Source)
+	}
+	if nav == nil {
+		nav = coords.NewTribeNetLayout()
 	}
 
 	var unitMoves []*tnrpt.Moves_t
@@ -27,29 +50,160 @@ func Walk(input *tnrpt.Turn_t, nav steppers.Stepper, quiet, verbose, debug bool)
 		return a.UnitId < b.UnitId
 	})
 
+	var tiles []*model.Tile
 	for _, unit := range unitMoves {
-		err := walkMoves(nav, unit)
+		unitTiles, err := walkMoves(ctx, nav, unit, debug)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%s: %w", unit.UnitId, err)
 		}
+		tiles = append(tiles, unitTiles...)
+	}
+	if verbose {
+		logging.FromContext(ctx).Info("anhinga: placed moves", logging.KeyFile, input.Source, "moves", len(tiles))
 	}
 
-	return nil, nil
+	return tiles, nil
 }
 
-func walkMoves(nav steppers.Stepper, moves *tnrpt.Moves_t) error {
+func walkMoves(ctx context.Context, nav steppers.Stepper, moves *tnrpt.Moves_t, debug bool) ([]*model.Tile, error) {
 	currentHex, err := nav.CoordToHex(model.TNCoord(moves.CurrentHex))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("current hex %q: %w", moves.CurrentHex, err)
+	}
+	if debug {
+		logging.FromContext(ctx).Debug("anhinga: current hex", "unit", moves.UnitId, "coord", moves.CurrentHex, "hex", currentHex.ConciseString())
 	}
-	log.Printf("walk: %s: curr %s: %q\n", moves.UnitId, moves.CurrentHex, currentHex.ConciseString())
 
-	// walk all moves backwards
+	// walk all moves backwards, placing each in the hex it ended in
+	tiles := make([]*model.Tile, len(moves.Moves))
+	hex, first := currentHex, 0
 	for i := len(moves.Moves) - 1; i >= 0; i-- {
 		move := moves.Moves[i]
-		log.Printf("walk: %s: %3d: %4d %3d\n", moves.UnitId, i+1, move.LineNo, move.StepNo)
+		tile := &model.Tile{
+			Hex: hex,
+			Src: []*model.TileSrc{{UnitID: string(moves.UnitId), StepSeq: move.StepNo, Note: move.Line}},
+		}
+		if move.Report != nil {
+			if move.Report.Terrain != terrain.Blank {
+				tile.Terr = move.Report.Terrain.String()
+			}
+			for _, b := range move.Report.Borders {
+				obs := &model.BorderObs{Dir: b.Direction.String()}
+				if b.Edge != edges.None {
+					obs.Kind = b.Edge.String()
+				} else if b.Terrain != terrain.Blank {
+					obs.Kind = b.Terrain.String()
+				}
+				tile.Borders = append(tile.Borders, obs)
+			}
+		}
+		tiles[i] = tile
+		if debug {
+			logging.FromContext(ctx).Debug("anhinga: move", "unit", moves.UnitId, "move", i+1, "line", move.LineNo, "step", move.StepNo, "hex", hex.ConciseString())
+		}
+
+		if move.Follows != "" || move.GoesToHex != "" {
+			first = i // nothing before a relocation can be placed
+			break
+		}
+		if move.Advance != direction.Unknown && move.Result == results.Succeeded {
+			prev, ok := nav.StepBackwardHex(hex, move.Advance.String())
+			if !ok {
+				return nil, fmt.Errorf("line %d: step %d: invalid direction %q", move.LineNo, move.StepNo, move.Advance)
+			}
+			hex = prev
+		}
+	}
+	if debug {
+		logging.FromContext(ctx).Debug("anhinga: previous hex", "unit", moves.UnitId, "coord", moves.PreviousHex, "hex", hex.ConciseString())
+	}
+
+	if err := checkCrossings(nav, moves, tiles, first, hex); err != nil {
+		return nil, err
+	}
+
+	if first == 0 && !model.TNCoord(moves.PreviousHex).IsUnknown() {
+		previousHex, err := nav.CoordToHex(model.TNCoord(moves.PreviousHex))
+		if err != nil {
+			return nil, fmt.Errorf("previous hex %q: %w", moves.PreviousHex, err)
+		}
+		if previousHex != hex {
+			walkedTo, _ := nav.HexToCoord(hex)
+			return nil, fmt.Errorf("walked back to %s, want previous hex %s", walkedTo, moves.PreviousHex)
+		}
 	}
-	log.Printf("walk: %s: prev %s: %q\n", moves.UnitId, moves.PreviousHex, currentHex.ConciseString())
 
+	return tiles[first:], nil
+}
+
+// side is the edge of a hex in a direction.
+type side struct {
+	hex hexg.Hex
+	dir direction.Direction_e
+}
+
+// checkCrossings replays the unit's placed moves forward from start, the hex
+// it was in before tiles[first], and returns an error for an advance it
+// couldn't have made: across a river with no ford, or into water for any unit
+// but a fleet. Rivers and fords come from every border the unit reported this
+// turn, seen from either side of the edge. Passes, canals, and roads never
+// block. A step that was blocked is reported as failed and doesn't move the
+// unit, so it needs no check here.
+func checkCrossings(nav steppers.Stepper, moves *tnrpt.Moves_t, tiles []*model.Tile, first int, start hexg.Hex) error {
+	seen := map[side]map[edges.Edge_e]bool{}
+	for i := first; i < len(moves.Moves); i++ {
+		move := moves.Moves[i]
+		if move.Report == nil {
+			continue
+		}
+		for _, b := range move.Report.Borders {
+			if b.Edge == edges.None || b.Direction == direction.Unknown {
+				continue
+			}
+			here := side{tiles[i].Hex, b.Direction}
+			if seen[here] == nil {
+				seen[here] = map[edges.Edge_e]bool{}
+			}
+			seen[here][b.Edge] = true
+			if there, ok := nav.StepForwardHex(tiles[i].Hex, b.Direction.String()); ok {
+				back := side{there, opposite[b.Direction]}
+				if seen[back] == nil {
+					seen[back] = map[edges.Edge_e]bool{}
+				}
+				seen[back][b.Edge] = true
+			}
+		}
+	}
+
+	fleet := isFleet(string(moves.UnitId))
+	hex := start
+	for i := first; i < len(moves.Moves); i++ {
+		move := moves.Moves[i]
+		if move.Advance != direction.Unknown && move.Result == results.Succeeded {
+			crossed := seen[side{hex, move.Advance}]
+			if crossed[edges.River] && !crossed[edges.Ford] {
+				return fmt.Errorf("line %d: step %d: crossed a river to the %s with no ford", move.LineNo, move.StepNo, move.Advance)
+			}
+			if !fleet && move.Report != nil && move.Report.Terrain.IsAnyWater() {
+				return fmt.Errorf("line %d: step %d: moved %s into %s, which only fleets can do", move.LineNo, move.StepNo, move.Advance, move.Report.Terrain.String())
+			}
+		}
+		hex = tiles[i].Hex
+	}
 	return nil
 }
+
+// opposite is the direction back across the edge a unit crossed.
+var opposite = map[direction.Direction_e]direction.Direction_e{
+	direction.North:     direction.South,
+	direction.NorthEast: direction.SouthWest,
+	direction.SouthEast: direction.NorthWest,
+	direction.South:     direction.North,
+	direction.SouthWest: direction.NorthEast,
+	direction.NorthWest: direction.SouthEast,
+}
+
+// isFleet reports whether the unit ID is a fleet's, e.g. "0987f1".
+func isFleet(unitID string) bool {
+	return len(unitID) >= 2 && unitID[len(unitID)-2] == 'f'
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package anhinga_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/coords"
+	"github.com/mdhender/tnrpt/direction"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/parsers/azul"
+	"github.com/mdhender/tnrpt/results"
+	"github.com/mdhender/tnrpt/terrain"
+	"github.com/mdhender/tnrpt/walkers/anhinga"
+)
+
+// script builds a unit's moves from a space-separated list of steps:
+//
+//	N         a successful advance to the north
+//	!NE       a failed advance to the northeast
+//	SE:PR     a successful advance into prairie
+//	still     the unit stays in place
+//	follows=X the unit follows unit X
+//
+// from and to are the unit's previous and current hexes.
+func script(unit, from, to, steps string) *tnrpt.Moves_t {
+	moves := &tnrpt.Moves_t{UnitId: tnrpt.UnitId_t(unit), PreviousHex: from, CurrentHex: to}
+	for n, tok := range strings.Fields(steps) {
+		move := &tnrpt.Move_t{LineNo: 1, StepNo: n + 1, Line: tok, Result: results.Succeeded}
+		if dir, terr, ok := strings.Cut(tok, ":"); ok {
+			move.Report = &tnrpt.Report_t{Terrain: terrain.StringToEnum[terr]}
+			tok = dir
+		}
+		switch {
+		case tok == "still":
+			move.Still = true
+		case strings.HasPrefix(tok, "follows="):
+			move.Follows = tnrpt.UnitId_t(strings.TrimPrefix(tok, "follows="))
+		case strings.HasPrefix(tok, "!"):
+			move.Advance, move.Result = direction.StringToEnum[tok[1:]], results.Failed
+		default:
+			move.Advance = direction.StringToEnum[tok]
+		}
+		moves.Moves = append(moves.Moves, move)
+	}
+	return moves
+}
+
+// forward replays the moves from the previous hex with WorldRules.Neighbor,
+// which doesn't share any code with the walker, and returns the hex after each move.
+func forward(world model.WorldRules, moves *tnrpt.Moves_t) ([]string, error) {
+	var coords []string
+	at := model.TNCoord(moves.PreviousHex)
+	for _, move := range moves.Moves {
+		if move.Advance != direction.Unknown && move.Result == results.Succeeded {
+			next, err := world.Neighbor(at, move.Advance)
+			if err != nil {
+				return nil, err
+			}
+			at = next
+		}
+		coords = append(coords, string(at))
+	}
+	return coords, nil
+}
+
+func TestWalk(t *testing.T) {
+	nav := coords.NewTribeNetLayout()
+	for _, tc := range []struct {
+		name  string
+		moves *tnrpt.Moves_t
+		want  []string // hex after each move
+		terr  []string // terrain after each move, if checked
+	}{
+		{name: "stays put",
+			moves: script("0138", "QQ 1510", "QQ 1510", "still"),
+			want:  []string{"QQ 1510"}},
+		{name: "straight line",
+			moves: script("0138", "QQ 1510", "QQ 1507", "N N N"),
+			want:  []string{"QQ 1509", "QQ 1508", "QQ 1507"}},
+		{name: "failed step",
+			moves: script("0138", "QQ 1510", "QQ 1509", "N !N"),
+			want:  []string{"QQ 1509", "QQ 1509"}},
+		{name: "terrain",
+			moves: script("0138e1", "QQ 1510", "QQ 1609", "NE:PR !NE:PR"),
+			want:  []string{"QQ 1609", "QQ 1609"},
+			terr:  []string{"PR", "PR"}},
+		{name: "east edge, NE",
+			moves: script("0138", "QQ 3010", "QR 0110", "NE"),
+			want:  []string{"QR 0110"}},
+		{name: "east edge, SE",
+			moves: script("0138", "QQ 3010", "QR 0111", "SE"),
+			want:  []string{"QR 0111"}},
+		{name: "east edge and back",
+			moves: script("0138", "QQ 3010", "QQ 3010", "SE NW"),
+			want:  []string{"QR 0111", "QQ 3010"}},
+		{name: "west edge, NW",
+			moves: script("0138", "QQ 0105", "QP 3004", "NW"),
+			want:  []string{"QP 3004"}},
+		{name: "south edge, S",
+			moves: script("0138", "QQ 2921", "RQ 2901", "S"),
+			want:  []string{"RQ 2901"}},
+		{name: "north edge, N",
+			moves: script("0138", "QQ 1501", "PQ 1521", "N"),
+			want:  []string{"PQ 1521"}},
+		{name: "corner, S then SE",
+			moves: script("0138", "QQ 2921", "RQ 3001", "S SE"),
+			want:  []string{"RQ 2901", "RQ 3001"}},
+		{name: "follows",
+			moves: script("0138c1", "N/A", "QQ 1508", "N follows=0138 N"),
+			want:  []string{"QQ 1509", "QQ 1508"}}, // the follow and the step after it
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &tnrpt.Turn_t{Source: tc.name, UnitMoves: map[tnrpt.UnitId_t]*tnrpt.Moves_t{tc.moves.UnitId: tc.moves}}
+			tiles, err := anhinga.Walk(context.Background(), input, nav, true, false, false)
+			if err != nil {
+				t.Fatalf("walk: %v", err)
+			}
+			var got []string
+			for _, tile := range tiles {
+				coord, err := nav.HexToCoord(tile.Hex)
+				if err != nil {
+					t.Fatalf("hex to coord: %v", err)
+				}
+				got = append(got, string(coord))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+			if tc.terr != nil {
+				var terr []string
+				for _, tile := range tiles {
+					terr = append(terr, tile.Terr)
+				}
+				if fmt.Sprint(terr) != fmt.Sprint(tc.terr) {
+					t.Errorf("terrain: got %v, want %v", terr, tc.terr)
+				}
+			}
+
+			// check the table against the model's own grid arithmetic
+			if model.TNCoord(tc.moves.PreviousHex).IsUnknown() {
+				return
+			}
+			replay, err := forward(model.DefaultWorldRules, tc.moves)
+			if err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			if fmt.Sprint(replay) != fmt.Sprint(tc.want) {
+				t.Errorf("replay: got %v, want %v", replay, tc.want)
+			}
+		})
+	}
+}
+
+func TestWalkWrappedWorld(t *testing.T) {
+	world := model.WorldRules{GridRows: 2, GridCols: 2, WrapRows: true, WrapCols: true}
+	nav := coords.NewTribeNetLayoutFor(world)
+	for _, tc := range []struct {
+		name  string
+		moves *tnrpt.Moves_t
+		want  []string // hex after each move
+	}{
+		{name: "west edge",
+			moves: script("0138", "AA 0105", "AB 3004", "NW"),
+			want:  []string{"AB 3004"}},
+		{name: "north edge",
+			moves: script("0138", "AA 1501", "BA 1521", "N"),
+			want:  []string{"BA 1521"}},
+		{name: "corner and back",
+			moves: script("0138", "BB 3021", "BB 3021", "SE NW"),
+			want:  []string{"AA 0101", "BB 3021"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &tnrpt.Turn_t{Source: tc.name, UnitMoves: map[tnrpt.UnitId_t]*tnrpt.Moves_t{tc.moves.UnitId: tc.moves}}
+			tiles, err := anhinga.Walk(context.Background(), input, nav, true, false, false)
+			if err != nil {
+				t.Fatalf("walk: %v", err)
+			}
+			var got []string
+			for _, tile := range tiles {
+				coord, err := nav.HexToCoord(tile.Hex)
+				if err != nil {
+					t.Fatalf("hex to coord: %v", err)
+				}
+				got = append(got, string(coord))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+			replay, err := forward(world, tc.moves)
+			if err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			if fmt.Sprint(replay) != fmt.Sprint(tc.want) {
+				t.Errorf("replay: got %v, want %v", replay, tc.want)
+			}
+		})
+	}
+
+	// the same step off the edge of a world that doesn't wrap
+	input := &tnrpt.Turn_t{UnitMoves: map[tnrpt.UnitId_t]*tnrpt.Moves_t{"0138": script("0138", "AA 0105", "AB 3004", "NW")}}
+	if _, err := anhinga.Walk(context.Background(), input, coords.NewTribeNetLayoutFor(model.WorldRules{GridRows: 2, GridCols: 2}), true, false, false); err == nil {
+		t.Errorf("no wrap: want error, got nil")
+	}
+	// a hex outside the world
+	input = &tnrpt.Turn_t{UnitMoves: map[tnrpt.UnitId_t]*tnrpt.Moves_t{"0138": script("0138", "CC 0105", "CC 0104", "N")}}
+	if _, err := anhinga.Walk(context.Background(), input, nav, true, false, false); err == nil {
+		t.Errorf("outside the world: want error, got nil")
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		moves *tnrpt.Moves_t
+	}{
+		{name: "does not reach previous hex", moves: script("0138", "QQ 1510", "QQ 1507", "N N")},
+		{name: "invalid current hex", moves: script("0138", "QQ 1510", "QQ 3122", "N")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &tnrpt.Turn_t{Source: tc.name, UnitMoves: map[tnrpt.UnitId_t]*tnrpt.Moves_t{tc.moves.UnitId: tc.moves}}
+			if _, err := anhinga.Walk(context.Background(), input, nil, true, false, false); err == nil {
+				t.Errorf("walk: want error, got nil")
+			}
+		})
+	}
+}
+
+func TestWalkOrdersUnits(t *testing.T) {
+	input := &tnrpt.Turn_t{UnitMoves: map[tnrpt.UnitId_t]*tnrpt.Moves_t{}}
+	for _, moves := range []*tnrpt.Moves_t{
+		script("0138e1", "QQ 1510", "QQ 1509", "N"),
+		script("0138", "QQ 1510", "QQ 1511", "S still"),
+	} {
+		input.UnitMoves[moves.UnitId] = moves
+	}
+	tiles, err := anhinga.Walk(context.Background(), input, nil, true, false, false)
+	if err != nil {
+		t.Fatalf("walk: %v", err)
+	}
+	var got []string
+	for _, tile := range tiles {
+		got = append(got, fmt.Sprintf("%s/%d", tile.Src[0].UnitID, tile.Src[0].StepSeq))
+	}
+	if want := "[0138/1 0138/2 0138e1/1]"; fmt.Sprint(got) != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+}
+
+// reportTurn parses a tribe's report section from its header, movement line,
+// and status line.
+func reportTurn(t *testing.T, from, to, movement, status string) *tnrpt.Turn_t {
+	t.Helper()
+	text := fmt.Sprintf("Tribe 0987, , Current Hex = %s, (Previous Hex = %s)\n"+
+		"Current Turn 899-12 (#0), Winter, FINE Next Turn 900-01 (#1), 28/11/2025\n"+
+		"Tribe Movement: Move %s\n"+
+		"0987 Status: %s\n", to, from, movement, status)
+	pt, err := azul.ParseInput("test", "", []byte(text), false, false, false, false, false, false, false, false, azul.ParseConfig{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	turn, err := adapters.AzulParserTurnToModel("test", pt)
+	if err != nil {
+		t.Fatalf("adapt: %v", err)
+	}
+	return turn
+}
+
+func TestWalkRiversAndWater(t *testing.T) {
+	nav := coords.NewTribeNetLayout()
+	for _, tc := range []struct {
+		name     string
+		from, to string
+		movement string
+		status   string
+		want     []string // hex after each move
+		wantErr  string   // part of the error, if the walk fails
+	}{
+		{name: "ford crosses river",
+			from: "QQ 1010", to: "QQ 1008",
+			movement: `N-PR, , L SW,River N NE,Ford N\N-PR`, status: "PRAIRIE",
+			want: []string{"QQ 1009", "QQ 1008", "QQ 1008"}},
+		{name: "ford seen from the far side",
+			from: "QQ 1010", to: "QQ 1008",
+			movement: `N-PR, , L SW,River N NE\N-PR,River S,Ford S`, status: "PRAIRIE",
+			want: []string{"QQ 1009", "QQ 1008", "QQ 1008"}},
+		{name: "no ford stops the unit",
+			from: "QQ 1010", to: "QQ 1009",
+			movement: `N-PR, , L SW,River N NE\,No Ford on River to N of HEX`, status: "PRAIRIE",
+			want: []string{"QQ 1009", "QQ 1009", "QQ 1009"}},
+		{name: "pass doesn't block",
+			from: "QQ 1010", to: "QQ 1008",
+			movement: `N-PR,Pass N\N-LCM`, status: "LOW CONIFER MOUNTAINS",
+			want: []string{"QQ 1009", "QQ 1008", "QQ 1008"}},
+		{name: "crossed river with no ford",
+			from: "QQ 1010", to: "QQ 1008",
+			movement: `N-PR, , L SW,River N NE\N-PR`, status: "PRAIRIE",
+			wantErr: "step 2: crossed a river to the N with no ford"},
+		{name: "river seen from the far side",
+			from: "QQ 1010", to: "QQ 1008",
+			movement: `N-PR\N-PR,River S`, status: "PRAIRIE",
+			wantErr: "step 2: crossed a river to the N with no ford"},
+		{name: "tribe into the ocean",
+			from: "QQ 1010", to: "QQ 1009",
+			movement: `N-O`, status: "OCEAN",
+			wantErr: "only fleets"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			turn := reportTurn(t, tc.from, tc.to, tc.movement, tc.status)
+			tiles, err := anhinga.Walk(context.Background(), turn, nav, true, false, false)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("walk: got error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("walk: %v", err)
+			}
+			var got []string
+			for _, tile := range tiles {
+				coord, err := nav.HexToCoord(tile.Hex)
+				if err != nil {
+					t.Fatalf("hex to coord: %v", err)
+				}
+				got = append(got, string(coord))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -3,104 +3,363 @@
 package auth
 
 import (
-	"crypto/rand"
-	"encoding/hex"
+	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/mdhender/tnrpt/clock"
+	"github.com/mdhender/tnrpt/logging"
 )
 
 type User struct {
 	Handle   string // user's unique handle (e.g., "xtc69", "clan0500")
 	UserName string // display name
 	GameID   string // active game context
-	ClanNo   int    // clan number in the active game
+	ClanNo   int    // clan number in the active game; the lowest if the user runs several
+	Clans    []int  // every clan the user runs in the active game, in clan order
 }
 
 type Session struct {
 	ID        string
+	Ref       string // public reference for listing and revoking; ID is the secret cookie value
 	User      User
+	CreatedAt time.Time
 	ExpiresAt time.Time
+	IP        string // client address when the session was created
+	UserAgent string
+}
+
+// SessionBackend keeps the sessions for a SessionStore. The default keeps
+// them in memory, so they end when the program exits; SetBackend replaces it
+// with one that persists them.
+type SessionBackend interface {
+	InsertSession(ctx context.Context, session *Session) error
+	// GetSession returns nil if there is no session with the ID, expired or not.
+	GetSession(ctx context.Context, id string) (*Session, error)
+	DeleteSession(ctx context.Context, id string) error
+	// SessionsForUser returns the user's sessions that expire after now, newest first.
+	SessionsForUser(ctx context.Context, handle string, now time.Time) ([]*Session, error)
+	// RevokeSession deletes the user's session with the given Ref.
+	// It returns false if the user has no such session.
+	RevokeSession(ctx context.Context, handle, ref string) (bool, error)
+	// RevokeSessions deletes every session the user has and returns how many there were.
+	RevokeSessions(ctx context.Context, handle string) (int, error)
 }
 
 type SessionStore struct {
-	mu       sync.RWMutex
-	sessions map[string]*Session
+	backend SessionBackend
+	clock   clock.Clock
+	ids     clock.IDs
+	cookie  CookieConfig
 }
 
 func NewSessionStore() *SessionStore {
 	return &SessionStore{
-		sessions: make(map[string]*Session),
+		backend: &memorySessions{sessions: make(map[string]*Session)},
+		clock:   clock.Real,
+		ids:     clock.Random,
+		cookie:  DefaultCookieConfig(),
 	}
 }
 
-func (s *SessionStore) Create(user User) *Session {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// SetBackend sets where sessions are kept. Call it before the store is used;
+// sessions already created are not carried over.
+func (s *SessionStore) SetBackend(b SessionBackend) {
+	s.backend = b
+}
 
-	id := generateSessionID()
+// SetClock sets the clock used to stamp and expire sessions, for testing.
+func (s *SessionStore) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetIDs sets the generator for session IDs and refs, for testing.
+func (s *SessionStore) SetIDs(ids clock.IDs) {
+	s.ids = ids
+}
+
+// Create starts a session for the user, recording the client's address and
+// user agent from r.
+func (s *SessionStore) Create(user User, r *http.Request) (*Session, error) {
+	now := s.clock.Now()
 	session := &Session{
-		ID:        id,
+		ID:        s.ids.NewID(32),
+		Ref:       s.ids.NewID(8),
 		User:      user,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.cookie.Lifetime),
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
 	}
-	s.sessions[id] = session
-	return session
+	if err := s.backend.InsertSession(r.Context(), session); err != nil {
+		return nil, err
+	}
+	return session, nil
 }
 
-func (s *SessionStore) Get(id string) *Session {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// Get returns the session with the ID, or nil if there is none or it has expired.
+func (s *SessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	session, err := s.backend.GetSession(ctx, id)
+	if err != nil || session == nil {
+		return nil, err
+	}
+	if s.clock.Now().After(session.ExpiresAt) {
+		return nil, nil
+	}
+	return session, nil
+}
 
-	session, ok := s.sessions[id]
-	if !ok {
-		return nil
+func (s *SessionStore) Delete(ctx context.Context, id string) error {
+	return s.backend.DeleteSession(ctx, id)
+}
+
+// ListForUser returns the user's unexpired sessions, newest first.
+func (s *SessionStore) ListForUser(ctx context.Context, handle string) ([]*Session, error) {
+	return s.backend.SessionsForUser(ctx, handle, s.clock.Now())
+}
+
+// Revoke deletes the user's session with the given Ref.
+// It returns false if the user has no such session.
+func (s *SessionStore) Revoke(ctx context.Context, handle, ref string) (bool, error) {
+	return s.backend.RevokeSession(ctx, handle, ref)
+}
+
+// RevokeAll deletes every session the user has and returns how many there were.
+func (s *SessionStore) RevokeAll(ctx context.Context, handle string) (int, error) {
+	return s.backend.RevokeSessions(ctx, handle)
+}
+
+// memorySessions is the default SessionBackend.
+type memorySessions struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+func (m *memorySessions) InsertSession(_ context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memorySessions) GetSession(_ context.Context, id string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sessions[id], nil
+}
+
+func (m *memorySessions) DeleteSession(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memorySessions) SessionsForUser(_ context.Context, handle string, now time.Time) ([]*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var list []*Session
+	for _, session := range m.sessions {
+		if session.User.Handle == handle && now.Before(session.ExpiresAt) {
+			list = append(list, session)
+		}
 	}
-	if time.Now().After(session.ExpiresAt) {
-		return nil
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+func (m *memorySessions) RevokeSession(_ context.Context, handle, ref string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, session := range m.sessions {
+		if session.Ref == ref && session.User.Handle == handle {
+			delete(m.sessions, id)
+			return true, nil
+		}
 	}
-	return session
+	return false, nil
 }
 
-func (s *SessionStore) Delete(id string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	delete(s.sessions, id)
+func (m *memorySessions) RevokeSessions(_ context.Context, handle string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for id, session := range m.sessions {
+		if session.User.Handle == handle {
+			delete(m.sessions, id)
+			n++
+		}
+	}
+	return n, nil
 }
 
-func generateSessionID() string {
-	b := make([]byte, 32)
-	rand.Read(b)
-	return hex.EncodeToString(b)
+// clientIP returns the host part of the request's remote address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
 
+// SessionCookieName is the default name of the session cookie.
 const SessionCookieName = "tnrpt_session"
 
-func SetSessionCookie(w http.ResponseWriter, session *Session) {
-	http.SetCookie(w, &http.Cookie{
+// Values for CookieConfig.Secure.
+const (
+	CookieSecureAuto   = "auto"   // secure when the request came over HTTPS
+	CookieSecureAlways = "always" // always secure, for servers behind a TLS proxy that doesn't say so
+	CookieSecureNever  = "never"  // never secure, for plain HTTP on a trusted network
+)
+
+// CookieConfig holds the attributes of the session cookie. Proxies that
+// serve the site under another host or over HTTPS may need them changed.
+type CookieConfig struct {
+	Name     string        // empty for SessionCookieName
+	Domain   string        // empty for a cookie sent only to the host that set it
+	Secure   string        // CookieSecureAuto, CookieSecureAlways, or CookieSecureNever; empty for auto
+	SameSite http.SameSite // zero for Lax
+	Lifetime time.Duration // how long a session lasts; zero for 24 hours
+}
+
+// DefaultCookieConfig returns the cookie settings a new SessionStore has.
+func DefaultCookieConfig() CookieConfig {
+	return CookieConfig{
 		Name:     SessionCookieName,
+		Secure:   CookieSecureAuto,
+		SameSite: http.SameSiteLaxMode,
+		Lifetime: 24 * time.Hour,
+	}
+}
+
+// ParseSameSite returns the SameSite mode for "lax", "strict", or "none".
+func ParseSameSite(s string) (http.SameSite, error) {
+	switch strings.ToLower(s) {
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	}
+	return 0, fmt.Errorf("same-site: %q is not lax, strict, or none", s)
+}
+
+// SetCookieConfig sets the session cookie attributes. Fields left at their
+// zero value keep the defaults. Browsers drop SameSite=None cookies that
+// aren't secure, so that pairing is an error.
+func (s *SessionStore) SetCookieConfig(c CookieConfig) error {
+	def := DefaultCookieConfig()
+	if c.Name == "" {
+		c.Name = def.Name
+	}
+	if c.Secure == "" {
+		c.Secure = def.Secure
+	}
+	if c.SameSite == 0 {
+		c.SameSite = def.SameSite
+	}
+	if c.Lifetime == 0 {
+		c.Lifetime = def.Lifetime
+	}
+	switch {
+	case c.Secure != CookieSecureAuto && c.Secure != CookieSecureAlways && c.Secure != CookieSecureNever:
+		return fmt.Errorf("cookie: secure %q is not auto, always, or never", c.Secure)
+	case c.Lifetime < 0:
+		return fmt.Errorf("cookie: lifetime must not be negative")
+	case c.SameSite == http.SameSiteNoneMode && c.Secure == CookieSecureNever:
+		return fmt.Errorf("cookie: same-site none requires a secure cookie")
+	case !validCookieName(c.Name):
+		return fmt.Errorf("cookie: invalid name %q", c.Name)
+	}
+	s.cookie = c
+	return nil
+}
+
+// CookieName returns the name of the session cookie.
+func (s *SessionStore) CookieName() string {
+	return s.cookie.Name
+}
+
+// SetCookie sets the session cookie on the response to r.
+func (s *SessionStore) SetCookie(w http.ResponseWriter, r *http.Request, session *Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.cookie.Name,
 		Value:    session.ID,
 		Path:     "/",
+		Domain:   s.cookie.Domain,
 		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
+		Secure:   s.secure(r),
+		SameSite: s.cookie.SameSite,
 		Expires:  session.ExpiresAt,
 	})
 }
 
-func ClearSessionCookie(w http.ResponseWriter) {
+// ClearCookie tells the browser to drop the session cookie.
+func (s *SessionStore) ClearCookie(w http.ResponseWriter, r *http.Request) {
 	http.SetCookie(w, &http.Cookie{
-		Name:     SessionCookieName,
+		Name:     s.cookie.Name,
 		Value:    "",
 		Path:     "/",
+		Domain:   s.cookie.Domain,
 		HttpOnly: true,
+		Secure:   s.secure(r),
+		SameSite: s.cookie.SameSite,
 		MaxAge:   -1,
 	})
 }
 
+// secure reports whether the cookie for r should be marked Secure. In auto
+// mode that is when r came over TLS or a proxy says it did with
+// X-Forwarded-Proto. A client can send that header itself, but all it gets
+// is a cookie its own plain HTTP requests won't carry.
+func (s *SessionStore) secure(r *http.Request) bool {
+	switch s.cookie.Secure {
+	case CookieSecureAlways:
+		return true
+	case CookieSecureNever:
+		return false
+	}
+	if r.TLS != nil {
+		return true
+	}
+	// proxies chaining the header list the client's scheme first
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// validCookieName reports whether name is a non-empty cookie token.
+func validCookieName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, c) {
+			return false
+		}
+	}
+	return true
+}
+
 func GetSessionFromRequest(r *http.Request, store *SessionStore) *Session {
-	cookie, err := r.Cookie(SessionCookieName)
+	cookie, err := r.Cookie(store.cookie.Name)
 	if err != nil {
 		return nil
 	}
-	return store.Get(cookie.Value)
+	session, err := store.Get(r.Context(), cookie.Value)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("auth: get session", "err", err)
+		return nil
+	}
+	return session
 }
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package auth_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+func TestSessionCookie(t *testing.T) {
+	sessions := auth.NewSessionStore()
+	err := sessions.SetCookieConfig(auth.CookieConfig{Name: "tn", Domain: "example.com", SameSite: http.SameSiteStrictMode, Lifetime: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookie := func(r *http.Request) *http.Cookie {
+		session, err := sessions.Create(auth.User{Handle: "clan0500"}, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		sessions.SetCookie(w, r, session)
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 {
+			t.Fatalf("got %d cookies, want 1", len(cookies))
+		}
+		c := cookies[0]
+		if c.Value != session.ID || !c.HttpOnly {
+			t.Errorf("cookie = %+v", c)
+		}
+		r.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+		if got := auth.GetSessionFromRequest(r, sessions); got == nil || got.ID != session.ID {
+			t.Errorf("session from cookie = %+v", got)
+		}
+		return c
+	}
+
+	before := time.Now()
+	if c := cookie(httptest.NewRequest(http.MethodGet, "http://example.com/", nil)); c.Secure {
+		t.Error("plain http: cookie is secure")
+	} else if c.Name != "tn" || c.Domain != "example.com" || c.SameSite != http.SameSiteStrictMode || c.Expires.After(before.Add(time.Hour+time.Minute)) {
+		t.Errorf("configured cookie = %+v", c)
+	}
+	r := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	r.TLS = &tls.ConnectionState{}
+	if c := cookie(r); !c.Secure {
+		t.Error("tls: cookie is not secure")
+	}
+	r = httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	r.Header.Set("X-Forwarded-Proto", "HTTPS, http")
+	if c := cookie(r); !c.Secure {
+		t.Error("X-Forwarded-Proto https: cookie is not secure")
+	}
+
+	if err := sessions.SetCookieConfig(auth.CookieConfig{Secure: auth.CookieSecureAlways}); err != nil {
+		t.Fatal(err)
+	}
+	if sessions.CookieName() != auth.SessionCookieName {
+		t.Errorf("default name = %q", sessions.CookieName())
+	}
+	if c := cookie(httptest.NewRequest(http.MethodGet, "http://example.com/", nil)); !c.Secure || c.SameSite != http.SameSiteLaxMode {
+		t.Errorf("always secure: cookie = %+v", c)
+	}
+}
+
+func TestSetCookieConfig_Invalid(t *testing.T) {
+	for _, c := range []auth.CookieConfig{
+		{Secure: "sometimes"},
+		{Secure: auth.CookieSecureNever, SameSite: http.SameSiteNoneMode},
+		{Name: "tn session"},
+		{Lifetime: -time.Minute},
+	} {
+		if err := auth.NewSessionStore().SetCookieConfig(c); err == nil {
+			t.Errorf("%+v: expected an error", c)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package auth
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// LoopbackAddr returns a listen address bound to the loopback interface, for
+// the development modes that skip logging in. A bare port (":8787") or a
+// wildcard host ("0.0.0.0", "::") is moved to 127.0.0.1; a loopback host is
+// kept; any other host is an error, since it would let other machines in.
+func LoopbackAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("loopback: %w", err)
+	}
+	switch host {
+	case "", "0.0.0.0", "::":
+		return net.JoinHostPort("127.0.0.1", port), nil
+	case "localhost":
+		return addr, nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return addr, nil
+	}
+	return "", fmt.Errorf("loopback: %s is not a loopback address", host)
+}
+
+// WriteInsecureBanner writes a warning that the server is running without
+// logins, so it can't be missed in the console.
+func WriteInsecureBanner(w io.Writer, mode, addr string) {
+	line := strings.Repeat("!", 72)
+	fmt.Fprintf(w, "%s\n!!\n!! INSECURE DEVELOPMENT MODE: %s\n!! Listening on %s only. Do not run this way in production.\n!!\n%s\n", line, mode, addr, line)
+}
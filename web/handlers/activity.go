@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// activityRSSItems caps the number of items in the RSS feed.
+const activityRSSItems = 100
+
+// Activity shows the clan's change feed: settlements and clans seen for the
+// first time, units lost, and grids entered, newest turn first. If a turn is
+// selected, later turns are left out.
+// With ?format=json, the feed is returned as JSON, oldest first. An htmx
+// request gets just the latest turn's activity, for the units page.
+func (h *Handlers) Activity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.notModified(w, r, session) {
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+
+	feed, err := h.store.ActivityByGameClan(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("activity", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if feed == nil {
+			feed = []model.Activity{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(feed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if r.Header.Get("HX-Request") == "true" {
+		if err := templates.RecentActivity(feed, layoutData).Render(r.Context(), w); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := templates.ActivityPage(feed, layoutData).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// rssFeed is an RSS 2.0 document.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title string  `xml:"title"`
+	Link  string  `xml:"link"`
+	GUID  rssGUID `xml:"guid"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// ActivityRSS serves the clan's change feed as RSS, newest first, for feed
+// readers that send the session cookie. Items link to the activity page for
+// their turn.
+func (h *Handlers) ActivityRSS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	gameID, clanNo := layoutData.CurrentGameID, layoutData.CurrentClanNo
+
+	feed, err := h.store.ActivityByGameClan(r.Context(), gameID, clanNo, 0)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("activity: rss", logging.KeyGame, gameID, logging.KeyClan, clanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	slices.Reverse(feed)
+	if len(feed) > activityRSSItems {
+		feed = feed[:activityRSSItems]
+	}
+
+	site := h.siteURL(r)
+	page := func(turnNo model.TurnNo) string {
+		q := url.Values{"game": {gameID}, "clan": {fmt.Sprint(clanNo)}}
+		if turnNo != 0 {
+			q.Set("turn", turnNo.String())
+		}
+		return site + "/activity?" + q.Encode()
+	}
+	doc := rssFeed{Version: "2.0", Channel: rssChannel{
+		Title:       fmt.Sprintf("Clan %04d activity, game %s", clanNo, gameID),
+		Link:        page(0),
+		Description: "Settlements and clans seen for the first time, units lost, and grids entered.",
+	}}
+	for _, a := range feed {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title: a.TurnNo.String() + ": " + a.String(),
+			Link:  page(a.TurnNo),
+			GUID:  rssGUID{Value: fmt.Sprintf("tnrpt:%s:%04d:%s:%s:%s:%s", gameID, clanNo, a.TurnNo, a.Kind, a.UnitID, a.Subject)},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		logging.FromContext(r.Context()).Error("activity: rss", logging.KeyGame, gameID, logging.KeyClan, clanNo, "err", err)
+	}
+}
+
+// siteURL returns the public URL of the site for the feed's links: the base
+// URL if login links are enabled, or else the scheme and host of the request.
+// Login links never come from the request; see SetMagicLinks.
+func (h *Handlers) siteURL(r *http.Request) string {
+	if h.baseURL != "" {
+		return h.baseURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// maxIngestBytes caps the size of an ingest request, all files included.
+const maxIngestBytes = 32 << 20
+
+// ingestResponse is the body of a successful ingest request.
+type ingestResponse struct {
+	Batch int64            `json:"batch"`
+	Files []ingestedReport `json:"files"`
+}
+
+type ingestedReport struct {
+	Name         string `json:"name"`
+	ReportFileID int64  `json:"reportFileId"`
+	WorkID       int64  `json:"workId,omitempty"` // zero for a duplicate
+	Duplicate    bool   `json:"duplicate,omitempty"`
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeAPIFailure writes the response for an error from the pipeline or the
+// store. The status and "code" come from the error's cerrs.Code; the message
+// is only the status text, so internal details stay in the log.
+func writeAPIFailure(w http.ResponseWriter, err error) {
+	code := cerrs.CodeOf(err)
+	status := cerrs.HTTPStatus(code)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": errorMessage(strings.ToLower(http.StatusText(status)), err), "code": string(code)})
+}
+
+// errorMessage returns what an error response tells the client about err,
+// starting with msg. A failure the client caused (a code with a 4xx status)
+// is described so that they can fix the request. Any other failure gets only
+// msg: its text (SQL, file paths) is of no use to the client, and the caller
+// logs it.
+func errorMessage(msg string, err error) string {
+	if status := cerrs.HTTPStatus(cerrs.CodeOf(err)); status >= 400 && status < 500 {
+		return msg + ": " + err.Error()
+	}
+	return msg
+}
+
+// validateAPIClan checks a clan parameter, e.g. "0512", against the game's
+// unit ID rules, and writes the error response if it doesn't follow them.
+func (h *Handlers) validateAPIClan(w http.ResponseWriter, r *http.Request, game, clan string) bool {
+	ids, err := h.store.UnitIDRules(r.Context(), game)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("api: unit id rules", logging.KeyGame, game, "err", err)
+		writeAPIFailure(w, err)
+		return false
+	}
+	if err := ids.ValidateClan(clan); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "clan: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// RequireAPIToken wraps an API handler to require an "Authorization: Bearer"
+// API token with the given scope. Tokens with the GM scope only work while
+// their owner is still a GM.
+func (h *Handlers) RequireAPIToken(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tnrpt"`)
+			writeAPIError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		handle, err := h.store.APITokenUser(r.Context(), strings.TrimSpace(token), scope)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("api: token", "err", err)
+			writeAPIError(w, http.StatusInternalServerError, "internal server error")
+			return
+		} else if handle == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tnrpt", error="invalid_token"`)
+			writeAPIError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if scope == store.APIScopeGM {
+			if isGM, err := h.store.IsUserGM(r.Context(), handle); err != nil || !isGM {
+				writeAPIError(w, http.StatusForbidden, "GM role required")
+				return
+			}
+		}
+		next(w, withUsername(r, handle))
+	}
+}
+
+// APIIngest queues report files for the pipeline, the way
+// stages.IngestService.IngestBatch does for "tnrpt pipeline ingest".
+// Expects a multipart form with game, clan (e.g. "0512"), turn (e.g.
+// "0899-12"), and one or more file parts. Files that were already ingested
+// are reported as duplicates and not queued again.
+// Protected route: requires an API token with the GM scope.
+func (h *Handlers) APIIngest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.dataDir == "" {
+		writeAPIError(w, http.StatusServiceUnavailable, "ingest is not enabled on this server")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxIngestBytes)
+	if err := r.ParseMultipartForm(maxIngestBytes); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "failed to parse form: "+err.Error())
+		return
+	}
+	game, clan := r.FormValue("game"), r.FormValue("clan")
+	if !gameIDPattern.MatchString(game) {
+		writeAPIError(w, http.StatusBadRequest, "game must be 4 digits")
+		return
+	}
+	if !h.validateAPIClan(w, r, game, clan) {
+		return
+	}
+	turnNo, err := model.ParseTurnNo(r.FormValue("turn"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid turn")
+		return
+	}
+	headers := r.MultipartForm.File["file"]
+	if len(headers) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "no files uploaded")
+		return
+	}
+
+	var files []stages.IngestRequest
+	for _, fh := range headers {
+		f, err := fh.Open()
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "failed to read "+fh.Filename)
+			return
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "failed to read "+fh.Filename)
+			return
+		}
+		files = append(files, stages.IngestRequest{Filename: fh.Filename, Data: data})
+	}
+
+	handle, _ := r.Context().Value("username").(string) // set by RequireAPIToken
+	svc := stages.NewIngestService(h.store, h.dataDir)
+	svc.SetFS(h.fs)
+	svc.SetClock(h.clock)
+	batchID, results, err := svc.IngestBatch(r.Context(), game, clan, turnNo, "api:"+handle, files)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("api: ingest", logging.KeyUser, handle, logging.KeyGame, game, logging.KeyTurn, turnNo.String(), logging.KeyClan, clan, "code", cerrs.CodeOf(err), "err", err)
+		writeAPIFailure(w, err)
+		return
+	}
+	logging.FromContext(r.Context()).Info("api: ingest: queued", logging.KeyUser, handle, logging.KeyBatch, batchID, "files", len(results), logging.KeyGame, game, logging.KeyTurn, turnNo.String(), logging.KeyClan, clan)
+
+	resp := ingestResponse{Batch: batchID}
+	for i, res := range results {
+		resp.Files = append(resp.Files, ingestedReport{
+			Name:         files[i].Filename,
+			ReportFileID: res.ReportFileID,
+			WorkID:       res.WorkID,
+			Duplicate:    res.Duplicate,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
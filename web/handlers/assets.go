@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/mdhender/tnrpt/logging"
+)
+
+// RequiredStaticAssets lists the files under the static directory that the
+// templates link to. The templates themselves are compiled into the binary.
+var RequiredStaticAssets = []string{
+	"style.css",
+}
+
+// MissingStaticAssets returns the required assets that are not regular files
+// under dir. If dir itself is missing, it is the only entry returned.
+func MissingStaticAssets(dir string) []string {
+	if sb, err := os.Stat(dir); err != nil || !sb.IsDir() {
+		return []string{dir}
+	}
+	var missing []string
+	for _, name := range RequiredStaticAssets {
+		path := filepath.Join(dir, name)
+		if sb, err := os.Stat(path); err != nil || !sb.Mode().IsRegular() {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
+
+var assetsUnavailableTmpl = template.Must(template.New("assets").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8"/><title>TN Report - unavailable</title></head>
+<body style="font-family: sans-serif; max-width: 40em; margin: 2em auto;">
+<h1>Server misconfigured</h1>
+<p>The server started without the static assets it needs, so pages can't be served.</p>
+<p>Missing from the static directory <code>{{.Dir}}</code>:</p>
+<ul>{{range .Missing}}<li><code>{{.}}</code></li>{{end}}</ul>
+<p>Restart the server with <code>--static</code> pointing at the <code>web/static</code> directory from the release.</p>
+</body>
+</html>
+`))
+
+// AssetsUnavailable returns a handler that answers every request with a
+// built-in page listing the missing assets. It is served instead of the
+// application so a misconfigured deployment explains itself.
+func AssetsUnavailable(dir string, missing []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		err := assetsUnavailableTmpl.Execute(w, struct {
+			Dir     string
+			Missing []string
+		}{Dir: dir, Missing: missing})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("assets", "err", err)
+		}
+	})
+}
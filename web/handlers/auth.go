@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/logging"
 	"github.com/mdhender/tnrpt/web/auth"
 	"github.com/mdhender/tnrpt/web/templates"
 )
@@ -31,7 +32,7 @@ func (h *Handlers) LoginPage(w http.ResponseWriter, r *http.Request) {
 
 	data := templates.LayoutData{Version: tnrpt.Version().String()}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := templates.LoginPage("", data).Render(r.Context(), w); err != nil {
+	if err := templates.LoginPage("", h.mailer != nil, data).Render(r.Context(), w); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
@@ -46,7 +47,7 @@ func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 
 	if err := r.ParseForm(); err != nil {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		templates.LoginPage("Invalid form submission", data).Render(r.Context(), w)
+		templates.LoginPage("Invalid form submission", h.mailer != nil, data).Render(r.Context(), w)
 		return
 	}
 
@@ -60,26 +61,34 @@ func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 	user, err := h.store.ValidateCredentials(r.Context(), handle, password, gameID)
 	if err != nil {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		templates.LoginPage("Authentication error", data).Render(r.Context(), w)
+		templates.LoginPage("Authentication error", h.mailer != nil, data).Render(r.Context(), w)
 		return
 	}
 	if user == nil {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		templates.LoginPage("Invalid username or password", data).Render(r.Context(), w)
+		templates.LoginPage("Invalid username or password", h.mailer != nil, data).Render(r.Context(), w)
 		return
 	}
 
-	session := h.sessions.Create(*user)
-	auth.SetSessionCookie(w, session)
+	session, err := h.sessions.Create(*user, r)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("login: create session", logging.KeyUser, user.Handle, "err", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		templates.LoginPage("Authentication error", h.mailer != nil, data).Render(r.Context(), w)
+		return
+	}
+	h.sessions.SetCookie(w, r, session)
 
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
 func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
-	if cookie, err := r.Cookie(auth.SessionCookieName); err == nil {
-		h.sessions.Delete(cookie.Value)
+	if cookie, err := r.Cookie(h.sessions.CookieName()); err == nil {
+		if err := h.sessions.Delete(r.Context(), cookie.Value); err != nil {
+			logging.FromContext(r.Context()).Error("logout: delete session", "err", err)
+		}
 	}
-	auth.ClearSessionCookie(w)
+	h.sessions.ClearCookie(w, r)
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
@@ -88,8 +97,13 @@ func (h *Handlers) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 		session := auth.GetSessionFromRequest(r, h.sessions)
 		if session == nil {
 			if h.autoAuthUser != nil {
-				session = h.sessions.Create(*h.autoAuthUser)
-				auth.SetSessionCookie(w, session)
+				var err error
+				if session, err = h.sessions.Create(*h.autoAuthUser, r); err != nil {
+					logging.FromContext(r.Context()).Error("auth: create session", logging.KeyUser, h.autoAuthUser.Handle, "err", err)
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+				h.sessions.SetCookie(w, r, session)
 			} else {
 				http.Redirect(w, r, "/login", http.StatusSeeOther)
 				return
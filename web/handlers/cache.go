@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// notModified sets the ETag and Last-Modified headers for a page rendered from
+// the store and reports whether the client's cached copy is still current. If it
+// is, a 304 has already been written and the handler should return.
+//
+// The ETag combines the store generation with everything else the page depends
+// on: the user, the request URI, whether it's an htmx fragment, and the build
+// version. Pages are private to the user and must be revalidated on every use.
+func (h *Handlers) notModified(w http.ResponseWriter, r *http.Request, session *auth.Session) bool {
+	gen, updated, err := h.store.Generation(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("cache: generation", "err", err)
+		return false // serve the page uncached rather than fail it
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		session.User.Handle,
+		r.URL.RequestURI(),
+		r.Header.Get("HX-Request"),
+		tnrpt.Version().String(),
+	}, "\x00")))
+	etag := fmt.Sprintf(`W/"%d-%s"`, gen, hex.EncodeToString(sum[:8]))
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Add("Vary", "Cookie, HX-Request")
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2)
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatch(inm, etag) {
+			return false
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims == "" {
+		return false
+	} else if t, err := http.ParseTime(ims); err != nil || updated.Truncate(time.Second).After(t) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatch reports whether an If-None-Match header matches etag using weak comparison.
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheStatic wraps a static file handler with a long-lived Cache-Control header.
+// http.FileServer already answers conditional requests from the file's modification time.
+func CacheStatic(maxAge time.Duration, next http.Handler) http.Handler {
+	value := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", value)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// GMCorrections lists the reports in the current game that replaced a report
+// the clan already had for the turn, with the units, acts, and steps each
+// one changed. Query parameters: format=json.
+// Protected route: requires GM role.
+func (h *Handlers) GMCorrections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	corrections, err := h.store.ReportCorrections(r.Context(), data.CurrentGameID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: corrections", logging.KeyGame, data.CurrentGameID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if corrections == nil {
+			corrections = []*model.ReportCorrection{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(corrections)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.GMCorrectionsPage(corrections, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
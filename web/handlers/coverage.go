@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// Coverage shows how often the clan's units have been in each hex and how long
+// ago each was last observed, so players can find stale parts of their map.
+// If a turn is selected, coverage is as of the end of that turn.
+// With ?format=json, the coverage is returned as JSON instead of a heatmap.
+func (h *Handlers) Coverage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.notModified(w, r, session) {
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+
+	latest := layoutData.SelectedTurn
+	if latest == 0 && len(layoutData.Turns) != 0 {
+		latest = slices.Max(layoutData.Turns)
+	}
+
+	coverage, err := h.store.CoverageByGameClan(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("coverage", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if coverage == nil {
+			coverage = []model.HexCoverage{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Game   string              `json:"game"`
+			ClanNo int                 `json:"clanNo"`
+			AsOf   model.TurnNo        `json:"asOf"`
+			Hexes  []model.HexCoverage `json:"hexes"`
+		}{layoutData.CurrentGameID, layoutData.CurrentClanNo, latest, coverage})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.CoveragePage(coverage, latest, layoutData).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	"github.com/spf13/afero"
+)
+
+// GMTurnExport streams a zip of every clan's report extract for a game and turn.
+// Each extract and unit carries its natural key (see model.Key) so that exports
+// from different servers can be merged without relying on database IDs.
+// With ?originals=1 the uploaded report files are included as well, if the server
+// was started with a data directory.
+//
+// The zip is laid out as
+//
+//	GGGG.YYYY-MM/GGGG.YYYY-MM.CCCC.json
+//	GGGG.YYYY-MM/originals/CCCC/<stored file name>
+func (h *Handlers) GMTurnExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	turnNo, err := model.ParseTurnNo(r.PathValue("turn"))
+	if err != nil {
+		http.Error(w, "Invalid turn", http.StatusBadRequest)
+		return
+	}
+	game := r.URL.Query().Get("game")
+	if game == "" {
+		http.Error(w, "Missing game", http.StatusBadRequest)
+		return
+	}
+	originals := r.URL.Query().Get("originals") == "1"
+
+	reports, err := h.store.ReportExtractsByGameTurn(r.Context(), game, turnNo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("export", logging.KeyGame, game, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	var files []*model.ReportFile
+	if originals && h.dataDir != "" {
+		if files, err = h.store.GetReportFilesByGameTurn(r.Context(), game, turnNo); err != nil {
+			logging.FromContext(r.Context()).Error("export", logging.KeyGame, game, logging.KeyTurn, turnNo.String(), "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if len(reports) == 0 && len(files) == 0 {
+		http.Error(w, "No reports for turn", http.StatusNotFound)
+		return
+	}
+
+	// from here on the response is committed; errors can only be logged.
+	prefix := fmt.Sprintf("%s.%s", game, turnNo)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", prefix+".zip"))
+
+	zw := zip.NewWriter(w)
+	seen := map[string]bool{}
+	for _, rx := range reports {
+		name := fmt.Sprintf("%s/%s.%s.json", prefix, prefix, rx.ClanNo)
+		if seen[name] { // a clan with more than one extract for the turn
+			name = fmt.Sprintf("%s/%s.%s.%d.json", prefix, prefix, rx.ClanNo, rx.ID)
+		}
+		seen[name] = true
+		fw, err := zw.Create(name)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("export", logging.KeyFile, name, "err", err)
+			return
+		}
+		rx.SetKeys()
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rx); err != nil {
+			logging.FromContext(r.Context()).Error("export", logging.KeyFile, name, "err", err)
+			return
+		}
+	}
+	fsys := stages.ContextFS(r.Context(), h.fs)
+	for _, rf := range files {
+		if rf.FsPath == "" {
+			continue
+		}
+		data, err := afero.ReadFile(fsys, filepath.Join(h.dataDir, rf.FsPath))
+		if err != nil {
+			logging.FromContext(r.Context()).Error("export: read original", logging.KeyFile, prefix, logging.KeyReportFileID, rf.ID, "err", err)
+			continue
+		}
+		name := fmt.Sprintf("%s/originals/%s/%s", prefix, rf.ClanNo, filepath.Base(rf.FsPath))
+		fw, err := zw.Create(name)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("export", logging.KeyFile, name, "err", err)
+			return
+		}
+		if _, err := fw.Write(data); err != nil {
+			logging.FromContext(r.Context()).Error("export", logging.KeyFile, name, "err", err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		logging.FromContext(r.Context()).Error("export", logging.KeyFile, prefix, "err", err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// Flags shows the feature flags set for the deployment and for each game.
+// A POST changes one; it expects form values name, value ("on", "off", or
+// "default" to remove the setting), and optionally game. It redirects back
+// to the page.
+// Query parameter on GET: format=json.
+// Protected route: requires GM role.
+func (h *Handlers) Flags(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		h.setFlag(w, r)
+		return
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	set, err := h.store.FeatureFlags(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).Error("admin: flags", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if set == nil {
+			set = []model.FeatureFlag{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Flags []model.FlagInfo    `json:"flags"`
+			Set   []model.FeatureFlag `json:"set"`
+		}{model.FeatureFlags, set})
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.FlagsPage(set, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// setFlag handles the POST for Flags.
+func (h *Handlers) setFlag(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("name")
+	gameID := strings.TrimSpace(r.FormValue("game"))
+	if !model.IsFeatureFlag(name) {
+		http.Error(w, "Unknown flag", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch value := r.FormValue("value"); value {
+	case "on", "off":
+		err = h.store.SetFeatureFlag(r.Context(), h.currentHandle(r), name, gameID, value == "on")
+	case "default":
+		_, err = h.store.ClearFeatureFlag(r.Context(), h.currentHandle(r), name, gameID)
+	default:
+		http.Error(w, "Invalid value", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("admin: flags: set", logging.KeyGame, gameID, "flag", name, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("admin: flags: set", logging.KeyUser, h.currentHandle(r), logging.KeyGame, gameID, "flag", name, "value", r.FormValue("value"))
+	http.Redirect(w, r, "/admin/flags", http.StatusSeeOther)
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// Game ids are 4 digits, matching the GGGG in report file names.
+var gameIDPattern = regexp.MustCompile(`^\d{4}$`)
+
+// GMGames shows the games and their turns (GET) or creates a game (POST, form
+// values id and description).
+// Protected route: requires GM role.
+func (h *Handlers) GMGames(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.renderGMGames(w, r, http.StatusOK, "")
+	case http.MethodPost:
+		id := strings.TrimSpace(r.FormValue("id"))
+		if !gameIDPattern.MatchString(id) {
+			h.renderGMGames(w, r, http.StatusBadRequest, "Game must be 4 digits, e.g. 0300.")
+			return
+		}
+		created, err := h.store.CreateGame(r.Context(), id, strings.TrimSpace(r.FormValue("description")))
+		if err != nil {
+			logging.FromContext(r.Context()).Error("gm: games: create", logging.KeyGame, id, "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		} else if !created {
+			h.renderGMGames(w, r, http.StatusConflict, "Game "+id+" already exists.")
+			return
+		}
+		logging.FromContext(r.Context()).Info("gm: games: created", logging.KeyUser, h.currentHandle(r), logging.KeyGame, id)
+		http.Redirect(w, r, "/gm/games", http.StatusSeeOther)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GMAddTurn adds a turn to a game. Expects form values turn ("YYYY-MM") and,
+// optionally, due in the games.json format.
+// Protected route: requires GM role.
+func (h *Handlers) GMAddTurn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gameID := r.PathValue("game")
+	turnNo, err := model.ParseTurnNo(strings.TrimSpace(r.FormValue("turn")))
+	if err != nil {
+		h.renderGMGames(w, r, http.StatusBadRequest, "Turn must look like 0901-01.")
+		return
+	}
+	due, ok := h.parseDueForm(w, r)
+	if !ok {
+		return
+	}
+
+	if ok, err := h.gameExists(r, gameID); err != nil {
+		logging.FromContext(r.Context()).Error("gm: games", logging.KeyGame, gameID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+
+	added, err := h.store.AddGameTurn(r.Context(), gameID, turnNo, due)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: games: add turn", logging.KeyGame, gameID, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	} else if !added {
+		h.renderGMGames(w, r, http.StatusConflict, "Game "+gameID+" already has turn "+turnNo.String()+".")
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: games: added turn", logging.KeyUser, h.currentHandle(r), logging.KeyGame, gameID, logging.KeyTurn, turnNo.String())
+	http.Redirect(w, r, "/gm/games", http.StatusSeeOther)
+}
+
+// GMActivateTurn makes a turn the game's active turn, deactivating the others.
+// Protected route: requires GM role.
+func (h *Handlers) GMActivateTurn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gameID := r.PathValue("game")
+	turnNo, err := model.ParseTurnNo(r.PathValue("turn"))
+	if err != nil {
+		http.Error(w, "Invalid turn", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.store.SetActiveTurn(r.Context(), h.currentHandle(r), gameID, turnNo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: games: activate turn", logging.KeyGame, gameID, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	} else if !found {
+		http.Error(w, "Turn not found", http.StatusNotFound)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: games: activated turn", logging.KeyUser, h.currentHandle(r), logging.KeyGame, gameID, logging.KeyTurn, turnNo.String())
+	http.Redirect(w, r, "/gm/games", http.StatusSeeOther)
+}
+
+// GMSetAutoAdvance turns automatic activation of the next turn, when the
+// active turn's orders are due, on or off for a game. Expects form value
+// auto_advance ("1" for on).
+// Protected route: requires GM role.
+func (h *Handlers) GMSetAutoAdvance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gameID := r.PathValue("game")
+	on := r.FormValue("auto_advance") == "1"
+	found, err := h.store.SetGameAutoAdvance(r.Context(), gameID, on)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: games: set auto advance", logging.KeyGame, gameID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	} else if !found {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: games: set auto advance", logging.KeyUser, h.currentHandle(r), logging.KeyGame, gameID, "on", on)
+	http.Redirect(w, r, "/gm/games", http.StatusSeeOther)
+}
+
+// GMSetTurnDueDate sets the date orders are due for a turn. Expects form value
+// due in the games.json format; an empty value clears the date.
+// Protected route: requires GM role.
+func (h *Handlers) GMSetTurnDueDate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gameID := r.PathValue("game")
+	turnNo, err := model.ParseTurnNo(r.PathValue("turn"))
+	if err != nil {
+		http.Error(w, "Invalid turn", http.StatusBadRequest)
+		return
+	}
+	due, ok := h.parseDueForm(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := h.store.SetTurnDueDate(r.Context(), gameID, turnNo, due)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: games: set due date", logging.KeyGame, gameID, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	} else if !found {
+		http.Error(w, "Turn not found", http.StatusNotFound)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: games: set due date", logging.KeyUser, h.currentHandle(r), logging.KeyGame, gameID, logging.KeyTurn, turnNo.String())
+	http.Redirect(w, r, "/gm/games", http.StatusSeeOther)
+}
+
+// parseDueForm parses the due form value. It renders the games page with an
+// error and returns false if the value is invalid. An empty value is the zero time.
+func (h *Handlers) parseDueForm(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	s := strings.TrimSpace(r.FormValue("due"))
+	if s == "" {
+		return time.Time{}, true
+	}
+	due, err := store.ParseDueDate(s)
+	if err != nil {
+		h.renderGMGames(w, r, http.StatusBadRequest, "Invalid due date: "+err.Error())
+		return time.Time{}, false
+	}
+	return due, true
+}
+
+func (h *Handlers) gameExists(r *http.Request, gameID string) (bool, error) {
+	games, err := h.store.GetAllGames(r.Context())
+	if err != nil {
+		return false, err
+	}
+	for _, g := range games {
+		if g.ID == gameID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// currentHandle returns the handle of the signed-in user, for logging.
+func (h *Handlers) currentHandle(r *http.Request) string {
+	if session := auth.GetSessionFromRequest(r, h.sessions); session != nil {
+		return session.User.Handle
+	}
+	return "?"
+}
+
+func (h *Handlers) renderGMGames(w http.ResponseWriter, r *http.Request, status int, errorMsg string) {
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	games, err := h.store.GetAllGames(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: games", "err", err)
+		http.Error(w, "Failed to load games", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	templates.GMGamesPage(games, errorMsg, data).Render(r.Context(), w)
+}
@@ -3,34 +3,49 @@
 package handlers
 
 import (
-	"log"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/mdhender/tnrpt"
-	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/clock"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/lint"
+	"github.com/mdhender/tnrpt/tracing"
 	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/mail"
 	"github.com/mdhender/tnrpt/web/templates"
+	"github.com/spf13/afero"
 )
 
 // Handlers holds dependencies for HTTP handlers.
 type Handlers struct {
-	store        *store.SQLiteStore
+	store        Store
 	sessions     *auth.SessionStore
 	autoAuthUser *auth.User
+	authMode     string   // shown in the page footer when logins are skipped for development
+	dataDir      string   // pipeline data directory; empty if original files aren't available
+	fs           afero.Fs // the filesystem dataDir is on
+	mailer       mail.Sender
+	baseURL      string // public URL for links in email and feeds; set with login links
+	clock        clock.Clock
+	linter       *lint.Linter
 }
 
 // New creates a new Handlers with the given store and session store.
-func New(s *store.SQLiteStore, sessions *auth.SessionStore) *Handlers {
-	return &Handlers{store: s, sessions: sessions}
+func New(s Store, sessions *auth.SessionStore) *Handlers {
+	return &Handlers{store: s, sessions: sessions, fs: afero.NewOsFs(), clock: clock.Real, linter: lint.Default()}
 }
 
 // getLayoutData returns layout data with turns for the authenticated user.
-// It reads ?game= from the query string to determine which game context to use.
+// It reads ?game= from the query string to determine which game context to use,
+// and ?clan= to pick between clans when the user runs more than one in the game.
 func (h *Handlers) getLayoutData(r *http.Request, session *auth.Session) templates.LayoutData {
 	var data templates.LayoutData
 	data.CurrentPath = r.URL.Path
 	data.Version = tnrpt.Version().String()
+	data.AuthMode = h.authMode
 
 	if session == nil {
 		return data
@@ -41,7 +56,7 @@ func (h *Handlers) getLayoutData(r *http.Request, session *auth.Session) templat
 	// Get all games for this user
 	games, err := h.store.GetGamesForUser(r.Context(), session.User.Handle)
 	if err != nil {
-		log.Printf("warning: failed to get games for user: %v", err)
+		logging.FromContext(r.Context()).Warn("failed to get games for user", logging.KeyUser, session.User.Handle, "err", err)
 		return data
 	}
 	data.Games = games
@@ -53,35 +68,61 @@ func (h *Handlers) getLayoutData(r *http.Request, session *auth.Session) templat
 	}
 	data.CurrentGameID = gameID
 
-	// Find clan number for current game
+	// Find clan number for current game; a user with several clans in the
+	// game picks one with ?clan=, defaulting to the first
+	clanNo, _ := strconv.Atoi(r.URL.Query().Get("clan"))
 	for _, g := range games {
-		if g.GameID == gameID {
+		if g.GameID != gameID {
+			continue
+		}
+		data.Clans = append(data.Clans, g.ClanNo)
+		if data.CurrentClanNo == 0 || g.ClanNo == clanNo {
 			data.CurrentClanNo = g.ClanNo
-			break
 		}
 	}
 
 	turns, err := h.store.TurnsByGameClan(gameID, data.CurrentClanNo)
 	if err != nil {
-		log.Printf("warning: failed to get turns: %v", err)
+		logging.FromContext(r.Context()).Warn("failed to get turns", logging.KeyGame, gameID, logging.KeyClan, data.CurrentClanNo, "err", err)
 		return data
 	}
 	data.Turns = turns
 
 	if turnStr := r.URL.Query().Get("turn"); turnStr != "" {
-		if t, err := strconv.Atoi(turnStr); err == nil {
+		if t, err := model.ParseTurnNo(turnStr); err == nil {
 			data.SelectedTurn = t
 		}
 	}
+	data.AsOf = r.URL.Query().Get("asof") == "1"
+	span := tracing.FromContext(r.Context())
+	span.SetAttrs(tracing.String(logging.KeyGame, gameID), tracing.String(logging.KeyClan, fmt.Sprintf("%04d", data.CurrentClanNo)))
+	if data.SelectedTurn > 0 {
+		span.SetAttrs(tracing.String(logging.KeyTurn, data.SelectedTurn.String()))
+		if data.Season, data.Weather, err = h.store.TurnConditions(gameID, data.SelectedTurn); err != nil {
+			logging.FromContext(r.Context()).Warn("failed to get turn conditions", logging.KeyGame, gameID, logging.KeyTurn, data.SelectedTurn.String(), "err", err)
+		}
+	}
 
 	isGM, _ := h.store.IsUserGM(r.Context(), session.User.Handle)
 	data.IsGM = isGM
 
+	if theme, err := h.store.GetUserTheme(r.Context(), session.User.Handle); err != nil {
+		logging.FromContext(r.Context()).Warn("failed to get theme", logging.KeyUser, session.User.Handle, "err", err)
+	} else {
+		data.Theme = theme
+	}
+
+	if n, err := h.store.PendingReports(r.Context(), gameID); err != nil {
+		logging.FromContext(r.Context()).Warn("failed to count pending reports", logging.KeyGame, gameID, "err", err)
+	} else {
+		data.Processing = n
+	}
+
 	return data
 }
 
-// Store returns the underlying SQLite store.
-func (h *Handlers) Store() *store.SQLiteStore {
+// Store returns the store the handlers read from and write to.
+func (h *Handlers) Store() Store {
 	return h.store
 }
 
@@ -90,8 +131,32 @@ func (h *Handlers) Sessions() *auth.SessionStore {
 	return h.sessions
 }
 
-// SetAutoAuth configures automatic authentication for testing.
+// SetDataDir sets the pipeline data directory that report_files.fs_path is relative to.
+func (h *Handlers) SetDataDir(dir string) {
+	h.dataDir = dir
+}
+
+// SetFS sets the filesystem the pipeline data directory is on (see stages.NewFS).
+// The default is the OS filesystem.
+func (h *Handlers) SetFS(fs afero.Fs) {
+	h.fs = fs
+}
+
+// SetClock sets the clock used to stamp uploads, for testing.
+func (h *Handlers) SetClock(c clock.Clock) {
+	h.clock = c
+}
+
+// SetLinter sets the lint rules run over uploaded reports and shown with a
+// report's text. The default is lint.Default.
+func (h *Handlers) SetLinter(l *lint.Linter) {
+	h.linter = l
+}
+
+// SetAutoAuth configures automatic authentication for testing. Every page
+// footer then says who visitors are logged in as.
 func (h *Handlers) SetAutoAuth(gameID, handle string, clanNo int) {
+	h.authMode = "insecure dev mode: everyone is logged in as " + handle
 	h.autoAuthUser = &auth.User{
 		Handle:   handle,
 		UserName: handle,
@@ -5,6 +5,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/mdhender/tnrpt/logging"
 	"github.com/mdhender/tnrpt/web/auth"
 )
 
@@ -20,8 +21,13 @@ func (h *Handlers) Index(w http.ResponseWriter, r *http.Request) {
 
 	session := auth.GetSessionFromRequest(r, h.sessions)
 	if session == nil && h.autoAuthUser != nil {
-		session = h.sessions.Create(*h.autoAuthUser)
-		auth.SetSessionCookie(w, session)
+		var err error
+		if session, err = h.sessions.Create(*h.autoAuthUser, r); err != nil {
+			logging.FromContext(r.Context()).Error("index: create session", logging.KeyUser, h.autoAuthUser.Handle, "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		h.sessions.SetCookie(w, r, session)
 	}
 
 	if session != nil {
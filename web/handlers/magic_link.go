@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/web/mail"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+const (
+	magicLinkTTL   = 30 * time.Minute // links players request for themselves
+	gmMagicLinkTTL = 24 * time.Hour   // links a GM sends; players may not read their mail right away
+)
+
+// SetMagicLinks enables passwordless login. Login links are delivered by sender
+// and point at baseURL (e.g. "https://tn.example.com"). The base URL is never
+// taken from the request: a forged Host header would send the token elsewhere.
+func (h *Handlers) SetMagicLinks(sender mail.Sender, baseURL string) {
+	h.mailer = sender
+	h.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// MagicLinkPage shows the form for requesting a login link.
+func (h *Handlers) MagicLinkPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.mailer == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := templates.LayoutData{Version: tnrpt.Version().String()}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.MagicLinkPage("", "", data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// RequestMagicLink emails a login link to the user named by handle or email address.
+// The response is the same whether or not the user exists so the form can't be
+// used to discover accounts.
+func (h *Handlers) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.mailer == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := templates.LayoutData{Version: tnrpt.Version().String()}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := r.ParseForm(); err != nil {
+		templates.MagicLinkPage("", "Invalid form submission", data).Render(r.Context(), w)
+		return
+	}
+
+	handle, email, err := h.store.UserEmail(r.Context(), r.FormValue("username"))
+	if err != nil {
+		logging.FromContext(r.Context()).Error("magic-link: user email", "err", err)
+		templates.MagicLinkPage("", "Unable to send a login link right now", data).Render(r.Context(), w)
+		return
+	}
+	if handle != "" && email != "" {
+		if err := h.sendLoginLink(r.Context(), handle, email, magicLinkTTL); err != nil {
+			logging.FromContext(r.Context()).Error("magic-link: send", logging.KeyUser, handle, "err", err)
+			templates.MagicLinkPage("", "Unable to send a login link right now", data).Render(r.Context(), w)
+			return
+		}
+	}
+
+	msg := fmt.Sprintf("If that account has an email address, a login link is on its way. The link works once and expires in %s.", formatTTL(magicLinkTTL))
+	if err := templates.MagicLinkPage(msg, "", data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// MagicLinkConfirm shows the page a login link opens. It doesn't use the
+// token; mail scanners and link previews fetch links, and would use it up.
+func (h *Handlers) MagicLinkConfirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.mailer == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := templates.LayoutData{Version: tnrpt.Version().String()}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.MagicLinkConfirmPage(data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// MagicLinkLogin logs the user in with the token from a login link.
+func (h *Handlers) MagicLinkLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.mailer == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := templates.LayoutData{Version: tnrpt.Version().String()}
+	user, err := h.store.ConsumeLoginToken(r.Context(), r.PathValue("token"))
+	if err != nil {
+		logging.FromContext(r.Context()).Error("magic-link: consume token", "err", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		templates.LoginPage("Authentication error", true, data).Render(r.Context(), w)
+		return
+	}
+	if user == nil {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		templates.LoginPage("That login link has expired or was already used", true, data).Render(r.Context(), w)
+		return
+	}
+
+	session, err := h.sessions.Create(*user, r)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("magic-link: create session", logging.KeyUser, user.Handle, "err", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		templates.LoginPage("Authentication error", true, data).Render(r.Context(), w)
+		return
+	}
+	h.sessions.SetCookie(w, r, session)
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// GMSendMagicLink emails a login link to a player on the GM's behalf.
+func (h *Handlers) GMSendMagicLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.mailer == nil {
+		http.Error(w, "Login links are not enabled", http.StatusNotFound)
+		return
+	}
+
+	handle, email, err := h.store.UserEmail(r.Context(), r.PathValue("handle"))
+	if err != nil {
+		logging.FromContext(r.Context()).Error("magic-link: user email", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if handle == "" || handle != r.PathValue("handle") {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if email == "" {
+		http.Error(w, "User has no email address", http.StatusUnprocessableEntity)
+		return
+	}
+	if err := h.sendLoginLink(r.Context(), handle, email, gmMagicLinkTTL); err != nil {
+		logging.FromContext(r.Context()).Error("magic-link: send", logging.KeyUser, handle, "err", err)
+		http.Error(w, "Unable to send login link", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "login link sent to %s\n", handle)
+}
+
+func (h *Handlers) sendLoginLink(ctx context.Context, handle, email string, ttl time.Duration) error {
+	token, err := h.store.CreateLoginToken(ctx, handle, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := h.baseURL + "/login/magic/" + token
+
+	body := fmt.Sprintf(`Hello %s,
+
+Use this link to log in to TN Report:
+
+  %s
+
+The link works once and expires in %s.
+If you didn't ask for it, you can ignore this message.
+`, handle, link, formatTTL(ttl))
+	return h.mailer.Send(ctx, email, "Your TN Report login link", body)
+}
+
+// formatTTL renders a duration in whole hours or minutes, e.g. "30 minutes".
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if d == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	}
+	return fmt.Sprintf("%d minutes", d/time.Minute)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/clock"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/handlers"
+)
+
+// sentMail records the messages a handler sends instead of delivering them.
+type sentMail struct {
+	to, bodies []string
+}
+
+func (m *sentMail) Send(ctx context.Context, to, subject, body string) error {
+	m.to = append(m.to, to)
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+var loginLinkPattern = regexp.MustCompile(`https://tn\.example\.com/login/magic/(\S+)`)
+
+// TestMagicLinks requests login links and checks that the response doesn't
+// tell known accounts from unknown ones, that opening a link doesn't use it,
+// and that a link logs in once and not after it expires.
+func TestMagicLinks(t *testing.T) {
+	ctx := context.Background()
+	s, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	now := clock.NewFixed(time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC))
+	s.SetClock(now)
+	if _, err := s.ImportUsers(ctx, "test", []store.UserRecord{
+		{Handle: "clan0987", Email: "clan0987@example.com", Roles: []string{"active", "user"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sessions := auth.NewSessionStore()
+	sessions.SetClock(now)
+	h := handlers.New(s, sessions)
+	h.SetClock(now)
+	mail := &sentMail{}
+	h.SetMagicLinks(mail, "https://tn.example.com/")
+
+	// the routes as cmd/server registers them
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/magic", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.RequestMagicLink(w, r)
+		} else {
+			h.MagicLinkPage(w, r)
+		}
+	})
+	mux.HandleFunc("/login/magic/{token}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.MagicLinkLogin(w, r)
+		} else {
+			h.MagicLinkConfirm(w, r)
+		}
+	})
+	serve := func(method, path string, form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		if form != nil {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+	requestLink := func() string {
+		t.Helper()
+		sent := len(mail.bodies)
+		if w := serve(http.MethodPost, "/login/magic", url.Values{"username": {"clan0987"}}); w.Code != http.StatusOK {
+			t.Fatalf("request link: status = %d", w.Code)
+		}
+		if len(mail.bodies) != sent+1 {
+			t.Fatalf("request link: sent %d messages, want 1", len(mail.bodies)-sent)
+		}
+		m := loginLinkPattern.FindStringSubmatch(mail.bodies[sent])
+		if m == nil {
+			t.Fatalf("request link: no link in %q", mail.bodies[sent])
+		}
+		return m[1]
+	}
+
+	known := serve(http.MethodPost, "/login/magic", url.Values{"username": {"clan0987"}})
+	unknown := serve(http.MethodPost, "/login/magic", url.Values{"username": {"nobody"}})
+	if known.Code != unknown.Code || known.Body.String() != unknown.Body.String() {
+		t.Errorf("known and unknown accounts get different responses:\n%d %s\n%d %s", known.Code, known.Body, unknown.Code, unknown.Body)
+	}
+	if len(mail.to) != 1 || mail.to[0] != "clan0987@example.com" {
+		t.Errorf("sent mail to %q, want only clan0987@example.com", mail.to)
+	}
+
+	token := requestLink()
+	for i := 0; i < 2; i++ {
+		if w := serve(http.MethodGet, "/login/magic/"+token, nil); w.Code != http.StatusOK || w.Header().Get("Set-Cookie") != "" {
+			t.Errorf("open link: status = %d, cookie %q, want the confirm page without a session", w.Code, w.Header().Get("Set-Cookie"))
+		}
+	}
+	w := serve(http.MethodPost, "/login/magic/"+token, url.Values{})
+	if w.Code != http.StatusSeeOther || w.Header().Get("Set-Cookie") == "" {
+		t.Fatalf("first login: status = %d, cookie %q, want a redirect with a session", w.Code, w.Header().Get("Set-Cookie"))
+	}
+	w = serve(http.MethodPost, "/login/magic/"+token, url.Values{})
+	if w.Code == http.StatusSeeOther || !strings.Contains(w.Body.String(), "expired or was already used") {
+		t.Errorf("second login: status = %d, want the login page saying the link was used", w.Code)
+	}
+
+	token = requestLink()
+	now.Advance(31 * time.Minute)
+	w = serve(http.MethodPost, "/login/magic/"+token, url.Values{})
+	if w.Code == http.StatusSeeOther || !strings.Contains(w.Body.String(), "expired or was already used") {
+		t.Errorf("expired login: status = %d, want the login page saying the link expired", w.Code)
+	}
+}
@@ -3,8 +3,11 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 
+	"github.com/mdhender/tnrpt/logging"
 	"github.com/mdhender/tnrpt/web/auth"
 	"github.com/mdhender/tnrpt/web/templates"
 )
@@ -21,6 +24,10 @@ func (h *Handlers) Movements(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.notModified(w, r, session) {
+		return
+	}
+
 	layoutData := h.getLayoutData(r, session)
 
 	movements, err := h.store.MovementsByGameClan(layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
@@ -42,3 +49,39 @@ func (h *Handlers) Movements(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
+
+// MovementPointsExport downloads what the current clan's move and scout acts
+// spent in movement points in the selected turn, as JSON (see model.MoveBudget),
+// with any steps that don't add up.
+func (h *Handlers) MovementPointsExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	gameID, clanNo, turnNo := layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn
+	if clanNo == 0 || turnNo == 0 {
+		http.Error(w, "Turn not found", http.StatusNotFound)
+		return
+	}
+
+	budgets, err := h.store.MoveBudgetsByGameClan(r.Context(), gameID, clanNo, turnNo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("movement points", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s.%s.%04d.movement-points.json", gameID, turnNo, clanNo)))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(budgets)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// hexNeighborhood is the body of a neighborhood response: the six hexes
+// around a tile, fewer at the edge of the world, and what the clan knows
+// about each (see model.HexKnowledge).
+type hexNeighborhood struct {
+	Tile      model.TNCoord       `json:"tile"`
+	Neighbors []model.HexNeighbor `json:"neighbors"`
+}
+
+// TileNeighbors returns the hexes around a tile as the current clan knows
+// them, observed, inferred, or unknown. It is the data behind the tile
+// page's hex browser, for scripts that want to walk the map without loading
+// each page. ?turn=T&asof=1 answers as of the end of turn T, like the tile
+// page does.
+func (h *Handlers) TileNeighbors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	col, cerr := strconv.Atoi(r.PathValue("col"))
+	row, rerr := strconv.Atoi(r.PathValue("row"))
+	tn := model.NewTNCoord(r.PathValue("grid"), col, row)
+	if cerr != nil || rerr != nil || tn.Validate() != nil {
+		http.Error(w, "Invalid coordinate", http.StatusBadRequest)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	if layoutData.CurrentClanNo == 0 {
+		http.Error(w, "Clan not found", http.StatusNotFound)
+		return
+	}
+	var asOf model.TurnNo
+	if layoutData.AsOf {
+		asOf = layoutData.SelectedTurn
+	}
+
+	hood, err := h.store.HexNeighborhoodByGameClan(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, asOf, tn)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("tile neighbors", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "tile", tn, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hexNeighborhood{Tile: tn, Neighbors: hood})
+}
+
+// APINeighbors returns the hexes around a tile as a clan knows them, like
+// TileNeighbors does, for bots working for the GM. Query parameters: game,
+// clan (e.g. "0512"), tile (e.g. "QQ 1010"), and turn (default all turns).
+// Protected route: requires an API token with the GM scope.
+func (h *Handlers) APINeighbors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	game, clan := r.URL.Query().Get("game"), r.URL.Query().Get("clan")
+	if !gameIDPattern.MatchString(game) {
+		writeAPIError(w, http.StatusBadRequest, "game must be 4 digits")
+		return
+	}
+	if !h.validateAPIClan(w, r, game, clan) {
+		return
+	}
+	clanNo, _ := strconv.Atoi(clan)
+	tn, err := model.ParseTNCoord(r.URL.Query().Get("tile"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "tile: "+err.Error())
+		return
+	}
+	var turnNo model.TurnNo
+	if s := r.URL.Query().Get("turn"); s != "" {
+		if turnNo, err = model.ParseTurnNo(s); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid turn")
+			return
+		}
+	}
+
+	hood, err := h.store.HexNeighborhoodByGameClan(r.Context(), game, clanNo, turnNo, tn)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("api: neighbors", logging.KeyGame, game, logging.KeyClan, clanNo, "tile", tn, "err", err)
+		writeAPIFailure(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hexNeighborhood{Tile: tn, Neighbors: hood})
+}
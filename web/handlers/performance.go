@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+const performanceDefaultLimit = 50
+
+// Performance lists the reports that took longest to parse, with the time
+// spent in each step, so GMs can find reports that need attention.
+// Query parameters: limit (default 50) and format=json.
+// Protected route: requires GM role.
+func (h *Handlers) Performance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := performanceDefaultLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	timings, err := h.store.SlowestReports(r.Context(), limit)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("performance", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if timings == nil {
+			timings = []model.ReportTiming{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(timings)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.PerformancePage(timings, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
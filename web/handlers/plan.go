@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+const (
+	planDefaultPaths = 3
+	planMaxPaths     = 20
+)
+
+// PlannedPath is one candidate route. Order is the directions joined the way
+// they are written in a move order, e.g. "N NE NE".
+type PlannedPath struct {
+	Steps []string `json:"steps"`
+	Order string   `json:"order"`
+}
+
+// PlanPath suggests the shortest routes between two hexes over terrain the
+// clan has seen, treating water and rivers without a ford as impassable.
+//
+// Query parameters:
+//
+//	unit  - start from the hex the unit ended its latest turn in, or
+//	from  - start from this hex (e.g. "QQ 1010")
+//	to    - the destination hex (required)
+//	turn  - plan with what was known at the end of this turn (default: all turns)
+//	limit - the most candidate paths to return (default 3)
+func (h *Handlers) PlanPath(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	q := r.URL.Query()
+
+	to, err := model.ParseTNCoord(q.Get("to"))
+	if err != nil || to.IsUnknown() || to.IsObscured() {
+		http.Error(w, "Invalid destination hex", http.StatusBadRequest)
+		return
+	}
+	limit := planDefaultPaths
+	if s := q.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > planMaxPaths {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var from model.TNCoord
+	if unitID := q.Get("unit"); unitID != "" {
+		from, _, err = h.store.UnitLocation(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, unitID, layoutData.SelectedTurn)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("plan", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if from == "" {
+			http.Error(w, "Unit not found", http.StatusNotFound)
+			return
+		}
+	} else if from, err = model.ParseTNCoord(q.Get("from")); err != nil {
+		http.Error(w, "Invalid starting hex", http.StatusBadRequest)
+		return
+	}
+	if from.IsUnknown() || from.IsObscured() {
+		http.Error(w, "Starting hex is not known", http.StatusBadRequest)
+		return
+	}
+
+	known, err := h.store.KnownMapByGameClan(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("plan", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	paths := []PlannedPath{}
+	for _, path := range known.Paths(from, to, limit) {
+		pp := PlannedPath{Steps: make([]string, len(path))}
+		for i, d := range path {
+			pp.Steps[i] = d.String()
+		}
+		pp.Order = strings.Join(pp.Steps, " ")
+		paths = append(paths, pp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		From  model.TNCoord `json:"from"`
+		To    model.TNCoord `json:"to"`
+		Paths []PlannedPath `json:"paths"`
+	}{from, to, paths})
+}
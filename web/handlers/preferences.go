@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/mdhender/tnrpt/logging"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// SetTheme saves the user's light/dark theme preference.
+// The layout's toggle posts here in the background, so success returns 204.
+func (h *Handlers) SetTheme(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	theme := r.FormValue("theme")
+	if !store.ValidTheme(theme) {
+		http.Error(w, "Invalid theme", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.SetUserTheme(r.Context(), session.User.Handle, theme); err != nil {
+		logging.FromContext(r.Context()).Error("preferences: theme", logging.KeyUser, session.User.Handle, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// TurnPrint renders a clan's units, movements, and observations for one turn
+// as a single print-friendly page. Use the browser's print dialog to save as PDF.
+func (h *Handlers) TurnPrint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	turnNo, err := model.ParseTurnNo(r.PathValue("turn"))
+	if err != nil {
+		http.Error(w, "Invalid turn", http.StatusBadRequest)
+		return
+	}
+
+	if h.notModified(w, r, session) {
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	if !slices.Contains(layoutData.Turns, turnNo) {
+		http.Error(w, "Turn not found", http.StatusNotFound)
+		return
+	}
+	layoutData.SelectedTurn = turnNo
+
+	data := templates.TurnPrintData{
+		GameID:  layoutData.CurrentGameID,
+		ClanNo:  layoutData.CurrentClanNo,
+		TurnNo:  turnNo,
+		BackURL: layoutData.LinkWithTurn("/units"),
+	}
+	gameID, clanNo := layoutData.CurrentGameID, layoutData.CurrentClanNo
+	fail := func(err error) {
+		logging.FromContext(r.Context()).Error("print", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+	if data.Units, err = h.store.UnitsByGameClan(gameID, clanNo, turnNo); err != nil {
+		fail(err)
+		return
+	}
+	if data.Movements, err = h.store.MovementsByGameClan(gameID, clanNo, turnNo); err != nil {
+		fail(err)
+		return
+	}
+	if data.Terrain, err = h.store.TerrainObservationsByGameClan(gameID, clanNo, turnNo); err != nil {
+		fail(err)
+		return
+	}
+	if data.Resources, err = h.store.ResourcesByGameClan(gameID, clanNo, turnNo); err != nil {
+		fail(err)
+		return
+	}
+	if data.Season, data.Weather, err = h.store.TurnConditions(gameID, turnNo); err != nil {
+		fail(err)
+		return
+	}
+	if cm, err := h.store.ClanMap(r.Context(), gameID, clanNo, turnNo); err != nil {
+		fail(err)
+		return
+	} else if cm != nil && h.dataDir != "" {
+		data.MapURL = layoutData.MapURL()
+	}
+	if h.dataDir != "" {
+		files, err := h.store.GetReportFilesByGameTurn(r.Context(), gameID, turnNo)
+		if err != nil {
+			fail(err)
+			return
+		}
+		for _, rf := range files {
+			if rf.ClanNo == fmt.Sprintf("%04d", clanNo) && rf.FsPath != "" {
+				data.Originals = append(data.Originals, templates.ReportLink{Name: rf.Name, URL: layoutData.OriginalURL(rf.ID)})
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.TurnPrintPage(data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// TurnMap serves the map image the pipeline's render stage drew of what the
+// clan knew as of the turn. It needs the pipeline data directory.
+func (h *Handlers) TurnMap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	turnNo, err := model.ParseTurnNo(r.PathValue("turn"))
+	if err != nil {
+		http.Error(w, "Invalid turn", http.StatusBadRequest)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	if !slices.Contains(layoutData.Turns, turnNo) {
+		http.Error(w, "Turn not found", http.StatusNotFound)
+		return
+	}
+	gameID, clanNo := layoutData.CurrentGameID, layoutData.CurrentClanNo
+
+	cm, err := h.store.ClanMap(r.Context(), gameID, clanNo, turnNo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("map", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if cm == nil || h.dataDir == "" {
+		http.Error(w, "Map not found", http.StatusNotFound)
+		return
+	}
+
+	f, err := stages.ContextFS(r.Context(), h.fs).Open(filepath.Join(h.dataDir, cm.FsPath))
+	if err != nil {
+		logging.FromContext(r.Context()).Error("map", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Map not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	name := fmt.Sprintf("%s.%s.%04d.map.svg", gameID, turnNo, clanNo)
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
+	http.ServeContent(w, r, name, cm.CreatedAt, f)
+}
+
+// signedURLTTL is how long a download link to an original report file lasts.
+const signedURLTTL = 5 * time.Minute
+
+// TurnOriginal downloads one of the clan's original report files for the turn.
+// The file is checked against the SHA-256 recorded when it was ingested. If
+// the data directory is on object storage that can sign URLs, the browser is
+// redirected to a short-lived signed URL instead of the server relaying the file.
+func (h *Handlers) TurnOriginal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	turnNo, err := model.ParseTurnNo(r.PathValue("turn"))
+	if err != nil {
+		http.Error(w, "Invalid turn", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid report file", http.StatusBadRequest)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	if !slices.Contains(layoutData.Turns, turnNo) {
+		http.Error(w, "Turn not found", http.StatusNotFound)
+		return
+	}
+	gameID, clanNo := layoutData.CurrentGameID, layoutData.CurrentClanNo
+
+	rf, err := h.store.GetReportFileByID(r.Context(), id)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("original", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyReportFileID, id, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	// report files of other clans, games, or turns don't exist as far as the user knows
+	if rf == nil || rf.Game != gameID || rf.ClanNo != fmt.Sprintf("%04d", clanNo) || rf.TurnNo != turnNo || rf.FsPath == "" || h.dataDir == "" {
+		http.Error(w, "Report file not found", http.StatusNotFound)
+		return
+	}
+
+	path := filepath.Join(h.dataDir, rf.FsPath)
+	fsys := stages.ContextFS(r.Context(), h.fs)
+	if err := stages.VerifySHA256(fsys, path, rf.SHA256); err != nil {
+		logging.FromContext(r.Context()).Error("original", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyReportFileID, id, "err", err)
+		var errChecksum *stages.ErrChecksum
+		if errors.As(err, &errChecksum) {
+			http.Error(w, "Report file failed its integrity check", http.StatusInternalServerError)
+		} else {
+			http.Error(w, "Report file not found", http.StatusNotFound)
+		}
+		return
+	}
+
+	if signer, ok := fsys.(stages.URLSigner); ok {
+		url, err := signer.SignedURL(path, signedURLTTL)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("original", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyReportFileID, id, "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, url, http.StatusFound)
+		return
+	}
+
+	f, err := fsys.Open(path)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("original", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyReportFileID, id, "err", err)
+		http.Error(w, "Report file not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	if rf.Mime != "" {
+		w.Header().Set("Content-Type", rf.Mime)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(rf.FsPath)))
+	http.ServeContent(w, r, rf.FsPath, rf.CreatedAt, f)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// TurnPublicJSON downloads the current clan's public summary of the turn as
+// JSON (see model.PublicSummary). It has no coordinates or unit IDs, so players
+// can post it without giving away where they are.
+func (h *Handlers) TurnPublicJSON(w http.ResponseWriter, r *http.Request) {
+	ps, name, ok := h.publicSummary(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(ps)
+}
+
+// TurnPublicMap draws the current clan's public summary of the turn as an SVG
+// map (see stages.RenderPublicMapSVG).
+func (h *Handlers) TurnPublicMap(w http.ResponseWriter, r *http.Request) {
+	ps, name, ok := h.publicSummary(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name+".svg"))
+	w.Write(stages.RenderPublicMapSVG(ps, "Turn "+ps.TurnNo.String()))
+}
+
+// publicSummary loads the public summary for the turn in the path. The file
+// name it returns leaves out the clan. On failure it writes the response and
+// returns false.
+func (h *Handlers) publicSummary(w http.ResponseWriter, r *http.Request) (*model.PublicSummary, string, bool) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, "", false
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, "", false
+	}
+
+	turnNo, err := model.ParseTurnNo(r.PathValue("turn"))
+	if err != nil {
+		http.Error(w, "Invalid turn", http.StatusBadRequest)
+		return nil, "", false
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	if !slices.Contains(layoutData.Turns, turnNo) {
+		http.Error(w, "Turn not found", http.StatusNotFound)
+		return nil, "", false
+	}
+	gameID, clanNo := layoutData.CurrentGameID, layoutData.CurrentClanNo
+
+	ps, err := h.store.PublicSummaryByGameClan(r.Context(), gameID, clanNo, turnNo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("public", logging.KeyGame, gameID, logging.KeyClan, clanNo, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, "", false
+	}
+	return ps, fmt.Sprintf("%s.%s.public", gameID, turnNo), true
+}
+
+// SetPublicRedaction saves what the current clan leaves out of its public turn
+// summaries. Expects a form value hide for each thing to leave out: terrain,
+// rivers, settlements, resources, encounters, or units. Anything not listed is
+// shown. The game is taken from ?game= like other pages.
+func (h *Handlers) SetPublicRedaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	if layoutData.CurrentClanNo == 0 {
+		http.Error(w, "No clan in this game", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	var redaction model.PublicRedaction
+	for _, hide := range r.Form["hide"] {
+		switch hide {
+		case "terrain":
+			redaction.HideTerrain = true
+		case "rivers":
+			redaction.HideRivers = true
+		case "settlements":
+			redaction.HideSettlements = true
+		case "resources":
+			redaction.HideResources = true
+		case "encounters":
+			redaction.HideEncounters = true
+		case "units":
+			redaction.HideUnits = true
+		default:
+			http.Error(w, "Invalid hide", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.store.SetPublicRedaction(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, redaction); err != nil {
+		logging.FromContext(r.Context()).Error("public: redaction", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/logging"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// queryFilterRows is the number of filter rows the query builder form has.
+const queryFilterRows = 3
+
+// QueryBuilder lets a player list their clan's units, steps, and encounters
+// with their own filters, columns, and sort, without the SQL console (see
+// store.RunQuery). The form is submitted with GET so that a query can be
+// bookmarked. Query parameters: entity, col (repeated), field, op, and value
+// (repeated, one of each per filter), sort, desc=1, limit, dedup=1 to show
+// repeated sightings once (see store.QueryEntity.Dedups), and run=1 to run it.
+func (h *Handlers) QueryBuilder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	q := parseQueryForm(r.URL.Query())
+	entity := store.QueryEntityByName(q.Entity)
+	if entity == nil {
+		http.Error(w, "Unknown entity", http.StatusBadRequest)
+		return
+	}
+
+	var result *store.QueryResult
+	if r.URL.Query().Get("run") == "1" {
+		var err error
+		result, err = h.store.RunQuery(r.Context(), data.CurrentGameID, data.CurrentClanNo, q)
+		if cerrs.CodeOf(err) == cerrs.CodeInvalidInput {
+			// shown with the form so that the user can fix it
+			result = &store.QueryResult{Error: err.Error()}
+		} else if err != nil {
+			logging.FromContext(r.Context()).Error("query", logging.KeyGame, data.CurrentGameID, logging.KeyClan, data.CurrentClanNo, "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.QueryBuilderPage(entity, q, queryFilterRows, result, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// parseQueryForm reads a store.Query from the query builder's form. Filter
+// rows without a field are skipped; the entity defaults to units.
+func parseQueryForm(form url.Values) store.Query {
+	q := store.Query{
+		Entity:  form.Get("entity"),
+		Columns: form["col"],
+		Sort:    form.Get("sort"),
+		Desc:    form.Get("desc") == "1",
+		Dedup:   form.Get("dedup") == "1",
+	}
+	if q.Entity == "" {
+		q.Entity = store.QueryEntities[0].Name
+	}
+	q.Limit, _ = strconv.Atoi(form.Get("limit"))
+	fields, ops, values := form["field"], form["op"], form["value"]
+	for i, field := range fields {
+		if field == "" || i >= len(ops) || i >= len(values) {
+			continue
+		}
+		q.Filters = append(q.Filters, store.QueryFilter{Field: field, Op: ops[i], Value: values[i]})
+	}
+	return q
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+const queueDefaultLimit = 50
+
+// GMQueue lists the most recent upload batches with their pipeline jobs by
+// status, the web version of "tnrpt pipeline status".
+// Query parameters: limit (default 50) and format=json.
+// Protected route: requires GM role.
+func (h *Handlers) GMQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := queueDefaultLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	batches, err := h.store.ListUploadBatches(r.Context(), limit)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	pauses, err := h.store.WorkPauses(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue: pauses", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if batches == nil {
+			batches = []model.BatchStatus{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(batches)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.GMQueuePage(batches, pauses, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// GMQueueBatch shows every pipeline job for the report files in a batch,
+// with its status and error.
+// Query parameter: format=json.
+// Protected route: requires GM role.
+func (h *Handlers) GMQueueBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	batchID, err := strconv.ParseInt(r.PathValue("batch"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid batch", http.StatusBadRequest)
+		return
+	}
+	batch, err := h.store.GetUploadBatch(r.Context(), batchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Batch not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue: batch", logging.KeyBatch, batchID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	files, err := h.store.GetReportFilesByBatch(r.Context(), batchID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue: batch files", logging.KeyBatch, batchID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	jobs, err := h.store.GetWorkByBatch(r.Context(), batchID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue: batch work", logging.KeyBatch, batchID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if files == nil {
+			files = []*model.ReportFile{}
+		}
+		if jobs == nil {
+			jobs = []model.Work{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Batch *model.UploadBatch  `json:"batch"`
+			Files []*model.ReportFile `json:"files"`
+			Work  []model.Work        `json:"work"`
+		}{batch, files, jobs})
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.GMQueueBatchPage(batch, files, jobs, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// GMQueueRetry puts a batch's failed jobs back in the queue. Expects form
+// value stage ("extract", "parse", or "render"); without it, failed jobs in
+// every stage are retried.
+// Protected route: requires GM role.
+func (h *Handlers) GMQueueRetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	batchID, err := strconv.ParseInt(r.PathValue("batch"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid batch", http.StatusBadRequest)
+		return
+	}
+	stage := r.FormValue("stage")
+	if stage != "" && !slices.Contains([]string{model.WorkStageExtract, model.WorkStageParse, model.WorkStageRender}, stage) {
+		http.Error(w, "Invalid stage", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.store.GetUploadBatch(r.Context(), batchID); errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Batch not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue: retry", logging.KeyBatch, batchID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	n, err := h.store.ResetFailedWorkByBatch(r.Context(), batchID, stage)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue: retry", logging.KeyBatch, batchID, logging.KeyStage, stage, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: queue: retried failed jobs", logging.KeyUser, h.currentHandle(r), logging.KeyBatch, batchID, logging.KeyStage, stage, "count", n)
+	http.Redirect(w, r, "/gm/queue/"+strconv.FormatInt(batchID, 10), http.StatusSeeOther)
+}
+
+// GMQueuePause stops workers from claiming jobs (see store.PauseWork).
+// Expects form values stage ("extract", "parse", "render", or empty for
+// every stage) and an optional reason.
+// Protected route: requires GM role.
+func (h *Handlers) GMQueuePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stage, ok := queueFormStage(w, r)
+	if !ok {
+		return
+	}
+	reason := strings.TrimSpace(r.FormValue("reason"))
+	if err := h.store.PauseWork(r.Context(), h.currentHandle(r), stage, reason); err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue: pause", logging.KeyStage, stage, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: queue: paused", logging.KeyUser, h.currentHandle(r), logging.KeyStage, stage, "reason", reason)
+	http.Redirect(w, r, "/gm/queue", http.StatusSeeOther)
+}
+
+// GMQueueResume lifts a pause set by GMQueuePause. Expects form value stage
+// as for GMQueuePause.
+// Protected route: requires GM role.
+func (h *Handlers) GMQueueResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stage, ok := queueFormStage(w, r)
+	if !ok {
+		return
+	}
+	resumed, err := h.store.ResumeWork(r.Context(), h.currentHandle(r), stage)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: queue: resume", logging.KeyStage, stage, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if resumed {
+		logging.FromContext(r.Context()).Info("gm: queue: resumed", logging.KeyUser, h.currentHandle(r), logging.KeyStage, stage)
+	}
+	http.Redirect(w, r, "/gm/queue", http.StatusSeeOther)
+}
+
+// queueFormStage returns the stage form value, which may be empty for every
+// stage. On an invalid stage it writes the response and returns false.
+func queueFormStage(w http.ResponseWriter, r *http.Request) (string, bool) {
+	stage := r.FormValue("stage")
+	if stage != "" && !slices.Contains([]string{model.WorkStageExtract, model.WorkStageParse, model.WorkStageRender}, stage) {
+		http.Error(w, "Invalid stage", http.StatusBadRequest)
+		return "", false
+	}
+	return stage, true
+}
+
+// GMReassignReport moves a report file uploaded under the wrong game, clan,
+// or turn, with the reports parsed from it (see store.ReassignReportFile).
+// Expects form values game, clan (e.g. "0512"), turn ("YYYY-MM"), and
+// optionally reparse ("1" to parse the file again instead of moving its
+// parsed reports). Redirects to the file's batch.
+// Protected route: requires GM role.
+func (h *Handlers) GMReassignReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid report file", http.StatusBadRequest)
+		return
+	}
+	gameID := strings.TrimSpace(r.FormValue("game"))
+	clan := strings.TrimSpace(r.FormValue("clan"))
+	turnNo, err := model.ParseTurnNo(strings.TrimSpace(r.FormValue("turn")))
+	if err != nil {
+		http.Error(w, "Turn must look like 0901-01.", http.StatusBadRequest)
+		return
+	}
+	reparse := r.FormValue("reparse") == "1"
+
+	ids, err := h.store.UnitIDRules(r.Context(), gameID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: reassign", logging.KeyReportFileID, id, logging.KeyGame, gameID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := ids.ValidateClan(clan); err != nil {
+		http.Error(w, "Invalid clan: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	old, err := h.store.ReassignReportFile(r.Context(), h.currentHandle(r), id, gameID, clan, turnNo, reparse)
+	if status := cerrs.HTTPStatus(cerrs.CodeOf(err)); err != nil && status < 500 {
+		http.Error(w, err.Error(), status)
+		return
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("gm: reassign", logging.KeyReportFileID, id, logging.KeyGame, gameID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: reassigned report", logging.KeyUser, h.currentHandle(r), logging.KeyReportFileID, id,
+		logging.KeyGame, gameID, logging.KeyClan, clan, logging.KeyTurn, turnNo.String(), "reparse", reparse)
+
+	if old.BatchID == nil {
+		http.Redirect(w, r, "/gm/queue", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/gm/queue/"+strconv.FormatInt(*old.BatchID, 10), http.StatusSeeOther)
+}
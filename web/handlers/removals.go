@@ -0,0 +1,270 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// Reports lists the report files uploaded for the current clan, with the
+// removal requests made for them.
+// Protected route: requires authentication.
+func (h *Handlers) Reports(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+	gameID, clanNo := data.CurrentGameID, fmt.Sprintf("%04d", data.CurrentClanNo)
+
+	files, err := h.store.GetReportFilesByGameClan(r.Context(), gameID, clanNo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("reports", logging.KeyGame, gameID, logging.KeyClan, clanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	requests, err := h.store.RemovalRequests(r.Context(), gameID, clanNo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("reports", logging.KeyGame, gameID, logging.KeyClan, clanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.ReportsPage(files, requests, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// RequestRemoval asks a GM to remove a report file from the current clan,
+// usually one that was uploaded under the wrong clan, and emails the GMs when
+// mail is configured. Expects the form value reason. Redirects to /reports.
+// Protected route: requires authentication.
+func (h *Handlers) RequestRemoval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid report file", http.StatusBadRequest)
+		return
+	}
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	gameID, clanNo := data.CurrentGameID, fmt.Sprintf("%04d", data.CurrentClanNo)
+
+	rf, err := h.store.GetReportFileByID(r.Context(), id)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("reports: request removal", logging.KeyReportFileID, id, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	// report files of other clans or games don't exist as far as the user knows
+	if rf == nil || rf.Game != gameID || rf.ClanNo != clanNo {
+		http.Error(w, "Report file not found", http.StatusNotFound)
+		return
+	}
+
+	req, err := h.store.RequestRemoval(r.Context(), h.currentHandle(r), id, r.FormValue("reason"))
+	if status := cerrs.HTTPStatus(cerrs.CodeOf(err)); err != nil && status < 500 {
+		http.Error(w, err.Error(), status)
+		return
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("reports: request removal", logging.KeyReportFileID, id, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("reports: removal requested", logging.KeyUser, req.RequestedBy, logging.KeyReportFileID, id,
+		logging.KeyGame, req.Game, logging.KeyClan, req.ClanNo)
+
+	if h.mailer != nil {
+		emails, err := h.store.GMEmails(r.Context())
+		if err != nil {
+			logging.FromContext(r.Context()).Error("reports: gm emails", "err", err)
+		}
+		body := fmt.Sprintf(`%s asked for a report file to be removed from clan %s in game %s:
+
+  %s (turn %s)
+
+Reason: %s
+
+Approve or reject the request at:
+
+  %s/gm/removals
+`, req.RequestedBy, req.ClanNo, req.Game, req.FileName, req.TurnNo, removalText(req.Reason), h.baseURL)
+		for _, email := range emails {
+			if err := h.mailer.Send(r.Context(), email, "Report removal request", body); err != nil {
+				logging.FromContext(r.Context()).Error("reports: notify gm", "err", err)
+			}
+		}
+	}
+
+	http.Redirect(w, r, "/reports", http.StatusSeeOther)
+}
+
+// GMRemovals lists the removal requests for the current game, open ones
+// first. Query parameters: format=json.
+// Protected route: requires GM role.
+func (h *Handlers) GMRemovals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	requests, err := h.store.RemovalRequests(r.Context(), data.CurrentGameID, "")
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: removals", logging.KeyGame, data.CurrentGameID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if requests == nil {
+			requests = []*model.RemovalRequest{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(requests)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.GMRemovalsPage(requests, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// GMApproveRemoval approves a removal request: the report file and everything
+// derived from it are purged (see store.PurgeReportFile), and its files are
+// deleted from storage. Expects the form value note, the reply to the player.
+// Redirects to /gm/removals.
+// Protected route: requires GM role.
+func (h *Handlers) GMApproveRemoval(w http.ResponseWriter, r *http.Request) {
+	h.decideRemoval(w, r, true)
+}
+
+// GMRejectRemoval rejects a removal request and keeps the report file.
+// Expects the form value note, the reply to the player. Redirects to
+// /gm/removals.
+// Protected route: requires GM role.
+func (h *Handlers) GMRejectRemoval(w http.ResponseWriter, r *http.Request) {
+	h.decideRemoval(w, r, false)
+}
+
+func (h *Handlers) decideRemoval(w http.ResponseWriter, r *http.Request, approve bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid removal request", http.StatusBadRequest)
+		return
+	}
+
+	var req *model.RemovalRequest
+	var purge *store.ReportPurge
+	if approve {
+		req, purge, err = h.store.ApproveRemoval(r.Context(), h.currentHandle(r), id, r.FormValue("note"))
+	} else {
+		req, err = h.store.RejectRemoval(r.Context(), h.currentHandle(r), id, r.FormValue("note"))
+	}
+	if status := cerrs.HTTPStatus(cerrs.CodeOf(err)); err != nil && status < 500 {
+		http.Error(w, err.Error(), status)
+		return
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("gm: removal", "request", id, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: removal decided", logging.KeyUser, req.DecidedBy, "request", id,
+		logging.KeyGame, req.Game, logging.KeyClan, req.ClanNo, "status", req.Status)
+
+	if purge != nil {
+		h.removePurgedFiles(r.Context(), purge)
+	}
+	if h.mailer != nil {
+		h.notifyRemovalDecided(r.Context(), req)
+	}
+	http.Redirect(w, r, "/gm/removals", http.StatusSeeOther)
+}
+
+// removePurgedFiles deletes a purged report file's upload, its extracted
+// text, and its maps from storage. The rows are already gone, so failures are
+// logged and left for the GM to clean up.
+func (h *Handlers) removePurgedFiles(ctx context.Context, purge *store.ReportPurge) {
+	if h.dataDir == "" || purge.File.FsPath == "" {
+		return
+	}
+	paths := append([]string{purge.File.FsPath}, purge.MapPaths...)
+	if txt := stages.ReportTextPath(purge.File); txt != purge.File.FsPath {
+		paths = append(paths, txt)
+	}
+	fsys := stages.ContextFS(ctx, h.fs)
+	for _, path := range paths {
+		if err := fsys.Remove(filepath.Join(h.dataDir, path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logging.FromContext(ctx).Error("gm: removal: delete file", logging.KeyReportFileID, purge.File.ID, "path", path, "err", err)
+		}
+	}
+}
+
+// notifyRemovalDecided emails the player who asked for a removal with the GM's
+// decision. Players without an email address aren't told.
+func (h *Handlers) notifyRemovalDecided(ctx context.Context, req *model.RemovalRequest) {
+	handle, email, err := h.store.UserEmail(ctx, req.RequestedBy)
+	if err != nil {
+		logging.FromContext(ctx).Error("gm: removal: user email", logging.KeyUser, req.RequestedBy, "err", err)
+		return
+	} else if email == "" {
+		return
+	}
+	outcome := "was removed"
+	if req.Status == model.RemovalRejected {
+		outcome = "was kept"
+	}
+	body := fmt.Sprintf(`Hello %s,
+
+You asked for a report file to be removed from clan %s in game %s:
+
+  %s (turn %s)
+
+The report %s by %s.
+
+Note: %s
+`, handle, req.ClanNo, req.Game, req.FileName, req.TurnNo, outcome, req.DecidedBy, removalText(req.Note))
+	if err := h.mailer.Send(ctx, email, "Your report removal request", body); err != nil {
+		logging.FromContext(ctx).Error("gm: removal: notify player", logging.KeyUser, handle, "err", err)
+	}
+}
+
+// removalText returns s, or "(none)" when it's empty.
+func removalText(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
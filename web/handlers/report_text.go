@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/norm"
+	"github.com/mdhender/tnrpt/pipelines/lint"
+	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+	"github.com/spf13/afero"
+)
+
+// GMReportText shows the text a report file is parsed from, one numbered
+// line at a time, so that a diagnostic's line number can be followed to the
+// line itself. The text is parsed again to label each line with the unit and
+// act read from it, and to list the parser's diagnostics and what the game's
+// lint rules find.
+// Query parameters: line (the line to highlight) or unit (redirects to the
+// unit's header line).
+// Protected route: requires GM role.
+func (h *Handlers) GMReportText(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid report file", http.StatusBadRequest)
+		return
+	}
+	line := 0
+	if s := r.URL.Query().Get("line"); s != "" {
+		if line, err = strconv.Atoi(s); err != nil || line < 1 {
+			http.Error(w, "Invalid line", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rf, err := h.store.GetReportFileByID(r.Context(), id)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: report text", logging.KeyReportFileID, id, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if rf == nil || rf.FsPath == "" || h.dataDir == "" {
+		http.Error(w, "Report file not found", http.StatusNotFound)
+		return
+	}
+	data, err := afero.ReadFile(stages.ContextFS(r.Context(), h.fs), filepath.Join(h.dataDir, stages.ReportTextPath(rf)))
+	if errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, "Report text not found; DOCX files have text once the extract stage has run", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("gm: report text", logging.KeyReportFileID, id, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	ids, err := h.store.UnitIDRules(r.Context(), rf.Game)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: report text", logging.KeyReportFileID, id, logging.KeyGame, rf.Game, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	lintCfg, err := h.store.LintConfigForGame(r.Context(), rf.Game)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: report text", logging.KeyReportFileID, id, logging.KeyGame, rf.Game, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	world, err := h.store.WorldRules(r.Context(), rf.Game)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: report text", logging.KeyReportFileID, id, logging.KeyGame, rf.Game, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	lines, diags, headers := reportTextLines(rf, data, ids, h.linter, world, lintCfg)
+	if unitID := r.URL.Query().Get("unit"); unitID != "" && line == 0 {
+		if n, ok := headers[unitID]; ok {
+			http.Redirect(w, r, fmt.Sprintf("/gm/reports/%d/text?line=%d#L%d", id, n, n), http.StatusSeeOther)
+			return
+		}
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	layoutData := h.getLayoutData(r, session)
+	layoutData.HideTurnSelect = true
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.GMReportTextPage(rf, lines, diags, line, layoutData).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// reportTextLines splits report text into lines labeled with the units and
+// acts parsed from them. It returns the parser's diagnostics, which are
+// expected on a report that failed to parse, followed by the linter's, and
+// each unit's header line.
+func reportTextLines(rf *model.ReportFile, data []byte, ids model.UnitIDRules, linter *lint.Linter, world model.WorldRules, cfg model.LintConfig) ([]templates.ReportTextLine, []string, map[string]int) {
+	var diags []string
+	data, enc := norm.ToUTF8(data)
+	if enc != norm.EncodingUTF8 {
+		diags = append(diags, "encoding: converted from "+string(enc)+" to UTF-8")
+	}
+
+	var lines []templates.ReportTextLine
+	for i, text := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		lines = append(lines, templates.ReportTextLine{No: i + 1, Text: strings.TrimSuffix(text, "\r")})
+	}
+
+	// parse as the parse stage does, less the experimental parser flags,
+	// so line numbers agree
+	turn, err := bistre.ParseInput(rf.Name, rf.TurnNo.String(), data, bistre.ParseConfig{AcceptLoneDash: true})
+	if err != nil {
+		return lines, append(diags, "parse: "+err.Error()), nil
+	}
+	diags = append(diags, adapters.ResolveDuplicateUnits(turn)...)
+	rx, err := adapters.BistreTurnToModelReportX(rf.Name, turn, rf.Game, rf.ClanNo, ids, nil)
+	if err != nil {
+		return lines, append(diags, "adapt: "+err.Error()), nil
+	}
+
+	for _, d := range linter.Run(&lint.Report{RX: rx, Text: data, World: world}, cfg) {
+		diags = append(diags, "lint: "+d.String())
+	}
+
+	headers := map[string]int{}
+	for _, e := range model.NewSourceMap(data, rx.Units).Entities() {
+		n := e.Start.Line
+		if n < 1 || n > len(lines) {
+			continue
+		}
+		switch e.Kind {
+		case model.SourceUnit:
+			headers[e.UnitID] = n
+			lines[n-1].Notes = append(lines[n-1].Notes, "unit "+e.UnitID)
+		case model.SourceAct:
+			lines[n-1].Notes = append(lines[n-1].Notes, e.UnitID+" act "+strconv.Itoa(e.ActSeq)+" "+string(e.Act.Kind))
+		}
+	}
+	return lines, diags, headers
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/handlers"
+	"github.com/spf13/afero"
+)
+
+// reportTextStore implements the store methods the report text page calls.
+type reportTextStore struct {
+	handlers.Store
+	rf *model.ReportFile
+}
+
+func (f *reportTextStore) IsUserGM(ctx context.Context, handle string) (bool, error) {
+	return true, nil
+}
+
+func (f *reportTextStore) GetGamesForUser(ctx context.Context, handle string) ([]store.UserGame, error) {
+	return nil, nil
+}
+
+func (f *reportTextStore) TurnsByGameClan(gameID string, clanNo int) ([]model.TurnNo, error) {
+	return nil, nil
+}
+
+func (f *reportTextStore) GetUserTheme(ctx context.Context, handle string) (string, error) {
+	return "", nil
+}
+
+func (f *reportTextStore) PendingReports(ctx context.Context, gameID string) (int, error) {
+	return 0, nil
+}
+
+func (f *reportTextStore) UnitIDRules(ctx context.Context, gameID string) (model.UnitIDRules, error) {
+	return model.DefaultUnitIDRules, nil
+}
+
+func (f *reportTextStore) LintConfigForGame(ctx context.Context, gameID string) (model.LintConfig, error) {
+	return nil, nil
+}
+
+func (f *reportTextStore) WorldRules(ctx context.Context, gameID string) (model.WorldRules, error) {
+	return model.DefaultWorldRules, nil
+}
+
+func (f *reportTextStore) GetReportFileByID(ctx context.Context, id int64) (*model.ReportFile, error) {
+	if id != f.rf.ID {
+		return nil, nil
+	}
+	return f.rf, nil
+}
+
+func TestGMReportText(t *testing.T) {
+	const text = "Tribe 0987, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)\n" +
+		"Current Turn 900-01 (#1), Winter, FINE Next Turn 900-02 (#2), 15/12/2025\n" +
+		"\n" +
+		"0987 Status: PRAIRIE, L NW 0987\n" +
+		"\n" +
+		"Courier 0987c1, , Current Hex = QQ 1010, (Previous Hex = QQ 1010)\n" +
+		"Current Turn 900-01 (#1), Winter, FINE\n" +
+		"\n" +
+		"0987c1 Status: PRAIRIE, L NW 0987\n"
+	fsys := afero.NewMemMapFs()
+	if err := afero.WriteFile(fsys, "/data/0900-01.0987.txt", []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fake := &reportTextStore{rf: &model.ReportFile{ID: 7, Name: "0900-01.0987.txt", Game: "0301", ClanNo: "0987", TurnNo: 90001, FsPath: "0900-01.0987.txt"}}
+	sessions := auth.NewSessionStore()
+	h := handlers.New(fake, sessions)
+	h.SetDataDir("/data")
+	h.SetFS(fsys)
+	handler := h.RequireGM(h.GMReportText)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.SetPathValue("id", strings.Split(target, "/")[3])
+		session, err := sessions.Create(auth.User{Handle: "gm"}, r)
+		if err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		r.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: session.ID})
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	w := get("/gm/reports/7/text?line=6")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	body := w.Body.String()
+	for _, want := range []string{`<tr id="L6" class="highlight">`, `<tr id="L9">`, "unit 0987c1", `href="/gm/reports/7/text?line=9#L9"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page is missing %s", want)
+		}
+	}
+
+	if w := get("/gm/reports/7/text?unit=0987c1"); w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/gm/reports/7/text?line=6#L6" {
+		t.Errorf("unit: status = %d, location = %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := get("/gm/reports/8/text"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown file: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
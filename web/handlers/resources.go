@@ -5,6 +5,7 @@ package handlers
 import (
 	"net/http"
 
+	store "github.com/mdhender/tnrpt/stores/sqlite"
 	"github.com/mdhender/tnrpt/web/auth"
 	"github.com/mdhender/tnrpt/web/templates"
 )
@@ -21,9 +22,19 @@ func (h *Handlers) Resources(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.notModified(w, r, session) {
+		return
+	}
+
 	layoutData := h.getLayoutData(r, session)
 
-	resources, err := h.store.ResourcesByGameClan(layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	var resources []store.Resource
+	var err error
+	if layoutData.AsOf && layoutData.SelectedTurn > 0 {
+		resources, err = h.store.ResourcesByGameClanAsOf(layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	} else {
+		resources, err = h.store.ResourcesByGameClan(layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	}
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// Scouting shows, for each unit and turn, how many of its steps told the clan
+// something new about the map (a hex seen for the first time, changed
+// terrain, or a new settlement) and how many only confirmed what was known,
+// so players can judge how well their scouts are being used. If a turn is
+// selected, later turns are left out.
+// With ?format=json, the counts and each step's news are returned as JSON.
+// An htmx request gets just the latest turn's counts, for the units page.
+func (h *Handlers) Scouting(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.notModified(w, r, session) {
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+
+	feed, err := h.store.MapNewsByGameClan(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("scouting", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	summary := model.SummarizeMapNews(feed)
+
+	if r.URL.Query().Get("format") == "json" {
+		if feed == nil {
+			feed = []model.StepNews{}
+		}
+		if summary == nil {
+			summary = []model.UnitNews{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Units []model.UnitNews `json:"units"`
+			Steps []model.StepNews `json:"steps"`
+		}{summary, feed})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if r.Header.Get("HX-Request") == "true" {
+		if err := templates.RecentScouting(summary, layoutData).Render(r.Context(), w); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := templates.ScoutingPage(summary, layoutData).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// SessionsPage lists the current user's active sessions.
+func (h *Handlers) SessionsPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	data := h.getLayoutData(r, session)
+	sessions, err := h.sessions.ListForUser(r.Context(), session.User.Handle)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("sessions", logging.KeyUser, session.User.Handle, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := templates.SessionsPage(sessions, session.Ref, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// RevokeSession logs the current user out of one of their other sessions.
+func (h *Handlers) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	revoked, err := h.sessions.Revoke(r.Context(), session.User.Handle, r.PathValue("ref"))
+	if err != nil {
+		logging.FromContext(r.Context()).Error("sessions: revoke", logging.KeyUser, session.User.Handle, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	logging.FromContext(r.Context()).Info("sessions: revoked a session", logging.KeyUser, session.User.Handle)
+	http.Redirect(w, r, "/sessions", http.StatusSeeOther)
+}
+
+// GMLogoutUser ends every session a user has.
+// Protected route: requires GM role.
+func (h *Handlers) GMLogoutUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	handle := r.PathValue("handle")
+	n, err := h.sessions.RevokeAll(r.Context(), handle)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: users: logout", logging.KeyUser, h.currentHandle(r), "target", handle, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: users: logged out", logging.KeyUser, h.currentHandle(r), "target", handle, "sessions", n)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "%d sessions ended for %s\n", n, handle)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// ShareMap grants or revokes another clan's access to the current clan's tiles
+// in the alliance view. Expects form values clan (the ally) and action
+// ("grant" or "revoke"); the game is taken from ?game= like other pages.
+func (h *Handlers) ShareMap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	if layoutData.CurrentClanNo == 0 {
+		http.Error(w, "No clan in this game", http.StatusForbidden)
+		return
+	}
+
+	ally, err := strconv.Atoi(r.FormValue("clan"))
+	if err != nil || ally <= 0 || ally == layoutData.CurrentClanNo {
+		http.Error(w, "Invalid clan", http.StatusBadRequest)
+		return
+	}
+
+	switch r.FormValue("action") {
+	case "grant":
+		err = h.store.GrantClanShare(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, ally)
+	case "revoke":
+		_, err = h.store.RevokeClanShare(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, ally)
+	default:
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("shares", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "ally", ally, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
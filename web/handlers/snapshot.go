@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/mdhender/tnrpt/logging"
+)
+
+// Snapshot downloads a consistent, read-only copy of the live database so
+// that analytics can run against it without contending with uploads.
+// The copy is made with VACUUM INTO in a temporary directory and removed
+// once it has been sent.
+// Protected route: requires GM role.
+func (h *Handlers) Snapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "tnrpt-snapshot-")
+	if err != nil {
+		logging.FromContext(r.Context()).Error("admin: snapshot", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	started := h.clock.Now()
+	path := filepath.Join(dir, "tnrpt.db")
+	if err := h.store.Snapshot(r.Context(), path); err != nil {
+		logging.FromContext(r.Context()).Error("admin: snapshot", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("admin: snapshot", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		logging.FromContext(r.Context()).Error("admin: snapshot", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("admin: snapshot", logging.KeyUser, h.currentHandle(r), "bytes", fi.Size(), "elapsed", h.clock.Now().Sub(started))
+
+	name := fmt.Sprintf("tnrpt-%s.db", started.UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, name, started, f)
+}
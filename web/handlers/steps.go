@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/stream"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// StepsExport downloads the current clan's steps as a JSON array with one
+// record per step, stamped with the hexes it went from and to (see
+// model.StepRecord). This is the flat dataset map bots want, so they don't
+// have to understand the database schema. The records are streamed as they
+// are made (see writeStepRecords).
+// Query parameters: game, clan, turn (default all turns), and format=csv.
+func (h *Handlers) StepsExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	gameID, clanNo, turnNo := layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn
+	if clanNo == 0 {
+		http.Error(w, "Clan not found", http.StatusNotFound)
+		return
+	}
+
+	csv := r.URL.Query().Get("format") == "csv"
+	name := fmt.Sprintf("%s.%04d.steps", gameID, clanNo)
+	if turnNo != 0 {
+		name = fmt.Sprintf("%s.%s.%04d.steps", gameID, turnNo, clanNo)
+	}
+	if csv {
+		name += ".csv"
+	} else {
+		name += ".json"
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	err := h.writeStepRecords(w, r, gameID, clanNo, turnNo, csv)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("steps", logging.KeyGame, gameID, logging.KeyClan, clanNo, "err", err)
+		if errors.Is(err, errNothingWritten) {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// APISteps returns a clan's steps like StepsExport does, for bots working for
+// the GM. Query parameters: game, clan (e.g. "0512"), turn (default all
+// turns), and format=csv.
+// Protected route: requires an API token with the GM scope.
+func (h *Handlers) APISteps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	game, clan := r.URL.Query().Get("game"), r.URL.Query().Get("clan")
+	if !gameIDPattern.MatchString(game) {
+		writeAPIError(w, http.StatusBadRequest, "game must be 4 digits")
+		return
+	}
+	if !h.validateAPIClan(w, r, game, clan) {
+		return
+	}
+	clanNo, _ := strconv.Atoi(clan)
+	var turnNo model.TurnNo
+	if s := r.URL.Query().Get("turn"); s != "" {
+		var err error
+		if turnNo, err = model.ParseTurnNo(s); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid turn")
+			return
+		}
+	}
+
+	err := h.writeStepRecords(w, r, game, clanNo, turnNo, r.URL.Query().Get("format") == "csv")
+	if err != nil {
+		logging.FromContext(r.Context()).Error("api: steps", logging.KeyGame, game, logging.KeyClan, clanNo, "err", err)
+		if errors.Is(err, errNothingWritten) {
+			writeAPIError(w, http.StatusInternalServerError, "internal server error")
+		}
+	}
+}
+
+// errNothingWritten wraps an error from writeStepRecords that happened before
+// any of the response was written, so the caller can still send an error.
+var errNothingWritten = errors.New("nothing written")
+
+// writeStepRecords streams the clan's step records as a JSON array, or as CSV
+// rows (see model.StepRecord.CSV), flushing the response as it goes. Once a
+// record has been written the status can't change, so later errors are only
+// returned for logging.
+func (h *Handlers) writeStepRecords(w http.ResponseWriter, r *http.Request, gameID string, clanNo int, turnNo model.TurnNo, csv bool) error {
+	fw := flushWriter{w, http.NewResponseController(w)}
+	var write func(model.StepRecord) error
+	var close func() error
+	if csv {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		cw, err := stream.NewCSV(fw, model.StepRecordCSVHeader)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errNothingWritten, err)
+		}
+		write = func(rec model.StepRecord) error { return cw.Write(rec.CSV()) }
+		close = cw.Close
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		ja := stream.NewJSONArray(fw, false)
+		write = func(rec model.StepRecord) error { return ja.Write(rec) }
+		close = ja.Close
+	}
+
+	// the writers buffer, so nothing has gone out until the first record
+	written := 0
+	err := h.store.EachStepRecord(r.Context(), gameID, clanNo, turnNo, func(rec model.StepRecord) error {
+		written++
+		return write(rec)
+	})
+	if err != nil && written == 0 {
+		return fmt.Errorf("%w: %w", errNothingWritten, err)
+	} else if err != nil {
+		return err
+	}
+	return close()
+}
+
+// flushWriter is a response writer that stream can flush, including through
+// middleware that wraps the response (see statusWriter.Unwrap).
+type flushWriter struct {
+	http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// Flush sends the buffered response to the client. Writers that can't flush
+// are left to send it when the handler returns.
+func (f flushWriter) Flush() error {
+	if err := f.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
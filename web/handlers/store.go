@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/pipelines/stages"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+)
+
+// UnitReader defines the store operations the unit, movement, resource,
+// print, step export, activity, scouting, and query builder pages read from.
+type UnitReader interface {
+	Units(orderBy string) ([]*model.UnitX, error)
+	UnitsByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]*model.UnitX, error)
+	UnitByIDAndGameClan(id int64, gameID string, clanNo int) (*model.UnitX, error)
+	UnitEventsByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]model.UnitEvent, error)
+	UnitLocation(ctx context.Context, gameID string, clanNo int, unitID string, asOf model.TurnNo) (model.TNCoord, model.TurnNo, error)
+	MovementsByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]store.Movement, error)
+	EachStepRecord(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo, fn func(model.StepRecord) error) error
+	ActivityByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]model.Activity, error)
+	MapNewsByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]model.StepNews, error)
+	MoveBudgetsByGameClan(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) ([]model.MoveBudget, error)
+	ResourcesByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]store.Resource, error)
+	ResourcesByGameClanAsOf(gameID string, clanNo int, asOf model.TurnNo) ([]store.Resource, error)
+	TurnsByGameClan(gameID string, clanNo int) ([]model.TurnNo, error)
+	TurnConditions(gameID string, turnNo model.TurnNo) (season, weather string, err error)
+	RunQuery(ctx context.Context, gameID string, clanNo int, q store.Query) (*store.QueryResult, error)
+	OrdersByGameClanTurn(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) ([]model.Order, error)
+	ClanMap(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) (*model.ClanMap, error)
+}
+
+// TileReader defines the store operations the terrain, tile, coverage,
+// movement odds, and plan pages read from.
+type TileReader interface {
+	TerrainObservationsByGameClan(gameID string, clanNo int, turnNo model.TurnNo) ([]store.TerrainObs, error)
+	TerrainObservationsByGameClans(gameID string, clanNos []int, turnNo model.TurnNo, asOf bool) ([]store.TerrainObs, error)
+	TileDetailByGameClanCoord(grid string, col, row int, gameID string, clanNo int, asOf model.TurnNo) (*store.TileDetail, error)
+	TileStepsByGameClanCoord(grid string, col, row int, gameID string, clanNo int, asOf model.TurnNo) ([]store.TileStep, error)
+	TileSourcesByGameClanCoord(grid string, col, row int, gameID string, clanNo int, asOf model.TurnNo) ([]store.TileSource, error)
+	TileByGameCoord(ctx context.Context, gameID string, tn model.TNCoord) (*model.Tile, error)
+	CoverageByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]model.HexCoverage, error)
+	KnownMapByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) (*model.KnownMap, error)
+	HexNeighborhoodByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo, c model.TNCoord) ([]model.HexNeighbor, error)
+	AlliedClans(ctx context.Context, gameID string, clanNo int) ([]int, error)
+	TerrainTransitionsByGameClan(ctx context.Context, gameID string, clanNo int, asOf model.TurnNo) ([]model.TerrainTransition, error)
+}
+
+// AuthStore defines the store operations for logging users in and for
+// what the layout shows about them.
+type AuthStore interface {
+	ValidateCredentials(ctx context.Context, handle, password, gameID string) (*auth.User, error)
+	IsUserGM(ctx context.Context, handle string) (bool, error)
+	APITokenUser(ctx context.Context, token, scope string) (string, error)
+	UserEmail(ctx context.Context, handleOrEmail string) (handle, email string, err error)
+	CreateLoginToken(ctx context.Context, handle string, ttl time.Duration) (string, error)
+	ConsumeLoginToken(ctx context.Context, token string) (*auth.User, error)
+	GetGamesForUser(ctx context.Context, handle string) ([]store.UserGame, error)
+	GetUserTheme(ctx context.Context, handle string) (string, error)
+	SetUserTheme(ctx context.Context, handle, theme string) error
+}
+
+// GameStore defines the store operations behind the GM pages.
+type GameStore interface {
+	GetAllGames(ctx context.Context) ([]store.Game, error)
+	CreateGame(ctx context.Context, id, description string) (bool, error)
+	AddGameTurn(ctx context.Context, gameID string, turnNo model.TurnNo, due time.Time) (bool, error)
+	SetActiveTurn(ctx context.Context, actor, gameID string, turnNo model.TurnNo) (bool, error)
+	SetGameAutoAdvance(ctx context.Context, gameID string, on bool) (bool, error)
+	SetTurnDueDate(ctx context.Context, gameID string, turnNo model.TurnNo, due time.Time) (bool, error)
+	GrantClanShare(ctx context.Context, gameID string, clanNo, sharedWith int) error
+	RevokeClanShare(ctx context.Context, gameID string, clanNo, sharedWith int) (bool, error)
+	SetPublicRedaction(ctx context.Context, gameID string, clanNo int, r model.PublicRedaction) error
+	PublicSummaryByGameClan(ctx context.Context, gameID string, clanNo int, turnNo model.TurnNo) (*model.PublicSummary, error)
+	ExportUsers(ctx context.Context, withHashes bool) ([]store.UserRecord, error)
+	ImportUsers(ctx context.Context, actor string, users []store.UserRecord) (store.UsersImport, error)
+	ExecRawQuery(ctx context.Context, query string) *store.QueryResult
+	Snapshot(ctx context.Context, path string) error
+	SlowestReports(ctx context.Context, limit int) ([]model.ReportTiming, error)
+	FeatureFlags(ctx context.Context) ([]model.FeatureFlag, error)
+	SetFeatureFlag(ctx context.Context, actor, name, gameID string, enabled bool) error
+	ClearFeatureFlag(ctx context.Context, actor, name, gameID string) (bool, error)
+}
+
+// ReportStore defines the store operations for uploading, exporting, and
+// tracking the processing of report files.
+type ReportStore interface {
+	stages.IngestStore
+
+	AddReport(rx *model.ReportX) error
+	AddReportFile(rf *model.ReportFile) error
+	SetReportTimings(ctx context.Context, rxID int64, t model.ParseTimings) error
+	RecordCorrection(ctx context.Context, rxID int64) (*model.ReportCorrection, error)
+	ReportCorrections(ctx context.Context, game string) ([]*model.ReportCorrection, error)
+	UnitIDRules(ctx context.Context, gameID string) (model.UnitIDRules, error)
+	GetReportFileByID(ctx context.Context, id int64) (*model.ReportFile, error)
+	GetReportFilesByGameTurn(ctx context.Context, game string, turnNo model.TurnNo) ([]*model.ReportFile, error)
+	ReportExtractsByGameTurn(ctx context.Context, game string, turnNo model.TurnNo) ([]*model.ReportX, error)
+	GetWorkByBatch(ctx context.Context, batchID int64) ([]model.Work, error)
+	ListUploadBatches(ctx context.Context, limit int) ([]model.BatchStatus, error)
+	GetReportFilesByBatch(ctx context.Context, batchID int64) ([]*model.ReportFile, error)
+	ResetFailedWorkByBatch(ctx context.Context, batchID int64, stage string) (int, error)
+	WorkPauses(ctx context.Context) ([]model.WorkPause, error)
+	PauseWork(ctx context.Context, actor, stage, reason string) error
+	ResumeWork(ctx context.Context, actor, stage string) (bool, error)
+	ReassignReportFile(ctx context.Context, actor string, id int64, gameID, clanNo string, turnNo model.TurnNo, reparse bool) (*model.ReportFile, error)
+	PendingReports(ctx context.Context, gameID string) (int, error)
+	LintConfigForGame(ctx context.Context, gameID string) (model.LintConfig, error)
+	WorldRules(ctx context.Context, gameID string) (model.WorldRules, error)
+	GetReportFilesByGameClan(ctx context.Context, game, clanNo string) ([]*model.ReportFile, error)
+	RequestRemoval(ctx context.Context, actor string, reportFileID int64, reason string) (*model.RemovalRequest, error)
+	RemovalRequests(ctx context.Context, gameID, clanNo string) ([]*model.RemovalRequest, error)
+	ApproveRemoval(ctx context.Context, actor string, id int64, note string) (*model.RemovalRequest, *store.ReportPurge, error)
+	RejectRemoval(ctx context.Context, actor string, id int64, note string) (*model.RemovalRequest, error)
+	GMEmails(ctx context.Context) ([]string, error)
+	Generation(ctx context.Context) (int64, time.Time, error)
+}
+
+// UsageStore defines the store operations for the local request log and the
+// usage statistics computed from it.
+type UsageStore interface {
+	RecordRequest(ctx context.Context, handle, route string, status int, d time.Duration) error
+	UsageStats(ctx context.Context, since time.Time, limit int) (*model.UsageStats, error)
+}
+
+// Store is everything the handlers need from the store. *store.SQLiteStore
+// implements it; tests can implement it with a fake that embeds Store and
+// overrides only the methods the handler under test calls.
+type Store interface {
+	UnitReader
+	TileReader
+	AuthStore
+	GameStore
+	ReportStore
+	UsageStore
+}
+
+var _ Store = (*store.SQLiteStore)(nil)
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/handlers"
+)
+
+// fakeStore implements only the store methods the GM users export calls.
+// Calling any other method panics on the nil embedded Store.
+type fakeStore struct {
+	handlers.Store
+	gms   map[string]bool
+	users []store.UserRecord
+}
+
+func (f *fakeStore) IsUserGM(ctx context.Context, handle string) (bool, error) {
+	return f.gms[handle], nil
+}
+
+func (f *fakeStore) ExportUsers(ctx context.Context, withHashes bool) ([]store.UserRecord, error) {
+	return f.users, nil
+}
+
+func TestGMUsersExport(t *testing.T) {
+	fake := &fakeStore{
+		gms:   map[string]bool{"gm": true},
+		users: []store.UserRecord{{Handle: "clan0500", Roles: []string{"user"}}},
+	}
+	sessions := auth.NewSessionStore()
+	h := handlers.New(fake, sessions)
+	handler := h.RequireGM(h.GMUsersExport)
+
+	get := func(handle string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/gm/users/export", nil)
+		session, err := sessions.Create(auth.User{Handle: handle}, r)
+		if err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+		r.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: session.ID})
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	if w := get("clan0500"); w.Code != http.StatusForbidden {
+		t.Errorf("non-GM: status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w := get("gm")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GM: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var got []store.UserRecord
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Handle != "clan0500" {
+		t.Errorf("exported users = %+v, want clan0500", got)
+	}
+}
@@ -3,9 +3,14 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
+	"slices"
 	"strconv"
 
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
 	"github.com/mdhender/tnrpt/web/auth"
 	"github.com/mdhender/tnrpt/web/templates"
 )
@@ -22,9 +27,42 @@ func (h *Handlers) Terrain(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.notModified(w, r, session) {
+		return
+	}
+
 	layoutData := h.getLayoutData(r, session)
 
-	observations, err := h.store.TerrainObservationsByGameClan(layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	// ?allies=1 merges in tiles from the user's other clans in the game and
+	// from clans that have shared their map with us
+	allies := r.URL.Query().Get("allies") == "1"
+
+	var observations []store.TerrainObs
+	var err error
+	if allies {
+		clans := []int{layoutData.CurrentClanNo}
+		for _, clanNo := range layoutData.Clans {
+			if clanNo != layoutData.CurrentClanNo {
+				clans = append(clans, clanNo)
+			}
+		}
+		allied, aerr := h.store.AlliedClans(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo)
+		if aerr != nil {
+			logging.FromContext(r.Context()).Error("terrain: allies", logging.KeyGame, layoutData.CurrentGameID, "err", aerr)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		for _, clanNo := range allied {
+			if !slices.Contains(clans, clanNo) {
+				clans = append(clans, clanNo)
+			}
+		}
+		observations, err = h.store.TerrainObservationsByGameClans(layoutData.CurrentGameID, clans, layoutData.SelectedTurn, layoutData.AsOf)
+	} else if layoutData.AsOf && layoutData.SelectedTurn > 0 {
+		observations, err = h.store.TerrainObservationsByGameClans(layoutData.CurrentGameID, []int{layoutData.CurrentClanNo}, layoutData.SelectedTurn, true)
+	} else {
+		observations, err = h.store.TerrainObservationsByGameClan(layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	}
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -33,13 +71,13 @@ func (h *Handlers) Terrain(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
 	if r.Header.Get("HX-Request") == "true" {
-		if err := templates.TerrainTable(observations).Render(r.Context(), w); err != nil {
+		if err := templates.TerrainTable(observations, allies).Render(r.Context(), w); err != nil {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
 		return
 	}
 
-	if err := templates.TerrainPageWithData(observations, layoutData).Render(r.Context(), w); err != nil {
+	if err := templates.TerrainPageWithData(observations, allies, layoutData).Render(r.Context(), w); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
@@ -70,11 +108,25 @@ func (h *Handlers) TileDetail(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid row", http.StatusBadRequest)
 		return
 	}
+	if err := model.NewTNCoord(grid, col, row).Validate(); err != nil {
+		http.Error(w, "Invalid coordinate", http.StatusBadRequest)
+		return
+	}
+
+	if h.notModified(w, r, session) {
+		return
+	}
 
 	layoutData := h.getLayoutData(r, session)
 	layoutData.HideTurnSelect = true
 
-	tile, err := h.store.TileDetailByGameClanCoord(grid, col, row, layoutData.CurrentGameID, layoutData.CurrentClanNo)
+	// ?turn=T&asof=1 shows the tile as the clan knew it at the end of turn T
+	var asOf model.TurnNo
+	if layoutData.AsOf {
+		asOf = layoutData.SelectedTurn
+	}
+
+	tile, err := h.store.TileDetailByGameClanCoord(grid, col, row, layoutData.CurrentGameID, layoutData.CurrentClanNo, asOf)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -84,9 +136,56 @@ func (h *Handlers) TileDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tile.Neighbors, err = h.store.HexNeighborhoodByGameClan(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, asOf, model.NewTNCoord(grid, col, row))
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	tile.Steps, err = h.store.TileStepsByGameClanCoord(grid, col, row, layoutData.CurrentGameID, layoutData.CurrentClanNo, asOf)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	tile.Sources, err = h.store.TileSourcesByGameClanCoord(grid, col, row, layoutData.CurrentGameID, layoutData.CurrentClanNo, asOf)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
 	if err := templates.TileDetailPage(tile, layoutData).Render(r.Context(), w); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
+
+// APITile returns the merged tile at a grid location, with its contents and
+// the provenance of each part (see model.TileSrc), for bots auditing how
+// reports were merged. Query parameters: game and tile (e.g. "QQ 1010").
+// Protected route: requires an API token with the GM scope.
+func (h *Handlers) APITile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	game := r.URL.Query().Get("game")
+	if !gameIDPattern.MatchString(game) {
+		writeAPIError(w, http.StatusBadRequest, "game must be 4 digits")
+		return
+	}
+	tn, err := model.ParseTNCoord(r.URL.Query().Get("tile"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "tile: "+err.Error())
+		return
+	}
+
+	tile, err := h.store.TileByGameCoord(r.Context(), game, tn)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("api: tile", logging.KeyGame, game, "tile", tn, "err", err)
+		writeAPIFailure(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tile)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/stream"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// Transitions shows how often the clan's moves from one terrain into another
+// have succeeded, by kind of unit, so players can calibrate the movement
+// points they plan with. If a turn is selected, only turns up to it count.
+// With ?format=csv, the counts are returned as CSV instead of a table.
+func (h *Handlers) Transitions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.notModified(w, r, session) {
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+
+	transitions, err := h.store.TerrainTransitionsByGameClan(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("transitions", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		name := fmt.Sprintf("%s.%04d.transitions.csv", layoutData.CurrentGameID, layoutData.CurrentClanNo)
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		cw, err := stream.NewCSV(flushWriter{w, http.NewResponseController(w)},
+			[]string{"unit_kind", "from", "to", "rulebook_mp", "attempts", "succeeded", "success_rate", "failures"})
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		for _, t := range transitions {
+			cw.Write([]string{
+				string(t.UnitKind),
+				t.From.String(),
+				t.To.String(),
+				t.To.MPCost(),
+				strconv.Itoa(t.Attempts),
+				strconv.Itoa(t.Succeeded),
+				strconv.FormatFloat(t.SuccessRate(), 'f', 3, 64),
+				templates.FailureSummary(t.Failures),
+			})
+		}
+		if err := cw.Close(); err != nil {
+			logging.FromContext(r.Context()).Error("transitions", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, "err", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.TransitionsPage(transitions, layoutData).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+// TurnDiff shows what changed for the clan in a turn: units that were created
+// and units that were disbanded since the clan's previous report.
+func (h *Handlers) TurnDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	if session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	turnNo, err := model.ParseTurnNo(r.PathValue("turn"))
+	if err != nil {
+		http.Error(w, "Invalid turn", http.StatusBadRequest)
+		return
+	}
+
+	if h.notModified(w, r, session) {
+		return
+	}
+
+	layoutData := h.getLayoutData(r, session)
+	if !slices.Contains(layoutData.Turns, turnNo) {
+		http.Error(w, "Turn not found", http.StatusNotFound)
+		return
+	}
+	layoutData.SelectedTurn = turnNo
+
+	events, err := h.store.UnitEventsByGameClan(layoutData.CurrentGameID, layoutData.CurrentClanNo, turnNo)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("diff", logging.KeyGame, layoutData.CurrentGameID, logging.KeyClan, layoutData.CurrentClanNo, logging.KeyTurn, turnNo.String(), "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.TurnDiffPage(turnNo, events, layoutData).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
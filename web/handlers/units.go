@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/mdhender/tnrpt"
+	"github.com/mdhender/tnrpt/model"
 	"github.com/mdhender/tnrpt/web/auth"
 	"github.com/mdhender/tnrpt/web/templates"
 )
@@ -22,6 +24,10 @@ func (h *Handlers) Units(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.notModified(w, r, session) {
+		return
+	}
+
 	layoutData := h.getLayoutData(r, session)
 
 	units, err := h.store.UnitsByGameClan(layoutData.CurrentGameID, layoutData.CurrentClanNo, layoutData.SelectedTurn)
@@ -63,6 +69,10 @@ func (h *Handlers) UnitDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.notModified(w, r, session) {
+		return
+	}
+
 	layoutData := h.getLayoutData(r, session)
 	layoutData.HideTurnSelect = true
 
@@ -76,13 +86,32 @@ func (h *Handlers) UnitDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the unit's orders for its turn, if the clan imported any
+	allOrders, err := h.store.OrdersByGameClanTurn(r.Context(), layoutData.CurrentGameID, layoutData.CurrentClanNo, unit.TurnNo)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	var orders []model.Order
+	for _, o := range allOrders {
+		if o.UnitID == unit.UnitID {
+			orders = append(orders, o)
+		}
+	}
+	divs := model.CheckOrders(orders, []*model.UnitX{unit})
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	if err := templates.UnitDetailPage(unit, layoutData).Render(r.Context(), w); err != nil {
+	if err := templates.UnitDetailPage(unit, orders, divs, layoutData).Render(r.Context(), w); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
+// authModeNoAuth is the footer notice for pages served without logins.
+const authModeNoAuth = "insecure dev mode: no login required"
+
+// UnitsNoAuth lists every unit without a login, for the bistre command's
+// --serve-no-auth development mode.
 func (h *Handlers) UnitsNoAuth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -104,7 +133,8 @@ func (h *Handlers) UnitsNoAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := templates.UnitsPage(units).Render(r.Context(), w); err != nil {
+	data := templates.LayoutData{Version: tnrpt.Version().String(), AuthMode: authModeNoAuth}
+	if err := templates.UnitsPageWithData(units, data).Render(r.Context(), w); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,7 +16,11 @@ import (
 	"time"
 
 	"github.com/mdhender/tnrpt/adapters"
+	"github.com/mdhender/tnrpt/cerrs"
+	"github.com/mdhender/tnrpt/logging"
 	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/norm"
+	"github.com/mdhender/tnrpt/pipelines/lint"
 	"github.com/mdhender/tnrpt/pipelines/parsers/bistre"
 	"github.com/mdhender/tnrpt/pipelines/parsers/docx"
 	"github.com/mdhender/tnrpt/pipelines/parsers/report"
@@ -24,25 +29,86 @@ import (
 )
 
 var (
-	// Clan numbers are 0001-0999 (must start with 0)
-	docxPattern             = regexp.MustCompile(`^(0\d{3})\.docx$`)
-	gameTurnClanDocxPattern = regexp.MustCompile(`^(\d{4})\.(\d{4}-\d{2})\.(0\d{3})\.docx$`)
-	gameTurnClanTextPattern = regexp.MustCompile(`^(\d{4})\.(\d{4}-\d{2})\.(0\d{3})\.report\.txt$`)
+	// Clans start with 0; the game's rules say how many digits follow (see model.ClanPattern)
+	docxPattern             = regexp.MustCompile(`^(` + model.ClanPattern + `)\.docx$`)
+	gameTurnClanDocxPattern = regexp.MustCompile(`^(\d{4})\.(\d{4}-\d{2})\.(` + model.ClanPattern + `)\.docx$`)
+	gameTurnClanTextPattern = regexp.MustCompile(`^(\d{4})\.(\d{4}-\d{2})\.(` + model.ClanPattern + `)\.report\.txt$`)
 )
 
 const (
 	docxContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
 )
 
+// Error codes returned in uploadResponse.Code so that the UI and scripts can
+// react to a failure without matching on the message. UNIT_ID_INVALID and
+// CLAN_MISMATCH are the cerrs codes the pipeline records for the same
+// failures, and failures the
+// uploader can't fix carry their cerrs code (see internalFailed).
+const (
+	uploadErrBadRequest      = "BAD_REQUEST"      // missing or malformed form fields
+	uploadErrFilenameInvalid = "FILENAME_INVALID" // name doesn't match an accepted pattern
+	uploadErrContentType     = "CONTENT_TYPE_INVALID"
+	uploadErrGameMismatch    = "GAME_MISMATCH"    // game in the filename isn't the selected game
+	uploadErrTurnMismatch    = "TURN_MISMATCH"    // turn in the filename isn't the selected turn
+	uploadErrParseFailed     = "PARSE_FAILED"     // see Diagnostics for details
+	uploadErrDuplicateReport = "DUPLICATE_REPORT" // the same file was already uploaded
+	uploadErrUnitIDInvalid   = "UNIT_ID_INVALID"  // see Diagnostics for the malformed unit ids
+	uploadErrClanMismatch    = "CLAN_MISMATCH"    // units in the report belong to another clan than the filename's
+	uploadErrInternal        = "INTERNAL"         // an error without an cerrs code
+)
+
 type uploadResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
-	Clan    string `json:"clan,omitempty"`
-	Game    string `json:"game,omitempty"`
-	Turn    string `json:"turn,omitempty"`
-	Units   int    `json:"units,omitempty"`
-	Acts    int    `json:"acts,omitempty"`
-	Steps   int    `json:"steps,omitempty"`
+	Success     bool               `json:"success"`
+	Code        string             `json:"code,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	Diagnostics []uploadDiagnostic `json:"diagnostics,omitempty"`
+	Clan        string             `json:"clan,omitempty"`
+	Game        string             `json:"game,omitempty"`
+	Turn        string             `json:"turn,omitempty"`
+	Units       int                `json:"units,omitempty"`
+	Acts        int                `json:"acts,omitempty"`
+	Steps       int                `json:"steps,omitempty"`
+	Batch       int64              `json:"batch,omitempty"` // set when the file is queued for the pipeline; see UploadEvents
+}
+
+// uploadDiagnostic is one problem found while parsing an uploaded report.
+// Stage is the step that reported it: "docx", "email", "encoding", "report",
+// "turn", "adapt", "clan", or "lint". A successful upload can still carry
+// "encoding", "report", "adapt", "clan", and "lint" diagnostics, e.g. for a
+// text report saved as UTF-16, lines the splitter skipped, a unit whose
+// section appears twice, units of another clan that the GM let through, or a
+// unit section without a status line. Only lint diagnostics have a severity.
+type uploadDiagnostic struct {
+	Stage    string `json:"stage"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message"`
+}
+
+// parseFailed builds a PARSE_FAILED response. Parsers that collect several
+// errors report them one per line, so each line becomes a diagnostic.
+func parseFailed(stage, msg string, err error) uploadResponse {
+	resp := uploadResponse{Code: uploadErrParseFailed, Error: msg + ": " + err.Error()}
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			resp.Diagnostics = append(resp.Diagnostics, uploadDiagnostic{Stage: stage, Message: line})
+		}
+	}
+	return resp
+}
+
+// internalFailed logs err and writes the response for a failure the uploader
+// can't fix. The code and status come from the error's cerrs.Code (e.g.,
+// DATABASE_BUSY and 503, so the client knows to retry), or INTERNAL and 500
+// if it has none. The error's text is only sent for client errors, as in
+// writeAPIFailure.
+func internalFailed(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	code := cerrs.CodeOf(err)
+	logging.FromContext(r.Context()).Error("upload: "+msg, "code", code, "err", err)
+	resp := uploadResponse{Code: uploadErrInternal, Error: errorMessage(msg, err)}
+	if code != cerrs.CodeUnknown {
+		resp.Code = string(code)
+	}
+	writeJSON(w, cerrs.HTTPStatus(code), resp)
 }
 
 func writeJSON(w http.ResponseWriter, status int, resp uploadResponse) {
@@ -66,8 +132,7 @@ func (h *Handlers) UploadPage(w http.ResponseWriter, r *http.Request) {
 	for i, g := range games {
 		turns := make([]templates.GameTurnOption, len(g.Turns))
 		for j, t := range g.Turns {
-			year, month := t.TurnNo/100, t.TurnNo%100
-			turns[j] = templates.GameTurnOption{ID: fmt.Sprintf("%04d-%02d", year, month), IsActive: t.IsActive}
+			turns[j] = templates.GameTurnOption{ID: t.TurnNo.String(), IsActive: t.IsActive}
 		}
 		gameOptions[i] = templates.GameOption{ID: g.ID, Description: g.Description, Turns: turns}
 	}
@@ -81,14 +146,18 @@ func (h *Handlers) UploadPage(w http.ResponseWriter, r *http.Request) {
 // UploadHandler handles POST requests to upload files.
 // Protected route: requires GM role.
 // Accepts files named CCCC.docx or GGGG.YYYY-MM.CCCC.report.txt
+// A text file sent with the email field set is a report that was sent as the
+// body of an email, and is cleaned up with report.FromEmail before splitting.
+// A report with units of another clan than the filename's is rejected unless
+// the allow_clan_mismatch field is set.
 func (h *Handlers) UploadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSON(w, http.StatusMethodNotAllowed, uploadResponse{Error: "method not allowed"})
+		writeJSON(w, http.StatusMethodNotAllowed, uploadResponse{Code: uploadErrBadRequest, Error: "method not allowed"})
 		return
 	}
 
 	if err := r.ParseMultipartForm(100 << 10); err != nil { // 100KB max
-		writeJSON(w, http.StatusBadRequest, uploadResponse{Error: "failed to parse form: " + err.Error()})
+		writeJSON(w, http.StatusBadRequest, uploadResponse{Code: uploadErrBadRequest, Error: "failed to parse form: " + err.Error()})
 		return
 	}
 
@@ -96,17 +165,17 @@ func (h *Handlers) UploadHandler(w http.ResponseWriter, r *http.Request) {
 	turn := r.FormValue("turn")
 
 	if game == "" {
-		writeJSON(w, http.StatusBadRequest, uploadResponse{Error: "game is required"})
+		writeJSON(w, http.StatusBadRequest, uploadResponse{Code: uploadErrBadRequest, Error: "game is required"})
 		return
 	}
 	if turn == "" {
-		writeJSON(w, http.StatusBadRequest, uploadResponse{Error: "turn is required"})
+		writeJSON(w, http.StatusBadRequest, uploadResponse{Code: uploadErrBadRequest, Error: "turn is required"})
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, uploadResponse{Error: "no file uploaded"})
+		writeJSON(w, http.StatusBadRequest, uploadResponse{Code: uploadErrBadRequest, Error: "no file uploaded"})
 		return
 	}
 	defer file.Close()
@@ -116,13 +185,14 @@ func (h *Handlers) UploadHandler(w http.ResponseWriter, r *http.Request) {
 
 	clan, fileGame, fileTurn, validationErr := validateFilename(filename)
 	if validationErr != "" {
-		writeJSON(w, http.StatusBadRequest, uploadResponse{Error: validationErr})
+		writeJSON(w, http.StatusBadRequest, uploadResponse{Code: uploadErrFilenameInvalid, Error: validationErr})
 		return
 	}
 
 	if strings.HasSuffix(strings.ToLower(filename), ".docx") {
 		if contentType != "" && contentType != docxContentType {
 			writeJSON(w, http.StatusBadRequest, uploadResponse{
+				Code:  uploadErrContentType,
 				Error: "invalid content type for .docx file: expected Word document",
 			})
 			return
@@ -131,70 +201,179 @@ func (h *Handlers) UploadHandler(w http.ResponseWriter, r *http.Request) {
 
 	if fileGame != "" && fileGame != game {
 		writeJSON(w, http.StatusBadRequest, uploadResponse{
+			Code:  uploadErrGameMismatch,
 			Error: "game in filename (" + fileGame + ") does not match selected game (" + game + ")",
 		})
 		return
 	}
 	if fileTurn != "" && fileTurn != turn {
 		writeJSON(w, http.StatusBadRequest, uploadResponse{
+			Code:  uploadErrTurnMismatch,
 			Error: "turn in filename (" + fileTurn + ") does not match selected turn (" + turn + ")",
 		})
 		return
 	}
 
+	// the file name pattern accepts a clan of any length; the game decides
+	ids, err := h.store.UnitIDRules(r.Context(), game)
+	if err != nil {
+		internalFailed(w, r, "failed to load unit id rules", err)
+		return
+	}
+	if err := ids.ValidateClan(clan); err != nil {
+		writeJSON(w, http.StatusBadRequest, uploadResponse{Code: uploadErrFilenameInvalid, Error: "clan in filename: " + err.Error()})
+		return
+	}
+
 	// Read the file contents
 	data, err := io.ReadAll(file)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, uploadResponse{Error: "failed to read file: " + err.Error()})
+		writeJSON(w, http.StatusInternalServerError, uploadResponse{Code: uploadErrBadRequest, Error: "failed to read file: " + err.Error()})
 		return
 	}
 	hash := sha256.Sum256(data)
+	if existing, err := h.store.GetReportFileBySHA256(r.Context(), hex.EncodeToString(hash[:])); err != nil {
+		internalFailed(w, r, "failed to check for duplicate", err)
+		return
+	} else if existing != nil {
+		writeJSON(w, http.StatusConflict, uploadResponse{
+			Code:  uploadErrDuplicateReport,
+			Error: "this file was already uploaded as " + existing.Name + " (game " + existing.Game + ", turn " + existing.TurnNo.String() + ")",
+			Clan:  existing.ClanNo,
+			Game:  existing.Game,
+			Turn:  existing.TurnNo.String(),
+		})
+		return
+	}
 
 	// Parse the file based on type
 	var text []byte
 	var mime string
+	var timings model.ParseTimings
+	var diagnostics []uploadDiagnostic
 
+	// Word documents and email bodies are split into report sections;
+	// plain text reports are parsed as they are
+	var doc *docx.Docx
+	started := time.Now()
 	if strings.HasSuffix(strings.ToLower(filename), ".docx") {
-		// Parse DOCX file
-		doc, err := docx.ParseReader(bytes.NewReader(data), true, true, true, false, false)
+		doc, err = docx.ParseReader(bytes.NewReader(data), true, true, true, false, false)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, parseFailed("docx", "failed to parse docx", err))
+			return
+		}
+		mime = docxContentType
+	} else if r.FormValue("email") != "" {
+		body, err := report.FromEmail(data, report.EmailWidth)
 		if err != nil {
-			writeJSON(w, http.StatusBadRequest, uploadResponse{Error: "failed to parse docx: " + err.Error()})
+			writeJSON(w, http.StatusBadRequest, parseFailed("email", "failed to decode email", err))
 			return
 		}
+		doc = &docx.Docx{Source: filename, Text: body}
+		mime = "text/plain"
+	} else {
+		var enc norm.Encoding
+		if text, enc = norm.ToUTF8(data); enc != norm.EncodingUTF8 {
+			diagnostics = append(diagnostics, uploadDiagnostic{Stage: "encoding", Message: "converted from " + string(enc) + " to UTF-8"})
+		}
+		mime = "text/plain"
+	}
+	if doc != nil {
+		timings.Extract = time.Since(started)
 
 		// Parse report to extract sections
+		started = time.Now()
 		rpt, err := report.ParseReportText(doc, true, true, true, false, false)
 		if err != nil {
-			writeJSON(w, http.StatusBadRequest, uploadResponse{Error: "failed to parse report: " + err.Error()})
+			writeJSON(w, http.StatusBadRequest, parseFailed("report", "failed to parse report", err))
 			return
 		}
+		for _, warning := range rpt.Warnings {
+			diagnostics = append(diagnostics, uploadDiagnostic{Stage: "report", Message: warning})
+		}
 
 		// Combine sections into text for bistre parser
 		for _, section := range rpt.Sections {
 			text = append(text, bytes.Join(section.Lines, []byte{'\n'})...)
 			text = append(text, '\n')
 		}
-		mime = docxContentType
-	} else {
-		// Plain text report
-		text = data
-		mime = "text/plain"
+		timings.Split = time.Since(started)
 	}
 
 	// Run bistre parser
-	parsedTurn, err := bistre.ParseInput(filename, turn, text, false, false, false, false, false, false, false, false, bistre.ParseConfig{})
+	started = time.Now()
+	parsedTurn, err := bistre.ParseInput(filename, turn, text, bistre.ParseConfig{Logger: logging.FromContext(r.Context())})
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, uploadResponse{Error: "failed to parse turn report: " + err.Error()})
+		writeJSON(w, http.StatusBadRequest, parseFailed("turn", "failed to parse turn report", err))
 		return
 	}
 	if parsedTurn == nil {
-		writeJSON(w, http.StatusBadRequest, uploadResponse{Error: "parser returned no data"})
+		writeJSON(w, http.StatusBadRequest, uploadResponse{Code: uploadErrParseFailed, Error: "parser returned no data"})
+		return
+	}
+
+	timings.Parse = time.Since(started)
+
+	// Convert parsed turn to model, rejecting unit IDs the game doesn't allow
+	started = time.Now()
+	for _, warning := range adapters.ResolveDuplicateUnits(parsedTurn) {
+		diagnostics = append(diagnostics, uploadDiagnostic{Stage: "adapt", Message: warning})
+	}
+	rx, err := adapters.BistreTurnToModelReportX(filename, parsedTurn, game, clan, ids, h.clock.Now)
+	var uerr *model.UnitIDError
+	if errors.As(err, &uerr) {
+		resp := parseFailed("adapt", "invalid unit ids", err)
+		resp.Code = uploadErrUnitIDInvalid
+		writeJSON(w, http.StatusBadRequest, resp)
+		return
+	} else if err != nil {
+		internalFailed(w, r, "failed to convert report", err)
 		return
 	}
+	timings.Adapt = time.Since(started)
 
-	// Convert to model and store
-	turnNo := 100*parsedTurn.Year + parsedTurn.Month
-	now := time.Now().UTC()
+	// the filename says whose report it is; the section headers must agree
+	unitIDs := make([]string, 0, len(rx.Units))
+	for _, u := range rx.Units {
+		unitIDs = append(unitIDs, u.UnitID)
+	}
+	if err := ids.CheckClan(clan, unitIDs); err != nil {
+		diag := uploadDiagnostic{Stage: "clan", Message: err.Error()}
+		if r.FormValue("allow_clan_mismatch") == "" {
+			writeJSON(w, http.StatusBadRequest, uploadResponse{
+				Code:        uploadErrClanMismatch,
+				Error:       err.Error(),
+				Diagnostics: append(diagnostics, diag),
+				Clan:        clan,
+			})
+			return
+		}
+		logging.FromContext(r.Context()).Warn("upload: clan mismatch allowed", logging.KeyFile, filename, logging.KeyUser, h.currentHandle(r), "err", err)
+		diagnostics = append(diagnostics, diag)
+	}
+
+	// lint problems are reported but don't stop the upload
+	lintCfg, err := h.store.LintConfigForGame(r.Context(), game)
+	if err != nil {
+		internalFailed(w, r, "failed to load lint rules", err)
+		return
+	}
+	world, err := h.store.WorldRules(r.Context(), game)
+	if err != nil {
+		internalFailed(w, r, "failed to load world rules", err)
+		return
+	}
+	for _, d := range h.linter.Run(&lint.Report{RX: rx, Text: text, World: world}, lintCfg) {
+		msg := d.Rule + ": " + d.Message
+		if d.Line > 0 {
+			msg = fmt.Sprintf("line %d: %s", d.Line, msg)
+		}
+		diagnostics = append(diagnostics, uploadDiagnostic{Stage: "lint", Severity: string(d.Severity), Message: msg})
+	}
+
+	// Store the report file and report
+	turnNo := model.NewTurnNo(parsedTurn.Year, parsedTurn.Month)
+	now := h.clock.Now().UTC()
 
 	// Create report file record
 	rf := &model.ReportFile{
@@ -207,23 +386,24 @@ func (h *Handlers) UploadHandler(w http.ResponseWriter, r *http.Request) {
 		CreatedAt: now,
 	}
 	if err := h.store.AddReportFile(rf); err != nil {
-		writeJSON(w, http.StatusInternalServerError, uploadResponse{Error: "failed to store report file: " + err.Error()})
+		internalFailed(w, r, "failed to store report file", err)
 		return
 	}
 
-	// Convert parsed turn to model
-	rx, err := adapters.BistreTurnToModelReportX(filename, parsedTurn, game, clan)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, uploadResponse{Error: "failed to convert report: " + err.Error()})
-		return
-	}
 	rx.ReportFileID = rf.ID
 
-	// Store the report
+	started = time.Now()
 	if err := h.store.AddReport(rx); err != nil {
-		writeJSON(w, http.StatusInternalServerError, uploadResponse{Error: "failed to store report: " + err.Error()})
+		internalFailed(w, r, "failed to store report", err)
 		return
 	}
+	timings.Store = time.Since(started)
+	if err := h.store.SetReportTimings(r.Context(), rx.ID, timings); err != nil {
+		logging.FromContext(r.Context()).Error("upload: report timings", logging.KeyFile, filename, "err", err)
+	}
+	if _, err := h.store.RecordCorrection(r.Context(), rx.ID); err != nil {
+		logging.FromContext(r.Context()).Error("upload: report correction", logging.KeyFile, filename, "err", err)
+	}
 
 	// Count results for response
 	units := len(rx.Units)
@@ -237,13 +417,14 @@ func (h *Handlers) UploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeJSON(w, http.StatusOK, uploadResponse{
-		Success: true,
-		Clan:    clan,
-		Game:    game,
-		Turn:    turn,
-		Units:   units,
-		Acts:    acts,
-		Steps:   steps,
+		Success:     true,
+		Diagnostics: diagnostics,
+		Clan:        clan,
+		Game:        game,
+		Turn:        turn,
+		Units:       units,
+		Acts:        acts,
+		Steps:       steps,
 	})
 }
 
@@ -264,5 +445,5 @@ func validateFilename(filename string) (clan, game, turn, errMsg string) {
 		return matches[3], matches[1], matches[2], ""
 	}
 
-	return "", "", "", "invalid filename: must be CCCC.docx or GGGG.YYYY-MM.CCCC.report.txt (where CCCC is clan 0001-0999, GGGG is 4-digit game, YYYY-MM is turn)"
+	return "", "", "", "invalid filename: must be CCCC.docx or GGGG.YYYY-MM.CCCC.report.txt (where CCCC is the clan, starting with 0, GGGG is 4-digit game, YYYY-MM is turn)"
 }
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+)
+
+// uploadEventsInterval is how often UploadEvents checks the work queue for changes.
+var uploadEventsInterval = time.Second
+
+// uploadStageEvent is the payload of a "stage" event.
+// Event is "queued", "running", "extracted", "parsed", or "failed".
+type uploadStageEvent struct {
+	ReportFileID int64  `json:"reportFileId"`
+	Stage        string `json:"stage"`
+	Status       string `json:"status"`
+	Event        string `json:"event"`
+	Attempt      int    `json:"attempt"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// uploadDoneEvent is the payload of the "done" event sent once every job
+// in the batch has finished.
+type uploadDoneEvent struct {
+	Batch  int64 `json:"batch"`
+	Files  int   `json:"files"`
+	Ok     int   `json:"ok"`
+	Failed int   `json:"failed"`
+}
+
+// UploadEvents streams pipeline progress for an upload batch as Server-Sent Events.
+// A "stage" event is sent each time a job in the batch changes status, and a
+// "done" event is sent (and the stream closed) once no jobs are queued or running.
+// Protected route: requires GM role.
+func (h *Handlers) UploadEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	batchID, err := strconv.ParseInt(r.PathValue("batch"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid batch", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.store.GetUploadBatch(r.Context(), batchID); errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Batch not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("upload: events", logging.KeyBatch, batchID, "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// the server's write timeout would otherwise cut off long-running streams
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logging.FromContext(r.Context()).Warn("upload: events: clear write deadline", logging.KeyBatch, batchID, "err", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(uploadEventsInterval)
+	defer ticker.Stop()
+
+	seen := map[int64]string{} // work id -> last status sent
+	for {
+		works, err := h.store.GetWorkByBatch(r.Context(), batchID)
+		if err != nil {
+			if r.Context().Err() == nil {
+				logging.FromContext(r.Context()).Error("upload: events", logging.KeyBatch, batchID, "err", err)
+			}
+			return
+		}
+
+		done := len(works) > 0
+		files := map[int64]bool{}
+		var ok, failed int
+		for _, work := range works {
+			files[work.ReportFileID] = true
+			switch work.Status {
+			case model.WorkStatusQueued, model.WorkStatusRunning:
+				done = false
+			case model.WorkStatusOk:
+				ok++
+			case model.WorkStatusFailed:
+				failed++
+			}
+			if seen[work.ID] == work.Status {
+				continue
+			}
+			seen[work.ID] = work.Status
+			if err := writeEvent(w, "stage", newUploadStageEvent(work)); err != nil {
+				return
+			}
+		}
+
+		if done {
+			writeEvent(w, "done", uploadDoneEvent{Batch: batchID, Files: len(files), Ok: ok, Failed: failed})
+			rc.Flush()
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func newUploadStageEvent(work model.Work) uploadStageEvent {
+	ev := uploadStageEvent{
+		ReportFileID: work.ReportFileID,
+		Stage:        work.Stage,
+		Status:       work.Status,
+		Event:        work.Status,
+		Attempt:      work.Attempt,
+	}
+	switch work.Status {
+	case model.WorkStatusOk:
+		switch work.Stage {
+		case model.WorkStageExtract:
+			ev.Event = "extracted"
+		case model.WorkStageParse:
+			ev.Event = "parsed"
+		case model.WorkStageRender:
+			ev.Event = "rendered"
+		}
+	case model.WorkStatusFailed:
+		if work.ErrorCode != nil {
+			ev.ErrorCode = *work.ErrorCode
+		}
+		if work.ErrorMessage != nil {
+			ev.Error = *work.ErrorMessage
+		}
+	}
+	return ev
+}
+
+// writeEvent writes a single Server-Sent Event with a JSON payload.
+func writeEvent(w http.ResponseWriter, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mdhender/tnrpt/clock"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/handlers"
+)
+
+// TestUploadHandler_Clock checks that an upload stamps the report file and
+// the report with the handler's clock.
+func TestUploadHandler_Clock(t *testing.T) {
+	ctx := context.Background()
+	s, err := store.NewSQLiteStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	now := time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC)
+	h := handlers.New(s, auth.NewSessionStore())
+	h.SetClock(clock.NewFixed(now))
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "0900-01.0987.report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("game", "0301")
+	mw.WriteField("turn", "0900-01")
+	fw, err := mw.CreateFormFile("file", "0301.0900-01.0987.report.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(data)
+	mw.Close()
+	r := httptest.NewRequest(http.MethodPost, "/upload", &buf)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	h.UploadHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload: status = %d: %s", w.Code, w.Body.String())
+	}
+
+	rfs, err := s.GetReportFilesByGameTurn(ctx, "0301", 90001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rfs) != 1 || !rfs[0].CreatedAt.Equal(now) {
+		t.Errorf("report files = %v, want one created at %v", rfs, now)
+	}
+	rxs, err := s.ReportExtractsByGameTurn(ctx, "0301", 90001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rxs) != 1 || !rxs[0].CreatedAt.Equal(now) {
+		t.Errorf("reports = %v, want one created at %v", rxs, now)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+	"github.com/mdhender/tnrpt/model"
+	"github.com/mdhender/tnrpt/web/auth"
+	"github.com/mdhender/tnrpt/web/templates"
+)
+
+const (
+	usageDefaultDays = 30
+	usageBusiestMax  = 20
+)
+
+// RecordUsage wraps the server's mux to add each request to the request log:
+// the route pattern it matched, the user, the status, and how long it took.
+// Static files aren't recorded. It must wrap the mux directly, since the
+// route pattern is set on the request by the mux.
+//
+// The server only installs it when usage statistics are turned on.
+func (h *Handlers) RecordUsage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := h.clock.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		if r.Pattern == "" || r.Pattern == "/static/" {
+			return
+		}
+		var handle string
+		if session := auth.GetSessionFromRequest(r, h.sessions); session != nil {
+			handle = session.User.Handle
+		}
+		// the client may be gone, but the request was still served
+		ctx := context.WithoutCancel(r.Context())
+		if err := h.store.RecordRequest(ctx, handle, r.Pattern, sw.status, h.clock.Now().Sub(start)); err != nil {
+			logging.FromContext(ctx).Warn("usage: record request", "err", err)
+		}
+	})
+}
+
+// statusWriter remembers the status code written to a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, which
+// the upload events stream needs to flush.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Usage shows how the server has been used: reports parsed per day, active
+// users per week, and the busiest pages. The numbers come from the server's
+// own database; users and pages are only counted while the server runs with
+// usage statistics turned on.
+// Query parameters: days (default 30) and format=json.
+// Protected route: requires GM role.
+func (h *Handlers) Usage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days := usageDefaultDays
+	if s := r.URL.Query().Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	since := h.clock.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+
+	stats, err := h.store.UsageStats(r.Context(), since, usageBusiestMax)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("usage", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		if stats.ReportsPerDay == nil {
+			stats.ReportsPerDay = []model.DayCount{}
+		}
+		if stats.UsersPerWeek == nil {
+			stats.UsersPerWeek = []model.WeekCount{}
+		}
+		if stats.BusiestPages == nil {
+			stats.BusiestPages = []model.RouteUsage{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+		return
+	}
+
+	session := auth.GetSessionFromRequest(r, h.sessions)
+	data := h.getLayoutData(r, session)
+	data.HideTurnSelect = true
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.UsagePage(stats, days, data).Render(r.Context(), w); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mdhender/tnrpt/logging"
+	store "github.com/mdhender/tnrpt/stores/sqlite"
+)
+
+// maxUsersImportBytes caps the size of a users import request body.
+const maxUsersImportBytes = 4 << 20
+
+// GMUsersExport returns every user, with their roles and clan assignments, as JSON.
+// Password hashes are only included with ?hashes=1.
+// Protected route: requires GM role.
+func (h *Handlers) GMUsersExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	withHashes := r.URL.Query().Get("hashes") == "1"
+	users, err := h.store.ExportUsers(r.Context(), withHashes)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("gm: users: export", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: users: exported", logging.KeyUser, h.currentHandle(r), "users", len(users), "hashes", withHashes)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="users.json"`)
+	w.Header().Set("Cache-Control", "no-store")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(users); err != nil {
+		logging.FromContext(r.Context()).Error("gm: users: export", "err", err)
+	}
+}
+
+// GMUsersImport creates or replaces users from a JSON body in the format
+// GMUsersExport writes. The import is all or nothing.
+// Protected route: requires GM role.
+func (h *Handlers) GMUsersImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var users []store.UserRecord
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUsersImportBytes)).Decode(&users); err != nil {
+		http.Error(w, "Invalid users JSON", http.StatusBadRequest)
+		return
+	}
+
+	handle := h.currentHandle(r)
+	result, err := h.store.ImportUsers(r.Context(), handle, users)
+	if errors.Is(err, store.ErrInvalidUsers) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("gm: users: import", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("gm: users: imported", logging.KeyUser, handle, "users", len(users), "created", result.Created, "updated", result.Updated)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"created": result.Created, "updated": result.Updated})
+}
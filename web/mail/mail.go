@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Package mail sends plain-text email, such as login links, to users.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/mdhender/tnrpt/logging"
+)
+
+// Sender delivers a plain-text message to one recipient.
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// SMTPSender sends mail through an SMTP server.
+// If Username is set, it authenticates with PLAIN auth (which net/smtp only
+// allows over TLS or to localhost).
+type SMTPSender struct {
+	Addr     string // host:port, e.g. "smtp.example.com:587"
+	From     string // envelope and header sender
+	Username string
+	Password string
+}
+
+func (s *SMTPSender) Send(ctx context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("mail: invalid header value")
+	}
+	var a smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return fmt.Errorf("mail: %w", err)
+		}
+		a = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(s.Addr, a, s.From, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("mail: send to %s: %w", to, err)
+	}
+	return nil
+}
+
+// LogSender writes messages to the log instead of sending them.
+// It is meant for development, where there is no mail server.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, to, subject, body string) error {
+	logging.FromContext(ctx).Info("mail", "to", to, "subject", subject, "body", body)
+	return nil
+}
@@ -158,7 +158,7 @@ func UploadPage(data LayoutData, games []GameOption, selectedGame string, turns
 					}
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</select></div><div class=\"form-group\"><label for=\"turn-select\">Turn Number</label> <select id=\"turn-select\" name=\"turn\" required><option value=\"\">Select a turn...</option></select></div></div><div id=\"drop-zone\" class=\"drop-zone\"><div class=\"drop-zone-content\"><p class=\"drop-icon\">📁</p><p>Drag & drop files here</p><p class=\"drop-hint\">or click to select files</p><input type=\"file\" id=\"file-input\" multiple accept=\".docx,.txt\" style=\"display:none\"></div></div><div id=\"upload-list\" class=\"upload-list\"></div></div><div id=\"toast-container\" class=\"toast-container\"></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</select></div><div class=\"form-group\"><label for=\"turn-select\">Turn Number</label> <select id=\"turn-select\" name=\"turn\" required><option value=\"\">Select a turn...</option></select></div></div><label class=\"upload-email\"><input type=\"checkbox\" id=\"email-body\"> Text files are email bodies (quoted-printable, quoted, or wrapped at 72 columns)</label> <label class=\"upload-email\"><input type=\"checkbox\" id=\"allow-clan-mismatch\"> Load reports whose units belong to another clan than the file name's</label><div id=\"drop-zone\" class=\"drop-zone\"><div class=\"drop-zone-content\"><p class=\"drop-icon\">📁</p><p>Drag & drop files here</p><p class=\"drop-hint\">or click to select files</p><input type=\"file\" id=\"file-input\" multiple accept=\".docx,.txt\" style=\"display:none\"></div></div><div id=\"upload-list\" class=\"upload-list\"></div></div><div id=\"toast-container\" class=\"toast-container\"></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -178,13 +178,15 @@ func UploadPage(data LayoutData, games []GameOption, selectedGame string, turns
 
 func uploadScript() templ.ComponentScript {
 	return templ.ComponentScript{
-		Name: `__templ_uploadScript_5070`,
-		Function: `function __templ_uploadScript_5070(){const dropZone = document.getElementById('drop-zone');
+		Name: `__templ_uploadScript_026b`,
+		Function: `function __templ_uploadScript_026b(){const dropZone = document.getElementById('drop-zone');
 	const fileInput = document.getElementById('file-input');
 	const uploadList = document.getElementById('upload-list');
 	const toastContainer = document.getElementById('toast-container');
 	const gameSelect = document.getElementById('game-select');
 	const turnSelect = document.getElementById('turn-select');
+	const emailBody = document.getElementById('email-body');
+	const allowClanMismatch = document.getElementById('allow-clan-mismatch');
 
 	// Update turn dropdown when game changes
 	gameSelect.addEventListener('change', () => {
@@ -315,6 +317,12 @@ func uploadScript() templ.ComponentScript {
 		formData.append('file', file);
 		formData.append('game', game);
 		formData.append('turn', turn);
+		if (emailBody.checked) {
+			formData.append('email', 'on');
+		}
+		if (allowClanMismatch.checked) {
+			formData.append('allow_clan_mismatch', 'on');
+		}
 
 		const xhr = new XMLHttpRequest();
 		xhr.open('POST', '/upload');
@@ -329,15 +337,34 @@ func uploadScript() templ.ComponentScript {
 		xhr.onload = () => {
 			if (xhr.status === 200) {
 				const resp = JSON.parse(xhr.responseText);
+				if (resp.batch) {
+					status.textContent = 'Queued';
+					watchBatch(resp.batch, file.name, status);
+					return;
+				}
 				status.textContent = '✓ Success';
 				status.className = 'upload-status success';
 				showToast(` + "`" + `<strong>${file.name}</strong><br>Parsed ${resp.units || 0} units, ${resp.acts || 0} acts, ${resp.steps || 0} steps.` + "`" + `, 'success');
 			} else {
 				let msg = 'Upload failed';
+				let code = '';
 				try {
 					const resp = JSON.parse(xhr.responseText);
 					msg = resp.error || msg;
+					code = resp.code || '';
+					if (resp.diagnostics && resp.diagnostics.length > 1) {
+						msg += ' (' + resp.diagnostics.length + ' problems)';
+					}
 				} catch {}
+				if (code === 'DUPLICATE_REPORT') {
+					status.textContent = '= Already uploaded';
+					status.className = 'upload-status';
+					showToast('<strong>' + file.name + '</strong><br>' + msg, 'info', 8000);
+					return;
+				}
+				if (code === 'CLAN_MISMATCH') {
+					msg += '. Check the file name, or tick "Load reports whose units belong to another clan" to load it anyway.';
+				}
 				status.textContent = '✗ ' + msg;
 				status.className = 'upload-status error';
 				showToast(` + "`" + `<strong>${file.name}</strong><br>${msg}` + "`" + `, 'error', 8000);
@@ -353,6 +380,43 @@ func uploadScript() templ.ComponentScript {
 		xhr.send(formData);
 	}
 
+	// Follow a queued upload through the pipeline stages using server-sent events.
+	function watchBatch(batch, name, status) {
+		const labels = {
+			queued: 'Queued',
+			running: 'Processing',
+			extracted: 'Extracted',
+			parsed: 'Parsed',
+			rendered: 'Map drawn',
+			failed: 'Failed'
+		};
+		const events = new EventSource(` + "`" + `/uploads/${batch}/events` + "`" + `);
+
+		events.addEventListener('stage', (e) => {
+			const ev = JSON.parse(e.data);
+			status.textContent = ` + "`" + `${labels[ev.event] || ev.event} (${ev.stage})` + "`" + `;
+			if (ev.event === 'failed') {
+				status.className = 'upload-status error';
+				showToast(` + "`" + `<strong>${name}</strong><br>${ev.stage} failed: ${ev.error || ev.errorCode}` + "`" + `, 'error', 8000);
+			}
+		});
+
+		events.addEventListener('done', (e) => {
+			events.close();
+			const ev = JSON.parse(e.data);
+			if (ev.failed === 0) {
+				status.textContent = '✓ Processed';
+				status.className = 'upload-status success';
+				showToast(` + "`" + `<strong>${name}</strong><br>Processing complete.` + "`" + `, 'success');
+			} else {
+				status.textContent = '✗ Processing failed';
+				status.className = 'upload-status error';
+			}
+		});
+
+		events.onerror = () => events.close();
+	}
+
 	function showToast(message, type = 'info', duration = 5000) {
 		const icons = {
 			success: '✓',
@@ -382,8 +446,8 @@ func uploadScript() templ.ComponentScript {
 		setTimeout(() => toast.remove(), 300);
 	}
 }`,
-		Call:       templ.SafeScript(`__templ_uploadScript_5070`),
-		CallInline: templ.SafeScriptInline(`__templ_uploadScript_5070`),
+		Call:       templ.SafeScript(`__templ_uploadScript_026b`),
+		CallInline: templ.SafeScriptInline(`__templ_uploadScript_026b`),
 	}
 }
 
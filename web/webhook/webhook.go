@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Michael D Henderson. All rights reserved.
+
+// Package webhook notifies an external service, such as a chat integration,
+// of game events by POSTing JSON to a URL.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Poster sends events to a single URL.
+type Poster struct {
+	URL    string
+	Client *http.Client // nil uses a client with a 10 second timeout
+}
+
+// Event is the body of every webhook request.
+type Event struct {
+	Event string    `json:"event"` // e.g., "turn.advance"
+	At    time.Time `json:"at"`
+	Data  any       `json:"data"`
+}
+
+// Post sends an event. Any response other than 2xx is an error.
+func (p *Poster) Post(ctx context.Context, event string, data any) error {
+	body, err := json.Marshal(Event{Event: event, At: time.Now().UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: %s: %s", p.URL, resp.Status)
+	}
+	return nil
+}